@@ -52,6 +52,14 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&VolumeNfsExportList{},
 		&VolumeNfsExportContent{},
 		&VolumeNfsExportContentList{},
+		&NfsExportMount{},
+		&NfsExportMountList{},
+		&NfsExportSummary{},
+		&NfsExportSummaryList{},
+		&NfsExportContentView{},
+		&NfsExportContentViewList{},
+		&NfsExportSet{},
+		&NfsExportSetList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
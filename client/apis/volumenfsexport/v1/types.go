@@ -38,6 +38,8 @@ import (
 // +kubebuilder:printcolumn:name="NfsExportClass",type=string,JSONPath=`.spec.volumeNfsExportClassName`,description="The name of the VolumeNfsExportClass requested by the VolumeNfsExport."
 // +kubebuilder:printcolumn:name="NfsExportContent",type=string,JSONPath=`.status.boundVolumeNfsExportContentName`,description="Name of the VolumeNfsExportContent object to which the VolumeNfsExport object intends to bind to. Please note that verification of binding actually requires checking both VolumeNfsExport and VolumeNfsExportContent to ensure both are pointing at each other. Binding MUST be verified prior to usage of this object."
 // +kubebuilder:printcolumn:name="CreationTime",type=date,JSONPath=`.status.creationTime`,description="Timestamp when the point-in-time nfsexport was taken by the underlying storage system."
+// +kubebuilder:printcolumn:name="TimeToReady",type=string,JSONPath=`.status.timeToReady`,description="Time it took from the creation of the VolumeNfsExport until it became ready to use."
+// +kubebuilder:printcolumn:name="Error",type=string,JSONPath=`.status.errorSummary`,description="First characters of the last observed error message."
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type VolumeNfsExport struct {
 	metav1.TypeMeta `json:",inline"`
@@ -92,8 +94,77 @@ type VolumeNfsExportSpec struct {
 	// Empty string is not allowed for this field.
 	// +optional
 	VolumeNfsExportClassName *string `json:"volumeNfsExportClassName,omitempty" protobuf:"bytes,2,opt,name=volumeNfsExportClassName"`
+
+	// exportPathHint is the requested path or path prefix of the export
+	// directory on the storage system. It is passed to the CSI driver, which
+	// may derive the export path from it instead of from the nfsexport handle.
+	// The hint must fully match the regular expression set in the
+	// "csi.storage.k8s.io/export-path-hint-pattern" parameter of the
+	// VolumeNfsExportClass; classes without the parameter reject hints.
+	// This field is immutable.
+	// +optional
+	ExportPathHint *string `json:"exportPathHint,omitempty" protobuf:"bytes,3,opt,name=exportPathHint"`
+
+	// mode selects what the nfsexport exports. "Live" exports the current
+	// contents of the source volume, including later changes. "PointInTime"
+	// asks the CSI driver to snapshot the source volume first and exports the
+	// snapshot. The mode must be listed in the
+	// "csi.storage.k8s.io/export-modes" parameter of the
+	// VolumeNfsExportClass; classes without the parameter only support "Live".
+	// If not specified, "Live" is used.
+	// This field is immutable.
+	// +kubebuilder:validation:Enum=Live;PointInTime
+	// +optional
+	Mode *VolumeNfsExportMode `json:"mode,omitempty" protobuf:"bytes,4,opt,name=mode,casttype=VolumeNfsExportMode"`
+
+	// sources lists the names of further PersistentVolumeClaims whose volumes
+	// are merged with the volume of source.persistentVolumeClaimName into a
+	// single export, which exposes each volume in its own subdirectory. The
+	// PersistentVolumeClaims are in the same namespace as the VolumeNfsExport
+	// and their volumes must be of the driver of the VolumeNfsExportClass.
+	// It requires source.persistentVolumeClaimName and a CSI driver able to
+	// merge volumes, e.g. with a union mount; other drivers fail the export.
+	// This field is immutable.
+	// +listType=set
+	// +optional
+	Sources []string `json:"sources,omitempty" protobuf:"bytes,5,rep,name=sources"`
+
+	// cacheTier asks the CSI driver to serve the export through a cache
+	// tier, e.g. "NodeLocal" pins the exported data in a cache local to the
+	// nodes reading it, for read-heavy workloads like AI training. The tier
+	// must be listed in the "csi.storage.k8s.io/export-cache-tiers"
+	// parameter of the VolumeNfsExportClass and the CSI driver must support
+	// export cache tiers. The tier the driver achieved is reported in the
+	// "csi.storage.k8s.io/export-cache-tier" attribute of the status.
+	// It may only be set for dynamically provisioned nfsexports.
+	// If not specified, the export is not cached.
+	// This field is immutable.
+	// +kubebuilder:validation:Enum=NodeLocal
+	// +optional
+	CacheTier *VolumeNfsExportCacheTier `json:"cacheTier,omitempty" protobuf:"bytes,6,opt,name=cacheTier,casttype=VolumeNfsExportCacheTier"`
 }
 
+// VolumeNfsExportMode selects what a VolumeNfsExport exports.
+type VolumeNfsExportMode string
+
+const (
+	// VolumeNfsExportModeLive exports the current contents of the source
+	// volume.
+	VolumeNfsExportModeLive VolumeNfsExportMode = "Live"
+	// VolumeNfsExportModePointInTime exports a snapshot of the source volume
+	// taken by the CSI driver when the nfsexport is created.
+	VolumeNfsExportModePointInTime VolumeNfsExportMode = "PointInTime"
+)
+
+// VolumeNfsExportCacheTier selects the cache tier serving a VolumeNfsExport.
+type VolumeNfsExportCacheTier string
+
+const (
+	// VolumeNfsExportCacheTierNodeLocal serves the export through a cache
+	// local to the nodes reading it.
+	VolumeNfsExportCacheTierNodeLocal VolumeNfsExportCacheTier = "NodeLocal"
+)
+
 // VolumeNfsExportSource specifies whether the underlying nfsexport should be
 // dynamically taken upon creation or if a pre-existing VolumeNfsExportContent
 // object should be used.
@@ -183,6 +254,53 @@ type VolumeNfsExportStatus struct {
 	// nfsexport creation. Upon success, this error field will be cleared.
 	// +optional
 	Error *VolumeNfsExportError `json:"error,omitempty" protobuf:"bytes,5,opt,name=error,casttype=VolumeNfsExportError"`
+
+	// exportPath is the path of the export directory on the storage system,
+	// copied from the bound VolumeNfsExportContent. It can be compared with
+	// spec.exportPathHint to check whether the driver honored the hint.
+	// +optional
+	ExportPath *string `json:"exportPath,omitempty" protobuf:"bytes,6,opt,name=exportPath"`
+
+	// errorSummary is the first 60 characters of error.message, shown by
+	// kubectl get. It is set and cleared along with error.
+	// +optional
+	ErrorSummary *string `json:"errorSummary,omitempty" protobuf:"bytes,7,opt,name=errorSummary"`
+
+	// timeToReady is the time from the creation of the VolumeNfsExport object
+	// until it first became ready to use, rounded to seconds.
+	// +optional
+	TimeToReady *metav1.Duration `json:"timeToReady,omitempty" protobuf:"bytes,8,opt,name=timeToReady"`
+
+	// zone is the zone or region of the storage system the export lives in,
+	// copied from the bound VolumeNfsExportContent.
+	// +optional
+	Zone *string `json:"zone,omitempty" protobuf:"bytes,9,opt,name=zone"`
+
+	// conditions are the conditions of the bound VolumeNfsExportContent,
+	// e.g. "Warming" or "Failed", and the "ClassMissing",
+	// "InvalidFlapping", "SourceDeleted" and "Blocked" conditions of the
+	// VolumeNfsExport.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,10,rep,name=conditions"`
+
+	// observedGeneration is the metadata.generation of the VolumeNfsExport
+	// the status was last written for. While it is smaller than
+	// metadata.generation, the latest change of the spec has not been
+	// processed by the nfsexport controller yet.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,11,opt,name=observedGeneration"`
+
+	// lastSyncTime is the last time the status was written by the nfsexport
+	// controller.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty" protobuf:"bytes,12,opt,name=lastSyncTime"`
+
+	// attributes are the attributes the CSI driver reported for the export,
+	// copied from the bound VolumeNfsExportContent.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty" protobuf:"bytes,13,rep,name=attributes"`
 }
 
 // +genclient
@@ -195,8 +313,10 @@ type VolumeNfsExportStatus struct {
 // VolumeNfsExportClasses are non-namespaced
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:scope=Cluster,shortName=vsclass;vsclasses
+// +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="Driver",type=string,JSONPath=`.driver`
 // +kubebuilder:printcolumn:name="DeletionPolicy",type=string,JSONPath=`.deletionPolicy`,description="Determines whether a VolumeNfsExportContent created through the VolumeNfsExportClass should be deleted when its bound VolumeNfsExport is deleted."
+// +kubebuilder:printcolumn:name="DriverVersion",type=string,JSONPath=`.status.driverInfo.vendorVersion`,description="Version of the CSI driver as reported by the csi-nfsexporter sidecar serving the class."
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 type VolumeNfsExportClass struct {
 	metav1.TypeMeta `json:",inline"`
@@ -221,6 +341,95 @@ type VolumeNfsExportClass struct {
 	// "Delete" means that the VolumeNfsExportContent and its physical nfsexport on underlying storage system are deleted.
 	// Required.
 	DeletionPolicy DeletionPolicy `json:"deletionPolicy" protobuf:"bytes,4,opt,name=deletionPolicy"`
+
+	// creationTimeout is the time a VolumeNfsExportContent created through the
+	// VolumeNfsExportClass may take to become ready to use. Once it is exceeded,
+	// the Failed condition of the content and of its VolumeNfsExport is set to
+	// "True" and the CSI driver is no longer polled for the export.
+	// If not set, the controller waits for the export indefinitely.
+	// +optional
+	CreationTimeout *metav1.Duration `json:"creationTimeout,omitempty" protobuf:"bytes,6,opt,name=creationTimeout"`
+
+	// encryption configures the encryption of the exports created through the
+	// VolumeNfsExportClass, in transit and at rest. The settings are passed
+	// to the CSI driver when an export is created.
+	// If not set, the defaults of the driver apply.
+	// +optional
+	Encryption *NfsExportEncryption `json:"encryption,omitempty" protobuf:"bytes,7,opt,name=encryption"`
+
+	// status represents the current information of the CSI driver of the class.
+	// It is populated by the csi-nfsexporter sidecar serving the driver.
+	// +optional
+	Status *VolumeNfsExportClassStatus `json:"status,omitempty" protobuf:"bytes,5,opt,name=status"`
+}
+
+// NfsExportEncryption configures the encryption of exports.
+type NfsExportEncryption struct {
+	// transport selects how the traffic between the NFS clients and an
+	// export is protected. Supported values are "krb5p", "stunnel" and
+	// "none".
+	// "krb5p" means that the export requires Kerberos with privacy.
+	// "stunnel" means that the export is only reachable through a TLS tunnel.
+	// "none" means that the traffic is not encrypted.
+	// If not set, the default of the driver applies.
+	// +kubebuilder:validation:Enum=krb5p;stunnel;none
+	// +optional
+	Transport *NfsExportTransport `json:"transport,omitempty" protobuf:"bytes,1,opt,name=transport,casttype=NfsExportTransport"`
+
+	// atRest configures the encryption of the data of an export on the
+	// storage system.
+	// +optional
+	AtRest *NfsExportAtRestEncryption `json:"atRest,omitempty" protobuf:"bytes,2,opt,name=atRest"`
+}
+
+// NfsExportTransport selects how the traffic to an export is protected.
+type NfsExportTransport string
+
+const (
+	// NfsExportTransportKrb5p requires Kerberos authentication with
+	// integrity and privacy protection.
+	NfsExportTransportKrb5p NfsExportTransport = "krb5p"
+	// NfsExportTransportStunnel only serves the export through a TLS tunnel.
+	NfsExportTransportStunnel NfsExportTransport = "stunnel"
+	// NfsExportTransportNone does not encrypt the traffic.
+	NfsExportTransportNone NfsExportTransport = "none"
+)
+
+// NfsExportAtRestEncryption configures the encryption at rest of exports.
+type NfsExportAtRestEncryption struct {
+	// kmsKeySecretRef references the Secret holding the reference of the
+	// encryption key in the key management system of the storage system. The
+	// data of the Secret is passed to the CSI driver with the credentials of
+	// the export. The Secret must have a "version" key, which must be changed
+	// when the key is rotated. The csi-nfsexporter sidecar then asks the
+	// driver to update the exports of the class.
+	// Required.
+	KMSKeySecretRef core_v1.SecretReference `json:"kmsKeySecretRef" protobuf:"bytes,1,opt,name=kmsKeySecretRef"`
+}
+
+// VolumeNfsExportClassStatus is the status of a VolumeNfsExportClass.
+type VolumeNfsExportClassStatus struct {
+	// driverInfo describes the CSI driver of the class, as discovered by the
+	// csi-nfsexporter sidecar on startup.
+	// +optional
+	DriverInfo *NfsExportDriverInfo `json:"driverInfo,omitempty" protobuf:"bytes,1,opt,name=driverInfo"`
+}
+
+// NfsExportDriverInfo describes a CSI driver.
+type NfsExportDriverInfo struct {
+	// vendorVersion is the version of the driver, as reported by GetPluginInfo.
+	// +optional
+	VendorVersion string `json:"vendorVersion,omitempty" protobuf:"bytes,1,opt,name=vendorVersion"`
+
+	// pluginCapabilities lists the service capabilities of the driver, as
+	// reported by GetPluginCapabilities, e.g. "CONTROLLER_SERVICE".
+	// +optional
+	PluginCapabilities []string `json:"pluginCapabilities,omitempty" protobuf:"bytes,2,rep,name=pluginCapabilities"`
+
+	// controllerCapabilities lists the controller service capabilities of the
+	// driver, as reported by ControllerGetCapabilities, e.g. "CREATE_DELETE_VOLUME".
+	// +optional
+	ControllerCapabilities []string `json:"controllerCapabilities,omitempty" protobuf:"bytes,3,rep,name=controllerCapabilities"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -254,6 +463,7 @@ type VolumeNfsExportClassList struct {
 // +kubebuilder:printcolumn:name="VolumeNfsExportClass",type=string,JSONPath=`.spec.volumeNfsExportClassName`,description="Name of the VolumeNfsExportClass to which this nfsexport belongs."
 // +kubebuilder:printcolumn:name="VolumeNfsExport",type=string,JSONPath=`.spec.volumeNfsExportRef.name`,description="Name of the VolumeNfsExport object to which this VolumeNfsExportContent object is bound."
 // +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// +kubebuilder:selectablefield:JSONPath=`.spec.driver`
 type VolumeNfsExportContent struct {
 	metav1.TypeMeta `json:",inline"`
 	// Standard object's metadata.
@@ -336,6 +546,29 @@ type VolumeNfsExportContentSpec struct {
 	// This field is an alpha field.
 	// +optional
 	SourceVolumeMode *core_v1.PersistentVolumeMode `json:"sourceVolumeMode" protobuf:"bytes,6,opt,name=sourceVolumeMode"`
+
+	// exportPathHint is the requested path or path prefix of the export
+	// directory on the storage system, copied from the VolumeNfsExport for
+	// dynamically provisioned nfsexports. See VolumeNfsExportSpec.ExportPathHint.
+	// This field is immutable.
+	// +optional
+	ExportPathHint *string `json:"exportPathHint,omitempty" protobuf:"bytes,7,opt,name=exportPathHint"`
+
+	// mode selects what the nfsexport exports, copied from the
+	// VolumeNfsExport for dynamically provisioned nfsexports. See
+	// VolumeNfsExportSpec.Mode.
+	// This field is immutable.
+	// +kubebuilder:validation:Enum=Live;PointInTime
+	// +optional
+	Mode *VolumeNfsExportMode `json:"mode,omitempty" protobuf:"bytes,8,opt,name=mode,casttype=VolumeNfsExportMode"`
+
+	// cacheTier is the cache tier serving the export, copied from the
+	// VolumeNfsExport for dynamically provisioned nfsexports. See
+	// VolumeNfsExportSpec.CacheTier.
+	// This field is immutable.
+	// +kubebuilder:validation:Enum=NodeLocal
+	// +optional
+	CacheTier *VolumeNfsExportCacheTier `json:"cacheTier,omitempty" protobuf:"bytes,9,opt,name=cacheTier,casttype=VolumeNfsExportCacheTier"`
 }
 
 // VolumeNfsExportContentSource represents the CSI source of a nfsexport.
@@ -354,6 +587,14 @@ type VolumeNfsExportContentSource struct {
 	// This field is immutable.
 	// +optional
 	NfsExportHandle *string `json:"nfsexportHandle,omitempty" protobuf:"bytes,2,opt,name=nfsexportHandle"`
+
+	// volumeHandles specifies the CSI "volume_id"s of further volumes merged
+	// with the volume of volumeHandle into the nfsexport, see
+	// VolumeNfsExportSpec.Sources. It requires volumeHandle.
+	// This field is immutable.
+	// +listType=set
+	// +optional
+	VolumeHandles []string `json:"volumeHandles,omitempty" protobuf:"bytes,3,rep,name=volumeHandles"`
 }
 
 // VolumeNfsExportContentStatus is the status of a VolumeNfsExportContent object
@@ -415,8 +656,185 @@ type VolumeNfsExportContentStatus struct {
 	// Upon success after retry, this error field will be cleared.
 	// +optional
 	Error *VolumeNfsExportError `json:"error,omitempty" protobuf:"bytes,5,opt,name=error,casttype=VolumeNfsExportError"`
+
+	// lastTransitionTime is the last time readyToUse changed its value.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,6,opt,name=lastTransitionTime"`
+
+	// errorHistory holds the most recent errors observed for this content,
+	// oldest first. It is bounded by the nfsexporter sidecar and is not cleared
+	// on success, so it can be used to reconstruct how long a content flapped.
+	// +optional
+	ErrorHistory []VolumeNfsExportError `json:"errorHistory,omitempty" protobuf:"bytes,7,rep,name=errorHistory"`
+
+	// exportPath is the path of the export directory on the storage system.
+	// It is derived from nfsexport handles in the form server:/path and is
+	// not set for other handles.
+	// +optional
+	ExportPath *string `json:"exportPath,omitempty" protobuf:"bytes,8,opt,name=exportPath"`
+
+	// zone is the zone or region of the storage system the export lives in,
+	// as set by the "csi.storage.k8s.io/export-zone" parameter of the
+	// VolumeNfsExportClass. It can be used to create PVCs restored from the
+	// export in the same zone, to avoid cross-zone NFS traffic.
+	// +optional
+	Zone *string `json:"zone,omitempty" protobuf:"bytes,9,opt,name=zone"`
+
+	// conditions are the latest observations of the state of the nfsexport.
+	// See ConditionWarming, ConditionFailed, ConditionPermissionDenied,
+	// ConditionContentInUse, ConditionInvalidFlapping and
+	// ConditionDuplicateNfsExportHandle.
+	// +listType=map
+	// +listMapKey=type
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty" protobuf:"bytes,10,rep,name=conditions"`
+
+	// observedGeneration is the metadata.generation of the
+	// VolumeNfsExportContent the status was last written for. While it is
+	// smaller than metadata.generation, the latest change of the spec has not
+	// been processed by the nfsexporter sidecar yet.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty" protobuf:"varint,11,opt,name=observedGeneration"`
+
+	// lastSyncTime is the last time the status was written by the
+	// nfsexporter sidecar.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty" protobuf:"bytes,12,opt,name=lastSyncTime"`
+
+	// attributes are the attributes the CSI driver reported for the export
+	// once it was created, e.g. an ID of the export on the storage system or
+	// the export options actually applied. They are opaque to Kubernetes.
+	// +optional
+	Attributes map[string]string `json:"attributes,omitempty" protobuf:"bytes,13,rep,name=attributes"`
+
+	// sourceVolumes lists the volumes merged into an export created from
+	// several volumes, see VolumeNfsExportContentSource.VolumeHandles, with
+	// the subdirectory of the export exposing each of them.
+	// +optional
+	SourceVolumes []NfsExportSourceVolume `json:"sourceVolumes,omitempty" protobuf:"bytes,14,rep,name=sourceVolumes"`
 }
 
+// NfsExportSourceVolume is a volume merged into an export created from
+// several volumes.
+type NfsExportSourceVolume struct {
+	// volumeHandle is the CSI "volume_id" of the volume.
+	VolumeHandle string `json:"volumeHandle" protobuf:"bytes,1,opt,name=volumeHandle"`
+
+	// subdirectory is the path of the directory exposing the volume,
+	// relative to the root of the export.
+	Subdirectory string `json:"subdirectory" protobuf:"bytes,2,opt,name=subdirectory"`
+}
+
+const (
+	// ConditionWarming is the condition of a VolumeNfsExportContent whose
+	// class requests a warm-up. It is True while the CSI driver pre-stages
+	// the export, e.g. hydrates it from a cold tier, and False once the
+	// export is ready or the warm-up timed out.
+	ConditionWarming = "Warming"
+
+	// Reasons of the Warming condition.
+	WarmingReasonInProgress = "WarmingUp"
+	WarmingReasonCompleted  = "WarmedUp"
+	WarmingReasonTimedOut   = "WarmUpTimedOut"
+
+	// ConditionFailed is the condition of a VolumeNfsExportContent, and of
+	// its VolumeNfsExport, that failed permanently. Once it is "True", the
+	// CSI driver is no longer polled for the export.
+	ConditionFailed = "Failed"
+
+	// Reasons of the Failed condition.
+	FailedReasonCreationTimedOut = "CreationTimedOut"
+	// FailedReasonClassDeleted is the reason of a VolumeNfsExport whose
+	// VolumeNfsExportClass was deleted before its export was created. It is
+	// set on the VolumeNfsExport only, as no VolumeNfsExportContent exists.
+	FailedReasonClassDeleted = "ClassDeleted"
+
+	// ConditionClassMissing is the condition of a VolumeNfsExport without a
+	// class name whose default VolumeNfsExportClass cannot be determined,
+	// e.g. because no class of the driver of its source volume is marked as
+	// the default. It turns "False" once a default class is found.
+	ConditionClassMissing = "ClassMissing"
+
+	// Reasons of the ClassMissing condition.
+	ClassMissingReasonNoDefault        = "NoDefaultClass"
+	ClassMissingReasonMultipleDefaults = "MultipleDefaultClasses"
+	ClassMissingReasonResolved         = "DefaultClassFound"
+
+	// ConditionPermissionDenied is the condition of a VolumeNfsExportContent
+	// whose deletion secret the nfsexporter sidecar is not allowed to get,
+	// e.g. because its RBAC rules do not cover the namespace of the secret.
+	// The export is not deleted until the sidecar is granted access.
+	ConditionPermissionDenied = "PermissionDenied"
+
+	// Reasons of the PermissionDenied condition.
+	PermissionDeniedReasonDeletionSecretForbidden = "DeletionSecretForbidden"
+
+	// ConditionContentInUse is the condition of a VolumeNfsExportContent
+	// being deleted while a PersistentVolumeClaim is still being restored
+	// from its VolumeNfsExport. The nfsexporter sidecar does not delete the
+	// export on the storage system while it is "True". It turns "False" once
+	// no claim is being restored from the export.
+	ConditionContentInUse = "ContentInUse"
+
+	// Reasons of the ContentInUse condition.
+	ContentInUseReasonRestoreInProgress = "RestoreInProgress"
+	ContentInUseReasonResolved          = "NotInUse"
+
+	// ConditionInvalidFlapping is the condition of a VolumeNfsExport or a
+	// VolumeNfsExportContent whose invalid label was added and removed too
+	// many times within an hour, e.g. because the validation webhook and the
+	// nfsexport controller disagree about it. While it is "True", the
+	// controller leaves the label as it is. It turns "False" once the label is
+	// stable again.
+	ConditionInvalidFlapping = "InvalidFlapping"
+
+	// Reasons of the InvalidFlapping condition.
+	InvalidFlappingReasonToggleLimitReached = "InvalidLabelToggleLimitReached"
+	InvalidFlappingReasonStable             = "InvalidLabelStable"
+
+	// ConditionDuplicateNfsExportHandle is the condition of a pre-provisioned
+	// VolumeNfsExportContent whose nfsexport handle is also used by other
+	// contents of the same driver. Deleting one of them would delete the
+	// export of all of them. It turns "False" once the handle is unique.
+	ConditionDuplicateNfsExportHandle = "DuplicateNfsExportHandle"
+
+	// Reasons of the DuplicateNfsExportHandle condition.
+	DuplicateNfsExportHandleReasonShared   = "NfsExportHandleShared"
+	DuplicateNfsExportHandleReasonResolved = "NfsExportHandleUnique"
+
+	// ConditionSourceDeleted is the informational condition of a ready
+	// PointInTime VolumeNfsExport whose source PersistentVolumeClaim no
+	// longer exists, or was recreated after the export was cut. The export is
+	// then the only copy of the data of the original claim. Live exports
+	// never get it. It is never cleared.
+	ConditionSourceDeleted = "SourceDeleted"
+
+	// Reasons of the SourceDeleted condition.
+	SourceDeletedReasonClaimNotFound  = "SourcePVCNotFound"
+	SourceDeletedReasonClaimRecreated = "SourcePVCRecreated"
+
+	// ConditionBlocked is the condition of a VolumeNfsExport the nfsexport
+	// controller does not act on until something else changes. Its reason
+	// names what the controller waits for and its message the objects
+	// involved, e.g. the pods still writing to the source
+	// PersistentVolumeClaim. It turns "False" once the controller acts.
+	ConditionBlocked = "Blocked"
+
+	// Reasons of the Blocked condition.
+	BlockedReasonRestoreInProgress = "RestoreInProgress"
+	BlockedReasonWaitingForWriters = "WaitingForWriters"
+	BlockedReasonWaitingForQuiesce = "WaitingForQuiesce"
+	BlockedReasonSourcePVCNotBound = "SourcePVCNotBound"
+	BlockedReasonClassNotFound     = "ClassNotFound"
+	BlockedReasonContentNotFound   = "ContentNotFound"
+	// BlockedReasonDynamicProvisioningDisabled is the reason of a
+	// VolumeNfsExport with a source PersistentVolumeClaim when the nfsexport
+	// controller runs in content-only mode, without access to
+	// PersistentVolumeClaims.
+	BlockedReasonDynamicProvisioningDisabled = "DynamicProvisioningDisabled"
+	BlockedReasonResolved                    = "Unblocked"
+)
+
 // DeletionPolicy describes a policy for end-of-life maintenance of volume nfsexport contents
 // +kubebuilder:validation:Enum=Delete;Retain
 type DeletionPolicy string
@@ -444,3 +862,344 @@ type VolumeNfsExportError struct {
 	// +optional
 	Message *string `json:"message,omitempty" protobuf:"bytes,2,opt,name=message"`
 }
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NfsExportMount is a request to mount a ready VolumeNfsExport at a host path
+// of a node, for legacy workloads that cannot consume PersistentVolumeClaims.
+// It is served by the nfsexport-mount-agent running on that node, which keeps
+// the export mounted only while the VolumeNfsExport is ready to use.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=nem
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="NfsExport",type=string,JSONPath=`.spec.volumeNfsExportName`,description="Name of the VolumeNfsExport to mount."
+// +kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`,description="Name of the node the VolumeNfsExport is mounted on."
+// +kubebuilder:printcolumn:name="Path",type=string,JSONPath=`.spec.path`,description="Host path the VolumeNfsExport is mounted at."
+// +kubebuilder:printcolumn:name="Mounted",type=boolean,JSONPath=`.status.mounted`,description="Indicates if the VolumeNfsExport is currently mounted."
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type NfsExportMount struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// spec defines where the nfsexport is mounted.
+	// Required.
+	Spec NfsExportMountSpec `json:"spec" protobuf:"bytes,2,opt,name=spec"`
+
+	// status represents the current state of the mount, as observed by the
+	// mount agent of the node.
+	// +optional
+	Status *NfsExportMountStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NfsExportMountList is a list of NfsExportMount objects.
+type NfsExportMountList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// List of NfsExportMounts.
+	Items []NfsExportMount `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// NfsExportMountSpec describes the mount of a VolumeNfsExport on a node.
+type NfsExportMountSpec struct {
+	// volumeNfsExportName is the name of the VolumeNfsExport to mount. The
+	// VolumeNfsExport must be in the same namespace as the NfsExportMount.
+	// This field is immutable.
+	VolumeNfsExportName string `json:"volumeNfsExportName" protobuf:"bytes,1,opt,name=volumeNfsExportName"`
+
+	// nodeName is the name of the node to mount the nfsexport on.
+	// This field is immutable.
+	NodeName string `json:"nodeName" protobuf:"bytes,2,opt,name=nodeName"`
+
+	// path is the absolute host path to mount the nfsexport at. The directory
+	// is created by the mount agent if it does not exist.
+	// This field is immutable.
+	Path string `json:"path" protobuf:"bytes,3,opt,name=path"`
+
+	// subPath is a relative path within the export to mount instead of its
+	// root.
+	// This field is immutable.
+	// +optional
+	SubPath string `json:"subPath,omitempty" protobuf:"bytes,4,opt,name=subPath"`
+
+	// mountOptions are passed to the NFS mount, e.g. ["ro", "nfsvers=4.1"].
+	// +optional
+	MountOptions []string `json:"mountOptions,omitempty" protobuf:"bytes,5,rep,name=mountOptions"`
+}
+
+// NfsExportMountStatus is the status of a NfsExportMount.
+type NfsExportMountStatus struct {
+	// mounted indicates if the nfsexport is currently mounted at spec.path.
+	// The nfsexport is unmounted as soon as its VolumeNfsExport is no longer
+	// ready to use or is deleted.
+	// +optional
+	Mounted *bool `json:"mounted,omitempty" protobuf:"varint,1,opt,name=mounted"`
+
+	// source is the NFS source that is mounted, in the form server:/path.
+	// +optional
+	Source *string `json:"source,omitempty" protobuf:"bytes,2,opt,name=source"`
+
+	// lastTransitionTime is the time the mount last changed from mounted to
+	// unmounted or the other way round.
+	// +optional
+	LastTransitionTime *metav1.Time `json:"lastTransitionTime,omitempty" protobuf:"bytes,3,opt,name=lastTransitionTime"`
+
+	// error is the last error encountered while mounting or unmounting the
+	// nfsexport. It is cleared on success.
+	// +optional
+	Error *VolumeNfsExportError `json:"error,omitempty" protobuf:"bytes,4,opt,name=error,casttype=VolumeNfsExportError"`
+}
+
+// NfsExportSummaryName is the name of the NfsExportSummary of a namespace.
+const NfsExportSummaryName = "nfsexport-summary"
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NfsExportSummary aggregates the state of the VolumeNfsExports of a
+// namespace, so that tenant dashboards can read it without permission to
+// list the VolumeNfsExports or the VolumeNfsExportContents. It is maintained
+// by the nfsexport controller, which creates one named "nfsexport-summary" in
+// each namespace with at least one VolumeNfsExport.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=nes
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyCount`,description="Number of VolumeNfsExports ready to use."
+// +kubebuilder:printcolumn:name="Pending",type=integer,JSONPath=`.status.pendingCount`,description="Number of VolumeNfsExports being created."
+// +kubebuilder:printcolumn:name="Failed",type=integer,JSONPath=`.status.failedCount`,description="Number of VolumeNfsExports that failed."
+// +kubebuilder:printcolumn:name="RestoreSize",type=string,JSONPath=`.status.totalRestoreSize`,description="Total restore size of the VolumeNfsExports ready to use."
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type NfsExportSummary struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// status is the aggregated state of the VolumeNfsExports of the
+	// namespace, as observed by the nfsexport controller.
+	// +optional
+	Status *NfsExportSummaryStatus `json:"status,omitempty" protobuf:"bytes,2,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NfsExportSummaryList is a list of NfsExportSummary objects.
+type NfsExportSummaryList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// List of NfsExportSummaries.
+	Items []NfsExportSummary `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// NfsExportSummaryStatus is the status of a NfsExportSummary.
+type NfsExportSummaryStatus struct {
+	// readyCount is the number of VolumeNfsExports of the namespace that are
+	// ready to use.
+	ReadyCount int32 `json:"readyCount" protobuf:"varint,1,opt,name=readyCount"`
+
+	// pendingCount is the number of VolumeNfsExports of the namespace that
+	// are neither ready to use nor failed, e.g. being created.
+	PendingCount int32 `json:"pendingCount" protobuf:"varint,2,opt,name=pendingCount"`
+
+	// failedCount is the number of VolumeNfsExports of the namespace that
+	// are not ready to use and have an error or a "Failed" condition.
+	FailedCount int32 `json:"failedCount" protobuf:"varint,3,opt,name=failedCount"`
+
+	// totalRestoreSize is the sum of the restore sizes of the VolumeNfsExports
+	// of the namespace that are ready to use.
+	// +optional
+	TotalRestoreSize *resource.Quantity `json:"totalRestoreSize,omitempty" protobuf:"bytes,4,opt,name=totalRestoreSize"`
+
+	// lastUpdateTime is the time the counts last changed.
+	// +optional
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty" protobuf:"bytes,5,opt,name=lastUpdateTime"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NfsExportContentView is a read-only projection of the
+// VolumeNfsExportContent bound to a VolumeNfsExport, so that users of a
+// namespace can see where and how big their exports are without permission
+// to read the cluster scoped VolumeNfsExportContents. It is maintained by the
+// nfsexport controller, which creates one with the name of each bound
+// VolumeNfsExport in its namespace. It only holds fields that are safe to
+// show to the users of the namespace, e.g. no secrets, volume handles or
+// error messages of the driver.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=necv
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="ReadyToUse",type=boolean,JSONPath=`.status.readyToUse`,description="Indicates if the export is ready to be used to restore a volume."
+// +kubebuilder:printcolumn:name="RestoreSize",type=string,JSONPath=`.status.restoreSize`,description="Represents the minimum size of volume required to rehydrate from this export."
+// +kubebuilder:printcolumn:name="Server",type=string,JSONPath=`.status.server`,description="NFS server of the export."
+// +kubebuilder:printcolumn:name="ExportPath",type=string,JSONPath=`.status.exportPath`,description="Path of the export directory on the NFS server."
+// +kubebuilder:printcolumn:name="CreationTime",type=date,JSONPath=`.status.creationTime`,description="Timestamp when the point-in-time export was taken by the underlying storage system."
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type NfsExportContentView struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// status is the projection of the VolumeNfsExportContent bound to the
+	// VolumeNfsExport of the same name, as observed by the nfsexport
+	// controller.
+	// +optional
+	Status *NfsExportContentViewStatus `json:"status,omitempty" protobuf:"bytes,2,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NfsExportContentViewList is a list of NfsExportContentView objects.
+type NfsExportContentViewList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// List of NfsExportContentViews.
+	Items []NfsExportContentView `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// NfsExportContentViewStatus is the status of a NfsExportContentView.
+type NfsExportContentViewStatus struct {
+	// readyToUse indicates if the export is ready to be used to restore a
+	// volume, see VolumeNfsExportContentStatus.ReadyToUse.
+	// +optional
+	ReadyToUse *bool `json:"readyToUse,omitempty" protobuf:"varint,1,opt,name=readyToUse"`
+
+	// restoreSize is the minimum size of a volume restored from the export,
+	// see VolumeNfsExportContentStatus.RestoreSize.
+	// +optional
+	RestoreSize *resource.Quantity `json:"restoreSize,omitempty" protobuf:"bytes,2,opt,name=restoreSize"`
+
+	// creationTime is the time the export was taken by the storage system,
+	// see VolumeNfsExportContentStatus.CreationTime.
+	// +optional
+	CreationTime *metav1.Time `json:"creationTime,omitempty" protobuf:"bytes,3,opt,name=creationTime"`
+
+	// server is the NFS server of the export. It is derived from nfsexport
+	// handles in the form server:/path and is not set for other handles.
+	// +optional
+	Server *string `json:"server,omitempty" protobuf:"bytes,4,opt,name=server"`
+
+	// exportPath is the path of the export directory on the NFS server, see
+	// VolumeNfsExportContentStatus.ExportPath.
+	// +optional
+	ExportPath *string `json:"exportPath,omitempty" protobuf:"bytes,5,opt,name=exportPath"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NfsExportSet maintains one VolumeNfsExport of each PersistentVolumeClaim of
+// its namespace matched by its selector, created from its template, so that
+// users do not need to write generators to e.g. export every claim labeled
+// backup=true. It is maintained by the nfsexport controller, which creates a
+// VolumeNfsExport named <set name>-<claim name> when a claim starts matching
+// and deletes it when the claim stops matching or is deleted. The
+// VolumeNfsExports are owned by their NfsExportSet and are garbage collected
+// with it.
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Namespaced,shortName=nesets
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Exports",type=integer,JSONPath=`.status.exports`,description="Number of VolumeNfsExports of the set."
+// +kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyExports`,description="Number of VolumeNfsExports of the set ready to use."
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+type NfsExportSet struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// More info: https://git.k8s.io/community/contributors/devel/sig-architecture/api-conventions.md#metadata
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// spec defines the PersistentVolumeClaims to export and the
+	// VolumeNfsExports created for them.
+	// Required.
+	Spec NfsExportSetSpec `json:"spec" protobuf:"bytes,2,opt,name=spec"`
+
+	// status is the state of the VolumeNfsExports of the set, as observed by
+	// the nfsexport controller.
+	// +optional
+	Status *NfsExportSetStatus `json:"status,omitempty" protobuf:"bytes,3,opt,name=status"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NfsExportSetList is a list of NfsExportSet objects.
+type NfsExportSetList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty" protobuf:"bytes,1,opt,name=metadata"`
+
+	// List of NfsExportSets.
+	Items []NfsExportSet `json:"items" protobuf:"bytes,2,rep,name=items"`
+}
+
+// NfsExportSetSpec is the specification of a NfsExportSet.
+type NfsExportSetSpec struct {
+	// selector selects the PersistentVolumeClaims of the namespace of the set
+	// to export. An empty selector selects all of them. Claims being deleted
+	// are not selected.
+	// Required.
+	Selector metav1.LabelSelector `json:"selector" protobuf:"bytes,1,opt,name=selector"`
+
+	// template describes the VolumeNfsExports created for the selected
+	// PersistentVolumeClaims. Changes of the template apply to the
+	// VolumeNfsExports created afterwards, existing ones are left as they are.
+	// +optional
+	Template NfsExportSetTemplate `json:"template,omitempty" protobuf:"bytes,2,opt,name=template"`
+}
+
+// NfsExportSetTemplate describes the VolumeNfsExports of a NfsExportSet. The
+// source of each VolumeNfsExport is its PersistentVolumeClaim.
+type NfsExportSetTemplate struct {
+	// labels are added to the VolumeNfsExports. Labels with the
+	// nfsexport.storage.kubernetes.io/ prefix managed by the controllers are
+	// not added.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" protobuf:"bytes,1,rep,name=labels"`
+
+	// annotations are added to the VolumeNfsExports. Annotations with the
+	// nfsexport.storage.kubernetes.io/ prefix managed by the controllers are
+	// not added.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty" protobuf:"bytes,2,rep,name=annotations"`
+
+	// volumeNfsExportClassName is the VolumeNfsExportClass of the
+	// VolumeNfsExports, see VolumeNfsExportSpec.VolumeNfsExportClassName.
+	// +optional
+	VolumeNfsExportClassName *string `json:"volumeNfsExportClassName,omitempty" protobuf:"bytes,3,opt,name=volumeNfsExportClassName"`
+
+	// mode is the mode of the VolumeNfsExports, see VolumeNfsExportSpec.Mode.
+	// +optional
+	// +kubebuilder:validation:Enum=Live;PointInTime
+	Mode *VolumeNfsExportMode `json:"mode,omitempty" protobuf:"bytes,4,opt,name=mode,casttype=VolumeNfsExportMode"`
+}
+
+// NfsExportSetStatus is the status of a NfsExportSet.
+type NfsExportSetStatus struct {
+	// exports is the number of VolumeNfsExports of the set.
+	Exports int32 `json:"exports" protobuf:"varint,1,opt,name=exports"`
+
+	// readyExports is the number of VolumeNfsExports of the set that are
+	// ready to use.
+	ReadyExports int32 `json:"readyExports" protobuf:"varint,2,opt,name=readyExports"`
+
+	// conflictingClaims are the selected PersistentVolumeClaims whose
+	// VolumeNfsExport cannot be created because a VolumeNfsExport not
+	// controlled by the set already has its name.
+	// +optional
+	// +listType=set
+	ConflictingClaims []string `json:"conflictingClaims,omitempty" protobuf:"bytes,3,rep,name=conflictingClaims"`
+}
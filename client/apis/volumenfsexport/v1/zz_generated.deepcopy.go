@@ -23,9 +23,553 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportAtRestEncryption) DeepCopyInto(out *NfsExportAtRestEncryption) {
+	*out = *in
+	out.KMSKeySecretRef = in.KMSKeySecretRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportAtRestEncryption.
+func (in *NfsExportAtRestEncryption) DeepCopy() *NfsExportAtRestEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportAtRestEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportDriverInfo) DeepCopyInto(out *NfsExportDriverInfo) {
+	*out = *in
+	if in.PluginCapabilities != nil {
+		in, out := &in.PluginCapabilities, &out.PluginCapabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ControllerCapabilities != nil {
+		in, out := &in.ControllerCapabilities, &out.ControllerCapabilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportDriverInfo.
+func (in *NfsExportDriverInfo) DeepCopy() *NfsExportDriverInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportDriverInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportEncryption) DeepCopyInto(out *NfsExportEncryption) {
+	*out = *in
+	if in.Transport != nil {
+		in, out := &in.Transport, &out.Transport
+		*out = new(NfsExportTransport)
+		**out = **in
+	}
+	if in.AtRest != nil {
+		in, out := &in.AtRest, &out.AtRest
+		*out = new(NfsExportAtRestEncryption)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportEncryption.
+func (in *NfsExportEncryption) DeepCopy() *NfsExportEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportContentView) DeepCopyInto(out *NfsExportContentView) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(NfsExportContentViewStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportContentView.
+func (in *NfsExportContentView) DeepCopy() *NfsExportContentView {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportContentView)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NfsExportContentView) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportContentViewList) DeepCopyInto(out *NfsExportContentViewList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NfsExportContentView, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportContentViewList.
+func (in *NfsExportContentViewList) DeepCopy() *NfsExportContentViewList {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportContentViewList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NfsExportContentViewList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportContentViewStatus) DeepCopyInto(out *NfsExportContentViewStatus) {
+	*out = *in
+	if in.ReadyToUse != nil {
+		in, out := &in.ReadyToUse, &out.ReadyToUse
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RestoreSize != nil {
+		in, out := &in.RestoreSize, &out.RestoreSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Server != nil {
+		in, out := &in.Server, &out.Server
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExportPath != nil {
+		in, out := &in.ExportPath, &out.ExportPath
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportContentViewStatus.
+func (in *NfsExportContentViewStatus) DeepCopy() *NfsExportContentViewStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportContentViewStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportMount) DeepCopyInto(out *NfsExportMount) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(NfsExportMountStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportMount.
+func (in *NfsExportMount) DeepCopy() *NfsExportMount {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportMount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NfsExportMount) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportMountList) DeepCopyInto(out *NfsExportMountList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NfsExportMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportMountList.
+func (in *NfsExportMountList) DeepCopy() *NfsExportMountList {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportMountList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NfsExportMountList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportMountSpec) DeepCopyInto(out *NfsExportMountSpec) {
+	*out = *in
+	if in.MountOptions != nil {
+		in, out := &in.MountOptions, &out.MountOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportMountSpec.
+func (in *NfsExportMountSpec) DeepCopy() *NfsExportMountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportMountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportMountStatus) DeepCopyInto(out *NfsExportMountStatus) {
+	*out = *in
+	if in.Mounted != nil {
+		in, out := &in.Mounted, &out.Mounted
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(string)
+		**out = **in
+	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Error != nil {
+		in, out := &in.Error, &out.Error
+		*out = new(VolumeNfsExportError)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportMountStatus.
+func (in *NfsExportMountStatus) DeepCopy() *NfsExportMountStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportMountStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSourceVolume) DeepCopyInto(out *NfsExportSourceVolume) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSourceVolume.
+func (in *NfsExportSourceVolume) DeepCopy() *NfsExportSourceVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSourceVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSet) DeepCopyInto(out *NfsExportSet) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(NfsExportSetStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSet.
+func (in *NfsExportSet) DeepCopy() *NfsExportSet {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NfsExportSet) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSetList) DeepCopyInto(out *NfsExportSetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NfsExportSet, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSetList.
+func (in *NfsExportSetList) DeepCopy() *NfsExportSetList {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NfsExportSetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSetSpec) DeepCopyInto(out *NfsExportSetSpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	in.Template.DeepCopyInto(&out.Template)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSetSpec.
+func (in *NfsExportSetSpec) DeepCopy() *NfsExportSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSetStatus) DeepCopyInto(out *NfsExportSetStatus) {
+	*out = *in
+	if in.ConflictingClaims != nil {
+		in, out := &in.ConflictingClaims, &out.ConflictingClaims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSetStatus.
+func (in *NfsExportSetStatus) DeepCopy() *NfsExportSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSetTemplate) DeepCopyInto(out *NfsExportSetTemplate) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VolumeNfsExportClassName != nil {
+		in, out := &in.VolumeNfsExportClassName, &out.VolumeNfsExportClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(VolumeNfsExportMode)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSetTemplate.
+func (in *NfsExportSetTemplate) DeepCopy() *NfsExportSetTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSetTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSummary) DeepCopyInto(out *NfsExportSummary) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(NfsExportSummaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSummary.
+func (in *NfsExportSummary) DeepCopy() *NfsExportSummary {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSummary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NfsExportSummary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSummaryList) DeepCopyInto(out *NfsExportSummaryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NfsExportSummary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSummaryList.
+func (in *NfsExportSummaryList) DeepCopy() *NfsExportSummaryList {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSummaryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NfsExportSummaryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NfsExportSummaryStatus) DeepCopyInto(out *NfsExportSummaryStatus) {
+	*out = *in
+	if in.TotalRestoreSize != nil {
+		in, out := &in.TotalRestoreSize, &out.TotalRestoreSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NfsExportSummaryStatus.
+func (in *NfsExportSummaryStatus) DeepCopy() *NfsExportSummaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NfsExportSummaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeNfsExport) DeepCopyInto(out *VolumeNfsExport) {
 	*out = *in
@@ -70,6 +614,21 @@ func (in *VolumeNfsExportClass) DeepCopyInto(out *VolumeNfsExportClass) {
 			(*out)[key] = val
 		}
 	}
+	if in.CreationTimeout != nil {
+		in, out := &in.CreationTimeout, &out.CreationTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(NfsExportEncryption)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = new(VolumeNfsExportClassStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -124,6 +683,27 @@ func (in *VolumeNfsExportClassList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeNfsExportClassStatus) DeepCopyInto(out *VolumeNfsExportClassStatus) {
+	*out = *in
+	if in.DriverInfo != nil {
+		in, out := &in.DriverInfo, &out.DriverInfo
+		*out = new(NfsExportDriverInfo)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeNfsExportClassStatus.
+func (in *VolumeNfsExportClassStatus) DeepCopy() *VolumeNfsExportClassStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeNfsExportClassStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeNfsExportContent) DeepCopyInto(out *VolumeNfsExportContent) {
 	*out = *in
@@ -202,6 +782,11 @@ func (in *VolumeNfsExportContentSource) DeepCopyInto(out *VolumeNfsExportContent
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeHandles != nil {
+		in, out := &in.VolumeHandles, &out.VolumeHandles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -230,6 +815,21 @@ func (in *VolumeNfsExportContentSpec) DeepCopyInto(out *VolumeNfsExportContentSp
 		*out = new(corev1.PersistentVolumeMode)
 		**out = **in
 	}
+	if in.ExportPathHint != nil {
+		in, out := &in.ExportPathHint, &out.ExportPathHint
+		*out = new(string)
+		**out = **in
+	}
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(VolumeNfsExportMode)
+		**out = **in
+	}
+	if in.CacheTier != nil {
+		in, out := &in.CacheTier, &out.CacheTier
+		*out = new(VolumeNfsExportCacheTier)
+		**out = **in
+	}
 	return
 }
 
@@ -271,6 +871,50 @@ func (in *VolumeNfsExportContentStatus) DeepCopyInto(out *VolumeNfsExportContent
 		*out = new(VolumeNfsExportError)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.LastTransitionTime != nil {
+		in, out := &in.LastTransitionTime, &out.LastTransitionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ErrorHistory != nil {
+		in, out := &in.ErrorHistory, &out.ErrorHistory
+		*out = make([]VolumeNfsExportError, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExportPath != nil {
+		in, out := &in.ExportPath, &out.ExportPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.Zone != nil {
+		in, out := &in.Zone, &out.Zone
+		*out = new(string)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SourceVolumes != nil {
+		in, out := &in.SourceVolumes, &out.SourceVolumes
+		*out = make([]NfsExportSourceVolume, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -377,6 +1021,26 @@ func (in *VolumeNfsExportSpec) DeepCopyInto(out *VolumeNfsExportSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ExportPathHint != nil {
+		in, out := &in.ExportPathHint, &out.ExportPathHint
+		*out = new(string)
+		**out = **in
+	}
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(VolumeNfsExportMode)
+		**out = **in
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CacheTier != nil {
+		in, out := &in.CacheTier, &out.CacheTier
+		*out = new(VolumeNfsExportCacheTier)
+		**out = **in
+	}
 	return
 }
 
@@ -417,6 +1081,44 @@ func (in *VolumeNfsExportStatus) DeepCopyInto(out *VolumeNfsExportStatus) {
 		*out = new(VolumeNfsExportError)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExportPath != nil {
+		in, out := &in.ExportPath, &out.ExportPath
+		*out = new(string)
+		**out = **in
+	}
+	if in.ErrorSummary != nil {
+		in, out := &in.ErrorSummary, &out.ErrorSummary
+		*out = new(string)
+		**out = **in
+	}
+	if in.TimeToReady != nil {
+		in, out := &in.TimeToReady, &out.TimeToReady
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Zone != nil {
+		in, out := &in.Zone, &out.Zone
+		*out = new(string)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -28,6 +28,22 @@ type FakeNfsExportV1 struct {
 	*testing.Fake
 }
 
+func (c *FakeNfsExportV1) NfsExportContentViews(namespace string) v1.NfsExportContentViewInterface {
+	return &FakeNfsExportContentViews{c, namespace}
+}
+
+func (c *FakeNfsExportV1) NfsExportMounts(namespace string) v1.NfsExportMountInterface {
+	return &FakeNfsExportMounts{c, namespace}
+}
+
+func (c *FakeNfsExportV1) NfsExportSets(namespace string) v1.NfsExportSetInterface {
+	return &FakeNfsExportSets{c, namespace}
+}
+
+func (c *FakeNfsExportV1) NfsExportSummaries(namespace string) v1.NfsExportSummaryInterface {
+	return &FakeNfsExportSummaries{c, namespace}
+}
+
 func (c *FakeNfsExportV1) VolumeNfsExports(namespace string) v1.VolumeNfsExportInterface {
 	return &FakeVolumeNfsExports{c, namespace}
 }
@@ -18,6 +18,14 @@ limitations under the License.
 
 package v1
 
+type NfsExportContentViewExpansion interface{}
+
+type NfsExportMountExpansion interface{}
+
+type NfsExportSetExpansion interface{}
+
+type NfsExportSummaryExpansion interface{}
+
 type VolumeNfsExportExpansion interface{}
 
 type VolumeNfsExportClassExpansion interface{}
@@ -28,6 +28,10 @@ import (
 
 type NfsExportV1Interface interface {
 	RESTClient() rest.Interface
+	NfsExportContentViewsGetter
+	NfsExportMountsGetter
+	NfsExportSetsGetter
+	NfsExportSummariesGetter
 	VolumeNfsExportsGetter
 	VolumeNfsExportClassesGetter
 	VolumeNfsExportContentsGetter
@@ -38,6 +42,22 @@ type NfsExportV1Client struct {
 	restClient rest.Interface
 }
 
+func (c *NfsExportV1Client) NfsExportContentViews(namespace string) NfsExportContentViewInterface {
+	return newNfsExportContentViews(c, namespace)
+}
+
+func (c *NfsExportV1Client) NfsExportMounts(namespace string) NfsExportMountInterface {
+	return newNfsExportMounts(c, namespace)
+}
+
+func (c *NfsExportV1Client) NfsExportSets(namespace string) NfsExportSetInterface {
+	return newNfsExportSets(c, namespace)
+}
+
+func (c *NfsExportV1Client) NfsExportSummaries(namespace string) NfsExportSummaryInterface {
+	return newNfsExportSummaries(c, namespace)
+}
+
 func (c *NfsExportV1Client) VolumeNfsExports(namespace string) VolumeNfsExportInterface {
 	return newVolumeNfsExports(c, namespace)
 }
@@ -53,6 +53,14 @@ func (f *genericInformer) Lister() cache.GenericLister {
 func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource) (GenericInformer, error) {
 	switch resource {
 	// Group=nfsexport.storage.k8s.io, Version=v1
+	case v1.SchemeGroupVersion.WithResource("nfsexportcontentviews"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.NfsExport().V1().NfsExportContentViews().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("nfsexportmounts"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.NfsExport().V1().NfsExportMounts().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("nfsexportsets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.NfsExport().V1().NfsExportSets().Informer()}, nil
+	case v1.SchemeGroupVersion.WithResource("nfsexportsummaries"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.NfsExport().V1().NfsExportSummaries().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("volumenfsexports"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.NfsExport().V1().VolumeNfsExports().Informer()}, nil
 	case v1.SchemeGroupVersion.WithResource("volumenfsexportclasses"):
@@ -24,6 +24,14 @@ import (
 
 // Interface provides access to all the informers in this group version.
 type Interface interface {
+	// NfsExportContentViews returns a NfsExportContentViewInformer.
+	NfsExportContentViews() NfsExportContentViewInformer
+	// NfsExportMounts returns a NfsExportMountInformer.
+	NfsExportMounts() NfsExportMountInformer
+	// NfsExportSets returns a NfsExportSetInformer.
+	NfsExportSets() NfsExportSetInformer
+	// NfsExportSummaries returns a NfsExportSummaryInformer.
+	NfsExportSummaries() NfsExportSummaryInformer
 	// VolumeNfsExports returns a VolumeNfsExportInformer.
 	VolumeNfsExports() VolumeNfsExportInformer
 	// VolumeNfsExportClasses returns a VolumeNfsExportClassInformer.
@@ -43,6 +51,26 @@ func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakList
 	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
 }
 
+// NfsExportContentViews returns a NfsExportContentViewInformer.
+func (v *version) NfsExportContentViews() NfsExportContentViewInformer {
+	return &nfsExportContentViewInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// NfsExportMounts returns a NfsExportMountInformer.
+func (v *version) NfsExportMounts() NfsExportMountInformer {
+	return &nfsExportMountInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// NfsExportSets returns a NfsExportSetInformer.
+func (v *version) NfsExportSets() NfsExportSetInformer {
+	return &nfsExportSetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// NfsExportSummaries returns a NfsExportSummaryInformer.
+func (v *version) NfsExportSummaries() NfsExportSummaryInformer {
+	return &nfsExportSummaryInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // VolumeNfsExports returns a VolumeNfsExportInformer.
 func (v *version) VolumeNfsExports() VolumeNfsExportInformer {
 	return &volumeNfsExportInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
@@ -18,6 +18,38 @@ limitations under the License.
 
 package v1
 
+// NfsExportContentViewListerExpansion allows custom methods to be added to
+// NfsExportContentViewLister.
+type NfsExportContentViewListerExpansion interface{}
+
+// NfsExportContentViewNamespaceListerExpansion allows custom methods to be added to
+// NfsExportContentViewNamespaceLister.
+type NfsExportContentViewNamespaceListerExpansion interface{}
+
+// NfsExportMountListerExpansion allows custom methods to be added to
+// NfsExportMountLister.
+type NfsExportMountListerExpansion interface{}
+
+// NfsExportMountNamespaceListerExpansion allows custom methods to be added to
+// NfsExportMountNamespaceLister.
+type NfsExportMountNamespaceListerExpansion interface{}
+
+// NfsExportSetListerExpansion allows custom methods to be added to
+// NfsExportSetLister.
+type NfsExportSetListerExpansion interface{}
+
+// NfsExportSetNamespaceListerExpansion allows custom methods to be added to
+// NfsExportSetNamespaceLister.
+type NfsExportSetNamespaceListerExpansion interface{}
+
+// NfsExportSummaryListerExpansion allows custom methods to be added to
+// NfsExportSummaryLister.
+type NfsExportSummaryListerExpansion interface{}
+
+// NfsExportSummaryNamespaceListerExpansion allows custom methods to be added to
+// NfsExportSummaryNamespaceLister.
+type NfsExportSummaryNamespaceListerExpansion interface{}
+
 // VolumeNfsExportListerExpansion allows custom methods to be added to
 // VolumeNfsExportLister.
 type VolumeNfsExportListerExpansion interface{}
@@ -32,6 +32,7 @@ import (
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -40,13 +41,20 @@ import (
 	klog "k8s.io/klog/v2"
 
 	// "github.com/container-storage-interface/spec/lib/go/csi"
-	"github.com/kubernetes-csi/csi-lib-utils/connection"
 	"github.com/kubernetes-csi/csi-lib-utils/leaderelection"
 	"github.com/kubernetes-csi/csi-lib-utils/metrics"
 	csirpc "github.com/kubernetes-csi/csi-lib-utils/rpc"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/audit"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/buildinfo"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/configfile"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/csiconnection"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/eventtemplates"
 	controller "github.com/kubernetes-csi/external-nfsexporter/v6/pkg/sidecar-controller"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/features"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/httpendpoint"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/nfsexporter"
 
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
 	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
 	nfsexportscheme "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/scheme"
 	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
@@ -61,14 +69,25 @@ const (
 // Command line flags
 var (
 	kubeconfig             = flag.String("kubeconfig", "", "Absolute path to the kubeconfig file. Required only when running out of cluster.")
-	csiAddress             = flag.String("csi-address", "/run/csi/socket", "Address of the CSI driver socket.")
-	resyncPeriod           = flag.Duration("resync-period", 15*time.Minute, "Resync interval of the controller. Default is 15 minutes")
+	csiAddress             = flag.String("csi-address", "/run/csi/socket", "Address of the CSI driver socket. Either the path of a unix socket, which may be on a volume shared with a CSI driver running in another pod, or a tcp://<host>:<port> endpoint, to run the sidecar as a standalone deployment next to the controller service of the CSI driver.")
+	contentResyncPeriod    = flag.Duration("content-resync-period", 15*time.Minute, "Resync interval of the VolumeNfsExportContents and of the other resources watched by the sidecar. 0 disables the periodic resync, relying on watch bookmarks to keep the informers up to date. Default is 15 minutes")
 	nfsexportNamePrefix     = flag.String("nfsexport-name-prefix", "nfsexport", "Prefix to apply to the name of a created nfsexport")
 	nfsexportNameUUIDLength = flag.Int("nfsexport-name-uuid-length", -1, "Length in characters for the generated uuid of a created nfsexport. Defaults behavior is to NOT truncate.")
 	showVersion            = flag.Bool("version", false, "Show version.")
 	threads                = flag.Int("worker-threads", 10, "Number of worker threads.")
 	csiTimeout             = flag.Duration("timeout", defaultCSITimeout, "The timeout for any RPCs to the CSI driver. Default is 1 minute.")
 	extraCreateMetadata    = flag.Bool("extra-create-metadata", false, "If set, add nfsexport metadata to plugin nfsexport requests as parameters.")
+	// Deprecated, replaced by --content-resync-period.
+	_ = flag.Duration(utils.DeprecatedResyncPeriodFlag, 15*time.Minute, "(deprecated) Resync interval of the controller. Use --content-resync-period instead, which it sets unless it is set.")
+
+	csiTLSCAFile     = flag.String("csi-tls-ca-file", "", "PEM file of the certificate authorities used to verify the CSI driver at a tcp:// --csi-address. Either it or --csi-insecure is required for a tcp:// --csi-address.")
+	csiTLSCertFile   = flag.String("csi-tls-cert-file", "", "PEM file of the client certificate presented to the CSI driver at a tcp:// --csi-address, for mutual TLS. Requires --csi-tls-ca-file and --csi-tls-key-file.")
+	csiTLSKeyFile    = flag.String("csi-tls-key-file", "", "PEM file of the key of --csi-tls-cert-file.")
+	csiTLSServerName = flag.String("csi-tls-server-name", "", "Name used to verify the certificate of the CSI driver at a tcp:// --csi-address. Defaults to the host of --csi-address.")
+	csiInsecure      = flag.Bool("csi-insecure", false, "Connect to the CSI driver at a tcp:// --csi-address without TLS. The CSI calls carry secrets, use it only on trusted networks.")
+	csiDialTimeout   = flag.Duration("csi-dial-timeout", time.Minute, "How long to wait for the connection to the CSI driver at a tcp:// --csi-address. 0 waits forever.")
+	csiReconnect     = flag.Bool("csi-reconnect-on-connection-loss", false, "Re-establishes a lost connection to the CSI driver at a unix socket --csi-address, e.g. when the driver container restarts, instead of exiting. Lost connections to a tcp:// --csi-address are always re-established. Either way, the VolumeNfsExportContents being created are retried as soon as the driver is reachable again.")
+	csiKeepaliveTime = flag.Duration("csi-keepalive-time", 0, "Interval of the keepalive pings sent to the CSI driver at a tcp:// --csi-address, so that dead connections are detected. The CSI driver must permit pings at this interval. The default is 0, which disables keepalive pings.")
 
 	leaderElection              = flag.Bool("leader-election", false, "Enables leader election.")
 	leaderElectionNamespace     = flag.String("leader-election-namespace", "", "The namespace where the leader election resource exists. Defaults to the pod namespace if not set.")
@@ -79,12 +98,43 @@ var (
 	kubeAPIQPS   = flag.Float64("kube-api-qps", 5, "QPS to use while communicating with the kubernetes apiserver. Defaults to 5.0.")
 	kubeAPIBurst = flag.Int("kube-api-burst", 10, "Burst to use while communicating with the kubernetes apiserver. Defaults to 10.")
 
+	kubeAPIReadQPS     = flag.Float64("kube-api-read-qps", 0, "QPS to use for the list and watch requests of the informers. They are throttled separately from the other requests. The default is 0, which means --kube-api-qps is used.")
+	kubeAPIReadBurst   = flag.Int("kube-api-read-burst", 0, "Burst to use for the list and watch requests of the informers. Only used if --kube-api-read-qps is set.")
+	kubeAPIStatusQPS   = flag.Float64("kube-api-status-qps", 0, "QPS to use for status updates of VolumeNfsExportContents and VolumeNfsExportClasses. They are throttled separately from the other requests, so that a burst of status updates does not delay deletions. The default is 0, which means --kube-api-qps is used.")
+	kubeAPIStatusBurst = flag.Int("kube-api-status-burst", 0, "Burst to use for status updates of VolumeNfsExportContents and VolumeNfsExportClasses. Only used if --kube-api-status-qps is set.")
+
 	metricsAddress       = flag.String("metrics-address", "", "(deprecated) The TCP network address where the prometheus metrics endpoint will listen (example: `:8080`). The default is empty string, which means metrics endpoint is disabled. Only one of `--metrics-address` and `--http-endpoint` can be set.")
-	httpEndpoint         = flag.String("http-endpoint", "", "The TCP network address where the HTTP server for diagnostics, including metrics and leader election health check, will listen (example: `:8080`). The default is empty string, which means the server is disabled. Only one of `--metrics-address` and `--http-endpoint` can be set.")
+	httpEndpoint         = flag.String("http-endpoint", "", "The TCP network address (example: `:8080`) or the unix socket (example: `unix:///run/nfsexport/http.sock`) where the HTTP server for diagnostics, including metrics and leader election health check, will listen. The default is empty string, which means the server is disabled. Only one of `--metrics-address` and `--http-endpoint` can be set.")
+	disableHTTPEndpoint  = flag.Bool("disable-http-endpoint", false, "Do not start the HTTP server for diagnostics even if --http-endpoint or --metrics-address is set, for environments that forbid additional listeners.")
+	httpTLSCertFile      = flag.String("http-tls-cert-file", "", "PEM file of the certificate of the HTTP server for diagnostics at a TCP --http-endpoint. TLS is used when it is set. Requires --http-tls-key-file. It is reloaded when it changes.")
+	httpTLSKeyFile       = flag.String("http-tls-key-file", "", "PEM file of the key of --http-tls-cert-file.")
+	httpTLSClientCAFile  = flag.String("http-tls-client-ca-file", "", "PEM file of the certificate authorities used to verify the client certificates required by the HTTP server for diagnostics, for mutual TLS. Requires --http-tls-cert-file. It is reloaded when it changes.")
 	metricsPath          = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is `/metrics`.")
 	retryIntervalStart   = flag.Duration("retry-interval-start", time.Second, "Initial retry interval of failed volume nfsexport creation or deletion. It doubles with each failure, up to retry-interval-max. Default is 1 second.")
 	retryIntervalMax     = flag.Duration("retry-interval-max", 5*time.Minute, "Maximum retry interval of failed volume nfsexport creation or deletion. Default is 5 minutes.")
 	enableNodeDeployment = flag.Bool("node-deployment", false, "Enables deploying the sidecar controller together with a CSI driver on nodes to manage nfsexports for node-local volumes.")
+
+	dryRun           = flag.Bool("dry-run", false, "Runs the full sync flow without connecting to a CSI driver. Each CSI call that would be issued is written to stdout as a JSON line and reported as successful. All writes to the API server are sent with dryRun=All, so objects are validated but never changed.")
+	dryRunDriverName = flag.String("dry-run-driver-name", "", "Name of the CSI driver to act as in dry-run mode. Required when --dry-run is set.")
+
+	deleteBatchSize   = flag.Int("delete-batch-size", 0, "Maximum number of nfsexports deleted in a single DeleteNfsExports call, if the CSI driver supports it. Deletions are grouped by credentials, and a group holds at most as many nfsexports as there are worker threads. The default is 0, which means nfsexports are deleted one by one.")
+	deleteBatchWindow = flag.Duration("delete-batch-window", 100*time.Millisecond, "Time to wait for more deletions after the first one of a group before deleting the group. Only used if --delete-batch-size is greater than 1. Default is 100 milliseconds.")
+
+	configFile           = flag.String("config", "", "Path of a YAML config file mapping flag names to their values. Flags set on the command line take precedence. Changes of --v, --vmodule, --kube-api-qps, --kube-api-burst and --timeout in the config file are applied without a restart, changes of the other flags require one.")
+	configReloadInterval = flag.Duration("config-reload-interval", time.Minute, "Interval at which --config is checked for changes. Default is 1 minute.")
+
+	warmUpTimeout = flag.Duration("warm-up-timeout", 30*time.Minute, "Maximum time an export whose class sets csi.storage.k8s.io/export-warm-up may stay warming before its Warming condition reports a timeout. Separate from --timeout, which bounds each CSI call. Default is 30 minutes.")
+
+	auditLogPath        = flag.String("audit-log-path", "", "Path of a file to which a JSON line is appended for each create, delete and update of an export issued to the CSI driver, with its driver, handle, VolumeNfsExportContent, VolumeNfsExport, requesting user, result and duration. The requesting user is recorded by the validation webhook run with --record-requesters. The default is empty string, which means no audit log file is written. Only one of `--audit-log-path` and `--audit-webhook-url` can be set.")
+	auditWebhookURL     = flag.String("audit-webhook-url", "", "URL to which the audit records described in --audit-log-path are POSTed as JSON, one per request. Records are sent asynchronously and dropped if the webhook falls behind. The default is empty string, which means no audit webhook is used.")
+	auditWebhookTimeout = flag.Duration("audit-webhook-timeout", 10*time.Second, "Timeout of each request to --audit-webhook-url. Default is 10 seconds.")
+
+	// Deprecated, replaced by feature gates. See pkg/features.
+	_ = flag.Bool("static-contents-ready", false, "(deprecated) Marks pre-provisioned VolumeNfsExportContents without a VolumeNfsExportClass ready to use without getting the status of their nfsexport from the CSI driver, for static setups with drivers that expose no RPC to get it. Their creation time is the creation timestamp of the VolumeNfsExportContent and their restore size is 0. Use --feature-gates=StaticContentsReady=true instead.")
+
+	watchKMSKeySecrets = flag.Bool("watch-kms-key-secrets", false, "Watches the Secrets to read the KMS key Secrets of the VolumeNfsExportClasses encrypting exports at rest from a cache, instead of getting them from the API server whenever a content is synced. Requires list and watch permissions on Secrets in all namespaces.")
+
+	eventTemplatesPath = flag.String("event-templates", "", "Path of a YAML file mapping event reasons to Go text templates of the event messages, e.g. to link the events to runbooks. A template gets the .Type, .Reason, .Message, .Kind, .Namespace and .Name of the event, .Message being the default message. The reasons of the events are not changed. The default is empty string, which means events keep their default messages.")
 )
 
 var (
@@ -94,14 +144,38 @@ var (
 
 func main() {
 	klog.InitFlags(nil)
+	features.AddFlag(flag.CommandLine)
 	flag.Set("logtostderr", "true")
 	flag.Parse()
 
 	if *showVersion {
-		fmt.Println(os.Args[0], version)
+		fmt.Println(os.Args[0], buildinfo.Get(version))
 		os.Exit(0)
 	}
-	klog.Infof("Version: %s", version)
+	klog.Infof("Version: %s", buildinfo.Get(version))
+
+	var cfg *configfile.ConfigFile
+	var err error
+	if *configFile != "" {
+		if cfg, err = configfile.Load(flag.CommandLine, *configFile); err != nil {
+			klog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if err := features.SetFromDeprecatedFlags(flag.CommandLine); err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+	if *contentResyncPeriod, err = utils.ResyncPeriod(flag.CommandLine, "content-resync-period"); err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if *auditLogPath != "" && *auditWebhookURL != "" {
+		klog.Error("Only one of `--audit-log-path` and `--audit-webhook-url` can be set.")
+		os.Exit(1)
+	}
 
 	// If distributed nfsexportting is enabled and leaderElection is also set to true, return
 	if *enableNodeDeployment && *leaderElection {
@@ -119,32 +193,68 @@ func main() {
 	config.QPS = (float32)(*kubeAPIQPS)
 	config.Burst = *kubeAPIBurst
 
-	kubeClient, err := kubernetes.NewForConfig(config)
+	// Informers, status updates and the other writes each get their own
+	// client, so that they are throttled separately, with a user agent
+	// telling them apart.
+	readConfig := utils.SubsystemConfig(config, utils.UserAgentInformers, float32(*kubeAPIReadQPS), *kubeAPIReadBurst)
+	statusConfig := utils.SubsystemConfig(config, utils.UserAgentStatus, float32(*kubeAPIStatusQPS), *kubeAPIStatusBurst)
+	writeConfig := utils.SubsystemConfig(config, utils.UserAgentWrites, 0, 0)
+	if *dryRun {
+		// Writes, including status updates and events, are only validated
+		// by the API server. Leader election still persists its lease.
+		writeConfig = utils.DryRunConfig(writeConfig)
+		statusConfig = utils.DryRunConfig(statusConfig)
+	}
+
+	// Clients throttled by --kube-api-qps and --kube-api-burst follow their
+	// changes in the config file.
+	apiRateLimits, err := utils.NewReloadableRateLimits(config.QPS, config.Burst)
+	if err != nil {
+		klog.Errorf("Invalid --kube-api-qps or --kube-api-burst: %v", err)
+		os.Exit(1)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(apiRateLimits.ClientConfig(writeConfig, 0))
 	if err != nil {
 		klog.Error(err.Error())
 		os.Exit(1)
 	}
 
-	snapClient, err := clientset.NewForConfig(config)
+	snapClient, err := clientset.NewForConfig(apiRateLimits.ClientConfig(writeConfig, 0))
 	if err != nil {
 		klog.Errorf("Error building nfsexport clientset: %s", err.Error())
 		os.Exit(1)
 	}
 
-	factory := informers.NewSharedInformerFactory(snapClient, *resyncPeriod)
-	coreFactory := coreinformers.NewSharedInformerFactory(kubeClient, *resyncPeriod)
-	var nfsexportContentfactory informers.SharedInformerFactory
+	statusSnapClient, err := clientset.NewForConfig(apiRateLimits.ClientConfig(statusConfig, float32(*kubeAPIStatusQPS)))
+	if err != nil {
+		klog.Errorf("Error building nfsexport status clientset: %s", err.Error())
+		os.Exit(1)
+	}
+
+	readKubeClient, err := kubernetes.NewForConfig(apiRateLimits.ClientConfig(readConfig, float32(*kubeAPIReadQPS)))
+	if err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	readSnapClient, err := clientset.NewForConfig(apiRateLimits.ClientConfig(readConfig, float32(*kubeAPIReadQPS)))
+	if err != nil {
+		klog.Errorf("Error building read-only nfsexport clientset: %s", err.Error())
+		os.Exit(1)
+	}
+
+	factory := informers.NewSharedInformerFactory(readSnapClient, *contentResyncPeriod)
+	coreFactory := coreinformers.NewSharedInformerFactory(readKubeClient, *contentResyncPeriod)
+	// Labels of the contents cached by the content informer, which caches
+	// all contents if empty.
+	contentLabels := labels.Set{}
 	if *enableNodeDeployment {
 		node := os.Getenv("NODE_NAME")
 		if node == "" {
 			klog.Fatal("The NODE_NAME environment variable must be set when using --enable-node-deployment.")
 		}
-		nfsexportContentfactory = informers.NewSharedInformerFactoryWithOptions(snapClient, *resyncPeriod, informers.WithTweakListOptions(func(lo *v1.ListOptions) {
-			lo.LabelSelector = labels.Set{utils.VolumeNfsExportContentManagedByLabel: node}.AsSelector().String()
-		}),
-		)
-	} else {
-		nfsexportContentfactory = factory
+		contentLabels[utils.VolumeNfsExportContentManagedByLabel] = node
 	}
 
 	// Add NfsExport types to the default Kubernetes so events can be logged for them
@@ -158,60 +268,128 @@ func main() {
 	if addr == "" {
 		addr = *httpEndpoint
 	}
+	if *disableHTTPEndpoint && addr != "" {
+		klog.Infof("Not starting the HTTP server at %s, --disable-http-endpoint is set", addr)
+		addr, *httpEndpoint = "", ""
+	}
 
 	// Connect to CSI.
 	metricsManager := metrics.NewCSIMetricsManager("" /* driverName */)
-	csiConn, err := connection.Connect(
-		*csiAddress,
-		metricsManager,
-		connection.OnConnectionLoss(connection.ExitOnConnectionLoss()))
-	if err != nil {
-		klog.Errorf("error connecting to CSI driver: %v", err)
-		os.Exit(1)
-	}
+	features.RegisterMetrics(metricsManager.GetRegistry())
+	buildinfo.RegisterMetrics(metricsManager.GetRegistry(), buildinfo.Get(version))
 
 	// Pass a context with a timeout
 	ctx, cancel := context.WithTimeout(context.Background(), *csiTimeout)
 	defer cancel()
 
-	// Find driver name
-	driverName, err := csirpc.GetDriverName(ctx, csiConn)
-	if err != nil {
-		klog.Errorf("error getting CSI driver name: %v", err)
-		os.Exit(1)
+	var csiConn *grpc.ClientConn
+	var driverName string
+	if *dryRun {
+		if *dryRunDriverName == "" {
+			klog.Error("--dry-run-driver-name must be set when --dry-run is set")
+			os.Exit(1)
+		}
+		driverName = *dryRunDriverName
+		klog.Warningf("Running in dry-run mode, CSI calls for driver %q are recorded to stdout instead of being issued and API writes are not persisted", driverName)
+	} else {
+		if csiconnection.IsRemote(*csiAddress) && !*leaderElection {
+			klog.Warningf("Connecting to the remote CSI driver at %s without --leader-election, make sure that a single replica of the sidecar runs", *csiAddress)
+		}
+		csiConn, err = csiconnection.Connect(*csiAddress, metricsManager, csiconnection.Options{
+			CAFile:                    *csiTLSCAFile,
+			CertFile:                  *csiTLSCertFile,
+			KeyFile:                   *csiTLSKeyFile,
+			ServerName:                *csiTLSServerName,
+			Insecure:                  *csiInsecure,
+			KeepaliveTime:             *csiKeepaliveTime,
+			DialTimeout:               *csiDialTimeout,
+			ReconnectOnConnectionLoss: *csiReconnect,
+		})
+		if err != nil {
+			klog.Errorf("error connecting to CSI driver: %v", err)
+			os.Exit(1)
+		}
+
+		// Find driver name
+		driverName, err = csirpc.GetDriverName(ctx, csiConn)
+		if err != nil {
+			klog.Errorf("error getting CSI driver name: %v", err)
+			os.Exit(1)
+		}
 	}
 
 	klog.V(2).Infof("CSI driver name: %q", driverName)
 
+	if features.Enabled(features.DriverScopedContentInformer) {
+		if errs := validation.IsValidLabelValue(driverName); len(errs) > 0 {
+			klog.Errorf("CSI driver name %q cannot be used with the %s feature gate: %s", driverName, features.DriverScopedContentInformer, strings.Join(errs, ", "))
+			os.Exit(1)
+		}
+		contentLabels[utils.VolumeNfsExportContentDriverLabel] = driverName
+	}
+	nfsexportContentfactory := factory
+	if len(contentLabels) > 0 {
+		contentSelector := contentLabels.AsSelector().String()
+		nfsexportContentfactory = informers.NewSharedInformerFactoryWithOptions(readSnapClient, *contentResyncPeriod, informers.WithTweakListOptions(func(lo *v1.ListOptions) {
+			lo.LabelSelector = contentSelector
+		}),
+		)
+	}
+
 	// Prepare http endpoint for metrics + leader election healthz
 	mux := http.NewServeMux()
 	if addr != "" {
 		metricsManager.RegisterToServer(mux, *metricsPath)
+		mux.Handle(buildinfo.VersionPath, buildinfo.Handler(buildinfo.Get(version)))
 		metricsManager.SetDriverName(driverName)
+		listener, err := httpendpoint.Listen(addr, httpendpoint.Options{
+			CertFile:     *httpTLSCertFile,
+			KeyFile:      *httpTLSKeyFile,
+			ClientCAFile: *httpTLSClientCAFile,
+		})
+		if err != nil {
+			klog.Errorf("Failed to listen at %q: %v", addr, err)
+			os.Exit(1)
+		}
 		go func() {
 			klog.Infof("ServeMux listening at %q", addr)
-			err := http.ListenAndServe(addr, mux)
+			err := http.Serve(listener, mux)
 			if err != nil {
 				klog.Fatalf("Failed to start HTTP server at specified address (%q) and metrics path (%q): %s", addr, *metricsPath, err)
 			}
 		}()
 	}
 
-	// Check it's ready
-	if err = csirpc.ProbeForever(csiConn, *csiTimeout); err != nil {
-		klog.Errorf("error waiting for CSI driver to be ready: %v", err)
-		os.Exit(1)
-	}
+	if !*dryRun {
+		// Check it's ready
+		if err = csirpc.ProbeForever(csiConn, *csiTimeout); err != nil {
+			klog.Errorf("error waiting for CSI driver to be ready: %v", err)
+			os.Exit(1)
+		}
 
-	// Find out if the driver supports create/delete nfsexport.
-	supportsCreateNfsExport, err := supportsControllerCreateNfsExport(ctx, csiConn)
-	if err != nil {
-		klog.Errorf("error determining if driver supports create/delete nfsexport operations: %v", err)
-		os.Exit(1)
+		// Find out if the driver supports create/delete nfsexport.
+		supportsCreateNfsExport, err := supportsControllerCreateNfsExport(ctx, csiConn)
+		if err != nil {
+			klog.Errorf("error determining if driver supports create/delete nfsexport operations: %v", err)
+			os.Exit(1)
+		}
+		if !supportsCreateNfsExport {
+			klog.Errorf("CSI driver %s does not support ControllerCreateNfsExport", driverName)
+			os.Exit(1)
+		}
 	}
-	if !supportsCreateNfsExport {
-		klog.Errorf("CSI driver %s does not support ControllerCreateNfsExport", driverName)
-		os.Exit(1)
+
+	// Discover the driver version and capabilities to publish them on the
+	// classes of the driver. Sidecars deployed on nodes do not publish them,
+	// as they could be running different versions of the driver.
+	var driverInfo *crdv1.NfsExportDriverInfo
+	if !*dryRun && !*enableNodeDeployment {
+		infoCtx, infoCancel := context.WithTimeout(context.Background(), *csiTimeout)
+		driverInfo, err = nfsexporter.GetDriverInfo(infoCtx, csiConn)
+		infoCancel()
+		if err != nil {
+			klog.Warningf("error getting CSI driver info, it will not be published on the VolumeNfsExportClasses: %v", err)
+		}
 	}
 
 	if len(*nfsexportNamePrefix) == 0 {
@@ -219,23 +397,96 @@ func main() {
 		os.Exit(1)
 	}
 
-	klog.V(2).Infof("Start NewCSINfsExportSideCarController with nfsexporter [%s] kubeconfig [%s] csiTimeout [%+v] csiAddress [%s] resyncPeriod [%+v] nfsexportNamePrefix [%s] nfsexportNameUUIDLength [%d]", driverName, *kubeconfig, *csiTimeout, *csiAddress, *resyncPeriod, *nfsexportNamePrefix, nfsexportNameUUIDLength)
+	klog.V(2).Infof("Start NewCSINfsExportSideCarController with nfsexporter [%s] kubeconfig [%s] csiTimeout [%+v] csiAddress [%s] contentResyncPeriod [%+v] nfsexportNamePrefix [%s] nfsexportNameUUIDLength [%d]", driverName, *kubeconfig, *csiTimeout, *csiAddress, *contentResyncPeriod, *nfsexportNamePrefix, nfsexportNameUUIDLength)
 
 	nfsExporter := nfsexporter.NewNfsExportter(csiConn)
+	if *dryRun {
+		nfsExporter = nfsexporter.NewDryRunNfsExportter(driverName, os.Stdout)
+	}
+
+	batchSize := *deleteBatchSize
+	if batchSize > 1 {
+		bulkDeleter, ok := nfsExporter.(nfsexporter.BulkNfsExportDeleter)
+		supported := false
+		if ok {
+			bulkCtx, bulkCancel := context.WithTimeout(context.Background(), *csiTimeout)
+			supported, err = bulkDeleter.SupportsDeleteNfsExports(bulkCtx)
+			bulkCancel()
+			if err != nil {
+				klog.Errorf("error determining if driver supports DeleteNfsExports: %v", err)
+				os.Exit(1)
+			}
+		}
+		if !supported {
+			klog.Warningf("CSI driver %s does not support DeleteNfsExports, nfsexports are deleted one by one", driverName)
+			batchSize = 0
+		}
+	}
 	ctrl := controller.NewCSINfsExportSideCarController(
 		snapClient,
+		statusSnapClient,
 		kubeClient,
 		driverName,
 		nfsexportContentfactory.NfsExport().V1().VolumeNfsExportContents(),
 		factory.NfsExport().V1().VolumeNfsExportClasses(),
 		nfsExporter,
 		*csiTimeout,
-		*resyncPeriod,
+		*contentResyncPeriod,
 		*nfsexportNamePrefix,
 		*nfsexportNameUUIDLength,
 		*extraCreateMetadata,
 		workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+		batchSize,
+		*deleteBatchWindow,
+		*warmUpTimeout,
 	)
+	if *auditLogPath != "" {
+		sink, err := audit.NewFileSink(*auditLogPath)
+		if err != nil {
+			klog.Errorf("Failed to open the audit log: %v", err)
+			os.Exit(1)
+		}
+		ctrl.SetAuditSink(sink)
+	} else if *auditWebhookURL != "" {
+		ctrl.SetAuditSink(audit.NewWebhookSink(*auditWebhookURL, *auditWebhookTimeout))
+	}
+	ctrl.SetStaticContentsReady(features.Enabled(features.StaticContentsReady))
+	ctrl.SetClaimInformer(coreFactory.Core().V1().PersistentVolumeClaims())
+	if *watchKMSKeySecrets {
+		ctrl.SetSecretInformer(coreFactory.Core().V1().Secrets())
+	}
+	if *eventTemplatesPath != "" {
+		templates, err := eventtemplates.Load(*eventTemplatesPath)
+		if err != nil {
+			klog.Errorf("Failed to load the event templates: %v", err)
+			os.Exit(1)
+		}
+		ctrl.SetEventTemplates(templates)
+	}
+
+	var driverInfoPublisher *controller.DriverInfoPublisher
+	if driverInfo != nil {
+		driverInfoPublisher = controller.NewDriverInfoPublisher(
+			statusSnapClient,
+			driverName,
+			driverInfo,
+			factory.NfsExport().V1().VolumeNfsExportClasses(),
+			workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+		)
+	}
+
+	if cfg != nil {
+		cfg.OnReload(func() {}, "v", "vmodule")
+		cfg.OnReload(func() {
+			if err := apiRateLimits.Set(float32(*kubeAPIQPS), *kubeAPIBurst); err != nil {
+				klog.Errorf("Failed to reload the API rate limits: %v", err)
+			}
+		}, "kube-api-qps", "kube-api-burst")
+		cfg.OnReload(func() {
+			ctrl.SetCSITimeout(*csiTimeout)
+		}, "timeout")
+		go cfg.Watch(*configReloadInterval, make(chan struct{}))
+	}
 
 	run := func(context.Context) {
 		// run...
@@ -244,6 +495,12 @@ func main() {
 		factory.Start(stopCh)
 		coreFactory.Start(stopCh)
 		go ctrl.Run(*threads, stopCh)
+		if csiConn != nil {
+			go csiconnection.WatchReconnects(csiConn, stopCh, ctrl.EnqueueBeingCreatedContents)
+		}
+		if driverInfoPublisher != nil {
+			go driverInfoPublisher.Run(stopCh)
+		}
 
 		// ...until SIGINT
 		c := make(chan os.Signal, 1)
@@ -258,7 +515,7 @@ func main() {
 		lockName := fmt.Sprintf("%s-%s", prefix, strings.Replace(driverName, "/", "-", -1))
 		// Create a new clientset for leader election to prevent throttling
 		// due to nfsexport sidecar
-		leClientset, err := kubernetes.NewForConfig(config)
+		leClientset, err := kubernetes.NewForConfig(apiRateLimits.ClientConfig(utils.SubsystemConfig(config, utils.UserAgentLeaderElection, 0, 0), 0))
 		if err != nil {
 			klog.Fatalf("failed to create leaderelection client: %v", err)
 		}
@@ -20,29 +20,46 @@ import (
 	"context"
 	"flag"
 	"fmt"
-	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"time"
 
+	apiv1 "k8s.io/api/core/v1"
 	v1 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	klog "k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	"github.com/kubernetes-csi/csi-lib-utils/leaderelection"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/buildinfo"
 	controller "github.com/kubernetes-csi/external-nfsexporter/v6/pkg/common-controller"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/configfile"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/contentview"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/crds"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/eventtemplates"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/exportset"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/features"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/httpendpoint"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/metrics"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/replication"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/summary"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
 	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
 	nfsexportscheme "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/scheme"
 	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
@@ -51,10 +68,13 @@ import (
 
 // Command line flags
 var (
-	kubeconfig   = flag.String("kubeconfig", "", "Absolute path to the kubeconfig file. Required only when running out of cluster.")
-	resyncPeriod = flag.Duration("resync-period", 15*time.Minute, "Resync interval of the controller.")
-	showVersion  = flag.Bool("version", false, "Show version.")
-	threads      = flag.Int("worker-threads", 10, "Number of worker threads.")
+	kubeconfig            = flag.String("kubeconfig", "", "Absolute path to the kubeconfig file. Required only when running out of cluster.")
+	nfsexportResyncPeriod = flag.Duration("nfsexport-resync-period", 15*time.Minute, "Resync interval of the VolumeNfsExports and of the other resources watched by the controller, except VolumeNfsExportContents. 0 disables the periodic resync, relying on watch bookmarks to keep the informers up to date. Default is 15 minutes.")
+	contentResyncPeriod   = flag.Duration("content-resync-period", 15*time.Minute, "Resync interval of the VolumeNfsExportContents. Contents are cluster-scoped and usually more numerous than VolumeNfsExports, so a longer interval reduces the load of the resync. 0 disables the periodic resync, relying on watch bookmarks to keep the informer up to date. Default is 15 minutes.")
+	showVersion           = flag.Bool("version", false, "Show version.")
+	threads               = flag.Int("worker-threads", 10, "Number of worker threads.")
+	// Deprecated, replaced by --nfsexport-resync-period and --content-resync-period.
+	_ = flag.Duration(utils.DeprecatedResyncPeriodFlag, 15*time.Minute, "(deprecated) Resync interval of the controller. Use --nfsexport-resync-period and --content-resync-period instead. If set, it is used for whichever of them is not set.")
 
 	leaderElection              = flag.Bool("leader-election", false, "Enables leader election.")
 	leaderElectionNamespace     = flag.String("leader-election-namespace", "", "The namespace where the leader election resource exists. Defaults to the pod namespace if not set.")
@@ -65,12 +85,51 @@ var (
 	kubeAPIQPS   = flag.Float64("kube-api-qps", 5, "QPS to use while communicating with the kubernetes apiserver. Defaults to 5.0.")
 	kubeAPIBurst = flag.Int("kube-api-burst", 10, "Burst to use while communicating with the kubernetes apiserver. Defaults to 10.")
 
-	httpEndpoint                  = flag.String("http-endpoint", "", "The TCP network address where the HTTP server for diagnostics, including metrics, will listen (example: :8080). The default is empty string, which means the server is disabled.")
+	kubeAPIReadQPS     = flag.Float64("kube-api-read-qps", 0, "QPS to use for the list and watch requests of the informers. They are throttled separately from the other requests. The default is 0, which means --kube-api-qps is used.")
+	kubeAPIReadBurst   = flag.Int("kube-api-read-burst", 0, "Burst to use for the list and watch requests of the informers. Only used if --kube-api-read-qps is set.")
+	kubeAPIStatusQPS   = flag.Float64("kube-api-status-qps", 0, "QPS to use for status updates of VolumeNfsExports. They are throttled separately from the other requests, so that a burst of status updates does not delay deletions. The default is 0, which means --kube-api-qps is used.")
+	kubeAPIStatusBurst = flag.Int("kube-api-status-burst", 0, "Burst to use for status updates of VolumeNfsExports. Only used if --kube-api-status-qps is set.")
+
+	httpEndpoint                  = flag.String("http-endpoint", "", "The TCP network address (example: :8080) or the unix socket (example: unix:///run/nfsexport/http.sock) where the HTTP server for diagnostics, including metrics, will listen. The default is empty string, which means the server is disabled.")
+	disableHTTPEndpoint           = flag.Bool("disable-http-endpoint", false, "Do not start the HTTP server for diagnostics even if --http-endpoint is set, for environments that forbid additional listeners.")
+	httpTLSCertFile               = flag.String("http-tls-cert-file", "", "PEM file of the certificate of the HTTP server for diagnostics at a TCP --http-endpoint. TLS is used when it is set. Requires --http-tls-key-file. It is reloaded when it changes.")
+	httpTLSKeyFile                = flag.String("http-tls-key-file", "", "PEM file of the key of --http-tls-cert-file.")
+	httpTLSClientCAFile           = flag.String("http-tls-client-ca-file", "", "PEM file of the certificate authorities used to verify the client certificates required by the HTTP server for diagnostics, for mutual TLS. Requires --http-tls-cert-file. It is reloaded when it changes.")
 	metricsPath                   = flag.String("metrics-path", "/metrics", "The HTTP path where prometheus metrics will be exposed. Default is `/metrics`.")
 	retryIntervalStart            = flag.Duration("retry-interval-start", time.Second, "Initial retry interval of failed volume nfsexport creation or deletion. It doubles with each failure, up to retry-interval-max. Default is 1 second.")
 	retryIntervalMax              = flag.Duration("retry-interval-max", 5*time.Minute, "Maximum retry interval of failed volume nfsexport creation or deletion. Default is 5 minutes.")
-	enableDistributedNfsExportting = flag.Bool("enable-distributed-nfsexportting", false, "Enables each node to handle nfsexportting for the local volumes created on that node")
-	preventVolumeModeConversion   = flag.Bool("prevent-volume-mode-conversion", false, "Prevents an unauthorised user from modifying the volume mode when creating a PVC from an existing VolumeNfsExport.")
+	apiServerBackoffStart         = flag.Duration("apiserver-backoff-start", time.Second, "Initial time both work queues back off when the API server answers a request of the controller with 429 Too Many Requests, or the Retry-After of the answer if longer. It doubles while the API server keeps throttling the controller, up to apiserver-backoff-max. 0 disables the backoff. Default is 1 second.")
+	apiServerBackoffMax           = flag.Duration("apiserver-backoff-max", time.Minute, "Maximum time both work queues back off while the API server throttles the controller. Default is 1 minute.")
+	// Deprecated, replaced by feature gates. See pkg/features.
+	_                             = flag.Bool("enable-distributed-nfsexportting", false, "(deprecated) Enables each node to handle nfsexportting for the local volumes created on that node. Use --feature-gates=DistributedExporting=true instead.")
+	_                             = flag.Bool("prevent-volume-mode-conversion", false, "(deprecated) Prevents an unauthorised user from modifying the volume mode when creating a PVC from an existing VolumeNfsExport. Use --feature-gates=PreventVolumeModeConversion=true instead.")
+	_                             = flag.Bool("enable-nfsexport-summaries", false, "(deprecated) Maintains a NfsExportSummary named nfsexport-summary in each namespace with VolumeNfsExports, counting the VolumeNfsExports that are ready, pending and failed, so that tenants can monitor them without permission to list VolumeNfsExports or VolumeNfsExportContents. Requires the NfsExportSummary CRD and permission to manage nfsexportsummaries. Use --feature-gates=NfsExportSummaries=true instead.")
+	_                             = flag.Bool("enable-nfsexport-content-views", false, "(deprecated) Maintains a NfsExportContentView with the name of each bound VolumeNfsExport in its namespace, showing whether its export is ready, its size, creation time, server and path, so that the users of the namespace can see them without permission to read VolumeNfsExportContents. Requires the NfsExportContentView CRD and permission to manage nfsexportcontentviews. Use --feature-gates=NfsExportContentViews=true instead.")
+	_                             = flag.Bool("enable-nfsexport-sets", false, "(deprecated) Maintains the VolumeNfsExports of the NfsExportSets: one of each PersistentVolumeClaim matched by the selector of a set, created from its template, which is deleted when the claim stops matching or is deleted. Requires the NfsExportSet CRD and permission to manage nfsexportsets. Cannot be combined with --content-only. Use --feature-gates=NfsExportSets=true instead.")
+	_                             = flag.Bool("fallback-to-default-class", false, "(deprecated) If the VolumeNfsExportClass of a VolumeNfsExport is deleted before its export is created, switch the VolumeNfsExport to the default VolumeNfsExportClass of the driver of its source volume. If false, or if there is no single default class, the VolumeNfsExport is marked Failed with the ClassDeleted reason. Use --feature-gates=FallbackToDefaultClass=true instead.")
+	labelInvalidObjects           = flag.Bool("label-invalid-objects", true, "Label VolumeNfsExports and VolumeNfsExportContents that fail validation, and remove the label once they pass. If false, invalid objects are only logged and existing labels are left untouched.")
+	invalidLabelToggleLimit       = flag.Int("invalid-label-toggle-limit", 10, "Maximum number of times per hour the invalid label of the same VolumeNfsExport or VolumeNfsExportContent is added or removed. Beyond it, the label is left as it is and a VolumeNfsExport gets an InvalidFlapping condition. 0 disables the limit. Only used if --label-invalid-objects is set.")
+	enablePVInformer              = flag.Bool("enable-pv-informer", false, "Enables a PersistentVolume informer so that source volumes are read from a cache instead of the API server on every sync.")
+	enablePodInformer             = flag.Bool("enable-pod-informer", false, "Enables a Pod informer so that the pods using the source PVC of a VolumeNfsExport are read from a cache. It is required by the VolumeNfsExportClasses deriving the security context of the export from these pods, asking them to quiesce or waiting for their writers to stop. Requires permission to list and watch pods.")
+	pvInformerDrivers             = flag.String("pv-informer-drivers", "", "Comma separated list of CSI driver names whose PersistentVolumes are cached in full by the PersistentVolume informer. Other PersistentVolumes are cached by name only. The default is empty string, which means PersistentVolumes of all CSI drivers are cached. Only used if --enable-pv-informer is set.")
+	contentEventCoalesceWindow    = flag.Duration("content-event-coalesce-window", 0, "Window during which add and update events for the same VolumeNfsExportContent are coalesced into a single sync. Reduces repeated processing during mass updates at the cost of delaying content syncs by the window. The default is 0, which disables coalescing.")
+	pvcFinalizerSweepInterval     = flag.Duration("pvc-finalizer-sweep-interval", 10*time.Minute, "Interval of the sweep removing the nfsexport source protection finalizer from PersistentVolumeClaims that are not used by any VolumeNfsExport being created, which is left behind if the controller crashes before removing it. 0 disables the sweep. Default is 10 minutes.")
+	neverBoundContentGracePeriod  = flag.Duration("never-bound-content-grace-period", 10*time.Minute, "Grace period after which a dynamically created VolumeNfsExportContent without status is deleted if its VolumeNfsExport no longer exists. Such contents are left behind if the VolumeNfsExport is deleted right after the content is created. The contents are looked for once per grace period. 0 disables the deletion. Default is 10 minutes.")
+	contentOnly                   = flag.Bool("content-only", false, "Runs the controller without PersistentVolumeClaim and PersistentVolume access, for clusters that only use pre-provisioned VolumeNfsExportContents. VolumeNfsExports with a source PersistentVolumeClaim are not provisioned and get a Blocked condition, and the deletion of a VolumeNfsExport does not wait for the PersistentVolumeClaims being restored from it. The persistentvolumes and persistentvolumeclaims RBAC rules can then be dropped. Cannot be combined with --enable-pv-informer or the DistributedExporting feature gate.")
+
+	ensureCRDs                = flag.Bool("ensure-crds", false, "Installs the VolumeNfsExport CRDs bundled with the controller at startup, or upgrades the installed ones to them. Installed CRDs that are newer are left untouched, and the controller exits if objects are stored in a version that is not bundled. Requires permission to get, create and update customresourcedefinitions.")
+	replicationPeerKubeconfig = flag.String("replication-peer-kubeconfig", "", "Absolute path to the kubeconfig file of a peer cluster. If set, ready VolumeNfsExportContents are mirrored into the peer cluster as pre-provisioned contents. The default is empty string, which means replication is disabled.")
+	eventTemplatesPath        = flag.String("event-templates", "", "Path of a YAML file mapping event reasons to Go text templates of the event messages, e.g. to link the events to runbooks. A template gets the .Type, .Reason, .Message, .Kind, .Namespace and .Name of the event, .Message being the default message. The reasons of the events are not changed. The default is empty string, which means events keep their default messages.")
+	replicationClusterID      = flag.String("replication-cluster-id", "", "ID of this cluster, recorded on the contents mirrored into the peer cluster. Required if --replication-peer-kubeconfig is set and must be unique among the clusters replicating into the same peer.")
+	replicationSweepInterval  = flag.Duration("replication-sweep-interval", 15*time.Minute, "Interval at which all the contents mirrored into the peer cluster are checked, so that mirrors whose local content is gone are deleted and changes made in the peer cluster are detected. Only used if --replication-peer-kubeconfig is set. 0 disables the sweep. Default is 15 minutes.")
+	enableGraphEndpoint       = flag.Bool("enable-graph-endpoint", false, "Serves the object graph of the VolumeNfsExports, their contents and classes at /debug/graph on the HTTP endpoint. The graph reveals the objects of all namespaces: the endpoint should be restricted, e.g. with a unix socket or --http-tls-client-ca-file.")
+
+	configFile           = flag.String("config", "", "Path of a YAML config file mapping flag names to their values. Flags set on the command line take precedence. Changes of --v, --vmodule, --kube-api-qps and --kube-api-burst in the config file are applied without a restart, changes of the other flags require one.")
+	configReloadInterval = flag.Duration("config-reload-interval", time.Minute, "Interval at which --config is checked for changes. Default is 1 minute.")
+
+	writeKubeconfig        = flag.String("write-kubeconfig", "", "Absolute path to the kubeconfig file of the credential used for writes. If set, or if --write-impersonate-user is set, informers list and watch with the credential of --kubeconfig or the in-cluster one, which then only needs list and watch permissions, and all other requests use the write credential. The default is empty string, which means --kubeconfig or the in-cluster credential is used for writes.")
+	writeImpersonateUser   = flag.String("write-impersonate-user", "", "User to impersonate for writes, e.g. system:serviceaccount:kube-system:nfsexport-controller-writer. The credential of --write-kubeconfig, or of --kubeconfig if not set, must be allowed to impersonate it. The default is empty string, which means no impersonation.")
+	writeImpersonateGroups = flag.String("write-impersonate-groups", "", "Comma separated list of groups to impersonate for writes. Only used if --write-impersonate-user is set.")
 )
 
 var version = "unknown"
@@ -115,14 +174,50 @@ func ensureCustomResourceDefinitionsExist(client *clientset.Clientset) error {
 
 func main() {
 	klog.InitFlags(nil)
+	features.AddFlag(flag.CommandLine)
 	flag.Set("logtostderr", "true")
 	flag.Parse()
 
 	if *showVersion {
-		fmt.Println(os.Args[0], version)
+		fmt.Println(os.Args[0], buildinfo.Get(version))
 		os.Exit(0)
 	}
-	klog.Infof("Version: %s", version)
+	klog.Infof("Version: %s", buildinfo.Get(version))
+
+	var cfg *configfile.ConfigFile
+	if *configFile != "" {
+		var err error
+		if cfg, err = configfile.Load(flag.CommandLine, *configFile); err != nil {
+			klog.Error(err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if err := features.SetFromDeprecatedFlags(flag.CommandLine); err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+	if *contentOnly && (*enablePVInformer || *enablePodInformer || features.Enabled(features.DistributedExporting)) {
+		klog.Error("--content-only cannot be combined with --enable-pv-informer, --enable-pod-informer or the DistributedExporting feature gate")
+		os.Exit(1)
+	}
+	if *contentOnly && features.Enabled(features.NfsExportSets) {
+		klog.Error("--content-only cannot be combined with the NfsExportSets feature gate")
+		os.Exit(1)
+	}
+	if *disableHTTPEndpoint && *httpEndpoint != "" {
+		klog.Infof("Not starting the HTTP server at %s, --disable-http-endpoint is set", *httpEndpoint)
+		*httpEndpoint = ""
+	}
+	var err error
+	if *nfsexportResyncPeriod, err = utils.ResyncPeriod(flag.CommandLine, "nfsexport-resync-period"); err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+	if *contentResyncPeriod, err = utils.ResyncPeriod(flag.CommandLine, "content-resync-period"); err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
 
 	// Create the client config. Use kubeconfig if given, otherwise assume in-cluster.
 	config, err := buildConfig(*kubeconfig)
@@ -134,28 +229,141 @@ func main() {
 	config.QPS = (float32)(*kubeAPIQPS)
 	config.Burst = *kubeAPIBurst
 
-	kubeClient, err := kubernetes.NewForConfig(config)
+	// Writes use a separate credential when requested, informers keep the
+	// read-only one.
+	writeConfig, err := buildWriteConfig(config)
+	if err != nil {
+		klog.Errorf("Error building write config: %s", err.Error())
+		os.Exit(1)
+	}
+
+	separateWriteCredential := writeConfig != config
+
+	// Both work queues back off while the API server throttles any of the
+	// clients of the controller.
+	var apiServerThrottle *utils.APIServerThrottle
+	if *apiServerBackoffStart > 0 {
+		apiServerThrottle = utils.NewAPIServerThrottle(*apiServerBackoffStart, *apiServerBackoffMax, clock.RealClock{})
+		config.Wrap(apiServerThrottle.WrapTransport)
+		if separateWriteCredential {
+			writeConfig.Wrap(apiServerThrottle.WrapTransport)
+		}
+	}
+
+	// Informers, status updates and the other writes each get their own
+	// client, so that they are throttled separately, with a user agent
+	// telling them apart.
+	readConfig := utils.SubsystemConfig(config, utils.UserAgentInformers, float32(*kubeAPIReadQPS), *kubeAPIReadBurst)
+	statusConfig := utils.SubsystemConfig(writeConfig, utils.UserAgentStatus, float32(*kubeAPIStatusQPS), *kubeAPIStatusBurst)
+	leaderElectionConfig := utils.SubsystemConfig(writeConfig, utils.UserAgentLeaderElection, 0, 0)
+	writeConfig = utils.SubsystemConfig(writeConfig, utils.UserAgentWrites, 0, 0)
+
+	// Clients throttled by --kube-api-qps and --kube-api-burst follow their
+	// changes in the config file.
+	apiRateLimits, err := utils.NewReloadableRateLimits(config.QPS, config.Burst)
+	if err != nil {
+		klog.Errorf("Invalid --kube-api-qps or --kube-api-burst: %v", err)
+		os.Exit(1)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(apiRateLimits.ClientConfig(writeConfig, 0))
 	if err != nil {
 		klog.Error(err.Error())
 		os.Exit(1)
 	}
 
-	snapClient, err := clientset.NewForConfig(config)
+	snapClient, err := clientset.NewForConfig(apiRateLimits.ClientConfig(writeConfig, 0))
 	if err != nil {
 		klog.Errorf("Error building nfsexport clientset: %s", err.Error())
 		os.Exit(1)
 	}
 
-	factory := informers.NewSharedInformerFactory(snapClient, *resyncPeriod)
-	coreFactory := coreinformers.NewSharedInformerFactory(kubeClient, *resyncPeriod)
+	statusSnapClient, err := clientset.NewForConfig(apiRateLimits.ClientConfig(statusConfig, float32(*kubeAPIStatusQPS)))
+	if err != nil {
+		klog.Errorf("Error building nfsexport status clientset: %s", err.Error())
+		os.Exit(1)
+	}
+
+	if separateWriteCredential {
+		klog.Infof("Using a separate credential for writes")
+	}
+	readKubeClient, err := kubernetes.NewForConfig(apiRateLimits.ClientConfig(readConfig, float32(*kubeAPIReadQPS)))
+	if err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+	readSnapClient, err := clientset.NewForConfig(apiRateLimits.ClientConfig(readConfig, float32(*kubeAPIReadQPS)))
+	if err != nil {
+		klog.Errorf("Error building read-only nfsexport clientset: %s", err.Error())
+		os.Exit(1)
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(readSnapClient, *nfsexportResyncPeriod, informers.WithCustomResyncConfig(map[metav1.Object]time.Duration{
+		&crdv1.VolumeNfsExportContent{}: *contentResyncPeriod,
+	}))
+	coreFactory := coreinformers.NewSharedInformerFactory(readKubeClient, *nfsexportResyncPeriod)
 	var nodeInformer v1.NodeInformer
 
-	if *enableDistributedNfsExportting {
+	if features.Enabled(features.DistributedExporting) {
 		nodeInformer = coreFactory.Core().V1().Nodes()
 	}
 
+	var pvcInformer v1.PersistentVolumeClaimInformer
+	if !*contentOnly {
+		pvcInformer = coreFactory.Core().V1().PersistentVolumeClaims()
+	}
+	var pvInformer v1.PersistentVolumeInformer
+	if *enablePVInformer {
+		var drivers []string
+		if *pvInformerDrivers != "" {
+			drivers = strings.Split(*pvInformerDrivers, ",")
+		}
+		pvInformer = coreFactory.Core().V1().PersistentVolumes()
+		if err := pvInformer.Informer().SetTransform(utils.CSIPersistentVolumeTransform(drivers)); err != nil {
+			klog.Errorf("Failed to set PersistentVolume informer transform: %s", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	var podInformer v1.PodInformer
+	if *enablePodInformer {
+		podInformer = coreFactory.Core().V1().Pods()
+	}
+
 	// Create and register metrics manager
 	metricsManager := metrics.NewMetricsManager()
+	features.RegisterMetrics(metricsManager.GetRegistry())
+	buildinfo.RegisterMetrics(metricsManager.GetRegistry(), buildinfo.Get(version))
+	cacheStores := map[string]cache.Store{
+		"volumenfsexports":        factory.NfsExport().V1().VolumeNfsExports().Informer().GetStore(),
+		"volumenfsexportcontents": factory.NfsExport().V1().VolumeNfsExportContents().Informer().GetStore(),
+		"volumenfsexportclasses":  factory.NfsExport().V1().VolumeNfsExportClasses().Informer().GetStore(),
+	}
+	if pvcInformer != nil {
+		cacheStores["persistentvolumeclaims"] = pvcInformer.Informer().GetStore()
+	}
+	if pvInformer != nil {
+		cacheStores["persistentvolumes"] = pvInformer.Informer().GetStore()
+	}
+	if nodeInformer != nil {
+		cacheStores["nodes"] = nodeInformer.Informer().GetStore()
+	}
+	if podInformer != nil {
+		cacheStores["pods"] = podInformer.Informer().GetStore()
+	}
+	metrics.RegisterCacheMetrics(metricsManager.GetRegistry(), cacheStores)
+	stuckDeletionStores := map[string]cache.Store{
+		"volumenfsexports":        cacheStores["volumenfsexports"],
+		"volumenfsexportcontents": cacheStores["volumenfsexportcontents"],
+	}
+	if pvcInformer != nil {
+		stuckDeletionStores["persistentvolumeclaims"] = cacheStores["persistentvolumeclaims"]
+	}
+	metrics.RegisterStuckDeletionMetrics(metricsManager.GetRegistry(), stuckDeletionStores, clock.RealClock{})
+	metrics.RegisterRestoreSizeMetrics(metricsManager.GetRegistry(), cacheStores["volumenfsexports"])
+	if apiServerThrottle != nil {
+		metrics.RegisterAPIServerThrottleMetrics(metricsManager.GetRegistry(), apiServerThrottle)
+	}
 	wg := &sync.WaitGroup{}
 
 	mux := http.NewServeMux()
@@ -166,40 +374,164 @@ func main() {
 			os.Exit(1)
 		}
 		klog.Infof("Metrics path successfully registered at %s", *metricsPath)
+		mux.Handle(buildinfo.VersionPath, buildinfo.Handler(buildinfo.Get(version)))
 	}
 
 	// Add NfsExport types to the default Kubernetes so events can be logged for them
 	nfsexportscheme.AddToScheme(scheme.Scheme)
 
-	klog.V(2).Infof("Start NewCSINfsExportController with kubeconfig [%s] resyncPeriod [%+v]", *kubeconfig, *resyncPeriod)
+	klog.V(2).Infof("Start NewCSINfsExportController with kubeconfig [%s] nfsexportResyncPeriod [%+v] contentResyncPeriod [%+v]", *kubeconfig, *nfsexportResyncPeriod, *contentResyncPeriod)
 
 	ctrl := controller.NewCSINfsExportCommonController(
 		snapClient,
+		statusSnapClient,
 		kubeClient,
 		factory.NfsExport().V1().VolumeNfsExports(),
 		factory.NfsExport().V1().VolumeNfsExportContents(),
 		factory.NfsExport().V1().VolumeNfsExportClasses(),
-		coreFactory.Core().V1().PersistentVolumeClaims(),
+		pvcInformer,
+		pvInformer,
 		nodeInformer,
+		podInformer,
 		metricsManager,
-		*resyncPeriod,
+		*nfsexportResyncPeriod,
+		*contentResyncPeriod,
 		workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
 		workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
-		*enableDistributedNfsExportting,
-		*preventVolumeModeConversion,
+		features.Enabled(features.DistributedExporting),
+		features.Enabled(features.PreventVolumeModeConversion),
+		*labelInvalidObjects,
+		*contentEventCoalesceWindow,
+		*pvcFinalizerSweepInterval,
+		*neverBoundContentGracePeriod,
+		*invalidLabelToggleLimit,
+		features.Enabled(features.FallbackToDefaultClass),
 	)
+	if *httpEndpoint != "" {
+		if *enableGraphEndpoint {
+			mux.Handle(controller.GraphPath, ctrl.GraphHandler())
+		}
+		mux.Handle(utils.BacklogPath, ctrl.BacklogHandler())
+	}
+	if apiServerThrottle != nil {
+		ctrl.SetAPIServerThrottle(apiServerThrottle)
+	}
+	if *eventTemplatesPath != "" {
+		templates, err := eventtemplates.Load(*eventTemplatesPath)
+		if err != nil {
+			klog.Errorf("Failed to load the event templates: %v", err)
+			os.Exit(1)
+		}
+		ctrl.SetEventTemplates(templates)
+	}
 
-	if err := ensureCustomResourceDefinitionsExist(snapClient); err != nil {
+	if *ensureCRDs {
+		if err := crds.Ensure(context.TODO(), kubeClient.Discovery().RESTClient()); err != nil {
+			klog.Errorf("Exiting due to failure to install or upgrade CRDs during startup: %+v", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := ensureCustomResourceDefinitionsExist(readSnapClient); err != nil {
 		klog.Errorf("Exiting due to failure to ensure CRDs exist during startup: %+v", err)
 		os.Exit(1)
 	}
 
+	var replicator *replication.Replicator
+	if *replicationPeerKubeconfig != "" {
+		if *replicationClusterID == "" {
+			klog.Error("--replication-cluster-id must be set when --replication-peer-kubeconfig is set")
+			os.Exit(1)
+		}
+		peerConfig, err := buildConfig(*replicationPeerKubeconfig)
+		if err != nil {
+			klog.Errorf("Error building peer cluster config: %s", err.Error())
+			os.Exit(1)
+		}
+		peerConfig.QPS = (float32)(*kubeAPIQPS)
+		peerConfig.Burst = *kubeAPIBurst
+		peerClient, err := clientset.NewForConfig(apiRateLimits.ClientConfig(peerConfig, 0))
+		if err != nil {
+			klog.Errorf("Error building peer cluster nfsexport clientset: %s", err.Error())
+			os.Exit(1)
+		}
+		broadcaster := record.NewBroadcaster()
+		broadcaster.StartLogging(klog.Infof)
+		broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events(apiv1.NamespaceAll)})
+		replicator = replication.NewReplicator(
+			*replicationClusterID,
+			peerClient,
+			broadcaster.NewRecorder(scheme.Scheme, apiv1.EventSource{Component: "nfsexport-replicator"}),
+			factory.NfsExport().V1().VolumeNfsExportContents(),
+			*contentResyncPeriod,
+			*replicationSweepInterval,
+			workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+		)
+	}
+
+	var summarizer *summary.Summarizer
+	if features.Enabled(features.NfsExportSummaries) {
+		summarizer = summary.NewSummarizer(
+			snapClient,
+			factory.NfsExport().V1().VolumeNfsExports(),
+			factory.NfsExport().V1().NfsExportSummaries(),
+			*nfsexportResyncPeriod,
+			workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+		)
+	}
+
+	var projector *contentview.Projector
+	if features.Enabled(features.NfsExportContentViews) {
+		projector = contentview.NewProjector(
+			snapClient,
+			factory.NfsExport().V1().VolumeNfsExports(),
+			factory.NfsExport().V1().VolumeNfsExportContents(),
+			factory.NfsExport().V1().NfsExportContentViews(),
+			*nfsexportResyncPeriod,
+			workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+		)
+	}
+
+	var setReconciler *exportset.Reconciler
+	if features.Enabled(features.NfsExportSets) {
+		setReconciler = exportset.NewReconciler(
+			snapClient,
+			factory.NfsExport().V1().NfsExportSets(),
+			factory.NfsExport().V1().VolumeNfsExports(),
+			pvcInformer,
+			*nfsexportResyncPeriod,
+			workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+		)
+	}
+
+	if cfg != nil {
+		cfg.OnReload(func() {}, "v", "vmodule")
+		cfg.OnReload(func() {
+			if err := apiRateLimits.Set(float32(*kubeAPIQPS), *kubeAPIBurst); err != nil {
+				klog.Errorf("Failed to reload the API rate limits: %v", err)
+			}
+		}, "kube-api-qps", "kube-api-burst")
+		go cfg.Watch(*configReloadInterval, make(chan struct{}))
+	}
+
 	run := func(context.Context) {
 		// run...
 		stopCh := make(chan struct{})
 		factory.Start(stopCh)
 		coreFactory.Start(stopCh)
 		go ctrl.Run(*threads, stopCh)
+		if replicator != nil {
+			go replicator.Run(*threads, stopCh)
+		}
+		if summarizer != nil {
+			go summarizer.Run(*threads, stopCh)
+		}
+		if projector != nil {
+			go projector.Run(*threads, stopCh)
+		}
+		if setReconciler != nil {
+			go setReconciler.Run(*threads, stopCh)
+		}
 
 		// ...until SIGINT
 		c := make(chan os.Signal, 1)
@@ -210,7 +542,11 @@ func main() {
 
 	// start listening & serving http endpoint if set
 	if *httpEndpoint != "" {
-		l, err := net.Listen("tcp", *httpEndpoint)
+		l, err := httpendpoint.Listen(*httpEndpoint, httpendpoint.Options{
+			CertFile:     *httpTLSCertFile,
+			KeyFile:      *httpTLSKeyFile,
+			ClientCAFile: *httpTLSClientCAFile,
+		})
 		if err != nil {
 			klog.Fatalf("failed to listen on address[%s], error[%v]", *httpEndpoint, err)
 		}
@@ -240,7 +576,7 @@ func main() {
 		lockName := "nfsexport-controller-leader"
 		// Create a new clientset for leader election to prevent throttling
 		// due to nfsexport controller
-		leClientset, err := kubernetes.NewForConfig(config)
+		leClientset, err := kubernetes.NewForConfig(apiRateLimits.ClientConfig(leaderElectionConfig, 0))
 		if err != nil {
 			klog.Fatalf("failed to create leaderelection client: %v", err)
 		}
@@ -268,6 +604,34 @@ func buildConfig(kubeconfig string) (*rest.Config, error) {
 	return rest.InClusterConfig()
 }
 
+// buildWriteConfig returns the config of the credential used for writes, or
+// config itself if no separate credential is requested.
+func buildWriteConfig(config *rest.Config) (*rest.Config, error) {
+	if *writeImpersonateGroups != "" && *writeImpersonateUser == "" {
+		return nil, fmt.Errorf("--write-impersonate-groups requires --write-impersonate-user")
+	}
+	if *writeKubeconfig == "" && *writeImpersonateUser == "" {
+		return config, nil
+	}
+	writeConfig := rest.CopyConfig(config)
+	if *writeKubeconfig != "" {
+		var err error
+		writeConfig, err = buildConfig(*writeKubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		writeConfig.QPS = config.QPS
+		writeConfig.Burst = config.Burst
+	}
+	if *writeImpersonateUser != "" {
+		writeConfig.Impersonate = rest.ImpersonationConfig{UserName: *writeImpersonateUser}
+		if *writeImpersonateGroups != "" {
+			writeConfig.Impersonate.Groups = strings.Split(*writeImpersonateGroups, ",")
+		}
+	}
+	return writeConfig, nil
+}
+
 type promklog struct{}
 
 func (pl promklog) Println(v ...interface{}) {
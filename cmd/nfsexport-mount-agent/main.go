@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+
+	klog "k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/buildinfo"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/mountagent"
+
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	nfsexportscheme "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/scheme"
+	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
+)
+
+// Command line flags
+var (
+	kubeconfig   = flag.String("kubeconfig", "", "Absolute path to the kubeconfig file. Required only when running out of cluster.")
+	nodeName     = flag.String("node-name", "", "Name of the node the agent runs on. NfsExportMounts of other nodes are ignored. Defaults to the NODE_NAME environment variable.")
+	allowedRoot  = flag.String("allowed-root", "/mnt/nfsexports", "Absolute path below which NfsExportMounts are mounted. NfsExportMounts with other paths are rejected.")
+	resyncPeriod = flag.Duration("resync-period", 15*time.Minute, "Resync interval of the agent.")
+	showVersion  = flag.Bool("version", false, "Show version.")
+	threads      = flag.Int("worker-threads", 2, "Number of worker threads.")
+
+	kubeAPIQPS   = flag.Float64("kube-api-qps", 5, "QPS to use while communicating with the kubernetes apiserver. Defaults to 5.0.")
+	kubeAPIBurst = flag.Int("kube-api-burst", 10, "Burst to use while communicating with the kubernetes apiserver. Defaults to 10.")
+
+	retryIntervalStart = flag.Duration("retry-interval-start", time.Second, "Initial retry interval of a failed mount or unmount. It doubles with each failure, up to retry-interval-max. Default is 1 second.")
+	retryIntervalMax   = flag.Duration("retry-interval-max", 5*time.Minute, "Maximum retry interval of a failed mount or unmount. Default is 5 minutes.")
+)
+
+var version = "unknown"
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(os.Args[0], buildinfo.Get(version))
+		os.Exit(0)
+	}
+	klog.Infof("Version: %s", buildinfo.Get(version))
+
+	if *nodeName == "" {
+		*nodeName = os.Getenv("NODE_NAME")
+	}
+	if *nodeName == "" {
+		klog.Error("--node-name or the NODE_NAME environment variable must be set")
+		os.Exit(1)
+	}
+
+	if !filepath.IsAbs(*allowedRoot) || filepath.Clean(*allowedRoot) == "/" {
+		klog.Error("--allowed-root must be an absolute path other than /")
+		os.Exit(1)
+	}
+
+	// Create the client config. Use kubeconfig if given, otherwise assume in-cluster.
+	config, err := buildConfig(*kubeconfig)
+	if err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	config.QPS = (float32)(*kubeAPIQPS)
+	config.Burst = *kubeAPIBurst
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	snapClient, err := clientset.NewForConfig(config)
+	if err != nil {
+		klog.Errorf("Error building nfsexport clientset: %s", err.Error())
+		os.Exit(1)
+	}
+
+	factory := informers.NewSharedInformerFactory(snapClient, *resyncPeriod)
+
+	// Add NfsExport types to the default Kubernetes so events can be logged for them
+	nfsexportscheme.AddToScheme(scheme.Scheme)
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(klog.Infof)
+	broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events(apiv1.NamespaceAll)})
+
+	agent := mountagent.NewAgent(
+		*nodeName,
+		*allowedRoot,
+		snapClient,
+		mountagent.NewMounter(),
+		broadcaster.NewRecorder(scheme.Scheme, apiv1.EventSource{Component: "nfsexport-mount-agent", Host: *nodeName}),
+		factory.NfsExport().V1().NfsExportMounts(),
+		factory.NfsExport().V1().VolumeNfsExports(),
+		factory.NfsExport().V1().VolumeNfsExportContents(),
+		workqueue.NewItemExponentialFailureRateLimiter(*retryIntervalStart, *retryIntervalMax),
+	)
+
+	// run...
+	stopCh := make(chan struct{})
+	factory.Start(stopCh)
+	go agent.Run(*threads, stopCh)
+
+	// ...until SIGINT
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	<-c
+	close(stopCh)
+}
+
+func buildConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return rest.InClusterConfig()
+}
@@ -18,13 +18,20 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"os"
 
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/buildinfo"
 	webhook "github.com/kubernetes-csi/external-nfsexporter/v6/pkg/validation-webhook"
 	"k8s.io/klog/v2"
 )
 
+var version = "unknown"
+
 func main() {
 	rootCmd := webhook.CmdWebhook
+	rootCmd.Version = version
+	rootCmd.SetVersionTemplate(fmt.Sprintln(os.Args[0], buildinfo.Get(version)))
 
 	loggingFlags := &flag.FlagSet{}
 	klog.InitFlags(loggingFlags)
@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	klog "k8s.io/klog/v2"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/buildinfo"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/storagemigration"
+)
+
+// Command line flags
+var (
+	kubeconfig           = flag.String("kubeconfig", "", "Absolute path to the kubeconfig file. Required only when running out of cluster.")
+	showVersion          = flag.Bool("version", false, "Show version.")
+	pageSize             = flag.Int64("page-size", 500, "Number of objects listed at a time. Progress is reported after each page.")
+	updateStoredVersions = flag.Bool("update-stored-versions", true, "Reduce status.storedVersions of the CRDs to their storage version once all objects are migrated, so that older versions can be removed from the CRDs.")
+	timeout              = flag.Duration("timeout", time.Hour, "Maximum duration of the migration. Default is 1 hour.")
+
+	kubeAPIQPS   = flag.Float64("kube-api-qps", 5, "QPS to use while communicating with the kubernetes apiserver. Defaults to 5.0.")
+	kubeAPIBurst = flag.Int("kube-api-burst", 10, "Burst to use while communicating with the kubernetes apiserver. Defaults to 10.")
+)
+
+var version = "unknown"
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(os.Args[0], buildinfo.Get(version))
+		os.Exit(0)
+	}
+	klog.Infof("Version: %s", buildinfo.Get(version))
+
+	if *pageSize <= 0 {
+		klog.Error("--page-size must be positive")
+		os.Exit(1)
+	}
+
+	// Create the client config. Use kubeconfig if given, otherwise assume in-cluster.
+	config, err := buildConfig(*kubeconfig)
+	if err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	config.QPS = (float32)(*kubeAPIQPS)
+	config.Burst = *kubeAPIBurst
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		klog.Error(err.Error())
+		os.Exit(1)
+	}
+	// The objects and the CRDs are read and written as JSON, with a REST
+	// client that is not bound to any API group.
+	restClient := kubeClient.Discovery().RESTClient()
+	var crdClient rest.Interface
+	if *updateStoredVersions {
+		crdClient = restClient
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	// Stop on SIGINT: the objects migrated so far stay migrated and a new
+	// run starts over.
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		<-c
+		cancel()
+	}()
+
+	migrator := storagemigration.NewMigrator(restClient, crdClient, *pageSize, func(p storagemigration.Progress) {
+		klog.Infof("Migrating %s: %d migrated, %d skipped, %d failed", p.Resource, p.Migrated, p.Skipped, p.Failed)
+	})
+	progress, err := migrator.Run(ctx)
+	for _, p := range progress {
+		klog.Infof("Migrated %s: %d migrated, %d skipped, %d failed", p.Resource, p.Migrated, p.Skipped, p.Failed)
+	}
+	if err != nil {
+		klog.Errorf("Storage version migration failed: %v", err)
+		os.Exit(1)
+	}
+	klog.Info("Storage version migration completed")
+}
+
+func buildConfig(kubeconfig string) (*rest.Config, error) {
+	if kubeconfig != "" {
+		return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	}
+	return rest.InClusterConfig()
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package audit records the export operations the csi-nfsexporter issues to
+// the CSI driver, one structured Record per operation, so that they can be
+// audited without parsing the logs of the controllers. Records are written
+// as JSON lines to a file, or posted to an HTTP endpoint.
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	klog "k8s.io/klog/v2"
+)
+
+// Operation is an export operation issued to the CSI driver.
+type Operation string
+
+const (
+	OperationCreate Operation = "Create"
+	OperationDelete Operation = "Delete"
+	OperationUpdate Operation = "Update"
+)
+
+// Result is the outcome of an operation.
+type Result string
+
+const (
+	ResultSuccess Result = "Success"
+	ResultFailure Result = "Failure"
+)
+
+// Record is one export operation.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Operation Operation `json:"operation"`
+	Driver    string    `json:"driver"`
+	// NfsExportHandle is the handle of the export on the storage system. It
+	// is empty for a failed creation.
+	NfsExportHandle        string `json:"nfsexportHandle,omitempty"`
+	VolumeNfsExportContent string `json:"volumeNfsExportContent"`
+	// VolumeNfsExport is the namespace/name of the VolumeNfsExport the
+	// operation was issued for, empty for a content without one.
+	VolumeNfsExport    string `json:"volumeNfsExport,omitempty"`
+	VolumeNfsExportUID string `json:"volumeNfsExportUID,omitempty"`
+	// User is the name of the user who requested the export, as recorded
+	// by the validation webhook in the requested-by annotation of the
+	// VolumeNfsExportContent. It is empty if it was not recorded.
+	User   string `json:"user,omitempty"`
+	Result Result `json:"result"`
+	Error  string `json:"error,omitempty"`
+	// DurationSeconds is how long the CSI driver took.
+	DurationSeconds float64 `json:"durationSeconds"`
+}
+
+// Sink receives the records of the operations. Record must not block for
+// long, as it is called by the workers of the controller.
+type Sink interface {
+	Record(r Record)
+}
+
+type fileSink struct {
+	lock sync.Mutex
+	out  *json.Encoder
+}
+
+// NewFileSink returns a Sink appending the records to the file at path as
+// JSON lines. The file is created if needed.
+func NewFileSink(path string) (Sink, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log %s: %v", path, err)
+	}
+	return NewWriterSink(file), nil
+}
+
+// NewWriterSink returns a Sink writing the records to out as JSON lines.
+func NewWriterSink(out io.Writer) Sink {
+	return &fileSink{out: json.NewEncoder(out)}
+}
+
+func (s *fileSink) Record(r Record) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.out.Encode(r); err != nil {
+		klog.Errorf("failed to write audit record of %s operation on %s: %v", r.Operation, r.VolumeNfsExportContent, err)
+	}
+}
+
+// webhookQueueSize is the number of records a webhook sink buffers while the
+// endpoint is slow or unreachable. Further records are dropped.
+const webhookQueueSize = 1000
+
+type webhookSink struct {
+	url    string
+	client *http.Client
+	queue  chan Record
+}
+
+// NewWebhookSink returns a Sink posting each record as JSON to url. Records
+// are posted in order from a background goroutine, so that a slow endpoint
+// does not delay the operations; a record that cannot be posted within
+// timeout is logged and dropped.
+func NewWebhookSink(url string, timeout time.Duration) Sink {
+	s := &webhookSink{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		queue:  make(chan Record, webhookQueueSize),
+	}
+	go s.run()
+	return s
+}
+
+func (s *webhookSink) Record(r Record) {
+	select {
+	case s.queue <- r:
+	default:
+		klog.Errorf("audit webhook queue is full, dropping record of %s operation on %s", r.Operation, r.VolumeNfsExportContent)
+	}
+}
+
+func (s *webhookSink) run() {
+	for r := range s.queue {
+		if err := s.post(r); err != nil {
+			klog.Errorf("failed to post audit record of %s operation on %s to %s: %v", r.Operation, r.VolumeNfsExportContent, s.url, err)
+		}
+	}
+}
+
+func (s *webhookSink) post(r Record) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
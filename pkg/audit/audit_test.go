@@ -0,0 +1,114 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testRecords = []Record{
+	{
+		Time:                   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Operation:              OperationCreate,
+		Driver:                 "csi-mock-plugin",
+		NfsExportHandle:        "handle1",
+		VolumeNfsExportContent: "content1",
+		VolumeNfsExport:        "default/snap1",
+		VolumeNfsExportUID:     "snapuid1",
+		User:                   "alice",
+		Result:                 ResultSuccess,
+		DurationSeconds:        1.5,
+	},
+	{
+		Time:                   time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC),
+		Operation:              OperationDelete,
+		Driver:                 "csi-mock-plugin",
+		NfsExportHandle:        "handle1",
+		VolumeNfsExportContent: "content1",
+		Result:                 ResultFailure,
+		Error:                  "mock delete error",
+	},
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	if err := os.WriteFile(path, []byte("{}\n"), 0600); err != nil {
+		t.Fatalf("failed to write existing audit log: %v", err)
+	}
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, r := range testRecords {
+		sink.Record(r)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 || lines[0] != "{}" {
+		t.Fatalf("expected the records to be appended to the existing log, got %q", lines)
+	}
+	var records []Record
+	for _, line := range lines[1:] {
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("failed to decode %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	if !reflect.DeepEqual(records, testRecords) {
+		t.Errorf("expected records %+v, got %+v", testRecords, records)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan Record, len(testRecords))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var r Record
+		if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+			t.Errorf("failed to decode record: %v", err)
+		}
+		received <- r
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(server.URL, 10*time.Second)
+	for _, r := range testRecords {
+		sink.Record(r)
+	}
+	for _, expected := range testRecords {
+		select {
+		case r := <-received:
+			if !reflect.DeepEqual(r, expected) {
+				t.Errorf("expected record %+v, got %+v", expected, r)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("expected record %+v to be posted", expected)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backup provides hooks for backup tools such as Velero plugins.
+// Instead of polling the CRDs, a backup tool marks the VolumeNfsExports it
+// wants to include with SetBackupInclusion and implements Provider to be
+// called back when a marked nfsexport becomes ready or is deleted. Notifier
+// is the reference implementation that drives a Provider from an informer.
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog/v2"
+)
+
+// AnnBackupInclude marks a VolumeNfsExport for inclusion in backups. Only
+// nfsexports with this annotation set to "true" are reported to a Provider.
+// Users and backup tools may set it despite its reserved prefix.
+const AnnBackupInclude = utils.AnnBackupInclude
+
+// Provider receives callbacks for VolumeNfsExports marked for backup.
+// Callbacks are invoked sequentially and must not block for long.
+type Provider interface {
+	// NfsExportReady is called once when a marked nfsexport is bound and
+	// ready to use.
+	NfsExportReady(nfsexport *crdv1.VolumeNfsExport)
+	// NfsExportDeleted is called when a marked nfsexport that was reported
+	// ready has been deleted.
+	NfsExportDeleted(nfsexport *crdv1.VolumeNfsExport)
+}
+
+// IsIncluded returns true if the nfsexport is marked for backup.
+func IsIncluded(nfsexport *crdv1.VolumeNfsExport) bool {
+	return nfsexport.Annotations[AnnBackupInclude] == "true"
+}
+
+// IsReady returns true if the nfsexport is bound to a content and ready to use.
+func IsReady(nfsexport *crdv1.VolumeNfsExport) bool {
+	return utils.IsBoundVolumeNfsExportContentNameSet(nfsexport) && utils.IsNfsExportReady(nfsexport)
+}
+
+// QueryReady fetches the given VolumeNfsExport and returns whether it is
+// bound and ready to use.
+func QueryReady(client clientset.Interface, namespace, name string) (bool, error) {
+	nfsexport, err := client.NfsExportV1().VolumeNfsExports(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get nfsexport %s/%s: %v", namespace, name, err)
+	}
+	return IsReady(nfsexport), nil
+}
+
+// SetBackupInclusion adds or removes the backup inclusion annotation on the
+// given VolumeNfsExport.
+func SetBackupInclusion(client clientset.Interface, namespace, name string, include bool) error {
+	var value interface{}
+	if include {
+		value = "true"
+	}
+	// A null value removes the annotation in a JSON merge patch.
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				AnnBackupInclude: value,
+			},
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	_, err = client.NfsExportV1().VolumeNfsExports(namespace).Patch(context.TODO(), name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to set backup inclusion of nfsexport %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
+
+// Notifier calls a Provider for the VolumeNfsExports of an informer that
+// are marked for backup.
+type Notifier struct {
+	provider Provider
+	synced   cache.InformerSynced
+
+	lock sync.Mutex
+	// reported holds the UIDs of nfsexports reported ready to the provider.
+	reported map[types.UID]bool
+}
+
+// NewNotifier returns a new *Notifier that reports the nfsexports of the
+// informer to provider.
+func NewNotifier(volumeNfsExportInformer storageinformers.VolumeNfsExportInformer, provider Provider) *Notifier {
+	n := &Notifier{
+		provider: provider,
+		synced:   volumeNfsExportInformer.Informer().HasSynced,
+		reported: make(map[types.UID]bool),
+	}
+	volumeNfsExportInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { n.nfsexportUpdated(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { n.nfsexportUpdated(newObj) },
+			DeleteFunc: func(obj interface{}) { n.nfsexportDeleted(obj) },
+		},
+	)
+	return n
+}
+
+// WaitForCacheSync blocks until the informer has synced or stopCh is closed.
+// It returns false if the informer did not sync.
+func (n *Notifier) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	return cache.WaitForCacheSync(stopCh, n.synced)
+}
+
+func (n *Notifier) nfsexportUpdated(obj interface{}) {
+	nfsexport, ok := obj.(*crdv1.VolumeNfsExport)
+	if !ok {
+		return
+	}
+
+	n.lock.Lock()
+	if !IsIncluded(nfsexport) {
+		// The nfsexport was unmarked; report it again if it is marked again.
+		delete(n.reported, nfsexport.UID)
+		n.lock.Unlock()
+		return
+	}
+	if n.reported[nfsexport.UID] || !IsReady(nfsexport) {
+		n.lock.Unlock()
+		return
+	}
+	n.reported[nfsexport.UID] = true
+	n.lock.Unlock()
+
+	klog.V(4).Infof("backup: nfsexport %s is ready", utils.NfsExportKey(nfsexport))
+	n.provider.NfsExportReady(nfsexport)
+}
+
+func (n *Notifier) nfsexportDeleted(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	nfsexport, ok := obj.(*crdv1.VolumeNfsExport)
+	if !ok {
+		return
+	}
+
+	n.lock.Lock()
+	reported := n.reported[nfsexport.UID]
+	delete(n.reported, nfsexport.UID)
+	n.lock.Unlock()
+
+	if !reported {
+		return
+	}
+	klog.V(4).Infof("backup: nfsexport %s is deleted", utils.NfsExportKey(nfsexport))
+	n.provider.NfsExportDeleted(nfsexport)
+}
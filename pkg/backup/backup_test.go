@@ -0,0 +1,157 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+)
+
+type recordingProvider struct {
+	calls []string
+}
+
+func (p *recordingProvider) NfsExportReady(nfsexport *crdv1.VolumeNfsExport) {
+	p.calls = append(p.calls, "ready "+nfsexport.Name)
+}
+
+func (p *recordingProvider) NfsExportDeleted(nfsexport *crdv1.VolumeNfsExport) {
+	p.calls = append(p.calls, "deleted "+nfsexport.Name)
+}
+
+func newNfsExport(name string, included, ready bool) *crdv1.VolumeNfsExport {
+	contentName := "content-" + name
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       types.UID("uid-" + name),
+		},
+		Status: &crdv1.VolumeNfsExportStatus{
+			BoundVolumeNfsExportContentName: &contentName,
+			ReadyToUse:                      &ready,
+		},
+	}
+	if included {
+		nfsexport.Annotations = map[string]string{AnnBackupInclude: "true"}
+	}
+	return nfsexport
+}
+
+func TestNotifier(t *testing.T) {
+	tests := []struct {
+		name          string
+		events        func(n *Notifier)
+		expectedCalls []string
+	}{
+		{
+			name: "marked nfsexport is reported once when ready",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", true, false))
+				n.nfsexportUpdated(newNfsExport("snap1", true, true))
+				n.nfsexportUpdated(newNfsExport("snap1", true, true))
+			},
+			expectedCalls: []string{"ready snap1"},
+		},
+		{
+			name: "unmarked nfsexport is not reported",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", false, true))
+				n.nfsexportDeleted(newNfsExport("snap1", false, true))
+			},
+		},
+		{
+			name: "reported nfsexport is reported deleted",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", true, true))
+				n.nfsexportDeleted(cache.DeletedFinalStateUnknown{Key: "default/snap1", Obj: newNfsExport("snap1", true, true)})
+			},
+			expectedCalls: []string{"ready snap1", "deleted snap1"},
+		},
+		{
+			name: "nfsexport that never became ready is not reported deleted",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", true, false))
+				n.nfsexportDeleted(newNfsExport("snap1", true, false))
+			},
+		},
+		{
+			name: "nfsexport marked again is reported again",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", true, true))
+				n.nfsexportUpdated(newNfsExport("snap1", false, true))
+				n.nfsexportUpdated(newNfsExport("snap1", true, true))
+			},
+			expectedCalls: []string{"ready snap1", "ready snap1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+			provider := &recordingProvider{}
+			n := NewNotifier(factory.NfsExport().V1().VolumeNfsExports(), provider)
+			test.events(n)
+			if !reflect.DeepEqual(provider.calls, test.expectedCalls) {
+				t.Errorf("expected calls %v, got %v", test.expectedCalls, provider.calls)
+			}
+		})
+	}
+}
+
+func TestSetBackupInclusion(t *testing.T) {
+	client := fake.NewSimpleClientset(newNfsExport("snap1", false, true))
+
+	if err := SetBackupInclusion(client, "default", "snap1", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nfsexport, err := client.NfsExportV1().VolumeNfsExports("default").Get(context.TODO(), "snap1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsIncluded(nfsexport) {
+		t.Errorf("expected nfsexport to be marked for backup, got annotations %v", nfsexport.Annotations)
+	}
+	ready, err := QueryReady(client, "default", "snap1")
+	if err != nil || !ready {
+		t.Errorf("expected nfsexport to be ready, got %v, %v", ready, err)
+	}
+
+	if err := SetBackupInclusion(client, "default", "snap1", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	nfsexport, err = client.NfsExportV1().VolumeNfsExports("default").Get(context.TODO(), "snap1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if IsIncluded(nfsexport) {
+		t.Errorf("expected nfsexport not to be marked for backup, got annotations %v", nfsexport.Annotations)
+	}
+
+	if err := SetBackupInclusion(client, "default", "missing", true); err == nil {
+		t.Errorf("expected error for missing nfsexport, got none")
+	}
+}
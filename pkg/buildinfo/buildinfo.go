@@ -0,0 +1,98 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package buildinfo reports how the binaries of the nfsexporter were built,
+// including the crypto module they use, in their logs, on their /version
+// endpoint and in a build_info metric.
+package buildinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+const (
+	// CryptoModeStandard is the crypto mode of binaries using the crypto of
+	// the Go standard library.
+	CryptoModeStandard = "standard"
+	// CryptoModeBoringCrypto is the crypto mode of binaries built with
+	// GOEXPERIMENT=boringcrypto, which use the BoringCrypto module.
+	CryptoModeBoringCrypto = "boringcrypto"
+	// CryptoModeFIPS is the crypto mode of binaries built with
+	// GOEXPERIMENT=boringcrypto and the fips build tag, which use the
+	// BoringCrypto module and only allow FIPS 140-2 approved TLS settings.
+	CryptoModeFIPS = "fips"
+
+	// VersionPath is the HTTP path of the version endpoint.
+	VersionPath = "/version"
+)
+
+// Info describes a binary.
+type Info struct {
+	// Version is the version of the binary, set by the build with
+	// -X main.version.
+	Version string `json:"version"`
+	// GoVersion is the version of Go the binary was built with.
+	GoVersion string `json:"goVersion"`
+	// Platform is the OS and architecture of the binary, e.g. linux/amd64.
+	Platform string `json:"platform"`
+	// CryptoMode is the crypto module used by the binary, one of
+	// CryptoModeStandard, CryptoModeBoringCrypto or CryptoModeFIPS.
+	CryptoMode string `json:"cryptoMode"`
+}
+
+// Get returns the Info of the running binary of the given version.
+func Get(version string) Info {
+	return Info{
+		Version:    version,
+		GoVersion:  runtime.Version(),
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		CryptoMode: cryptoMode(),
+	}
+}
+
+// String returns the Info as printed by --version and logged at startup.
+func (i Info) String() string {
+	return fmt.Sprintf("%s (%s, %s, crypto: %s)", i.Version, i.GoVersion, i.Platform, i.CryptoMode)
+}
+
+// RegisterMetrics registers a build_info gauge, always 1, whose labels
+// describe info to registry.
+func RegisterMetrics(registry k8smetrics.KubeRegistry, info Info) {
+	buildInfo := k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Subsystem: "nfsexport",
+			Name:      "build_info",
+			Help:      "A metric with a constant '1' value labeled by the version, Go version, platform and crypto mode of the binary",
+		},
+		[]string{"version", "go_version", "platform", "crypto_mode"},
+	)
+	registry.MustRegister(buildInfo)
+	buildInfo.WithLabelValues(info.Version, info.GoVersion, info.Platform, info.CryptoMode).Set(1)
+}
+
+// Handler returns the http handler of the version endpoint, which returns
+// info as JSON.
+func Handler(info Info) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(info)
+	})
+}
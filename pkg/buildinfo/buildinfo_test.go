@@ -0,0 +1,102 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildinfo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+func TestGet(t *testing.T) {
+	info := Get("v6.1.0")
+	expected := Info{
+		Version:    "v6.1.0",
+		GoVersion:  runtime.Version(),
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		CryptoMode: CryptoModeStandard,
+	}
+	if info != expected {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+	if s := info.String(); !strings.HasPrefix(s, "v6.1.0 (") || !strings.HasSuffix(s, "crypto: standard)") {
+		t.Errorf("unexpected string %q", s)
+	}
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	info := Info{Version: "v6.1.0", GoVersion: "go1.18", Platform: "linux/arm64", CryptoMode: CryptoModeFIPS}
+	registry := k8smetrics.NewKubeRegistry()
+	RegisterMetrics(registry, info)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "nfsexport_build_info" {
+			continue
+		}
+		if len(family.GetMetric()) != 1 {
+			t.Fatalf("expected one build_info metric, got %d", len(family.GetMetric()))
+		}
+		metric := family.GetMetric()[0]
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		expected := map[string]string{"version": "v6.1.0", "go_version": "go1.18", "platform": "linux/arm64", "crypto_mode": "fips"}
+		for name, value := range expected {
+			if labels[name] != value {
+				t.Errorf("expected label %s=%q, got %q", name, value, labels[name])
+			}
+		}
+		if value := metric.GetGauge().GetValue(); value != 1 {
+			t.Errorf("expected value 1, got %v", value)
+		}
+		return
+	}
+	t.Errorf("nfsexport_build_info not registered")
+}
+
+func TestHandler(t *testing.T) {
+	info := Info{Version: "v6.1.0", GoVersion: "go1.18", Platform: "linux/amd64", CryptoMode: CryptoModeBoringCrypto}
+	recorder := httptest.NewRecorder()
+	Handler(info).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, VersionPath, nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected Content-Type application/json, got %q", contentType)
+	}
+	var got Info
+	if err := json.Unmarshal(recorder.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != info {
+		t.Errorf("expected %+v, got %+v", info, got)
+	}
+	if !strings.Contains(recorder.Body.String(), `"cryptoMode":"boringcrypto"`) {
+		t.Errorf("unexpected body %s", recorder.Body.String())
+	}
+}
@@ -0,0 +1,31 @@
+//go:build boringcrypto && !fips
+// +build boringcrypto,!fips
+
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildinfo
+
+import "crypto/boring"
+
+// cryptoMode reports the standard crypto when BoringCrypto is not available,
+// e.g. in a binary built with GOEXPERIMENT=boringcrypto but without cgo.
+func cryptoMode() string {
+	if !boring.Enabled() {
+		return CryptoModeStandard
+	}
+	return CryptoModeBoringCrypto
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Backlog returns the number of objects waiting in the work queues of the
+// controller, including the ones waiting for their retry.
+func (ctrl *csiNfsExportCommonController) Backlog() utils.Backlog {
+	return utils.Backlog{
+		NfsExports: queueBacklog(ctrl.nfsexportQueue),
+		Contents:   queueBacklog(ctrl.contentQueue),
+	}
+}
+
+// BacklogHandler returns an http.Handler serving the Backlog of the
+// controller as JSON, for the validation webhook to push back on the
+// creation of VolumeNfsExports while the controller is behind. It responds
+// with 503 until the controller runs, i.e. on the replicas that are not the
+// leader, whose queues are always empty.
+func (ctrl *csiNfsExportCommonController) BacklogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.LoadInt32(&ctrl.running) == 0 {
+			http.Error(w, "the nfsexport controller is not running, it is not the leader", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ctrl.Backlog())
+	})
+}
+
+// queueBacklog returns the number of keys in queue, including the delayed
+// ones if it is a backlogQueue.
+func queueBacklog(queue workqueue.RateLimitingInterface) int {
+	if q, ok := queue.(*backlogQueue); ok {
+		return q.Len() + q.delayed()
+	}
+	return queue.Len()
+}
+
+// backlogQueue is a rate limiting work queue which also counts the keys
+// added with a delay, e.g. by AddRateLimited, until they are ready. The
+// queue of client-go does not count them in Len.
+type backlogQueue struct {
+	workqueue.RateLimitingInterface
+	rateLimiter workqueue.RateLimiter
+	clock       func() time.Time
+
+	lock sync.Mutex
+	// ready is the time at which each delayed key is added to the queue.
+	ready map[interface{}]time.Time
+}
+
+func newBacklogQueue(rateLimiter workqueue.RateLimiter, name string) *backlogQueue {
+	return &backlogQueue{
+		RateLimitingInterface: workqueue.NewNamedRateLimitingQueue(rateLimiter, name),
+		rateLimiter:           rateLimiter,
+		clock:                 time.Now,
+		ready:                 make(map[interface{}]time.Time),
+	}
+}
+
+// AddRateLimited adds item after the delay of the rate limiter, like the
+// queue of client-go does.
+func (q *backlogQueue) AddRateLimited(item interface{}) {
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+// AddAfter adds item after duration. Like the queue, it keeps the earliest
+// time of a key added several times.
+func (q *backlogQueue) AddAfter(item interface{}, duration time.Duration) {
+	if duration > 0 {
+		ready := q.clock().Add(duration)
+		q.lock.Lock()
+		if existing, ok := q.ready[item]; !ok || ready.Before(existing) {
+			q.ready[item] = ready
+		}
+		q.lock.Unlock()
+	}
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+// delayed returns the number of keys which are not yet ready, and forgets
+// the ready ones, which are counted by Len.
+func (q *backlogQueue) delayed() int {
+	now := q.clock()
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for item, ready := range q.ready {
+		if !ready.After(now) {
+			delete(q.ready, item)
+		}
+	}
+	return len(q.ready)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	"k8s.io/client-go/util/workqueue"
+)
+
+func TestBacklogHandler(t *testing.T) {
+	ctrl := &csiNfsExportCommonController{
+		nfsexportQueue: newBacklogQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+		contentQueue:   workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	defer ctrl.nfsexportQueue.ShutDown()
+	defer ctrl.contentQueue.ShutDown()
+	ctrl.nfsexportQueue.Add("default/snap1")
+	ctrl.nfsexportQueue.Add("default/snap2")
+	ctrl.nfsexportQueue.AddAfter("default/snap3", time.Hour)
+	ctrl.nfsexportQueue.AddRateLimited("default/snap4")
+	ctrl.contentQueue.Add("content1")
+
+	recorder := httptest.NewRecorder()
+	ctrl.BacklogHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, utils.BacklogPath, nil))
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503 before the controller runs, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+
+	ctrl.running = 1
+	recorder = httptest.NewRecorder()
+	ctrl.BacklogHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, utils.BacklogPath, nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", recorder.Code, recorder.Body.String())
+	}
+	var backlog utils.Backlog
+	if err := json.Unmarshal(recorder.Body.Bytes(), &backlog); err != nil {
+		t.Fatalf("failed to decode the backlog: %v", err)
+	}
+	expected := utils.Backlog{NfsExports: 4, Contents: 1}
+	if backlog != expected {
+		t.Errorf("expected backlog %+v, got %+v", expected, backlog)
+	}
+}
+
+func TestBacklogQueueDelayed(t *testing.T) {
+	now := time.Now()
+	q := newBacklogQueue(workqueue.DefaultControllerRateLimiter(), "test")
+	defer q.ShutDown()
+	q.clock = func() time.Time { return now }
+
+	q.AddAfter("a", time.Minute)
+	q.AddAfter("a", time.Hour)
+	q.AddAfter("b", time.Hour)
+	q.AddAfter("c", 0)
+	if delayed := q.delayed(); delayed != 2 {
+		t.Errorf("expected 2 delayed keys, got %d", delayed)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if delayed := q.delayed(); delayed != 1 {
+		t.Errorf("expected 1 delayed key once a is ready, got %d", delayed)
+	}
+	now = now.Add(time.Hour)
+	if delayed := q.delayed(); delayed != 0 {
+		t.Errorf("expected no delayed key, got %d", delayed)
+	}
+}
@@ -38,11 +38,14 @@ import (
 	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
 	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/metrics"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/fakeapiserver"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -57,6 +60,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	klog "k8s.io/klog/v2"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 // This is a unit test framework for nfsexport controller.
@@ -104,7 +108,7 @@ type controllerTest struct {
 	// event message.
 	expectedEvents []string
 	// Errors to produce on matching action
-	errors []reactorError
+	errors []fakeapiserver.Hook
 	// Function to call as the test.
 	test          testCall
 	expectSuccess bool
@@ -122,7 +126,7 @@ var (
 	nocontents         []*crdv1.VolumeNfsExportContent
 	nonfsexports        []*crdv1.VolumeNfsExport
 	noevents           = []string{}
-	noerrors           = []reactorError{}
+	noerrors           = []fakeapiserver.Hook{}
 )
 
 // nfsexportReactor is a core.Reactor that simulates etcd and API server. It
@@ -137,11 +141,9 @@ var (
 //   used by the controller. Any time an event function like deleteContentEvent
 //   is called to simulate an event, the reactor's stores are updated and the
 //   controller is sent the event via the fake watcher.
-// - Optionally, list of error that should be returned by reactor, simulating
-//   etcd / API server failures. These errors are evaluated in order and every
-//   error is returned only once. I.e. when the reactor finds matching
-//   reactorError, it return appropriate error and removes the reactorError from
-//   the list.
+// - Optionally, hooks that fail the matching actions, simulating etcd / API
+//   server failures. They are installed on the fake clients before the
+//   reactor, see fakeapiserver.Hooks.
 type nfsexportReactor struct {
 	secrets              map[string]*v1.Secret
 	volumes              map[string]*v1.PersistentVolume
@@ -155,17 +157,7 @@ type nfsexportReactor struct {
 	fakeContentWatch     *watch.FakeWatcher
 	fakeNfsExportWatch    *watch.FakeWatcher
 	lock                 sync.Mutex
-	errors               []reactorError
-}
-
-// reactorError is an error that is returned by test reactor (=simulated
-// etcd+/API server) when an action performed by the reactor matches given verb
-// ("get", "update", "create", "delete" or "*"") on given resource
-// ("volumenfsexportcontents", "volumenfsexports" or "*").
-type reactorError struct {
-	verb     string
-	resource string
-	error    error
+	hooks                *fakeapiserver.Hooks
 }
 
 // testError is an error returned from a test that marks a test as failed even
@@ -197,6 +189,25 @@ func withPVCFinalizer(pvc *v1.PersistentVolumeClaim) *v1.PersistentVolumeClaim {
 	return pvc
 }
 
+// withClaimRestoredFrom sets the data source of claims to the nfsexport, and
+// adds annotations.
+func withClaimRestoredFrom(claims []*v1.PersistentVolumeClaim, nfsexportName string, annotations map[string]string) []*v1.PersistentVolumeClaim {
+	apiGroup := crdv1.GroupName
+	for i := range claims {
+		claims[i].Spec.DataSource = &v1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "VolumeNfsExport", Name: nfsexportName}
+		claims[i].Annotations = annotations
+	}
+	return claims
+}
+
+// withClaimCreationTimestamp sets the creation timestamp of claims.
+func withClaimCreationTimestamp(claims []*v1.PersistentVolumeClaim, creationTimestamp metav1.Time) []*v1.PersistentVolumeClaim {
+	for i := range claims {
+		claims[i].CreationTimestamp = creationTimestamp
+	}
+	return claims
+}
+
 // React is a callback called by fake kubeClient from the controller.
 // In other words, every nfsexport/content change performed by the controller ends
 // here.
@@ -212,13 +223,8 @@ func (r *nfsexportReactor) React(action core.Action) (handled bool, ret runtime.
 
 	klog.V(4).Infof("reactor got operation %q on %q", action.GetVerb(), action.GetResource())
 
-	// Inject error when requested
-	err = r.injectReactError(action)
-	if err != nil {
-		return true, nil, err
-	}
-
-	// Test did not request to inject an error, continue simulating API server.
+	// Errors requested by the test are injected by the hooks, continue
+	// simulating API server.
 	switch {
 	case action.Matches("create", "volumenfsexportcontents"):
 		obj := action.(core.UpdateAction).GetObject()
@@ -275,14 +281,27 @@ func (r *nfsexportReactor) React(action core.Action) (handled bool, ret runtime.
 			if err != nil {
 				return true, nil, err
 			}
-			contentPatch, err := jsonpatch.DecodePatch(action.GetPatch())
-			if err != nil {
-				return true, nil, err
-			}
+			var modified []byte
+			if action.GetPatchType() == types.ApplyPatchType {
+				modified, err = fakeapiserver.ApplyStatus(action, storedNfsExportBytes, utils.CommonControllerFieldManager)
+				if err != nil {
+					return true, nil, err
+				}
+			} else if action.GetPatchType() == types.MergePatchType {
+				modified, err = fakeapiserver.MergePatch(action, storedNfsExportBytes)
+				if err != nil {
+					return true, nil, err
+				}
+			} else {
+				contentPatch, err := jsonpatch.DecodePatch(action.GetPatch())
+				if err != nil {
+					return true, nil, err
+				}
 
-			modified, err := contentPatch.Apply(storedNfsExportBytes)
-			if err != nil {
-				return true, nil, err
+				modified, err = contentPatch.Apply(storedNfsExportBytes)
+				if err != nil {
+					return true, nil, fakeapiserver.PatchApplyError(action, err)
+				}
 			}
 
 			err = json.Unmarshal(modified, content)
@@ -339,16 +358,32 @@ func (r *nfsexportReactor) React(action core.Action) (handled bool, ret runtime.
 			if err != nil {
 				return true, nil, err
 			}
-			snapPatch, err := jsonpatch.DecodePatch(action.GetPatch())
-			if err != nil {
-				return true, nil, err
-			}
+			var modified []byte
+			if action.GetPatchType() == types.ApplyPatchType {
+				modified, err = fakeapiserver.ApplyStatus(action, storedNfsExportBytes, utils.CommonControllerFieldManager)
+				if err != nil {
+					return true, nil, err
+				}
+			} else if action.GetPatchType() == types.MergePatchType {
+				modified, err = fakeapiserver.MergePatch(action, storedNfsExportBytes)
+				if err != nil {
+					return true, nil, err
+				}
+			} else {
+				snapPatch, err := jsonpatch.DecodePatch(action.GetPatch())
+				if err != nil {
+					return true, nil, err
+				}
 
-			modified, err := snapPatch.Apply(storedNfsExportBytes)
-			if err != nil {
-				return true, nil, err
+				modified, err = snapPatch.Apply(storedNfsExportBytes)
+				if err != nil {
+					return true, nil, fakeapiserver.PatchApplyError(action, err)
+				}
 			}
 
+			// Decode into a new object, json.Unmarshal would leave fields
+			// removed by the patch untouched in the stored one.
+			storedNfsExport = &crdv1.VolumeNfsExport{}
 			err = json.Unmarshal(modified, storedNfsExport)
 			if err != nil {
 				return true, nil, err
@@ -402,7 +437,7 @@ func (r *nfsexportReactor) React(action core.Action) (handled bool, ret runtime.
 	case action.Matches("delete", "volumenfsexports"):
 		name := action.(core.DeleteAction).GetName()
 		klog.V(4).Infof("deleted nfsexport %s", name)
-		_, found := r.contents[name]
+		_, found := r.nfsexports[name]
 		if found {
 			delete(r.nfsexports, name)
 			r.changedSinceLastSync++
@@ -471,24 +506,51 @@ func (r *nfsexportReactor) React(action core.Action) (handled bool, ret runtime.
 	return false, nil, nil
 }
 
-// injectReactError returns an error when the test requested given action to
-// fail. nil is returned otherwise.
-func (r *nfsexportReactor) injectReactError(action core.Action) error {
-	if len(r.errors) == 0 {
-		// No more errors to inject, everything should succeed.
-		return nil
+// conflictError returns the error the API server responds with when an
+// object has been modified concurrently. Tests inject it to simulate
+// conflicting writers.
+func conflictError(resource, name string) error {
+	return apierrs.NewConflict(schema.GroupResource{Group: crdv1.GroupName, Resource: resource}, name, errors.New("the object has been modified"))
+}
+
+// normalizeObjectMeta clears ResourceVersion and the differences in
+// representation an object gets after it has been serialized by a patch:
+// timestamps are stored with second precision and empty finalizers are dropped.
+// The managed fields recorded by a status apply are cleared as well.
+func normalizeObjectMeta(meta *metav1.ObjectMeta) {
+	meta.ResourceVersion = ""
+	meta.ManagedFields = nil
+	meta.CreationTimestamp = meta.CreationTimestamp.Rfc3339Copy()
+	if meta.DeletionTimestamp != nil {
+		deletionTimestamp := meta.DeletionTimestamp.Rfc3339Copy()
+		meta.DeletionTimestamp = &deletionTimestamp
+	}
+	if len(meta.Finalizers) == 0 {
+		meta.Finalizers = nil
 	}
+}
 
-	for i, expected := range r.errors {
-		klog.V(4).Infof("trying to match %q %q with %q %q", expected.verb, expected.resource, action.GetVerb(), action.GetResource())
-		if action.Matches(expected.verb, expected.resource) {
-			// That's the action we're waiting for, remove it from injectedErrors
-			r.errors = append(r.errors[:i], r.errors[i+1:]...)
-			klog.V(4).Infof("reactor found matching error at index %d: %q %q, returning %v", i, expected.verb, expected.resource, expected.error)
-			return expected.error
-		}
+// normalizeNfsExportStatus drops the parts of a nfsexport status that do not
+// survive its serialization to JSON, as in a server-side apply, and the error
+// and sync times.
+func normalizeNfsExportStatus(status *crdv1.VolumeNfsExportStatus) {
+	if status == nil {
+		return
+	}
+	status.LastSyncTime = nil
+	if status.CreationTime != nil {
+		creationTime := status.CreationTime.Rfc3339Copy()
+		status.CreationTime = &creationTime
+	}
+	if status.RestoreSize != nil {
+		status.RestoreSize = resource.NewQuantity(status.RestoreSize.Value(), resource.BinarySI)
+	}
+	if status.Error != nil {
+		status.Error.Time = &metav1.Time{}
+	}
+	for i := range status.Conditions {
+		status.Conditions[i].LastTransitionTime = status.Conditions[i].LastTransitionTime.Rfc3339Copy()
 	}
-	return nil
 }
 
 // checkContents compares all expectedContents with set of contents at the end of
@@ -503,7 +565,7 @@ func (r *nfsexportReactor) checkContents(expectedContents []*crdv1.VolumeNfsExpo
 	for _, v := range expectedContents {
 		// Don't modify the existing object
 		v := v.DeepCopy()
-		v.ResourceVersion = ""
+		normalizeObjectMeta(&v.ObjectMeta)
 		v.Spec.VolumeNfsExportRef.ResourceVersion = ""
 		if v.Status != nil {
 			v.Status.CreationTime = nil
@@ -514,7 +576,7 @@ func (r *nfsexportReactor) checkContents(expectedContents []*crdv1.VolumeNfsExpo
 		// We must clone the content because of golang race check - it was
 		// written by the controller without any locks on it.
 		v := v.DeepCopy()
-		v.ResourceVersion = ""
+		normalizeObjectMeta(&v.ObjectMeta)
 		v.Spec.VolumeNfsExportRef.ResourceVersion = ""
 		if v.Status != nil {
 			v.Status.CreationTime = nil
@@ -541,20 +603,16 @@ func (r *nfsexportReactor) checkNfsExports(expectedNfsExports []*crdv1.VolumeNfs
 	for _, c := range expectedNfsExports {
 		// Don't modify the existing object
 		c = c.DeepCopy()
-		c.ResourceVersion = ""
-		if c.Status != nil && c.Status.Error != nil {
-			c.Status.Error.Time = &metav1.Time{}
-		}
+		normalizeObjectMeta(&c.ObjectMeta)
+		normalizeNfsExportStatus(c.Status)
 		expectedMap[c.Name] = c
 	}
 	for _, c := range r.nfsexports {
 		// We must clone the nfsexport because of golang race check - it was
 		// written by the controller without any locks on it.
 		c = c.DeepCopy()
-		c.ResourceVersion = ""
-		if c.Status != nil && c.Status.Error != nil {
-			c.Status.Error.Time = &metav1.Time{}
-		}
+		normalizeObjectMeta(&c.ObjectMeta)
+		normalizeNfsExportStatus(c.Status)
 		gotMap[c.Name] = c
 	}
 	if !reflect.DeepEqual(expectedMap, gotMap) {
@@ -782,7 +840,7 @@ func (r *nfsexportReactor) addNfsExportEvent(nfsexport *crdv1.VolumeNfsExport) {
 	}
 }
 
-func newNfsExportReactor(kubeClient *kubefake.Clientset, client *fake.Clientset, ctrl *csiNfsExportCommonController, fakeVolumeWatch, fakeClaimWatch *watch.FakeWatcher, errors []reactorError) *nfsexportReactor {
+func newNfsExportReactor(kubeClient *kubefake.Clientset, client *fake.Clientset, ctrl *csiNfsExportCommonController, fakeVolumeWatch, fakeClaimWatch *watch.FakeWatcher, errors []fakeapiserver.Hook) *nfsexportReactor {
 	reactor := &nfsexportReactor{
 		secrets:           make(map[string]*v1.Secret),
 		volumes:           make(map[string]*v1.PersistentVolume),
@@ -793,9 +851,12 @@ func newNfsExportReactor(kubeClient *kubefake.Clientset, client *fake.Clientset,
 		ctrl:              ctrl,
 		fakeContentWatch:  fakeVolumeWatch,
 		fakeNfsExportWatch: fakeClaimWatch,
-		errors:            errors,
+		hooks:              fakeapiserver.NewHooks(errors...),
 	}
 
+	reactor.hooks.Install(&client.Fake)
+	reactor.hooks.Install(&kubeClient.Fake)
+
 	client.AddReactor("create", "volumenfsexportcontents", reactor.React)
 	client.AddReactor("update", "volumenfsexportcontents", reactor.React)
 	client.AddReactor("update", "volumenfsexports", reactor.React)
@@ -836,6 +897,7 @@ func newTestController(kubeClient kubernetes.Interface, clientset clientset.Inte
 	}()
 
 	ctrl := NewCSINfsExportCommonController(
+		clientset,
 		clientset,
 		kubeClient,
 		informerFactory.NfsExport().V1().VolumeNfsExports(),
@@ -843,15 +905,25 @@ func newTestController(kubeClient kubernetes.Interface, clientset clientset.Inte
 		informerFactory.NfsExport().V1().VolumeNfsExportClasses(),
 		coreFactory.Core().V1().PersistentVolumeClaims(),
 		nil,
+		nil,
+		nil,
 		metricsManager,
 		60*time.Second,
+		60*time.Second,
 		workqueue.NewItemExponentialFailureRateLimiter(1*time.Millisecond, 1*time.Minute),
 		workqueue.NewItemExponentialFailureRateLimiter(1*time.Millisecond, 1*time.Minute),
 		false,
 		false,
+		true,
+		0,
+		0,
+		0,
+		0,
+		false,
 	)
 
 	ctrl.eventRecorder = record.NewFakeRecorder(1000)
+	ctrl.clock = clocktesting.NewFakeClock(timeNow)
 
 	ctrl.contentListerSynced = alwaysReady
 	ctrl.nfsexportListerSynced = alwaysReady
@@ -938,6 +1010,29 @@ func withContentAnnotations(contents []*crdv1.VolumeNfsExportContent, annotation
 	return contents
 }
 
+// withContentDriverLabel labels the contents with mockDriverName, as done by
+// the controller when it syncs or creates them.
+func withContentDriverLabel(contents []*crdv1.VolumeNfsExportContent) []*crdv1.VolumeNfsExportContent {
+	for i := range contents {
+		metav1.SetMetaDataLabel(&contents[i].ObjectMeta, utils.VolumeNfsExportContentDriverLabel, mockDriverName)
+	}
+	return contents
+}
+
+func withSourceVolume(contents []*crdv1.VolumeNfsExportContent, volumeName string, reclaimPolicy v1.PersistentVolumeReclaimPolicy) []*crdv1.VolumeNfsExportContent {
+	return withContentAnnotations(contents, map[string]string{
+		utils.AnnSourceVolumeName:          volumeName,
+		utils.AnnSourceVolumeReclaimPolicy: string(reclaimPolicy),
+	})
+}
+
+func withContentAttributes(contents []*crdv1.VolumeNfsExportContent, attributes map[string]string) []*crdv1.VolumeNfsExportContent {
+	for i := range contents {
+		contents[i].Status.Attributes = attributes
+	}
+	return contents
+}
+
 func withContentSpecNfsExportClassName(contents []*crdv1.VolumeNfsExportContent, volumeNfsExportClassName *string) []*crdv1.VolumeNfsExportContent {
 	for i := range contents {
 		contents[i].Spec.VolumeNfsExportClassName = volumeNfsExportClassName
@@ -1021,6 +1116,7 @@ func newNfsExport(
 			CreationTime: creationTime,
 			ReadyToUse:   readyToUse,
 			Error:        err,
+			ErrorSummary: utils.GetVolumeNfsExportErrorSummary(err),
 			RestoreSize:  restoreSize,
 		}
 	}
@@ -1064,6 +1160,68 @@ func withNfsExportInvalidLabel(nfsexports []*crdv1.VolumeNfsExport) []*crdv1.Vol
 	return nfsexports
 }
 
+func withNfsExportAnnotations(nfsexports []*crdv1.VolumeNfsExport, annotations map[string]string) []*crdv1.VolumeNfsExport {
+	for i := range nfsexports {
+		if nfsexports[i].ObjectMeta.Annotations == nil {
+			nfsexports[i].ObjectMeta.Annotations = make(map[string]string)
+		}
+		for k, v := range annotations {
+			nfsexports[i].ObjectMeta.Annotations[k] = v
+		}
+	}
+	return nfsexports
+}
+
+func withNfsExportGeneration(nfsexports []*crdv1.VolumeNfsExport, generation, observedGeneration int64) []*crdv1.VolumeNfsExport {
+	for i := range nfsexports {
+		nfsexports[i].ObjectMeta.Generation = generation
+		nfsexports[i].Status.ObservedGeneration = observedGeneration
+	}
+	return nfsexports
+}
+
+func withNfsExportAttributes(nfsexports []*crdv1.VolumeNfsExport, attributes map[string]string) []*crdv1.VolumeNfsExport {
+	for i := range nfsexports {
+		nfsexports[i].Status.Attributes = attributes
+	}
+	return nfsexports
+}
+
+func withNfsExportCreationTimestamp(nfsexports []*crdv1.VolumeNfsExport, creationTimestamp metav1.Time) []*crdv1.VolumeNfsExport {
+	for i := range nfsexports {
+		nfsexports[i].ObjectMeta.CreationTimestamp = creationTimestamp
+	}
+	return nfsexports
+}
+
+func withNfsExportConditions(nfsexports []*crdv1.VolumeNfsExport, conditions ...metav1.Condition) []*crdv1.VolumeNfsExport {
+	for i := range nfsexports {
+		nfsexports[i].Status.Conditions = conditions
+	}
+	return nfsexports
+}
+
+func withNfsExportSources(nfsexports []*crdv1.VolumeNfsExport, sources ...string) []*crdv1.VolumeNfsExport {
+	for i := range nfsexports {
+		nfsexports[i].Spec.Sources = sources
+	}
+	return nfsexports
+}
+
+func withNfsExportMode(nfsexports []*crdv1.VolumeNfsExport, mode crdv1.VolumeNfsExportMode) []*crdv1.VolumeNfsExport {
+	for i := range nfsexports {
+		nfsexports[i].Spec.Mode = &mode
+	}
+	return nfsexports
+}
+
+func withNfsExportTimeToReady(nfsexports []*crdv1.VolumeNfsExport, timeToReady time.Duration) []*crdv1.VolumeNfsExport {
+	for i := range nfsexports {
+		nfsexports[i].Status.TimeToReady = &metav1.Duration{Duration: timeToReady}
+	}
+	return nfsexports
+}
+
 func newNfsExportClass(nfsexportClassName, nfsexportClassUID, driverName string, isDefaultClass bool) *crdv1.VolumeNfsExportClass {
 	sc := &crdv1.VolumeNfsExportClass{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1203,6 +1361,14 @@ func testSyncNfsExport(ctrl *csiNfsExportCommonController, reactor *nfsexportRea
 	return ctrl.syncNfsExport(test.initialNfsExports[0])
 }
 
+// testSyncNfsExportContentOnly syncs the nfsexport with a controller running
+// in content-only mode, i.e. without a PVC informer.
+func testSyncNfsExportContentOnly(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
+	ctrl.pvcLister = nil
+	ctrl.pvcIndexer = nil
+	return ctrl.syncNfsExport(test.initialNfsExports[0])
+}
+
 func testSyncNfsExportError(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
 	err := ctrl.syncNfsExport(test.initialNfsExports[0])
 	if err != nil {
@@ -1276,6 +1442,32 @@ func testUpdateNfsExportClass(ctrl *csiNfsExportCommonController, reactor *nfsex
 	return err
 }
 
+// testUpdateDeletedNfsExportClass runs testUpdateNfsExportClass with a
+// controller that saw the deletion of the class missing-class.
+func testUpdateDeletedNfsExportClass(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
+	ctrl.deletedClasses.add(&crdv1.VolumeNfsExportClass{ObjectMeta: metav1.ObjectMeta{Name: "missing-class"}})
+	return testUpdateNfsExportClass(ctrl, reactor, test)
+}
+
+// testUpdateNfsExportClassWithFallback runs testUpdateDeletedNfsExportClass
+// with a controller falling back to the default class when the class of a
+// nfsexport is deleted before its content is created.
+func testUpdateNfsExportClassWithFallback(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
+	ctrl.fallbackToDefaultClass = true
+	return testUpdateDeletedNfsExportClass(ctrl, reactor, test)
+}
+
+// testSyncNfsExportByKey syncs the nfsexport through syncNfsExportByKey, the
+// entry point of the nfsexport workers, which checks its class first.
+func testSyncNfsExportByKey(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, nfsexport := range test.initialNfsExports {
+		indexer.Add(nfsexport)
+	}
+	ctrl.nfsexportLister = storagelisters.NewVolumeNfsExportLister(indexer)
+	return ctrl.syncNfsExportByKey(utils.NfsExportKey(test.initialNfsExports[0]))
+}
+
 func testNewNfsExportContentCreation(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
 	if err := ctrl.syncUnreadyNfsExport(test.initialNfsExports[0]); err != nil {
 		return fmt.Errorf("syncUnreadyNfsExport failed: %v", err)
@@ -1293,6 +1485,7 @@ var (
 	emptySecretClass   = "empty-secret-class"
 	invalidSecretClass = "invalid-secret-class"
 	validSecretClass   = "valid-secret-class"
+	propagateClass     = "propagate-class"
 	sameDriver         = "sameDriver"
 	diffDriver         = "diffDriver"
 	noClaim            = ""
@@ -1376,12 +1569,13 @@ func runSyncTests(t *testing.T, tests []controllerTest, nfsexportClasses []*crdv
 			reactor.contents[content.Name] = content
 		}
 
-		pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{utils.ClaimSourceNfsExportIndex: utils.ClaimSourceNfsExportIndexFunc})
 		for _, claim := range test.initialClaims {
 			reactor.claims[claim.Name] = claim
 			pvcIndexer.Add(claim)
 		}
 		ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+		ctrl.pvcIndexer = pvcIndexer
 
 		for _, volume := range test.initialVolumes {
 			reactor.volumes[volume.Name] = volume
@@ -1438,12 +1632,13 @@ func runFinalizerTests(t *testing.T, tests []controllerTest, nfsexportClasses []
 			reactor.contents[content.Name] = content
 		}
 
-		pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{utils.ClaimSourceNfsExportIndex: utils.ClaimSourceNfsExportIndexFunc})
 		for _, claim := range test.initialClaims {
 			reactor.claims[claim.Name] = claim
 			pvcIndexer.Add(claim)
 		}
 		ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+		ctrl.pvcIndexer = pvcIndexer
 
 		for _, volume := range test.initialVolumes {
 			reactor.volumes[volume.Name] = volume
@@ -1580,12 +1775,13 @@ func runUpdateNfsExportClassTests(t *testing.T, tests []controllerTest, nfsexpor
 			reactor.contents[content.Name] = content
 		}
 
-		pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{utils.ClaimSourceNfsExportIndex: utils.ClaimSourceNfsExportIndexFunc})
 		for _, claim := range test.initialClaims {
 			reactor.claims[claim.Name] = claim
 			pvcIndexer.Add(claim)
 		}
 		ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+		ctrl.pvcIndexer = pvcIndexer
 
 		for _, volume := range test.initialVolumes {
 			reactor.volumes[volume.Name] = volume
@@ -0,0 +1,252 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// GraphPath is the HTTP path of the endpoint returning the graph of the
+// objects related to a VolumeNfsExport, see GraphHandler.
+const GraphPath = "/debug/nfsexport-graph"
+
+// GraphEdgeStatus is the validation status of an edge of a NfsExportGraph.
+type GraphEdgeStatus string
+
+const (
+	// GraphEdgeBound is the status of an edge whose target exists and
+	// matches the reference of its source.
+	GraphEdgeBound GraphEdgeStatus = "bound"
+	// GraphEdgeMisbound is the status of an edge whose target exists but
+	// refers to another object or does not match its source, e.g. a
+	// VolumeNfsExportContent bound to another VolumeNfsExport.
+	GraphEdgeMisbound GraphEdgeStatus = "misbound"
+	// GraphEdgeMissing is the status of an edge whose target does not exist.
+	GraphEdgeMissing GraphEdgeStatus = "missing"
+	// GraphEdgeUnknown is the status of an edge whose target the controller
+	// cannot read, e.g. a PersistentVolumeClaim in content-only mode.
+	GraphEdgeUnknown GraphEdgeStatus = "unknown"
+)
+
+// GraphNode is an object of a NfsExportGraph.
+type GraphNode struct {
+	// ID identifies the node in the edges, as <kind>/<namespace>/<name>, or
+	// <kind>/<name> for cluster scoped objects.
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// GraphEdge is a reference from an object of a NfsExportGraph to another.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Relation names the reference, e.g. "content" or "source".
+	Relation string          `json:"relation"`
+	Status   GraphEdgeStatus `json:"status"`
+	// Message explains a status other than GraphEdgeBound.
+	Message string `json:"message,omitempty"`
+}
+
+// NfsExportGraph is the graph of the objects related to a VolumeNfsExport:
+// its content, source PVC and PV, and classes. Secrets are not part of the
+// graph, as the controller is not allowed to read them.
+type NfsExportGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type graphBuilder struct {
+	graph NfsExportGraph
+	ids   map[string]bool
+}
+
+// node adds the node of an object to the graph, unless it is already there,
+// and returns its ID.
+func (b *graphBuilder) node(kind, namespace, name string) string {
+	id := kind + "/" + name
+	if namespace != "" {
+		id = kind + "/" + namespace + "/" + name
+	}
+	if !b.ids[id] {
+		b.ids[id] = true
+		b.graph.Nodes = append(b.graph.Nodes, GraphNode{ID: id, Kind: kind, Namespace: namespace, Name: name})
+	}
+	return id
+}
+
+func (b *graphBuilder) edge(from, to, relation string, status GraphEdgeStatus, message string) {
+	b.graph.Edges = append(b.graph.Edges, GraphEdge{From: from, To: to, Relation: relation, Status: status, Message: message})
+}
+
+// GraphHandler returns the http handler of the graph endpoint. It returns the
+// NfsExportGraph of the VolumeNfsExport given as <namespace>/<name> by the
+// "nfsexport" query parameter as JSON, to help debugging a VolumeNfsExport
+// that is not ready without reading all the related objects. The graph is
+// returned to anyone who can reach the endpoint, so it is only served with
+// --enable-graph-endpoint.
+func (ctrl *csiNfsExportCommonController) GraphHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := req.URL.Query().Get("nfsexport")
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil || namespace == "" || name == "" {
+			http.Error(w, fmt.Sprintf("the nfsexport query parameter must be <namespace>/<name>, got %q", key), http.StatusBadRequest)
+			return
+		}
+		graph, err := ctrl.buildNfsExportGraph(namespace, name)
+		if apierrs.IsNotFound(err) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(graph)
+	})
+}
+
+// buildNfsExportGraph returns the NfsExportGraph of a VolumeNfsExport from
+// the informer caches. Only the PVs without PV informer are read from the API
+// server.
+func (ctrl *csiNfsExportCommonController) buildNfsExportGraph(namespace, name string) (*NfsExportGraph, error) {
+	nfsexport, err := ctrl.nfsexportLister.VolumeNfsExports(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	b := &graphBuilder{ids: map[string]bool{}}
+	exportID := b.node("VolumeNfsExport", namespace, name)
+
+	var pv *v1.PersistentVolume
+	if claimName := nfsexport.Spec.Source.PersistentVolumeClaimName; claimName != nil {
+		pv = ctrl.addClaimGraph(b, exportID, namespace, *claimName)
+	}
+
+	var contentName string
+	switch {
+	case nfsexport.Status != nil && nfsexport.Status.BoundVolumeNfsExportContentName != nil:
+		contentName = *nfsexport.Status.BoundVolumeNfsExportContentName
+	case nfsexport.Spec.Source.VolumeNfsExportContentName != nil:
+		contentName = *nfsexport.Spec.Source.VolumeNfsExportContentName
+	default:
+		contentName = utils.GetDynamicNfsExportContentNameForNfsExport(nfsexport)
+	}
+
+	if className := nfsexport.Spec.VolumeNfsExportClassName; className != nil {
+		ctrl.addClassGraph(b, exportID, *className, "")
+	}
+
+	content, err := ctrl.contentLister.Get(contentName)
+	contentID := b.node("VolumeNfsExportContent", "", contentName)
+	switch {
+	case apierrs.IsNotFound(err):
+		b.edge(exportID, contentID, "content", GraphEdgeMissing, fmt.Sprintf("VolumeNfsExportContent %s does not exist", contentName))
+		return &b.graph, nil
+	case err != nil:
+		return nil, err
+	case utils.IsVolumeNfsExportRefSet(nfsexport, content):
+		b.edge(exportID, contentID, "content", GraphEdgeBound, "")
+	default:
+		ref := content.Spec.VolumeNfsExportRef
+		b.edge(exportID, contentID, "content", GraphEdgeMisbound, fmt.Sprintf("VolumeNfsExportContent %s is bound to VolumeNfsExport %s/%s with UID %q", contentName, ref.Namespace, ref.Name, ref.UID))
+	}
+
+	if className := content.Spec.VolumeNfsExportClassName; className != nil {
+		ctrl.addClassGraph(b, contentID, *className, content.Spec.Driver)
+	}
+	if pv != nil && content.Spec.Source.VolumeHandle != nil {
+		pvID := b.node("PersistentVolume", "", pv.Name)
+		if pv.Spec.CSI != nil && pv.Spec.CSI.VolumeHandle == *content.Spec.Source.VolumeHandle {
+			b.edge(contentID, pvID, "volume", GraphEdgeBound, "")
+		} else {
+			b.edge(contentID, pvID, "volume", GraphEdgeMisbound, fmt.Sprintf("the volume handle %s of the VolumeNfsExportContent is not the one of PersistentVolume %s", *content.Spec.Source.VolumeHandle, pv.Name))
+		}
+	}
+	return &b.graph, nil
+}
+
+// addClaimGraph adds the edges from a VolumeNfsExport to its source PVC and
+// from the PVC to its PV. It returns the PV, if found.
+func (ctrl *csiNfsExportCommonController) addClaimGraph(b *graphBuilder, exportID, namespace, claimName string) *v1.PersistentVolume {
+	claimID := b.node("PersistentVolumeClaim", namespace, claimName)
+	if ctrl.pvcLister == nil {
+		b.edge(exportID, claimID, "source", GraphEdgeUnknown, "the controller runs in content-only mode")
+		return nil
+	}
+	pvc, err := ctrl.pvcLister.PersistentVolumeClaims(namespace).Get(claimName)
+	switch {
+	case apierrs.IsNotFound(err):
+		b.edge(exportID, claimID, "source", GraphEdgeMissing, fmt.Sprintf("PersistentVolumeClaim %s does not exist", claimName))
+		return nil
+	case err != nil:
+		b.edge(exportID, claimID, "source", GraphEdgeUnknown, err.Error())
+		return nil
+	}
+	b.edge(exportID, claimID, "source", GraphEdgeBound, "")
+
+	if pvc.Spec.VolumeName == "" {
+		return nil
+	}
+	pvID := b.node("PersistentVolume", "", pvc.Spec.VolumeName)
+	var pv *v1.PersistentVolume
+	if ctrl.pvLister != nil {
+		pv, err = ctrl.pvLister.Get(pvc.Spec.VolumeName)
+	} else {
+		pv, err = ctrl.client.CoreV1().PersistentVolumes().Get(context.TODO(), pvc.Spec.VolumeName, metav1.GetOptions{})
+	}
+	switch {
+	case apierrs.IsNotFound(err):
+		b.edge(claimID, pvID, "volume", GraphEdgeMissing, fmt.Sprintf("PersistentVolume %s does not exist", pvc.Spec.VolumeName))
+		return nil
+	case err != nil:
+		b.edge(claimID, pvID, "volume", GraphEdgeUnknown, err.Error())
+		return nil
+	case ctrl.isVolumeBoundToClaim(pv, pvc):
+		b.edge(claimID, pvID, "volume", GraphEdgeBound, "")
+	default:
+		b.edge(claimID, pvID, "volume", GraphEdgeMisbound, fmt.Sprintf("PersistentVolume %s is not bound to the PersistentVolumeClaim", pv.Name))
+	}
+	return pv
+}
+
+// addClassGraph adds the edge from an object to its VolumeNfsExportClass. If
+// driver is set, the class must be of that driver.
+func (ctrl *csiNfsExportCommonController) addClassGraph(b *graphBuilder, fromID, className, driver string) {
+	classID := b.node("VolumeNfsExportClass", "", className)
+	class, err := ctrl.classLister.Get(className)
+	switch {
+	case apierrs.IsNotFound(err):
+		b.edge(fromID, classID, "class", GraphEdgeMissing, fmt.Sprintf("VolumeNfsExportClass %s does not exist", className))
+	case err != nil:
+		b.edge(fromID, classID, "class", GraphEdgeUnknown, err.Error())
+	case driver != "" && class.Driver != driver:
+		b.edge(fromID, classID, "class", GraphEdgeMisbound, fmt.Sprintf("VolumeNfsExportClass %s is of driver %s, not %s", className, class.Driver, driver))
+	default:
+		b.edge(fromID, classID, "class", GraphEdgeBound, "")
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package common_controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestNfsExportGraph(t *testing.T) {
+	class := &crdv1.VolumeNfsExportClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "gold-secrets"},
+		Driver:     mockDriverName,
+		Parameters: map[string]string{
+			utils.PrefixedNfsExportterSecretNameKey:      "nfsexporter-secret",
+			utils.PrefixedNfsExportterSecretNamespaceKey: testNamespace,
+		},
+	}
+	content := newContent("content1", "snapuid1", "snap1", "sid1", "gold-secrets", "", "pv-handle-1", deletionPolicy, nil, nil, false, false)
+	content.Annotations = map[string]string{
+		utils.AnnDeletionSecretRefName:      "deletion-secret",
+		utils.AnnDeletionSecretRefNamespace: testNamespace,
+	}
+	misbound := newContent("content2", "snapuid9", "snap9", "sid2", "gold-secrets", "", "pv-handle-2", deletionPolicy, nil, nil, false, false)
+
+	nfsexportIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, nfsexport := range []*crdv1.VolumeNfsExport{
+		newNfsExport("snap1", "snapuid1", "claim1", "", "gold-secrets", "content1", &True, nil, nil, nil, false, true, nil),
+		newNfsExport("snap2", "snapuid2", "claim2", "", "missing-class", "content2", &True, nil, nil, nil, false, true, nil),
+		newNfsExport("snap3", "snapuid3", "claim3", "", "gold-secrets", "", nil, nil, nil, nil, false, true, nil),
+	} {
+		nfsexportIndexer.Add(nfsexport)
+	}
+	contentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	contentIndexer.Add(content)
+	contentIndexer.Add(misbound)
+	classIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	classIndexer.Add(class)
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	pvcIndexer.Add(newClaim("claim1", "pvc-uid1", "1Gi", "volume1", v1.ClaimBound, &classEmpty, false))
+	pvcIndexer.Add(newClaim("claim2", "pvc-uid2", "1Gi", "volume2", v1.ClaimBound, &classEmpty, false))
+	pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	pvIndexer.Add(newVolume("volume1", "pv-uid1", "pv-handle-1", "1Gi", "pvc-uid1", "claim1", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty))
+	pvIndexer.Add(newVolume("volume2", "pv-uid2", "pv-handle-9", "1Gi", "pvc-uid9", "claim2", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty))
+
+	ctrl := &csiNfsExportCommonController{
+		client:          fake.NewSimpleClientset(),
+		nfsexportLister: storagelisters.NewVolumeNfsExportLister(nfsexportIndexer),
+		contentLister:   storagelisters.NewVolumeNfsExportContentLister(contentIndexer),
+		classLister:     storagelisters.NewVolumeNfsExportClassLister(classIndexer),
+		pvcLister:       corelisters.NewPersistentVolumeClaimLister(pvcIndexer),
+		pvLister:        corelisters.NewPersistentVolumeLister(pvIndexer),
+	}
+
+	tests := []struct {
+		name      string
+		nfsexport string
+		expected  map[string]GraphEdgeStatus
+	}{
+		{
+			name:      "graph-1 - all objects bound",
+			nfsexport: "snap1",
+			expected: map[string]GraphEdgeStatus{
+				"VolumeNfsExport/default/snap1 -> PersistentVolumeClaim/default/claim1": GraphEdgeBound,
+				"PersistentVolumeClaim/default/claim1 -> PersistentVolume/volume1":      GraphEdgeBound,
+				"VolumeNfsExport/default/snap1 -> VolumeNfsExportClass/gold-secrets":    GraphEdgeBound,
+				"VolumeNfsExport/default/snap1 -> VolumeNfsExportContent/content1":      GraphEdgeBound,
+				"VolumeNfsExportContent/content1 -> VolumeNfsExportClass/gold-secrets":  GraphEdgeBound,
+				"VolumeNfsExportContent/content1 -> PersistentVolume/volume1":           GraphEdgeBound,
+			},
+		},
+		{
+			name:      "graph-2 - misbound content and PV, missing class",
+			nfsexport: "snap2",
+			expected: map[string]GraphEdgeStatus{
+				"VolumeNfsExport/default/snap2 -> PersistentVolumeClaim/default/claim2": GraphEdgeBound,
+				"PersistentVolumeClaim/default/claim2 -> PersistentVolume/volume2":      GraphEdgeMisbound,
+				"VolumeNfsExport/default/snap2 -> VolumeNfsExportClass/missing-class":   GraphEdgeMissing,
+				"VolumeNfsExport/default/snap2 -> VolumeNfsExportContent/content2":      GraphEdgeMisbound,
+				"VolumeNfsExportContent/content2 -> VolumeNfsExportClass/gold-secrets":  GraphEdgeBound,
+				"VolumeNfsExportContent/content2 -> PersistentVolume/volume2":           GraphEdgeMisbound,
+			},
+		},
+		{
+			name:      "graph-3 - missing claim and content",
+			nfsexport: "snap3",
+			expected: map[string]GraphEdgeStatus{
+				"VolumeNfsExport/default/snap3 -> PersistentVolumeClaim/default/claim3":        GraphEdgeMissing,
+				"VolumeNfsExport/default/snap3 -> VolumeNfsExportClass/gold-secrets":           GraphEdgeBound,
+				"VolumeNfsExport/default/snap3 -> VolumeNfsExportContent/snapcontent-snapuid3": GraphEdgeMissing,
+			},
+		},
+	}
+	for _, test := range tests {
+		graph, err := ctrl.buildNfsExportGraph(testNamespace, test.nfsexport)
+		if err != nil {
+			t.Errorf("Test %q: unexpected error: %v", test.name, err)
+			continue
+		}
+		edges := map[string]GraphEdgeStatus{}
+		for _, edge := range graph.Edges {
+			edges[edge.From+" -> "+edge.To] = edge.Status
+		}
+		if !reflect.DeepEqual(edges, test.expected) {
+			t.Errorf("Test %q: expected edges %v, got %v", test.name, test.expected, edges)
+		}
+	}
+
+	handler := ctrl.GraphHandler()
+	for query, code := range map[string]int{
+		"nfsexport=default/snap1":   http.StatusOK,
+		"nfsexport=default/missing": http.StatusNotFound,
+		"nfsexport=snap1":           http.StatusBadRequest,
+		"":                          http.StatusBadRequest,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, GraphPath+"?"+query, nil))
+		if rec.Code != code {
+			t.Errorf("expected status %d for %q, got %d: %s", code, query, rec.Code, rec.Body.String())
+		}
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, GraphPath+"?nfsexport=default/snap1", nil))
+	graph := NfsExportGraph{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &graph); err != nil {
+		t.Fatalf("failed to decode the graph: %v", err)
+	}
+	if len(graph.Nodes) != 5 || len(graph.Edges) != 6 {
+		t.Errorf("expected 5 nodes and 6 edges, got %+v", graph)
+	}
+}
@@ -18,16 +18,24 @@ package common_controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
 	ref "k8s.io/client-go/tools/reference"
 	corev1helpers "k8s.io/component-helpers/scheduling/corev1"
 	klog "k8s.io/klog/v2"
@@ -82,7 +90,7 @@ const (
 	nfsexportAPIGroup = crdv1.GroupName
 )
 
-const controllerUpdateFailMsg = "nfsexport controller failed to update"
+const controllerUpdateFailMsg = utils.ControllerUpdateFailMsg
 
 // syncContent deals with one key off the queue
 func (ctrl *csiNfsExportCommonController) syncContent(content *crdv1.VolumeNfsExportContent) error {
@@ -99,6 +107,15 @@ func (ctrl *csiNfsExportCommonController) syncContent(content *crdv1.VolumeNfsEx
 		return err
 	}
 
+	// Label the content with its driver so that it is cached by the driver
+	// scoped content informer of the sidecar. Contents created before the
+	// label was introduced are labeled here.
+	content, err = ctrl.checkAndSetContentDriverLabel(content)
+	if err != nil {
+		klog.Errorf("syncContent[%s]: check and add driver label failed, %s", content.Name, err.Error())
+		return err
+	}
+
 	// Keep this check in the controller since the validation webhook may not have been deployed.
 	if (content.Spec.Source.VolumeHandle == nil && content.Spec.Source.NfsExportHandle == nil) ||
 		(content.Spec.Source.VolumeHandle != nil && content.Spec.Source.NfsExportHandle != nil) {
@@ -107,6 +124,11 @@ func (ctrl *csiNfsExportCommonController) syncContent(content *crdv1.VolumeNfsEx
 		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "ContentValidationError", err.Error())
 		return err
 	}
+	// Report rather than reject contents duplicating the nfsexport handle of
+	// another one, the webhook may not check them.
+	if content, err = ctrl.checkDuplicateNfsExportHandle(content); err != nil {
+		klog.Errorf("syncContent[%s]: failed to report a duplicate nfsexport handle: %v", content.Name, err)
+	}
 
 	// The VolumeNfsExportContent is reserved for a VolumeNfsExport;
 	// that VolumeNfsExport has not yet been bound to this VolumeNfsExportContent;
@@ -149,6 +171,12 @@ func (ctrl *csiNfsExportCommonController) syncContent(content *crdv1.VolumeNfsEx
 		}
 	}
 
+	// A content whose nfsexport is gone may be rebound to a new nfsexport
+	// on request of the user.
+	if nfsexport == nil && metav1.HasAnnotation(content.ObjectMeta, utils.AnnVolumeNfsExportRebindTo) {
+		return ctrl.rebindContent(content)
+	}
+
 	// NOTE(xyang): Do not trigger content deletion if
 	// nfsexport is nil. This is to avoid data loss if
 	// the user copied the yaml files and expect it to work
@@ -176,6 +204,58 @@ func (ctrl *csiNfsExportCommonController) syncContent(content *crdv1.VolumeNfsEx
 	return nil
 }
 
+// checkDuplicateNfsExportHandle sets the DuplicateNfsExportHandle condition
+// of a pre-provisioned content whose nfsexport handle is also used by other
+// contents of the same driver, deleting one of them would delete the export
+// of all of them. A warning event is emitted when the condition turns "True",
+// and the condition turns "False" once the handle is unique again.
+func (ctrl *csiNfsExportCommonController) checkDuplicateNfsExportHandle(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
+	if ctrl.contentIndexer == nil {
+		return content, nil
+	}
+	duplicates, err := utils.GetDuplicateHandleContents(ctrl.contentIndexer, content)
+	if err != nil {
+		klog.Errorf("syncContent[%s]: failed to look up contents with the same nfsexport handle: %v", content.Name, err)
+		return content, nil
+	}
+	var current *metav1.Condition
+	if content.Status != nil {
+		current = meta.FindStatusCondition(content.Status.Conditions, crdv1.ConditionDuplicateNfsExportHandle)
+	}
+	duplicated := current != nil && current.Status == metav1.ConditionTrue
+	if len(duplicates) == 0 {
+		if !duplicated {
+			return content, nil
+		}
+		klog.V(2).Infof("syncContent[%s]: nfsexport handle is unique", content.Name)
+		return ctrl.applyContentCondition(content, metav1.Condition{
+			Type:               crdv1.ConditionDuplicateNfsExportHandle,
+			Status:             metav1.ConditionFalse,
+			Reason:             crdv1.DuplicateNfsExportHandleReasonResolved,
+			Message:            "NfsExportHandle is not used by another VolumeNfsExportContent",
+			ObservedGeneration: content.Generation,
+			LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+		})
+	}
+	msg := fmt.Sprintf("NfsExportHandle %s of driver %s is also used by VolumeNfsExportContent %s", *content.Spec.Source.NfsExportHandle, content.Spec.Driver, strings.Join(duplicates, ", "))
+	if duplicated && current.Message == msg {
+		return content, nil
+	}
+	klog.Warningf("syncContent[%s]: %s", content.Name, msg)
+	newContent, err := ctrl.applyContentCondition(content, metav1.Condition{
+		Type:               crdv1.ConditionDuplicateNfsExportHandle,
+		Status:             metav1.ConditionTrue,
+		Reason:             crdv1.DuplicateNfsExportHandleReasonShared,
+		Message:            msg,
+		ObservedGeneration: content.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	if !duplicated {
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "DuplicateNfsExportHandle", msg)
+	}
+	return newContent, err
+}
+
 // syncNfsExport is the main controller method to decide what to do with a nfsexport.
 // It's invoked by appropriate cache.Controller callbacks when a nfsexport is
 // created, updated or periodically synced. We do not differentiate between
@@ -193,6 +273,12 @@ func (ctrl *csiNfsExportCommonController) syncNfsExport(nfsexport *crdv1.VolumeN
 		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "ErrorPVCFinalizer", "Error check and remove PVC Finalizer for VolumeNfsExport")
 	}
 
+	// Let the pods asked to quiesce for the export resume once it is cut.
+	if err := ctrl.checkandReleaseSourcePods(nfsexport); err != nil {
+		klog.Errorf("error check and release the pods quiesced for nfsexport [%s]: %v", nfsexport.Name, err)
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "QuiesceReleaseFailed", fmt.Sprintf("Failed to release the pods quiesced for the export: %v", err))
+	}
+
 	klog.V(5).Infof("syncNfsExport[%s]: check if we should add invalid label on nfsexport", utils.NfsExportKey(nfsexport))
 	// Perform additional validation. Label objects which fail.
 	// Part of a plan to tighten validation, this label will enable users to
@@ -218,6 +304,13 @@ func (ctrl *csiNfsExportCommonController) syncNfsExport(nfsexport *crdv1.VolumeN
 		return err
 	}
 
+	klog.V(5).Infof("syncNfsExport[%s]: check if the bound content in nfsexport status is the expected one", utils.NfsExportKey(nfsexport))
+	nfsexport, err = ctrl.checkAndResetUnexpectedBinding(nfsexport)
+	if err != nil {
+		klog.Errorf("syncNfsExport[%s]: check and reset unexpected binding failed, %s", utils.NfsExportKey(nfsexport), err.Error())
+		return err
+	}
+
 	klog.V(5).Infof("syncNfsExport[%s]: check if we should add finalizers on nfsexport", utils.NfsExportKey(nfsexport))
 	if err := ctrl.checkandAddNfsExportFinalizers(nfsexport); err != nil {
 		klog.Errorf("error check and add NfsExport finalizers for nfsexport [%s]: %v", nfsexport.Name, err)
@@ -234,6 +327,64 @@ func (ctrl *csiNfsExportCommonController) syncNfsExport(nfsexport *crdv1.VolumeN
 	return ctrl.syncReadyNfsExport(nfsexport)
 }
 
+// checkAndResetUnexpectedBinding clears the status of a nfsexport whose
+// Status.BoundVolumeNfsExportContentName can only come from a user supplied
+// status, e.g. a manifest applied by a GitOps tool with a status block copied
+// from another cluster. A pre-provisioned nfsexport may only be bound to
+// Spec.Source.VolumeNfsExportContentName. A dynamically provisioned nfsexport
+// may also be bound to a content named otherwise, as long as that content
+// refers back to it; a missing content is reported by syncReadyNfsExport.
+// Such a status is discarded so that the nfsexport goes through the regular
+// binding process instead of being reported as bound and ready.
+func (ctrl *csiNfsExportCommonController) checkAndResetUnexpectedBinding(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExport, error) {
+	if !utils.IsBoundVolumeNfsExportContentNameSet(nfsexport) {
+		return nfsexport, nil
+	}
+	expectedContentName := utils.GetDynamicNfsExportContentNameForNfsExport(nfsexport)
+	if nfsexport.Spec.Source.VolumeNfsExportContentName != nil {
+		expectedContentName = *nfsexport.Spec.Source.VolumeNfsExportContentName
+	}
+	boundContentName := *nfsexport.Status.BoundVolumeNfsExportContentName
+	if boundContentName == expectedContentName {
+		return nfsexport, nil
+	}
+	if nfsexport.Spec.Source.VolumeNfsExportContentName == nil {
+		obj, found, err := ctrl.contentStore.GetByKey(boundContentName)
+		if err != nil || !found {
+			return nfsexport, nil
+		}
+		content, ok := obj.(*crdv1.VolumeNfsExportContent)
+		if !ok || content.Spec.VolumeNfsExportRef.UID == nfsexport.UID {
+			return nfsexport, nil
+		}
+	}
+
+	klog.V(4).Infof("checkAndResetUnexpectedBinding[%s]: status is bound to VolumeNfsExportContent %q while expecting %q, resetting status", utils.NfsExportKey(nfsexport), boundContentName, expectedContentName)
+	message := fmt.Sprintf("Status bound to unexpected VolumeNfsExportContent %s was not set by the controller and has been reset", boundContentName)
+	ready := false
+	newStatus := &crdv1.VolumeNfsExportStatus{
+		ReadyToUse: &ready,
+		Error: &crdv1.VolumeNfsExportError{
+			Time: &metav1.Time{
+				Time: ctrl.clock.Now(),
+			},
+			Message: &message,
+		},
+	}
+	ctrl.markNfsExportStatusSynced(newStatus, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, newStatus, ctrl.statusClientset, utils.CommonControllerFieldManager)
+	if err != nil {
+		return nfsexport, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+	ctrl.eventRecorder.Event(newNfsExport, v1.EventTypeWarning, "NfsExportStatusReset", message)
+
+	_, err = ctrl.storeNfsExportUpdate(newNfsExport)
+	if err != nil {
+		klog.V(4).Infof("checkAndResetUnexpectedBinding[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+	}
+	return newNfsExport, nil
+}
+
 // processNfsExportWithDeletionTimestamp processes finalizers and deletes the content when appropriate. It has the following steps:
 // 1. Get the content which the to-be-deleted VolumeNfsExport points to and verifies bi-directional binding.
 // 2. Call checkandRemoveNfsExportFinalizersAndCheckandDeleteContent() with information obtained from step 1. This function name is very long but the name suggests what it does. It determines whether to remove finalizers on nfsexport and whether to delete content.
@@ -301,11 +452,15 @@ func (ctrl *csiNfsExportCommonController) checkandRemoveNfsExportFinalizersAndCh
 
 	// check if the nfsexport is being used for restore a PVC, if yes, do nothing
 	// and wait until PVC restoration finishes
-	if content != nil && ctrl.isVolumeBeingCreatedFromNfsExport(nfsexport) {
-		klog.V(4).Infof("checkandRemoveNfsExportFinalizersAndCheckandDeleteContent[%s]: nfsexport is being used to restore a PVC", utils.NfsExportKey(nfsexport))
-		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "NfsExportDeletePending", "NfsExport is being used to restore a PVC")
-		// TODO(@xiangqian): should requeue this?
-		return nil
+	if content != nil {
+		if claimName := ctrl.getClaimBeingCreatedFromNfsExport(nfsexport); claimName != "" {
+			klog.V(4).Infof("checkandRemoveNfsExportFinalizersAndCheckandDeleteContent[%s]: nfsexport is being used to restore a PVC", utils.NfsExportKey(nfsexport))
+			ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "NfsExportDeletePending", "NfsExport is being used to restore a PVC")
+			ctrl.setNfsExportBlocked(nfsexport, crdv1.BlockedReasonRestoreInProgress, fmt.Sprintf("Deletion waits for PVC %s being restored from the nfsexport", claimName))
+			// The nfsexport is enqueued again when the PVC leaves the Pending
+			// phase, see enqueueRestoreSourceNfsExport.
+			return nil
+		}
 	}
 
 	// regardless of the deletion policy, set the VolumeNfsExportBeingDeleted on
@@ -325,6 +480,7 @@ func (ctrl *csiNfsExportCommonController) checkandRemoveNfsExportFinalizersAndCh
 	// If content exists and has a deletion policy of Delete, set DeletionTimeStamp on the content;
 	// content won't be deleted immediately due to the VolumeNfsExportContentFinalizer
 	if content != nil && deleteContent {
+		ctrl.checkRetainedSourceVolume(nfsexport, content)
 		klog.V(5).Infof("checkandRemoveNfsExportFinalizersAndCheckandDeleteContent: set DeletionTimeStamp on content [%s].", content.Name)
 		err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Delete(context.TODO(), content.Name, metav1.DeleteOptions{})
 		if err != nil {
@@ -348,6 +504,33 @@ func (ctrl *csiNfsExportCommonController) checkandRemoveNfsExportFinalizersAndCh
 	return ctrl.removeNfsExportFinalizer(nfsexport, true, removeBoundFinalizer)
 }
 
+// checkRetainedSourceVolume warns that the data of content is deleted
+// although its source PV had the Retain reclaim policy and was deleted
+// meanwhile, i.e. the export may hold the last copy of the data the user
+// meant to keep. The deletion is not blocked.
+func (ctrl *csiNfsExportCommonController) checkRetainedSourceVolume(nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) {
+	pvName := content.Annotations[utils.AnnSourceVolumeName]
+	if pvName == "" || content.Annotations[utils.AnnSourceVolumeReclaimPolicy] != string(v1.PersistentVolumeReclaimRetain) {
+		return
+	}
+	var err error
+	if ctrl.pvLister != nil {
+		_, err = ctrl.pvLister.Get(pvName)
+	} else {
+		_, err = ctrl.client.CoreV1().PersistentVolumes().Get(context.TODO(), pvName, metav1.GetOptions{})
+	}
+	if !apierrs.IsNotFound(err) {
+		if err != nil {
+			klog.Warningf("checkRetainedSourceVolume: failed to check whether source PV %s of content %s still exists: %v", pvName, content.Name, err)
+		}
+		return
+	}
+	msg := fmt.Sprintf("Deleting the nfsexport data of content %s although its source PV %s, whose reclaim policy was %s, has been deleted", content.Name, pvName, v1.PersistentVolumeReclaimRetain)
+	klog.Warningf("checkRetainedSourceVolume[%s]: %s", utils.NfsExportKey(nfsexport), msg)
+	ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "RetainedSourceVolumeDeleted", msg)
+	ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "RetainedSourceVolumeDeleted", msg)
+}
+
 // checkandAddNfsExportFinalizers checks and adds nfsexport finailzers when needed
 func (ctrl *csiNfsExportCommonController) checkandAddNfsExportFinalizers(nfsexport *crdv1.VolumeNfsExport) error {
 	// get the content for this NfsExport
@@ -406,18 +589,175 @@ func (ctrl *csiNfsExportCommonController) syncReadyNfsExport(nfsexport *crdv1.Vo
 	klog.V(5).Infof("syncReadyNfsExport[%s]: VolumeNfsExportContent %q found", utils.NfsExportKey(nfsexport), content.Name)
 	// check binding from content side to make sure the binding is still valid
 	if !utils.IsVolumeNfsExportRefSet(nfsexport, content) {
-		// nfsexport is bound but content is not pointing to the nfsexport
-		return ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, true, v1.EventTypeWarning, "NfsExportMisbound", "VolumeNfsExportContent is not bound to the VolumeNfsExport correctly")
+		if !isContentBindingIncomplete(nfsexport, content) {
+			// nfsexport is bound but content is not pointing to the nfsexport
+			return ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, true, v1.EventTypeWarning, "NfsExportMisbound", "VolumeNfsExportContent is not bound to the VolumeNfsExport correctly")
+		}
+		// The binding was interrupted after the status of the nfsexport was
+		// written, complete it on the content side.
+		klog.V(4).Infof("syncReadyNfsExport[%s]: VolumeNfsExportContent %q is missing the UID of the nfsexport, completing the binding", utils.NfsExportKey(nfsexport), content.Name)
+		newContent, err := ctrl.checkandBindNfsExportContent(nfsexport, content)
+		if err != nil {
+			return fmt.Errorf("failed to complete the binding of nfsexport %s and content %s: %v", utils.NfsExportKey(nfsexport), content.Name, err)
+		}
+		content = newContent
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "NfsExportBindingRepaired", fmt.Sprintf("Completed the binding of VolumeNfsExportContent %s to the VolumeNfsExport", content.Name))
+	}
+
+	if deleted, err := ctrl.checkandDeleteRestoredNfsExport(nfsexport); err != nil || deleted {
+		return err
+	}
+
+	nfsexport, err = ctrl.checkNfsExportSourceDeleted(nfsexport)
+	if err != nil {
+		return err
+	}
+
+	// Record that the latest spec of the nfsexport has been processed.
+	if nfsexport.Status.ObservedGeneration != nfsexport.Generation {
+		newNfsExport, err := ctrl.updateNfsExportStatus(nfsexport, content)
+		if err != nil {
+			return err
+		}
+		if _, err := ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+			klog.V(4).Infof("syncReadyNfsExport[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+		}
 	}
 
 	// everything is verified, return
 	return nil
 }
 
+// checkandDeleteRestoredNfsExport deletes a ready nfsexport once a claim
+// restored from it with the AnnDeleteSourceWhenBound annotation is Bound, and
+// no other claim is being provisioned from it. Claims created before the
+// nfsexport were restored from an earlier VolumeNfsExport of the same name
+// and are ignored. It returns true if the nfsexport was deleted.
+func (ctrl *csiNfsExportCommonController) checkandDeleteRestoredNfsExport(nfsexport *crdv1.VolumeNfsExport) (bool, error) {
+	if ctrl.pvcIndexer == nil {
+		return false, nil
+	}
+	objs, err := ctrl.pvcIndexer.ByIndex(utils.ClaimSourceNfsExportIndex, utils.NfsExportKey(nfsexport))
+	if err != nil {
+		return false, fmt.Errorf("failed to list the claims restored from nfsexport %s: %v", utils.NfsExportKey(nfsexport), err)
+	}
+	var boundClaim *v1.PersistentVolumeClaim
+	for _, obj := range objs {
+		pvc, ok := obj.(*v1.PersistentVolumeClaim)
+		if !ok || getSourceNfsExportName(pvc) != nfsexport.Name || pvc.CreationTimestamp.Before(&nfsexport.CreationTimestamp) {
+			continue
+		}
+		if pvc.Status.Phase == v1.ClaimPending {
+			// The nfsexport must outlive the provisioning of the claim.
+			return false, nil
+		}
+		if boundClaim == nil && pvc.Status.Phase == v1.ClaimBound && pvc.Annotations[utils.AnnDeleteSourceWhenBound] == "true" {
+			boundClaim = pvc
+		}
+	}
+	if boundClaim == nil {
+		return false, nil
+	}
+
+	klog.V(2).Infof("checkandDeleteRestoredNfsExport[%s]: deleting nfsexport, claim %s restored from it is bound", utils.NfsExportKey(nfsexport), boundClaim.Name)
+	uid := nfsexport.UID
+	err = ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexport.Namespace).Delete(context.TODO(), nfsexport.Name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &uid},
+	})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return false, fmt.Errorf("failed to delete nfsexport %s restored to claim %s: %v", utils.NfsExportKey(nfsexport), boundClaim.Name, err)
+	}
+	ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "RestoredNfsExportDeleted",
+		fmt.Sprintf("Deleted VolumeNfsExport, PersistentVolumeClaim %s restored from it is bound and annotated with %s", boundClaim.Name, utils.AnnDeleteSourceWhenBound))
+	return true, nil
+}
+
+// checkNfsExportSourceDeleted sets the SourceDeleted condition of a ready
+// PointInTime nfsexport once one of its source PVCs, including the further
+// sources of VolumeNfsExportSpec.Sources, no longer exists, or was recreated
+// after the export was cut, so that restore tooling knows the export is the
+// only copy of the data of the claim. A Live export serves the source volume
+// itself, which may outlive its claim, so it is never marked.
+func (ctrl *csiNfsExportCommonController) checkNfsExportSourceDeleted(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExport, error) {
+	if ctrl.pvcLister == nil || nfsexport.Spec.Source.PersistentVolumeClaimName == nil || meta.IsStatusConditionTrue(nfsexport.Status.Conditions, crdv1.ConditionSourceDeleted) {
+		return nfsexport, nil
+	}
+	if utils.GetExportMode(nfsexport.Spec.Mode) != crdv1.VolumeNfsExportModePointInTime {
+		return nfsexport, nil
+	}
+	var reason, msg string
+	for _, claimName := range append([]string{*nfsexport.Spec.Source.PersistentVolumeClaimName}, nfsexport.Spec.Sources...) {
+		pvc, err := ctrl.pvcLister.PersistentVolumeClaims(nfsexport.Namespace).Get(claimName)
+		switch {
+		case apierrs.IsNotFound(err):
+			reason = crdv1.SourceDeletedReasonClaimNotFound
+			msg = fmt.Sprintf("PersistentVolumeClaim %s was deleted, the export is the only copy of its data", claimName)
+		case err != nil:
+			return nfsexport, fmt.Errorf("failed to get the source PVC %s of nfsexport %s: %v", claimName, utils.NfsExportKey(nfsexport), err)
+		case nfsexport.Status.CreationTime != nil && pvc.CreationTimestamp.After(nfsexport.Status.CreationTime.Time):
+			reason = crdv1.SourceDeletedReasonClaimRecreated
+			msg = fmt.Sprintf("PersistentVolumeClaim %s was recreated after the export was cut, the export is the only copy of the data of the original claim", claimName)
+		}
+		if reason != "" {
+			break
+		}
+	}
+	if reason == "" {
+		return nfsexport, nil
+	}
+	klog.V(2).Infof("checkNfsExportSourceDeleted[%s]: %s", utils.NfsExportKey(nfsexport), msg)
+
+	nfsexportClone := nfsexport.DeepCopy()
+	meta.SetStatusCondition(&nfsexportClone.Status.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionSourceDeleted,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: nfsexport.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
+	if err != nil {
+		return nfsexport, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+	ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "SourcePVCDeleted", msg)
+	if _, err = ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.V(4).Infof("checkNfsExportSourceDeleted[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+	}
+	return newNfsExport, nil
+}
+
+// getSourceNfsExportName returns the name of the VolumeNfsExport in the data
+// source of pvc, or an empty string if pvc is not restored from a nfsexport.
+func getSourceNfsExportName(pvc *v1.PersistentVolumeClaim) string {
+	dataSource := pvc.Spec.DataSource
+	if dataSource == nil || dataSource.Kind != nfsexportKind || dataSource.APIGroup == nil || *dataSource.APIGroup != nfsexportAPIGroup {
+		return ""
+	}
+	return dataSource.Name
+}
+
+// isContentBindingIncomplete returns true if content points to nfsexport by
+// name but is missing its UID, as left by a binding interrupted after the
+// status of nfsexport was written.
+func isContentBindingIncomplete(nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) bool {
+	ref := content.Spec.VolumeNfsExportRef
+	return ref.UID == "" &&
+		ref.Name == nfsexport.Name &&
+		ref.Namespace == nfsexport.Namespace &&
+		utils.IsVolumeNfsExportCreationTimestampMatched(nfsexport, content)
+}
+
 // syncUnreadyNfsExport is the main controller method to decide what to do with a nfsexport which is not set to ready.
 func (ctrl *csiNfsExportCommonController) syncUnreadyNfsExport(nfsexport *crdv1.VolumeNfsExport) error {
 	uniqueNfsExportName := utils.NfsExportKey(nfsexport)
 	klog.V(5).Infof("syncUnreadyNfsExport %s", uniqueNfsExportName)
+	if isNfsExportClassDeleted(nfsexport) {
+		// The nfsexport failed permanently, see handleNfsExportClassDeleted.
+		klog.V(4).Infof("syncUnreadyNfsExport[%s]: class was deleted before the content was created, nothing to do", uniqueNfsExportName)
+		return nil
+	}
 	driverName, err := ctrl.getNfsExportDriverName(nfsexport)
 	if err != nil {
 		klog.Errorf("failed to getNfsExportDriverName while recording metrics for nfsexport %q: %s", utils.NfsExportKey(nfsexport), err)
@@ -436,10 +776,14 @@ func (ctrl *csiNfsExportCommonController) syncUnreadyNfsExport(nfsexport *crdv1.
 			metrics.NewOperationValue(driverName, nfsexportProvisionType),
 		)
 	}
+	createAndReadyOperationKey := metrics.NewOperationKey(metrics.CreateNfsExportAndReadyOperationName, nfsexport.UID)
 	ctrl.metricsManager.OperationStart(
-		metrics.NewOperationKey(metrics.CreateNfsExportAndReadyOperationName, nfsexport.UID),
+		createAndReadyOperationKey,
 		metrics.NewOperationValue(driverName, nfsexportProvisionType),
 	)
+	if waited, ok := ctrl.nfsexportQueueWait.take(uniqueNfsExportName); ok {
+		ctrl.metricsManager.RecordOperationPhase(createAndReadyOperationKey, metrics.OperationPhaseQueueWait, waited)
+	}
 
 	// Pre-provisioned nfsexport
 	if nfsexport.Spec.Source.VolumeNfsExportContentName != nil {
@@ -451,6 +795,7 @@ func (ctrl *csiNfsExportCommonController) syncUnreadyNfsExport(nfsexport *crdv1.
 		// if no content found yet, update status and return
 		if content == nil {
 			// can not find the desired VolumeNfsExportContent from cache store
+			nfsexport = ctrl.setNfsExportBlocked(nfsexport, crdv1.BlockedReasonContentNotFound, fmt.Sprintf("VolumeNfsExportContent %s does not exist", *nfsexport.Spec.Source.VolumeNfsExportContentName))
 			ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, true, v1.EventTypeWarning, "NfsExportContentMissing", "VolumeNfsExportContent is missing")
 			klog.V(4).Infof("syncUnreadyNfsExport[%s]: nfsexport content %q requested but not found, will try again", utils.NfsExportKey(nfsexport), *nfsexport.Spec.Source.VolumeNfsExportContentName)
 
@@ -465,6 +810,10 @@ func (ctrl *csiNfsExportCommonController) syncUnreadyNfsExport(nfsexport *crdv1.
 			return fmt.Errorf("nfsexport %s is bound, but VolumeNfsExportContent %s is not bound to the VolumeNfsExport correctly, %v", uniqueNfsExportName, content.Name, err)
 		}
 
+		if nfsexport, err = ctrl.resolveNfsExportBlocked(nfsexport); err != nil {
+			return err
+		}
+
 		// update nfsexport status
 		klog.V(5).Infof("syncUnreadyNfsExport [%s]: trying to update nfsexport status", utils.NfsExportKey(nfsexport))
 		if _, err = ctrl.updateNfsExportStatus(nfsexport, newContent); err != nil {
@@ -507,9 +856,20 @@ func (ctrl *csiNfsExportCommonController) syncUnreadyNfsExport(nfsexport *crdv1.
 	}
 	var content *crdv1.VolumeNfsExportContent
 	if content, err = ctrl.createNfsExportContent(nfsexport); err != nil {
+		var blocked *blockedError
+		if errors.As(err, &blocked) {
+			nfsexport = ctrl.setNfsExportBlocked(nfsexport, blocked.reason, blocked.message)
+		}
 		ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, true, v1.EventTypeWarning, "NfsExportContentCreationFailed", fmt.Sprintf("Failed to create nfsexport content with error %v", err))
 		return err
 	}
+	if content == nil {
+		// Requeued until the pods using the source PVC are quiesced.
+		return nil
+	}
+	if nfsexport, err = ctrl.resolveNfsExportBlocked(nfsexport); err != nil {
+		return err
+	}
 
 	// Update nfsexport status with BoundVolumeNfsExportContentName
 	klog.V(5).Infof("syncUnreadyNfsExport [%s]: trying to update nfsexport status", utils.NfsExportKey(nfsexport))
@@ -546,8 +906,9 @@ func (ctrl *csiNfsExportCommonController) getPreprovisionedContentFromStore(nfse
 		// can not find the desired VolumeNfsExportContent from cache store
 		return nil, nil
 	}
-	// check whether the content is a pre-provisioned VolumeNfsExportContent
-	if content.Spec.Source.NfsExportHandle == nil {
+	// check whether the content is a pre-provisioned VolumeNfsExportContent.
+	// A dynamically provisioned content rebound to this nfsexport is accepted too.
+	if content.Spec.Source.NfsExportHandle == nil && (content.Spec.VolumeNfsExportRef.UID == "" || content.Spec.VolumeNfsExportRef.UID != nfsexport.UID) {
 		// found a content which represents a dynamically provisioned nfsexport
 		// update the nfsexport and return an error
 		ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, true, v1.EventTypeWarning, "NfsExportContentMismatch", "VolumeNfsExportContent is dynamically provisioned while expecting a pre-provisioned one")
@@ -556,7 +917,7 @@ func (ctrl *csiNfsExportCommonController) getPreprovisionedContentFromStore(nfse
 	}
 	// verify the content points back to the nfsexport
 	ref := content.Spec.VolumeNfsExportRef
-	if ref.Name != nfsexport.Name || ref.Namespace != nfsexport.Namespace || (ref.UID != "" && ref.UID != nfsexport.UID) {
+	if ref.Name != nfsexport.Name || ref.Namespace != nfsexport.Namespace || (ref.UID != "" && ref.UID != nfsexport.UID) || !utils.IsVolumeNfsExportCreationTimestampMatched(nfsexport, content) {
 		klog.V(4).Infof("sync nfsexport[%s]: VolumeNfsExportContent %s is bound to another nfsexport %v", utils.NfsExportKey(nfsexport), contentName, ref)
 		msg := fmt.Sprintf("VolumeNfsExportContent [%s] is bound to a different nfsexport", contentName)
 		ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, true, v1.EventTypeWarning, "NfsExportContentMisbound", msg)
@@ -602,7 +963,7 @@ func (ctrl *csiNfsExportCommonController) getDynamicallyProvisionedContentFromSt
 	// left to be empty to allow binding to a nfsexport, a dynamically provisioned
 	// content MUST have its Spec.VolumeNfsExportRef.UID set to the nfsexport's UID
 	// from which it's been created, thus ref.UID == "" is not a legit case here.
-	if ref.Name != nfsexport.Name || ref.Namespace != nfsexport.Namespace || ref.UID != nfsexport.UID {
+	if ref.Name != nfsexport.Name || ref.Namespace != nfsexport.Namespace || ref.UID != nfsexport.UID || !utils.IsVolumeNfsExportCreationTimestampMatched(nfsexport, content) {
 		klog.V(4).Infof("sync nfsexport[%s]: VolumeNfsExportContent %s is bound to another nfsexport %v", utils.NfsExportKey(nfsexport), contentName, ref)
 		msg := fmt.Sprintf("VolumeNfsExportContent [%s] is bound to a different nfsexport", contentName)
 		ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, true, v1.EventTypeWarning, "NfsExportContentMisbound", msg)
@@ -633,10 +994,16 @@ func (ctrl *csiNfsExportCommonController) getContentFromStore(contentName string
 	return content, nil
 }
 
-// createNfsExportContent will only be called for dynamic provisioning
+// createNfsExportContent will only be called for dynamic provisioning. It
+// returns a nil content while the pods using the source PVC of an application
+// consistent nfsexport are quiescing.
 func (ctrl *csiNfsExportCommonController) createNfsExportContent(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExportContent, error) {
 	klog.Infof("createNfsExportContent: Creating content for nfsexport %s through the plugin ...", utils.NfsExportKey(nfsexport))
 
+	if ctrl.pvcLister == nil {
+		return nil, &blockedError{reason: crdv1.BlockedReasonDynamicProvisioningDisabled, message: errDynamicProvisioningDisabled.Error()}
+	}
+
 	// If PVC is not being deleted and finalizer is not added yet, a finalizer should be added to PVC until nfsexport is created
 	klog.V(5).Infof("createNfsExportContent: Check if PVC is not being deleted and add Finalizer for source of nfsexport [%s] if needed", nfsexport.Name)
 	err := ctrl.ensurePVCFinalizer(nfsexport)
@@ -647,13 +1014,37 @@ func (ctrl *csiNfsExportCommonController) createNfsExportContent(nfsexport *crdv
 
 	class, volume, contentName, nfsexporterSecretRef, err := ctrl.getCreateNfsExportInput(nfsexport)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get input parameters to create nfsexport %s: %q", nfsexport.Name, err)
+		inputErr := fmt.Errorf("failed to get input parameters to create nfsexport %s: %q", nfsexport.Name, err)
+		var blocked *blockedError
+		if errors.As(err, &blocked) {
+			return nil, &blockedError{reason: blocked.reason, message: blocked.message, err: inputErr}
+		}
+		return nil, inputErr
 	}
 
 	// Create VolumeNfsExportContent in the database
 	if volume.Spec.CSI == nil {
 		return nil, fmt.Errorf("cannot find CSI PersistentVolumeSource for volume %s", volume.Name)
 	}
+	volumeHandles, err := ctrl.getSourceVolumeHandles(nfsexport, class)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := ctrl.checkConsistencyGate(nfsexport, class)
+	if err != nil {
+		return nil, err
+	}
+	if !open {
+		return nil, nil
+	}
+	consistency, quiesced, err := ctrl.quiesceSourcePods(nfsexport, class)
+	if err != nil {
+		return nil, err
+	}
+	if !quiesced {
+		return nil, nil
+	}
 	nfsexportRef, err := ref.GetReference(scheme.Scheme, nfsexport)
 	if err != nil {
 		return nil, err
@@ -666,11 +1057,15 @@ func (ctrl *csiNfsExportCommonController) createNfsExportContent(nfsexport *crdv
 		Spec: crdv1.VolumeNfsExportContentSpec{
 			VolumeNfsExportRef: *nfsexportRef,
 			Source: crdv1.VolumeNfsExportContentSource{
-				VolumeHandle: &volume.Spec.CSI.VolumeHandle,
+				VolumeHandle:  &volume.Spec.CSI.VolumeHandle,
+				VolumeHandles: volumeHandles,
 			},
 			VolumeNfsExportClassName: &(class.Name),
 			DeletionPolicy:          class.DeletionPolicy,
 			Driver:                  class.Driver,
+			ExportPathHint:          nfsexport.Spec.ExportPathHint,
+			Mode:                    nfsexport.Spec.Mode,
+			CacheTier:               nfsexport.Spec.CacheTier,
 		},
 	}
 
@@ -686,6 +1081,10 @@ func (ctrl *csiNfsExportCommonController) createNfsExportContent(nfsexport *crdv
 		}
 	}
 
+	if len(validation.IsValidLabelValue(class.Driver)) == 0 {
+		metav1.SetMetaDataLabel(&nfsexportContent.ObjectMeta, utils.VolumeNfsExportContentDriverLabel, class.Driver)
+	}
+
 	if ctrl.preventVolumeModeConversion {
 		if volume.Spec.VolumeMode != nil {
 			nfsexportContent.Spec.SourceVolumeMode = volume.Spec.VolumeMode
@@ -693,6 +1092,17 @@ func (ctrl *csiNfsExportCommonController) createNfsExportContent(nfsexport *crdv
 		}
 	}
 
+	// Set AnnVolumeNfsExportCreationTimestamp to guard the binding against a reused nfsexport UID
+	if creationTimestamp := utils.GetNfsExportCreationTimestampForContent(nfsexport); creationTimestamp != "" {
+		klog.V(5).Infof("createNfsExportContent: set annotation [%s] on content [%s].", utils.AnnVolumeNfsExportCreationTimestamp, nfsexportContent.Name)
+		metav1.SetMetaDataAnnotation(&nfsexportContent.ObjectMeta, utils.AnnVolumeNfsExportCreationTimestamp, creationTimestamp)
+	}
+
+	// Record the source PV and its reclaim policy for the safety check of the deletion
+	klog.V(5).Infof("createNfsExportContent: set annotations [%s] and [%s] on content [%s].", utils.AnnSourceVolumeName, utils.AnnSourceVolumeReclaimPolicy, nfsexportContent.Name)
+	metav1.SetMetaDataAnnotation(&nfsexportContent.ObjectMeta, utils.AnnSourceVolumeName, volume.Name)
+	metav1.SetMetaDataAnnotation(&nfsexportContent.ObjectMeta, utils.AnnSourceVolumeReclaimPolicy, string(volume.Spec.PersistentVolumeReclaimPolicy))
+
 	// Set AnnDeletionSecretRefName and AnnDeletionSecretRefNamespace
 	if nfsexporterSecretRef != nil {
 		klog.V(5).Infof("createNfsExportContent: set annotation [%s] on content [%s].", utils.AnnDeletionSecretRefName, nfsexportContent.Name)
@@ -702,11 +1112,53 @@ func (ctrl *csiNfsExportCommonController) createNfsExportContent(nfsexport *crdv
 		metav1.SetMetaDataAnnotation(&nfsexportContent.ObjectMeta, utils.AnnDeletionSecretRefNamespace, nfsexporterSecretRef.Namespace)
 	}
 
+	// Set AnnRequestedBy, so that the operations of the sidecar on the
+	// content are audited as requested by the user of the nfsexport
+	if requestedBy, ok := nfsexport.Annotations[utils.AnnRequestedBy]; ok {
+		klog.V(5).Infof("createNfsExportContent: set annotation [%s] on content [%s].", utils.AnnRequestedBy, nfsexportContent.Name)
+		metav1.SetMetaDataAnnotation(&nfsexportContent.ObjectMeta, utils.AnnRequestedBy, requestedBy)
+	}
+
+	// Set the security context of exported files derived from the pods using the source PVC
+	if requested, _ := utils.IsExportSecurityContextFromPodRequested(class.Parameters); requested {
+		annotations, err := ctrl.getExportSecurityContext(nfsexport)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range annotations {
+			klog.V(5).Infof("createNfsExportContent: set annotation [%s] on content [%s].", key, nfsexportContent.Name)
+			metav1.SetMetaDataAnnotation(&nfsexportContent.ObjectMeta, key, value)
+		}
+	}
+
+	// Record the consistency of an export whose source pods were asked to quiesce
+	if consistency != "" {
+		klog.V(5).Infof("createNfsExportContent: set annotation [%s] on content [%s].", utils.AnnExportConsistency, nfsexportContent.Name)
+		metav1.SetMetaDataAnnotation(&nfsexportContent.ObjectMeta, utils.AnnExportConsistency, consistency)
+	}
+
+	// Record the annotations and labels of the nfsexport selected by the class
+	propagated, err := utils.GetPropagatedMetadataParameters(nfsexport, class.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	if len(propagated) > 0 {
+		value, err := utils.EncodePropagatedMetadata(propagated)
+		if err != nil {
+			return nil, err
+		}
+		klog.V(5).Infof("createNfsExportContent: set annotation [%s] on content [%s].", utils.AnnExportPropagatedMetadata, nfsexportContent.Name)
+		metav1.SetMetaDataAnnotation(&nfsexportContent.ObjectMeta, utils.AnnExportPropagatedMetadata, value)
+	}
+
 	var updateContent *crdv1.VolumeNfsExportContent
 	klog.V(5).Infof("volume nfsexport content %#v", nfsexportContent)
 	// Try to create the VolumeNfsExportContent object
 	klog.V(5).Infof("createNfsExportContent [%s]: trying to save volume nfsexport content %s", utils.NfsExportKey(nfsexport), nfsexportContent.Name)
-	if updateContent, err = ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Create(context.TODO(), nfsexportContent, metav1.CreateOptions{}); err == nil || apierrs.IsAlreadyExists(err) {
+	writeStart := ctrl.clock.Now()
+	updateContent, err = ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Create(context.TODO(), nfsexportContent, metav1.CreateOptions{})
+	ctrl.recordKubernetesWritePhase(nfsexport, writeStart)
+	if err == nil || apierrs.IsAlreadyExists(err) {
 		// Save succeeded.
 		if err != nil {
 			klog.V(3).Infof("volume nfsexport content %q for nfsexport %q already exists, reusing", nfsexportContent.Name, utils.NfsExportKey(nfsexport))
@@ -721,7 +1173,7 @@ func (ctrl *csiNfsExportCommonController) createNfsExportContent(nfsexport *crdv
 		strerr := fmt.Sprintf("Error creating volume nfsexport content object for nfsexport %s: %v.", utils.NfsExportKey(nfsexport), err)
 		klog.Error(strerr)
 		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "CreateNfsExportContentFailed", strerr)
-		return nil, newControllerUpdateError(utils.NfsExportKey(nfsexport), err.Error())
+		return nil, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
 	}
 
 	msg := fmt.Sprintf("Waiting for a nfsexport %s to be created by the CSI driver.", utils.NfsExportKey(nfsexport))
@@ -745,6 +1197,9 @@ func (ctrl *csiNfsExportCommonController) getCreateNfsExportInput(nfsexport *crd
 		class, err = ctrl.getNfsExportClass(*className)
 		if err != nil {
 			klog.Errorf("getCreateNfsExportInput failed to getClassFromVolumeNfsExport %s", err)
+			if apierrs.IsNotFound(err) {
+				return nil, nil, "", nil, &blockedError{reason: crdv1.BlockedReasonClassNotFound, message: fmt.Sprintf("VolumeNfsExportClass %s does not exist", *className), err: err}
+			}
 			return nil, nil, "", nil, err
 		}
 	} else {
@@ -752,6 +1207,24 @@ func (ctrl *csiNfsExportCommonController) getCreateNfsExportInput(nfsexport *crd
 		return nil, nil, "", nil, fmt.Errorf("failed to take nfsexport %s without a nfsexport class", nfsexport.Name)
 	}
 
+	if nfsexport.Spec.ExportPathHint != nil {
+		if err := utils.ValidateExportPathHint(*nfsexport.Spec.ExportPathHint, class.Parameters); err != nil {
+			klog.Errorf("getCreateNfsExportInput failed to validate export path hint of nfsexport %s: %v", nfsexport.Name, err)
+			return nil, nil, "", nil, err
+		}
+	}
+	// The default Live mode must be supported by the class too.
+	if err := utils.ValidateExportMode(utils.GetExportMode(nfsexport.Spec.Mode), class.Parameters); err != nil {
+		klog.Errorf("getCreateNfsExportInput failed to validate export mode of nfsexport %s: %v", nfsexport.Name, err)
+		return nil, nil, "", nil, err
+	}
+	if nfsexport.Spec.CacheTier != nil {
+		if err := utils.ValidateExportCacheTier(*nfsexport.Spec.CacheTier, class.Parameters); err != nil {
+			klog.Errorf("getCreateNfsExportInput failed to validate cache tier of nfsexport %s: %v", nfsexport.Name, err)
+			return nil, nil, "", nil, err
+		}
+	}
+
 	volume, err := ctrl.getVolumeFromVolumeNfsExport(nfsexport)
 	if err != nil {
 		klog.Errorf("getCreateNfsExportInput failed to get PersistentVolume object [%s]: Error: [%#v]", nfsexport.Name, err)
@@ -800,17 +1273,19 @@ func (ctrl *csiNfsExportCommonController) updateNfsExportErrorStatusWithEvent(nf
 	}
 	statusError := &crdv1.VolumeNfsExportError{
 		Time: &metav1.Time{
-			Time: time.Now(),
+			Time: ctrl.clock.Now(),
 		},
 		Message: &message,
 	}
 	nfsexportClone.Status.Error = statusError
+	nfsexportClone.Status.ErrorSummary = utils.GetVolumeNfsExportErrorSummary(statusError)
 	// Only update ReadyToUse in VolumeNfsExport's Status to false if setReadyToFalse is true.
 	if setReadyToFalse {
 		ready := false
 		nfsexportClone.Status.ReadyToUse = &ready
 	}
-	newNfsExport, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexportClone.Namespace).UpdateStatus(context.TODO(), nfsexportClone, metav1.UpdateOptions{})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
 
 	// Emit the event even if the status update fails so that user can see the error
 	ctrl.eventRecorder.Event(newNfsExport, eventtype, reason, message)
@@ -831,25 +1306,9 @@ func (ctrl *csiNfsExportCommonController) updateNfsExportErrorStatusWithEvent(nf
 
 // addContentFinalizer adds a Finalizer for VolumeNfsExportContent.
 func (ctrl *csiNfsExportCommonController) addContentFinalizer(content *crdv1.VolumeNfsExportContent) error {
-	var patches []utils.PatchOp
-	if len(content.Finalizers) > 0 {
-		// Add to the end of the finalizers if we have any other finalizers
-		patches = append(patches, utils.PatchOp{
-			Op:    "add",
-			Path:  "/metadata/finalizers/-",
-			Value: utils.VolumeNfsExportContentFinalizer,
-		})
-	} else {
-		// Replace finalizers with new array if there are no other finalizers
-		patches = append(patches, utils.PatchOp{
-			Op:    "add",
-			Path:  "/metadata/finalizers",
-			Value: []string{utils.VolumeNfsExportContentFinalizer},
-		})
-	}
-	newContent, err := utils.PatchVolumeNfsExportContent(content, patches, ctrl.clientset)
+	newContent, err := utils.AddVolumeNfsExportContentFinalizers(content, ctrl.clientset, utils.VolumeNfsExportContentFinalizer)
 	if err != nil {
-		return newControllerUpdateError(content.Name, err.Error())
+		return utils.NewControllerUpdateError(content.Name, err)
 	}
 
 	_, err = ctrl.storeContentUpdate(newContent)
@@ -861,26 +1320,55 @@ func (ctrl *csiNfsExportCommonController) addContentFinalizer(content *crdv1.Vol
 	return nil
 }
 
-// isVolumeBeingCreatedFromNfsExport checks if an volume is being created from the nfsexport.
-func (ctrl *csiNfsExportCommonController) isVolumeBeingCreatedFromNfsExport(nfsexport *crdv1.VolumeNfsExport) bool {
+// getSourceVolumeHandles returns the handles of the volumes of the further
+// source PVCs of a nfsexport merging several volumes, see
+// VolumeNfsExportSpec.Sources. The volumes must be of the driver of class.
+func (ctrl *csiNfsExportCommonController) getSourceVolumeHandles(nfsexport *crdv1.VolumeNfsExport, class *crdv1.VolumeNfsExportClass) ([]string, error) {
+	var volumeHandles []string
+	for _, claimName := range nfsexport.Spec.Sources {
+		pvc, err := ctrl.pvcLister.PersistentVolumeClaims(nfsexport.Namespace).Get(claimName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve PVC %s from the lister: %q", claimName, err)
+		}
+		volume, err := ctrl.getVolumeFromClaim(nfsexport, pvc)
+		if err != nil {
+			return nil, err
+		}
+		if volume.Spec.CSI == nil || volume.Spec.CSI.Driver != class.Driver {
+			return nil, fmt.Errorf("volume %s of PVC %s is not a volume of CSI driver %s and cannot be merged into the export", volume.Name, claimName, class.Driver)
+		}
+		volumeHandles = append(volumeHandles, volume.Spec.CSI.VolumeHandle)
+	}
+	return volumeHandles, nil
+}
+
+// getClaimBeingCreatedFromNfsExport returns the name of a PVC whose volume is
+// being created from the nfsexport, or an empty string if there is none.
+func (ctrl *csiNfsExportCommonController) getClaimBeingCreatedFromNfsExport(nfsexport *crdv1.VolumeNfsExport) string {
+	if ctrl.pvcLister == nil {
+		// Without access to the PVCs, restores cannot be detected. The
+		// nfsexport is deleted even if a PVC is being restored from it.
+		klog.V(4).Infof("getClaimBeingCreatedFromNfsExport: cannot check the PVCs restored from nfsexport %s in content-only mode", utils.NfsExportKey(nfsexport))
+		return ""
+	}
 	pvcList, err := ctrl.pvcLister.PersistentVolumeClaims(nfsexport.Namespace).List(labels.Everything())
 	if err != nil {
 		klog.Errorf("Failed to retrieve PVCs from the lister to check if volume nfsexport %s is being used by a volume: %q", utils.NfsExportKey(nfsexport), err)
-		return false
+		return ""
 	}
 	for _, pvc := range pvcList {
 		if pvc.Spec.DataSource != nil && pvc.Spec.DataSource.Name == nfsexport.Name {
 			if pvc.Spec.DataSource.Kind == nfsexportKind && *(pvc.Spec.DataSource.APIGroup) == nfsexportAPIGroup {
 				if pvc.Status.Phase == v1.ClaimPending {
 					// A volume is being created from the nfsexport
-					klog.Infof("isVolumeBeingCreatedFromNfsExport: volume %s is being created from nfsexport %s", pvc.Name, pvc.Spec.DataSource.Name)
-					return true
+					klog.Infof("getClaimBeingCreatedFromNfsExport: volume %s is being created from nfsexport %s", pvc.Name, pvc.Spec.DataSource.Name)
+					return pvc.Name
 				}
 			}
 		}
 	}
-	klog.V(5).Infof("isVolumeBeingCreatedFromNfsExport: no volume is being created from nfsexport %s", utils.NfsExportKey(nfsexport))
-	return false
+	klog.V(5).Infof("getClaimBeingCreatedFromNfsExport: no volume is being created from nfsexport %s", utils.NfsExportKey(nfsexport))
+	return ""
 }
 
 // ensurePVCFinalizer checks if a Finalizer needs to be added for the nfsexport source;
@@ -895,9 +1383,30 @@ func (ctrl *csiNfsExportCommonController) ensurePVCFinalizer(nfsexport *crdv1.Vo
 	pvc, err := ctrl.getClaimFromVolumeNfsExport(nfsexport)
 	if err != nil {
 		klog.Infof("cannot get claim from nfsexport [%s]: [%v] Claim may be deleted already.", nfsexport.Name, err)
-		return newControllerUpdateError(nfsexport.Name, "cannot get claim from nfsexport")
+		return utils.NewControllerUpdateError(nfsexport.Name, fmt.Errorf("cannot get claim from nfsexport"))
+	}
+	if err := ctrl.ensureClaimFinalizer(nfsexport, pvc); err != nil {
+		return err
+	}
+
+	// The further sources of a nfsexport merging several volumes are
+	// protected as well
+	for _, claimName := range nfsexport.Spec.Sources {
+		pvc, err := ctrl.pvcLister.PersistentVolumeClaims(nfsexport.Namespace).Get(claimName)
+		if err != nil {
+			klog.Infof("cannot get claim %s from nfsexport [%s]: [%v] Claim may be deleted already.", claimName, nfsexport.Name, err)
+			return utils.NewControllerUpdateError(nfsexport.Name, fmt.Errorf("cannot get claim %s from nfsexport", claimName))
+		}
+		if err := ctrl.ensureClaimFinalizer(nfsexport, pvc); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+// ensureClaimFinalizer adds the PVCFinalizer to a source PVC of nfsexport, if
+// it is not there yet.
+func (ctrl *csiNfsExportCommonController) ensureClaimFinalizer(nfsexport *crdv1.VolumeNfsExport, pvc *v1.PersistentVolumeClaim) error {
 	if utils.ContainsString(pvc.ObjectMeta.Finalizers, utils.PVCFinalizer) {
 		klog.Infof("Protection finalizer already exists for persistent volume claim %s/%s", pvc.Namespace, pvc.Name)
 		return nil
@@ -905,15 +1414,15 @@ func (ctrl *csiNfsExportCommonController) ensurePVCFinalizer(nfsexport *crdv1.Vo
 
 	if pvc.ObjectMeta.DeletionTimestamp != nil {
 		klog.Errorf("cannot add finalizer on claim [%s/%s] for nfsexport [%s/%s]: claim is being deleted", pvc.Namespace, pvc.Name, nfsexport.Namespace, nfsexport.Name)
-		return newControllerUpdateError(pvc.Name, "cannot add finalizer on claim because it is being deleted")
+		return utils.NewControllerUpdateError(pvc.Name, fmt.Errorf("cannot add finalizer on claim because it is being deleted"))
 	} else {
 		// If PVC is not being deleted and PVCFinalizer is not added yet, add the PVCFinalizer.
 		pvcClone := pvc.DeepCopy()
 		pvcClone.ObjectMeta.Finalizers = append(pvcClone.ObjectMeta.Finalizers, utils.PVCFinalizer)
-		_, err = ctrl.client.CoreV1().PersistentVolumeClaims(pvcClone.Namespace).Update(context.TODO(), pvcClone, metav1.UpdateOptions{})
+		_, err := ctrl.client.CoreV1().PersistentVolumeClaims(pvcClone.Namespace).Update(context.TODO(), pvcClone, metav1.UpdateOptions{})
 		if err != nil {
 			klog.Errorf("cannot add finalizer on claim [%s/%s] for nfsexport [%s/%s]: [%v]", pvc.Namespace, pvc.Name, nfsexport.Namespace, nfsexport.Name, err)
-			return newControllerUpdateError(pvcClone.Name, err.Error())
+			return utils.NewControllerUpdateError(pvcClone.Name, err)
 		}
 		klog.Infof("Added protection finalizer to persistent volume claim %s/%s", pvc.Namespace, pvc.Name)
 	}
@@ -931,7 +1440,7 @@ func (ctrl *csiNfsExportCommonController) removePVCFinalizer(pvc *v1.PersistentV
 
 	_, err := ctrl.client.CoreV1().PersistentVolumeClaims(pvcClone.Namespace).Update(context.TODO(), pvcClone, metav1.UpdateOptions{})
 	if err != nil {
-		return newControllerUpdateError(pvcClone.Name, err.Error())
+		return utils.NewControllerUpdateError(pvcClone.Name, err)
 	}
 
 	klog.V(5).Infof("Removed protection finalizer from persistent volume claim %s", pvc.Name)
@@ -943,16 +1452,30 @@ func (ctrl *csiNfsExportCommonController) removePVCFinalizer(pvc *v1.PersistentV
 func (ctrl *csiNfsExportCommonController) isPVCBeingUsed(pvc *v1.PersistentVolumeClaim, nfsexport *crdv1.VolumeNfsExport, skipCurrentNfsExport bool) bool {
 	klog.V(5).Infof("Checking isPVCBeingUsed for nfsexport [%s]", utils.NfsExportKey(nfsexport))
 
-	// Going through nfsexports in the cache (nfsexportLister). If a nfsexport's PVC source
-	// is the same as the input nfsexport's PVC source and nfsexport's ReadyToUse status
-	// is false, the nfsexport is still being created from the PVC and the PVC is in-use.
-	nfsexports, err := ctrl.nfsexportLister.VolumeNfsExports(nfsexport.Namespace).List(labels.Everything())
+	skipNfsExportName := ""
+	if skipCurrentNfsExport {
+		skipNfsExportName = nfsexport.Name
+	}
+	inUse, err := ctrl.isPVCUsedByUnreadyNfsExport(pvc, skipNfsExportName)
 	if err != nil {
 		return false
 	}
+	return inUse
+}
+
+// isPVCUsedByUnreadyNfsExport checks if a PVC is the source of a nfsexport in the
+// cache which is not ready yet, other than the nfsexport named skipNfsExportName.
+func (ctrl *csiNfsExportCommonController) isPVCUsedByUnreadyNfsExport(pvc *v1.PersistentVolumeClaim, skipNfsExportName string) (bool, error) {
+	// Going through nfsexports in the cache (nfsexportLister). If a nfsexport's PVC source
+	// is the PVC and nfsexport's ReadyToUse status is false, the nfsexport is still being
+	// created from the PVC and the PVC is in-use.
+	nfsexports, err := ctrl.nfsexportLister.VolumeNfsExports(pvc.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
 	for _, snap := range nfsexports {
 		// Skip the current nfsexport
-		if skipCurrentNfsExport && snap.Name == nfsexport.Name {
+		if skipNfsExportName != "" && snap.Name == skipNfsExportName {
 			continue
 		}
 		// Skip pre-provisioned nfsexport without a PVC source
@@ -960,14 +1483,23 @@ func (ctrl *csiNfsExportCommonController) isPVCBeingUsed(pvc *v1.PersistentVolum
 			klog.V(4).Infof("Skipping static bound nfsexport %s when checking PVC %s/%s", snap.Name, pvc.Namespace, pvc.Name)
 			continue
 		}
-		if snap.Spec.Source.PersistentVolumeClaimName != nil && pvc.Name == *snap.Spec.Source.PersistentVolumeClaimName && !utils.IsNfsExportReady(snap) {
+		if isNfsExportSourceClaim(snap, pvc.Name) && !utils.IsNfsExportReady(snap) {
 			klog.V(2).Infof("Keeping PVC %s/%s, it is used by nfsexport %s/%s", pvc.Namespace, pvc.Name, snap.Namespace, snap.Name)
-			return true
+			return true, nil
 		}
 	}
 
-	klog.V(5).Infof("isPVCBeingUsed: no nfsexport is being created from PVC %s/%s", pvc.Namespace, pvc.Name)
-	return false
+	klog.V(5).Infof("isPVCUsedByUnreadyNfsExport: no nfsexport is being created from PVC %s/%s", pvc.Namespace, pvc.Name)
+	return false, nil
+}
+
+// isNfsExportSourceClaim returns true if the PVC named claimName is a source
+// of nfsexport, including the further sources of VolumeNfsExportSpec.Sources.
+func isNfsExportSourceClaim(nfsexport *crdv1.VolumeNfsExport, claimName string) bool {
+	if nfsexport.Spec.Source.PersistentVolumeClaimName == nil {
+		return false
+	}
+	return *nfsexport.Spec.Source.PersistentVolumeClaimName == claimName || utils.ContainsString(nfsexport.Spec.Sources, claimName)
 }
 
 // checkandRemovePVCFinalizer checks if the nfsexport source finalizer should be removed
@@ -978,6 +1510,10 @@ func (ctrl *csiNfsExportCommonController) checkandRemovePVCFinalizer(nfsexport *
 		// PVC finalizer is only needed for dynamic provisioning
 		return nil
 	}
+	if ctrl.pvcLister == nil {
+		// No finalizer is added to PVCs in content-only mode.
+		return nil
+	}
 
 	// Get nfsexport source which is a PVC
 	pvc, err := ctrl.getClaimFromVolumeNfsExport(nfsexport)
@@ -988,6 +1524,25 @@ func (ctrl *csiNfsExportCommonController) checkandRemovePVCFinalizer(nfsexport *
 
 	klog.V(5).Infof("checkandRemovePVCFinalizer for nfsexport [%s]: nfsexport status [%#v]", nfsexport.Name, nfsexport.Status)
 
+	if err := ctrl.checkandRemoveClaimFinalizer(nfsexport, pvc, skipCurrentNfsExport); err != nil {
+		return err
+	}
+	for _, claimName := range nfsexport.Spec.Sources {
+		pvc, err := ctrl.pvcLister.PersistentVolumeClaims(nfsexport.Namespace).Get(claimName)
+		if err != nil {
+			klog.Infof("cannot get claim %s from nfsexport [%s]: [%v] Claim may be deleted already. No need to remove finalizer on the claim.", claimName, nfsexport.Name, err)
+			continue
+		}
+		if err := ctrl.checkandRemoveClaimFinalizer(nfsexport, pvc, skipCurrentNfsExport); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkandRemoveClaimFinalizer removes the PVCFinalizer from a source PVC of
+// nfsexport if no nfsexport in creation uses the PVC.
+func (ctrl *csiNfsExportCommonController) checkandRemoveClaimFinalizer(nfsexport *crdv1.VolumeNfsExport, pvc *v1.PersistentVolumeClaim, skipCurrentNfsExport bool) error {
 	// Check if there is a Finalizer on PVC to be removed
 	if utils.ContainsString(pvc.ObjectMeta.Finalizers, utils.PVCFinalizer) {
 		// There is a Finalizer on PVC. Check if PVC is used
@@ -995,7 +1550,7 @@ func (ctrl *csiNfsExportCommonController) checkandRemovePVCFinalizer(nfsexport *
 		inUse := ctrl.isPVCBeingUsed(pvc, nfsexport, skipCurrentNfsExport)
 		if !inUse {
 			klog.Infof("checkandRemovePVCFinalizer[%s]: Remove Finalizer for PVC %s as it is not used by nfsexports in creation", nfsexport.Name, pvc.Name)
-			err = ctrl.removePVCFinalizer(pvc)
+			err := ctrl.removePVCFinalizer(pvc)
 			if err != nil {
 				klog.Errorf("checkandRemovePVCFinalizer [%s]: removePVCFinalizer failed to remove finalizer %v", nfsexport.Name, err)
 				return err
@@ -1006,26 +1561,126 @@ func (ctrl *csiNfsExportCommonController) checkandRemovePVCFinalizer(nfsexport *
 	return nil
 }
 
-// The function checks whether the volumeNfsExportRef in the nfsexport content matches
-// the given nfsexport. If match, it binds the content with the nfsexport. This is for
-// static binding where user has specified nfsexport name but not UID of the nfsexport
-// in content.Spec.VolumeNfsExportRef.
-func (ctrl *csiNfsExportCommonController) checkandBindNfsExportContent(nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
-	if content.Spec.VolumeNfsExportRef.Name != nfsexport.Name {
-		return nil, fmt.Errorf("Could not bind nfsexport %s and content %s, the VolumeNfsExportRef does not match", nfsexport.Name, content.Name)
-	} else if content.Spec.VolumeNfsExportRef.UID != "" && content.Spec.VolumeNfsExportRef.UID != nfsexport.UID {
-		return nil, fmt.Errorf("Could not bind nfsexport %s and content %s, the VolumeNfsExportRef does not match", nfsexport.Name, content.Name)
-	} else if content.Spec.VolumeNfsExportRef.UID != "" && content.Spec.VolumeNfsExportClassName != nil {
-		return content, nil
+// sweepPVCFinalizers removes the PVCFinalizer from PVCs that are not the source of
+// any nfsexport being created. The finalizer is normally removed once the nfsexports
+// using the PVC are ready or deleted, but it is left behind if the controller
+// crashes in between, which blocks the deletion of the PVC forever.
+func (ctrl *csiNfsExportCommonController) sweepPVCFinalizers() {
+	pvcs, err := ctrl.pvcLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("sweepPVCFinalizers: failed to list PVCs: %v", err)
+		return
+	}
+	for _, pvc := range pvcs {
+		if !utils.ContainsString(pvc.ObjectMeta.Finalizers, utils.PVCFinalizer) {
+			continue
+		}
+		inUse, err := ctrl.isPVCUsedByUnreadyNfsExport(pvc, "")
+		if err != nil {
+			klog.Errorf("sweepPVCFinalizers: failed to check if PVC %s/%s is used by nfsexports: %v", pvc.Namespace, pvc.Name, err)
+			continue
+		}
+		if inUse {
+			continue
+		}
+		klog.Infof("sweepPVCFinalizers: removing leftover finalizer from PVC %s/%s, it is not used by nfsexports in creation", pvc.Namespace, pvc.Name)
+		if err := ctrl.removePVCFinalizer(pvc); err != nil {
+			klog.Errorf("sweepPVCFinalizers: failed to remove finalizer from PVC %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		}
 	}
+}
 
-	patches := []utils.PatchOp{
+// sweepNeverBoundContents deletes dynamically created contents whose nfsexport
+// no longer exists and that never got a status. A nfsexport deleted right
+// after its content is created, before it is bound to it, leaves the content
+// behind with a reference to the deleted nfsexport. syncContent does not
+// delete contents of missing nfsexports, see the note there, but these contents
+// have no export on the storage system yet.
+func (ctrl *csiNfsExportCommonController) sweepNeverBoundContents() {
+	contents, err := ctrl.contentLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("sweepNeverBoundContents: failed to list contents: %v", err)
+		return
+	}
+	for _, content := range contents {
+		if !ctrl.isNeverBoundContent(content) {
+			continue
+		}
+		klog.Infof("sweepNeverBoundContents: deleting content %s, its nfsexport %s was deleted before the content got a status", content.Name, utils.NfsExportRefKey(&content.Spec.VolumeNfsExportRef))
+		// The annotation lets the sidecar remove the content finalizer
+		// instead of creating the export.
+		if _, err := ctrl.setAnnVolumeNfsExportBeingDeleted(content.DeepCopy()); err != nil {
+			klog.Errorf("sweepNeverBoundContents: failed to set VolumeNfsExportBeingDeleted annotation on content %s: %v", content.Name, err)
+			continue
+		}
+		err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Delete(context.TODO(), content.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			klog.Errorf("sweepNeverBoundContents: failed to delete content %s: %v", content.Name, err)
+		}
+	}
+}
+
+// isNeverBoundContent returns true if content was created for a nfsexport that
+// no longer exists, has no status and is older than
+// neverBoundContentGracePeriod. The nfsexport is looked up on the API server,
+// the cache may lag behind.
+func (ctrl *csiNfsExportCommonController) isNeverBoundContent(content *crdv1.VolumeNfsExportContent) bool {
+	ref := content.Spec.VolumeNfsExportRef
+	if content.Spec.Source.VolumeHandle == nil || ref.UID == "" || content.Status != nil || content.ObjectMeta.DeletionTimestamp != nil {
+		return false
+	}
+	// A CreateNfsExport call may be in progress.
+	if metav1.HasAnnotation(content.ObjectMeta, utils.AnnVolumeNfsExportBeingCreated) {
+		return false
+	}
+	if ctrl.clock.Since(content.CreationTimestamp.Time) < ctrl.neverBoundContentGracePeriod {
+		return false
+	}
+	nfsexport, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	if err == nil {
+		return nfsexport.UID != ref.UID
+	}
+	if !apierrs.IsNotFound(err) {
+		klog.Errorf("sweepNeverBoundContents: failed to get nfsexport %s of content %s: %v", utils.NfsExportRefKey(&ref), content.Name, err)
+		return false
+	}
+	return true
+}
+
+// The function checks whether the volumeNfsExportRef in the nfsexport content matches
+// the given nfsexport. If match, it binds the content with the nfsexport. This is for
+// static binding where user has specified nfsexport name but not UID of the nfsexport
+// in content.Spec.VolumeNfsExportRef.
+func (ctrl *csiNfsExportCommonController) checkandBindNfsExportContent(nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
+	if content.Spec.VolumeNfsExportRef.Name != nfsexport.Name {
+		return nil, fmt.Errorf("Could not bind nfsexport %s and content %s, the VolumeNfsExportRef does not match", nfsexport.Name, content.Name)
+	} else if content.Spec.VolumeNfsExportRef.UID != "" && content.Spec.VolumeNfsExportRef.UID != nfsexport.UID {
+		return nil, fmt.Errorf("Could not bind nfsexport %s and content %s, the VolumeNfsExportRef does not match", nfsexport.Name, content.Name)
+	} else if !utils.IsVolumeNfsExportCreationTimestampMatched(nfsexport, content) {
+		return nil, fmt.Errorf("Could not bind nfsexport %s and content %s, the VolumeNfsExport creation timestamp does not match", nfsexport.Name, content.Name)
+	} else if content.Spec.VolumeNfsExportRef.UID != "" && content.Spec.VolumeNfsExportClassName != nil {
+		return content, nil
+	}
+
+	patches := []utils.PatchOp{
 		{
 			Op:    "replace",
 			Path:  "/spec/volumeNfsExportRef/uid",
 			Value: string(nfsexport.UID),
 		},
 	}
+	if creationTimestamp := utils.GetNfsExportCreationTimestampForContent(nfsexport); creationTimestamp != "" && !metav1.HasAnnotation(content.ObjectMeta, utils.AnnVolumeNfsExportCreationTimestamp) {
+		annotations := make(map[string]string)
+		for k, v := range content.ObjectMeta.Annotations {
+			annotations[k] = v
+		}
+		annotations[utils.AnnVolumeNfsExportCreationTimestamp] = creationTimestamp
+		patches = append(patches, utils.PatchOp{
+			Op:    "add",
+			Path:  "/metadata/annotations",
+			Value: annotations,
+		})
+	}
 	if nfsexport.Spec.VolumeNfsExportClassName != nil {
 		className := *(nfsexport.Spec.VolumeNfsExportClassName)
 		patches = append(patches, utils.PatchOp{
@@ -1096,29 +1751,180 @@ func (ctrl *csiNfsExportCommonController) needsUpdateNfsExportStatus(nfsexport *
 	if nfsexport.Status.BoundVolumeNfsExportContentName == nil {
 		return true
 	}
+	if nfsexport.Status.ObservedGeneration != nfsexport.Generation {
+		return true
+	}
 	if nfsexport.Status.CreationTime == nil && content.Status.CreationTime != nil {
 		return true
 	}
 	if nfsexport.Status.ReadyToUse == nil && content.Status.ReadyToUse != nil {
 		return true
 	}
-	if nfsexport.Status.ReadyToUse != nil && content.Status.ReadyToUse != nil && nfsexport.Status.ReadyToUse != content.Status.ReadyToUse {
+	if nfsexport.Status.ReadyToUse != nil && content.Status.ReadyToUse != nil && *nfsexport.Status.ReadyToUse != *content.Status.ReadyToUse {
+		return true
+	}
+	if !utils.IsVolumeNfsExportErrorEqual(nfsexport.Status.Error, content.Status.Error) {
+		return true
+	}
+	if restoreSizeNeedsUpdate(nfsexport.Status.RestoreSize, content.Status.RestoreSize) {
+		return true
+	}
+	if nfsexport.Status.ExportPath == nil && content.Status.ExportPath != nil {
+		return true
+	}
+	if nfsexport.Status.Zone == nil && content.Status.Zone != nil {
 		return true
 	}
-	if nfsexport.Status.RestoreSize == nil && content.Status.RestoreSize != nil {
+	if contentConditionsNeedUpdate(nfsexport.Status.Conditions, content.Status.Conditions) {
 		return true
 	}
-	if nfsexport.Status.RestoreSize != nil && nfsexport.Status.RestoreSize.IsZero() && content.Status.RestoreSize != nil && *content.Status.RestoreSize > 0 {
+	if len(content.Status.Attributes) > 0 && !reflect.DeepEqual(nfsexport.Status.Attributes, content.Status.Attributes) {
 		return true
 	}
 
 	return false
 }
 
-// UpdateNfsExportStatus updates nfsexport status based on content status
+// propagatedContentConditions are the conditions of a content copied to the
+// status of its nfsexport.
+var propagatedContentConditions = []string{crdv1.ConditionWarming, crdv1.ConditionFailed}
+
+// contentConditionsNeedUpdate returns true if a propagated condition of a
+// content is not reflected in the conditions of its nfsexport.
+func contentConditionsNeedUpdate(current, contentConditions []metav1.Condition) bool {
+	for _, condType := range propagatedContentConditions {
+		contentCond := meta.FindStatusCondition(contentConditions, condType)
+		if contentCond == nil {
+			continue
+		}
+		cond := meta.FindStatusCondition(current, condType)
+		if cond == nil || cond.Status != contentCond.Status || cond.Reason != contentCond.Reason || cond.Message != contentCond.Message {
+			return true
+		}
+	}
+	return false
+}
+
+// copyContentConditions copies the propagated conditions of a content to
+// conditions.
+func copyContentConditions(conditions *[]metav1.Condition, contentConditions []metav1.Condition) {
+	for _, condType := range propagatedContentConditions {
+		if cond := meta.FindStatusCondition(contentConditions, condType); cond != nil {
+			meta.SetStatusCondition(conditions, *cond)
+		}
+	}
+}
+
+// isNfsExportCreationTimedOut returns true if the Failed condition of
+// nfsexport reports that it did not become ready within the creation timeout
+// of its class.
+func isNfsExportCreationTimedOut(nfsexport *crdv1.VolumeNfsExport) bool {
+	if nfsexport.Status == nil {
+		return false
+	}
+	cond := meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionFailed)
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == crdv1.FailedReasonCreationTimedOut
+}
+
+// restoreSizeNeedsUpdate returns true if the restore size in nfsexport status
+// differs from the size in bytes reported in content status.
+// The quantity is compared by its int64 value rather than by its representation,
+// as the same size may be serialized either as "1Gi" or "1073741824" and should
+// not trigger a status update.
+func restoreSizeNeedsUpdate(current *resource.Quantity, size *int64) bool {
+	if size == nil {
+		return false
+	}
+	if current == nil {
+		return true
+	}
+	return current.Value() != *size
+}
+
+// UpdateNfsExportStatus updates nfsexport status based on content status.
+// The status is first computed from nfsexport, usually read from the informer
+// cache, and applied with its resourceVersion as a precondition. Only if the
+// API server rejects the apply with a conflict because nfsexport is stale is
+// the nfsexport read from the API server and its status computed again. This
+// saves a round trip to the API server per status update in the common case.
 func (ctrl *csiNfsExportCommonController) updateNfsExportStatus(nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExport, error) {
 	klog.V(5).Infof("updateNfsExportStatus[%s]", utils.NfsExportKey(nfsexport))
+	klog.V(5).Infof("updateNfsExportStatus: updating VolumeNfsExport [%+v] based on VolumeNfsExportContentStatus [%+v]", nfsexport, content.Status)
+
+	nfsexportObj := nfsexport
+	newStatus, updated := ctrl.newNfsExportStatus(nfsexportObj, content)
+	writeStart := ctrl.clock.Now()
+	var newNfsExportObj *crdv1.VolumeNfsExport
+	var err error
+	if updated && nfsexportObj.ResourceVersion != "" {
+		newNfsExportObj, err = utils.ApplyVolumeNfsExportStatusIfUnmodified(nfsexportObj, newStatus, ctrl.statusClientset, utils.CommonControllerFieldManager)
+	}
+	if updated && (nfsexportObj.ResourceVersion == "" || apierrs.IsConflict(err)) {
+		klog.V(4).Infof("updateNfsExportStatus[%s]: nfsexport has been modified, reading it from the API server", utils.NfsExportKey(nfsexport))
+		nfsexportObj, err = ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexport.Namespace).Get(context.TODO(), nfsexport.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("error get nfsexport %s from api server: %v", utils.NfsExportKey(nfsexport), err)
+		}
+		newStatus, updated = ctrl.newNfsExportStatus(nfsexportObj, content)
+		if updated {
+			newNfsExportObj, err = utils.ApplyVolumeNfsExportStatus(nfsexportObj, newStatus, ctrl.statusClientset, utils.CommonControllerFieldManager)
+		}
+	}
+	if !updated {
+		return nfsexportObj, nil
+	}
+	ctrl.recordKubernetesWritePhase(nfsexport, writeStart)
+
+	nfsexportClone := nfsexportObj.DeepCopy()
+	nfsexportClone.Status = newStatus
+
+	// We need to record metrics even if updating the status fails due to a bug causing cache entries after a failed status update.
+	// Must meet the following criteria to emit a successful CreateNfsExport status
+	// 1. Previous status was nil OR Previous status had a nil CreationTime
+	// 2. New status must be non-nil with a non-nil CreationTime
+	driverName := content.Spec.Driver
+	createOperationKey := metrics.NewOperationKey(metrics.CreateNfsExportOperationName, nfsexport.UID)
+	if !utils.IsNfsExportCreated(nfsexportObj) && utils.IsNfsExportCreated(nfsexportClone) {
+		ctrl.metricsManager.RecordMetrics(createOperationKey, metrics.NewNfsExportOperationStatus(metrics.NfsExportStatusTypeSuccess), driverName)
+		msg := fmt.Sprintf("NfsExport %s was successfully created by the CSI driver.", utils.NfsExportKey(nfsexport))
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "NfsExportCreated", msg)
+	}
+
+	// Must meet the following criteria to emit a successful CreateNfsExportAndReady status
+	// 1. Previous status was nil OR Previous status had a nil ReadyToUse OR Previous status had a false ReadyToUse
+	// 2. New status must be non-nil with a ReadyToUse as true
+	createAndReadyOperation := metrics.NewOperationKey(metrics.CreateNfsExportAndReadyOperationName, nfsexport.UID)
+	becameReady := !utils.IsNfsExportReady(nfsexportObj) && utils.IsNfsExportReady(nfsexportClone)
+	if becameReady {
+		ctrl.recordCSIPhase(createAndReadyOperation, nfsexport, content)
+		msg := fmt.Sprintf("NfsExport %s is ready to use.", utils.NfsExportKey(nfsexport))
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "NfsExportReady", msg)
+	}
+
+	// The sidecar gave up on the export, the CreateNfsExportAndReady
+	// operation is over.
+	timedOut := !isNfsExportCreationTimedOut(nfsexportObj) && isNfsExportCreationTimedOut(nfsexportClone)
+	if timedOut {
+		msg := meta.FindStatusCondition(nfsexportClone.Status.Conditions, crdv1.ConditionFailed).Message
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "NfsExportCreationTimedOut", msg)
+	}
+
+	if becameReady {
+		ctrl.metricsManager.RecordMetrics(createAndReadyOperation, metrics.NewNfsExportOperationStatus(metrics.NfsExportStatusTypeSuccess), driverName)
+	}
+	if timedOut {
+		ctrl.metricsManager.RecordMetrics(createAndReadyOperation, metrics.NewNfsExportOperationStatus(metrics.NfsExportStatusTypeTimeout), driverName)
+	}
+	if err != nil {
+		return nil, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+
+	return newNfsExportObj, nil
+}
 
+// newNfsExportStatus returns the status of nfsexportObj updated from the
+// status of content, and whether it differs from the current status.
+func (ctrl *csiNfsExportCommonController) newNfsExportStatus(nfsexportObj *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportStatus, bool) {
 	boundContentName := content.Name
 	var createdAt *time.Time
 	if content.Status != nil && content.Status.CreationTime != nil {
@@ -1137,12 +1943,19 @@ func (ctrl *csiNfsExportCommonController) updateNfsExportStatus(nfsexport *crdv1
 	if content.Status != nil && content.Status.Error != nil {
 		volumeNfsExportErr = content.Status.Error.DeepCopy()
 	}
-
-	klog.V(5).Infof("updateNfsExportStatus: updating VolumeNfsExport [%+v] based on VolumeNfsExportContentStatus [%+v]", nfsexport, content.Status)
-
-	nfsexportObj, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexport.Namespace).Get(context.TODO(), nfsexport.Name, metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("error get nfsexport %s from api server: %v", utils.NfsExportKey(nfsexport), err)
+	var exportPath *string
+	if content.Status != nil && content.Status.ExportPath != nil {
+		exportPath = content.Status.ExportPath
+	}
+	var zone *string
+	if content.Status != nil && content.Status.Zone != nil {
+		zone = content.Status.Zone
+	}
+	var contentConditions []metav1.Condition
+	var attributes map[string]string
+	if content.Status != nil {
+		contentConditions = content.Status.Conditions
+		attributes = content.Status.Attributes
 	}
 
 	var newStatus *crdv1.VolumeNfsExportStatus
@@ -1160,6 +1973,20 @@ func (ctrl *csiNfsExportCommonController) updateNfsExportStatus(nfsexport *crdv1
 		}
 		if volumeNfsExportErr != nil {
 			newStatus.Error = volumeNfsExportErr
+			newStatus.ErrorSummary = utils.GetVolumeNfsExportErrorSummary(volumeNfsExportErr)
+		}
+		if exportPath != nil {
+			newStatus.ExportPath = exportPath
+		}
+		if zone != nil {
+			newStatus.Zone = zone
+		}
+		copyContentConditions(&newStatus.Conditions, contentConditions)
+		if len(attributes) > 0 {
+			newStatus.Attributes = attributes
+		}
+		if readyToUse {
+			newStatus.TimeToReady = ctrl.getTimeToReady(nfsexportObj)
 		}
 		updated = true
 	} else {
@@ -1177,53 +2004,92 @@ func (ctrl *csiNfsExportCommonController) updateNfsExportStatus(nfsexport *crdv1
 			updated = true
 			if readyToUse && newStatus.Error != nil {
 				newStatus.Error = nil
+				newStatus.ErrorSummary = nil
+			}
+			if readyToUse && newStatus.TimeToReady == nil {
+				newStatus.TimeToReady = ctrl.getTimeToReady(nfsexportObj)
 			}
 		}
-		if (newStatus.RestoreSize == nil && size != nil) || (newStatus.RestoreSize != nil && newStatus.RestoreSize.IsZero() && size != nil && *size > 0) {
+		if restoreSizeNeedsUpdate(newStatus.RestoreSize, size) {
 			newStatus.RestoreSize = resource.NewQuantity(*size, resource.BinarySI)
 			updated = true
 		}
-		if (newStatus.Error == nil && volumeNfsExportErr != nil) || (newStatus.Error != nil && volumeNfsExportErr != nil && newStatus.Error.Time != nil && volumeNfsExportErr.Time != nil && &newStatus.Error.Time != &volumeNfsExportErr.Time) || (newStatus.Error != nil && volumeNfsExportErr == nil) {
+		if newStatus.ExportPath == nil && exportPath != nil {
+			newStatus.ExportPath = exportPath
+			updated = true
+		}
+		if newStatus.Zone == nil && zone != nil {
+			newStatus.Zone = zone
+			updated = true
+		}
+		if contentConditionsNeedUpdate(newStatus.Conditions, contentConditions) {
+			copyContentConditions(&newStatus.Conditions, contentConditions)
+			updated = true
+		}
+		if !utils.IsVolumeNfsExportErrorEqual(newStatus.Error, volumeNfsExportErr) {
 			newStatus.Error = volumeNfsExportErr
+			newStatus.ErrorSummary = utils.GetVolumeNfsExportErrorSummary(volumeNfsExportErr)
+			updated = true
+		}
+		if len(attributes) > 0 && !reflect.DeepEqual(newStatus.Attributes, attributes) {
+			newStatus.Attributes = attributes
+			updated = true
+		}
+		if newStatus.ObservedGeneration != nfsexportObj.Generation {
 			updated = true
 		}
 	}
 
 	if updated {
-		nfsexportClone := nfsexportObj.DeepCopy()
-		nfsexportClone.Status = newStatus
-
-		// We need to record metrics before updating the status due to a bug causing cache entries after a failed UpdateStatus call.
-		// Must meet the following criteria to emit a successful CreateNfsExport status
-		// 1. Previous status was nil OR Previous status had a nil CreationTime
-		// 2. New status must be non-nil with a non-nil CreationTime
-		driverName := content.Spec.Driver
-		createOperationKey := metrics.NewOperationKey(metrics.CreateNfsExportOperationName, nfsexport.UID)
-		if !utils.IsNfsExportCreated(nfsexportObj) && utils.IsNfsExportCreated(nfsexportClone) {
-			ctrl.metricsManager.RecordMetrics(createOperationKey, metrics.NewNfsExportOperationStatus(metrics.NfsExportStatusTypeSuccess), driverName)
-			msg := fmt.Sprintf("NfsExport %s was successfully created by the CSI driver.", utils.NfsExportKey(nfsexport))
-			ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "NfsExportCreated", msg)
-		}
-
-		// Must meet the following criteria to emit a successful CreateNfsExportAndReady status
-		// 1. Previous status was nil OR Previous status had a nil ReadyToUse OR Previous status had a false ReadyToUse
-		// 2. New status must be non-nil with a ReadyToUse as true
-		if !utils.IsNfsExportReady(nfsexportObj) && utils.IsNfsExportReady(nfsexportClone) {
-			createAndReadyOperation := metrics.NewOperationKey(metrics.CreateNfsExportAndReadyOperationName, nfsexport.UID)
-			ctrl.metricsManager.RecordMetrics(createAndReadyOperation, metrics.NewNfsExportOperationStatus(metrics.NfsExportStatusTypeSuccess), driverName)
-			msg := fmt.Sprintf("NfsExport %s is ready to use.", utils.NfsExportKey(nfsexport))
-			ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "NfsExportReady", msg)
-		}
-
-		newNfsExportObj, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexportClone.Namespace).UpdateStatus(context.TODO(), nfsexportClone, metav1.UpdateOptions{})
-		if err != nil {
-			return nil, newControllerUpdateError(utils.NfsExportKey(nfsexport), err.Error())
-		}
+		ctrl.markNfsExportStatusSynced(newStatus, nfsexportObj)
+	}
+	return newStatus, updated
+}
 
-		return newNfsExportObj, nil
+// markNfsExportStatusSynced records in status that it is written now for the
+// current generation of the nfsexport.
+func (ctrl *csiNfsExportCommonController) markNfsExportStatusSynced(status *crdv1.VolumeNfsExportStatus, nfsexport *crdv1.VolumeNfsExport) {
+	status.ObservedGeneration = nfsexport.Generation
+	status.LastSyncTime = &metav1.Time{Time: ctrl.clock.Now()}
+}
+
+// getTimeToReady returns the time from the creation of the nfsexport until
+// now, rounded to seconds, to record in its status when it becomes ready.
+// It returns nil if the creation timestamp of the nfsexport is unknown.
+func (ctrl *csiNfsExportCommonController) getTimeToReady(nfsexport *crdv1.VolumeNfsExport) *metav1.Duration {
+	if nfsexport.CreationTimestamp.IsZero() {
+		return nil
 	}
+	return &metav1.Duration{Duration: ctrl.clock.Since(nfsexport.CreationTimestamp.Time).Round(time.Second)}
+}
+
+// recordKubernetesWritePhase records the time since start as spent writing to
+// the API server for the CreateNfsExportAndReady operation of the nfsexport.
+func (ctrl *csiNfsExportCommonController) recordKubernetesWritePhase(nfsexport *crdv1.VolumeNfsExport, start time.Time) {
+	ctrl.metricsManager.RecordOperationPhase(
+		metrics.NewOperationKey(metrics.CreateNfsExportAndReadyOperationName, nfsexport.UID),
+		metrics.OperationPhaseKubernetesWrite,
+		ctrl.clock.Since(start),
+	)
+}
 
-	return nfsexportObj, nil
+// recordCSIPhase records the time the CSI driver took to make a dynamically
+// provisioned content ready to use, from the creation of the content until
+// the sidecar reported it ready. Pre-provisioned contents are skipped as they
+// were not created for this operation.
+func (ctrl *csiNfsExportCommonController) recordCSIPhase(op metrics.OperationKey, nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) {
+	if nfsexport.Spec.Source.VolumeNfsExportContentName != nil {
+		return
+	}
+	if content.Status == nil || content.Status.LastTransitionTime == nil {
+		return
+	}
+	duration := content.Status.LastTransitionTime.Sub(content.CreationTimestamp.Time)
+	if duration < 0 {
+		// Clock skew between the API server and the sidecar.
+		return
+	}
+	ctrl.metricsManager.RecordOperationPhase(op, metrics.OperationPhaseCSI, duration)
 }
 
 func (ctrl *csiNfsExportCommonController) getVolumeFromVolumeNfsExport(nfsexport *crdv1.VolumeNfsExport) (*v1.PersistentVolume, error) {
@@ -1231,12 +2097,25 @@ func (ctrl *csiNfsExportCommonController) getVolumeFromVolumeNfsExport(nfsexport
 	if err != nil {
 		return nil, err
 	}
+	return ctrl.getVolumeFromClaim(nfsexport, pvc)
+}
 
+// getVolumeFromClaim returns the PV a source PVC of nfsexport is bound to.
+func (ctrl *csiNfsExportCommonController) getVolumeFromClaim(nfsexport *crdv1.VolumeNfsExport, pvc *v1.PersistentVolumeClaim) (*v1.PersistentVolume, error) {
 	if pvc.Status.Phase != v1.ClaimBound {
-		return nil, fmt.Errorf("the PVC %s is not yet bound to a PV, will not attempt to take a nfsexport", pvc.Name)
+		return nil, &blockedError{reason: crdv1.BlockedReasonSourcePVCNotBound, message: fmt.Sprintf("the PVC %s is not yet bound to a PV, will not attempt to take a nfsexport", pvc.Name)}
 	}
 
 	pvName := pvc.Spec.VolumeName
+	if ctrl.pvLister != nil {
+		// Only trust a cached PV that was cached in full and is already bound
+		// to the claim; otherwise the cache may be stripped or lagging behind.
+		pv, err := ctrl.pvLister.Get(pvName)
+		if err == nil && pv.Spec.CSI != nil && ctrl.isVolumeBoundToClaim(pv, pvc) {
+			klog.V(5).Infof("getVolumeFromVolumeNfsExport: nfsexport [%s] PV name [%s] found in cache", nfsexport.Name, pvName)
+			return pv.DeepCopy(), nil
+		}
+	}
 	pv, err := ctrl.client.CoreV1().PersistentVolumes().Get(context.TODO(), pvName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve PV %s from the API server: %q", pvName, err)
@@ -1325,6 +2204,12 @@ func (ctrl *csiNfsExportCommonController) getNfsExportDriverName(vs *crdv1.Volum
 		}
 	}
 
+	// Bound nfsexports have a content labeled with its driver, found through
+	// the index of the contents by nfsexport
+	if driverName := ctrl.getBoundContentDriverLabel(vs); driverName != "" {
+		return driverName, nil
+	}
+
 	// Dynamic nfsexports will have a nfsexportclass with a driver
 	if vs.Spec.VolumeNfsExportClassName != nil {
 		class, err := ctrl.getNfsExportClass(*vs.Spec.VolumeNfsExportClassName)
@@ -1338,6 +2223,32 @@ func (ctrl *csiNfsExportCommonController) getNfsExportDriverName(vs *crdv1.Volum
 	return driverName, nil
 }
 
+// getBoundContentDriverLabel returns the driver label of the content bound
+// to the nfsexport, or an empty string if there is no such content in the
+// cache or it is not labeled yet.
+func (ctrl *csiNfsExportCommonController) getBoundContentDriverLabel(vs *crdv1.VolumeNfsExport) string {
+	if ctrl.contentIndexer == nil {
+		return ""
+	}
+	objs, err := ctrl.contentIndexer.ByIndex(contentNfsExportIndex, utils.NfsExportKey(vs))
+	if err != nil {
+		klog.Errorf("getNfsExportDriverName: failed to get the contents of nfsexport %s from the index: %v", utils.NfsExportKey(vs), err)
+		return ""
+	}
+	for _, obj := range objs {
+		content, ok := obj.(*crdv1.VolumeNfsExportContent)
+		// Contents retained from a deleted nfsexport of the same name are
+		// indexed under the same key
+		if !ok || content.Spec.VolumeNfsExportRef.UID != vs.UID {
+			continue
+		}
+		if driverName := content.Labels[utils.VolumeNfsExportContentDriverLabel]; driverName != "" {
+			return driverName
+		}
+	}
+	return ""
+}
+
 // SetDefaultNfsExportClass is a helper function to figure out the default nfsexport class.
 // For pre-provisioned case, it's an no-op.
 // For dynamic provisioning, it gets the default NfsExportClasses in the system if there is any(could be multiple),
@@ -1371,11 +2282,17 @@ func (ctrl *csiNfsExportCommonController) SetDefaultNfsExportClass(nfsexport *cr
 		}
 	}
 	if len(defaultClasses) == 0 {
-		return nil, nfsexport, fmt.Errorf("cannot find default nfsexport class")
+		return nil, nfsexport, &defaultClassError{
+			reason:  crdv1.ClassMissingReasonNoDefault,
+			message: "cannot find default nfsexport class",
+		}
 	}
 	if len(defaultClasses) > 1 {
 		klog.V(4).Infof("get DefaultClass %d defaults found", len(defaultClasses))
-		return nil, nfsexport, fmt.Errorf("%d default nfsexport classes were found", len(defaultClasses))
+		return nil, nfsexport, &defaultClassError{
+			reason:  crdv1.ClassMissingReasonMultipleDefaults,
+			message: fmt.Sprintf("%d default nfsexport classes were found", len(defaultClasses)),
+		}
 	}
 	klog.V(5).Infof("setDefaultNfsExportClass [%s]: default VolumeNfsExportClassName [%s]", nfsexport.Name, defaultClasses[0].Name)
 	nfsexportClone := nfsexport.DeepCopy()
@@ -1393,104 +2310,630 @@ func (ctrl *csiNfsExportCommonController) SetDefaultNfsExportClass(nfsexport *cr
 	return defaultClasses[0], newNfsExport, nil
 }
 
-// getClaimFromVolumeNfsExport is a helper function to get PVC from VolumeNfsExport.
-func (ctrl *csiNfsExportCommonController) getClaimFromVolumeNfsExport(nfsexport *crdv1.VolumeNfsExport) (*v1.PersistentVolumeClaim, error) {
-	if nfsexport.Spec.Source.PersistentVolumeClaimName == nil {
-		return nil, fmt.Errorf("the nfsexport source PVC name is not specified")
+// defaultClassError is returned by SetDefaultNfsExportClass when the
+// VolumeNfsExportClasses of the cluster, rather than the nfsexport, prevent
+// choosing a default class. It is reported with the ClassMissing condition
+// of the nfsexport, see updateNfsExportClassMissing.
+type defaultClassError struct {
+	reason  string
+	message string
+}
+
+func (e *defaultClassError) Error() string {
+	return e.message
+}
+
+// setDefaultClassFailedMessage returns the message of the error status and
+// of the event of a nfsexport whose default class cannot be set.
+func setDefaultClassFailedMessage(err error) string {
+	return fmt.Sprintf("Failed to set default nfsexport class with error %v", err)
+}
+
+// isNfsExportClassMissing returns true if the ClassMissing condition of
+// nfsexport is "True".
+func isNfsExportClassMissing(nfsexport *crdv1.VolumeNfsExport) bool {
+	return nfsexport.Status != nil && meta.IsStatusConditionTrue(nfsexport.Status.Conditions, crdv1.ConditionClassMissing)
+}
+
+// updateNfsExportClassMissing sets the ClassMissing condition and the error
+// status of a nfsexport whose default class cannot be determined, and emits
+// a warning event. As nothing changes until a class does, repeated failures
+// with the same reason and message are only logged at a higher verbosity.
+// It returns nil once the condition is recorded, or the error of the status
+// update.
+func (ctrl *csiNfsExportCommonController) updateNfsExportClassMissing(nfsexport *crdv1.VolumeNfsExport, classErr *defaultClassError) error {
+	if nfsexport.Status != nil {
+		cond := meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionClassMissing)
+		if cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == classErr.reason && cond.Message == classErr.message {
+			klog.V(4).Infof("updateNfsExportClassMissing[%s]: %s is already reported", utils.NfsExportKey(nfsexport), classErr.message)
+			return nil
+		}
 	}
-	pvcName := *nfsexport.Spec.Source.PersistentVolumeClaimName
-	if pvcName == "" {
-		return nil, fmt.Errorf("the PVC name is not specified in nfsexport %s", utils.NfsExportKey(nfsexport))
+	klog.Warningf("updateNfsExportClassMissing[%s]: %s", utils.NfsExportKey(nfsexport), classErr.message)
+
+	nfsexportClone := nfsexport.DeepCopy()
+	if nfsexportClone.Status == nil {
+		nfsexportClone.Status = &crdv1.VolumeNfsExportStatus{}
+	}
+	message := setDefaultClassFailedMessage(classErr)
+	statusError := &crdv1.VolumeNfsExportError{
+		Time:    &metav1.Time{Time: ctrl.clock.Now()},
+		Message: &message,
 	}
+	nfsexportClone.Status.Error = statusError
+	nfsexportClone.Status.ErrorSummary = utils.GetVolumeNfsExportErrorSummary(statusError)
+	meta.SetStatusCondition(&nfsexportClone.Status.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionClassMissing,
+		Status:             metav1.ConditionTrue,
+		Reason:             classErr.reason,
+		Message:            classErr.message,
+		ObservedGeneration: nfsexport.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
+
+	// Emit the event even if the status update fails so that user can see the error
+	ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "SetDefaultNfsExportClassFailed", message)
 
-	pvc, err := ctrl.pvcLister.PersistentVolumeClaims(nfsexport.Namespace).Get(pvcName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve PVC %s from the lister: %q", pvcName, err)
+		klog.V(4).Infof("updating VolumeNfsExport[%s] class missing status failed %v", utils.NfsExportKey(nfsexport), err)
+		return err
 	}
-
-	return pvc, nil
+	if _, err = ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.V(4).Infof("updating VolumeNfsExport[%s] class missing status: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+	}
+	return nil
 }
 
-var _ error = controllerUpdateError{}
+// resolveNfsExportClassMissing turns the ClassMissing condition of a
+// nfsexport to "False" once it has got class, and clears the error status
+// set along with the condition.
+func (ctrl *csiNfsExportCommonController) resolveNfsExportClassMissing(nfsexport *crdv1.VolumeNfsExport, class *crdv1.VolumeNfsExportClass) (*crdv1.VolumeNfsExport, error) {
+	if !isNfsExportClassMissing(nfsexport) {
+		return nfsexport, nil
+	}
+	cond := meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionClassMissing)
+	klog.V(2).Infof("resolveNfsExportClassMissing[%s]: VolumeNfsExportClass %s found", utils.NfsExportKey(nfsexport), class.Name)
 
-type controllerUpdateError struct {
-	message string
+	nfsexportClone := nfsexport.DeepCopy()
+	if nfsexportClone.Status.Error != nil && nfsexportClone.Status.Error.Message != nil &&
+		*nfsexportClone.Status.Error.Message == setDefaultClassFailedMessage(errors.New(cond.Message)) {
+		nfsexportClone.Status.Error = nil
+		nfsexportClone.Status.ErrorSummary = nil
+	}
+	meta.SetStatusCondition(&nfsexportClone.Status.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionClassMissing,
+		Status:             metav1.ConditionFalse,
+		Reason:             crdv1.ClassMissingReasonResolved,
+		Message:            fmt.Sprintf("VolumeNfsExportClass %s is used", class.Name),
+		ObservedGeneration: nfsexport.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
+	if err != nil {
+		return nil, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+	if _, err = ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.V(4).Infof("resolveNfsExportClassMissing[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+	}
+	return newNfsExport, nil
 }
 
-func newControllerUpdateError(name, message string) error {
-	return controllerUpdateError{
-		message: fmt.Sprintf("%s %s on API server: %s", controllerUpdateFailMsg, name, message),
+// isContentCreationPending returns true if nfsexport is a dynamic nfsexport
+// that is not being deleted and whose content has not been created yet.
+func (ctrl *csiNfsExportCommonController) isContentCreationPending(nfsexport *crdv1.VolumeNfsExport) bool {
+	if nfsexport.DeletionTimestamp != nil || nfsexport.Spec.Source.PersistentVolumeClaimName == nil || utils.IsNfsExportReady(nfsexport) {
+		return false
+	}
+	if nfsexport.Status != nil && nfsexport.Status.BoundVolumeNfsExportContentName != nil {
+		return false
 	}
+	content, err := ctrl.getContentFromStore(utils.GetDynamicNfsExportContentNameForNfsExport(nfsexport))
+	return err == nil && content == nil
 }
 
-func (e controllerUpdateError) Error() string {
-	return e.message
+// isNfsExportClassDeleted returns true if the Failed condition of nfsexport
+// reports that its class was deleted before its content was created.
+func isNfsExportClassDeleted(nfsexport *crdv1.VolumeNfsExport) bool {
+	if nfsexport.Status == nil {
+		return false
+	}
+	cond := meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionFailed)
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == crdv1.FailedReasonClassDeleted
 }
 
-func isControllerUpdateFailError(err *crdv1.VolumeNfsExportError) bool {
-	if err != nil {
-		if strings.Contains(*err.Message, controllerUpdateFailMsg) {
-			return true
+// handleNfsExportClassDeleted handles a nfsexport whose class className was
+// deleted before its content was created, which would otherwise fail on
+// every sync. Only deletions observed by the controller are handled here: a
+// class that is not found otherwise may still be created, and the nfsexport
+// gets the Blocked condition until it is. If fallbackToDefaultClass is set, the nfsexport is switched to
+// the default class of the driver of its source volume. Otherwise, or if
+// there is no single default class, the nfsexport is marked Failed with the
+// ClassDeleted reason and is no longer synced until it is deleted.
+func (ctrl *csiNfsExportCommonController) handleNfsExportClassDeleted(nfsexport *crdv1.VolumeNfsExport, className string) (*crdv1.VolumeNfsExport, error) {
+	if isNfsExportClassDeleted(nfsexport) {
+		klog.V(4).Infof("handleNfsExportClassDeleted[%s]: deletion of VolumeNfsExportClass %s is already reported", utils.NfsExportKey(nfsexport), className)
+		return nfsexport, nil
+	}
+	msg := fmt.Sprintf("VolumeNfsExportClass %s was deleted before the export was created", className)
+	if ctrl.fallbackToDefaultClass {
+		class, newNfsExport, err := ctrl.SetDefaultNfsExportClass(nfsexport)
+		if err == nil {
+			klog.V(2).Infof("handleNfsExportClassDeleted[%s]: %s, using default VolumeNfsExportClass %s", utils.NfsExportKey(nfsexport), msg, class.Name)
+			ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "NfsExportClassReselected", fmt.Sprintf("%s, using the default VolumeNfsExportClass %s instead", msg, class.Name))
+			return newNfsExport, nil
 		}
+		if _, ok := err.(*defaultClassError); !ok {
+			ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, false, v1.EventTypeWarning, "SetDefaultNfsExportClassFailed", setDefaultClassFailedMessage(err))
+			return nfsexport, err
+		}
+		msg = fmt.Sprintf("%s and no default class can replace it: %v", msg, err)
 	}
-	return false
+	msg += "; create a new VolumeNfsExport with an existing class"
+	klog.Warningf("handleNfsExportClassDeleted[%s]: %s", utils.NfsExportKey(nfsexport), msg)
+
+	nfsexportClone := nfsexport.DeepCopy()
+	if nfsexportClone.Status == nil {
+		nfsexportClone.Status = &crdv1.VolumeNfsExportStatus{}
+	}
+	statusError := &crdv1.VolumeNfsExportError{
+		Time:    &metav1.Time{Time: ctrl.clock.Now()},
+		Message: &msg,
+	}
+	nfsexportClone.Status.Error = statusError
+	nfsexportClone.Status.ErrorSummary = utils.GetVolumeNfsExportErrorSummary(statusError)
+	meta.SetStatusCondition(&nfsexportClone.Status.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionFailed,
+		Status:             metav1.ConditionTrue,
+		Reason:             crdv1.FailedReasonClassDeleted,
+		Message:            msg,
+		ObservedGeneration: nfsexport.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
+
+	// Emit the event even if the status update fails so that user can see the error
+	ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "NfsExportClassDeleted", msg)
+
+	if err != nil {
+		return nfsexport, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+	if _, err = ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.V(4).Infof("handleNfsExportClassDeleted[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+	}
+	return newNfsExport, nil
 }
 
-// addNfsExportFinalizer adds a Finalizer for VolumeNfsExport.
-func (ctrl *csiNfsExportCommonController) addNfsExportFinalizer(nfsexport *crdv1.VolumeNfsExport, addSourceFinalizer bool, addBoundFinalizer bool) error {
-	var updatedNfsExport *crdv1.VolumeNfsExport
-	var err error
+// errDynamicProvisioningDisabled is returned for nfsexports with a source PVC
+// when the controller runs in content-only mode, i.e. without a PVC informer.
+var errDynamicProvisioningDisabled = errors.New("dynamic provisioning is disabled, the nfsexport controller runs in content-only mode without access to PersistentVolumeClaims; use a pre-provisioned VolumeNfsExportContent as source instead")
 
-	// NOTE(ggriffiths): Must perform an update if no finalizers exist.
-	// Unable to find a patch that correctly updated the finalizers if none currently exist.
-	if len(nfsexport.ObjectMeta.Finalizers) == 0 {
-		nfsexportClone := nfsexport.DeepCopy()
-		if addSourceFinalizer {
-			nfsexportClone.ObjectMeta.Finalizers = append(nfsexportClone.ObjectMeta.Finalizers, utils.VolumeNfsExportAsSourceFinalizer)
-		}
-		if addBoundFinalizer {
-			nfsexportClone.ObjectMeta.Finalizers = append(nfsexportClone.ObjectMeta.Finalizers, utils.VolumeNfsExportBoundFinalizer)
-		}
-		updatedNfsExport, err = ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexportClone.Namespace).Update(context.TODO(), nfsexportClone, metav1.UpdateOptions{})
-		if err != nil {
-			return newControllerUpdateError(utils.NfsExportKey(nfsexport), err.Error())
-		}
-	} else {
-		// Otherwise, perform a patch
-		var patches []utils.PatchOp
+// blockedError is returned when the controller cannot act on a nfsexport
+// until something else changes, e.g. its source PVC gets bound. Besides the
+// error status, it is reported with the Blocked condition of the nfsexport,
+// see setNfsExportBlocked.
+type blockedError struct {
+	reason  string
+	message string
+	// err is the error reported to the caller, if different from message.
+	err error
+}
 
-		// If finalizers exist already, add new ones to the end of the array
-		if addSourceFinalizer {
-			patches = append(patches, utils.PatchOp{
-				Op:    "add",
-				Path:  "/metadata/finalizers/-",
-				Value: utils.VolumeNfsExportAsSourceFinalizer,
-			})
-		}
-		if addBoundFinalizer {
-			patches = append(patches, utils.PatchOp{
-				Op:    "add",
-				Path:  "/metadata/finalizers/-",
-				Value: utils.VolumeNfsExportBoundFinalizer,
-			})
-		}
+func (e *blockedError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return e.message
+}
 
-		updatedNfsExport, err = utils.PatchVolumeNfsExport(nfsexport, patches, ctrl.clientset)
-		if err != nil {
-			return newControllerUpdateError(utils.NfsExportKey(nfsexport), err.Error())
+// setNfsExportBlocked sets the Blocked condition of a nfsexport the
+// controller waits on, so that users can tell why nothing happens without
+// reading the controller logs. The condition is only written when its reason
+// or message changes. It returns the updated nfsexport, or nfsexport if the
+// update fails, as the condition is informational.
+func (ctrl *csiNfsExportCommonController) setNfsExportBlocked(nfsexport *crdv1.VolumeNfsExport, reason, message string) *crdv1.VolumeNfsExport {
+	if nfsexport.Status != nil {
+		cond := meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionBlocked)
+		if cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == reason && cond.Message == message {
+			return nfsexport
 		}
 	}
+	klog.V(4).Infof("setNfsExportBlocked[%s]: %s: %s", utils.NfsExportKey(nfsexport), reason, message)
 
-	_, err = ctrl.storeNfsExportUpdate(updatedNfsExport)
+	nfsexportClone := nfsexport.DeepCopy()
+	if nfsexportClone.Status == nil {
+		nfsexportClone.Status = &crdv1.VolumeNfsExportStatus{}
+	}
+	meta.SetStatusCondition(&nfsexportClone.Status.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionBlocked,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: nfsexport.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
 	if err != nil {
-		klog.Errorf("failed to update nfsexport store %v", err)
+		klog.V(4).Infof("setNfsExportBlocked[%s]: failed to update status: %v", utils.NfsExportKey(nfsexport), err)
+		return nfsexport
+	}
+	if _, err = ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.V(4).Infof("setNfsExportBlocked[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
 	}
+	return newNfsExport
+}
 
-	klog.V(5).Infof("Added protection finalizer to volume nfsexport %s", utils.NfsExportKey(updatedNfsExport))
-	return nil
+// isNfsExportBlockedOn returns true if the Blocked condition of nfsexport is
+// "True" with the given reason.
+func isNfsExportBlockedOn(nfsexport *crdv1.VolumeNfsExport, reason string) bool {
+	if nfsexport.Status == nil {
+		return false
+	}
+	cond := meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionBlocked)
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == reason
 }
 
-// removeNfsExportFinalizer removes a Finalizer for VolumeNfsExport.
+// resolveNfsExportBlocked turns the Blocked condition of a nfsexport to
+// "False" once the controller acts on it.
+func (ctrl *csiNfsExportCommonController) resolveNfsExportBlocked(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExport, error) {
+	if nfsexport.Status == nil || !meta.IsStatusConditionTrue(nfsexport.Status.Conditions, crdv1.ConditionBlocked) {
+		return nfsexport, nil
+	}
+	klog.V(2).Infof("resolveNfsExportBlocked[%s]: nfsexport is no longer blocked", utils.NfsExportKey(nfsexport))
+
+	nfsexportClone := nfsexport.DeepCopy()
+	meta.SetStatusCondition(&nfsexportClone.Status.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionBlocked,
+		Status:             metav1.ConditionFalse,
+		Reason:             crdv1.BlockedReasonResolved,
+		Message:            "The nfsexport controller is acting on the nfsexport",
+		ObservedGeneration: nfsexport.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
+	if err != nil {
+		return nil, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+	if _, err = ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.V(4).Infof("resolveNfsExportBlocked[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+	}
+	return newNfsExport, nil
+}
+
+// getClaimFromVolumeNfsExport is a helper function to get PVC from VolumeNfsExport.
+func (ctrl *csiNfsExportCommonController) getClaimFromVolumeNfsExport(nfsexport *crdv1.VolumeNfsExport) (*v1.PersistentVolumeClaim, error) {
+	if nfsexport.Spec.Source.PersistentVolumeClaimName == nil {
+		return nil, fmt.Errorf("the nfsexport source PVC name is not specified")
+	}
+	pvcName := *nfsexport.Spec.Source.PersistentVolumeClaimName
+	if pvcName == "" {
+		return nil, fmt.Errorf("the PVC name is not specified in nfsexport %s", utils.NfsExportKey(nfsexport))
+	}
+	if ctrl.pvcLister == nil {
+		return nil, errDynamicProvisioningDisabled
+	}
+
+	pvc, err := ctrl.pvcLister.PersistentVolumeClaims(nfsexport.Namespace).Get(pvcName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve PVC %s from the lister: %q", pvcName, err)
+	}
+
+	return pvc, nil
+}
+
+// errPodInformerDisabled is returned when a class requests a feature that
+// reads the pods using the source PVC of a nfsexport without the pod informer.
+var errPodInformerDisabled = errors.New("the pods using the source PVC are not cached, the nfsexport controller must run with --enable-pod-informer")
+
+// getClaimPods returns the cached pods in namespace that use the PVC
+// claimName. The pods are shared with the informer cache and must not be
+// modified.
+func (ctrl *csiNfsExportCommonController) getClaimPods(namespace, claimName string) ([]v1.Pod, error) {
+	if ctrl.podIndexer == nil {
+		return nil, errPodInformerDisabled
+	}
+	objs, err := ctrl.podIndexer.ByIndex(utils.PodClaimIndex, utils.PodClaimKey(namespace, claimName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pods using PVC %s/%s: %v", namespace, claimName, err)
+	}
+	pods := make([]v1.Pod, 0, len(objs))
+	for _, obj := range objs {
+		if pod, ok := obj.(*v1.Pod); ok {
+			pods = append(pods, *pod)
+		}
+	}
+	return pods, nil
+}
+
+// getExportSecurityContext derives the security context of exported files
+// from a pod using the source PVC of the nfsexport. It returns no annotations
+// if no pod uses the PVC, in which case the driver defaults apply.
+func (ctrl *csiNfsExportCommonController) getExportSecurityContext(nfsexport *crdv1.VolumeNfsExport) (map[string]string, error) {
+	claimName := *nfsexport.Spec.Source.PersistentVolumeClaimName
+	pods, err := ctrl.getClaimPods(nfsexport.Namespace, claimName)
+	if err != nil {
+		return nil, err
+	}
+	pod := utils.FindPodUsingClaim(pods, claimName)
+	if pod == nil {
+		msg := fmt.Sprintf("No pod uses PVC %s, the export security context is left to the driver", claimName)
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "ExportSecurityContextNotFound", msg)
+		return nil, nil
+	}
+	klog.V(5).Infof("getExportSecurityContext: using security context of pod %s/%s for nfsexport %s", pod.Namespace, pod.Name, utils.NfsExportKey(nfsexport))
+	return utils.GetExportSecurityContextFromPod(pod, claimName), nil
+}
+
+// quiescePollInterval is how often the nfsexport controller checks whether
+// the pods asked to quiesce have acknowledged.
+const quiescePollInterval = 2 * time.Second
+
+// quiesceSourcePods asks the running pods using the source PVC of an
+// application consistent PointInTime nfsexport to quiesce, see
+// utils.AnnQuiesceRequest. It returns true and the consistency of the export
+// once all the pods acknowledged or the quiesce timeout of the class elapsed.
+// While waiting, it requeues the nfsexport and returns false. It returns true
+// and no consistency if the class does not request application consistency.
+func (ctrl *csiNfsExportCommonController) quiesceSourcePods(nfsexport *crdv1.VolumeNfsExport, class *crdv1.VolumeNfsExportClass) (string, bool, error) {
+	requested, err := utils.IsQuiesceRequested(nfsexport, class.Parameters)
+	if err != nil {
+		return "", false, err
+	}
+	if !requested {
+		return "", true, nil
+	}
+	timeout, err := utils.GetExportQuiesceTimeout(class.Parameters)
+	if err != nil {
+		return "", false, err
+	}
+
+	claimName := *nfsexport.Spec.Source.PersistentVolumeClaimName
+	claimPods, err := ctrl.getClaimPods(nfsexport.Namespace, claimName)
+	if err != nil {
+		return "", false, err
+	}
+	pods := utils.FindRunningPodsUsingClaim(claimPods, claimName)
+	if len(pods) == 0 {
+		klog.V(4).Infof("quiesceSourcePods [%s]: no running pod uses PVC %s", utils.NfsExportKey(nfsexport), claimName)
+		return utils.ExportConsistencyApplication, true, nil
+	}
+
+	now := ctrl.clock.Now()
+	requestedAt := now
+	if value, ok := nfsexport.Annotations[utils.AnnQuiesceRequestedAt]; ok {
+		if requestedAt, err = time.Parse(time.RFC3339, value); err != nil {
+			return "", false, fmt.Errorf("invalid annotation %s %q: %v", utils.AnnQuiesceRequestedAt, value, err)
+		}
+	} else {
+		if err := ctrl.setNfsExportAnnotation(nfsexport, utils.AnnQuiesceRequestedAt, now.UTC().Format(time.RFC3339)); err != nil {
+			return "", false, err
+		}
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "QuiesceRequested", fmt.Sprintf("Asked %d pods using PVC %s to quiesce before creating the export", len(pods), claimName))
+	}
+
+	uid := string(nfsexport.UID)
+	var pending []string
+	for _, pod := range pods {
+		if pod.Annotations[utils.AnnQuiesceRequest] != uid {
+			if err := ctrl.setPodQuiesceRequest(pod, uid); err != nil {
+				return "", false, err
+			}
+		}
+		if pod.Annotations[utils.AnnQuiesceAck] != uid {
+			pending = append(pending, pod.Name)
+		}
+	}
+	if len(pending) == 0 {
+		klog.V(4).Infof("quiesceSourcePods [%s]: all pods using PVC %s are quiesced", utils.NfsExportKey(nfsexport), claimName)
+		return utils.ExportConsistencyApplication, true, nil
+	}
+
+	elapsed := now.Sub(requestedAt)
+	if elapsed >= timeout {
+		msg := fmt.Sprintf("Pods %s did not acknowledge the quiesce request within %v, creating a crash consistent export", strings.Join(pending, ", "), timeout)
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "QuiesceTimedOut", msg)
+		return utils.ExportConsistencyCrash, true, nil
+	}
+	delay := timeout - elapsed
+	if delay > quiescePollInterval {
+		delay = quiescePollInterval
+	}
+	klog.V(4).Infof("quiesceSourcePods [%s]: waiting for pods %s to quiesce", utils.NfsExportKey(nfsexport), strings.Join(pending, ", "))
+	ctrl.setNfsExportBlocked(nfsexport, crdv1.BlockedReasonWaitingForQuiesce, fmt.Sprintf("Waiting for pods %s to acknowledge the quiesce request before creating the export", strings.Join(pending, ", ")))
+	ctrl.nfsexportQueue.AddAfter(utils.NfsExportKey(nfsexport), delay)
+	return "", false, nil
+}
+
+// consistencyGatePollInterval is how often the nfsexport controller checks
+// whether the consistency gate of a nfsexport is open.
+const consistencyGatePollInterval = 10 * time.Second
+
+// checkConsistencyGate returns true if the export of nfsexport may be
+// created according to the consistency gate of its class, see
+// utils.PrefixedExportConsistencyGateKey. While pods write to the source
+// PVC, it requeues the nfsexport and returns false. It returns an error once
+// the gate timeout of the class elapsed, so that the nfsexport reports it
+// and is retried with backoff.
+func (ctrl *csiNfsExportCommonController) checkConsistencyGate(nfsexport *crdv1.VolumeNfsExport, class *crdv1.VolumeNfsExportClass) (bool, error) {
+	gate, err := utils.GetExportConsistencyGate(class.Parameters)
+	if err != nil {
+		return false, err
+	}
+	if gate == "" {
+		return true, nil
+	}
+	timeout, err := utils.GetExportConsistencyGateTimeout(class.Parameters)
+	if err != nil {
+		return false, err
+	}
+
+	claimName := *nfsexport.Spec.Source.PersistentVolumeClaimName
+	claimPods, err := ctrl.getClaimPods(nfsexport.Namespace, claimName)
+	if err != nil {
+		return false, err
+	}
+	var writers []string
+	for _, pod := range utils.FindRunningPodsWritingClaim(claimPods, claimName) {
+		writers = append(writers, pod.Name)
+	}
+	_, waiting := nfsexport.Annotations[utils.AnnConsistencyGateWaitingSince]
+	if len(writers) == 0 {
+		klog.V(4).Infof("checkConsistencyGate [%s]: no running pod writes to PVC %s", utils.NfsExportKey(nfsexport), claimName)
+		if waiting {
+			if err := ctrl.setNfsExportAnnotation(nfsexport, utils.AnnConsistencyGateWaitingSince, ""); err != nil {
+				return false, err
+			}
+			ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "ConsistencyGateOpened", fmt.Sprintf("No pod writes to PVC %s anymore, creating the export", claimName))
+		}
+		return true, nil
+	}
+
+	now := ctrl.clock.Now()
+	since := now
+	if waiting {
+		value := nfsexport.Annotations[utils.AnnConsistencyGateWaitingSince]
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			return false, fmt.Errorf("invalid annotation %s %q: %v", utils.AnnConsistencyGateWaitingSince, value, err)
+		}
+	} else {
+		if err := ctrl.setNfsExportAnnotation(nfsexport, utils.AnnConsistencyGateWaitingSince, now.UTC().Format(time.RFC3339)); err != nil {
+			return false, err
+		}
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeNormal, "WaitingForWriters", fmt.Sprintf("Waiting for pods %s to stop writing to PVC %s before creating the export", strings.Join(writers, ", "), claimName))
+	}
+
+	elapsed := now.Sub(since)
+	if elapsed >= timeout {
+		msg := fmt.Sprintf("Pods %s still mount PVC %s read-write after %v, the export is not created until they stop writing", strings.Join(writers, ", "), claimName, timeout)
+		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "ConsistencyGateTimedOut", msg)
+		return false, fmt.Errorf("consistency gate %s of nfsexport %s timed out: pods %s still mount PVC %s read-write", gate, utils.NfsExportKey(nfsexport), strings.Join(writers, ", "), claimName)
+	}
+	delay := timeout - elapsed
+	if delay > consistencyGatePollInterval {
+		delay = consistencyGatePollInterval
+	}
+	klog.V(4).Infof("checkConsistencyGate [%s]: waiting for pods %s to stop writing to PVC %s", utils.NfsExportKey(nfsexport), strings.Join(writers, ", "), claimName)
+	ctrl.setNfsExportBlocked(nfsexport, crdv1.BlockedReasonWaitingForWriters, fmt.Sprintf("Waiting for pods %s to stop writing to PVC %s before creating the export", strings.Join(writers, ", "), claimName))
+	ctrl.nfsexportQueue.AddAfter(utils.NfsExportKey(nfsexport), delay)
+	return false, nil
+}
+
+// checkandReleaseSourcePods removes the quiesce requests of nfsexport from
+// the pods using its source PVC once the export has been cut, or when the
+// nfsexport is being deleted, so that the workloads resume.
+func (ctrl *csiNfsExportCommonController) checkandReleaseSourcePods(nfsexport *crdv1.VolumeNfsExport) error {
+	if !metav1.HasAnnotation(nfsexport.ObjectMeta, utils.AnnQuiesceRequestedAt) {
+		return nil
+	}
+	if nfsexport.DeletionTimestamp == nil && (nfsexport.Status == nil || nfsexport.Status.CreationTime == nil) {
+		return nil
+	}
+	if ctrl.podIndexer == nil {
+		return errPodInformerDisabled
+	}
+	objs, err := ctrl.podIndexer.ByIndex(utils.PodQuiesceRequestIndex, utils.PodQuiesceRequestKey(nfsexport.Namespace, string(nfsexport.UID)))
+	if err != nil {
+		return fmt.Errorf("failed to get the pods quiesced for nfsexport %s: %v", utils.NfsExportKey(nfsexport), err)
+	}
+	for _, obj := range objs {
+		pod, ok := obj.(*v1.Pod)
+		if !ok {
+			continue
+		}
+		if err := ctrl.setPodQuiesceRequest(pod, ""); err != nil {
+			return err
+		}
+		klog.V(4).Infof("checkandReleaseSourcePods [%s]: released pod %s", utils.NfsExportKey(nfsexport), pod.Name)
+	}
+	return ctrl.setNfsExportAnnotation(nfsexport, utils.AnnQuiesceRequestedAt, "")
+}
+
+// setPodQuiesceRequest sets the quiesce request annotation of pod to the UID
+// of a nfsexport, or removes it and its acknowledgement if uid is empty.
+func (ctrl *csiNfsExportCommonController) setPodQuiesceRequest(pod *v1.Pod, uid string) error {
+	annotations := map[string]interface{}{utils.AnnQuiesceRequest: uid}
+	if uid == "" {
+		annotations = map[string]interface{}{utils.AnnQuiesceRequest: nil, utils.AnnQuiesceAck: nil}
+	}
+	data, err := annotationsMergePatch(annotations)
+	if err != nil {
+		return err
+	}
+	if _, err := ctrl.client.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, types.MergePatchType, data, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to update the quiesce request of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+	return nil
+}
+
+// setNfsExportAnnotation sets the annotation key of nfsexport to value, or
+// removes it if value is empty.
+func (ctrl *csiNfsExportCommonController) setNfsExportAnnotation(nfsexport *crdv1.VolumeNfsExport, key, value string) error {
+	var annotation interface{}
+	if value != "" {
+		annotation = value
+	}
+	data, err := annotationsMergePatch(map[string]interface{}{key: annotation})
+	if err != nil {
+		return err
+	}
+	newNfsExport, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexport.Namespace).Patch(context.TODO(), nfsexport.Name, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+	if _, err := ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.Errorf("failed to update nfsexport store %v", err)
+	}
+	return nil
+}
+
+// annotationsMergePatch returns a JSON merge patch setting the given
+// annotations. A nil value removes an annotation.
+func annotationsMergePatch(annotations map[string]interface{}) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": annotations,
+		},
+	})
+}
+
+func isControllerUpdateFailError(err *crdv1.VolumeNfsExportError) bool {
+	if err != nil {
+		if strings.Contains(*err.Message, controllerUpdateFailMsg) {
+			return true
+		}
+	}
+	return false
+}
+
+// addNfsExportFinalizer adds a Finalizer for VolumeNfsExport.
+func (ctrl *csiNfsExportCommonController) addNfsExportFinalizer(nfsexport *crdv1.VolumeNfsExport, addSourceFinalizer bool, addBoundFinalizer bool) error {
+	var finalizers []string
+	if addSourceFinalizer {
+		finalizers = append(finalizers, utils.VolumeNfsExportAsSourceFinalizer)
+	}
+	if addBoundFinalizer {
+		finalizers = append(finalizers, utils.VolumeNfsExportBoundFinalizer)
+	}
+	updatedNfsExport, err := utils.AddVolumeNfsExportFinalizers(nfsexport, ctrl.clientset, finalizers...)
+	if err != nil {
+		return utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+
+	_, err = ctrl.storeNfsExportUpdate(updatedNfsExport)
+	if err != nil {
+		klog.Errorf("failed to update nfsexport store %v", err)
+	}
+
+	klog.V(5).Infof("Added protection finalizer to volume nfsexport %s", utils.NfsExportKey(updatedNfsExport))
+	return nil
+}
+
+// removeNfsExportFinalizer removes a Finalizer for VolumeNfsExport.
 func (ctrl *csiNfsExportCommonController) removeNfsExportFinalizer(nfsexport *crdv1.VolumeNfsExport, removeSourceFinalizer bool, removeBoundFinalizer bool) error {
 	if !removeSourceFinalizer && !removeBoundFinalizer {
 		return nil
@@ -1510,19 +2953,19 @@ func (ctrl *csiNfsExportCommonController) removeNfsExportFinalizer(nfsexport *cr
 		klog.Errorf("removeNfsExportFinalizer: error check and remove PVC finalizer for nfsexport [%s]: %v", nfsexport.Name, err)
 		// Log an event and keep the original error from checkandRemovePVCFinalizer
 		ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "ErrorPVCFinalizer", "Error check and remove PVC Finalizer for VolumeNfsExport")
-		return newControllerUpdateError(nfsexport.Name, err.Error())
+		return utils.NewControllerUpdateError(nfsexport.Name, err)
 	}
 
-	nfsexportClone := nfsexport.DeepCopy()
+	var finalizers []string
 	if removeSourceFinalizer {
-		nfsexportClone.ObjectMeta.Finalizers = utils.RemoveString(nfsexportClone.ObjectMeta.Finalizers, utils.VolumeNfsExportAsSourceFinalizer)
+		finalizers = append(finalizers, utils.VolumeNfsExportAsSourceFinalizer)
 	}
 	if removeBoundFinalizer {
-		nfsexportClone.ObjectMeta.Finalizers = utils.RemoveString(nfsexportClone.ObjectMeta.Finalizers, utils.VolumeNfsExportBoundFinalizer)
+		finalizers = append(finalizers, utils.VolumeNfsExportBoundFinalizer)
 	}
-	newNfsExport, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexportClone.Namespace).Update(context.TODO(), nfsexportClone, metav1.UpdateOptions{})
+	newNfsExport, err := utils.RemoveVolumeNfsExportFinalizers(nfsexport, ctrl.clientset, finalizers...)
 	if err != nil {
-		return newControllerUpdateError(nfsexport.Name, err.Error())
+		return utils.NewControllerUpdateError(nfsexport.Name, err)
 	}
 
 	_, err = ctrl.storeNfsExportUpdate(newNfsExport)
@@ -1576,7 +3019,7 @@ func (ctrl *csiNfsExportCommonController) setAnnVolumeNfsExportBeingDeleted(cont
 
 		patchedContent, err := utils.PatchVolumeNfsExportContent(content, patches, ctrl.clientset)
 		if err != nil {
-			return content, newControllerUpdateError(content.Name, err.Error())
+			return content, utils.NewControllerUpdateError(content.Name, err)
 		}
 
 		// update content if update is successful
@@ -1592,6 +3035,96 @@ func (ctrl *csiNfsExportCommonController) setAnnVolumeNfsExportBeingDeleted(cont
 	return content, nil
 }
 
+// rebindContent binds a content whose nfsexport has been deleted to the
+// nfsexport named by its AnnVolumeNfsExportRebindTo annotation. The new
+// nfsexport must refer to the content in Spec.Source.VolumeNfsExportContentName.
+// The content is patched to point to the new nfsexport, and the status of the
+// new nfsexport to point back to the content, in the same sync. The patch of
+// the content fails if its nfsexport reference changed in the meantime.
+func (ctrl *csiNfsExportCommonController) rebindContent(content *crdv1.VolumeNfsExportContent) error {
+	target := content.ObjectMeta.Annotations[utils.AnnVolumeNfsExportRebindTo]
+	namespace, name, err := cache.SplitMetaNamespaceKey(target)
+	if err != nil || namespace == "" || name == "" {
+		msg := fmt.Sprintf("Invalid %s annotation %q, expected <namespace>/<name>", utils.AnnVolumeNfsExportRebindTo, target)
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportContentRebindFailed", msg)
+		return fmt.Errorf(msg)
+	}
+	// A content with the Delete policy could be deleted from under its new
+	// VolumeNfsExport, only retained contents are handed over.
+	if content.Spec.DeletionPolicy != crdv1.VolumeNfsExportContentRetain {
+		msg := fmt.Sprintf("Cannot rebind VolumeNfsExportContent with deletion policy %s, set it to %s first", content.Spec.DeletionPolicy, crdv1.VolumeNfsExportContentRetain)
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportContentRebindFailed", msg)
+		return fmt.Errorf(msg)
+	}
+	nfsexport, err := ctrl.getNfsExportFromStore(target)
+	if err != nil {
+		return err
+	}
+	if nfsexport == nil {
+		klog.V(4).Infof("rebindContent [%s]: nfsexport %s does not exist yet, will try again", content.Name, target)
+		return fmt.Errorf("nfsexport %s to rebind content %s to does not exist", target, content.Name)
+	}
+	if nfsexport.Spec.Source.VolumeNfsExportContentName == nil || *nfsexport.Spec.Source.VolumeNfsExportContentName != content.Name ||
+		(nfsexport.Status != nil && nfsexport.Status.BoundVolumeNfsExportContentName != nil && *nfsexport.Status.BoundVolumeNfsExportContentName != content.Name) {
+		msg := fmt.Sprintf("VolumeNfsExport %s does not refer to VolumeNfsExportContent %s in Spec.Source.VolumeNfsExportContentName", target, content.Name)
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportContentRebindFailed", msg)
+		return fmt.Errorf(msg)
+	}
+	if nfsexport.ObjectMeta.DeletionTimestamp != nil {
+		msg := fmt.Sprintf("VolumeNfsExport %s is being deleted", target)
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportContentRebindFailed", msg)
+		return fmt.Errorf(msg)
+	}
+
+	nfsexportRef, err := ref.GetReference(scheme.Scheme, nfsexport)
+	if err != nil {
+		return err
+	}
+	annotations := make(map[string]string)
+	for k, v := range content.ObjectMeta.Annotations {
+		annotations[k] = v
+	}
+	delete(annotations, utils.AnnVolumeNfsExportRebindTo)
+	delete(annotations, utils.AnnVolumeNfsExportBeingDeleted)
+	delete(annotations, utils.AnnVolumeNfsExportCreationTimestamp)
+	if creationTimestamp := utils.GetNfsExportCreationTimestampForContent(nfsexport); creationTimestamp != "" {
+		annotations[utils.AnnVolumeNfsExportCreationTimestamp] = creationTimestamp
+	}
+	annotations[utils.AnnVolumeNfsExportReboundFrom] = utils.NfsExportRefKey(&content.Spec.VolumeNfsExportRef)
+	patches := []utils.PatchOp{
+		{
+			Op:    "test",
+			Path:  "/spec/volumeNfsExportRef/uid",
+			Value: string(content.Spec.VolumeNfsExportRef.UID),
+		},
+		{
+			Op:    "replace",
+			Path:  "/spec/volumeNfsExportRef",
+			Value: nfsexportRef,
+		},
+		{
+			Op:    "replace",
+			Path:  "/metadata/annotations",
+			Value: annotations,
+		},
+	}
+	newContent, err := utils.PatchVolumeNfsExportContent(content, patches, ctrl.clientset)
+	if err != nil {
+		return utils.NewControllerUpdateError(content.Name, err)
+	}
+	if _, err = ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("rebindContent [%s]: cannot update internal cache %v", newContent.Name, err)
+	}
+
+	if _, err = ctrl.updateNfsExportStatus(nfsexport, newContent); err != nil {
+		// syncUnreadyNfsExport binds the nfsexport status on its next sync
+		klog.V(4).Infof("rebindContent [%s]: failed to update status of nfsexport %s: %v", newContent.Name, target, err)
+		ctrl.nfsexportQueue.Add(target)
+	}
+	ctrl.eventRecorder.Event(newContent, v1.EventTypeNormal, "NfsExportContentRebound", fmt.Sprintf("VolumeNfsExportContent rebound to VolumeNfsExport %s", target))
+	return nil
+}
+
 // checkAndSetInvalidContentLabel adds a label to unlabeled invalid content objects and removes the label from valid ones.
 func (ctrl *csiNfsExportCommonController) checkAndSetInvalidContentLabel(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
 	hasLabel := utils.MapContainsKey(content.ObjectMeta.Labels, utils.VolumeNfsExportContentInvalidLabel)
@@ -1599,10 +3132,19 @@ func (ctrl *csiNfsExportCommonController) checkAndSetInvalidContentLabel(content
 	if err != nil {
 		klog.Errorf("syncContent[%s]: Invalid content detected, %s", content.Name, err.Error())
 	}
+	if !ctrl.labelInvalidObjects {
+		return content, nil
+	}
 	// If the nfsexport content correctly has the label, or correctly does not have the label, take no action.
 	if hasLabel && err != nil || !hasLabel && err == nil {
+		if isContentInvalidFlapping(content) && (ctrl.invalidLabelToggles == nil || !ctrl.invalidLabelToggles.flapping(content.UID)) {
+			return ctrl.resolveContentInvalidFlapping(content)
+		}
 		return content, nil
 	}
+	if !ctrl.allowInvalidLabelToggle(content.UID, "volumenfsexportcontents") {
+		return ctrl.updateContentInvalidFlapping(content)
+	}
 
 	contentClone := content.DeepCopy()
 	if hasLabel {
@@ -1617,7 +3159,7 @@ func (ctrl *csiNfsExportCommonController) checkAndSetInvalidContentLabel(content
 	}
 	updatedContent, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Update(context.TODO(), contentClone, metav1.UpdateOptions{})
 	if err != nil {
-		return content, newControllerUpdateError(content.Name, err.Error())
+		return content, utils.NewControllerUpdateError(content.Name, err)
 	}
 
 	_, err = ctrl.storeContentUpdate(updatedContent)
@@ -1633,6 +3175,35 @@ func (ctrl *csiNfsExportCommonController) checkAndSetInvalidContentLabel(content
 	return updatedContent, nil
 }
 
+// checkAndSetContentDriverLabel labels the content with the name of its
+// driver if it is not labeled yet. Drivers whose name is not a valid label
+// value are left unlabeled, their sidecars must not use a driver scoped
+// content informer.
+func (ctrl *csiNfsExportCommonController) checkAndSetContentDriverLabel(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
+	if content.Labels[utils.VolumeNfsExportContentDriverLabel] == content.Spec.Driver {
+		return content, nil
+	}
+	if errs := validation.IsValidLabelValue(content.Spec.Driver); len(errs) > 0 {
+		klog.Warningf("syncContent[%s]: cannot label content with driver %q: %s", content.Name, content.Spec.Driver, strings.Join(errs, ", "))
+		return content, nil
+	}
+
+	contentClone := content.DeepCopy()
+	metav1.SetMetaDataLabel(&contentClone.ObjectMeta, utils.VolumeNfsExportContentDriverLabel, content.Spec.Driver)
+	updatedContent, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Update(context.TODO(), contentClone, metav1.UpdateOptions{})
+	if err != nil {
+		return content, utils.NewControllerUpdateError(content.Name, err)
+	}
+
+	_, err = ctrl.storeContentUpdate(updatedContent)
+	if err != nil {
+		klog.Errorf("failed to update content store %v", err)
+	}
+
+	klog.V(5).Infof("Added driver label to volume nfsexport content %s", content.Name)
+	return updatedContent, nil
+}
+
 // checkAndSetInvalidNfsExportLabel adds a label to unlabeled invalid nfsexport objects and removes the label from valid ones.
 func (ctrl *csiNfsExportCommonController) checkAndSetInvalidNfsExportLabel(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExport, error) {
 	hasLabel := utils.MapContainsKey(nfsexport.ObjectMeta.Labels, utils.VolumeNfsExportInvalidLabel)
@@ -1640,10 +3211,19 @@ func (ctrl *csiNfsExportCommonController) checkAndSetInvalidNfsExportLabel(nfsex
 	if err != nil {
 		klog.Errorf("syncNfsExport[%s]: Invalid nfsexport detected, %s", utils.NfsExportKey(nfsexport), err.Error())
 	}
+	if !ctrl.labelInvalidObjects {
+		return nfsexport, nil
+	}
 	// If the nfsexport correctly has the label, or correctly does not have the label, take no action.
 	if hasLabel && err != nil || !hasLabel && err == nil {
+		if isNfsExportInvalidFlapping(nfsexport) && (ctrl.invalidLabelToggles == nil || !ctrl.invalidLabelToggles.flapping(nfsexport.UID)) {
+			return ctrl.resolveNfsExportInvalidFlapping(nfsexport)
+		}
 		return nfsexport, nil
 	}
+	if !ctrl.allowInvalidLabelToggle(nfsexport.UID, "volumenfsexports") {
+		return ctrl.updateNfsExportInvalidFlapping(nfsexport)
+	}
 
 	nfsexportClone := nfsexport.DeepCopy()
 	if hasLabel {
@@ -1659,7 +3239,7 @@ func (ctrl *csiNfsExportCommonController) checkAndSetInvalidNfsExportLabel(nfsex
 
 	updatedNfsExport, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexport.Namespace).Update(context.TODO(), nfsexportClone, metav1.UpdateOptions{})
 	if err != nil {
-		return nfsexport, newControllerUpdateError(utils.NfsExportKey(nfsexport), err.Error())
+		return nfsexport, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
 	}
 
 	_, err = ctrl.storeNfsExportUpdate(updatedNfsExport)
@@ -1676,6 +3256,171 @@ func (ctrl *csiNfsExportCommonController) checkAndSetInvalidNfsExportLabel(nfsex
 	return updatedNfsExport, nil
 }
 
+// allowInvalidLabelToggle returns true if the invalid label of the object with
+// the given UID may be added or removed, and false if the object reached the
+// toggle limit. Both are counted in the metrics of resource.
+func (ctrl *csiNfsExportCommonController) allowInvalidLabelToggle(uid types.UID, resource string) bool {
+	allowed := ctrl.invalidLabelToggles == nil || ctrl.invalidLabelToggles.allow(uid)
+	ctrl.metricsManager.RecordInvalidLabelToggle(resource, !allowed)
+	return allowed
+}
+
+func invalidLabelFlappingMessage(limiter *labelToggleLimiter) string {
+	return fmt.Sprintf("Invalid label toggled %d times within %v, leaving it as it is until the validation of the object is stable", limiter.limit, limiter.window)
+}
+
+// isNfsExportInvalidFlapping returns true if the InvalidFlapping condition
+// of nfsexport is "True".
+func isNfsExportInvalidFlapping(nfsexport *crdv1.VolumeNfsExport) bool {
+	return nfsexport.Status != nil && meta.IsStatusConditionTrue(nfsexport.Status.Conditions, crdv1.ConditionInvalidFlapping)
+}
+
+// updateNfsExportInvalidFlapping sets the InvalidFlapping condition of a
+// nfsexport whose invalid label reached the toggle limit, and emits a
+// warning event. The condition stays "True" while the label keeps flapping
+// so that the status is written once rather than on every toggle.
+func (ctrl *csiNfsExportCommonController) updateNfsExportInvalidFlapping(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExport, error) {
+	msg := invalidLabelFlappingMessage(ctrl.invalidLabelToggles)
+	if isNfsExportInvalidFlapping(nfsexport) {
+		klog.V(4).Infof("updateNfsExportInvalidFlapping[%s]: %s", utils.NfsExportKey(nfsexport), msg)
+		return nfsexport, nil
+	}
+	klog.Warningf("updateNfsExportInvalidFlapping[%s]: %s", utils.NfsExportKey(nfsexport), msg)
+
+	nfsexportClone := nfsexport.DeepCopy()
+	if nfsexportClone.Status == nil {
+		nfsexportClone.Status = &crdv1.VolumeNfsExportStatus{}
+	}
+	meta.SetStatusCondition(&nfsexportClone.Status.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionInvalidFlapping,
+		Status:             metav1.ConditionTrue,
+		Reason:             crdv1.InvalidFlappingReasonToggleLimitReached,
+		Message:            msg,
+		ObservedGeneration: nfsexport.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
+
+	// Emit the event even if the status update fails so that user can see the flapping
+	ctrl.eventRecorder.Event(nfsexport, v1.EventTypeWarning, "InvalidLabelFlapping", msg)
+
+	if err != nil {
+		return nfsexport, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+	if _, err = ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.V(4).Infof("updateNfsExportInvalidFlapping[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+	}
+	return newNfsExport, nil
+}
+
+// resolveNfsExportInvalidFlapping turns the InvalidFlapping condition of a
+// nfsexport to "False" once its invalid label is stable and below the toggle
+// limit.
+func (ctrl *csiNfsExportCommonController) resolveNfsExportInvalidFlapping(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExport, error) {
+	klog.V(2).Infof("resolveNfsExportInvalidFlapping[%s]: invalid label is stable", utils.NfsExportKey(nfsexport))
+
+	nfsexportClone := nfsexport.DeepCopy()
+	meta.SetStatusCondition(&nfsexportClone.Status.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionInvalidFlapping,
+		Status:             metav1.ConditionFalse,
+		Reason:             crdv1.InvalidFlappingReasonStable,
+		Message:            "Invalid label is stable",
+		ObservedGeneration: nfsexport.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	ctrl.markNfsExportStatusSynced(nfsexportClone.Status, nfsexport)
+	newNfsExport, err := utils.ApplyVolumeNfsExportStatus(nfsexport, nfsexportClone.Status, ctrl.statusClientset, utils.CommonControllerFieldManager)
+	if err != nil {
+		return nfsexport, utils.NewControllerUpdateError(utils.NfsExportKey(nfsexport), err)
+	}
+	if _, err = ctrl.storeNfsExportUpdate(newNfsExport); err != nil {
+		klog.V(4).Infof("resolveNfsExportInvalidFlapping[%s]: cannot update internal cache %v", utils.NfsExportKey(nfsexport), err)
+	}
+	return newNfsExport, nil
+}
+
+// isContentInvalidFlapping returns true if the InvalidFlapping condition of
+// content is "True".
+func isContentInvalidFlapping(content *crdv1.VolumeNfsExportContent) bool {
+	return content.Status != nil && meta.IsStatusConditionTrue(content.Status.Conditions, crdv1.ConditionInvalidFlapping)
+}
+
+// commonControllerContentConditions are the conditions of a content set by
+// the common controller. The rest of the status of a content is owned by the
+// sidecar.
+var commonControllerContentConditions = []string{
+	crdv1.ConditionInvalidFlapping,
+	crdv1.ConditionDuplicateNfsExportHandle,
+}
+
+// applyContentCondition applies cond as a condition of content, together
+// with the other conditions of content set by the common controller, so that
+// the apply neither drops them nor takes over the fields of the sidecar.
+func (ctrl *csiNfsExportCommonController) applyContentCondition(content *crdv1.VolumeNfsExportContent, cond metav1.Condition) (*crdv1.VolumeNfsExportContent, error) {
+	status := &crdv1.VolumeNfsExportContentStatus{}
+	if content.Status != nil {
+		for _, conditionType := range commonControllerContentConditions {
+			if current := meta.FindStatusCondition(content.Status.Conditions, conditionType); current != nil {
+				status.Conditions = append(status.Conditions, *current)
+			}
+		}
+	}
+	meta.SetStatusCondition(&status.Conditions, cond)
+	newContent, err := utils.ApplyVolumeNfsExportContentStatus(content, status, ctrl.statusClientset, utils.CommonControllerFieldManager)
+	if err != nil {
+		return content, utils.NewControllerUpdateError(content.Name, err)
+	}
+	if _, err = ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("applyContentCondition[%s]: cannot update internal cache %v", content.Name, err)
+	}
+	return newContent, nil
+}
+
+// updateContentInvalidFlapping sets the InvalidFlapping condition of a
+// content whose invalid label reached the toggle limit, and emits a warning
+// event, see updateNfsExportInvalidFlapping. The event is emitted once, when
+// the condition turns "True".
+func (ctrl *csiNfsExportCommonController) updateContentInvalidFlapping(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
+	msg := invalidLabelFlappingMessage(ctrl.invalidLabelToggles)
+	if isContentInvalidFlapping(content) {
+		klog.V(4).Infof("updateContentInvalidFlapping[%s]: %s", content.Name, msg)
+		return content, nil
+	}
+	klog.Warningf("updateContentInvalidFlapping[%s]: %s", content.Name, msg)
+
+	newContent, err := ctrl.applyContentCondition(content, metav1.Condition{
+		Type:               crdv1.ConditionInvalidFlapping,
+		Status:             metav1.ConditionTrue,
+		Reason:             crdv1.InvalidFlappingReasonToggleLimitReached,
+		Message:            msg,
+		ObservedGeneration: content.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+	// Emit the event even if the status update fails so that user can see the flapping
+	ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "InvalidLabelFlapping", msg)
+	return newContent, err
+}
+
+// resolveContentInvalidFlapping turns the InvalidFlapping condition of a
+// content to "False" once its invalid label is stable and below the toggle
+// limit.
+func (ctrl *csiNfsExportCommonController) resolveContentInvalidFlapping(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
+	klog.V(2).Infof("resolveContentInvalidFlapping[%s]: invalid label is stable", content.Name)
+	return ctrl.applyContentCondition(content, metav1.Condition{
+		Type:               crdv1.ConditionInvalidFlapping,
+		Status:             metav1.ConditionFalse,
+		Reason:             crdv1.InvalidFlappingReasonStable,
+		Message:            "Invalid label is stable",
+		ObservedGeneration: content.Generation,
+		LastTransitionTime: metav1.NewTime(ctrl.clock.Now()),
+	})
+}
+
+// getManagedByNode returns the node that manages nfsexports of the given PV
+// in distributed mode. Among the nodes that match the node affinity of the PV
+// and the allowed topologies of its StorageClass, the node managing the fewest
+// contents is chosen so that exports are spread over the nodes of a zone.
 func (ctrl *csiNfsExportCommonController) getManagedByNode(pv *v1.PersistentVolume) (string, error) {
 	if pv.Spec.NodeAffinity == nil {
 		klog.V(5).Infof("NodeAffinity not set for pv %s", pv.Name)
@@ -1683,19 +3428,107 @@ func (ctrl *csiNfsExportCommonController) getManagedByNode(pv *v1.PersistentVolu
 	}
 	nodeSelectorTerms := pv.Spec.NodeAffinity.Required
 
+	allowedTopologies, err := ctrl.getAllowedTopologies(pv)
+	if err != nil {
+		return "", err
+	}
+
 	nodes, err := ctrl.nodeLister.List(labels.Everything())
 	if err != nil {
 		klog.Errorf("failed to get the list of nodes: %q", err)
 		return "", err
 	}
 
+	var candidates []*v1.Node
 	for _, node := range nodes {
 		match, _ := corev1helpers.MatchNodeSelectorTerms(node, nodeSelectorTerms)
-		if match {
-			return node.Name, nil
+		if match && matchesAllowedTopologies(node, allowedTopologies) {
+			candidates = append(candidates, node)
 		}
 	}
+	if len(candidates) == 0 {
+		klog.Errorf("failed to find nodes that match the node affinity requirements and allowed topologies for pv[%s]", pv.Name)
+		return "", nil
+	}
+
+	managedContents, err := ctrl.countManagedContents()
+	if err != nil {
+		return "", err
+	}
+	// Break ties by name so that the choice does not depend on list order.
+	sort.Slice(candidates, func(i, j int) bool {
+		ci, cj := managedContents[candidates[i].Name], managedContents[candidates[j].Name]
+		if ci != cj {
+			return ci < cj
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0].Name, nil
+}
 
-	klog.Errorf("failed to find nodes that match the node affinity requirements for pv[%s]", pv.Name)
-	return "", nil
+// getAllowedTopologies returns the allowed topologies of the StorageClass of
+// the PV, or nil if the PV has no StorageClass or it does not exist anymore.
+func (ctrl *csiNfsExportCommonController) getAllowedTopologies(pv *v1.PersistentVolume) ([]v1.TopologySelectorTerm, error) {
+	if pv.Spec.StorageClassName == "" {
+		return nil, nil
+	}
+	class, err := ctrl.client.StorageV1().StorageClasses().Get(context.TODO(), pv.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			klog.V(4).Infof("StorageClass %s of pv %s not found, ignoring allowed topologies", pv.Spec.StorageClassName, pv.Name)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get StorageClass %s of pv %s: %v", pv.Spec.StorageClassName, pv.Name, err)
+	}
+	return class.AllowedTopologies, nil
+}
+
+// countManagedContents returns the number of contents managed by each node.
+func (ctrl *csiNfsExportCommonController) countManagedContents() (map[string]int, error) {
+	contents, err := ctrl.contentLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to get the list of contents: %q", err)
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, content := range contents {
+		if nodeName, ok := content.Labels[utils.VolumeNfsExportContentManagedByLabel]; ok {
+			counts[nodeName]++
+		}
+	}
+	return counts, nil
+}
+
+// matchesAllowedTopologies returns true if the labels of the node match at
+// least one of the terms. An empty list of terms matches every node.
+func matchesAllowedTopologies(node *v1.Node, terms []v1.TopologySelectorTerm) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	for _, term := range terms {
+		if matchesTopologySelectorTerm(node, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesTopologySelectorTerm(node *v1.Node, term v1.TopologySelectorTerm) bool {
+	for _, expression := range term.MatchLabelExpressions {
+		value, ok := node.Labels[expression.Key]
+		if !ok {
+			return false
+		}
+		found := false
+		for _, allowed := range expression.Values {
+			if value == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
 }
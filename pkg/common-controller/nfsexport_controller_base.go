@@ -17,19 +17,26 @@ limitations under the License.
 package common_controller
 
 import (
+	"context"
 	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
 	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
 	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
 	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/eventtemplates"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/metrics"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -40,10 +47,14 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	klog "k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 type csiNfsExportCommonController struct {
 	clientset     clientset.Interface
+	// statusClientset is used for status updates, so that they are
+	// throttled separately from the other requests.
+	statusClientset clientset.Interface
 	client        kubernetes.Interface
 	eventRecorder record.EventRecorder
 	nfsexportQueue workqueue.RateLimitingInterface
@@ -53,39 +64,112 @@ type csiNfsExportCommonController struct {
 	nfsexportListerSynced cache.InformerSynced
 	contentLister        storagelisters.VolumeNfsExportContentLister
 	contentListerSynced  cache.InformerSynced
-	classLister          storagelisters.VolumeNfsExportClassLister
-	classListerSynced    cache.InformerSynced
-	pvcLister            corelisters.PersistentVolumeClaimLister
-	pvcListerSynced      cache.InformerSynced
-	nodeLister           corelisters.NodeLister
-	nodeListerSynced     cache.InformerSynced
+	// contentIndexer indexes the cached contents by the VolumeNfsExport
+	// they are bound to and by their nfsexport handle, see
+	// contentNfsExportIndex and utils.ContentHandleIndex.
+	contentIndexer    cache.Indexer
+	classLister       storagelisters.VolumeNfsExportClassLister
+	classListerSynced cache.InformerSynced
+	pvcLister         corelisters.PersistentVolumeClaimLister
+	pvcListerSynced   cache.InformerSynced
+	pvLister          corelisters.PersistentVolumeLister
+	pvListerSynced    cache.InformerSynced
+	nodeLister        corelisters.NodeLister
+	nodeListerSynced  cache.InformerSynced
+
+	// pvcIndexer indexes the cached claims by the VolumeNfsExport they are
+	// restored from, see utils.ClaimSourceNfsExportIndex.
+	pvcIndexer cache.Indexer
+	// podIndexer indexes the cached pods by the claims they use and by
+	// their quiesce request, see utils.PodClaimIndex and
+	// utils.PodQuiesceRequestIndex. It is nil if the pod informer is
+	// disabled.
+	podIndexer      cache.Indexer
+	podListerSynced cache.InformerSynced
 
 	nfsexportStore cache.Store
 	contentStore  cache.Store
 
 	metricsManager metrics.MetricsManager
 
-	resyncPeriod time.Duration
+	// Resync periods of the VolumeNfsExport and VolumeNfsExportContent
+	// event handlers. 0 disables the periodic resync.
+	nfsexportResyncPeriod time.Duration
+	contentResyncPeriod   time.Duration
 
 	enableDistributedNfsExportting bool
 	preventVolumeModeConversion   bool
+	labelInvalidObjects           bool
+
+	// deletedClasses are the names of the VolumeNfsExportClasses deleted
+	// while the controller runs, see handleNfsExportClassDeleted.
+	deletedClasses *classNames
+	// fallbackToDefaultClass makes a nfsexport whose class is deleted before
+	// its content is created use the default class instead of failing, see
+	// handleNfsExportClassDeleted.
+	fallbackToDefaultClass bool
+
+	// contentEventCoalescer delays content events by a short window and
+	// drops the ones that arrive while an event for the same content is
+	// already pending. It is nil if coalescing is disabled.
+	contentEventCoalescer *eventCoalescer
+
+	// invalidLabelToggles caps how many times per hour the invalid label of
+	// an object is added or removed. It is nil if there is no cap.
+	invalidLabelToggles *labelToggleLimiter
+
+	// nfsexportQueueWait tracks how long nfsexports wait in the nfsexport
+	// queue, for the queue wait phase of the operation metrics.
+	nfsexportQueueWait *queueWaitTracker
+
+	// running is 1 once Run is called, i.e. once this replica is the
+	// leader. It is accessed atomically.
+	running int32
+
+	// pvcFinalizerSweepInterval is the interval of the sweep removing
+	// leftover PVC finalizers. The sweep is disabled if it is 0.
+	pvcFinalizerSweepInterval time.Duration
+
+	// neverBoundContentGracePeriod is how long a dynamically created content
+	// without status may refer to a nfsexport that no longer exists before it
+	// is deleted. The sweep of these contents is disabled if it is 0.
+	neverBoundContentGracePeriod time.Duration
+
+	// apiServerThrottle makes the workers back off while the API server
+	// throttles the controller. It is nil if the workers never back off.
+	apiServerThrottle *utils.APIServerThrottle
+
+	// clock is the source of the current time of the controller, e.g. for
+	// error timestamps and the time to ready. Tests replace it with a fake
+	// clock.
+	clock clock.Clock
 }
 
 // NewCSINfsExportController returns a new *csiNfsExportCommonController
 func NewCSINfsExportCommonController(
 	clientset clientset.Interface,
+	statusClientset clientset.Interface,
 	client kubernetes.Interface,
 	volumeNfsExportInformer storageinformers.VolumeNfsExportInformer,
 	volumeNfsExportContentInformer storageinformers.VolumeNfsExportContentInformer,
 	volumeNfsExportClassInformer storageinformers.VolumeNfsExportClassInformer,
 	pvcInformer coreinformers.PersistentVolumeClaimInformer,
+	pvInformer coreinformers.PersistentVolumeInformer,
 	nodeInformer coreinformers.NodeInformer,
+	podInformer coreinformers.PodInformer,
 	metricsManager metrics.MetricsManager,
-	resyncPeriod time.Duration,
+	nfsexportResyncPeriod time.Duration,
+	contentResyncPeriod time.Duration,
 	nfsexportRateLimiter workqueue.RateLimiter,
 	contentRateLimiter workqueue.RateLimiter,
 	enableDistributedNfsExportting bool,
 	preventVolumeModeConversion bool,
+	labelInvalidObjects bool,
+	contentEventCoalesceWindow time.Duration,
+	pvcFinalizerSweepInterval time.Duration,
+	neverBoundContentGracePeriod time.Duration,
+	invalidLabelToggleLimit int,
+	fallbackToDefaultClass bool,
 ) *csiNfsExportCommonController {
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartLogging(klog.Infof)
@@ -95,18 +179,62 @@ func NewCSINfsExportCommonController(
 
 	ctrl := &csiNfsExportCommonController{
 		clientset:      clientset,
+		statusClientset: statusClientset,
 		client:         client,
 		eventRecorder:  eventRecorder,
-		resyncPeriod:   resyncPeriod,
+		nfsexportResyncPeriod: nfsexportResyncPeriod,
+		contentResyncPeriod:   contentResyncPeriod,
 		nfsexportStore:  cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
 		contentStore:   cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
-		nfsexportQueue:  workqueue.NewNamedRateLimitingQueue(nfsexportRateLimiter, "nfsexport-controller-nfsexport"),
-		contentQueue:   workqueue.NewNamedRateLimitingQueue(contentRateLimiter, "nfsexport-controller-content"),
+		nfsexportQueue:  newBacklogQueue(nfsexportRateLimiter, "nfsexport-controller-nfsexport"),
+		contentQueue:   newBacklogQueue(contentRateLimiter, "nfsexport-controller-content"),
 		metricsManager: metricsManager,
+		clock:          clock.RealClock{},
+	}
+	ctrl.nfsexportQueueWait = newQueueWaitTracker(ctrl.clock)
+	ctrl.deletedClasses = newClassNames()
+
+	// Without a PVC informer, the controller runs in content-only mode and
+	// only handles pre-provisioned nfsexports.
+	if pvcInformer != nil {
+		ctrl.pvcLister = pvcInformer.Lister()
+		ctrl.pvcListerSynced = pvcInformer.Informer().HasSynced
+		if err := pvcInformer.Informer().AddIndexers(cache.Indexers{
+			utils.ClaimSourceNfsExportIndex: utils.ClaimSourceNfsExportIndexFunc,
+		}); err != nil {
+			klog.Errorf("failed to add the %s index to the claim informer: %v", utils.ClaimSourceNfsExportIndex, err)
+		}
+		ctrl.pvcIndexer = pvcInformer.Informer().GetIndexer()
+		pvcInformer.Informer().AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc: func(obj interface{}) { ctrl.enqueueSourceNfsExportWork(obj) },
+				UpdateFunc: func(oldObj, newObj interface{}) {
+					ctrl.enqueueSourceNfsExportWork(newObj)
+					ctrl.enqueueRestoreSourceNfsExport(oldObj, newObj)
+				},
+				DeleteFunc: func(obj interface{}) {
+					ctrl.enqueueClaimNfsExports(obj)
+					ctrl.enqueueRestoreSourceNfsExport(obj, nil)
+				},
+			},
+		)
 	}
 
-	ctrl.pvcLister = pvcInformer.Lister()
-	ctrl.pvcListerSynced = pvcInformer.Informer().HasSynced
+	if pvInformer != nil {
+		ctrl.pvLister = pvInformer.Lister()
+		ctrl.pvListerSynced = pvInformer.Informer().HasSynced
+	}
+
+	if podInformer != nil {
+		if err := podInformer.Informer().AddIndexers(cache.Indexers{
+			utils.PodClaimIndex:          utils.PodClaimIndexFunc,
+			utils.PodQuiesceRequestIndex: utils.PodQuiesceRequestIndexFunc,
+		}); err != nil {
+			klog.Errorf("failed to add the %s and %s indexes to the pod informer: %v", utils.PodClaimIndex, utils.PodQuiesceRequestIndex, err)
+		}
+		ctrl.podIndexer = podInformer.Informer().GetIndexer()
+		ctrl.podListerSynced = podInformer.Informer().HasSynced
+	}
 
 	volumeNfsExportInformer.Informer().AddEventHandlerWithResyncPeriod(
 		cache.ResourceEventHandlerFuncs{
@@ -114,22 +242,49 @@ func NewCSINfsExportCommonController(
 			UpdateFunc: func(oldObj, newObj interface{}) { ctrl.enqueueNfsExportWork(newObj) },
 			DeleteFunc: func(obj interface{}) { ctrl.enqueueNfsExportWork(obj) },
 		},
-		ctrl.resyncPeriod,
+		ctrl.nfsexportResyncPeriod,
 	)
 	ctrl.nfsexportLister = volumeNfsExportInformer.Lister()
 	ctrl.nfsexportListerSynced = volumeNfsExportInformer.Informer().HasSynced
 
+	if contentEventCoalesceWindow > 0 {
+		ctrl.contentEventCoalescer = newEventCoalescer(contentEventCoalesceWindow, ctrl.clock)
+	}
+
 	volumeNfsExportContentInformer.Informer().AddEventHandlerWithResyncPeriod(
 		cache.ResourceEventHandlerFuncs{
-			AddFunc:    func(obj interface{}) { ctrl.enqueueContentWork(obj) },
-			UpdateFunc: func(oldObj, newObj interface{}) { ctrl.enqueueContentWork(newObj) },
+			AddFunc: func(obj interface{}) { ctrl.enqueueContentWorkCoalesced(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				ctrl.enqueueContentStatusChange(oldObj, newObj)
+				ctrl.enqueueContentWorkCoalesced(newObj)
+			},
 			DeleteFunc: func(obj interface{}) { ctrl.enqueueContentWork(obj) },
 		},
-		ctrl.resyncPeriod,
+		ctrl.contentResyncPeriod,
 	)
 	ctrl.contentLister = volumeNfsExportContentInformer.Lister()
 	ctrl.contentListerSynced = volumeNfsExportContentInformer.Informer().HasSynced
+	if err := volumeNfsExportContentInformer.Informer().AddIndexers(cache.Indexers{
+		contentNfsExportIndex:    contentNfsExportIndexFunc,
+		utils.ContentHandleIndex: utils.ContentHandleIndexFunc,
+	}); err != nil {
+		klog.Errorf("failed to add the %s and %s indexes to the content informer: %v", contentNfsExportIndex, utils.ContentHandleIndex, err)
+	}
+	ctrl.contentIndexer = volumeNfsExportContentInformer.Informer().GetIndexer()
 
+	volumeNfsExportClassInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				ctrl.deletedClasses.remove(obj)
+				ctrl.enqueueClassMissingNfsExports()
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) { ctrl.enqueueClassMissingNfsExports() },
+			DeleteFunc: func(obj interface{}) {
+				ctrl.deletedClasses.add(obj)
+				ctrl.enqueueClassMissingNfsExports()
+			},
+		},
+	)
 	ctrl.classLister = volumeNfsExportClassInformer.Lister()
 	ctrl.classListerSynced = volumeNfsExportClassInformer.Informer().HasSynced
 
@@ -141,21 +296,62 @@ func NewCSINfsExportCommonController(
 	}
 
 	ctrl.preventVolumeModeConversion = preventVolumeModeConversion
+	ctrl.labelInvalidObjects = labelInvalidObjects
+	if invalidLabelToggleLimit > 0 {
+		ctrl.invalidLabelToggles = newLabelToggleLimiter(invalidLabelToggleLimit, invalidLabelToggleWindow, ctrl.clock)
+	}
+	ctrl.pvcFinalizerSweepInterval = pvcFinalizerSweepInterval
+	ctrl.neverBoundContentGracePeriod = neverBoundContentGracePeriod
+	ctrl.fallbackToDefaultClass = fallbackToDefaultClass
 
 	return ctrl
 }
 
+// SetEventTemplates makes the controller emit its events with the messages of
+// templates. It must be called before Run.
+func (ctrl *csiNfsExportCommonController) SetEventTemplates(templates *eventtemplates.Templates) {
+	ctrl.eventRecorder = eventtemplates.NewRecorder(ctrl.eventRecorder, templates)
+}
+
+// SetAPIServerThrottle makes the workers of both queues back off while
+// throttle records 429 responses of the API server: the keys they get are
+// queued again after the backoff instead of being synced. The clients of the
+// controller must record their responses in throttle, see
+// utils.APIServerThrottle.WrapTransport. It must be called before Run.
+func (ctrl *csiNfsExportCommonController) SetAPIServerThrottle(throttle *utils.APIServerThrottle) {
+	ctrl.apiServerThrottle = throttle
+}
+
+// apiServerBackoff returns how long the workers should wait before syncing
+// the next key, 0 if the API server does not throttle the controller.
+func (ctrl *csiNfsExportCommonController) apiServerBackoff() time.Duration {
+	if ctrl.apiServerThrottle == nil {
+		return 0
+	}
+	return ctrl.apiServerThrottle.Backoff()
+}
+
 func (ctrl *csiNfsExportCommonController) Run(workers int, stopCh <-chan struct{}) {
 	defer ctrl.nfsexportQueue.ShutDown()
 	defer ctrl.contentQueue.ShutDown()
 
 	klog.Infof("Starting nfsexport controller")
+	atomic.StoreInt32(&ctrl.running, 1)
 	defer klog.Infof("Shutting nfsexport controller")
 
-	informersSynced := []cache.InformerSynced{ctrl.nfsexportListerSynced, ctrl.contentListerSynced, ctrl.classListerSynced, ctrl.pvcListerSynced}
+	informersSynced := []cache.InformerSynced{ctrl.nfsexportListerSynced, ctrl.contentListerSynced, ctrl.classListerSynced}
+	if ctrl.pvcLister != nil {
+		informersSynced = append(informersSynced, ctrl.pvcListerSynced)
+	}
 	if ctrl.enableDistributedNfsExportting {
 		informersSynced = append(informersSynced, ctrl.nodeListerSynced)
 	}
+	if ctrl.pvLister != nil {
+		informersSynced = append(informersSynced, ctrl.pvListerSynced)
+	}
+	if ctrl.podIndexer != nil {
+		informersSynced = append(informersSynced, ctrl.podListerSynced)
+	}
 
 	if !cache.WaitForCacheSync(stopCh, informersSynced...) {
 		klog.Errorf("Cannot sync caches")
@@ -168,6 +364,12 @@ func (ctrl *csiNfsExportCommonController) Run(workers int, stopCh <-chan struct{
 		go wait.Until(ctrl.nfsexportWorker, 0, stopCh)
 		go wait.Until(ctrl.contentWorker, 0, stopCh)
 	}
+	if ctrl.pvcFinalizerSweepInterval > 0 && ctrl.pvcLister != nil {
+		go wait.Until(ctrl.sweepPVCFinalizers, ctrl.pvcFinalizerSweepInterval, stopCh)
+	}
+	if ctrl.neverBoundContentGracePeriod > 0 {
+		go wait.Until(ctrl.sweepNeverBoundContents, ctrl.neverBoundContentGracePeriod, stopCh)
+	}
 
 	<-stopCh
 }
@@ -185,6 +387,81 @@ func (ctrl *csiNfsExportCommonController) enqueueNfsExportWork(obj interface{})
 			return
 		}
 		klog.V(5).Infof("enqueued %q for sync", objName)
+		ctrl.nfsexportQueueWait.enqueued(objName)
+		ctrl.nfsexportQueue.Add(objName)
+	}
+}
+
+// enqueueSourceNfsExportWork adds the nfsexport a bound claim was restored
+// from to the nfsexport work queue, so that it is deleted if requested with
+// the AnnDeleteSourceWhenBound annotation of one of its claims.
+func (ctrl *csiNfsExportCommonController) enqueueSourceNfsExportWork(obj interface{}) {
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok || pvc.Status.Phase != v1.ClaimBound {
+		return
+	}
+	nfsexportName := getSourceNfsExportName(pvc)
+	if nfsexportName == "" {
+		return
+	}
+	objName := pvc.Namespace + "/" + nfsexportName
+	klog.V(5).Infof("enqueued %q for sync, claim %s/%s restored from it is bound", objName, pvc.Namespace, pvc.Name)
+	ctrl.nfsexportQueueWait.enqueued(objName)
+	ctrl.nfsexportQueue.Add(objName)
+}
+
+// enqueueRestoreSourceNfsExport adds the nfsexport a PVC is restored from to
+// the nfsexport work queue when the PVC leaves the Pending phase or is deleted
+// while Pending, so that the deletion of the nfsexport, which waits for the
+// restore to finish, resumes without waiting for a resync.
+func (ctrl *csiNfsExportCommonController) enqueueRestoreSourceNfsExport(oldObj, newObj interface{}) {
+	if unknown, ok := oldObj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		oldObj = unknown.Obj
+	}
+	oldPVC, ok := oldObj.(*v1.PersistentVolumeClaim)
+	if !ok || oldPVC.Status.Phase != v1.ClaimPending {
+		return
+	}
+	if newPVC, ok := newObj.(*v1.PersistentVolumeClaim); ok && newPVC.Status.Phase == v1.ClaimPending {
+		return
+	}
+	nfsexportName := getSourceNfsExportName(oldPVC)
+	if nfsexportName == "" {
+		return
+	}
+	nfsexport, err := ctrl.nfsexportLister.VolumeNfsExports(oldPVC.Namespace).Get(nfsexportName)
+	if err != nil || nfsexport.DeletionTimestamp == nil {
+		// Only the deletion of a nfsexport waits for its restores.
+		return
+	}
+	objName := utils.NfsExportKey(nfsexport)
+	klog.V(5).Infof("enqueued %q for sync, claim %s/%s restored from it is no longer pending", objName, oldPVC.Namespace, oldPVC.Name)
+	ctrl.nfsexportQueueWait.enqueued(objName)
+	ctrl.nfsexportQueue.Add(objName)
+}
+
+// enqueueClaimNfsExports adds the nfsexports of a deleted PVC to the
+// nfsexport work queue, so that their SourceDeleted condition is set.
+func (ctrl *csiNfsExportCommonController) enqueueClaimNfsExports(obj interface{}) {
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	pvc, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return
+	}
+	nfsexports, err := ctrl.nfsexportLister.VolumeNfsExports(pvc.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list the nfsexports of deleted claim %s/%s: %v", pvc.Namespace, pvc.Name, err)
+		return
+	}
+	for _, nfsexport := range nfsexports {
+		if !isNfsExportSourceClaim(nfsexport, pvc.Name) {
+			continue
+		}
+		objName := utils.NfsExportKey(nfsexport)
+		klog.V(5).Infof("enqueued %q for sync, its source claim %s/%s is deleted", objName, pvc.Namespace, pvc.Name)
+		ctrl.nfsexportQueueWait.enqueued(objName)
 		ctrl.nfsexportQueue.Add(objName)
 	}
 }
@@ -206,6 +483,298 @@ func (ctrl *csiNfsExportCommonController) enqueueContentWork(obj interface{}) {
 	}
 }
 
+// enqueueContentStatusChange adds the nfsexport a content is bound to to the
+// nfsexport work queue when the status of the content changes, so that the
+// nfsexport status is updated without waiting for the content to be synced.
+func (ctrl *csiNfsExportCommonController) enqueueContentStatusChange(oldObj, newObj interface{}) {
+	oldContent, ok := oldObj.(*crdv1.VolumeNfsExportContent)
+	if !ok {
+		return
+	}
+	newContent, ok := newObj.(*crdv1.VolumeNfsExportContent)
+	if !ok || reflect.DeepEqual(oldContent.Status, newContent.Status) {
+		return
+	}
+	objName := contentNfsExportKey(newContent)
+	if objName == "" {
+		return
+	}
+	klog.V(5).Infof("enqueued %q for sync, the status of content %s changed", objName, newContent.Name)
+	ctrl.nfsexportQueueWait.enqueued(objName)
+	ctrl.nfsexportQueue.Add(objName)
+}
+
+// enqueueContentWorkCoalesced adds nfsexport content to the content work
+// queue after the coalescing window, unless an event for the same content is
+// already pending. The content is read from the informer cache when it is
+// synced, so the pending event covers all events coalesced into it.
+func (ctrl *csiNfsExportCommonController) enqueueContentWorkCoalesced(obj interface{}) {
+	if ctrl.contentEventCoalescer == nil {
+		ctrl.enqueueContentWork(obj)
+		return
+	}
+	content, ok := obj.(*crdv1.VolumeNfsExportContent)
+	if !ok {
+		return
+	}
+	objName, err := cache.DeletionHandlingMetaNamespaceKeyFunc(content)
+	if err != nil {
+		klog.Errorf("failed to get key from object: %v, %v", err, content)
+		return
+	}
+	if !ctrl.contentEventCoalescer.admit(content.UID) {
+		klog.V(5).Infof("coalesced event for %q", objName)
+		ctrl.metricsManager.RecordCoalescedEvent("volumenfsexportcontents")
+		return
+	}
+	klog.V(5).Infof("enqueued %q for sync after %v", objName, ctrl.contentEventCoalescer.window)
+	ctrl.contentQueue.AddAfter(objName, ctrl.contentEventCoalescer.window)
+}
+
+// classNames is a set of VolumeNfsExportClass names.
+type classNames struct {
+	lock  sync.Mutex
+	names map[string]struct{}
+}
+
+func newClassNames() *classNames {
+	return &classNames{names: make(map[string]struct{})}
+}
+
+// add adds the name of the class obj.
+func (c *classNames) add(obj interface{}) {
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	class, ok := obj.(*crdv1.VolumeNfsExportClass)
+	if !ok {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.names[class.Name] = struct{}{}
+}
+
+// remove removes the name of the class obj.
+func (c *classNames) remove(obj interface{}) {
+	class, ok := obj.(*crdv1.VolumeNfsExportClass)
+	if !ok {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.names, class.Name)
+}
+
+// has returns true if the set contains className.
+func (c *classNames) has(className string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.names[className]
+	return ok
+}
+
+// eventCoalescer tracks, by object UID, the events admitted within the last
+// window.
+type eventCoalescer struct {
+	window time.Duration
+	clock  clock.PassiveClock
+
+	lock      sync.Mutex
+	pending   map[types.UID]time.Time
+	lastPrune time.Time
+}
+
+func newEventCoalescer(window time.Duration, clock clock.PassiveClock) *eventCoalescer {
+	return &eventCoalescer{
+		window:  window,
+		clock:   clock,
+		pending: make(map[types.UID]time.Time),
+	}
+}
+
+// admit returns true if an event for the object with the given UID should be
+// enqueued, and false if it is coalesced with an event admitted less than
+// window ago.
+func (c *eventCoalescer) admit(uid types.UID) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.clock.Now()
+	if admitted, ok := c.pending[uid]; ok && now.Sub(admitted) < c.window {
+		return false
+	}
+	// Drop expired entries at most once per window so that the map does not
+	// grow with deleted objects.
+	if now.Sub(c.lastPrune) >= c.window {
+		for pendingUID, admitted := range c.pending {
+			if now.Sub(admitted) >= c.window {
+				delete(c.pending, pendingUID)
+			}
+		}
+		c.lastPrune = now
+	}
+	c.pending[uid] = now
+	return true
+}
+
+// invalidLabelToggleWindow is the window over which the toggles of the invalid
+// label of an object are counted.
+const invalidLabelToggleWindow = time.Hour
+
+// labelToggleLimiter tracks, by object UID, the label toggles allowed within
+// the last window and refuses toggles beyond limit.
+type labelToggleLimiter struct {
+	limit  int
+	window time.Duration
+	clock  clock.PassiveClock
+
+	lock      sync.Mutex
+	toggles   map[types.UID][]time.Time
+	lastPrune time.Time
+}
+
+func newLabelToggleLimiter(limit int, window time.Duration, clock clock.PassiveClock) *labelToggleLimiter {
+	return &labelToggleLimiter{
+		limit:   limit,
+		window:  window,
+		clock:   clock,
+		toggles: make(map[types.UID][]time.Time),
+	}
+}
+
+// allow records a toggle of the label of the object with the given UID and
+// returns true, or returns false without recording it if the object was
+// already toggled limit times within the last window.
+func (l *labelToggleLimiter) allow(uid types.UID) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.clock.Now()
+	// Drop the entries of objects that have not been toggled within the
+	// window at most once per window so that the map does not grow with
+	// deleted objects.
+	if now.Sub(l.lastPrune) >= l.window {
+		for toggledUID := range l.toggles {
+			l.prune(toggledUID, now)
+		}
+		l.lastPrune = now
+	}
+	if len(l.prune(uid, now)) >= l.limit {
+		return false
+	}
+	l.toggles[uid] = append(l.toggles[uid], now)
+	return true
+}
+
+// flapping returns true if the object with the given UID was toggled limit
+// times within the last window, i.e. if its next toggle would be refused.
+func (l *labelToggleLimiter) flapping(uid types.UID) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.prune(uid, l.clock.Now())) >= l.limit
+}
+
+// prune drops the toggles of the object with the given UID that are older
+// than window and returns the remaining ones. It must be called with lock
+// held.
+func (l *labelToggleLimiter) prune(uid types.UID, now time.Time) []time.Time {
+	toggles := l.toggles[uid]
+	i := 0
+	for i < len(toggles) && now.Sub(toggles[i]) >= l.window {
+		i++
+	}
+	if i == len(toggles) {
+		delete(l.toggles, uid)
+		return nil
+	}
+	toggles = toggles[i:]
+	l.toggles[uid] = toggles
+	return toggles
+}
+
+// queueWaitTracker measures, by queue key, the time between a key being
+// enqueued and a worker picking it up. Keys added again while already
+// queued keep their first enqueue time, like the work queue itself does.
+type queueWaitTracker struct {
+	clock clock.PassiveClock
+
+	lock    sync.Mutex
+	enqueue map[string]time.Time
+	waited  map[string]time.Duration
+}
+
+func newQueueWaitTracker(clock clock.PassiveClock) *queueWaitTracker {
+	return &queueWaitTracker{
+		clock:   clock,
+		enqueue: make(map[string]time.Time),
+		waited:  make(map[string]time.Duration),
+	}
+}
+
+// enqueued records that key was added to the queue.
+func (t *queueWaitTracker) enqueued(key string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, ok := t.enqueue[key]; !ok {
+		t.enqueue[key] = t.clock.Now()
+	}
+}
+
+// dequeued records that a worker picked up key.
+func (t *queueWaitTracker) dequeued(key string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	enqueued, ok := t.enqueue[key]
+	if !ok {
+		// The key was re-added by the rate limiter, not by an event.
+		return
+	}
+	delete(t.enqueue, key)
+	t.waited[key] = t.clock.Since(enqueued)
+}
+
+// take returns and forgets the time key last waited in the queue.
+func (t *queueWaitTracker) take(key string) (time.Duration, bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	waited, ok := t.waited[key]
+	delete(t.waited, key)
+	return waited, ok
+}
+
+// done drops the time key last waited in the queue once a worker finished
+// processing it, whether or not the sync took it.
+func (t *queueWaitTracker) done(key string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.waited, key)
+}
+
+// forget drops everything tracked for key.
+func (t *queueWaitTracker) forget(key string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.enqueue, key)
+	delete(t.waited, key)
+}
+
+// enqueueClassMissingNfsExports enqueues the nfsexports whose default class
+// could not be determined, so that they get one as soon as a change of the
+// VolumeNfsExportClasses makes it possible instead of on their next retry.
+func (ctrl *csiNfsExportCommonController) enqueueClassMissingNfsExports() {
+	nfsexports, err := ctrl.nfsexportLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list nfsexports missing a class: %v", err)
+		return
+	}
+	for _, nfsexport := range nfsexports {
+		if isNfsExportClassMissing(nfsexport) || isNfsExportBlockedOn(nfsexport, crdv1.BlockedReasonClassNotFound) {
+			ctrl.enqueueNfsExportWork(nfsexport)
+		}
+	}
+}
+
 // nfsexportWorker is the main worker for VolumeNfsExports.
 func (ctrl *csiNfsExportCommonController) nfsexportWorker() {
 	keyObj, quit := ctrl.nfsexportQueue.Get()
@@ -213,8 +782,25 @@ func (ctrl *csiNfsExportCommonController) nfsexportWorker() {
 		return
 	}
 	defer ctrl.nfsexportQueue.Done(keyObj)
+	if backoff := ctrl.apiServerBackoff(); backoff > 0 {
+		// The nfsexport keeps waiting in the queue until the API server
+		// stops throttling the controller.
+		klog.V(4).Infof("API server is throttling the controller, syncing nfsexport %q in %v", keyObj.(string), backoff)
+		ctrl.nfsexportQueue.AddAfter(keyObj, backoff)
+		return
+	}
+	ctrl.nfsexportQueueWait.dequeued(keyObj.(string))
+	defer ctrl.nfsexportQueueWait.done(keyObj.(string))
 
 	if err := ctrl.syncNfsExportByKey(keyObj.(string)); err != nil {
+		if utils.IsTerminalUpdateError(err) {
+			// Retrying right away would fail the same way, until the
+			// nfsexport or the RBAC rules of the controller change.
+			ctrl.nfsexportQueue.Forget(keyObj)
+			ctrl.nfsexportQueue.AddAfter(keyObj, utils.TerminalUpdateErrorRetryDelay)
+			klog.Errorf("Failed to sync nfsexport %q, will retry in %v: %v", keyObj.(string), utils.TerminalUpdateErrorRetryDelay, err)
+			return
+		}
 		// Rather than wait for a full resync, re-add the key to the
 		// queue to be processed.
 		ctrl.nfsexportQueue.AddRateLimited(keyObj)
@@ -241,6 +827,12 @@ func (ctrl *csiNfsExportCommonController) syncNfsExportByKey(key string) error {
 		// The volume nfsexport still exists in informer cache, the event must have
 		// been add/update/sync
 		newNfsExport, err := ctrl.checkAndUpdateNfsExportClass(nfsexport)
+		if _, ok := err.(*defaultClassError); ok {
+			// The ClassMissing condition is recorded and the nfsexport is
+			// enqueued again when a VolumeNfsExportClass changes, retrying
+			// it before would not help.
+			return nil
+		}
 		if err == nil || (newNfsExport.ObjectMeta.DeletionTimestamp != nil && errors.IsNotFound(err)) {
 			// If the VolumeNfsExportClass is not found, we still need to process an update
 			// so that syncNfsExport can delete the nfsexport, should it still exist in the
@@ -288,8 +880,21 @@ func (ctrl *csiNfsExportCommonController) contentWorker() {
 		return
 	}
 	defer ctrl.contentQueue.Done(keyObj)
+	if backoff := ctrl.apiServerBackoff(); backoff > 0 {
+		klog.V(4).Infof("API server is throttling the controller, syncing content %q in %v", keyObj.(string), backoff)
+		ctrl.contentQueue.AddAfter(keyObj, backoff)
+		return
+	}
 
 	if err := ctrl.syncContentByKey(keyObj.(string)); err != nil {
+		if utils.IsTerminalUpdateError(err) {
+			// Retrying right away would fail the same way, until the
+			// content or the RBAC rules of the controller change.
+			ctrl.contentQueue.Forget(keyObj)
+			ctrl.contentQueue.AddAfter(keyObj, utils.TerminalUpdateErrorRetryDelay)
+			klog.Errorf("Failed to sync content %q, will retry in %v: %v", keyObj.(string), utils.TerminalUpdateErrorRetryDelay, err)
+			return
+		}
 		// Rather than wait for a full resync, re-add the key to the
 		// queue to be processed.
 		ctrl.contentQueue.AddRateLimited(keyObj)
@@ -356,15 +961,29 @@ func (ctrl *csiNfsExportCommonController) checkAndUpdateNfsExportClass(nfsexport
 	if className != nil {
 		klog.V(5).Infof("checkAndUpdateNfsExportClass [%s]: VolumeNfsExportClassName [%s]", nfsexport.Name, *className)
 		class, err = ctrl.getNfsExportClass(*className)
+		if errors.IsNotFound(err) && ctrl.isContentCreationPending(nfsexport) {
+			if ctrl.deletedClasses.has(*className) || isNfsExportClassDeleted(nfsexport) {
+				return ctrl.handleNfsExportClassDeleted(nfsexport, *className)
+			}
+			// The class may still be created, e.g. by the same manifest
+			// as the nfsexport, so the nfsexport waits for it.
+			newNfsExport = ctrl.setNfsExportBlocked(nfsexport, crdv1.BlockedReasonClassNotFound, fmt.Sprintf("VolumeNfsExportClass %s does not exist", *className))
+		}
 		if err != nil {
 			klog.Errorf("checkAndUpdateNfsExportClass failed to getNfsExportClass %v", err)
-			ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, false, v1.EventTypeWarning, "GetNfsExportClassFailed", fmt.Sprintf("Failed to get nfsexport class with error %v", err))
+			ctrl.updateNfsExportErrorStatusWithEvent(newNfsExport, false, v1.EventTypeWarning, "GetNfsExportClassFailed", fmt.Sprintf("Failed to get nfsexport class with error %v", err))
 			// we need to return the original nfsexport even if the class isn't found, as it may need to be deleted
 			return newNfsExport, err
 		}
 	} else {
 		klog.V(5).Infof("checkAndUpdateNfsExportClass [%s]: SetDefaultNfsExportClass", nfsexport.Name)
 		class, newNfsExport, err = ctrl.SetDefaultNfsExportClass(nfsexport)
+		if classErr, ok := err.(*defaultClassError); ok {
+			if err := ctrl.updateNfsExportClassMissing(nfsexport, classErr); err != nil {
+				return nfsexport, err
+			}
+			return nfsexport, classErr
+		}
 		if err != nil {
 			klog.Errorf("checkAndUpdateNfsExportClass failed to setDefaultClass %v", err)
 			ctrl.updateNfsExportErrorStatusWithEvent(nfsexport, false, v1.EventTypeWarning, "SetDefaultNfsExportClassFailed", fmt.Sprintf("Failed to set default nfsexport class with error %v", err))
@@ -375,6 +994,11 @@ func (ctrl *csiNfsExportCommonController) checkAndUpdateNfsExportClass(nfsexport
 	// For pre-provisioned nfsexports, we may not have nfsexport class
 	if class != nil {
 		klog.V(5).Infof("VolumeNfsExportClass [%s] Driver [%s]", class.Name, class.Driver)
+		resolvedNfsExport, err := ctrl.resolveNfsExportClassMissing(newNfsExport, class)
+		if err != nil {
+			return nfsexport, err
+		}
+		newNfsExport = resolvedNfsExport
 	}
 	return newNfsExport, nil
 }
@@ -394,6 +1018,15 @@ func (ctrl *csiNfsExportCommonController) updateNfsExport(nfsexport *crdv1.Volum
 	}
 
 	err = ctrl.syncNfsExport(nfsexport)
+	if errors.IsConflict(err) {
+		// The nfsexport in the informer cache is stale, retry right away
+		// with its latest version rather than wait for the informer.
+		klog.V(3).Infof("could not sync nfsexport %q, retrying with its latest version: %+v", utils.NfsExportKey(nfsexport), err)
+		var latest *crdv1.VolumeNfsExport
+		if latest, err = ctrl.getLatestNfsExport(nfsexport); err == nil {
+			err = ctrl.syncNfsExport(latest)
+		}
+	}
 	if err != nil {
 		if errors.IsConflict(err) {
 			// Version conflict error happens quite often and the controller
@@ -420,6 +1053,15 @@ func (ctrl *csiNfsExportCommonController) updateContent(content *crdv1.VolumeNfs
 		return nil
 	}
 	err = ctrl.syncContent(content)
+	if errors.IsConflict(err) {
+		// The content in the informer cache is stale, retry right away
+		// with its latest version rather than wait for the informer.
+		klog.V(3).Infof("could not sync content %q, retrying with its latest version: %+v", content.Name, err)
+		var latest *crdv1.VolumeNfsExportContent
+		if latest, err = ctrl.getLatestContent(content); err == nil {
+			err = ctrl.syncContent(latest)
+		}
+	}
 	if err != nil {
 		if errors.IsConflict(err) {
 			// Version conflict error happens quite often and the controller
@@ -433,9 +1075,36 @@ func (ctrl *csiNfsExportCommonController) updateContent(content *crdv1.VolumeNfs
 	return nil
 }
 
+// getLatestNfsExport gets the latest version of nfsexport from the API server
+// and stores it in the controller cache.
+func (ctrl *csiNfsExportCommonController) getLatestNfsExport(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExport, error) {
+	latest, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(nfsexport.Namespace).Get(context.TODO(), nfsexport.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = ctrl.storeNfsExportUpdate(latest); err != nil {
+		klog.V(4).Infof("getLatestNfsExport [%s]: cannot update internal cache %v", utils.NfsExportKey(latest), err)
+	}
+	return latest, nil
+}
+
+// getLatestContent gets the latest version of content from the API server and
+// stores it in the controller cache.
+func (ctrl *csiNfsExportCommonController) getLatestContent(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
+	latest, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = ctrl.storeContentUpdate(latest); err != nil {
+		klog.V(4).Infof("getLatestContent [%s]: cannot update internal cache %v", latest.Name, err)
+	}
+	return latest, nil
+}
+
 // deleteNfsExport runs in worker thread and handles "nfsexport deleted" event.
 func (ctrl *csiNfsExportCommonController) deleteNfsExport(nfsexport *crdv1.VolumeNfsExport) {
 	_ = ctrl.nfsexportStore.Delete(nfsexport)
+	ctrl.nfsexportQueueWait.forget(utils.NfsExportKey(nfsexport))
 	klog.V(4).Infof("nfsexport %q deleted", utils.NfsExportKey(nfsexport))
 	driverName, err := ctrl.getNfsExportDriverName(nfsexport)
 	if err != nil {
@@ -444,6 +1113,9 @@ func (ctrl *csiNfsExportCommonController) deleteNfsExport(nfsexport *crdv1.Volum
 		deleteOperationKey := metrics.NewOperationKey(metrics.DeleteNfsExportOperationName, nfsexport.UID)
 		ctrl.metricsManager.RecordMetrics(deleteOperationKey, metrics.NewNfsExportOperationStatus(metrics.NfsExportStatusTypeSuccess), driverName)
 	}
+	// Operations of the nfsexport that are still cached, e.g. a creation
+	// that never finished, are cleaned up after a while.
+	ctrl.metricsManager.ObjectDeleted(nfsexport.UID)
 
 	nfsexportContentName := ""
 	if nfsexport.Status != nil && nfsexport.Status.BoundVolumeNfsExportContentName != nil {
@@ -466,7 +1138,7 @@ func (ctrl *csiNfsExportCommonController) deleteContent(content *crdv1.VolumeNfs
 	_ = ctrl.contentStore.Delete(content)
 	klog.V(4).Infof("content %q deleted", content.Name)
 
-	nfsexportName := utils.NfsExportRefKey(&content.Spec.VolumeNfsExportRef)
+	nfsexportName := contentNfsExportKey(content)
 	if nfsexportName == "" {
 		klog.V(5).Infof("deleteContent[%q]: content not bound", content.Name)
 		return
@@ -508,3 +1180,29 @@ func (ctrl *csiNfsExportCommonController) initializeCaches(nfsexportLister stora
 
 	klog.V(4).Infof("controller initialized")
 }
+
+// contentNfsExportIndex is the name of the index of the content informer
+// keyed by the namespace/name of the VolumeNfsExport a content is bound to.
+const contentNfsExportIndex = "nfsexport"
+
+// contentNfsExportIndexFunc indexes a content by the VolumeNfsExport it is
+// bound to, if any.
+func contentNfsExportIndexFunc(obj interface{}) ([]string, error) {
+	content, ok := obj.(*crdv1.VolumeNfsExportContent)
+	if !ok {
+		return nil, nil
+	}
+	if key := contentNfsExportKey(content); key != "" {
+		return []string{key}, nil
+	}
+	return nil, nil
+}
+
+// contentNfsExportKey returns the key of the VolumeNfsExport content is bound
+// to, or an empty string if it is not bound to any.
+func contentNfsExportKey(content *crdv1.VolumeNfsExportContent) string {
+	if content.Spec.VolumeNfsExportRef.Name == "" {
+		return ""
+	}
+	return utils.NfsExportRefKey(&content.Spec.VolumeNfsExportRef)
+}
@@ -17,14 +17,34 @@ limitations under the License.
 package common_controller
 
 import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientsetfake "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/fakeapiserver"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/metrics"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 var deletionPolicy = crdv1.VolumeNfsExportContentDelete
@@ -128,7 +148,8 @@ func TestGetManagedByNode(t *testing.T) {
 	}
 
 	ctrl := &csiNfsExportCommonController{
-		nodeLister: FakeNodeLister{NodeList: []*v1.Node{node1, node2}},
+		nodeLister:    FakeNodeLister{NodeList: []*v1.Node{node1, node2}},
+		contentLister: newContentLister(t),
 	}
 
 	pv := &v1.PersistentVolume{
@@ -168,7 +189,8 @@ func TestGetManagedByNode(t *testing.T) {
 	}
 
 	ctrl = &csiNfsExportCommonController{
-		nodeLister: FakeNodeLister{NodeList: []*v1.Node{node1}},
+		nodeLister:    FakeNodeLister{NodeList: []*v1.Node{node1}},
+		contentLister: newContentLister(t),
 	}
 
 	nodeName, _ = ctrl.getManagedByNode(pv)
@@ -176,3 +198,1674 @@ func TestGetManagedByNode(t *testing.T) {
 		t.Errorf("Expected no node, Found node(%s)", nodeName)
 	}
 }
+
+func newContentLister(t *testing.T, contents ...*crdv1.VolumeNfsExportContent) storagelisters.VolumeNfsExportContentLister {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, content := range contents {
+		if err := indexer.Add(content); err != nil {
+			t.Fatalf("failed to add content %s to indexer: %v", content.Name, err)
+		}
+	}
+	return storagelisters.NewVolumeNfsExportContentLister(indexer)
+}
+
+func TestGetManagedByNodeSpreadingAndTopology(t *testing.T) {
+	newNode := func(name, zone string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{"zone": zone, "rack": name},
+			},
+		}
+	}
+	newManagedContent := func(name, nodeName string) *crdv1.VolumeNfsExportContent {
+		return &crdv1.VolumeNfsExportContent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{utils.VolumeNfsExportContentManagedByLabel: nodeName},
+			},
+		}
+	}
+	nodes := []*v1.Node{newNode("node1", "zone-a"), newNode("node2", "zone-a"), newNode("node3", "zone-a")}
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "volume1"},
+		Spec: v1.PersistentVolumeSpec{
+			StorageClassName: "sc1",
+			NodeAffinity: &v1.VolumeNodeAffinity{
+				Required: &v1.NodeSelector{
+					NodeSelectorTerms: []v1.NodeSelectorTerm{
+						{
+							MatchExpressions: []v1.NodeSelectorRequirement{
+								{
+									Key:      "zone",
+									Operator: v1.NodeSelectorOpIn,
+									Values:   []string{"zone-a"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	storageClass := func(racks ...string) *storagev1.StorageClass {
+		class := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "sc1"}}
+		if len(racks) > 0 {
+			class.AllowedTopologies = []v1.TopologySelectorTerm{
+				{
+					MatchLabelExpressions: []v1.TopologySelectorLabelRequirement{
+						{Key: "rack", Values: racks},
+					},
+				},
+			}
+		}
+		return class
+	}
+
+	tests := []struct {
+		name         string
+		class        *storagev1.StorageClass
+		contents     []*crdv1.VolumeNfsExportContent
+		expectedNode string
+	}{
+		{
+			name:         "ties are broken by node name",
+			class:        storageClass(),
+			expectedNode: "node1",
+		},
+		{
+			name:         "least loaded node is chosen",
+			class:        storageClass(),
+			contents:     []*crdv1.VolumeNfsExportContent{newManagedContent("content1", "node1"), newManagedContent("content2", "node2"), newManagedContent("content3", "node1")},
+			expectedNode: "node3",
+		},
+		{
+			name:         "allowed topologies restrict the nodes",
+			class:        storageClass("node1", "node2"),
+			contents:     []*crdv1.VolumeNfsExportContent{newManagedContent("content1", "node1")},
+			expectedNode: "node2",
+		},
+		{
+			name:         "no node in allowed topologies",
+			class:        storageClass("node4"),
+			expectedNode: "",
+		},
+		{
+			name:         "missing StorageClass is ignored",
+			contents:     []*crdv1.VolumeNfsExportContent{newManagedContent("content1", "node1")},
+			expectedNode: "node2",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubeClient := fake.NewSimpleClientset()
+			if test.class != nil {
+				kubeClient = fake.NewSimpleClientset(test.class)
+			}
+			ctrl := &csiNfsExportCommonController{
+				client:        kubeClient,
+				nodeLister:    FakeNodeLister{NodeList: nodes},
+				contentLister: newContentLister(t, test.contents...),
+			}
+			nodeName, err := ctrl.getManagedByNode(pv)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if nodeName != test.expectedNode {
+				t.Errorf("expected node %q, got %q", test.expectedNode, nodeName)
+			}
+		})
+	}
+}
+
+func TestNeedsUpdateNfsExportStatusRestoreSize(t *testing.T) {
+	ctrl := &csiNfsExportCommonController{}
+	contentName := "content1"
+	ready := true
+	gi := int64(1073741824)
+	g := int64(1000000000)
+
+	tests := []struct {
+		name         string
+		statusSize   *resource.Quantity
+		contentSize  *int64
+		expectUpdate bool
+	}{
+		{
+			name:         "no size in content",
+			statusSize:   nil,
+			contentSize:  nil,
+			expectUpdate: false,
+		},
+		{
+			name:         "size not yet set in nfsexport status",
+			statusSize:   nil,
+			contentSize:  &gi,
+			expectUpdate: true,
+		},
+		{
+			name:         "binary suffix equals byte count",
+			statusSize:   resourcePtr(resource.MustParse("1Gi")),
+			contentSize:  &gi,
+			expectUpdate: false,
+		},
+		{
+			name:         "plain byte count equals byte count",
+			statusSize:   resourcePtr(resource.MustParse("1073741824")),
+			contentSize:  &gi,
+			expectUpdate: false,
+		},
+		{
+			name:         "decimal suffix equals byte count",
+			statusSize:   resourcePtr(resource.MustParse("1G")),
+			contentSize:  &g,
+			expectUpdate: false,
+		},
+		{
+			name:         "decimal quantity equals byte count",
+			statusSize:   resource.NewQuantity(gi, resource.DecimalSI),
+			contentSize:  &gi,
+			expectUpdate: false,
+		},
+		{
+			name:         "zero size in nfsexport status",
+			statusSize:   resource.NewQuantity(0, resource.BinarySI),
+			contentSize:  &gi,
+			expectUpdate: true,
+		},
+		{
+			name:         "different size",
+			statusSize:   resourcePtr(resource.MustParse("1G")),
+			contentSize:  &gi,
+			expectUpdate: true,
+		},
+	}
+
+	for _, test := range tests {
+		nfsexport := &crdv1.VolumeNfsExport{
+			Status: &crdv1.VolumeNfsExportStatus{
+				BoundVolumeNfsExportContentName: &contentName,
+				ReadyToUse:                      &ready,
+				RestoreSize:                     test.statusSize,
+			},
+		}
+		content := &crdv1.VolumeNfsExportContent{
+			Status: &crdv1.VolumeNfsExportContentStatus{
+				RestoreSize: test.contentSize,
+			},
+		}
+		if got := ctrl.needsUpdateNfsExportStatus(nfsexport, content); got != test.expectUpdate {
+			t.Errorf("%s: expected needsUpdateNfsExportStatus to return %v, got %v", test.name, test.expectUpdate, got)
+		}
+	}
+}
+
+func TestNeedsUpdateNfsExportStatusWarming(t *testing.T) {
+	ctrl := &csiNfsExportCommonController{}
+	contentName := "content1"
+	ready := false
+	warming := func(status metav1.ConditionStatus, reason string) []metav1.Condition {
+		return []metav1.Condition{{Type: crdv1.ConditionWarming, Status: status, Reason: reason}}
+	}
+
+	tests := []struct {
+		name              string
+		statusConditions  []metav1.Condition
+		contentConditions []metav1.Condition
+		expectUpdate      bool
+	}{
+		{
+			name:         "no warm-up",
+			expectUpdate: false,
+		},
+		{
+			name:              "warming not yet reported",
+			contentConditions: warming(metav1.ConditionTrue, crdv1.WarmingReasonInProgress),
+			expectUpdate:      true,
+		},
+		{
+			name:              "warming reported",
+			statusConditions:  warming(metav1.ConditionTrue, crdv1.WarmingReasonInProgress),
+			contentConditions: warming(metav1.ConditionTrue, crdv1.WarmingReasonInProgress),
+			expectUpdate:      false,
+		},
+		{
+			name:              "warm-up timed out",
+			statusConditions:  warming(metav1.ConditionTrue, crdv1.WarmingReasonInProgress),
+			contentConditions: warming(metav1.ConditionFalse, crdv1.WarmingReasonTimedOut),
+			expectUpdate:      true,
+		},
+	}
+
+	for _, test := range tests {
+		nfsexport := &crdv1.VolumeNfsExport{
+			Status: &crdv1.VolumeNfsExportStatus{
+				BoundVolumeNfsExportContentName: &contentName,
+				ReadyToUse:                      &ready,
+				Conditions:                      test.statusConditions,
+			},
+		}
+		content := &crdv1.VolumeNfsExportContent{
+			Status: &crdv1.VolumeNfsExportContentStatus{
+				ReadyToUse: &ready,
+				Conditions: test.contentConditions,
+			},
+		}
+		if got := ctrl.needsUpdateNfsExportStatus(nfsexport, content); got != test.expectUpdate {
+			t.Errorf("%s: expected needsUpdateNfsExportStatus to return %v, got %v", test.name, test.expectUpdate, got)
+		}
+	}
+}
+
+func TestNeedsUpdateNfsExportStatusFailed(t *testing.T) {
+	ctrl := &csiNfsExportCommonController{}
+	contentName := "content1"
+	ready := false
+	failed := []metav1.Condition{{Type: crdv1.ConditionFailed, Status: metav1.ConditionTrue, Reason: crdv1.FailedReasonCreationTimedOut}}
+
+	tests := []struct {
+		name             string
+		statusConditions []metav1.Condition
+		expectUpdate     bool
+	}{
+		{
+			name:         "failure not yet reported",
+			expectUpdate: true,
+		},
+		{
+			name:             "failure reported",
+			statusConditions: failed,
+			expectUpdate:     false,
+		},
+	}
+
+	for _, test := range tests {
+		nfsexport := &crdv1.VolumeNfsExport{
+			Status: &crdv1.VolumeNfsExportStatus{
+				BoundVolumeNfsExportContentName: &contentName,
+				ReadyToUse:                      &ready,
+				Conditions:                      test.statusConditions,
+			},
+		}
+		content := &crdv1.VolumeNfsExportContent{
+			Status: &crdv1.VolumeNfsExportContentStatus{
+				ReadyToUse: &ready,
+				Conditions: failed,
+			},
+		}
+		if got := ctrl.needsUpdateNfsExportStatus(nfsexport, content); got != test.expectUpdate {
+			t.Errorf("%s: expected needsUpdateNfsExportStatus to return %v, got %v", test.name, test.expectUpdate, got)
+		}
+		if got := isNfsExportCreationTimedOut(nfsexport); got != (test.statusConditions != nil) {
+			t.Errorf("%s: expected isNfsExportCreationTimedOut to return %v, got %v", test.name, test.statusConditions != nil, got)
+		}
+	}
+}
+
+func resourcePtr(q resource.Quantity) *resource.Quantity {
+	return &q
+}
+
+func TestEventCoalescer(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	c := newEventCoalescer(time.Second, clock)
+
+	if !c.admit("uid1") {
+		t.Errorf("expected first event for uid1 to be admitted")
+	}
+	if c.admit("uid1") {
+		t.Errorf("expected second event for uid1 within the window to be coalesced")
+	}
+	if !c.admit("uid2") {
+		t.Errorf("expected first event for uid2 to be admitted")
+	}
+
+	clock.Step(time.Second)
+	if !c.admit("uid1") {
+		t.Errorf("expected event for uid1 after the window to be admitted")
+	}
+	if _, ok := c.pending["uid2"]; ok {
+		t.Errorf("expected expired entry for uid2 to be pruned")
+	}
+}
+
+func TestLabelToggleLimiter(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	l := newLabelToggleLimiter(2, time.Hour, clock)
+
+	for i := 0; i < 2; i++ {
+		if !l.allow("uid1") {
+			t.Errorf("expected toggle %d of uid1 to be allowed", i)
+		}
+		clock.Step(10 * time.Minute)
+	}
+	if !l.flapping("uid1") {
+		t.Errorf("expected uid1 to be flapping at the limit")
+	}
+	if l.allow("uid1") {
+		t.Errorf("expected a toggle of uid1 beyond the limit to be refused")
+	}
+	if !l.allow("uid2") {
+		t.Errorf("expected the first toggle of uid2 to be allowed")
+	}
+
+	// The first toggle of uid1 ages out of the window.
+	clock.Step(40 * time.Minute)
+	if l.flapping("uid1") {
+		t.Errorf("expected uid1 not to be flapping once a toggle aged out")
+	}
+	if !l.allow("uid1") {
+		t.Errorf("expected a toggle of uid1 to be allowed once a toggle aged out")
+	}
+
+	clock.Step(time.Hour)
+	l.allow("uid3")
+	if _, ok := l.toggles["uid2"]; ok {
+		t.Errorf("expected expired entry for uid2 to be pruned")
+	}
+}
+
+func TestEnqueueContentStatusChange(t *testing.T) {
+	ctrl := &csiNfsExportCommonController{
+		nfsexportQueue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+		nfsexportQueueWait: newQueueWaitTracker(clocktesting.NewFakeClock(time.Now())),
+	}
+	defer ctrl.nfsexportQueue.ShutDown()
+
+	bound := newContent("content1", "snapuid1", "snap1", "sid1", classGold, "", "pv-handle-1", deletionPolicy, nil, nil, false, false)
+	unbound := newContent("content2", "", "", "sid2", classGold, "", "pv-handle-2", deletionPolicy, nil, nil, false, false)
+	if key := contentNfsExportKey(bound); key != "default/snap1" {
+		t.Errorf("expected content1 to be bound to default/snap1, got %q", key)
+	}
+	if key := contentNfsExportKey(unbound); key != "" {
+		t.Errorf("expected the unbound content2 not to be bound, got %q", key)
+	}
+
+	// An update that leaves the status untouched does not enqueue the nfsexport.
+	relabeled := bound.DeepCopy()
+	relabeled.Labels = map[string]string{"foo": "bar"}
+	ctrl.enqueueContentStatusChange(bound, relabeled)
+	if ctrl.nfsexportQueue.Len() != 0 {
+		t.Errorf("expected no nfsexport to be enqueued, got %d", ctrl.nfsexportQueue.Len())
+	}
+
+	ready := bound.DeepCopy()
+	ready.Status = &crdv1.VolumeNfsExportContentStatus{ReadyToUse: &True}
+	ctrl.enqueueContentStatusChange(bound, ready)
+	unboundReady := unbound.DeepCopy()
+	unboundReady.Status = &crdv1.VolumeNfsExportContentStatus{ReadyToUse: &True}
+	ctrl.enqueueContentStatusChange(unbound, unboundReady)
+	if ctrl.nfsexportQueue.Len() != 1 {
+		t.Fatalf("expected 1 nfsexport to be enqueued, got %d", ctrl.nfsexportQueue.Len())
+	}
+	if key, _ := ctrl.nfsexportQueue.Get(); key != "default/snap1" {
+		t.Errorf("expected default/snap1 to be enqueued, got %v", key)
+	}
+}
+
+func TestEnqueueClaimNfsExports(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, nfsexport := range []*crdv1.VolumeNfsExport{
+		newNfsExport("snap1", "snapuid1", "claim1", "", classGold, "", &True, nil, nil, nil, false, true, nil),
+		newNfsExport("snap2", "snapuid2", "claim2", "", classGold, "", &True, nil, nil, nil, false, true, nil),
+		newNfsExport("snap3", "snapuid3", "", "content3", classGold, "", &True, nil, nil, nil, false, true, nil),
+	} {
+		if err := indexer.Add(nfsexport); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctrl := &csiNfsExportCommonController{
+		nfsexportLister:    storagelisters.NewVolumeNfsExportLister(indexer),
+		nfsexportQueue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+		nfsexportQueueWait: newQueueWaitTracker(clocktesting.NewFakeClock(time.Now())),
+	}
+	defer ctrl.nfsexportQueue.ShutDown()
+
+	claim := newClaim("claim1", "pvc-uid1", "1Gi", "volume1", v1.ClaimBound, &classEmpty, false)
+	ctrl.enqueueClaimNfsExports(cache.DeletedFinalStateUnknown{Key: "default/claim1", Obj: claim})
+	if ctrl.nfsexportQueue.Len() != 1 {
+		t.Fatalf("expected 1 nfsexport to be enqueued, got %d", ctrl.nfsexportQueue.Len())
+	}
+	if key, _ := ctrl.nfsexportQueue.Get(); key != "default/snap1" {
+		t.Errorf("expected default/snap1 to be enqueued, got %v", key)
+	}
+}
+
+func TestEnqueueRestoreSourceNfsExport(t *testing.T) {
+	deleting := newNfsExport("snap1", "snapuid1", "claim1", "", classGold, "content1", &True, nil, nil, nil, false, true, &timeNowMetav1)
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, nfsexport := range []*crdv1.VolumeNfsExport{
+		deleting,
+		newNfsExport("snap2", "snapuid2", "claim1", "", classGold, "content2", &True, nil, nil, nil, false, true, nil),
+	} {
+		if err := indexer.Add(nfsexport); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctrl := &csiNfsExportCommonController{
+		nfsexportLister:    storagelisters.NewVolumeNfsExportLister(indexer),
+		nfsexportQueue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+		nfsexportQueueWait: newQueueWaitTracker(clocktesting.NewFakeClock(time.Now())),
+	}
+	defer ctrl.nfsexportQueue.ShutDown()
+
+	restoring := func(nfsexportName string, phase v1.PersistentVolumeClaimPhase) *v1.PersistentVolumeClaim {
+		return withClaimRestoredFrom(newClaimArray("restore1", "pvc-uid9", "1Gi", "", phase, &classEmpty), nfsexportName, nil)[0]
+	}
+	// Updates that leave the claim Pending, claims that were not Pending and
+	// nfsexports that are not being deleted are ignored.
+	ctrl.enqueueRestoreSourceNfsExport(restoring("snap1", v1.ClaimPending), restoring("snap1", v1.ClaimPending))
+	ctrl.enqueueRestoreSourceNfsExport(restoring("snap1", v1.ClaimBound), restoring("snap1", v1.ClaimLost))
+	ctrl.enqueueRestoreSourceNfsExport(restoring("snap2", v1.ClaimPending), restoring("snap2", v1.ClaimBound))
+	ctrl.enqueueRestoreSourceNfsExport(restoring("missing", v1.ClaimPending), nil)
+	if ctrl.nfsexportQueue.Len() != 0 {
+		t.Fatalf("expected no nfsexport to be enqueued, got %d", ctrl.nfsexportQueue.Len())
+	}
+
+	ctrl.enqueueRestoreSourceNfsExport(restoring("snap1", v1.ClaimPending), restoring("snap1", v1.ClaimBound))
+	if ctrl.nfsexportQueue.Len() != 1 {
+		t.Fatalf("expected 1 nfsexport to be enqueued, got %d", ctrl.nfsexportQueue.Len())
+	}
+	key, _ := ctrl.nfsexportQueue.Get()
+	if key != "default/snap1" {
+		t.Errorf("expected default/snap1 to be enqueued, got %v", key)
+	}
+	ctrl.nfsexportQueue.Done(key)
+
+	// A claim deleted while Pending no longer blocks the deletion either.
+	ctrl.enqueueRestoreSourceNfsExport(cache.DeletedFinalStateUnknown{Key: "default/restore1", Obj: restoring("snap1", v1.ClaimPending)}, nil)
+	if ctrl.nfsexportQueue.Len() != 1 {
+		t.Fatalf("expected 1 nfsexport to be enqueued, got %d", ctrl.nfsexportQueue.Len())
+	}
+}
+
+func TestQueueWaitTracker(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	tracker := newQueueWaitTracker(clock)
+
+	tracker.enqueued("default/snap1")
+	clock.Step(time.Second)
+	// A second event for a queued key keeps the first enqueue time.
+	tracker.enqueued("default/snap1")
+	clock.Step(time.Second)
+	tracker.dequeued("default/snap1")
+
+	if waited, ok := tracker.take("default/snap1"); !ok || waited != 2*time.Second {
+		t.Errorf("expected queue wait of 2s, got %v (found: %v)", waited, ok)
+	}
+	if _, ok := tracker.take("default/snap1"); ok {
+		t.Errorf("expected queue wait to be forgotten after take")
+	}
+
+	// Keys re-added by the rate limiter were not enqueued by an event.
+	tracker.dequeued("default/snap2")
+	if _, ok := tracker.take("default/snap2"); ok {
+		t.Errorf("expected no queue wait for a key that was not enqueued")
+	}
+
+	tracker.enqueued("default/snap3")
+	tracker.forget("default/snap3")
+	tracker.dequeued("default/snap3")
+	if _, ok := tracker.take("default/snap3"); ok {
+		t.Errorf("expected no queue wait for a forgotten key")
+	}
+
+	// A sync that returns before taking the wait must not leak it.
+	tracker.enqueued("default/snap4")
+	tracker.dequeued("default/snap4")
+	tracker.done("default/snap4")
+	if _, ok := tracker.take("default/snap4"); ok {
+		t.Errorf("expected queue wait to be dropped once the key is done")
+	}
+	if len(tracker.enqueue) != 0 || len(tracker.waited) != 0 {
+		t.Errorf("expected no tracked keys, got %d enqueued and %d waited", len(tracker.enqueue), len(tracker.waited))
+	}
+}
+
+func TestWorkersBackOffWhenThrottled(t *testing.T) {
+	clock := clocktesting.NewFakeClock(time.Now())
+	throttle := utils.NewAPIServerThrottle(10*time.Millisecond, time.Second, clock)
+	ctrl := &csiNfsExportCommonController{
+		nfsexportQueue:     workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+		contentQueue:       workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+		nfsexportQueueWait: newQueueWaitTracker(clock),
+	}
+	defer ctrl.nfsexportQueue.ShutDown()
+	defer ctrl.contentQueue.ShutDown()
+	ctrl.SetAPIServerThrottle(throttle)
+	throttle.Throttled(0)
+
+	// The controller has no listers, the workers would panic if they synced
+	// the keys instead of queueing them again after the backoff.
+	ctrl.nfsexportQueue.Add("default/snap1")
+	ctrl.contentQueue.Add("content1")
+	ctrl.nfsexportWorker()
+	ctrl.contentWorker()
+	if ctrl.nfsexportQueue.Len() != 0 || ctrl.contentQueue.Len() != 0 {
+		t.Errorf("expected the keys to wait for the backoff, got %d nfsexports and %d contents queued", ctrl.nfsexportQueue.Len(), ctrl.contentQueue.Len())
+	}
+	if key, _ := ctrl.nfsexportQueue.Get(); key != "default/snap1" {
+		t.Errorf("expected default/snap1 to be queued again after the backoff, got %v", key)
+	}
+	if key, _ := ctrl.contentQueue.Get(); key != "content1" {
+		t.Errorf("expected content1 to be queued again after the backoff, got %v", key)
+	}
+}
+
+func TestGetVolumeFromVolumeNfsExportPVCache(t *testing.T) {
+	pvcName := "claim1"
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"},
+		Spec: crdv1.VolumeNfsExportSpec{
+			Source: crdv1.VolumeNfsExportSource{PersistentVolumeClaimName: &pvcName},
+		},
+	}
+	pvc := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: pvcName, Namespace: "default", UID: "pvc-uid1"},
+		Spec:       v1.PersistentVolumeClaimSpec{VolumeName: "volume1"},
+		Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+	}
+	boundPV := func(handle string) *v1.PersistentVolume {
+		return &v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "volume1"},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeSource: v1.PersistentVolumeSource{
+					CSI: &v1.CSIPersistentVolumeSource{Driver: mockDriverName, VolumeHandle: handle},
+				},
+				ClaimRef: &v1.ObjectReference{Name: pvcName, Namespace: "default", UID: "pvc-uid1"},
+			},
+		}
+	}
+	strippedPV := &v1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "volume1"}}
+
+	tests := []struct {
+		name           string
+		cachedPV       *v1.PersistentVolume
+		apiPV          *v1.PersistentVolume
+		expectedHandle string
+		expectErr      bool
+	}{
+		{
+			name:           "PV found in cache",
+			cachedPV:       boundPV("cached-handle"),
+			expectedHandle: "cached-handle",
+		},
+		{
+			name:           "stripped PV in cache falls back to the API server",
+			cachedPV:       strippedPV,
+			apiPV:          boundPV("api-handle"),
+			expectedHandle: "api-handle",
+		},
+		{
+			name:           "PV missing from cache falls back to the API server",
+			apiPV:          boundPV("api-handle"),
+			expectedHandle: "api-handle",
+		},
+		{
+			name:      "PV missing from cache and API server",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		pvcIndexer.Add(pvc)
+		pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+		if test.cachedPV != nil {
+			pvIndexer.Add(test.cachedPV)
+		}
+		kubeClient := fake.NewSimpleClientset()
+		if test.apiPV != nil {
+			kubeClient = fake.NewSimpleClientset(test.apiPV)
+		}
+		ctrl := &csiNfsExportCommonController{
+			client:    kubeClient,
+			pvcLister: corelisters.NewPersistentVolumeClaimLister(pvcIndexer),
+			pvLister:  corelisters.NewPersistentVolumeLister(pvIndexer),
+		}
+
+		pv, err := ctrl.getVolumeFromVolumeNfsExport(nfsexport)
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got none", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if pv.Spec.CSI.VolumeHandle != test.expectedHandle {
+			t.Errorf("%s: expected volume handle %s, got %s", test.name, test.expectedHandle, pv.Spec.CSI.VolumeHandle)
+		}
+	}
+}
+
+func TestGetSourceVolumeHandles(t *testing.T) {
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	pvIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, claim := range []*v1.PersistentVolumeClaim{
+		newClaim("claim2", "pvc-uid2", "1Gi", "volume2", v1.ClaimBound, &classEmpty, false),
+		newClaim("claim3", "pvc-uid3", "1Gi", "volume3", v1.ClaimBound, &classEmpty, false),
+		newClaim("pending", "pvc-uid4", "1Gi", "", v1.ClaimPending, &classEmpty, false),
+		newClaim("other-driver", "pvc-uid5", "1Gi", "volume5", v1.ClaimBound, &classEmpty, false),
+	} {
+		pvcIndexer.Add(claim)
+	}
+	otherDriver := newVolume("volume5", "pv-uid5", "pv-handle5", "1Gi", "pvc-uid5", "other-driver", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty)
+	otherDriver.Spec.CSI.Driver = "other.csi.k8s.io"
+	for _, volume := range []*v1.PersistentVolume{
+		newVolume("volume2", "pv-uid2", "pv-handle2", "1Gi", "pvc-uid2", "claim2", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
+		newVolume("volume3", "pv-uid3", "pv-handle3", "1Gi", "pvc-uid3", "claim3", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
+		otherDriver,
+	} {
+		pvIndexer.Add(volume)
+	}
+	ctrl := &csiNfsExportCommonController{
+		client:    fake.NewSimpleClientset(),
+		pvcLister: corelisters.NewPersistentVolumeClaimLister(pvcIndexer),
+		pvLister:  corelisters.NewPersistentVolumeLister(pvIndexer),
+	}
+	class := &crdv1.VolumeNfsExportClass{ObjectMeta: metav1.ObjectMeta{Name: classGold}, Driver: mockDriverName}
+
+	tests := []struct {
+		name            string
+		sources         []string
+		expectedHandles []string
+		expectErr       bool
+	}{
+		{
+			name: "single source",
+		},
+		{
+			name:            "further sources",
+			sources:         []string{"claim3", "claim2"},
+			expectedHandles: []string{"pv-handle3", "pv-handle2"},
+		},
+		{
+			name:      "missing source",
+			sources:   []string{"claim2", "missing"},
+			expectErr: true,
+		},
+		{
+			name:      "unbound source",
+			sources:   []string{"pending"},
+			expectErr: true,
+		},
+		{
+			name:      "source of another driver",
+			sources:   []string{"other-driver"},
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		nfsexport := newNfsExport("snap1", "snapuid1", "claim1", "", classGold, "", nil, nil, nil, nil, false, true, nil)
+		nfsexport.Spec.Sources = test.sources
+		handles, err := ctrl.getSourceVolumeHandles(nfsexport, class)
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got none", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(handles, test.expectedHandles) {
+			t.Errorf("%s: expected volume handles %v, got %v", test.name, test.expectedHandles, handles)
+		}
+	}
+}
+
+func TestGetNfsExportDriverName(t *testing.T) {
+	className := "class1"
+	classIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	classIndexer.Add(&crdv1.VolumeNfsExportClass{ObjectMeta: metav1.ObjectMeta{Name: className}, Driver: "class.csi.k8s.io"})
+
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default", UID: "snapuid1"},
+		Spec:       crdv1.VolumeNfsExportSpec{VolumeNfsExportClassName: &className},
+	}
+	boundContent := func(uid types.UID, labels map[string]string) *crdv1.VolumeNfsExportContent {
+		return &crdv1.VolumeNfsExportContent{
+			ObjectMeta: metav1.ObjectMeta{Name: "content-" + string(uid), Labels: labels},
+			Spec: crdv1.VolumeNfsExportContentSpec{
+				VolumeNfsExportRef: v1.ObjectReference{Namespace: "default", Name: "snap1", UID: uid},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		contents []*crdv1.VolumeNfsExportContent
+		expected string
+	}{
+		{
+			name:     "driver label of the bound content",
+			contents: []*crdv1.VolumeNfsExportContent{boundContent("snapuid1", map[string]string{utils.VolumeNfsExportContentDriverLabel: "label.csi.k8s.io"})},
+			expected: "label.csi.k8s.io",
+		},
+		{
+			name:     "unlabeled bound content falls back to the class",
+			contents: []*crdv1.VolumeNfsExportContent{boundContent("snapuid1", nil)},
+			expected: "class.csi.k8s.io",
+		},
+		{
+			name:     "content of a previous nfsexport of the same name is ignored",
+			contents: []*crdv1.VolumeNfsExportContent{boundContent("snapuid0", map[string]string{utils.VolumeNfsExportContentDriverLabel: "label.csi.k8s.io"})},
+			expected: "class.csi.k8s.io",
+		},
+		{
+			name:     "no content falls back to the class",
+			expected: "class.csi.k8s.io",
+		},
+	}
+
+	for _, test := range tests {
+		contentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{contentNfsExportIndex: contentNfsExportIndexFunc})
+		for _, content := range test.contents {
+			contentIndexer.Add(content)
+		}
+		ctrl := &csiNfsExportCommonController{
+			contentIndexer: contentIndexer,
+			contentLister:  storagelisters.NewVolumeNfsExportContentLister(contentIndexer),
+			classLister:    storagelisters.NewVolumeNfsExportClassLister(classIndexer),
+		}
+
+		driverName, err := ctrl.getNfsExportDriverName(nfsexport)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if driverName != test.expected {
+			t.Errorf("%s: expected driver %q, got %q", test.name, test.expected, driverName)
+		}
+	}
+}
+
+func TestCheckDuplicateNfsExportHandle(t *testing.T) {
+	handleContent := func(name, driver, handle string) *crdv1.VolumeNfsExportContent {
+		return &crdv1.VolumeNfsExportContent{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: crdv1.VolumeNfsExportContentSpec{
+				Driver: driver,
+				Source: crdv1.VolumeNfsExportContentSource{NfsExportHandle: &handle},
+			},
+		}
+	}
+	content := handleContent("content1", mockDriverName, "handle1")
+
+	tests := []struct {
+		name        string
+		contents    []*crdv1.VolumeNfsExportContent
+		expectEvent string
+	}{
+		{
+			name:     "unique handle",
+			contents: []*crdv1.VolumeNfsExportContent{handleContent("content2", mockDriverName, "handle2")},
+		},
+		{
+			name:     "same handle of another driver",
+			contents: []*crdv1.VolumeNfsExportContent{handleContent("content2", "other.csi.k8s.io", "handle1")},
+		},
+		{
+			name: "same handle of the same driver",
+			contents: []*crdv1.VolumeNfsExportContent{
+				handleContent("content3", mockDriverName, "handle1"),
+				handleContent("content2", mockDriverName, "handle1"),
+			},
+			expectEvent: "Warning DuplicateNfsExportHandle NfsExportHandle handle1 of driver " + mockDriverName + " is also used by VolumeNfsExportContent content2, content3",
+		},
+	}
+
+	for _, test := range tests {
+		contentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{utils.ContentHandleIndex: utils.ContentHandleIndexFunc})
+		contentIndexer.Add(content)
+		for _, other := range test.contents {
+			contentIndexer.Add(other)
+		}
+		client := clientsetfake.NewSimpleClientset(content)
+		addApplyContentStatusReactor(client)
+		recorder := record.NewFakeRecorder(10)
+		ctrl := &csiNfsExportCommonController{
+			statusClientset: client,
+			contentStore:    cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+			contentIndexer:  contentIndexer,
+			eventRecorder:   recorder,
+			clock:           clocktesting.NewFakeClock(time.Now()),
+		}
+
+		// The event is emitted once, when the condition turns True.
+		updated := content
+		for i := 0; i < 2; i++ {
+			var err error
+			if updated, err = ctrl.checkDuplicateNfsExportHandle(updated); err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.name, err)
+			}
+		}
+		var event string
+		select {
+		case event = <-recorder.Events:
+		default:
+		}
+		if event != test.expectEvent {
+			t.Errorf("%s: expected event %q, got %q", test.name, test.expectEvent, event)
+		}
+		if len(recorder.Events) != 0 {
+			t.Errorf("%s: expected a single event, got %d more", test.name, len(recorder.Events))
+		}
+		var cond *metav1.Condition
+		if updated.Status != nil {
+			cond = meta.FindStatusCondition(updated.Status.Conditions, crdv1.ConditionDuplicateNfsExportHandle)
+		}
+		if test.expectEvent == "" {
+			if cond != nil {
+				t.Errorf("%s: expected no DuplicateNfsExportHandle condition, got %+v", test.name, cond)
+			}
+			continue
+		}
+		if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != crdv1.DuplicateNfsExportHandleReasonShared {
+			t.Fatalf("%s: expected a True DuplicateNfsExportHandle condition, got %+v", test.name, cond)
+		}
+
+		// The condition turns False once the other contents are gone.
+		for _, other := range test.contents {
+			contentIndexer.Delete(other)
+		}
+		if updated, err := ctrl.checkDuplicateNfsExportHandle(updated); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		} else if cond = meta.FindStatusCondition(updated.Status.Conditions, crdv1.ConditionDuplicateNfsExportHandle); cond == nil || cond.Status != metav1.ConditionFalse {
+			t.Errorf("%s: expected a False DuplicateNfsExportHandle condition, got %+v", test.name, cond)
+		}
+	}
+}
+
+func TestCheckAndSetContentDriverLabel(t *testing.T) {
+	tests := []struct {
+		name         string
+		driver       string
+		labels       map[string]string
+		expectLabel  string
+		expectUpdate bool
+	}{
+		{
+			name:         "unlabeled content is labeled",
+			driver:       mockDriverName,
+			expectLabel:  mockDriverName,
+			expectUpdate: true,
+		},
+		{
+			name:        "labeled content is left untouched",
+			driver:      mockDriverName,
+			labels:      map[string]string{utils.VolumeNfsExportContentDriverLabel: mockDriverName},
+			expectLabel: mockDriverName,
+		},
+		{
+			name:         "label of another driver is fixed",
+			driver:       mockDriverName,
+			labels:       map[string]string{utils.VolumeNfsExportContentDriverLabel: "other.csi.k8s.io"},
+			expectLabel:  mockDriverName,
+			expectUpdate: true,
+		},
+		{
+			name:   "driver name is not a valid label value",
+			driver: "invalid/driver",
+		},
+	}
+
+	for _, test := range tests {
+		content := &crdv1.VolumeNfsExportContent{
+			ObjectMeta: metav1.ObjectMeta{Name: "content1", Labels: test.labels},
+			Spec:       crdv1.VolumeNfsExportContentSpec{Driver: test.driver},
+		}
+		client := clientsetfake.NewSimpleClientset(content)
+		ctrl := &csiNfsExportCommonController{
+			clientset:    client,
+			contentStore: cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+		}
+
+		if _, err := ctrl.checkAndSetContentDriverLabel(content); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		updated, err := client.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), "content1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if label := updated.Labels[utils.VolumeNfsExportContentDriverLabel]; label != test.expectLabel {
+			t.Errorf("%s: expected driver label %q, got %q", test.name, test.expectLabel, label)
+		}
+		updates := 0
+		for _, action := range client.Actions() {
+			if action.GetVerb() == "update" {
+				updates++
+			}
+		}
+		if test.expectUpdate != (updates > 0) {
+			t.Errorf("%s: expected update %v, got %d updates", test.name, test.expectUpdate, updates)
+		}
+	}
+}
+
+func TestCheckAndSetInvalidNfsExportLabel(t *testing.T) {
+	emptyClass := ""
+	invalid := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"},
+		Spec:       crdv1.VolumeNfsExportSpec{VolumeNfsExportClassName: &emptyClass},
+	}
+
+	tests := []struct {
+		name                string
+		labelInvalidObjects bool
+		expectLabel         bool
+	}{
+		{
+			name:                "invalid nfsexport is labeled",
+			labelInvalidObjects: true,
+			expectLabel:         true,
+		},
+		{
+			name:                "labeling disabled",
+			labelInvalidObjects: false,
+			expectLabel:         false,
+		},
+	}
+
+	for _, test := range tests {
+		client := clientsetfake.NewSimpleClientset(invalid)
+		ctrl := &csiNfsExportCommonController{
+			clientset:           client,
+			nfsexportStore:      cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+			labelInvalidObjects: test.labelInvalidObjects,
+			metricsManager:      metrics.NewMetricsManager(),
+		}
+
+		if _, err := ctrl.checkAndSetInvalidNfsExportLabel(invalid); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		updated, err := client.NfsExportV1().VolumeNfsExports("default").Get(context.TODO(), "snap1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if hasLabel := utils.MapContainsKey(updated.Labels, utils.VolumeNfsExportInvalidLabel); hasLabel != test.expectLabel {
+			t.Errorf("%s: expected invalid label %v, got %v", test.name, test.expectLabel, hasLabel)
+		}
+		for _, action := range client.Actions() {
+			if !test.labelInvalidObjects && action.GetVerb() == "update" {
+				t.Errorf("%s: expected no update when labeling is disabled, got %v", test.name, action)
+			}
+		}
+	}
+}
+
+func TestInvalidLabelToggleLimit(t *testing.T) {
+	emptyClass := ""
+	invalid := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default", UID: "uid1"},
+		Spec:       crdv1.VolumeNfsExportSpec{VolumeNfsExportClassName: &emptyClass},
+	}
+	gvr := crdv1.SchemeGroupVersion.WithResource("volumenfsexports")
+	client := clientsetfake.NewSimpleClientset(invalid)
+	client.PrependReactor("patch", "volumenfsexports", func(action core.Action) (bool, runtime.Object, error) {
+		patch := action.(core.PatchAction)
+		stored, err := client.Tracker().Get(gvr, patch.GetNamespace(), patch.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		storedBytes, err := json.Marshal(stored)
+		if err != nil {
+			return true, nil, err
+		}
+		modified, err := fakeapiserver.ApplyStatus(patch, storedBytes, utils.CommonControllerFieldManager)
+		if err != nil {
+			return true, nil, err
+		}
+		nfsexport := &crdv1.VolumeNfsExport{}
+		if err := json.Unmarshal(modified, nfsexport); err != nil {
+			return true, nil, err
+		}
+		return true, nfsexport, client.Tracker().Update(gvr, nfsexport, patch.GetNamespace())
+	})
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	recorder := record.NewFakeRecorder(10)
+	ctrl := &csiNfsExportCommonController{
+		clientset:           client,
+		statusClientset:     client,
+		nfsexportStore:      cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+		labelInvalidObjects: true,
+		invalidLabelToggles: newLabelToggleLimiter(2, invalidLabelToggleWindow, fakeClock),
+		metricsManager:      metrics.NewMetricsManager(),
+		eventRecorder:       recorder,
+		clock:               fakeClock,
+	}
+	// removeLabel simulates another writer removing the label that the
+	// controller has just added.
+	removeLabel := func(nfsexport *crdv1.VolumeNfsExport) *crdv1.VolumeNfsExport {
+		nfsexport = nfsexport.DeepCopy()
+		delete(nfsexport.Labels, utils.VolumeNfsExportInvalidLabel)
+		updated, err := client.NfsExportV1().VolumeNfsExports("default").Update(context.TODO(), nfsexport, metav1.UpdateOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return updated
+	}
+
+	nfsexport := invalid
+	for i := 0; i < 2; i++ {
+		labeled, err := ctrl.checkAndSetInvalidNfsExportLabel(nfsexport)
+		if err != nil {
+			t.Fatalf("toggle %d: unexpected error: %v", i, err)
+		}
+		if !utils.MapContainsKey(labeled.Labels, utils.VolumeNfsExportInvalidLabel) {
+			t.Fatalf("toggle %d: expected the nfsexport to be labeled", i)
+		}
+		nfsexport = removeLabel(labeled)
+	}
+
+	client.ClearActions()
+	for i := 0; i < 2; i++ {
+		var err error
+		nfsexport, err = ctrl.checkAndSetInvalidNfsExportLabel(nfsexport)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if utils.MapContainsKey(nfsexport.Labels, utils.VolumeNfsExportInvalidLabel) {
+		t.Errorf("expected the label not to be rewritten beyond the toggle limit")
+	}
+	cond := meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionInvalidFlapping)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != crdv1.InvalidFlappingReasonToggleLimitReached {
+		t.Errorf("expected a True InvalidFlapping condition, got %+v", cond)
+	}
+	var patches int
+	for _, action := range client.Actions() {
+		switch action.GetVerb() {
+		case "update":
+			t.Errorf("expected no update beyond the toggle limit, got %v", action)
+		case "patch":
+			patches++
+		}
+	}
+	if patches != 1 {
+		t.Errorf("expected the InvalidFlapping condition to be written once, got %d patches", patches)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "InvalidLabelFlapping") {
+			t.Errorf("expected an InvalidLabelFlapping event, got %q", event)
+		}
+	default:
+		t.Errorf("expected an InvalidLabelFlapping event")
+	}
+
+	// The condition stays while the object keeps flapping, and turns False
+	// once the label is stable and the toggles aged out.
+	labeled := nfsexport.DeepCopy()
+	labeled.Labels = map[string]string{utils.VolumeNfsExportInvalidLabel: ""}
+	if nfsexport, _ = ctrl.checkAndSetInvalidNfsExportLabel(labeled); !isNfsExportInvalidFlapping(nfsexport) {
+		t.Errorf("expected the InvalidFlapping condition to stay True within the window")
+	}
+	fakeClock.Step(invalidLabelToggleWindow)
+	nfsexport, err := ctrl.checkAndSetInvalidNfsExportLabel(labeled)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cond = meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionInvalidFlapping)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != crdv1.InvalidFlappingReasonStable {
+		t.Errorf("expected a False InvalidFlapping condition, got %+v", cond)
+	}
+}
+
+func TestContentInvalidLabelToggleLimit(t *testing.T) {
+	// A content without a VolumeNfsExportRef is invalid.
+	invalid := &crdv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "content1", UID: "uid1"},
+	}
+	client := clientsetfake.NewSimpleClientset(invalid)
+	addApplyContentStatusReactor(client)
+	fakeClock := clocktesting.NewFakeClock(time.Now())
+	recorder := record.NewFakeRecorder(10)
+	ctrl := &csiNfsExportCommonController{
+		clientset:           client,
+		statusClientset:     client,
+		contentStore:        cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+		labelInvalidObjects: true,
+		invalidLabelToggles: newLabelToggleLimiter(2, invalidLabelToggleWindow, fakeClock),
+		metricsManager:      metrics.NewMetricsManager(),
+		eventRecorder:       recorder,
+		clock:               fakeClock,
+	}
+	// removeLabel simulates another writer removing the label that the
+	// controller has just added.
+	removeLabel := func(content *crdv1.VolumeNfsExportContent) *crdv1.VolumeNfsExportContent {
+		content = content.DeepCopy()
+		delete(content.Labels, utils.VolumeNfsExportContentInvalidLabel)
+		updated, err := client.NfsExportV1().VolumeNfsExportContents().Update(context.TODO(), content, metav1.UpdateOptions{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return updated
+	}
+
+	content := invalid
+	for i := 0; i < 2; i++ {
+		labeled, err := ctrl.checkAndSetInvalidContentLabel(content)
+		if err != nil {
+			t.Fatalf("toggle %d: unexpected error: %v", i, err)
+		}
+		if !utils.MapContainsKey(labeled.Labels, utils.VolumeNfsExportContentInvalidLabel) {
+			t.Fatalf("toggle %d: expected the content to be labeled", i)
+		}
+		content = removeLabel(labeled)
+	}
+
+	client.ClearActions()
+	for i := 0; i < 3; i++ {
+		var err error
+		content, err = ctrl.checkAndSetInvalidContentLabel(content)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if utils.MapContainsKey(content.Labels, utils.VolumeNfsExportContentInvalidLabel) {
+		t.Errorf("expected the label not to be rewritten beyond the toggle limit")
+	}
+	if content.Status == nil {
+		t.Fatalf("expected the content to have a status")
+	}
+	cond := meta.FindStatusCondition(content.Status.Conditions, crdv1.ConditionInvalidFlapping)
+	if cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != crdv1.InvalidFlappingReasonToggleLimitReached {
+		t.Errorf("expected a True InvalidFlapping condition, got %+v", cond)
+	}
+	var patches int
+	for _, action := range client.Actions() {
+		switch action.GetVerb() {
+		case "update":
+			t.Errorf("expected no update beyond the toggle limit, got %v", action)
+		case "patch":
+			patches++
+		}
+	}
+	if patches != 1 {
+		t.Errorf("expected the InvalidFlapping condition to be written once, got %d patches", patches)
+	}
+	if events := len(recorder.Events); events != 1 {
+		t.Errorf("expected one InvalidLabelFlapping event, got %d", events)
+	} else if event := <-recorder.Events; !strings.Contains(event, "InvalidLabelFlapping") {
+		t.Errorf("expected an InvalidLabelFlapping event, got %q", event)
+	}
+
+	// The condition turns False once the label is stable and the toggles
+	// aged out.
+	labeled := content.DeepCopy()
+	labeled.Labels = map[string]string{utils.VolumeNfsExportContentInvalidLabel: ""}
+	fakeClock.Step(invalidLabelToggleWindow)
+	content, err := ctrl.checkAndSetInvalidContentLabel(labeled)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cond = meta.FindStatusCondition(content.Status.Conditions, crdv1.ConditionInvalidFlapping)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != crdv1.InvalidFlappingReasonStable {
+		t.Errorf("expected a False InvalidFlapping condition, got %+v", cond)
+	}
+}
+
+func TestGetExportSecurityContext(t *testing.T) {
+	claimName := "claim1"
+	newPod := func(namespace string, uid int64) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: namespace},
+			Spec: v1.PodSpec{
+				SecurityContext: &v1.PodSecurityContext{RunAsUser: &uid},
+				Volumes: []v1.Volume{{
+					Name: "data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+					},
+				}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"},
+		Spec: crdv1.VolumeNfsExportSpec{
+			Source: crdv1.VolumeNfsExportSource{PersistentVolumeClaimName: &claimName},
+		},
+	}
+
+	ctrl := &csiNfsExportCommonController{
+		podIndexer:    newPodIndexer(newPod("default", 1000), newPod("other", 2000)),
+		eventRecorder: record.NewFakeRecorder(10),
+	}
+	annotations, err := ctrl.getExportSecurityContext(nfsexport)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := map[string]string{utils.AnnExportAnonUID: "1000"}; !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("expected annotations %v, got %v", expected, annotations)
+	}
+
+	ctrl.podIndexer = nil
+	if _, err := ctrl.getExportSecurityContext(nfsexport); err != errPodInformerDisabled {
+		t.Errorf("expected %v without the pod informer, got %v", errPodInformerDisabled, err)
+	}
+}
+
+func TestQuiesceSourcePods(t *testing.T) {
+	claimName := "claim1"
+	pointInTime := crdv1.VolumeNfsExportModePointInTime
+	newPod := func(name string, annotations map[string]string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec: v1.PodSpec{
+				Volumes: []v1.Volume{{
+					Name: "data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+					},
+				}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	applicationConsistent := map[string]string{
+		utils.PrefixedExportConsistencyKey:    utils.ExportConsistencyApplication,
+		utils.PrefixedExportQuiesceTimeoutKey: "30s",
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	acked := map[string]string{utils.AnnQuiesceRequest: "snapuid1", utils.AnnQuiesceAck: "snapuid1"}
+
+	tests := []struct {
+		name              string
+		params            map[string]string
+		requestedAt       string
+		pods              []*v1.Pod
+		expectConsistency string
+		expectQuiesced    bool
+		expectRequested   []string
+		expectRequestedAt bool
+		expectBlocked     string
+	}{
+		{
+			name:           "crash consistent class",
+			params:         map[string]string{},
+			pods:           []*v1.Pod{newPod("pod1", nil)},
+			expectQuiesced: true,
+		},
+		{
+			name:              "no pod uses the claim",
+			params:            applicationConsistent,
+			expectConsistency: utils.ExportConsistencyApplication,
+			expectQuiesced:    true,
+		},
+		{
+			name:              "pods are asked to quiesce",
+			params:            applicationConsistent,
+			pods:              []*v1.Pod{newPod("pod1", nil), newPod("pod2", acked)},
+			expectRequested:   []string{"pod1", "pod2"},
+			expectRequestedAt: true,
+			expectBlocked:     "Waiting for pods pod1 to acknowledge the quiesce request before creating the export",
+		},
+		{
+			name:              "all pods acknowledged",
+			params:            applicationConsistent,
+			requestedAt:       now.Add(-10 * time.Second).Format(time.RFC3339),
+			pods:              []*v1.Pod{newPod("pod1", acked)},
+			expectConsistency: utils.ExportConsistencyApplication,
+			expectQuiesced:    true,
+			expectRequested:   []string{"pod1"},
+			expectRequestedAt: true,
+		},
+		{
+			name:              "quiesce timed out",
+			params:            applicationConsistent,
+			requestedAt:       now.Add(-time.Minute).Format(time.RFC3339),
+			pods:              []*v1.Pod{newPod("pod1", acked), newPod("pod2", nil)},
+			expectConsistency: utils.ExportConsistencyCrash,
+			expectQuiesced:    true,
+			expectRequested:   []string{"pod1", "pod2"},
+			expectRequestedAt: true,
+		},
+	}
+
+	for _, test := range tests {
+		nfsexport := &crdv1.VolumeNfsExport{
+			ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default", UID: "snapuid1"},
+			Spec: crdv1.VolumeNfsExportSpec{
+				Source: crdv1.VolumeNfsExportSource{PersistentVolumeClaimName: &claimName},
+				Mode:   &pointInTime,
+			},
+		}
+		if test.requestedAt != "" {
+			metav1.SetMetaDataAnnotation(&nfsexport.ObjectMeta, utils.AnnQuiesceRequestedAt, test.requestedAt)
+		}
+		var objs []runtime.Object
+		for _, pod := range test.pods {
+			objs = append(objs, pod)
+		}
+		kubeClient := fake.NewSimpleClientset(objs...)
+		client := clientsetfake.NewSimpleClientset(nfsexport)
+		addApplyStatusReactor(client)
+		ctrl := &csiNfsExportCommonController{
+			client:          kubeClient,
+			clientset:       client,
+			statusClientset: client,
+			nfsexportStore:  cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+			nfsexportQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+			podIndexer:      newPodIndexer(test.pods...),
+			eventRecorder:   record.NewFakeRecorder(10),
+			clock:           clocktesting.NewFakeClock(now),
+		}
+		class := &crdv1.VolumeNfsExportClass{Parameters: test.params}
+
+		consistency, quiesced, err := ctrl.quiesceSourcePods(nfsexport, class)
+		ctrl.nfsexportQueue.ShutDown()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if consistency != test.expectConsistency || quiesced != test.expectQuiesced {
+			t.Errorf("%s: expected %q and quiesced %v, got %q and %v", test.name, test.expectConsistency, test.expectQuiesced, consistency, quiesced)
+		}
+		var requested []string
+		for _, pod := range test.pods {
+			updated, err := kubeClient.CoreV1().Pods("default").Get(context.TODO(), pod.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", test.name, err)
+			}
+			if updated.Annotations[utils.AnnQuiesceRequest] == "snapuid1" {
+				requested = append(requested, pod.Name)
+			}
+		}
+		if !reflect.DeepEqual(requested, test.expectRequested) {
+			t.Errorf("%s: expected pods %v to be asked to quiesce, got %v", test.name, test.expectRequested, requested)
+		}
+		updated, err := client.NfsExportV1().VolumeNfsExports("default").Get(context.TODO(), "snap1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if hasRequestedAt := metav1.HasAnnotation(updated.ObjectMeta, utils.AnnQuiesceRequestedAt); hasRequestedAt != test.expectRequestedAt {
+			t.Errorf("%s: expected annotation %s %v, got %v", test.name, utils.AnnQuiesceRequestedAt, test.expectRequestedAt, hasRequestedAt)
+		}
+		checkBlockedCondition(t, test.name, updated, crdv1.BlockedReasonWaitingForQuiesce, test.expectBlocked)
+	}
+}
+
+func TestCheckConsistencyGate(t *testing.T) {
+	claimName := "claim1"
+	newPod := func(name string, readOnly bool) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.PodSpec{
+				Volumes: []v1.Volume{{
+					Name: "data",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+					},
+				}},
+				Containers: []v1.Container{{
+					Name:         "app",
+					VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/data", ReadOnly: readOnly}},
+				}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	noWriters := map[string]string{
+		utils.PrefixedExportConsistencyGateKey:        utils.ExportConsistencyGateNoWriters,
+		utils.PrefixedExportConsistencyGateTimeoutKey: "5m",
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		params        map[string]string
+		waitingSince  string
+		pods          []*v1.Pod
+		expectOpen    bool
+		expectError   bool
+		expectWaiting bool
+		expectEvent   string
+		expectBlocked string
+	}{
+		{
+			name:       "no gate",
+			params:     map[string]string{},
+			pods:       []*v1.Pod{newPod("writer", false)},
+			expectOpen: true,
+		},
+		{
+			name:       "only readers",
+			params:     noWriters,
+			pods:       []*v1.Pod{newPod("reader", true)},
+			expectOpen: true,
+		},
+		{
+			name:          "start waiting for writers",
+			params:        noWriters,
+			pods:          []*v1.Pod{newPod("reader", true), newPod("writer", false)},
+			expectWaiting: true,
+			expectEvent:   "Normal WaitingForWriters Waiting for pods writer to stop writing to PVC claim1 before creating the export",
+			expectBlocked: "Waiting for pods writer to stop writing to PVC claim1 before creating the export",
+		},
+		{
+			name:          "still waiting for writers",
+			params:        noWriters,
+			waitingSince:  now.Add(-time.Minute).Format(time.RFC3339),
+			pods:          []*v1.Pod{newPod("writer", false)},
+			expectWaiting: true,
+			expectBlocked: "Waiting for pods writer to stop writing to PVC claim1 before creating the export",
+		},
+		{
+			name:         "writers stopped",
+			params:       noWriters,
+			waitingSince: now.Add(-time.Minute).Format(time.RFC3339),
+			pods:         []*v1.Pod{newPod("reader", true)},
+			expectOpen:   true,
+			expectEvent:  "Normal ConsistencyGateOpened No pod writes to PVC claim1 anymore, creating the export",
+		},
+		{
+			name:          "gate timed out",
+			params:        noWriters,
+			waitingSince:  now.Add(-5 * time.Minute).Format(time.RFC3339),
+			pods:          []*v1.Pod{newPod("writer", false)},
+			expectError:   true,
+			expectWaiting: true,
+			expectEvent:   "Warning ConsistencyGateTimedOut Pods writer still mount PVC claim1 read-write after 5m0s, the export is not created until they stop writing",
+		},
+	}
+
+	for _, test := range tests {
+		nfsexport := &crdv1.VolumeNfsExport{
+			ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default", UID: "snapuid1"},
+			Spec: crdv1.VolumeNfsExportSpec{
+				Source: crdv1.VolumeNfsExportSource{PersistentVolumeClaimName: &claimName},
+			},
+		}
+		if test.waitingSince != "" {
+			metav1.SetMetaDataAnnotation(&nfsexport.ObjectMeta, utils.AnnConsistencyGateWaitingSince, test.waitingSince)
+		}
+		client := clientsetfake.NewSimpleClientset(nfsexport)
+		addApplyStatusReactor(client)
+		recorder := record.NewFakeRecorder(10)
+		ctrl := &csiNfsExportCommonController{
+			client:          fake.NewSimpleClientset(),
+			clientset:       client,
+			statusClientset: client,
+			nfsexportStore:  cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+			nfsexportQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+			podIndexer:      newPodIndexer(test.pods...),
+			eventRecorder:   recorder,
+			clock:           clocktesting.NewFakeClock(now),
+		}
+		class := &crdv1.VolumeNfsExportClass{Parameters: test.params}
+
+		open, err := ctrl.checkConsistencyGate(nfsexport, class)
+		ctrl.nfsexportQueue.ShutDown()
+		if (err != nil) != test.expectError {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.expectError, err)
+		}
+		if open != test.expectOpen {
+			t.Errorf("%s: expected open %v, got %v", test.name, test.expectOpen, open)
+		}
+		var event string
+		select {
+		case event = <-recorder.Events:
+		default:
+		}
+		if event != test.expectEvent {
+			t.Errorf("%s: expected event %q, got %q", test.name, test.expectEvent, event)
+		}
+		updated, err := client.NfsExportV1().VolumeNfsExports("default").Get(context.TODO(), "snap1", metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if waiting := metav1.HasAnnotation(updated.ObjectMeta, utils.AnnConsistencyGateWaitingSince); waiting != test.expectWaiting {
+			t.Errorf("%s: expected annotation %s %v, got %v", test.name, utils.AnnConsistencyGateWaitingSince, test.expectWaiting, waiting)
+		}
+		checkBlockedCondition(t, test.name, updated, crdv1.BlockedReasonWaitingForWriters, test.expectBlocked)
+	}
+}
+
+// addApplyStatusReactor makes client handle the server-side apply of the
+// status of VolumeNfsExports, which the fake clientset does not support.
+func addApplyStatusReactor(client *clientsetfake.Clientset) {
+	gvr := crdv1.SchemeGroupVersion.WithResource("volumenfsexports")
+	client.PrependReactor("patch", "volumenfsexports", func(action core.Action) (bool, runtime.Object, error) {
+		patch := action.(core.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		stored, err := client.Tracker().Get(gvr, patch.GetNamespace(), patch.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		storedBytes, err := json.Marshal(stored)
+		if err != nil {
+			return true, nil, err
+		}
+		modified, err := fakeapiserver.ApplyStatus(patch, storedBytes, utils.CommonControllerFieldManager)
+		if err != nil {
+			return true, nil, err
+		}
+		nfsexport := &crdv1.VolumeNfsExport{}
+		if err := json.Unmarshal(modified, nfsexport); err != nil {
+			return true, nil, err
+		}
+		return true, nfsexport, client.Tracker().Update(gvr, nfsexport, patch.GetNamespace())
+	})
+}
+
+// newPodIndexer returns a pod indexer with the indexes of the controller,
+// filled with pods.
+func newPodIndexer(pods ...*v1.Pod) cache.Indexer {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{
+		utils.PodClaimIndex:          utils.PodClaimIndexFunc,
+		utils.PodQuiesceRequestIndex: utils.PodQuiesceRequestIndexFunc,
+	})
+	for _, pod := range pods {
+		indexer.Add(pod)
+	}
+	return indexer
+}
+
+// addApplyContentStatusReactor makes client handle the server-side apply of
+// the status of VolumeNfsExportContents by the common controller.
+func addApplyContentStatusReactor(client *clientsetfake.Clientset) {
+	gvr := crdv1.SchemeGroupVersion.WithResource("volumenfsexportcontents")
+	client.PrependReactor("patch", "volumenfsexportcontents", func(action core.Action) (bool, runtime.Object, error) {
+		patch := action.(core.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		stored, err := client.Tracker().Get(gvr, "", patch.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		storedBytes, err := json.Marshal(stored)
+		if err != nil {
+			return true, nil, err
+		}
+		modified, err := fakeapiserver.ApplyStatus(patch, storedBytes, utils.CommonControllerFieldManager)
+		if err != nil {
+			return true, nil, err
+		}
+		content := &crdv1.VolumeNfsExportContent{}
+		if err := json.Unmarshal(modified, content); err != nil {
+			return true, nil, err
+		}
+		return true, content, client.Tracker().Update(gvr, content, "")
+	})
+}
+
+// checkBlockedCondition checks that nfsexport is blocked with reason and
+// message, or is not blocked if message is empty.
+func checkBlockedCondition(t *testing.T, name string, nfsexport *crdv1.VolumeNfsExport, reason, message string) {
+	var cond *metav1.Condition
+	if nfsexport.Status != nil {
+		cond = meta.FindStatusCondition(nfsexport.Status.Conditions, crdv1.ConditionBlocked)
+	}
+	switch {
+	case message == "" && cond != nil:
+		t.Errorf("%s: expected no Blocked condition, got %+v", name, cond)
+	case message != "" && (cond == nil || cond.Status != metav1.ConditionTrue || cond.Reason != reason || cond.Message != message):
+		t.Errorf("%s: expected Blocked condition %s: %q, got %+v", name, reason, message, cond)
+	}
+}
+
+func TestCheckandReleaseSourcePods(t *testing.T) {
+	claimName := "claim1"
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "snap1",
+			Namespace:   "default",
+			UID:         "snapuid1",
+			Annotations: map[string]string{utils.AnnQuiesceRequestedAt: "2026-01-01T00:00:00Z"},
+		},
+		Spec:   crdv1.VolumeNfsExportSpec{Source: crdv1.VolumeNfsExportSource{PersistentVolumeClaimName: &claimName}},
+		Status: &crdv1.VolumeNfsExportStatus{CreationTime: &metav1.Time{}},
+	}
+	quiesced := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "pod1",
+		Namespace:   "default",
+		Annotations: map[string]string{utils.AnnQuiesceRequest: "snapuid1", utils.AnnQuiesceAck: "snapuid1"},
+	}}
+	other := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "pod2",
+		Namespace:   "default",
+		Annotations: map[string]string{utils.AnnQuiesceRequest: "snapuid2"},
+	}}
+	kubeClient := fake.NewSimpleClientset(quiesced, other)
+	client := clientsetfake.NewSimpleClientset(nfsexport)
+	ctrl := &csiNfsExportCommonController{
+		client:         kubeClient,
+		clientset:      client,
+		nfsexportStore: cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
+		podIndexer:     newPodIndexer(quiesced, other),
+	}
+
+	if err := ctrl.checkandReleaseSourcePods(nfsexport); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pod, err := kubeClient.CoreV1().Pods("default").Get(context.TODO(), "pod1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pod.Annotations) != 0 {
+		t.Errorf("expected the quiesce annotations of pod1 to be removed, got %v", pod.Annotations)
+	}
+	pod, err = kubeClient.CoreV1().Pods("default").Get(context.TODO(), "pod2", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pod.Annotations[utils.AnnQuiesceRequest] != "snapuid2" {
+		t.Errorf("expected the quiesce request of another nfsexport to be kept, got %v", pod.Annotations)
+	}
+	updated, err := client.NfsExportV1().VolumeNfsExports("default").Get(context.TODO(), "snap1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if metav1.HasAnnotation(updated.ObjectMeta, utils.AnnQuiesceRequestedAt) {
+		t.Errorf("expected annotation %s to be removed", utils.AnnQuiesceRequestedAt)
+	}
+}
@@ -22,6 +22,8 @@ import (
 	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/fakeapiserver"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -43,6 +45,16 @@ var (
 	retainPolicy       = crdv1.VolumeNfsExportContentRetain
 )
 
+func blockedCondition(status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               crdv1.ConditionBlocked,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(timeNow),
+	}
+}
+
 // Test single call to SyncNfsExport, expecting create nfsexport to happen.
 // 1. Fill in the controller with initial data
 // 2. Call the SyncNfsExport *once*.
@@ -52,7 +64,7 @@ func TestCreateNfsExportSync(t *testing.T) {
 		{
 			name:              "6-1 - successful create nfsexport with nfsexport class gold",
 			initialContents:   nocontents,
-			expectedContents:  newContentArrayNoStatus("snapcontent-snapuid6-1", "snapuid6-1", "snap6-1", "sid6-1", classGold, "", "pv-handle6-1", deletionPolicy, nil, nil, false, false),
+			expectedContents:  withContentDriverLabel(withSourceVolume(newContentArrayNoStatus("snapcontent-snapuid6-1", "snapuid6-1", "snap6-1", "sid6-1", classGold, "", "pv-handle6-1", deletionPolicy, nil, nil, false, false), "volume6-1", v1.PersistentVolumeReclaimDelete)),
 			initialNfsExports:  newNfsExportArray("snap6-1", "snapuid6-1", "claim6-1", "", classGold, "", &False, nil, nil, nil, false, true, nil),
 			expectedNfsExports: newNfsExportArray("snap6-1", "snapuid6-1", "claim6-1", "", classGold, "snapcontent-snapuid6-1", &False, nil, nil, nil, false, true, nil),
 			initialClaims:     newClaimArray("claim6-1", "pvc-uid6-1", "1Gi", "volume6-1", v1.ClaimBound, &classGold),
@@ -63,11 +75,11 @@ func TestCreateNfsExportSync(t *testing.T) {
 		{
 			name:            "6-2 - successful create nfsexport with validSecretClass and initial secret",
 			initialContents: nocontents,
-			expectedContents: withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid6-2", "snapuid6-2", "snap6-2", "sid6-2", validSecretClass, "", "pv-handle6-2", deletionPolicy, nil, nil, false, false),
+			expectedContents: withContentDriverLabel(withSourceVolume(withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid6-2", "snapuid6-2", "snap6-2", "sid6-2", validSecretClass, "", "pv-handle6-2", deletionPolicy, nil, nil, false, false),
 				map[string]string{
 					"nfsexport.storage.kubernetes.io/deletion-secret-name":      "secret",
 					"nfsexport.storage.kubernetes.io/deletion-secret-namespace": "default",
-				}),
+				}), "volume6-2", v1.PersistentVolumeReclaimDelete)),
 			initialNfsExports:  newNfsExportArray("snap6-2", "snapuid6-2", "claim6-2", "", validSecretClass, "", &False, nil, nil, nil, false, true, nil),
 			expectedNfsExports: newNfsExportArray("snap6-2", "snapuid6-2", "claim6-2", "", validSecretClass, "snapcontent-snapuid6-2", &False, nil, nil, nil, false, true, nil),
 			initialClaims:     newClaimArray("claim6-2", "pvc-uid6-2", "1Gi", "volume6-2", v1.ClaimBound, &classEmpty),
@@ -76,12 +88,64 @@ func TestCreateNfsExportSync(t *testing.T) {
 			errors:            noerrors,
 			test:              testSyncNfsExport,
 		},
+		{
+			name:            "6-3 - successful create nfsexport records the annotations propagated by the class",
+			initialContents: nocontents,
+			expectedContents: withContentDriverLabel(withSourceVolume(withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid6-3", "snapuid6-3", "snap6-3", "sid6-3", propagateClass, "", "pv-handle6-3", deletionPolicy, nil, nil, false, false),
+				map[string]string{
+					utils.AnnExportPropagatedMetadata: `{"csi.storage.k8s.io/volumenfsexport/annotation/example.com/ticket":"OPS-42"}`,
+				}), "volume6-3", v1.PersistentVolumeReclaimDelete)),
+			initialNfsExports:  withNfsExportAnnotations(newNfsExportArray("snap6-3", "snapuid6-3", "claim6-3", "", propagateClass, "", &False, nil, nil, nil, false, true, nil), map[string]string{"example.com/ticket": "OPS-42", "owner": "team-a"}),
+			expectedNfsExports: withNfsExportAnnotations(newNfsExportArray("snap6-3", "snapuid6-3", "claim6-3", "", propagateClass, "snapcontent-snapuid6-3", &False, nil, nil, nil, false, true, nil), map[string]string{"example.com/ticket": "OPS-42", "owner": "team-a"}),
+			initialClaims:     newClaimArray("claim6-3", "pvc-uid6-3", "1Gi", "volume6-3", v1.ClaimBound, &classEmpty),
+			initialVolumes:    newVolumeArray("volume6-3", "pv-uid6-3", "pv-handle6-3", "1Gi", "pvc-uid6-3", "claim6-3", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			name:             "6-4 - successful create nfsexport resolves the Blocked condition",
+			initialContents:  nocontents,
+			expectedContents: withContentDriverLabel(withSourceVolume(newContentArrayNoStatus("snapcontent-snapuid6-4", "snapuid6-4", "snap6-4", "sid6-4", classGold, "", "pv-handle6-4", deletionPolicy, nil, nil, false, false), "volume6-4", v1.PersistentVolumeReclaimDelete)),
+			initialNfsExports: withNfsExportConditions(newNfsExportArray("snap6-4", "snapuid6-4", "claim6-4", "", classGold, "", &False, nil, nil, nil, false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonSourcePVCNotBound, "the PVC claim6-4 is not yet bound to a PV, will not attempt to take a nfsexport")),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap6-4", "snapuid6-4", "claim6-4", "", classGold, "snapcontent-snapuid6-4", &False, nil, nil, nil, false, true, nil),
+				blockedCondition(metav1.ConditionFalse, crdv1.BlockedReasonResolved, "The nfsexport controller is acting on the nfsexport")),
+			initialClaims:  newClaimArray("claim6-4", "pvc-uid6-4", "1Gi", "volume6-4", v1.ClaimBound, &classGold),
+			initialVolumes: newVolumeArray("volume6-4", "pv-uid6-4", "pv-handle6-4", "1Gi", "pvc-uid6-4", "claim6-4", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classGold),
+			errors:         noerrors,
+			test:           testSyncNfsExport,
+		},
+		{
+			name:             "6-5 - nfsexport blocked on its class is resolved once the class exists",
+			initialContents:  nocontents,
+			expectedContents: withContentDriverLabel(withSourceVolume(newContentArrayNoStatus("snapcontent-snapuid6-5", "snapuid6-5", "snap6-5", "sid6-5", classGold, "", "pv-handle6-5", deletionPolicy, nil, nil, false, false), "volume6-5", v1.PersistentVolumeReclaimDelete)),
+			initialNfsExports: withNfsExportConditions(newNfsExportArray("snap6-5", "snapuid6-5", "claim6-5", "", classGold, "", &False, nil, nil, nil, false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonClassNotFound, "VolumeNfsExportClass gold does not exist")),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap6-5", "snapuid6-5", "claim6-5", "", classGold, "snapcontent-snapuid6-5", &False, nil, nil, nil, false, true, nil),
+				blockedCondition(metav1.ConditionFalse, crdv1.BlockedReasonResolved, "The nfsexport controller is acting on the nfsexport")),
+			initialClaims:  newClaimArray("claim6-5", "pvc-uid6-5", "1Gi", "volume6-5", v1.ClaimBound, &classGold),
+			initialVolumes: newVolumeArray("volume6-5", "pv-uid6-5", "pv-handle6-5", "1Gi", "pvc-uid6-5", "claim6-5", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classGold),
+			errors:         noerrors,
+			test:           testSyncNfsExportByKey,
+		},
+		{
+			name:               "6-6 - successful create nfsexport copies the requester of the nfsexport",
+			initialContents:    nocontents,
+			expectedContents:   withContentDriverLabel(withSourceVolume(withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid6-6", "snapuid6-6", "snap6-6", "sid6-6", classGold, "", "pv-handle6-6", deletionPolicy, nil, nil, false, false), map[string]string{utils.AnnRequestedBy: "developer"}), "volume6-6", v1.PersistentVolumeReclaimDelete)),
+			initialNfsExports:  withNfsExportAnnotations(newNfsExportArray("snap6-6", "snapuid6-6", "claim6-6", "", classGold, "", &False, nil, nil, nil, false, true, nil), map[string]string{utils.AnnRequestedBy: "developer"}),
+			expectedNfsExports: withNfsExportAnnotations(newNfsExportArray("snap6-6", "snapuid6-6", "claim6-6", "", classGold, "snapcontent-snapuid6-6", &False, nil, nil, nil, false, true, nil), map[string]string{utils.AnnRequestedBy: "developer"}),
+			initialClaims:      newClaimArray("claim6-6", "pvc-uid6-6", "1Gi", "volume6-6", v1.ClaimBound, &classGold),
+			initialVolumes:     newVolumeArray("volume6-6", "pv-uid6-6", "pv-handle6-6", "1Gi", "pvc-uid6-6", "claim6-6", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classGold),
+			errors:             noerrors,
+			test:               testSyncNfsExport,
+		},
 		{
 			name:              "7-1 - fail to create nfsexport with non-existing nfsexport class",
 			initialContents:   nocontents,
 			expectedContents:  nocontents,
 			initialNfsExports:  newNfsExportArray("snap7-1", "snapuid7-1", "claim7-1", "", classNonExisting, "", &False, nil, nil, nil, false, true, nil),
-			expectedNfsExports: newNfsExportArray("snap7-1", "snapuid7-1", "claim7-1", "", classNonExisting, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error failed to get input parameters to create nfsexport snap7-1: \"volumenfsexportclass.nfsexport.storage.k8s.io \\\"non-existing\\\" not found\""), false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap7-1", "snapuid7-1", "claim7-1", "", classNonExisting, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error failed to get input parameters to create nfsexport snap7-1: \"volumenfsexportclass.nfsexport.storage.k8s.io \\\"non-existing\\\" not found\""), false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonClassNotFound, "VolumeNfsExportClass non-existing does not exist")),
 			initialClaims:     newClaimArray("claim7-1", "pvc-uid7-1", "1Gi", "volume7-1", v1.ClaimBound, &classEmpty),
 			initialVolumes:    newVolumeArray("volume7-1", "pv-uid7-1", "pv-handle7-1", "1Gi", "pvc-uid7-1", "claim7-1", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
 			expectedEvents:    []string{"Warning NfsExportContentCreationFailed"},
@@ -132,7 +196,8 @@ func TestCreateNfsExportSync(t *testing.T) {
 			initialContents:   nocontents,
 			expectedContents:  nocontents,
 			initialNfsExports:  newNfsExportArray("snap7-6", "snapuid7-6", "claim7-6", "", classGold, "", &False, nil, nil, nil, false, true, nil),
-			expectedNfsExports: newNfsExportArray("snap7-6", "snapuid7-6", "claim7-6", "", classGold, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error failed to get input parameters to create nfsexport snap7-6: \"the PVC claim7-6 is not yet bound to a PV, will not attempt to take a nfsexport\""), false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap7-6", "snapuid7-6", "claim7-6", "", classGold, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error failed to get input parameters to create nfsexport snap7-6: \"the PVC claim7-6 is not yet bound to a PV, will not attempt to take a nfsexport\""), false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonSourcePVCNotBound, "the PVC claim7-6 is not yet bound to a PV, will not attempt to take a nfsexport")),
 			initialClaims:     newClaimArray("claim7-6", "pvc-uid7-6", "1Gi", "", v1.ClaimPending, &classGold),
 			expectedEvents:    []string{"Warning NfsExportContentCreationFailed"},
 			errors:            noerrors,
@@ -148,10 +213,10 @@ func TestCreateNfsExportSync(t *testing.T) {
 			expectedNfsExports: newNfsExportArray("snap7-7", "snapuid7-7", "claim7-7", "", classGold, "snapcontent-snapuid7-7", &True, nil, nil, nil, false, true, nil),
 			initialClaims:     newClaimArrayFinalizer("claim7-7", "pvc-uid7-7", "1Gi", "volume7-7", v1.ClaimBound, &classGold),
 			initialVolumes:    newVolumeArray("volume7-7", "pv-uid7-7", "pv-handle7-7", "1Gi", "pvc-uid7-7", "claim7-7", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classGold),
-			errors: []reactorError{
-				{"update", "persistentvolumeclaims", errors.New("mock update error")},
-				{"update", "persistentvolumeclaims", errors.New("mock update error")},
-				{"update", "persistentvolumeclaims", errors.New("mock update error")},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbUpdate, fakeapiserver.ResourcePersistentVolumeClaims, errors.New("mock update error")),
+				fakeapiserver.Error(fakeapiserver.VerbUpdate, fakeapiserver.ResourcePersistentVolumeClaims, errors.New("mock update error")),
+				fakeapiserver.Error(fakeapiserver.VerbUpdate, fakeapiserver.ResourcePersistentVolumeClaims, errors.New("mock update error")),
 			},
 			expectSuccess: false,
 			test:          testSyncNfsExport,
@@ -159,16 +224,16 @@ func TestCreateNfsExportSync(t *testing.T) {
 		{
 			name:              "7-9 - fail create nfsexport due to cannot update nfsexport status, and failure cannot be recorded either due to additional status update failure.",
 			initialContents:   nocontents,
-			expectedContents:  newContentArrayNoStatus("snapcontent-snapuid7-9", "snapuid7-9", "snap7-9", "sid7-9", classGold, "", "pv-handle7-9", deletionPolicy, nil, nil, false, false),
+			expectedContents:  withContentDriverLabel(withSourceVolume(newContentArrayNoStatus("snapcontent-snapuid7-9", "snapuid7-9", "snap7-9", "sid7-9", classGold, "", "pv-handle7-9", deletionPolicy, nil, nil, false, false), "volume7-9", v1.PersistentVolumeReclaimDelete)),
 			initialNfsExports:  newNfsExportArray("snap7-9", "snapuid7-9", "claim7-9", "", classGold, "", &False, nil, nil, nil, false, true, nil),
 			expectedNfsExports: newNfsExportArray("snap7-9", "snapuid7-9", "claim7-9", "", classGold, "", &False, nil, nil, nil, false, true, nil),
 			initialClaims:     newClaimArray("claim7-9", "pvc-uid7-9", "1Gi", "volume7-9", v1.ClaimBound, &classGold),
 			initialVolumes:    newVolumeArray("volume7-9", "pv-uid7-9", "pv-handle7-9", "1Gi", "pvc-uid7-9", "claim7-9", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classGold),
-			errors: []reactorError{
-				{"update", "volumenfsexports", errors.New("mock update error")},
-				{"update", "volumenfsexports", errors.New("mock update error")},
-				{"update", "volumenfsexports", errors.New("mock update error")},
-				{"update", "volumenfsexports", errors.New("mock update error")},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportsStatus, errors.New("mock update error")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportsStatus, errors.New("mock update error")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportsStatus, errors.New("mock update error")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportsStatus, errors.New("mock update error")),
 			},
 			expectSuccess: false,
 			test:          testSyncNfsExport,
@@ -192,14 +257,65 @@ func TestCreateNfsExportSync(t *testing.T) {
 			expectedNfsExports: newNfsExportArray("snap7-11", "snapuid7-11", "claim7-11", "", classGold, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error nfsexport controller failed to update default/snap7-11 on API server: mock create error"), false, true, nil),
 			initialClaims:     newClaimArray("claim7-11", "pvc-uid7-11", "1Gi", "volume7-11", v1.ClaimBound, &classGold),
 			initialVolumes:    newVolumeArray("volume7-11", "pv-uid7-11", "pv-handle7-11", "1Gi", "pvc-uid7-11", "claim7-11", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classGold),
-			errors: []reactorError{
-				{"create", "volumenfsexportcontents", errors.New("mock create error")},
-				{"create", "volumenfsexportcontents", errors.New("mock create error")},
-				{"create", "volumenfsexportcontents", errors.New("mock create error")},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbCreate, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock create error")),
+				fakeapiserver.Error(fakeapiserver.VerbCreate, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock create error")),
+				fakeapiserver.Error(fakeapiserver.VerbCreate, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock create error")),
 			},
 			expectedEvents: []string{"Warning CreateNfsExportContentFailed"},
 			test:           testSyncNfsExport,
 		},
+		{
+			name:              "7-12 - fail to create pre-provisioned nfsexport with non-existing content",
+			initialContents:   nocontents,
+			expectedContents:  nocontents,
+			initialNfsExports:  newNfsExportArray("snap7-12", "snapuid7-12", "", "content7-12", classGold, "", &False, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap7-12", "snapuid7-12", "", "content7-12", classGold, "", &False, nil, nil, newVolumeError("VolumeNfsExportContent is missing"), false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonContentNotFound, "VolumeNfsExportContent content7-12 does not exist")),
+			expectedEvents: []string{"Warning NfsExportContentMissing"},
+			errors:         noerrors,
+			expectSuccess:  false,
+			test:           testSyncNfsExport,
+		},
+		{
+			name:              "7-13 - fail to create dynamic nfsexport in content-only mode",
+			initialContents:   nocontents,
+			expectedContents:  nocontents,
+			initialNfsExports:  newNfsExportArray("snap7-13", "snapuid7-13", "claim7-13", "", classGold, "", &False, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap7-13", "snapuid7-13", "claim7-13", "", classGold, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error "+errDynamicProvisioningDisabled.Error()), false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonDynamicProvisioningDisabled, errDynamicProvisioningDisabled.Error())),
+			expectedEvents: []string{"Warning NfsExportContentCreationFailed"},
+			errors:         noerrors,
+			expectSuccess:  false,
+			test:           testSyncNfsExportContentOnly,
+		},
+		{
+			name:             "7-14 - nfsexport whose class was deleted before content creation is not synced",
+			initialContents:  nocontents,
+			expectedContents: nocontents,
+			initialNfsExports: withNfsExportConditions(newNfsExportArray("snap7-14", "snapuid7-14", "claim7-14", "", classNonExisting, "", &False, nil, nil, newVolumeError("class deleted"), false, true, nil),
+				classDeletedCondition("class deleted")),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap7-14", "snapuid7-14", "claim7-14", "", classNonExisting, "", &False, nil, nil, newVolumeError("class deleted"), false, true, nil),
+				classDeletedCondition("class deleted")),
+			initialClaims: newClaimArray("claim7-14", "pvc-uid7-14", "1Gi", "volume7-14", v1.ClaimBound, &classEmpty),
+			errors:        noerrors,
+			expectSuccess: true,
+			test:          testSyncNfsExport,
+		},
+		{
+			name:              "7-15 - nfsexport with non-existing nfsexport class is blocked by the worker",
+			initialContents:   nocontents,
+			expectedContents:  nocontents,
+			initialNfsExports: newNfsExportArray("snap7-15", "snapuid7-15", "claim7-15", "", classNonExisting, "", &False, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap7-15", "snapuid7-15", "claim7-15", "", classNonExisting, "", &False, nil, nil, newVolumeError("Failed to get nfsexport class with error volumenfsexportclass.nfsexport.storage.k8s.io \"non-existing\" not found"), false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonClassNotFound, "VolumeNfsExportClass non-existing does not exist")),
+			initialClaims:  newClaimArray("claim7-15", "pvc-uid7-15", "1Gi", "volume7-15", v1.ClaimBound, &classEmpty),
+			initialVolumes: newVolumeArray("volume7-15", "pv-uid7-15", "pv-handle7-15", "1Gi", "pvc-uid7-15", "claim7-15", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
+			expectedEvents: []string{"Warning GetNfsExportClassFailed"},
+			errors:         noerrors,
+			expectSuccess:  false,
+			test:           testSyncNfsExportByKey,
+		},
 	}
 	runSyncTests(t, tests, nfsexportClasses)
 }
@@ -17,13 +17,21 @@ limitations under the License.
 package common_controller
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/fakeapiserver"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 var class1Parameters = map[string]string{
@@ -49,6 +57,10 @@ var class5Parameters = map[string]string{
 	utils.PrefixedNfsExportterSecretNamespaceKey: "default",
 }
 
+var class6Parameters = map[string]string{
+	utils.PrefixedExportPropagatedAnnotationsKey: "example.com/ticket",
+}
+
 var timeNowMetav1 = metav1.Now()
 
 var (
@@ -112,6 +124,17 @@ var nfsexportClasses = []*crdv1.VolumeNfsExportClass{
 		Parameters:     class5Parameters,
 		DeletionPolicy: crdv1.VolumeNfsExportContentDelete,
 	},
+	{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "VolumeNfsExportClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: propagateClass,
+		},
+		Driver:         mockDriverName,
+		Parameters:     class6Parameters,
+		DeletionPolicy: crdv1.VolumeNfsExportContentDelete,
+	},
 	{
 		TypeMeta: metav1.TypeMeta{
 			Kind: "VolumeNfsExportClass",
@@ -134,7 +157,7 @@ func TestDeleteSync(t *testing.T) {
 		{
 			name:              "1-1 - noop: content will not be deleted if it is bound to a nfsexport correctly, nfsexport uid is not specified",
 			initialContents:   newContentArray("content1-1", "", "snap1-1", "snaphandle1-1", validSecretClass, "snaphandle1-1", "", deletePolicy, nil, nil, true),
-			expectedContents:  newContentArray("content1-1", "", "snap1-1", "snaphandle1-1", validSecretClass, "snaphandle1-1", "", deletePolicy, nil, nil, true),
+			expectedContents:  withContentDriverLabel(newContentArray("content1-1", "", "snap1-1", "snaphandle1-1", validSecretClass, "snaphandle1-1", "", deletePolicy, nil, nil, true)),
 			initialNfsExports:  newNfsExportArray("snap1-1", "snapuid1-1", "claim1-1", "", validSecretClass, "content1-1", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedNfsExports: newNfsExportArray("snap1-1", "snapuid1-1", "claim1-1", "", validSecretClass, "content1-1", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedEvents:    noevents,
@@ -162,7 +185,7 @@ func TestDeleteSync(t *testing.T) {
 		{
 			name:              "1-3 - will not delete content with retain policy set which is bound to a nfsexport incorrectly",
 			initialContents:   newContentArray("content1-3", "snapuid1-3-x", "snap1-3", "snaphandle1-3", validSecretClass, "snaphandle1-3", "", retainPolicy, nil, nil, true),
-			expectedContents:  newContentArray("content1-3", "snapuid1-3-x", "snap1-3", "snaphandle1-3", validSecretClass, "snaphandle1-3", "", retainPolicy, nil, nil, true),
+			expectedContents:  withContentDriverLabel(newContentArray("content1-3", "snapuid1-3-x", "snap1-3", "snaphandle1-3", validSecretClass, "snaphandle1-3", "", retainPolicy, nil, nil, true)),
 			initialNfsExports:  newNfsExportArray("snap1-3", "snapuid1-3", "claim1-3", "", validSecretClass, "content1-3", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedNfsExports: newNfsExportArray("snap1-3", "snapuid1-3", "claim1-3", "", validSecretClass, "content1-3", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedEvents:    noevents,
@@ -196,10 +219,10 @@ func TestDeleteSync(t *testing.T) {
 			initialClaims:     newClaimArray("claim3-2", "pvc-uid3-2", "1Gi", "volume3-2", v1.ClaimBound, &classEmpty),
 			expectedEvents:    []string{"Warning NfsExportContentObjectDeleteError"},
 			initialSecrets:    []*v1.Secret{secret()},
-			errors: []reactorError{
+			errors: []fakeapiserver.Hook{
 				// Inject error to the first client.VolumenfsexportV1().VolumeNfsExportContents().Delete call.
 				// All other calls will succeed.
-				{"delete", "volumenfsexportcontents", errors.New("mock delete error")},
+				fakeapiserver.Error(fakeapiserver.VerbDelete, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock delete error")),
 			},
 			expectSuccess: false,
 			test:          testSyncNfsExportError,
@@ -279,10 +302,10 @@ func TestDeleteSync(t *testing.T) {
 			expectedNfsExports: newNfsExportArray("snap3-8", "snapuid3-8", "", "content-3-8", validSecretClass, "content-3-8", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedEvents:    []string{"Warning NfsExportContentObjectDeleteError"},
 			initialSecrets:    []*v1.Secret{secret()},
-			errors: []reactorError{
+			errors: []fakeapiserver.Hook{
 				// Inject error to the first client.VolumenfsexportV1().VolumeNfsExportContents().Delete call.
 				// All other calls will succeed.
-				{"delete", "volumenfsexportcontents", errors.New("mock delete error")},
+				fakeapiserver.Error(fakeapiserver.VerbDelete, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock delete error")),
 			},
 			expectSuccess: false,
 			test:          testSyncNfsExportError,
@@ -334,6 +357,154 @@ func TestDeleteSync(t *testing.T) {
 			errors:            noerrors,
 			test:              testSyncNfsExport,
 		},
+		{
+			name:              "3-13 - (dynamic) nfsexport finalizer removal is retried after a conflict",
+			initialContents:   nocontents,
+			expectedContents:  nocontents,
+			initialNfsExports:  newNfsExportArray("snap3-13", "snapuid3-13", "claim3-13", "", validSecretClass, "", &False, nil, nil, nil, false, true, &timeNowMetav1),
+			expectedNfsExports: newNfsExportArray("snap3-13", "snapuid3-13", "claim3-13", "", validSecretClass, "", &False, nil, nil, nil, false, false, &timeNowMetav1),
+			initialClaims:     newClaimArray("claim3-13", "pvc-uid3-13", "1Gi", "volume3-13", v1.ClaimBound, &classEmpty),
+			expectedEvents:    noevents,
+			initialSecrets:    []*v1.Secret{secret()},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExports, conflictError("volumenfsexports", "snap3-13")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExports, conflictError("volumenfsexports", "snap3-13")),
+			},
+			test: testSyncNfsExport,
+		},
+		{
+			name:              "3-14 - (dynamic) nfsexport finalizer removal gives up after repeated conflicts",
+			initialContents:   nocontents,
+			expectedContents:  nocontents,
+			initialNfsExports:  newNfsExportArray("snap3-14", "snapuid3-14", "claim3-14", "", validSecretClass, "", &False, nil, nil, nil, false, true, &timeNowMetav1),
+			expectedNfsExports: newNfsExportArray("snap3-14", "snapuid3-14", "claim3-14", "", validSecretClass, "", &False, nil, nil, nil, false, true, &timeNowMetav1),
+			initialClaims:     newClaimArray("claim3-14", "pvc-uid3-14", "1Gi", "volume3-14", v1.ClaimBound, &classEmpty),
+			expectedEvents:    noevents,
+			initialSecrets:    []*v1.Secret{secret()},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExports, conflictError("volumenfsexports", "snap3-14")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExports, conflictError("volumenfsexports", "snap3-14")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExports, conflictError("volumenfsexports", "snap3-14")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExports, conflictError("volumenfsexports", "snap3-14")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExports, conflictError("volumenfsexports", "snap3-14")),
+			},
+			test: testSyncNfsExportError,
+		},
+		{
+			// the bound finalizer is removed concurrently, the test operation of the
+			// patch fails and the retry removes the remaining finalizer only
+			name:              "3-15 - (dynamic) nfsexport finalizer removal is retried after the finalizers changed",
+			initialContents:   nocontents,
+			expectedContents:  nocontents,
+			initialNfsExports:  newNfsExportArray("snap3-15", "snapuid3-15", "claim3-15", "", validSecretClass, "", &False, nil, nil, nil, false, true, &timeNowMetav1),
+			expectedNfsExports: newNfsExportArray("snap3-15", "snapuid3-15", "claim3-15", "", validSecretClass, "", &False, nil, nil, nil, false, false, &timeNowMetav1),
+			initialClaims:     newClaimArray("claim3-15", "pvc-uid3-15", "1Gi", "volume3-15", v1.ClaimBound, &classEmpty),
+			expectedEvents:    noevents,
+			initialSecrets:    []*v1.Secret{secret()},
+			errors:            noerrors,
+			test: wrapTestWithInjectedOperation(testSyncNfsExport, func(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor) {
+				nfsexport := reactor.nfsexports["snap3-15"].DeepCopy()
+				nfsexport.ObjectMeta.Finalizers = []string{utils.VolumeNfsExportBoundFinalizer}
+				reactor.nfsexports["snap3-15"] = nfsexport
+			}),
+		},
+		{
+			name:              "3-16 - (static) nfsexport used to restore a PVC is not deleted and reports the Blocked condition",
+			initialContents:   newContentArray("content-3-16", "snapuid3-16", "snap3-16", "sid3-16", validSecretClass, "sid3-16", "", deletePolicy, nil, nil, true),
+			expectedContents:  newContentArray("content-3-16", "snapuid3-16", "snap3-16", "sid3-16", validSecretClass, "sid3-16", "", deletePolicy, nil, nil, true),
+			initialNfsExports: newNfsExportArray("snap3-16", "snapuid3-16", "", "content-3-16", validSecretClass, "content-3-16", &False, nil, nil, nil, false, true, &timeNowMetav1),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap3-16", "snapuid3-16", "", "content-3-16", validSecretClass, "content-3-16", &False, nil, nil, nil, false, true, &timeNowMetav1),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonRestoreInProgress, "Deletion waits for PVC claim3-16 being restored from the nfsexport")),
+			initialClaims:  withClaimRestoredFrom(newClaimArray("claim3-16", "pvc-uid3-16", "1Gi", "", v1.ClaimPending, &classEmpty), "snap3-16", nil),
+			expectedEvents: []string{"Warning NfsExportDeletePending"},
+			initialSecrets: []*v1.Secret{secret()},
+			errors:         noerrors,
+			test:           testSyncNfsExport,
+		},
+		{
+			name:              "3-17 - (dynamic) content will be deleted with a warning if its source PV with retain policy was deleted",
+			initialContents:   withSourceVolume(newContentArray("snapcontent-snapuid3-17", "snapuid3-17", "snap3-17", "sid3-17", validSecretClass, "", "volume3-17", deletePolicy, nil, nil, true), "volume3-17", v1.PersistentVolumeReclaimRetain),
+			expectedContents:  nocontents,
+			initialNfsExports: newNfsExportArray("snap3-17", "snapuid3-17", "claim3-17", "", validSecretClass, "snapcontent-snapuid3-17", &True, nil, nil, nil, false, true, &timeNowMetav1),
+			expectedNfsExports: withNfsExportFinalizers(newNfsExportArray("snap3-17", "snapuid3-17", "claim3-17", "", validSecretClass, "snapcontent-snapuid3-17", &True, nil, nil, nil, false, false, &timeNowMetav1),
+				utils.VolumeNfsExportBoundFinalizer,
+			),
+			initialClaims:  newClaimArray("claim3-17", "pvc-uid3-17", "1Gi", "volume3-17", v1.ClaimBound, &classEmpty),
+			expectedEvents: []string{"Warning RetainedSourceVolumeDeleted", "Warning RetainedSourceVolumeDeleted"},
+			initialSecrets: []*v1.Secret{secret()},
+			errors:         noerrors,
+			test: func(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
+				// The lister reports the PV as not found.
+				ctrl.pvLister = corelisters.NewPersistentVolumeLister(cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{}))
+				return testSyncNfsExport(ctrl, reactor, test)
+			},
+		},
 	}
 	runSyncTests(t, tests, nfsexportClasses)
 }
+
+// Test a single sweep of never bound contents: only dynamically created
+// contents without status whose nfsexport is gone and that are older than the
+// grace period are deleted.
+func TestSweepNeverBoundContents(t *testing.T) {
+	old := metav1.NewTime(timeNow.Add(-time.Hour))
+	recent := metav1.NewTime(timeNow.Add(-time.Minute))
+	newStaleContent := func(name, snapUID, snapName string, created metav1.Time) *crdv1.VolumeNfsExportContent {
+		content := newContent(name, snapUID, snapName, "", classGold, "", "volume-handle", deletionPolicy, nil, nil, true, false)
+		content.CreationTimestamp = created
+		return content
+	}
+	beingCreated := newStaleContent("content-being-created", "snapuid5", "snap5", old)
+	metav1.SetMetaDataAnnotation(&beingCreated.ObjectMeta, utils.AnnVolumeNfsExportBeingCreated, "yes")
+	preProvisioned := newContent("content-pre-provisioned", "snapuid6", "snap6", "", classGold, "nfsexport-handle", "", deletionPolicy, nil, nil, true, false)
+	preProvisioned.CreationTimestamp = old
+	contents := []*crdv1.VolumeNfsExportContent{
+		newStaleContent("content-deleted", "snapuid1", "snap1", old),
+		newStaleContent("content-recreated", "snapuid2-old", "snap2", old),
+		newStaleContent("content-recent", "snapuid3", "snap3", recent),
+		newStaleContent("content-bound", "snapuid4", "snap4", old),
+		beingCreated,
+		preProvisioned,
+	}
+	nfsexports := []*crdv1.VolumeNfsExport{
+		newNfsExport("snap2", "snapuid2", "claim2", "", classGold, "", nil, nil, nil, nil, false, true, nil),
+		newNfsExport("snap4", "snapuid4", "claim4", "", classGold, "", nil, nil, nil, nil, false, true, nil),
+	}
+
+	client := fake.NewSimpleClientset()
+	contentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, content := range contents {
+		client.Tracker().Add(content)
+		contentIndexer.Add(content)
+	}
+	for _, nfsexport := range nfsexports {
+		client.Tracker().Add(nfsexport)
+	}
+	ctrl, err := newTestController(kubefake.NewSimpleClientset(), client, nil, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to construct controller: %v", err)
+	}
+	ctrl.contentLister = storagelisters.NewVolumeNfsExportContentLister(contentIndexer)
+	ctrl.neverBoundContentGracePeriod = 10 * time.Minute
+
+	ctrl.sweepNeverBoundContents()
+
+	expectedDeleted := map[string]bool{
+		"content-deleted":         true,
+		"content-recreated":       true,
+		"content-recent":          false,
+		"content-bound":           false,
+		"content-being-created":   false,
+		"content-pre-provisioned": false,
+	}
+	for name, deleted := range expectedDeleted {
+		content, err := client.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), name, metav1.GetOptions{})
+		if got := err != nil; got != deleted {
+			t.Errorf("content %s: expected deleted %v, got %v", name, deleted, got)
+			continue
+		}
+		if !deleted && metav1.HasAnnotation(content.ObjectMeta, utils.AnnVolumeNfsExportBeingDeleted) {
+			t.Errorf("content %s: expected no %s annotation", name, utils.AnnVolumeNfsExportBeingDeleted)
+		}
+	}
+}
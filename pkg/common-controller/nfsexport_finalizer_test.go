@@ -19,8 +19,16 @@ package common_controller
 import (
 	"testing"
 
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	nfsexportscheme "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/scheme"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/core/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 // Test single call to ensurePVCFinalizer, checkandRemovePVCFinalizer, addNfsExportFinalizer, removeNfsExportFinalizer
@@ -86,3 +94,53 @@ func TestNfsExportFinalizer(t *testing.T) {
 	}
 	runFinalizerTests(t, tests, nfsexportClasses)
 }
+
+// Test a single sweep of leftover PVC finalizers: the finalizer is kept on PVCs
+// used by nfsexports in creation and removed from the others.
+func TestSweepPVCFinalizers(t *testing.T) {
+	nfsexportscheme.AddToScheme(scheme.Scheme)
+	claims := []*v1.PersistentVolumeClaim{
+		newClaim("claim-unready", "pvc-uid1", "1Gi", "volume1", v1.ClaimBound, &classEmpty, true),
+		newClaim("claim-ready", "pvc-uid2", "1Gi", "volume2", v1.ClaimBound, &classEmpty, true),
+		newClaim("claim-unused", "pvc-uid3", "1Gi", "volume3", v1.ClaimBound, &classEmpty, true),
+		newClaim("claim-nofinalizer", "pvc-uid4", "1Gi", "volume4", v1.ClaimBound, &classEmpty, false),
+	}
+	nfsexports := []*crdv1.VolumeNfsExport{
+		newNfsExport("snap-unready", "snapuid1", "claim-unready", "", classSilver, "", &False, nil, nil, nil, false, true, nil),
+		newNfsExport("snap-ready", "snapuid2", "claim-ready", "", classSilver, "snapcontent-snapuid2", &True, nil, nil, nil, false, true, nil),
+	}
+
+	kubeClient := &kubefake.Clientset{}
+	client := &fake.Clientset{}
+	ctrl, err := newTestController(kubeClient, client, nil, t, controllerTest{})
+	if err != nil {
+		t.Fatalf("failed to construct controller: %v", err)
+	}
+	reactor := newNfsExportReactor(kubeClient, client, ctrl, nil, nil, noerrors)
+
+	pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, claim := range claims {
+		reactor.claims[claim.Name] = claim
+		pvcIndexer.Add(claim)
+	}
+	ctrl.pvcLister = corelisters.NewPersistentVolumeClaimLister(pvcIndexer)
+	nfsexportIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, nfsexport := range nfsexports {
+		nfsexportIndexer.Add(nfsexport)
+	}
+	ctrl.nfsexportLister = storagelisters.NewVolumeNfsExportLister(nfsexportIndexer)
+
+	ctrl.sweepPVCFinalizers()
+
+	expected := map[string]bool{
+		"claim-unready":     true,
+		"claim-ready":       false,
+		"claim-unused":      false,
+		"claim-nofinalizer": false,
+	}
+	for name, hasFinalizer := range expected {
+		if got := utils.ContainsString(reactor.claims[name].ObjectMeta.Finalizers, utils.PVCFinalizer); got != hasFinalizer {
+			t.Errorf("PVC %s: expected finalizer %v, got %v", name, hasFinalizer, got)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/fakeapiserver"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +34,22 @@ var metaTimeNow = &metav1.Time{
 
 var emptyString = ""
 
+var creationTimestamp2018 = metav1.NewTime(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+
+// creationTimestamp90s is 90s before the time of the fake clock of the
+// controller.
+var creationTimestamp90s = metav1.NewTime(timeNow.Add(-90 * time.Second))
+
+func sourceDeletedCondition(reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               crdv1.ConditionSourceDeleted,
+		Status:             metav1.ConditionTrue,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(timeNow),
+	}
+}
+
 // Test single call to syncNfsExport and syncContent methods.
 // 1. Fill in the controller with initial data
 // 2. Call the tested function (syncNfsExport/syncContent) via
@@ -41,6 +58,9 @@ var emptyString = ""
 func TestSync(t *testing.T) {
 	size := int64(1)
 	nfsexportErr := newVolumeError("Mock content error")
+	// A zero time does not survive the serialization of a status apply.
+	errTime := metav1.NewTime(timeNow.Truncate(time.Second))
+	nfsexportErr.Time = &errTime
 	tests := []controllerTest{
 		{
 			// nfsexport is bound to a non-existing content
@@ -116,10 +136,10 @@ func TestSync(t *testing.T) {
 			initialClaims:     newClaimArray("claim2-8", "pvc-uid2-8", "1Gi", "volume2-8", v1.ClaimBound, &classEmpty),
 			initialVolumes:    newVolumeArray("volume2-8", "pv-uid2-8", "pv-handle2-8", "1Gi", "pvc-uid2-8", "claim2-8", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
 			initialSecrets:    []*v1.Secret{secret()},
-			errors: []reactorError{
-				// Inject error to the first client.VolumenfsexportV1().VolumeNfsExports().Update call.
+			errors: []fakeapiserver.Hook{
+				// Inject error to the first client.VolumenfsexportV1().VolumeNfsExports().Patch call.
 				// All other calls will succeed.
-				{"update", "volumenfsexports", errors.New("mock update error")},
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExports, errors.New("mock update error")),
 			},
 			test: testSyncNfsExportError,
 		},
@@ -129,10 +149,10 @@ func TestSync(t *testing.T) {
 			expectedContents:  nocontents,
 			initialNfsExports:  newNfsExportArray("snap2-9", "snapuid2-9", "claim2-9", "", validSecretClass, "", &False, nil, nil, nil, false, true, nil),
 			expectedNfsExports: newNfsExportArray("snap2-9", "snapuid2-9", "claim2-9", "", validSecretClass, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error nfsexport controller failed to update snap2-9 on API server: cannot get claim from nfsexport"), false, true, nil),
-			errors: []reactorError{
-				{"get", "persistentvolumeclaims", errors.New("mock update error")},
-				{"get", "persistentvolumeclaims", errors.New("mock update error")},
-				{"get", "persistentvolumeclaims", errors.New("mock update error")},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourcePersistentVolumeClaims, errors.New("mock update error")),
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourcePersistentVolumeClaims, errors.New("mock update error")),
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourcePersistentVolumeClaims, errors.New("mock update error")),
 			}, test: testSyncNfsExport,
 		},
 		{
@@ -160,9 +180,9 @@ func TestSync(t *testing.T) {
 			expectedContents:  withContentSpecNfsExportClassName(newContentArray("content2-12", "snapuid2-12", "snap2-12", "sid2-12", validSecretClass, "sid2-12", "", deletionPolicy, nil, nil, false), nil),
 			initialNfsExports:  newNfsExportArray("snap2-12", "snapuid2-12", "", "content2-12", validSecretClass, "content2-12", &False, nil, nil, nil, false, true, nil),
 			expectedNfsExports: newNfsExportArray("snap2-12", "snapuid2-12", "", "content2-12", validSecretClass, "content2-12", &False, nil, nil, newVolumeError("NfsExport failed to bind VolumeNfsExportContent, mock update error"), false, true, nil),
-			errors: []reactorError{
+			errors: []fakeapiserver.Hook{
 				// Inject error to the forth client.VolumenfsexportV1().VolumeNfsExports().Update call.
-				{"patch", "volumenfsexportcontents", errors.New("mock update error")},
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock update error")),
 			},
 			test: testSyncNfsExport,
 		},
@@ -186,6 +206,102 @@ func TestSync(t *testing.T) {
 			expectedContents:  newContentArray("snapcontent-snapuid2-14", "snapuid2-14", "snap2-14", "sid2-14", validSecretClass, "", "pv-handle-2-14", deletionPolicy, nil, nil, false),
 			initialNfsExports:  newNfsExportArray("snap2-14", "snapuid2-14", "claim2-14", "", validSecretClass, "snapcontent-snapuid2-14", &True, metaTimeNow, nil, nil, false, true, nil),
 			expectedNfsExports: newNfsExportArray("snap2-14", "snapuid2-14", "claim2-14", "", validSecretClass, "snapcontent-snapuid2-14", &True, metaTimeNow, nil, nil, false, true, nil),
+			initialClaims:     newClaimArray("claim2-14", "pvc-uid2-14", "1Gi", "volume2-14", v1.ClaimBound, &classEmpty),
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			// status copied from another cluster points to a content the controller never bound
+			name:              "2-15 - (static) ready nfsexport with status bound to an unexpected content, status reset and rebound",
+			initialContents:   newContentArray("content2-15", "snapuid2-15", "snap2-15", "sid2-15", validSecretClass, "sid2-15", "", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("content2-15", "snapuid2-15", "snap2-15", "sid2-15", validSecretClass, "sid2-15", "", deletionPolicy, nil, nil, false),
+			initialNfsExports:  newNfsExportArray("snap2-15", "snapuid2-15", "", "content2-15", validSecretClass, "content2-15-x", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap2-15", "snapuid2-15", "", "content2-15", validSecretClass, "content2-15", &True, nil, nil, nil, false, true, nil),
+			expectedEvents:    []string{"Warning NfsExportStatusReset", "Normal NfsExportReady"},
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			name:              "2-16 - (static) ready nfsexport with status bound to an unexpected content, status reset fails",
+			initialContents:   newContentArray("content2-16", "snapuid2-16", "snap2-16", "sid2-16", validSecretClass, "sid2-16", "", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("content2-16", "snapuid2-16", "snap2-16", "sid2-16", validSecretClass, "sid2-16", "", deletionPolicy, nil, nil, false),
+			initialNfsExports:  newNfsExportArray("snap2-16", "snapuid2-16", "", "content2-16", validSecretClass, "content2-16-x", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap2-16", "snapuid2-16", "", "content2-16", validSecretClass, "content2-16-x", &True, metaTimeNow, nil, nil, false, true, nil),
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportsStatus, errors.New("mock update error")),
+			},
+			test: testSyncNfsExportError,
+		},
+		{
+			// nfsexport was recreated with a reused name and UID after the content had been bound
+			name:              "2-17 - (static) do not bind content recording a different nfsexport creation timestamp",
+			initialContents:   withContentAnnotations(newContentArray("content2-17", "snapuid2-17", "snap2-17", "sid2-17", validSecretClass, "sid2-17", "", deletionPolicy, nil, nil, false), map[string]string{utils.AnnVolumeNfsExportCreationTimestamp: "2020-01-02T03:04:05Z"}),
+			expectedContents:  withContentAnnotations(newContentArray("content2-17", "snapuid2-17", "snap2-17", "sid2-17", validSecretClass, "sid2-17", "", deletionPolicy, nil, nil, false), map[string]string{utils.AnnVolumeNfsExportCreationTimestamp: "2020-01-02T03:04:05Z"}),
+			initialNfsExports:  withNfsExportCreationTimestamp(newNfsExportArray("snap2-17", "snapuid2-17", "", "content2-17", validSecretClass, "", &False, nil, nil, nil, false, true, nil), creationTimestamp2018),
+			expectedNfsExports: withNfsExportCreationTimestamp(newNfsExportArray("snap2-17", "snapuid2-17", "", "content2-17", validSecretClass, "", &False, nil, nil, newVolumeError("VolumeNfsExportContent [content2-17] is bound to a different nfsexport"), false, true, nil), creationTimestamp2018),
+			expectedEvents:    []string{"Warning NfsExportContentMisbound"},
+			errors:            noerrors,
+			test:              testSyncNfsExportError,
+		},
+		{
+			name:              "2-18 - (static) bind content and record nfsexport creation timestamp",
+			initialContents:   newContentArrayWithReadyToUse("content2-18", "", "snap2-18", "sid2-18", validSecretClass, "sid2-18", "", deletionPolicy, &timeNowStamp, nil, &False, false),
+			expectedContents:  withContentAnnotations(newContentArrayWithReadyToUse("content2-18", "snapuid2-18", "snap2-18", "sid2-18", validSecretClass, "sid2-18", "", deletionPolicy, &timeNowStamp, nil, &False, false), map[string]string{utils.AnnVolumeNfsExportCreationTimestamp: "2018-01-02T03:04:05Z"}),
+			initialNfsExports:  withNfsExportCreationTimestamp(newNfsExportArray("snap2-18", "snapuid2-18", "", "content2-18", validSecretClass, "", &False, metaTimeNow, nil, nil, false, true, nil), creationTimestamp2018),
+			expectedNfsExports: withNfsExportCreationTimestamp(newNfsExportArray("snap2-18", "snapuid2-18", "", "content2-18", validSecretClass, "content2-18", &False, metaTimeNow, nil, nil, false, true, nil), creationTimestamp2018),
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			// The nfsexport is annotated after the controller read it. The
+			// status applied by the controller from its stale copy must not
+			// reset the annotation.
+			name:              "2-19 - (static) status reset does not reset metadata written in the meantime",
+			initialContents:   newContentArray("content2-19", "snapuid2-19", "snap2-19", "sid2-19", validSecretClass, "sid2-19", "", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("content2-19", "snapuid2-19", "snap2-19", "sid2-19", validSecretClass, "sid2-19", "", deletionPolicy, nil, nil, false),
+			initialNfsExports:  newNfsExportArray("snap2-19", "snapuid2-19", "", "content2-19", validSecretClass, "content2-19-x", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportAnnotations(newNfsExportArray("snap2-19", "snapuid2-19", "", "content2-19", validSecretClass, "content2-19", &True, nil, nil, nil, false, true, nil), map[string]string{"example.com/owner": "team-a"}),
+			expectedEvents:    []string{"Warning NfsExportStatusReset", "Normal NfsExportReady"},
+			errors:            noerrors,
+			test: wrapTestWithInjectedOperation(testSyncNfsExport, func(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor) {
+				reactor.lock.Lock()
+				defer reactor.lock.Unlock()
+				nfsexport := withNfsExportAnnotations([]*crdv1.VolumeNfsExport{reactor.nfsexports["snap2-19"].DeepCopy()}, map[string]string{"example.com/owner": "team-a"})[0]
+				nfsexport.ResourceVersion = "2"
+				reactor.nfsexports[nfsexport.Name] = nfsexport
+			}),
+		},
+		{
+			// The controller clock is at timeNow, 90s after the nfsexport was created.
+			name:              "2-20 - (static) nfsexport becoming ready records its time to ready",
+			initialContents:   newContentArrayWithReadyToUse("content2-20", "", "snap2-20", "sid2-20", validSecretClass, "sid2-20", "", deletionPolicy, &timeNowStamp, nil, &True, false),
+			expectedContents:  withContentAnnotations(newContentArrayWithReadyToUse("content2-20", "snapuid2-20", "snap2-20", "sid2-20", validSecretClass, "sid2-20", "", deletionPolicy, &timeNowStamp, nil, &True, false), map[string]string{utils.AnnVolumeNfsExportCreationTimestamp: creationTimestamp90s.UTC().Format(time.RFC3339)}),
+			initialNfsExports:  withNfsExportCreationTimestamp(newNfsExportArray("snap2-20", "snapuid2-20", "", "content2-20", validSecretClass, "content2-20", &False, metaTimeNow, nil, nil, false, true, nil), creationTimestamp90s),
+			expectedNfsExports: withNfsExportTimeToReady(withNfsExportCreationTimestamp(newNfsExportArray("snap2-20", "snapuid2-20", "", "content2-20", validSecretClass, "content2-20", &True, metaTimeNow, nil, nil, false, true, nil), creationTimestamp90s), 90*time.Second),
+			expectedEvents:    []string{"Normal NfsExportReady"},
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			name:              "2-21 - (static) nfsexport bound to content in content-only mode",
+			initialContents:   newContentArrayWithReadyToUse("content2-21", "", "snap2-21", "sid2-21", validSecretClass, "sid2-21", "", deletionPolicy, &timeNowStamp, nil, &False, false),
+			expectedContents:  newContentArrayWithReadyToUse("content2-21", "snapuid2-21", "snap2-21", "sid2-21", validSecretClass, "sid2-21", "", deletionPolicy, &timeNowStamp, nil, &False, false),
+			initialNfsExports:  newNfsExportArray("snap2-21", "snapuid2-21", "", "content2-21", validSecretClass, "content2-21", &False, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap2-21", "snapuid2-21", "", "content2-21", validSecretClass, "content2-21", &False, metaTimeNow, nil, nil, false, true, nil),
+			errors:            noerrors,
+			test:              testSyncNfsExportContentOnly,
+		},
+		{
+			// status copied from another cluster points to a content bound to another nfsexport,
+			// the nfsexport gets its own content once the status is reset
+			name:              "2-22 - (dynamic) ready nfsexport with status bound to the content of another nfsexport, status reset and new content created",
+			initialContents:   newContentArray("content2-22", "snapuid2-22-other", "snap2-22-other", "sid2-22", validSecretClass, "", "pv-handle2-22", deletionPolicy, nil, nil, false),
+			expectedContents:  append(newContentArray("content2-22", "snapuid2-22-other", "snap2-22-other", "sid2-22", validSecretClass, "", "pv-handle2-22", deletionPolicy, nil, nil, false), withContentDriverLabel(withSourceVolume(withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid2-22", "snapuid2-22", "snap2-22", "", validSecretClass, "", "pv-handle2-22", deletionPolicy, nil, nil, false, false), map[string]string{utils.AnnDeletionSecretRefName: "secret", utils.AnnDeletionSecretRefNamespace: "default"}), "volume2-22", v1.PersistentVolumeReclaimDelete))...),
+			initialNfsExports:  newNfsExportArray("snap2-22", "snapuid2-22", "claim2-22", "", validSecretClass, "content2-22", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap2-22", "snapuid2-22", "claim2-22", "", validSecretClass, "snapcontent-snapuid2-22", &False, nil, nil, nil, false, true, nil),
+			expectedEvents:    []string{"Warning NfsExportStatusReset"},
+			initialClaims:     newClaimArray("claim2-22", "pvc-uid2-22", "1Gi", "volume2-22", v1.ClaimBound, &classEmpty),
+			initialVolumes:    newVolumeArray("volume2-22", "pv-uid2-22", "pv-handle2-22", "1Gi", "pvc-uid2-22", "claim2-22", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
 			errors:            noerrors,
 			test:              testSyncNfsExport,
 		},
@@ -234,6 +350,7 @@ func TestSync(t *testing.T) {
 			expectedContents:  newContentArray("snapcontent-snapuid3-5", "snapuid3-5", "snap3-5", "sid3-5", validSecretClass, "", "volume-handle-3-5", deletionPolicy, nil, nil, false),
 			initialNfsExports:  newNfsExportArray("snap3-5", "snapuid3-5", "claim3-5", "", validSecretClass, "snapcontent-snapuid3-5", &True, metaTimeNow, nil, nil, false, true, nil),
 			expectedNfsExports: newNfsExportArray("snap3-5", "snapuid3-5", "claim3-5", "", validSecretClass, "snapcontent-snapuid3-5", &True, metaTimeNow, nil, nil, false, true, nil),
+			initialClaims:     newClaimArray("claim3-5", "pvc-uid3-5", "1Gi", "volume3-5", v1.ClaimBound, &classEmpty),
 			errors:            noerrors,
 			test:              testSyncNfsExport,
 		},
@@ -247,6 +364,119 @@ func TestSync(t *testing.T) {
 			errors:            noerrors,
 			test:              testSyncNfsExport,
 		},
+		{
+			name:              "3-7 - (static) ready nfsexport with a spec change not observed yet, observedGeneration updated",
+			initialContents:   newContentArray("content3-7", "snapuid3-7", "snap3-7", "sid3-7", validSecretClass, "sid3-7", "", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("content3-7", "snapuid3-7", "snap3-7", "sid3-7", validSecretClass, "sid3-7", "", deletionPolicy, nil, nil, false),
+			initialNfsExports:  withNfsExportGeneration(newNfsExportArray("snap3-7", "snapuid3-7", "", "content3-7", validSecretClass, "content3-7", &True, metaTimeNow, nil, nil, false, true, nil), 2, 1),
+			expectedNfsExports: withNfsExportGeneration(newNfsExportArray("snap3-7", "snapuid3-7", "", "content3-7", validSecretClass, "content3-7", &True, metaTimeNow, nil, nil, false, true, nil), 2, 2),
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			name:              "3-8 - (static) ready nfsexport bound to a content missing its UID, binding completed",
+			initialContents:   newContentArray("content3-8", "", "snap3-8", "sid3-8", validSecretClass, "sid3-8", "", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("content3-8", "snapuid3-8", "snap3-8", "sid3-8", validSecretClass, "sid3-8", "", deletionPolicy, nil, nil, false),
+			initialNfsExports:  newNfsExportArray("snap3-8", "snapuid3-8", "", "content3-8", validSecretClass, "content3-8", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap3-8", "snapuid3-8", "", "content3-8", validSecretClass, "content3-8", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedEvents:    []string{"Normal NfsExportBindingRepaired"},
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			name:              "3-9 - (static) ready nfsexport restored to a bound claim annotated to delete its source, nfsexport deleted",
+			initialContents:   newContentArray("content3-9", "snapuid3-9", "snap3-9", "sid3-9", validSecretClass, "sid3-9", "", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("content3-9", "snapuid3-9", "snap3-9", "sid3-9", validSecretClass, "sid3-9", "", deletionPolicy, nil, nil, false),
+			initialNfsExports:  newNfsExportArray("snap3-9", "snapuid3-9", "", "content3-9", validSecretClass, "content3-9", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: nonfsexports,
+			initialClaims:     withClaimRestoredFrom(newClaimArray("restore3-9", "pvc-uid3-9", "1Gi", "volume3-9", v1.ClaimBound, &classEmpty), "snap3-9", map[string]string{utils.AnnDeleteSourceWhenBound: "true"}),
+			expectedEvents:    []string{"Normal RestoredNfsExportDeleted"},
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			name:              "3-10 - (static) ready nfsexport restored to a bound claim annotated to delete its source and to a pending claim, nfsexport kept",
+			initialContents:   newContentArray("content3-10", "snapuid3-10", "snap3-10", "sid3-10", validSecretClass, "sid3-10", "", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("content3-10", "snapuid3-10", "snap3-10", "sid3-10", validSecretClass, "sid3-10", "", deletionPolicy, nil, nil, false),
+			initialNfsExports:  newNfsExportArray("snap3-10", "snapuid3-10", "", "content3-10", validSecretClass, "content3-10", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap3-10", "snapuid3-10", "", "content3-10", validSecretClass, "content3-10", &True, metaTimeNow, nil, nil, false, true, nil),
+			initialClaims: append(
+				withClaimRestoredFrom(newClaimArray("restore3-10", "pvc-uid3-10", "1Gi", "volume3-10", v1.ClaimBound, &classEmpty), "snap3-10", map[string]string{utils.AnnDeleteSourceWhenBound: "true"}),
+				withClaimRestoredFrom(newClaimArray("restore3-10-2", "pvc-uid3-10-2", "1Gi", "", v1.ClaimPending, &classEmpty), "snap3-10", nil)...),
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test:           testSyncNfsExport,
+		},
+		{
+			name:              "3-11 - (dynamic) ready point-in-time nfsexport whose source claim is deleted, SourceDeleted condition set",
+			initialContents:   newContentArray("snapcontent-snapuid3-11", "snapuid3-11", "snap3-11", "sid3-11", validSecretClass, "", "volume-handle-3-11", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("snapcontent-snapuid3-11", "snapuid3-11", "snap3-11", "sid3-11", validSecretClass, "", "volume-handle-3-11", deletionPolicy, nil, nil, false),
+			initialNfsExports:  withNfsExportMode(newNfsExportArray("snap3-11", "snapuid3-11", "claim3-11", "", validSecretClass, "snapcontent-snapuid3-11", &True, metaTimeNow, nil, nil, false, true, nil), crdv1.VolumeNfsExportModePointInTime),
+			expectedNfsExports: withNfsExportMode(withNfsExportConditions(newNfsExportArray("snap3-11", "snapuid3-11", "claim3-11", "", validSecretClass, "snapcontent-snapuid3-11", &True, metaTimeNow, nil, nil, false, true, nil),
+				sourceDeletedCondition(crdv1.SourceDeletedReasonClaimNotFound, "PersistentVolumeClaim claim3-11 was deleted, the export is the only copy of its data")), crdv1.VolumeNfsExportModePointInTime),
+			expectedEvents: []string{"Normal SourcePVCDeleted"},
+			errors:         noerrors,
+			test:           testSyncNfsExport,
+		},
+		{
+			name:              "3-12 - (dynamic) ready point-in-time nfsexport whose source claim is recreated, SourceDeleted condition set",
+			initialContents:   newContentArray("snapcontent-snapuid3-12", "snapuid3-12", "snap3-12", "sid3-12", validSecretClass, "", "volume-handle-3-12", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("snapcontent-snapuid3-12", "snapuid3-12", "snap3-12", "sid3-12", validSecretClass, "", "volume-handle-3-12", deletionPolicy, nil, nil, false),
+			initialNfsExports:  withNfsExportMode(newNfsExportArray("snap3-12", "snapuid3-12", "claim3-12", "", validSecretClass, "snapcontent-snapuid3-12", &True, metaTimeNow, nil, nil, false, true, nil), crdv1.VolumeNfsExportModePointInTime),
+			expectedNfsExports: withNfsExportMode(withNfsExportConditions(newNfsExportArray("snap3-12", "snapuid3-12", "claim3-12", "", validSecretClass, "snapcontent-snapuid3-12", &True, metaTimeNow, nil, nil, false, true, nil),
+				sourceDeletedCondition(crdv1.SourceDeletedReasonClaimRecreated, "PersistentVolumeClaim claim3-12 was recreated after the export was cut, the export is the only copy of the data of the original claim")), crdv1.VolumeNfsExportModePointInTime),
+			initialClaims:  withClaimCreationTimestamp(newClaimArray("claim3-12", "pvc-uid3-12", "1Gi", "volume3-12", v1.ClaimBound, &classEmpty), metav1.NewTime(metaTimeNow.Add(time.Hour))),
+			expectedEvents: []string{"Normal SourcePVCDeleted"},
+			errors:         noerrors,
+			test:           testSyncNfsExport,
+		},
+		{
+			name:              "3-13 - (dynamic) ready point-in-time nfsexport whose source claim is deleted, SourceDeleted condition already set",
+			initialContents:   newContentArray("snapcontent-snapuid3-13", "snapuid3-13", "snap3-13", "sid3-13", validSecretClass, "", "volume-handle-3-13", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("snapcontent-snapuid3-13", "snapuid3-13", "snap3-13", "sid3-13", validSecretClass, "", "volume-handle-3-13", deletionPolicy, nil, nil, false),
+			initialNfsExports: withNfsExportMode(withNfsExportConditions(newNfsExportArray("snap3-13", "snapuid3-13", "claim3-13", "", validSecretClass, "snapcontent-snapuid3-13", &True, metaTimeNow, nil, nil, false, true, nil),
+				sourceDeletedCondition(crdv1.SourceDeletedReasonClaimNotFound, "PersistentVolumeClaim claim3-13 was deleted, the export is the only copy of its data")), crdv1.VolumeNfsExportModePointInTime),
+			expectedNfsExports: withNfsExportMode(withNfsExportConditions(newNfsExportArray("snap3-13", "snapuid3-13", "claim3-13", "", validSecretClass, "snapcontent-snapuid3-13", &True, metaTimeNow, nil, nil, false, true, nil),
+				sourceDeletedCondition(crdv1.SourceDeletedReasonClaimNotFound, "PersistentVolumeClaim claim3-13 was deleted, the export is the only copy of its data")), crdv1.VolumeNfsExportModePointInTime),
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test:           testSyncNfsExport,
+		},
+		{
+			name:              "3-15 - (dynamic) ready point-in-time nfsexport whose further source claim is deleted, SourceDeleted condition set",
+			initialContents:   newContentArray("snapcontent-snapuid3-15", "snapuid3-15", "snap3-15", "sid3-15", validSecretClass, "", "volume-handle-3-15", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("snapcontent-snapuid3-15", "snapuid3-15", "snap3-15", "sid3-15", validSecretClass, "", "volume-handle-3-15", deletionPolicy, nil, nil, false),
+			initialNfsExports: withNfsExportMode(withNfsExportSources(newNfsExportArray("snap3-15", "snapuid3-15", "claim3-15", "", validSecretClass, "snapcontent-snapuid3-15", &True, metaTimeNow, nil, nil, false, true, nil), "claim3-15-2"), crdv1.VolumeNfsExportModePointInTime),
+			expectedNfsExports: withNfsExportMode(withNfsExportConditions(withNfsExportSources(newNfsExportArray("snap3-15", "snapuid3-15", "claim3-15", "", validSecretClass, "snapcontent-snapuid3-15", &True, metaTimeNow, nil, nil, false, true, nil), "claim3-15-2"),
+				sourceDeletedCondition(crdv1.SourceDeletedReasonClaimNotFound, "PersistentVolumeClaim claim3-15-2 was deleted, the export is the only copy of its data")), crdv1.VolumeNfsExportModePointInTime),
+			initialClaims:  newClaimArray("claim3-15", "pvc-uid3-15", "1Gi", "volume3-15", v1.ClaimBound, &classEmpty),
+			expectedEvents: []string{"Normal SourcePVCDeleted"},
+			errors:         noerrors,
+			test:           testSyncNfsExport,
+		},
+		{
+			name:              "3-16 - (dynamic) ready live nfsexport whose source claim is deleted, no SourceDeleted condition",
+			initialContents:   newContentArray("snapcontent-snapuid3-16", "snapuid3-16", "snap3-16", "sid3-16", validSecretClass, "", "volume-handle-3-16", deletionPolicy, nil, nil, false),
+			expectedContents:  newContentArray("snapcontent-snapuid3-16", "snapuid3-16", "snap3-16", "sid3-16", validSecretClass, "", "volume-handle-3-16", deletionPolicy, nil, nil, false),
+			initialNfsExports:  newNfsExportArray("snap3-16", "snapuid3-16", "claim3-16", "", validSecretClass, "snapcontent-snapuid3-16", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap3-16", "snapuid3-16", "claim3-16", "", validSecretClass, "snapcontent-snapuid3-16", &True, metaTimeNow, nil, nil, false, true, nil),
+			expectedEvents:    noevents,
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
+		{
+			name:               "3-14 - (static) ready nfsexport with the name of the source of a bound claim created before it, nfsexport kept",
+			initialContents:    newContentArray("content3-14", "snapuid3-14", "snap3-14", "sid3-14", validSecretClass, "sid3-14", "", deletionPolicy, nil, nil, false),
+			expectedContents:   newContentArray("content3-14", "snapuid3-14", "snap3-14", "sid3-14", validSecretClass, "sid3-14", "", deletionPolicy, nil, nil, false),
+			initialNfsExports:  withNfsExportCreationTimestamp(newNfsExportArray("snap3-14", "snapuid3-14", "", "content3-14", validSecretClass, "content3-14", &True, metaTimeNow, nil, nil, false, true, nil), *metaTimeNow),
+			expectedNfsExports: withNfsExportCreationTimestamp(newNfsExportArray("snap3-14", "snapuid3-14", "", "content3-14", validSecretClass, "content3-14", &True, metaTimeNow, nil, nil, false, true, nil), *metaTimeNow),
+			initialClaims: withClaimCreationTimestamp(
+				withClaimRestoredFrom(newClaimArray("restore3-14", "pvc-uid3-14", "1Gi", "volume3-14", v1.ClaimBound, &classEmpty), "snap3-14", map[string]string{utils.AnnDeleteSourceWhenBound: "true"}),
+				metav1.NewTime(metaTimeNow.Add(-time.Hour))),
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test:           testSyncNfsExport,
+		},
 		{
 			name:              "4-1 - (dynamic) content bound to nfsexport, nfsexport status missing and rebuilt",
 			initialContents:   newContentArrayWithReadyToUse("snapcontent-snapuid4-1", "snapuid4-1", "snap4-1", "sid4-1", validSecretClass, "", "pv-handle4-1", deletionPolicy, nil, &size, &True, false),
@@ -293,10 +523,20 @@ func TestSync(t *testing.T) {
 			errors:            noerrors,
 			test:              testSyncNfsExport,
 		},
+		{
+			name:              "4-5 - (static) content bound to nfsexport, attributes reported by the driver copied to nfsexport status",
+			initialContents:   withContentAttributes(newContentArrayWithReadyToUse("content4-5", "snapuid4-5", "snap4-5", "sid4-5", validSecretClass, "sid4-5", "", deletionPolicy, nil, &size, &True, false), map[string]string{"backendID": "fs-4-5"}),
+			expectedContents:  withContentAttributes(newContentArrayWithReadyToUse("content4-5", "snapuid4-5", "snap4-5", "sid4-5", validSecretClass, "sid4-5", "", deletionPolicy, nil, &size, &True, false), map[string]string{"backendID": "fs-4-5"}),
+			initialNfsExports:  newNfsExportArray("snap4-5", "snapuid4-5", "", "content4-5", validSecretClass, "", &False, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportAttributes(newNfsExportArray("snap4-5", "snapuid4-5", "", "content4-5", validSecretClass, "content4-5", &True, nil, getSize(1), nil, false, true, nil), map[string]string{"backendID": "fs-4-5"}),
+			initialSecrets:    []*v1.Secret{secret()},
+			errors:            noerrors,
+			test:              testSyncNfsExport,
+		},
 		{
 			name:             "5-1 - content missing finalizer is updated to have finalizer",
 			initialContents:  newContentArray("content5-1", "snapuid5-1", "snap5-1", "sid5-1", validSecretClass, "", "pv-handle5-1", deletionPolicy, nil, nil, false),
-			expectedContents: newContentArray("content5-1", "snapuid5-1", "snap5-1", "sid5-1", validSecretClass, "", "pv-handle5-1", deletionPolicy, nil, nil, true),
+			expectedContents: withContentDriverLabel(newContentArray("content5-1", "snapuid5-1", "snap5-1", "sid5-1", validSecretClass, "", "pv-handle5-1", deletionPolicy, nil, nil, true)),
 			initialClaims:    newClaimArray("claim5-1", "pvc-uid5-1", "1Gi", "volume5-1", v1.ClaimBound, &classEmpty),
 			initialVolumes:   newVolumeArray("volume5-1", "pv-uid5-1", "pv-handle5-1", "1Gi", "pvc-uid5-1", "claim5-1", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
 			initialSecrets:   []*v1.Secret{secret()},
@@ -306,13 +546,13 @@ func TestSync(t *testing.T) {
 		{
 			name:             "5-2 - content missing finalizer update attempt fails because of failed API call",
 			initialContents:  newContentArray("content5-2", "snapuid5-2", "snap5-2", "sid5-2", validSecretClass, "", "pv-handle5-2", deletionPolicy, nil, nil, false),
-			expectedContents: newContentArray("content5-2", "snapuid5-2", "snap5-2", "sid5-2", validSecretClass, "", "pv-handle5-2", deletionPolicy, nil, nil, false),
+			expectedContents: withContentDriverLabel(newContentArray("content5-2", "snapuid5-2", "snap5-2", "sid5-2", validSecretClass, "", "pv-handle5-2", deletionPolicy, nil, nil, false)),
 			initialClaims:    newClaimArray("claim5-2", "pvc-uid5-2", "1Gi", "volume5-2", v1.ClaimBound, &classEmpty),
 			initialVolumes:   newVolumeArray("volume5-2", "pv-uid5-2", "pv-handle5-2", "1Gi", "pvc-uid5-2", "claim5-2", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
 			initialSecrets:   []*v1.Secret{secret()},
-			errors: []reactorError{
+			errors: []fakeapiserver.Hook{
 				// Inject error to the forth client.VolumenfsexportV1().VolumeNfsExports().Update call.
-				{"patch", "volumenfsexportcontents", errors.New("mock update error")},
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock update error")),
 			},
 			expectSuccess: false,
 			test:          testSyncContentError,
@@ -322,7 +562,7 @@ func TestSync(t *testing.T) {
 			initialNfsExports:  newNfsExportArray("snap5-3", "snapuid5-3", "claim5-3", "", validSecretClass, "snapcontent-snapuid5-3", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedNfsExports: newNfsExportArray("snap5-3", "snapuid5-3", "claim5-3", "", validSecretClass, "snapcontent-snapuid5-3", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			initialContents:   newContentArray("snapcontent-snapuid5-3", "snapuid5-3", "snap5-3", "sid5-3", validSecretClass, "", "pv-handle5-3", deletionPolicy, nil, nil, true),
-			expectedContents:  withContentAnnotations(newContentArray("snapcontent-snapuid5-3", "snapuid5-3", "snap5-3", "sid5-3", validSecretClass, "", "pv-handle5-3", deletionPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportBeingDeleted: "yes"}),
+			expectedContents:  withContentDriverLabel(withContentAnnotations(newContentArray("snapcontent-snapuid5-3", "snapuid5-3", "snap5-3", "sid5-3", validSecretClass, "", "pv-handle5-3", deletionPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportBeingDeleted: "yes"})),
 			initialClaims:     newClaimArray("claim5-3", "pvc-uid5-3", "1Gi", "volume5-3", v1.ClaimBound, &classEmpty),
 			initialVolumes:    newVolumeArray("volume5-3", "pv-uid5-3", "pv-handle5-3", "1Gi", "pvc-uid5-3", "claim5-3", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
 			initialSecrets:    []*v1.Secret{secret()},
@@ -333,15 +573,15 @@ func TestSync(t *testing.T) {
 			name:              "5-4 - (dynamic) nfsexport deletion candidate fail to mark for deletion due to failed API call",
 			initialNfsExports:  newNfsExportArray("snap5-4", "snapuid5-4", "claim5-4", "", validSecretClass, "snapcontent-snapuid5-4", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedNfsExports: newNfsExportArray("snap5-4", "snapuid5-4", "claim5-4", "", validSecretClass, "snapcontent-snapuid5-4", &False, nil, nil, nil, false, true, &timeNowMetav1),
-			initialContents:   newContentArray("snapcontent-snapuid5-4", "snapuid5-4", "snap5-4", "sid5-4", validSecretClass, "", "pv-handle5-4", deletionPolicy, nil, nil, true),
+			initialContents:   withContentDriverLabel(newContentArray("snapcontent-snapuid5-4", "snapuid5-4", "snap5-4", "sid5-4", validSecretClass, "", "pv-handle5-4", deletionPolicy, nil, nil, true)),
 			// result of the test framework - annotation is still set in memory, but update call fails.
-			expectedContents: withContentAnnotations(newContentArray("snapcontent-snapuid5-4", "snapuid5-4", "snap5-4", "sid5-4", validSecretClass, "", "pv-handle5-4", deletionPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportBeingDeleted: "yes"}),
+			expectedContents: withContentDriverLabel(withContentAnnotations(newContentArray("snapcontent-snapuid5-4", "snapuid5-4", "snap5-4", "sid5-4", validSecretClass, "", "pv-handle5-4", deletionPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportBeingDeleted: "yes"})),
 			initialClaims:    newClaimArray("claim5-4", "pvc-uid5-4", "1Gi", "volume5-4", v1.ClaimBound, &classEmpty),
 			initialVolumes:   newVolumeArray("volume5-4", "pv-uid5-4", "pv-handle5-4", "1Gi", "pvc-uid5-4", "claim5-4", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
 			initialSecrets:   []*v1.Secret{secret()},
-			errors: []reactorError{
+			errors: []fakeapiserver.Hook{
 				// Inject error to the forth client.VolumenfsexportV1().VolumeNfsExports().Update call.
-				{"update", "volumenfsexportcontents", errors.New("mock update error")},
+				fakeapiserver.Error(fakeapiserver.VerbUpdate, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock update error")),
 			},
 			expectSuccess: false,
 			test:          testSyncContentError,
@@ -363,7 +603,7 @@ func TestSync(t *testing.T) {
 			initialNfsExports:  newNfsExportArray("snap5-6", "snapuid5-6", "", "content5-6", validSecretClass, "content5-6", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedNfsExports: newNfsExportArray("snap5-6", "snapuid5-6", "", "content5-6", validSecretClass, "content5-6", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			initialContents:   newContentArray("content5-6", "snapuid5-6", "snap5-6", "sid5-6", validSecretClass, "sid5-6", "", deletionPolicy, nil, nil, true),
-			expectedContents:  withContentAnnotations(newContentArray("content5-6", "snapuid5-6", "snap5-6", "sid5-6", validSecretClass, "sid5-6", "", deletionPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportBeingDeleted: "yes"}),
+			expectedContents:  withContentDriverLabel(withContentAnnotations(newContentArray("content5-6", "snapuid5-6", "snap5-6", "sid5-6", validSecretClass, "sid5-6", "", deletionPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportBeingDeleted: "yes"})),
 			initialSecrets:    []*v1.Secret{secret()},
 			expectSuccess:     true,
 			test:              testSyncContent,
@@ -372,13 +612,13 @@ func TestSync(t *testing.T) {
 			name:              "5-7 - (static) nfsexport deletion candidate fail to mark for deletion due to failed API call",
 			initialNfsExports:  newNfsExportArray("snap5-7", "snapuid5-7", "", "content5-7", validSecretClass, "content5-7", &False, nil, nil, nil, false, true, &timeNowMetav1),
 			expectedNfsExports: newNfsExportArray("snap5-7", "snapuid5-7", "", "content5-7", validSecretClass, "content5-7", &False, nil, nil, nil, false, true, &timeNowMetav1),
-			initialContents:   newContentArray("content5-7", "snapuid5-7", "snap5-7", "sid5-7", validSecretClass, "sid5-7", "", deletionPolicy, nil, nil, true),
+			initialContents:   withContentDriverLabel(newContentArray("content5-7", "snapuid5-7", "snap5-7", "sid5-7", validSecretClass, "sid5-7", "", deletionPolicy, nil, nil, true)),
 			// result of the test framework - annotation is still set in memory, but update call fails.
-			expectedContents: withContentAnnotations(newContentArray("content5-7", "snapuid5-7", "snap5-7", "sid5-7", validSecretClass, "sid5-7", "", deletionPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportBeingDeleted: "yes"}),
+			expectedContents: withContentDriverLabel(withContentAnnotations(newContentArray("content5-7", "snapuid5-7", "snap5-7", "sid5-7", validSecretClass, "sid5-7", "", deletionPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportBeingDeleted: "yes"})),
 			initialSecrets:   []*v1.Secret{secret()},
-			errors: []reactorError{
+			errors: []fakeapiserver.Hook{
 				// Inject error to the forth client.VolumenfsexportV1().VolumeNfsExports().Update call.
-				{"update", "volumenfsexportcontents", errors.New("mock update error")},
+				fakeapiserver.Error(fakeapiserver.VerbUpdate, fakeapiserver.ResourceVolumeNfsExportContents, errors.New("mock update error")),
 			},
 			expectSuccess: false,
 			test:          testSyncContentError,
@@ -393,12 +633,33 @@ func TestSync(t *testing.T) {
 			expectSuccess:     true,
 			test:              testSyncNfsExport,
 		},
+		{
+			name:             "5-9 - unlabeled content is labeled with its driver",
+			initialContents:  newContentArray("content5-9", "snapuid5-9", "snap5-9", "sid5-9", validSecretClass, "", "pv-handle5-9", deletionPolicy, nil, nil, true),
+			expectedContents: withContentDriverLabel(newContentArray("content5-9", "snapuid5-9", "snap5-9", "sid5-9", validSecretClass, "", "pv-handle5-9", deletionPolicy, nil, nil, true)),
+			initialClaims:    newClaimArray("claim5-9", "pvc-uid5-9", "1Gi", "volume5-9", v1.ClaimBound, &classEmpty),
+			initialVolumes:   newVolumeArray("volume5-9", "pv-uid5-9", "pv-handle5-9", "1Gi", "pvc-uid5-9", "claim5-9", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
+			initialSecrets:   []*v1.Secret{secret()},
+			errors:           noerrors,
+			test:             testSyncContent,
+		},
+		{
+			name:             "5-10 - content labeled with its driver is left untouched",
+			initialContents:  withContentDriverLabel(newContentArray("content5-10", "snapuid5-10", "snap5-10", "sid5-10", validSecretClass, "", "pv-handle5-10", deletionPolicy, nil, nil, true)),
+			expectedContents: withContentDriverLabel(newContentArray("content5-10", "snapuid5-10", "snap5-10", "sid5-10", validSecretClass, "", "pv-handle5-10", deletionPolicy, nil, nil, true)),
+			initialClaims:    newClaimArray("claim5-10", "pvc-uid5-10", "1Gi", "volume5-10", v1.ClaimBound, &classEmpty),
+			initialVolumes:   newVolumeArray("volume5-10", "pv-uid5-10", "pv-handle5-10", "1Gi", "pvc-uid5-10", "claim5-10", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
+			initialSecrets:   []*v1.Secret{secret()},
+			errors:           noerrors,
+			test:             testSyncContent,
+		},
 		{
 			name:              "7-1 - fail to create nfsexport with non-existing nfsexport class",
 			initialContents:   nocontents,
 			expectedContents:  nocontents,
 			initialNfsExports:  newNfsExportArray("snap7-1", "snapuid7-1", "claim7-1", "", classNonExisting, "", &False, nil, nil, nil, false, true, nil),
-			expectedNfsExports: newNfsExportArray("snap7-1", "snapuid7-1", "claim7-1", "", classNonExisting, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error failed to get input parameters to create nfsexport snap7-1: \"volumenfsexportclass.nfsexport.storage.k8s.io \\\"non-existing\\\" not found\""), false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap7-1", "snapuid7-1", "claim7-1", "", classNonExisting, "", &False, nil, nil, newVolumeError("Failed to create nfsexport content with error failed to get input parameters to create nfsexport snap7-1: \"volumenfsexportclass.nfsexport.storage.k8s.io \\\"non-existing\\\" not found\""), false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonClassNotFound, "VolumeNfsExportClass non-existing does not exist")),
 			initialClaims:     newClaimArray("claim7-1", "pvc-uid7-1", "1Gi", "volume7-1", v1.ClaimBound, &classEmpty),
 			initialVolumes:    newVolumeArray("volume7-1", "pv-uid7-1", "pv-handle7-1", "1Gi", "pvc-uid7-1", "claim7-1", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, classEmpty),
 			expectedEvents:    []string{"Warning NfsExportContentCreationFailed"},
@@ -462,11 +723,66 @@ func TestSync(t *testing.T) {
 			expectSuccess:     true,
 			test:              testUpdateNfsExportErrorStatus,
 		},
+		{
+			// The content reports the same error again with a new time. The nfsexport
+			// status must not be rewritten, any update call fails the test.
+			name:              "6-5 - same error with a different time does not update nfsexport status",
+			initialContents:   newContentArrayWithError("content6-5", "snapuid6-5", "snap6-5", "sid6-5", validSecretClass, "", "", deletionPolicy, nil, nil, false, &crdv1.VolumeNfsExportError{Time: metaTimeNow, Message: nfsexportErr.Message}),
+			expectedContents:  newContentArrayWithError("content6-5", "snapuid6-5", "snap6-5", "sid6-5", validSecretClass, "", "", deletionPolicy, nil, nil, false, &crdv1.VolumeNfsExportError{Time: metaTimeNow, Message: nfsexportErr.Message}),
+			initialNfsExports:  newNfsExportArray("snap6-5", "snapuid6-5", "claim6-5", "", validSecretClass, "content6-5", &False, nil, nil, nfsexportErr, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap6-5", "snapuid6-5", "claim6-5", "", validSecretClass, "content6-5", &False, nil, nil, nfsexportErr, false, true, nil),
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportsStatus, errors.New("unexpected nfsexport status update")),
+			},
+			expectSuccess: true,
+			test: func(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
+				if ctrl.needsUpdateNfsExportStatus(test.initialNfsExports[0], test.initialContents[0]) {
+					return errors.New("needsUpdateNfsExportStatus returned true for an unchanged error")
+				}
+				_, err := ctrl.updateNfsExportStatus(test.initialNfsExports[0], test.initialContents[0])
+				return err
+			},
+		},
+		{
+			// The nfsexport passed in is up to date, its status is applied
+			// without reading it from the API server.
+			name:              "6-6 - nfsexport status is updated from an up to date nfsexport without a get",
+			initialContents:   newContentArrayNoStatus("content6-6", "snapuid6-6", "snap6-6", "sid6-6", validSecretClass, "", "", deletionPolicy, nil, nil, false, false),
+			expectedContents:  newContentArrayNoStatus("content6-6", "snapuid6-6", "snap6-6", "sid6-6", validSecretClass, "", "", deletionPolicy, nil, nil, false, false),
+			initialNfsExports:  newNfsExportArray("snap6-6", "snapuid6-6", "claim6-6", "", validSecretClass, "", nil, nil, nil, nil, true, false, nil),
+			expectedNfsExports: newNfsExportArray("snap6-6", "snapuid6-6", "claim6-6", "", validSecretClass, "content6-6", &False, nil, nil, nil, false, false, nil),
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourceVolumeNfsExports, errors.New("unexpected nfsexport get")),
+			},
+			expectSuccess: true,
+			test: func(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
+				_, err := ctrl.updateNfsExportStatus(test.initialNfsExports[0], test.initialContents[0])
+				return err
+			},
+		},
+		{
+			// The nfsexport passed in is stale, the apply is rejected with a
+			// conflict and the status is computed again from the nfsexport
+			// read from the API server.
+			name:              "6-7 - nfsexport status is updated from a stale nfsexport after a conflict",
+			initialContents:   newContentArrayNoStatus("content6-7", "snapuid6-7", "snap6-7", "sid6-7", validSecretClass, "", "", deletionPolicy, nil, nil, false, false),
+			expectedContents:  newContentArrayNoStatus("content6-7", "snapuid6-7", "snap6-7", "sid6-7", validSecretClass, "", "", deletionPolicy, nil, nil, false, false),
+			initialNfsExports:  newNfsExportArray("snap6-7", "snapuid6-7", "claim6-7", "", validSecretClass, "", nil, nil, nil, nil, true, false, nil),
+			expectedNfsExports: newNfsExportArray("snap6-7", "snapuid6-7", "claim6-7", "", validSecretClass, "content6-7", &False, nil, nil, nil, false, false, nil),
+			errors:            noerrors,
+			expectSuccess:     true,
+			test: func(ctrl *csiNfsExportCommonController, reactor *nfsexportReactor, test controllerTest) error {
+				stale := test.initialNfsExports[0].DeepCopy()
+				stale.ResourceVersion = "0"
+				_, err := ctrl.updateNfsExportStatus(stale, test.initialContents[0])
+				return err
+			},
+		},
 		{
 			// NfsExport status nil, no initial content, new content should be created.
 			name:              "8-1 - NfsExport status nil, no initial nfsexport content, new content should be created",
 			initialContents:   nocontents,
-			expectedContents:  withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid8-1", "snapuid8-1", "snap8-1", "sid8-1", validSecretClass, "", "pv-handle8-1", deletionPolicy, nil, nil, false, false), map[string]string{utils.AnnDeletionSecretRefName: "secret", utils.AnnDeletionSecretRefNamespace: "default"}),
+			expectedContents:  withContentDriverLabel(withSourceVolume(withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid8-1", "snapuid8-1", "snap8-1", "sid8-1", validSecretClass, "", "pv-handle8-1", deletionPolicy, nil, nil, false, false), map[string]string{utils.AnnDeletionSecretRefName: "secret", utils.AnnDeletionSecretRefNamespace: "default"}), "volume8-1", v1.PersistentVolumeReclaimDelete)),
 			initialNfsExports:  newNfsExportArray("snap8-1", "snapuid8-1", "claim8-1", "", validSecretClass, "", nil, nil, nil, nil, true, false, nil),
 			expectedNfsExports: newNfsExportArray("snap8-1", "snapuid8-1", "claim8-1", "", validSecretClass, "snapcontent-snapuid8-1", &False, nil, nil, nil, false, false, nil),
 			initialClaims:     newClaimArray("claim8-1", "pvc-uid8-1", "1Gi", "volume8-1", v1.ClaimBound, &classEmpty),
@@ -480,7 +796,7 @@ func TestSync(t *testing.T) {
 			// NfsExport status with nil error, no initial content, new content should be created.
 			name:              "8-2 - NfsExport status with nil error, no initial nfsexport content, new content should be created",
 			initialContents:   nocontents,
-			expectedContents:  withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid8-2", "snapuid8-2", "snap8-2", "sid8-2", validSecretClass, "", "pv-handle8-2", deletionPolicy, nil, nil, false, false), map[string]string{utils.AnnDeletionSecretRefName: "secret", utils.AnnDeletionSecretRefNamespace: "default"}),
+			expectedContents:  withContentDriverLabel(withSourceVolume(withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid8-2", "snapuid8-2", "snap8-2", "sid8-2", validSecretClass, "", "pv-handle8-2", deletionPolicy, nil, nil, false, false), map[string]string{utils.AnnDeletionSecretRefName: "secret", utils.AnnDeletionSecretRefNamespace: "default"}), "volume8-2", v1.PersistentVolumeReclaimDelete)),
 			initialNfsExports:  newNfsExportArray("snap8-2", "snapuid8-2", "claim8-2", "", validSecretClass, "", nil, nil, nil, nil, false, false, nil),
 			expectedNfsExports: newNfsExportArray("snap8-2", "snapuid8-2", "claim8-2", "", validSecretClass, "snapcontent-snapuid8-2", &False, nil, nil, nil, false, false, nil),
 			initialClaims:     newClaimArray("claim8-2", "pvc-uid8-2", "1Gi", "volume8-2", v1.ClaimBound, &classEmpty),
@@ -494,7 +810,7 @@ func TestSync(t *testing.T) {
 			// NfsExport status with error, no initial content, new content should be created, nfsexport error should be cleared.
 			name:              "8-3 - NfsExport status with error, no initial content, new content should be created, nfsexport error should be cleared",
 			initialContents:   nocontents,
-			expectedContents:  withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid8-3", "snapuid8-3", "snap8-3", "sid8-3", validSecretClass, "", "pv-handle8-3", deletionPolicy, nil, nil, false, false), map[string]string{utils.AnnDeletionSecretRefName: "secret", utils.AnnDeletionSecretRefNamespace: "default"}),
+			expectedContents:  withContentDriverLabel(withSourceVolume(withContentAnnotations(newContentArrayNoStatus("snapcontent-snapuid8-3", "snapuid8-3", "snap8-3", "sid8-3", validSecretClass, "", "pv-handle8-3", deletionPolicy, nil, nil, false, false), map[string]string{utils.AnnDeletionSecretRefName: "secret", utils.AnnDeletionSecretRefNamespace: "default"}), "volume8-3", v1.PersistentVolumeReclaimDelete)),
 			initialNfsExports:  newNfsExportArray("snap8-3", "snapuid8-3", "claim8-3", "", validSecretClass, "", nil, nil, nil, nfsexportErr, false, false, nil),
 			expectedNfsExports: newNfsExportArray("snap8-3", "snapuid8-3", "claim8-3", "", validSecretClass, "snapcontent-snapuid8-3", &False, nil, nil, nil, false, false, nil),
 			initialClaims:     newClaimArray("claim8-3", "pvc-uid8-3", "1Gi", "volume8-3", v1.ClaimBound, &classEmpty),
@@ -516,6 +832,45 @@ func TestSync(t *testing.T) {
 			errors:            noerrors,
 			test:              testSyncNfsExport,
 		},
+		{
+			name:              "10-1 - retained content rebound to the nfsexport named by its rebind annotation",
+			initialContents:   withContentAnnotations(newContentArray("snapcontent-snapuid10-1", "snapuid10-1", "snap10-1-deleted", "sid10-1", validSecretClass, "", "pv-handle10-1", retainPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportRebindTo: "default/snap10-1", utils.AnnVolumeNfsExportBeingDeleted: "yes"}),
+			expectedContents:  withContentDriverLabel(withContentAnnotations(newContentArray("snapcontent-snapuid10-1", "snapuid10-1-new", "snap10-1", "sid10-1", validSecretClass, "", "pv-handle10-1", retainPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportReboundFrom: "default/snap10-1-deleted"})),
+			initialNfsExports:  newNfsExportArray("snap10-1", "snapuid10-1-new", "", "snapcontent-snapuid10-1", validSecretClass, "", nil, nil, nil, nil, true, false, nil),
+			expectedNfsExports: newNfsExportArray("snap10-1", "snapuid10-1-new", "", "snapcontent-snapuid10-1", validSecretClass, "snapcontent-snapuid10-1", &True, nil, nil, nil, false, false, nil),
+			expectedEvents:    []string{"Normal NfsExportReady", "Normal NfsExportContentRebound"},
+			errors:            noerrors,
+			test:              testSyncContent,
+		},
+		{
+			name:              "10-2 - content not rebound until the nfsexport named by its rebind annotation exists",
+			initialContents:   withContentAnnotations(newContentArray("snapcontent-snapuid10-2", "snapuid10-2", "snap10-2-deleted", "sid10-2", validSecretClass, "", "pv-handle10-2", retainPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportRebindTo: "default/snap10-2"}),
+			expectedContents:  withContentDriverLabel(withContentAnnotations(newContentArray("snapcontent-snapuid10-2", "snapuid10-2", "snap10-2-deleted", "sid10-2", validSecretClass, "", "pv-handle10-2", retainPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportRebindTo: "default/snap10-2"})),
+			initialNfsExports:  nonfsexports,
+			expectedNfsExports: nonfsexports,
+			errors:            noerrors,
+			test:              testSyncContentError,
+		},
+		{
+			name:              "10-3 - content not rebound to a nfsexport that does not refer to it",
+			initialContents:   withContentAnnotations(newContentArray("snapcontent-snapuid10-3", "snapuid10-3", "snap10-3-deleted", "sid10-3", validSecretClass, "", "pv-handle10-3", retainPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportRebindTo: "default/snap10-3"}),
+			expectedContents:  withContentDriverLabel(withContentAnnotations(newContentArray("snapcontent-snapuid10-3", "snapuid10-3", "snap10-3-deleted", "sid10-3", validSecretClass, "", "pv-handle10-3", retainPolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportRebindTo: "default/snap10-3"})),
+			initialNfsExports:  newNfsExportArray("snap10-3", "snapuid10-3-new", "", "other-content", validSecretClass, "", nil, nil, nil, nil, true, false, nil),
+			expectedNfsExports: newNfsExportArray("snap10-3", "snapuid10-3-new", "", "other-content", validSecretClass, "", nil, nil, nil, nil, true, false, nil),
+			expectedEvents:    []string{"Warning NfsExportContentRebindFailed"},
+			errors:            noerrors,
+			test:              testSyncContentError,
+		},
+		{
+			name:              "10-4 - content with Delete policy labelled invalid and not rebound",
+			initialContents:   withContentAnnotations(newContentArray("snapcontent-snapuid10-4", "snapuid10-4", "snap10-4-deleted", "sid10-4", validSecretClass, "", "pv-handle10-4", deletePolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportRebindTo: "other/snap10-4"}),
+			expectedContents:  withContentDriverLabel(withNfsExportContentInvalidLabel(withContentAnnotations(newContentArray("snapcontent-snapuid10-4", "snapuid10-4", "snap10-4-deleted", "sid10-4", validSecretClass, "", "pv-handle10-4", deletePolicy, nil, nil, true), map[string]string{utils.AnnVolumeNfsExportRebindTo: "other/snap10-4"}))),
+			initialNfsExports:  newNfsExportArray("snap10-4", "snapuid10-4-new", "", "snapcontent-snapuid10-4", validSecretClass, "", nil, nil, nil, nil, true, false, nil),
+			expectedNfsExports: newNfsExportArray("snap10-4", "snapuid10-4-new", "", "snapcontent-snapuid10-4", validSecretClass, "", nil, nil, nil, nil, true, false, nil),
+			expectedEvents:    []string{"Warning NfsExportContentRebindFailed"},
+			errors:            noerrors,
+			test:              testSyncContentError,
+		},
 	}
 
 	runSyncTests(t, tests, nfsexportClasses)
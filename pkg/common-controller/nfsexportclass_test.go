@@ -17,10 +17,40 @@ limitations under the License.
 package common_controller
 
 import (
-	v1 "k8s.io/api/core/v1"
 	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+var classMissingError = "Failed to set default nfsexport class with error cannot find default nfsexport class"
+
+func classMissingCondition(status metav1.ConditionStatus, reason, message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               crdv1.ConditionClassMissing,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(timeNow),
+	}
+}
+
+var (
+	classDeletedError          = "VolumeNfsExportClass missing-class was deleted before the export was created; create a new VolumeNfsExport with an existing class"
+	classDeletedNoDefaultError = "VolumeNfsExportClass missing-class was deleted before the export was created and no default class can replace it: cannot find default nfsexport class; create a new VolumeNfsExport with an existing class"
+)
+
+func classDeletedCondition(message string) metav1.Condition {
+	return metav1.Condition{
+		Type:               crdv1.ConditionFailed,
+		Status:             metav1.ConditionTrue,
+		Reason:             crdv1.FailedReasonClassDeleted,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(timeNow),
+	}
+}
+
 // Test single call to checkAndUpdateNfsExportClass.
 // 1. Fill in the controller with initial data
 // 2. Call the tested function checkAndUpdateNfsExportClass via
@@ -76,7 +106,133 @@ func TestUpdateNfsExportClass(t *testing.T) {
 			errors:            noerrors,
 			test:              testUpdateNfsExportClass,
 		},
+		{
+			name:            "1-6 - default nfsexport class found resolves the ClassMissing condition",
+			initialContents: nocontents,
+			initialNfsExports: withNfsExportConditions(newNfsExportArray("snap1-6", "snapuid1-6", "claim1-6", "", "", "", &True, nil, nil, newVolumeError(classMissingError), false, true, nil),
+				classMissingCondition(metav1.ConditionTrue, crdv1.ClassMissingReasonNoDefault, "cannot find default nfsexport class")),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap1-6", "snapuid1-6", "claim1-6", "", defaultClass, "", &True, nil, nil, nil, false, true, nil),
+				classMissingCondition(metav1.ConditionFalse, crdv1.ClassMissingReasonResolved, "VolumeNfsExportClass default-class is used")),
+			initialClaims:  newClaimArray("claim1-6", "pvc-uid1-6", "1Gi", "volume1-6", v1.ClaimBound, &sameDriver),
+			initialVolumes: newVolumeArray("volume1-6", "pv-uid1-6", "pv-handle1-6", "1Gi", "pvc-uid1-6", "claim1-6", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test:           testUpdateNfsExportClass,
+		},
+		{
+			name:              "1-7 - nfsexport class deleted before content creation marks the nfsexport Failed",
+			initialContents:   nocontents,
+			initialNfsExports: newNfsExportArray("snap1-7", "snapuid1-7", "claim1-7", "", "missing-class", "", &False, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap1-7", "snapuid1-7", "claim1-7", "", "missing-class", "", &False, nil, nil, newVolumeError(classDeletedError), false, true, nil),
+				classDeletedCondition(classDeletedError)),
+			initialClaims:  newClaimArray("claim1-7", "pvc-uid1-7", "1Gi", "volume1-7", v1.ClaimBound, &sameDriver),
+			initialVolumes: newVolumeArray("volume1-7", "pv-uid1-7", "pv-handle1-7", "1Gi", "pvc-uid1-7", "claim1-7", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents: []string{"Warning NfsExportClassDeleted"},
+			errors:         noerrors,
+			test:           testUpdateDeletedNfsExportClass,
+		},
+		{
+			name:            "1-8 - deleted nfsexport class is not reported again",
+			initialContents: nocontents,
+			initialNfsExports: withNfsExportConditions(newNfsExportArray("snap1-8", "snapuid1-8", "claim1-8", "", "missing-class", "", &False, nil, nil, newVolumeError(classDeletedError), false, true, nil),
+				classDeletedCondition(classDeletedError)),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap1-8", "snapuid1-8", "claim1-8", "", "missing-class", "", &False, nil, nil, newVolumeError(classDeletedError), false, true, nil),
+				classDeletedCondition(classDeletedError)),
+			initialClaims:  newClaimArray("claim1-8", "pvc-uid1-8", "1Gi", "volume1-8", v1.ClaimBound, &sameDriver),
+			initialVolumes: newVolumeArray("volume1-8", "pv-uid1-8", "pv-handle1-8", "1Gi", "pvc-uid1-8", "claim1-8", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test:           testUpdateNfsExportClass,
+		},
+		{
+			name:              "1-9 - nfsexport class deleted before content creation falls back to the default class",
+			initialContents:   nocontents,
+			initialNfsExports:  newNfsExportArray("snap1-9", "snapuid1-9", "claim1-9", "", "missing-class", "", &False, nil, nil, nil, false, true, nil),
+			expectedNfsExports: newNfsExportArray("snap1-9", "snapuid1-9", "claim1-9", "", defaultClass, "", &False, nil, nil, nil, false, true, nil),
+			initialClaims:     newClaimArray("claim1-9", "pvc-uid1-9", "1Gi", "volume1-9", v1.ClaimBound, &sameDriver),
+			initialVolumes:    newVolumeArray("volume1-9", "pv-uid1-9", "pv-handle1-9", "1Gi", "pvc-uid1-9", "claim1-9", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents:    []string{"Warning NfsExportClassReselected"},
+			errors:            noerrors,
+			test:              testUpdateNfsExportClassWithFallback,
+		},
+		{
+			name:              "1-10 - nfsexport class not found and not seen deleted blocks the nfsexport",
+			initialContents:   nocontents,
+			initialNfsExports: newNfsExportArray("snap1-10", "snapuid1-10", "claim1-10", "", "missing-class", "", &False, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap1-10", "snapuid1-10", "claim1-10", "", "missing-class", "", &False, nil, nil, newVolumeError("Failed to get nfsexport class with error volumenfsexportclass.nfsexport.storage.k8s.io \"missing-class\" not found"), false, true, nil),
+				blockedCondition(metav1.ConditionTrue, crdv1.BlockedReasonClassNotFound, "VolumeNfsExportClass missing-class does not exist")),
+			initialClaims:  newClaimArray("claim1-10", "pvc-uid1-10", "1Gi", "volume1-10", v1.ClaimBound, &sameDriver),
+			initialVolumes: newVolumeArray("volume1-10", "pv-uid1-10", "pv-handle1-10", "1Gi", "pvc-uid1-10", "claim1-10", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents: []string{"Warning GetNfsExportClassFailed"},
+			errors:         noerrors,
+			test:           testSyncNfsExportByKey,
+		},
 	}
 
 	runUpdateNfsExportClassTests(t, tests, nfsexportClasses)
 }
+
+// Test checkAndUpdateNfsExportClass without a default class of the driver.
+func TestUpdateNfsExportClassMissing(t *testing.T) {
+	var nonDefaultClasses []*crdv1.VolumeNfsExportClass
+	for _, class := range nfsexportClasses {
+		if class.Name != defaultClass {
+			nonDefaultClasses = append(nonDefaultClasses, class)
+		}
+	}
+
+	tests := []controllerTest{
+		{
+			name:              "2-1 - missing default nfsexport class sets the ClassMissing condition",
+			initialContents:   nocontents,
+			initialNfsExports: newNfsExportArray("snap2-1", "snapuid2-1", "claim2-1", "", "", "", &True, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap2-1", "snapuid2-1", "claim2-1", "", "", "", &True, nil, nil, newVolumeError(classMissingError), false, true, nil),
+				classMissingCondition(metav1.ConditionTrue, crdv1.ClassMissingReasonNoDefault, "cannot find default nfsexport class")),
+			initialClaims:  newClaimArray("claim2-1", "pvc-uid2-1", "1Gi", "volume2-1", v1.ClaimBound, &sameDriver),
+			initialVolumes: newVolumeArray("volume2-1", "pv-uid2-1", "pv-handle2-1", "1Gi", "pvc-uid2-1", "claim2-1", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents: []string{"Warning SetDefaultNfsExportClassFailed"},
+			errors:         noerrors,
+			test:           testUpdateNfsExportClass,
+		},
+		{
+			name:            "2-2 - still missing default nfsexport class is not reported again",
+			initialContents: nocontents,
+			initialNfsExports: withNfsExportConditions(newNfsExportArray("snap2-2", "snapuid2-2", "claim2-2", "", "", "", &True, nil, nil, newVolumeError(classMissingError), false, true, nil),
+				classMissingCondition(metav1.ConditionTrue, crdv1.ClassMissingReasonNoDefault, "cannot find default nfsexport class")),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap2-2", "snapuid2-2", "claim2-2", "", "", "", &True, nil, nil, newVolumeError(classMissingError), false, true, nil),
+				classMissingCondition(metav1.ConditionTrue, crdv1.ClassMissingReasonNoDefault, "cannot find default nfsexport class")),
+			initialClaims:  newClaimArray("claim2-2", "pvc-uid2-2", "1Gi", "volume2-2", v1.ClaimBound, &sameDriver),
+			initialVolumes: newVolumeArray("volume2-2", "pv-uid2-2", "pv-handle2-2", "1Gi", "pvc-uid2-2", "claim2-2", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test:           testUpdateNfsExportClass,
+		},
+		{
+			name:              "2-3 - nfsexport class deleted before content creation without a default class to fall back to",
+			initialContents:   nocontents,
+			initialNfsExports: newNfsExportArray("snap2-3", "snapuid2-3", "claim2-3", "", "missing-class", "", &False, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap2-3", "snapuid2-3", "claim2-3", "", "missing-class", "", &False, nil, nil, newVolumeError(classDeletedNoDefaultError), false, true, nil),
+				classDeletedCondition(classDeletedNoDefaultError)),
+			initialClaims:  newClaimArray("claim2-3", "pvc-uid2-3", "1Gi", "volume2-3", v1.ClaimBound, &sameDriver),
+			initialVolumes: newVolumeArray("volume2-3", "pv-uid2-3", "pv-handle2-3", "1Gi", "pvc-uid2-3", "claim2-3", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents: []string{"Warning NfsExportClassDeleted"},
+			errors:         noerrors,
+			test:           testUpdateNfsExportClassWithFallback,
+		},
+		{
+			name:              "2-4 - missing default nfsexport class is recorded without retrying the nfsexport",
+			initialContents:   nocontents,
+			initialNfsExports: newNfsExportArray("snap2-4", "snapuid2-4", "claim2-4", "", "", "", &True, nil, nil, nil, false, true, nil),
+			expectedNfsExports: withNfsExportConditions(newNfsExportArray("snap2-4", "snapuid2-4", "claim2-4", "", "", "", &True, nil, nil, newVolumeError(classMissingError), false, true, nil),
+				classMissingCondition(metav1.ConditionTrue, crdv1.ClassMissingReasonNoDefault, "cannot find default nfsexport class")),
+			initialClaims:  newClaimArray("claim2-4", "pvc-uid2-4", "1Gi", "volume2-4", v1.ClaimBound, &sameDriver),
+			initialVolumes: newVolumeArray("volume2-4", "pv-uid2-4", "pv-handle2-4", "1Gi", "pvc-uid2-4", "claim2-4", v1.VolumeBound, v1.PersistentVolumeReclaimDelete, sameDriver),
+			expectedEvents: []string{"Warning SetDefaultNfsExportClassFailed"},
+			errors:         noerrors,
+			expectSuccess:  true,
+			test:           testSyncNfsExportByKey,
+		},
+	}
+
+	runUpdateNfsExportClassTests(t, tests, nonDefaultClasses)
+}
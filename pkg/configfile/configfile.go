@@ -0,0 +1,207 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package configfile sets the command line flags of a binary from a YAML
+// config file, which maps flag names to their values:
+//
+//	v: 4
+//	kube-api-qps: 20
+//	timeout: 2m
+//
+// Flags set on the command line take precedence over the config file. The
+// config file can be watched, so that changes to a reloadable subset of the
+// flags are applied without restarting the binary:
+//
+//	cfg, err := configfile.Load(flag.CommandLine, *configPath)
+//	cfg.OnReload(func() { ... }, "kube-api-qps", "kube-api-burst")
+//	go cfg.Watch(time.Minute, stopCh)
+package configfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	klog "k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigFile is a config file whose values were applied to the flags of a
+// FlagSet.
+type ConfigFile struct {
+	fs   *flag.FlagSet
+	path string
+	// commandLine holds the flags set on the command line, which are not
+	// set from the config file.
+	commandLine map[string]bool
+
+	lock sync.Mutex
+	// values holds the last values read from the config file, by flag name.
+	values map[string]string
+	// reloadable holds the index in applies of the callback applying a new
+	// value of the reloadable flags, by flag name.
+	reloadable map[string]int
+	applies    []func()
+}
+
+// Load sets the flags of fs that are not set on the command line from the
+// config file at path. It fails if the config file sets unknown flags.
+func Load(fs *flag.FlagSet, path string) (*ConfigFile, error) {
+	c := &ConfigFile{
+		fs:          fs,
+		path:        path,
+		commandLine: map[string]bool{},
+		reloadable:  map[string]int{},
+	}
+	fs.Visit(func(f *flag.Flag) {
+		c.commandLine[f.Name] = true
+	})
+	values, err := c.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sortedNames(values) {
+		if c.commandLine[name] {
+			klog.V(2).Infof("Flag --%s is set on the command line, ignoring its value in config file %s", name, path)
+			continue
+		}
+		if err := fs.Set(name, values[name]); err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag --%s in config file %s: %v", values[name], name, path, err)
+		}
+	}
+	c.values = values
+	return c, nil
+}
+
+// OnReload marks the flags names as reloadable. When the config file is
+// reloaded, new values of these flags are set and apply is called once.
+// New values of the other flags are only used after a restart.
+func (c *ConfigFile) OnReload(apply func(), names ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, name := range names {
+		c.reloadable[name] = len(c.applies)
+	}
+	c.applies = append(c.applies, apply)
+}
+
+// Watch reloads the config file every interval until stopCh is closed.
+// Polling, rather than watching file events, also catches the atomic
+// symlink swaps of ConfigMap volumes.
+func (c *ConfigFile) Watch(interval time.Duration, stopCh <-chan struct{}) {
+	wait.Until(func() {
+		if err := c.Reload(); err != nil {
+			klog.Errorf("Failed to reload config file %s: %v", c.path, err)
+		}
+	}, interval, stopCh)
+}
+
+// Reload reads the config file again and sets the changed reloadable flags.
+// The flags are left untouched if the config file is invalid.
+func (c *ConfigFile) Reload() error {
+	values, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	changed := map[string]string{}
+	for _, name := range sortedNames(values) {
+		if values[name] == c.values[name] || c.commandLine[name] {
+			continue
+		}
+		if _, ok := c.reloadable[name]; !ok {
+			klog.Warningf("Flag --%s changed in config file %s, restart to use its new value", name, c.path)
+			continue
+		}
+		changed[name] = values[name]
+	}
+	old := map[string]string{}
+	applied := map[int]bool{}
+	for _, name := range sortedNames(changed) {
+		old[name] = c.fs.Lookup(name).Value.String()
+		if err := c.fs.Set(name, changed[name]); err != nil {
+			// Do not apply an invalid config file partially.
+			for name, value := range old {
+				c.fs.Set(name, value)
+			}
+			return fmt.Errorf("invalid value %q for flag --%s: %v", changed[name], name, err)
+		}
+		applied[c.reloadable[name]] = true
+	}
+	for i, apply := range c.applies {
+		if applied[i] {
+			apply()
+		}
+	}
+	for _, name := range sortedNames(changed) {
+		klog.Infof("Flag --%s reloaded from config file %s: %s", name, c.path, changed[name])
+	}
+	c.values = values
+	return nil
+}
+
+// read reads the config file and returns its values by flag name.
+func (c *ConfigFile) read() (map[string]string, error) {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", c.path, err)
+	}
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	// Keep numbers as written, so that large integers are not turned into
+	// floats in exponent notation.
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("config file %s must map flag names to values: %v", c.path, err)
+	}
+	values := map[string]string{}
+	for name, value := range raw {
+		if c.fs.Lookup(name) == nil {
+			return nil, fmt.Errorf("unknown flag %q in config file %s", name, c.path)
+		}
+		switch v := value.(type) {
+		case string:
+			values[name] = v
+		case json.Number, bool:
+			values[name] = fmt.Sprint(v)
+		default:
+			return nil, fmt.Errorf("flag %q in config file %s must have a scalar value", name, c.path)
+		}
+	}
+	return values, nil
+}
+
+// sortedNames returns the flag names of values in a stable order.
+func sortedNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configfile
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testFlags struct {
+	fs         *flag.FlagSet
+	qps        *float64
+	burst      *int
+	timeout    *time.Duration
+	kubeconfig *string
+}
+
+func newTestFlags(t *testing.T, args ...string) *testFlags {
+	f := &testFlags{fs: flag.NewFlagSet("test", flag.ContinueOnError)}
+	f.qps = f.fs.Float64("kube-api-qps", 5, "")
+	f.burst = f.fs.Int("kube-api-burst", 10, "")
+	f.timeout = f.fs.Duration("timeout", time.Minute, "")
+	f.kubeconfig = f.fs.String("kubeconfig", "", "")
+	if err := f.fs.Parse(args); err != nil {
+		t.Fatalf("failed to parse flags: %v", err)
+	}
+	return f
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		content       string
+		expectErr     bool
+		expectQPS     float64
+		expectBurst   int
+		expectTimeout time.Duration
+	}{
+		{
+			name:          "flags set from the config file",
+			content:       "kube-api-qps: 20.5\nkube-api-burst: 1000000\ntimeout: 2m\n",
+			expectQPS:     20.5,
+			expectBurst:   1000000,
+			expectTimeout: 2 * time.Minute,
+		},
+		{
+			name:          "command line takes precedence",
+			args:          []string{"--kube-api-qps=7"},
+			content:       "kube-api-qps: 20\nkube-api-burst: 40\n",
+			expectQPS:     7,
+			expectBurst:   40,
+			expectTimeout: time.Minute,
+		},
+		{
+			name:      "unknown flag",
+			content:   "kube-api-qs: 20\n",
+			expectErr: true,
+		},
+		{
+			name:      "invalid value",
+			content:   "timeout: soon\n",
+			expectErr: true,
+		},
+		{
+			name:      "non-scalar value",
+			content:   "kubeconfig: [a, b]\n",
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newTestFlags(t, test.args...)
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			writeConfig(t, path, test.content)
+
+			_, err := Load(f.fs, path)
+			if test.expectErr {
+				if err == nil {
+					t.Errorf("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if *f.qps != test.expectQPS || *f.burst != test.expectBurst || *f.timeout != test.expectTimeout {
+				t.Errorf("expected QPS %v, burst %d and timeout %v, got %v, %d and %v", test.expectQPS, test.expectBurst, test.expectTimeout, *f.qps, *f.burst, *f.timeout)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	f := newTestFlags(t, "--timeout=30s")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, "kube-api-qps: 20\nkube-api-burst: 40\nkubeconfig: /etc/kubeconfig\n")
+	cfg, err := Load(f.fs, path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	applied := 0
+	cfg.OnReload(func() { applied++ }, "kube-api-qps", "kube-api-burst", "timeout")
+
+	// Both reloadable flags change, the limits are applied once. The
+	// kubeconfig needs a restart and the timeout is set on the command line.
+	writeConfig(t, path, "kube-api-qps: 30\nkube-api-burst: 60\nkubeconfig: /etc/other\ntimeout: 1m\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *f.qps != 30 || *f.burst != 60 {
+		t.Errorf("expected QPS 30 and burst 60, got %v and %d", *f.qps, *f.burst)
+	}
+	if *f.kubeconfig != "/etc/kubeconfig" {
+		t.Errorf("expected kubeconfig not to be reloaded, got %q", *f.kubeconfig)
+	}
+	if *f.timeout != 30*time.Second {
+		t.Errorf("expected timeout of the command line, got %v", *f.timeout)
+	}
+	if applied != 1 {
+		t.Errorf("expected the new limits to be applied once, got %d", applied)
+	}
+
+	// An invalid config file is not applied partially.
+	writeConfig(t, path, "kube-api-qps: 40\nkube-api-burst: many\n")
+	if err := cfg.Reload(); err == nil {
+		t.Errorf("expected an error for an invalid config file")
+	}
+	if *f.qps != 30 || *f.burst != 60 {
+		t.Errorf("expected QPS 30 and burst 60 to be kept, got %v and %d", *f.qps, *f.burst)
+	}
+	if applied != 1 {
+		t.Errorf("expected an invalid config file not to be applied, got %d", applied)
+	}
+
+	// An unchanged config file is not applied again.
+	writeConfig(t, path, "kube-api-qps: 30\nkube-api-burst: 60\n")
+	if err := cfg.Reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if applied != 1 {
+		t.Errorf("expected an unchanged config file not to be applied, got %d", applied)
+	}
+}
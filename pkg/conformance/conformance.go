@@ -0,0 +1,273 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package conformance is a test harness for CSI driver authors. It runs a
+// NfsExportter against a live CSI endpoint and checks the create, status and
+// delete semantics that the sidecar relies on. A driver author calls Run from
+// a regular Go test:
+//
+//	func TestConformance(t *testing.T) {
+//		conn, _ := connection.Connect(address, metrics.NewCSIMetricsManager(""))
+//		conformance.Run(t, conformance.Config{
+//			NfsExportter:       nfsexporter.NewNfsExportter(conn),
+//			DriverName:         "hostpath.csi.k8s.io",
+//			SourceVolumeHandle: "an-existing-volume",
+//		})
+//	}
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/nfsexporter"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	defaultNamePrefix   = "conformance"
+	defaultTimeout      = 10 * time.Second
+	defaultReadyTimeout = 2 * time.Minute
+	defaultPollInterval = time.Second
+)
+
+// Config describes the driver under test.
+type Config struct {
+	// NfsExportter issues the CSI calls, usually created with
+	// nfsexporter.NewNfsExportter for a connection to the driver.
+	NfsExportter nfsexporter.NfsExportter
+	// DriverName is the name the driver is expected to report.
+	DriverName string
+	// SourceVolumeHandle is the handle of an existing volume to export.
+	SourceVolumeHandle string
+	// Parameters are passed to every CreateNfsExport call, like the
+	// parameters of a VolumeNfsExportClass.
+	Parameters map[string]string
+	// Secrets are passed to CreateNfsExport and DeleteNfsExport.
+	Secrets map[string]string
+	// ListSecrets are passed to GetNfsExportStatus.
+	ListSecrets map[string]string
+	// SecretsRequired checks that calls without Secrets are rejected.
+	SecretsRequired bool
+	// NamePrefix is prepended to the names of created nfsexports.
+	// Defaults to "conformance".
+	NamePrefix string
+	// Timeout bounds every single CSI call. Defaults to 10 seconds.
+	Timeout time.Duration
+	// ReadyTimeout bounds the wait for a nfsexport to become ready to use.
+	// Defaults to 2 minutes.
+	ReadyTimeout time.Duration
+	// PollInterval is the interval between status checks while waiting for
+	// a nfsexport to become ready. Defaults to 1 second.
+	PollInterval time.Duration
+}
+
+// Run runs the conformance checks as subtests of t. Every nfsexport created
+// by a check is deleted before the check returns.
+func Run(t *testing.T, config Config) {
+	if config.NfsExportter == nil {
+		t.Fatal("conformance: Config.NfsExportter must be set")
+	}
+	if config.SourceVolumeHandle == "" {
+		t.Fatal("conformance: Config.SourceVolumeHandle must be set")
+	}
+	if config.NamePrefix == "" {
+		config.NamePrefix = defaultNamePrefix
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.ReadyTimeout == 0 {
+		config.ReadyTimeout = defaultReadyTimeout
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	s := &suite{config: config, start: time.Now()}
+
+	t.Run("CreateNfsExport", s.testCreate)
+	t.Run("CreateNfsExportIsIdempotent", s.testCreateIdempotent)
+	t.Run("CreateNfsExportFromMissingVolume", s.testCreateFromMissingVolume)
+	t.Run("DeleteNfsExportIsIdempotent", s.testDeleteIdempotent)
+	t.Run("DeleteMissingNfsExport", s.testDeleteMissing)
+	if config.SecretsRequired {
+		t.Run("CreateNfsExportWithoutSecrets", s.testCreateWithoutSecrets)
+	}
+}
+
+type suite struct {
+	config Config
+	start  time.Time
+	seq    int
+}
+
+// name returns a unique nfsexport name for the current run.
+func (s *suite) name() string {
+	s.seq++
+	return fmt.Sprintf("%s-%d-%d", s.config.NamePrefix, s.start.Unix(), s.seq)
+}
+
+func (s *suite) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.config.Timeout)
+}
+
+func (s *suite) create(name, volumeHandle string, secrets map[string]string) (string, string, time.Time, int64, bool, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.config.NfsExportter.CreateNfsExport(ctx, name, volumeHandle, s.config.Parameters, secrets)
+}
+
+func (s *suite) delete(nfsexportID string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.config.NfsExportter.DeleteNfsExport(ctx, nfsexportID, s.config.Secrets)
+}
+
+func (s *suite) status(nfsexportID string) (bool, time.Time, int64, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+	return s.config.NfsExportter.GetNfsExportStatus(ctx, nfsexportID, s.config.ListSecrets)
+}
+
+// mustCreate creates a nfsexport of the source volume and registers its
+// deletion with t.Cleanup.
+func (s *suite) mustCreate(t *testing.T, name string) string {
+	driverName, nfsexportID, _, _, _, err := s.create(name, s.config.SourceVolumeHandle, s.config.Secrets)
+	if err != nil {
+		t.Fatalf("CreateNfsExport %s failed: %v", name, err)
+	}
+	if nfsexportID == "" {
+		t.Fatalf("CreateNfsExport %s returned an empty nfsexport ID", name)
+	}
+	t.Cleanup(func() {
+		if err := s.delete(nfsexportID); err != nil {
+			t.Errorf("failed to clean up nfsexport %s: %v", nfsexportID, err)
+		}
+	})
+	if s.config.DriverName != "" && driverName != s.config.DriverName {
+		t.Errorf("CreateNfsExport %s returned driver name %q, expected %q", name, driverName, s.config.DriverName)
+	}
+	return nfsexportID
+}
+
+func (s *suite) testCreate(t *testing.T) {
+	nfsexportID := s.mustCreate(t, s.name())
+
+	err := wait.PollImmediate(s.config.PollInterval, s.config.ReadyTimeout, func() (bool, error) {
+		ready, _, size, err := s.status(nfsexportID)
+		if err != nil {
+			return false, fmt.Errorf("GetNfsExportStatus %s failed: %v", nfsexportID, err)
+		}
+		if size < 0 {
+			return false, fmt.Errorf("GetNfsExportStatus %s returned negative size %d", nfsexportID, size)
+		}
+		return ready, nil
+	})
+	if err != nil {
+		t.Fatalf("nfsexport %s did not become ready to use: %v", nfsexportID, err)
+	}
+}
+
+func (s *suite) testCreateIdempotent(t *testing.T) {
+	name := s.name()
+	nfsexportID := s.mustCreate(t, name)
+
+	// The sidecar retries CreateNfsExport with the same name after timeouts,
+	// so a second call must return the nfsexport created by the first one.
+	_, secondID, _, _, _, err := s.create(name, s.config.SourceVolumeHandle, s.config.Secrets)
+	if err != nil {
+		t.Fatalf("second CreateNfsExport %s failed: %v", name, err)
+	}
+	if secondID != nfsexportID {
+		if secondID != "" {
+			t.Cleanup(func() { s.delete(secondID) })
+		}
+		t.Fatalf("second CreateNfsExport %s returned nfsexport ID %q, expected %q", name, secondID, nfsexportID)
+	}
+}
+
+func (s *suite) testCreateFromMissingVolume(t *testing.T) {
+	name := s.name()
+	volumeHandle := name + "-missing-volume"
+	_, nfsexportID, _, _, _, err := s.create(name, volumeHandle, s.config.Secrets)
+	if err == nil {
+		t.Cleanup(func() { s.delete(nfsexportID) })
+		t.Fatalf("CreateNfsExport from missing volume %s succeeded, expected an error", volumeHandle)
+	}
+	expectFinalError(t, "CreateNfsExport from missing volume", err)
+}
+
+func (s *suite) testCreateWithoutSecrets(t *testing.T) {
+	name := s.name()
+	_, nfsexportID, _, _, _, err := s.create(name, s.config.SourceVolumeHandle, nil)
+	if err == nil {
+		t.Cleanup(func() { s.delete(nfsexportID) })
+		t.Fatalf("CreateNfsExport %s without secrets succeeded, expected an error", name)
+	}
+	expectFinalError(t, "CreateNfsExport without secrets", err)
+}
+
+func (s *suite) testDeleteIdempotent(t *testing.T) {
+	name := s.name()
+	driverName, nfsexportID, _, _, _, err := s.create(name, s.config.SourceVolumeHandle, s.config.Secrets)
+	if err != nil {
+		t.Fatalf("CreateNfsExport %s failed: %v", name, err)
+	}
+	if s.config.DriverName != "" && driverName != s.config.DriverName {
+		t.Errorf("CreateNfsExport %s returned driver name %q, expected %q", name, driverName, s.config.DriverName)
+	}
+	if err := s.delete(nfsexportID); err != nil {
+		t.Fatalf("DeleteNfsExport %s failed: %v", nfsexportID, err)
+	}
+	if err := s.delete(nfsexportID); err != nil {
+		t.Errorf("second DeleteNfsExport %s failed, expected success: %v", nfsexportID, err)
+	}
+}
+
+func (s *suite) testDeleteMissing(t *testing.T) {
+	nfsexportID := s.name() + "-missing-nfsexport"
+	if err := s.delete(nfsexportID); err != nil {
+		t.Errorf("DeleteNfsExport of missing nfsexport %s failed, expected success: %v", nfsexportID, err)
+	}
+}
+
+// expectFinalError fails t unless err is a gRPC status error with a code the
+// sidecar treats as final.
+func expectFinalError(t *testing.T, call string, err error) {
+	if !isFinalError(err) {
+		t.Errorf("%s returned %v, expected a gRPC error with a final code", call, err)
+	}
+}
+
+// isFinalError mirrors the sidecar's classification of CreateNfsExport
+// errors. A non-gRPC error or a non-final code makes the sidecar assume the
+// operation may still be in progress and keep retrying it.
+func isFinalError(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	switch st.Code() {
+	case codes.OK, codes.Canceled, codes.DeadlineExceeded, codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return false
+	}
+	return true
+}
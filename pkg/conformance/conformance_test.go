@@ -0,0 +1,123 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeDriver is an in-memory NfsExportter that follows the semantics checked
+// by Run.
+type fakeDriver struct {
+	lock       sync.Mutex
+	volumes    map[string]bool
+	secretKey  string
+	nfsexports map[string]string // name -> volume handle
+}
+
+func (d *fakeDriver) checkSecrets(secrets map[string]string) error {
+	if d.secretKey != "" && secrets[d.secretKey] == "" {
+		return status.Error(codes.InvalidArgument, "missing secret")
+	}
+	return nil
+}
+
+func (d *fakeDriver) CreateNfsExport(ctx context.Context, nfsexportName string, volumeHandle string, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if err := d.checkSecrets(nfsexporterCredentials); err != nil {
+		return "", "", time.Time{}, 0, false, err
+	}
+	if !d.volumes[volumeHandle] {
+		return "", "", time.Time{}, 0, false, status.Error(codes.NotFound, "volume not found")
+	}
+	if source, ok := d.nfsexports[nfsexportName]; ok && source != volumeHandle {
+		return "", "", time.Time{}, 0, false, status.Error(codes.AlreadyExists, "nfsexport exists for another volume")
+	}
+	d.nfsexports[nfsexportName] = volumeHandle
+	return "fake.csi.k8s.io", "id-" + nfsexportName, time.Now(), 0, true, nil
+}
+
+func (d *fakeDriver) DeleteNfsExport(ctx context.Context, nfsexportID string, nfsexporterCredentials map[string]string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if err := d.checkSecrets(nfsexporterCredentials); err != nil {
+		return err
+	}
+	for name := range d.nfsexports {
+		if "id-"+name == nfsexportID {
+			delete(d.nfsexports, name)
+		}
+	}
+	return nil
+}
+
+func (d *fakeDriver) GetNfsExportStatus(ctx context.Context, nfsexportID string, nfsexporterListCredentials map[string]string) (bool, time.Time, int64, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for name := range d.nfsexports {
+		if "id-"+name == nfsexportID {
+			return true, time.Now(), 0, nil
+		}
+	}
+	return false, time.Time{}, 0, status.Error(codes.NotFound, "nfsexport not found")
+}
+
+func TestRun(t *testing.T) {
+	driver := &fakeDriver{
+		volumes:    map[string]bool{"volume1": true},
+		secretKey:  "password",
+		nfsexports: map[string]string{},
+	}
+	Run(t, Config{
+		NfsExportter:       driver,
+		DriverName:         "fake.csi.k8s.io",
+		SourceVolumeHandle: "volume1",
+		Secrets:            map[string]string{"password": "secret"},
+		SecretsRequired:    true,
+		PollInterval:       time.Millisecond,
+	})
+	if len(driver.nfsexports) != 0 {
+		t.Errorf("expected all nfsexports to be cleaned up, got %v", driver.nfsexports)
+	}
+}
+
+func TestIsFinalError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{err: status.Error(codes.NotFound, "not found"), expected: true},
+		{err: status.Error(codes.InvalidArgument, "invalid"), expected: true},
+		{err: status.Error(codes.Unavailable, "unavailable"), expected: false},
+		{err: status.Error(codes.DeadlineExceeded, "timeout"), expected: false},
+		{err: status.Error(codes.Aborted, "pending"), expected: false},
+		{err: errors.New("not a gRPC error"), expected: false},
+	}
+	for _, test := range tests {
+		if got := isFinalError(test.err); got != test.expected {
+			t.Errorf("isFinalError(%v) = %v, expected %v", test.err, got, test.expected)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentview
+
+import (
+	"context"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	klog "k8s.io/klog/v2"
+)
+
+// Design:
+//
+// The projector maintains one NfsExportContentView per VolumeNfsExport bound
+// to a VolumeNfsExportContent, with the name of the VolumeNfsExport and in
+// its namespace. The work queue is keyed by the namespace/name of the
+// VolumeNfsExport: events of a VolumeNfsExport, of the content bound to it
+// and of its view enqueue it, and a sync recomputes the view from the
+// informer cache. The view of a VolumeNfsExport that is deleted or not bound
+// is deleted. Views are owned by their VolumeNfsExport, so that they are
+// garbage collected with it even if the projector is not running.
+//
+// Users of a namespace read the views through a ClusterRole aggregated to the
+// view, edit and admin roles, so they need no access to the cluster scoped
+// VolumeNfsExportContents.
+
+// Projector maintains the NfsExportContentViews of the bound VolumeNfsExports.
+type Projector struct {
+	clientset clientset.Interface
+	queue     workqueue.RateLimitingInterface
+
+	nfsexportLister       storagelisters.VolumeNfsExportLister
+	nfsexportListerSynced cache.InformerSynced
+	contentLister         storagelisters.VolumeNfsExportContentLister
+	contentListerSynced   cache.InformerSynced
+	viewLister            storagelisters.NfsExportContentViewLister
+	viewListerSynced      cache.InformerSynced
+}
+
+// NewProjector returns a new *Projector that maintains the views of the
+// VolumeNfsExports of volumeNfsExportInformer with clientset.
+func NewProjector(
+	clientset clientset.Interface,
+	volumeNfsExportInformer storageinformers.VolumeNfsExportInformer,
+	volumeNfsExportContentInformer storageinformers.VolumeNfsExportContentInformer,
+	nfsExportContentViewInformer storageinformers.NfsExportContentViewInformer,
+	resyncPeriod time.Duration,
+	rateLimiter workqueue.RateLimiter,
+) *Projector {
+	p := &Projector{
+		clientset: clientset,
+		queue:     workqueue.NewNamedRateLimitingQueue(rateLimiter, "nfsexport-content-view"),
+	}
+
+	volumeNfsExportInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { p.enqueueNfsExport(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { p.enqueueNfsExport(newObj) },
+			DeleteFunc: func(obj interface{}) { p.enqueueNfsExport(obj) },
+		},
+		resyncPeriod,
+	)
+	p.nfsexportLister = volumeNfsExportInformer.Lister()
+	p.nfsexportListerSynced = volumeNfsExportInformer.Informer().HasSynced
+
+	volumeNfsExportContentInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { p.enqueueContentNfsExport(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { p.enqueueContentNfsExport(newObj) },
+			DeleteFunc: func(obj interface{}) { p.enqueueContentNfsExport(obj) },
+		},
+	)
+	p.contentLister = volumeNfsExportContentInformer.Lister()
+	p.contentListerSynced = volumeNfsExportContentInformer.Informer().HasSynced
+
+	// Views changed or deleted by someone else are repaired.
+	nfsExportContentViewInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) { p.enqueueNfsExport(newObj) },
+			DeleteFunc: func(obj interface{}) { p.enqueueNfsExport(obj) },
+		},
+	)
+	p.viewLister = nfsExportContentViewInformer.Lister()
+	p.viewListerSynced = nfsExportContentViewInformer.Informer().HasSynced
+
+	return p
+}
+
+// Run starts the view workers and blocks until stopCh is closed.
+func (p *Projector) Run(workers int, stopCh <-chan struct{}) {
+	defer p.queue.ShutDown()
+
+	klog.Infof("Starting nfsexport content view projector")
+	defer klog.Infof("Shutting nfsexport content view projector")
+
+	if !cache.WaitForCacheSync(stopCh, p.nfsexportListerSynced, p.contentListerSynced, p.viewListerSynced) {
+		klog.Errorf("Cannot sync caches")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(p.viewWorker, 0, stopCh)
+	}
+
+	<-stopCh
+}
+
+// enqueueNfsExport adds the key of a nfsexport, or of the nfsexport of a
+// view, to the work queue.
+func (p *Projector) enqueueNfsExport(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("failed to get key from object: %v, %v", err, obj)
+		return
+	}
+	klog.V(5).Infof("enqueued %q for content view", key)
+	p.queue.Add(key)
+}
+
+// enqueueContentNfsExport adds the nfsexport a content is bound to to the
+// work queue.
+func (p *Projector) enqueueContentNfsExport(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	content, ok := obj.(*crdv1.VolumeNfsExportContent)
+	if !ok || content.Spec.VolumeNfsExportRef.Name == "" {
+		return
+	}
+	key := utils.NfsExportRefKey(&content.Spec.VolumeNfsExportRef)
+	klog.V(5).Infof("enqueued %q for content view, content %s changed", key, content.Name)
+	p.queue.Add(key)
+}
+
+// viewWorker is the main worker for projecting contents.
+func (p *Projector) viewWorker() {
+	keyObj, quit := p.queue.Get()
+	if quit {
+		return
+	}
+	defer p.queue.Done(keyObj)
+
+	if err := p.syncView(keyObj.(string)); err != nil {
+		p.queue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to sync content view of nfsexport %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		p.queue.Forget(keyObj)
+	}
+}
+
+// syncView brings the view of a nfsexport in line with the content it is
+// bound to: it creates or updates the view of a bound nfsexport and deletes
+// the view of a nfsexport that is deleted or not bound.
+func (p *Projector) syncView(key string) error {
+	klog.V(5).Infof("syncView[%s]", key)
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		klog.Errorf("error getting namespace & name of nfsexport %q: %v", key, err)
+		return nil
+	}
+	nfsexport, err := p.nfsexportLister.VolumeNfsExports(namespace).Get(name)
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return err
+		}
+		nfsexport = nil
+	}
+	content, err := p.getBoundContent(nfsexport)
+	if err != nil {
+		return err
+	}
+	view, err := p.viewLister.NfsExportContentViews(namespace).Get(name)
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return err
+		}
+		view = nil
+	}
+
+	if content == nil {
+		if view == nil {
+			return nil
+		}
+		klog.V(4).Infof("deleting content view of nfsexport %s that is not bound", key)
+		err := p.clientset.NfsExportV1().NfsExportContentViews(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	status := project(content)
+	if view == nil {
+		klog.V(4).Infof("creating content view of nfsexport %s", key)
+		view, err = p.clientset.NfsExportV1().NfsExportContentViews(namespace).Create(context.TODO(), &crdv1.NfsExportContentView{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       namespace,
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(nfsexport, crdv1.SchemeGroupVersion.WithKind("VolumeNfsExport"))},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	if apiequality.Semantic.DeepEqual(view.Status, status) {
+		return nil
+	}
+
+	viewClone := view.DeepCopy()
+	viewClone.Status = status
+	if _, err := p.clientset.NfsExportV1().NfsExportContentViews(namespace).UpdateStatus(context.TODO(), viewClone, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	klog.V(5).Infof("updated content view of nfsexport %s", key)
+	return nil
+}
+
+// getBoundContent returns the content nfsexport is bound to, or nil if the
+// nfsexport is nil, being deleted or not bound.
+func (p *Projector) getBoundContent(nfsexport *crdv1.VolumeNfsExport) (*crdv1.VolumeNfsExportContent, error) {
+	if nfsexport == nil || nfsexport.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	if nfsexport.Status == nil || nfsexport.Status.BoundVolumeNfsExportContentName == nil {
+		return nil, nil
+	}
+	content, err := p.contentLister.Get(*nfsexport.Status.BoundVolumeNfsExportContentName)
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// The content must point back to the nfsexport, otherwise the user of
+	// the namespace could see the content of another nfsexport.
+	if content.Spec.VolumeNfsExportRef.UID != nfsexport.UID {
+		return nil, nil
+	}
+	return content, nil
+}
+
+// project returns the view of content. Only the fields that are safe to show
+// to the users of the namespace of its nfsexport are copied.
+func project(content *crdv1.VolumeNfsExportContent) *crdv1.NfsExportContentViewStatus {
+	status := &crdv1.NfsExportContentViewStatus{}
+	if content.Status == nil {
+		return status
+	}
+	if content.Status.ReadyToUse != nil {
+		ready := *content.Status.ReadyToUse
+		status.ReadyToUse = &ready
+	}
+	if content.Status.RestoreSize != nil {
+		status.RestoreSize = resource.NewQuantity(*content.Status.RestoreSize, resource.BinarySI)
+	}
+	if content.Status.CreationTime != nil {
+		// Truncated to the precision of the API, so that an unchanged view
+		// compares equal.
+		creationTime := metav1.NewTime(time.Unix(0, *content.Status.CreationTime)).Rfc3339Copy()
+		status.CreationTime = &creationTime
+	}
+	if content.Status.NfsExportHandle != nil {
+		if server := utils.GetExportServerFromHandle(*content.Status.NfsExportHandle); server != "" {
+			status.Server = &server
+		}
+	}
+	if content.Status.ExportPath != nil {
+		exportPath := *content.Status.ExportPath
+		status.ExportPath = &exportPath
+	}
+	return status
+}
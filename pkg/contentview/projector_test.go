@@ -0,0 +1,210 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package contentview
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const testNamespace = "default"
+
+var (
+	True         = true
+	creationTime = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func newNfsExport(name, uid, contentName string) *crdv1.VolumeNfsExport {
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: types.UID(uid)},
+	}
+	if contentName != "" {
+		nfsexport.Status = &crdv1.VolumeNfsExportStatus{BoundVolumeNfsExportContentName: &contentName}
+	}
+	return nfsexport
+}
+
+func newContent(name, nfsexportUID, nfsexportName string) *crdv1.VolumeNfsExportContent {
+	handle := "server1:/exports/snap1"
+	exportPath := "/exports/snap1"
+	size := int64(1024 * 1024 * 1024)
+	created := creationTime.UnixNano()
+	return &crdv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: crdv1.VolumeNfsExportContentSpec{
+			VolumeNfsExportRef: v1.ObjectReference{
+				Namespace: testNamespace,
+				Name:      nfsexportName,
+				UID:       types.UID(nfsexportUID),
+			},
+		},
+		Status: &crdv1.VolumeNfsExportContentStatus{
+			NfsExportHandle: &handle,
+			ExportPath:      &exportPath,
+			RestoreSize:     &size,
+			ReadyToUse:      &True,
+			CreationTime:    &created,
+		},
+	}
+}
+
+func newView(name string, status *crdv1.NfsExportContentViewStatus) *crdv1.NfsExportContentView {
+	return &crdv1.NfsExportContentView{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status:     status,
+	}
+}
+
+func expectedStatus() *crdv1.NfsExportContentViewStatus {
+	server := "server1"
+	exportPath := "/exports/snap1"
+	size := resource.MustParse("1Gi")
+	created := metav1.NewTime(creationTime)
+	return &crdv1.NfsExportContentViewStatus{
+		ReadyToUse:   &True,
+		RestoreSize:  &size,
+		CreationTime: &created,
+		Server:       &server,
+		ExportPath:   &exportPath,
+	}
+}
+
+func newTestProjector(t *testing.T, nfsexports []*crdv1.VolumeNfsExport, contents []*crdv1.VolumeNfsExportContent, views []*crdv1.NfsExportContentView) (*Projector, *fake.Clientset) {
+	var objects []runtime.Object
+	for _, view := range views {
+		objects = append(objects, view)
+	}
+	client := fake.NewSimpleClientset(objects...)
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	nfsexportInformer := factory.NfsExport().V1().VolumeNfsExports()
+	contentInformer := factory.NfsExport().V1().VolumeNfsExportContents()
+	viewInformer := factory.NfsExport().V1().NfsExportContentViews()
+	p := NewProjector(client, nfsexportInformer, contentInformer, viewInformer, 0, workqueue.DefaultControllerRateLimiter())
+	for _, nfsexport := range nfsexports {
+		if err := nfsexportInformer.Informer().GetIndexer().Add(nfsexport); err != nil {
+			t.Fatalf("failed to add nfsexport %s to informer: %v", nfsexport.Name, err)
+		}
+	}
+	for _, content := range contents {
+		if err := contentInformer.Informer().GetIndexer().Add(content); err != nil {
+			t.Fatalf("failed to add content %s to informer: %v", content.Name, err)
+		}
+	}
+	for _, view := range views {
+		if err := viewInformer.Informer().GetIndexer().Add(view); err != nil {
+			t.Fatalf("failed to add view %s to informer: %v", view.Name, err)
+		}
+	}
+	return p, client
+}
+
+func TestSyncView(t *testing.T) {
+	tests := []struct {
+		name         string
+		nfsexports   []*crdv1.VolumeNfsExport
+		contents     []*crdv1.VolumeNfsExportContent
+		views        []*crdv1.NfsExportContentView
+		expectedView *crdv1.NfsExportContentView
+		expectUpdate bool
+	}{
+		{
+			name:         "view is created",
+			nfsexports:   []*crdv1.VolumeNfsExport{newNfsExport("snap1", "snapuid1", "content1")},
+			contents:     []*crdv1.VolumeNfsExportContent{newContent("content1", "snapuid1", "snap1")},
+			expectedView: newView("snap1", expectedStatus()),
+			expectUpdate: true,
+		},
+		{
+			name:         "outdated view is updated",
+			nfsexports:   []*crdv1.VolumeNfsExport{newNfsExport("snap1", "snapuid1", "content1")},
+			contents:     []*crdv1.VolumeNfsExportContent{newContent("content1", "snapuid1", "snap1")},
+			views:        []*crdv1.NfsExportContentView{newView("snap1", &crdv1.NfsExportContentViewStatus{})},
+			expectedView: newView("snap1", expectedStatus()),
+			expectUpdate: true,
+		},
+		{
+			name:         "up to date view is left alone",
+			nfsexports:   []*crdv1.VolumeNfsExport{newNfsExport("snap1", "snapuid1", "content1")},
+			contents:     []*crdv1.VolumeNfsExportContent{newContent("content1", "snapuid1", "snap1")},
+			views:        []*crdv1.NfsExportContentView{newView("snap1", expectedStatus())},
+			expectedView: newView("snap1", expectedStatus()),
+		},
+		{
+			name:       "no view of a nfsexport that is not bound",
+			nfsexports: []*crdv1.VolumeNfsExport{newNfsExport("snap1", "snapuid1", "")},
+		},
+		{
+			name:       "no view of a content bound to another nfsexport",
+			nfsexports: []*crdv1.VolumeNfsExport{newNfsExport("snap1", "snapuid1", "content1")},
+			contents:   []*crdv1.VolumeNfsExportContent{newContent("content1", "snapuid2", "snap2")},
+		},
+		{
+			name:  "view of a deleted nfsexport is deleted",
+			views: []*crdv1.NfsExportContentView{newView("snap1", expectedStatus())},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, client := newTestProjector(t, test.nfsexports, test.contents, test.views)
+			if err := p.syncView(testNamespace + "/snap1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			view, err := client.NfsExportV1().NfsExportContentViews(testNamespace).Get(context.TODO(), "snap1", metav1.GetOptions{})
+			if test.expectedView == nil {
+				if !apierrs.IsNotFound(err) {
+					t.Errorf("expected no view, got %+v, %v", view, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get view: %v", err)
+			}
+			if !apiequality.Semantic.DeepEqual(view.Status, test.expectedView.Status) {
+				t.Errorf("expected view status %+v, got %+v", test.expectedView.Status, view.Status)
+			}
+			if test.views == nil && (len(view.OwnerReferences) != 1 || view.OwnerReferences[0].UID != "snapuid1") {
+				t.Errorf("expected the view to be owned by its nfsexport, got %+v", view.OwnerReferences)
+			}
+
+			updated := false
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+					updated = true
+				}
+			}
+			if updated != test.expectUpdate {
+				t.Errorf("expected status update %v, got %v", test.expectUpdate, updated)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package crds installs the CustomResourceDefinitions bundled with the
+// client, or upgrades the installed ones to them, for deployments where no
+// operator manages the CRDs.
+package crds
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/config/crd"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	klog "k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// crdPath is the API path of the CustomResourceDefinitions.
+const crdPath = "/apis/apiextensions.k8s.io/v1/customresourcedefinitions"
+
+// Bundled returns the CustomResourceDefinitions bundled with the client,
+// sorted by file name.
+func Bundled() ([]*unstructured.Unstructured, error) {
+	files, err := fs.Glob(crd.FS, "*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	var crds []*unstructured.Unstructured
+	for _, file := range files {
+		data, err := fs.ReadFile(crd.FS, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+		jsonData, err := yaml.YAMLToJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+		}
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(jsonData); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", file, err)
+		}
+		crds = append(crds, obj)
+	}
+	return crds, nil
+}
+
+// Ensure creates the bundled CustomResourceDefinitions that are not
+// installed, and updates the installed ones to the bundled version. client
+// must be able to send requests to any path of the API server, e.g. the REST
+// client of a discovery client.
+//
+// An installed CRD is not updated if it serves a version that is not bundled,
+// as it is newer than the bundled one. Ensure fails if an installed CRD has
+// stored objects in a version that is not bundled: the objects must be
+// migrated to a bundled version, and the version removed from the
+// status.storedVersions of the CRD, before it can be updated.
+func Ensure(ctx context.Context, client rest.Interface) error {
+	crds, err := Bundled()
+	if err != nil {
+		return err
+	}
+	for _, crd := range crds {
+		if err := ensure(ctx, client, crd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensure(ctx context.Context, client rest.Interface, crd *unstructured.Unstructured) error {
+	name := crd.GetName()
+	body, err := client.Get().AbsPath(crdPath, name).DoRaw(ctx)
+	if apierrors.IsNotFound(err) {
+		created := crd.DeepCopy()
+		unstructured.RemoveNestedField(created.Object, "status")
+		if err := send(ctx, client.Post().AbsPath(crdPath), created); err != nil {
+			return fmt.Errorf("failed to create CustomResourceDefinition %s: %v", name, err)
+		}
+		klog.Infof("Created CustomResourceDefinition %s", name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get CustomResourceDefinition %s: %v", name, err)
+	}
+	existing := &unstructured.Unstructured{}
+	if err := existing.UnmarshalJSON(body); err != nil {
+		return fmt.Errorf("failed to decode CustomResourceDefinition %s: %v", name, err)
+	}
+
+	bundledVersions, _ := versions(crd)
+	installedVersions, servedVersions := versions(existing)
+	if newer := servedVersions.Difference(bundledVersions); newer.Len() > 0 {
+		klog.Warningf("CustomResourceDefinition %s serves versions %v that are not bundled, it is newer than the bundled one and is not updated", name, newer.List())
+		return nil
+	}
+	storedVersions, _, _ := unstructured.NestedStringSlice(existing.Object, "status", "storedVersions")
+	if missing := sets.NewString(storedVersions...).Difference(bundledVersions); missing.Len() > 0 {
+		return fmt.Errorf("CustomResourceDefinition %s has objects stored in versions %v that are not bundled, migrate them to a bundled version first", name, missing.List())
+	}
+
+	updated := crd.DeepCopy()
+	unstructured.RemoveNestedField(updated.Object, "status")
+	updated.SetResourceVersion(existing.GetResourceVersion())
+	updated.SetLabels(merge(existing.GetLabels(), crd.GetLabels()))
+	updated.SetAnnotations(merge(existing.GetAnnotations(), crd.GetAnnotations()))
+	if err := send(ctx, client.Put().AbsPath(crdPath, name), updated); err != nil {
+		return fmt.Errorf("failed to update CustomResourceDefinition %s: %v", name, err)
+	}
+	klog.Infof("Updated CustomResourceDefinition %s from versions %v to versions %v", name, installedVersions.List(), bundledVersions.List())
+	return nil
+}
+
+// send sends obj as the body of request.
+func send(ctx context.Context, request *rest.Request, obj *unstructured.Unstructured) error {
+	data, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return request.SetHeader("Content-Type", "application/json").Body(data).Do(ctx).Error()
+}
+
+// versions returns the names of all the versions of a CRD, and of the served
+// ones.
+func versions(crd *unstructured.Unstructured) (all sets.String, served sets.String) {
+	all, served = sets.NewString(), sets.NewString()
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(version, "name")
+		all.Insert(name)
+		if isServed, _, _ := unstructured.NestedBool(version, "served"); isServed {
+			served.Insert(name)
+		}
+	}
+	return all, served
+}
+
+// merge returns the entries of installed overridden by the ones of bundled.
+func merge(installed, bundled map[string]string) map[string]string {
+	ret := make(map[string]string, len(installed)+len(bundled))
+	for k, v := range installed {
+		ret[k] = v
+	}
+	for k, v := range bundled {
+		ret[k] = v
+	}
+	return ret
+}
@@ -0,0 +1,220 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package crds
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const nfsexportCRD = "volumenfsexports.nfsexport.storage.k8s.io"
+
+// fakeAPIServer serves CustomResourceDefinitions from memory.
+type fakeAPIServer struct {
+	lock    sync.Mutex
+	crds    map[string]map[string]interface{}
+	updates []string
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	name := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, crdPath), "/")
+	w.Header().Set("Content-Type", "application/json")
+	switch r.Method {
+	case http.MethodGet:
+		crd, ok := s.crds[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(&metav1.Status{
+				TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+				Status:   metav1.StatusFailure,
+				Reason:   metav1.StatusReasonNotFound,
+				Code:     http.StatusNotFound,
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(crd)
+	case http.MethodPost, http.MethodPut:
+		body, _ := ioutil.ReadAll(r.Body)
+		obj := map[string]interface{}{}
+		json.Unmarshal(body, &obj)
+		name, _, _ := unstructured.NestedString(obj, "metadata", "name")
+		s.crds[name] = obj
+		s.updates = append(s.updates, r.Method+" "+name)
+		w.Write(body)
+	}
+}
+
+func newClient(t *testing.T, server *fakeAPIServer) rest.Interface {
+	srv := httptest.NewServer(server)
+	t.Cleanup(srv.Close)
+	kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kubeClient.Discovery().RESTClient()
+}
+
+func installed(t *testing.T, resourceVersion string, versions []interface{}, storedVersions ...interface{}) map[string]interface{} {
+	crds, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, crd := range crds {
+		if crd.GetName() == nfsexportCRD {
+			crd.SetResourceVersion(resourceVersion)
+			crd.SetAnnotations(map[string]string{"installed-by": "admin"})
+			if versions != nil {
+				unstructured.SetNestedSlice(crd.Object, versions, "spec", "versions")
+			}
+			unstructured.SetNestedSlice(crd.Object, storedVersions, "status", "storedVersions")
+			return crd.Object
+		}
+	}
+	t.Fatalf("CustomResourceDefinition %s is not bundled", nfsexportCRD)
+	return nil
+}
+
+func TestBundled(t *testing.T) {
+	crds, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, crd := range crds {
+		if crd.GetKind() != "CustomResourceDefinition" {
+			t.Errorf("expected a CustomResourceDefinition, got %s", crd.GetKind())
+		}
+		names = append(names, crd.GetName())
+	}
+	expected := []string{
+		"nfsexportcontentviews.nfsexport.storage.k8s.io",
+		"nfsexportmounts.nfsexport.storage.k8s.io",
+		"nfsexportsets.nfsexport.storage.k8s.io",
+		"nfsexportsummaries.nfsexport.storage.k8s.io",
+		"volumenfsexportclasses.nfsexport.storage.k8s.io",
+		"volumenfsexportcontents.nfsexport.storage.k8s.io",
+		nfsexportCRD,
+	}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected CustomResourceDefinitions %v, got %v", expected, names)
+	}
+
+	// Contents can be listed by driver with a field selector.
+	for _, crd := range crds {
+		if crd.GetName() != "volumenfsexportcontents.nfsexport.storage.k8s.io" {
+			continue
+		}
+		versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+		for _, version := range versions {
+			version := version.(map[string]interface{})
+			if version["name"] != "v1" {
+				continue
+			}
+			fields, _, _ := unstructured.NestedSlice(version, "selectableFields")
+			if len(fields) != 1 || fields[0].(map[string]interface{})["jsonPath"] != ".spec.driver" {
+				t.Errorf("expected .spec.driver to be the selectable field of VolumeNfsExportContents, got %v", fields)
+			}
+		}
+	}
+}
+
+func TestEnsureCreates(t *testing.T) {
+	server := &fakeAPIServer{crds: map[string]map[string]interface{}{}}
+	if err := Ensure(context.TODO(), newClient(t, server)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(server.updates) != 7 {
+		t.Fatalf("expected the 7 CustomResourceDefinitions to be created, got %v", server.updates)
+	}
+	for _, update := range server.updates {
+		if !strings.HasPrefix(update, http.MethodPost) {
+			t.Errorf("expected a create, got %s", update)
+		}
+	}
+	if _, ok := server.crds[nfsexportCRD]["status"]; ok {
+		t.Errorf("expected the status not to be sent")
+	}
+}
+
+func TestEnsureUpdates(t *testing.T) {
+	server := &fakeAPIServer{crds: map[string]map[string]interface{}{
+		nfsexportCRD: installed(t, "42", []interface{}{
+			map[string]interface{}{"name": "v1beta1", "served": true, "storage": false},
+			map[string]interface{}{"name": "v1", "served": true, "storage": true},
+		}, "v1beta1", "v1"),
+	}}
+	if err := Ensure(context.TODO(), newClient(t, server)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !utils.ContainsString(server.updates, http.MethodPut+" "+nfsexportCRD) {
+		t.Fatalf("expected %s to be updated, got %v", nfsexportCRD, server.updates)
+	}
+	updated := &unstructured.Unstructured{Object: server.crds[nfsexportCRD]}
+	if updated.GetResourceVersion() != "42" {
+		t.Errorf("expected the update to be based on resource version 42, got %q", updated.GetResourceVersion())
+	}
+	if updated.GetAnnotations()["installed-by"] != "admin" {
+		t.Errorf("expected installed annotations to be kept, got %v", updated.GetAnnotations())
+	}
+	_, served := versions(updated)
+	if served.Has("v1beta1") || !served.Has("v1") {
+		t.Errorf("expected only v1 to be served, got %v", served.List())
+	}
+}
+
+func TestEnsureStoredVersions(t *testing.T) {
+	server := &fakeAPIServer{crds: map[string]map[string]interface{}{
+		nfsexportCRD: installed(t, "42", nil, "v1alpha1", "v1"),
+	}}
+	err := Ensure(context.TODO(), newClient(t, server))
+	if err == nil || !strings.Contains(err.Error(), "v1alpha1") {
+		t.Fatalf("expected an error about the stored version v1alpha1, got %v", err)
+	}
+	if utils.ContainsString(server.updates, http.MethodPut+" "+nfsexportCRD) {
+		t.Errorf("expected %s not to be updated", nfsexportCRD)
+	}
+}
+
+func TestEnsureKeepsNewer(t *testing.T) {
+	server := &fakeAPIServer{crds: map[string]map[string]interface{}{
+		nfsexportCRD: installed(t, "42", []interface{}{
+			map[string]interface{}{"name": "v1", "served": true, "storage": false},
+			map[string]interface{}{"name": "v2", "served": true, "storage": true},
+		}, "v1", "v2"),
+	}}
+	if err := Ensure(context.TODO(), newClient(t, server)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if utils.ContainsString(server.updates, http.MethodPut+" "+nfsexportCRD) {
+		t.Errorf("expected %s not to be updated, got %v", nfsexportCRD, server.updates)
+	}
+}
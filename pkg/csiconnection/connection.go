@@ -0,0 +1,226 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package csiconnection connects the csi-nfsexporter to a CSI driver. The
+// driver is usually reached through a unix socket shared with the driver
+// container of the same pod. When the csi-nfsexporter runs as a standalone
+// deployment, the driver is reached either through a unix socket on a shared
+// volume or through a tcp:// endpoint secured with TLS, or explicitly
+// insecure.
+package csiconnection
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-csi/csi-lib-utils/connection"
+	"github.com/kubernetes-csi/csi-lib-utils/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	klog "k8s.io/klog/v2"
+)
+
+const tcpPrefix = "tcp://"
+
+// Options configures the connection to a tcp:// endpoint. They are ignored
+// for unix sockets, except ReconnectOnConnectionLoss.
+type Options struct {
+	// CAFile is the PEM file of the certificate authorities used to verify
+	// the CSI driver. TLS is used when it is set. Either CAFile or Insecure
+	// must be set.
+	CAFile string
+	// Insecure connects to the CSI driver without TLS. It must be set
+	// explicitly, as the CSI calls carry secrets.
+	Insecure bool
+	// CertFile and KeyFile are the PEM files of the client certificate and
+	// key presented to the CSI driver, for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the name used to verify the certificate of the
+	// CSI driver. It defaults to the host of the endpoint.
+	ServerName string
+	// KeepaliveTime is the interval of the keepalive pings sent to the CSI
+	// driver, so that a dead connection is detected and re-established.
+	// 0 disables keepalive pings.
+	KeepaliveTime time.Duration
+	// DialTimeout is how long Connect waits for the connection to a tcp://
+	// endpoint to succeed. 0 waits forever.
+	DialTimeout time.Duration
+	// ReconnectOnConnectionLoss re-establishes a lost connection to a unix
+	// socket, e.g. when the CSI driver container restarts, instead of
+	// exiting. Lost connections to a tcp:// endpoint are always
+	// re-established.
+	ReconnectOnConnectionLoss bool
+}
+
+// IsRemote returns true if address is a tcp:// endpoint.
+func IsRemote(address string) bool {
+	return strings.HasPrefix(address, tcpPrefix)
+}
+
+// Connect connects to the CSI driver at address, which is either a unix
+// socket path, a unix:// URL or a tcp:// endpoint. It blocks until the
+// connection succeeds, or opts.DialTimeout passes for a tcp:// endpoint. The
+// csi-nfsexporter exits when the connection to a
+// unix socket is lost unless opts.ReconnectOnConnectionLoss is set, gRPC
+// re-establishes lost connections to a tcp:// endpoint.
+func Connect(address string, metricsManager metrics.CSIMetricsManager, opts Options) (*grpc.ClientConn, error) {
+	if !IsRemote(address) {
+		if opts.CAFile != "" || opts.CertFile != "" || opts.KeyFile != "" || opts.Insecure {
+			return nil, fmt.Errorf("TLS options are only supported for %s endpoints, got %q", tcpPrefix, address)
+		}
+		onConnectionLoss := connection.ExitOnConnectionLoss()
+		if opts.ReconnectOnConnectionLoss {
+			onConnectionLoss = func() bool { return true }
+		}
+		return connection.Connect(address, metricsManager, connection.OnConnectionLoss(onConnectionLoss))
+	}
+
+	target := strings.TrimPrefix(address, tcpPrefix)
+	dialOptions, err := dialOptions(target, metricsManager, opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	if opts.DialTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.DialTimeout)
+		defer cancel()
+	}
+	klog.V(5).Infof("Connecting to %s", address)
+	conn, err := grpc.DialContext(ctx, target, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", address, err)
+	}
+	return conn, nil
+}
+
+// WatchReconnects calls onReconnect each time conn is ready again after the
+// connection to the CSI driver was lost, e.g. because the driver restarted
+// and forgot the calls in flight. It returns when stopCh is closed or conn is
+// closed.
+func WatchReconnects(conn *grpc.ClientConn, stopCh <-chan struct{}, onReconnect func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	connected, lost := false, false
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			if lost {
+				klog.Infof("Reconnected to the CSI driver")
+				onReconnect()
+			}
+			connected, lost = true, false
+		case connectivity.Shutdown:
+			return
+		default:
+			if connected && !lost {
+				klog.Warningf("Lost connection to the CSI driver, state %s", state)
+				lost = true
+			}
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return
+		}
+	}
+}
+
+// dialOptions returns the options to dial the tcp:// endpoint target.
+func dialOptions(target string, metricsManager metrics.CSIMetricsManager, opts Options) ([]grpc.DialOption, error) {
+	connectParams := grpc.ConnectParams{Backoff: backoff.DefaultConfig}
+	connectParams.Backoff.MaxDelay = time.Second // Retry every second after failure.
+	dialOptions := []grpc.DialOption{
+		grpc.WithConnectParams(connectParams),
+		grpc.WithBlock(), // Block until connection succeeds.
+		grpc.WithChainUnaryInterceptor(
+			connection.LogGRPC, // Log all messages.
+			connection.ExtendedCSIMetricsManager{CSIMetricsManager: metricsManager}.RecordMetricsClientInterceptor, // Record metrics for each gRPC call.
+		),
+	}
+	if opts.KeepaliveTime > 0 {
+		dialOptions = append(dialOptions, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                opts.KeepaliveTime,
+			PermitWithoutStream: true,
+		}))
+	}
+
+	if opts.Insecure {
+		if opts.CAFile != "" || opts.CertFile != "" || opts.KeyFile != "" {
+			return nil, fmt.Errorf("TLS files cannot be used with an insecure connection")
+		}
+		klog.Warningf("Connecting to CSI driver at %s without TLS", target)
+		return append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials())), nil
+	}
+	if opts.CAFile == "" {
+		return nil, fmt.Errorf("a CA file is required to connect to %s%s, unless the connection is explicitly insecure", tcpPrefix, target)
+	}
+	tlsConfig, err := tlsConfig(target, opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))), nil
+}
+
+// tlsConfig returns the TLS configuration to connect to the tcp:// endpoint
+// target.
+func tlsConfig(target string, opts Options) (*tls.Config, error) {
+	caData, err := os.ReadFile(opts.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caData) {
+		return nil, fmt.Errorf("no certificate found in CA file %s", opts.CAFile)
+	}
+	config := &tls.Config{
+		RootCAs:    pool,
+		ServerName: opts.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if config.ServerName == "" {
+		config.ServerName = target
+		if host, _, err := net.SplitHostPort(target); err == nil {
+			config.ServerName = host
+		}
+	}
+	if opts.CertFile != "" || opts.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,234 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package csiconnection
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-csi/csi-lib-utils/metrics"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+func TestIsRemote(t *testing.T) {
+	tests := map[string]bool{
+		"/run/csi/socket":        false,
+		"unix:///run/csi/socket": false,
+		"tcp://csi-driver:10000": true,
+	}
+	for address, expected := range tests {
+		if remote := IsRemote(address); remote != expected {
+			t.Errorf("%s: expected remote %v, got %v", address, expected, remote)
+		}
+	}
+}
+
+func TestConnectTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := grpc.NewServer()
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := Connect("tcp://"+listener.Addr().String(), metrics.NewCSIMetricsManager("csi-mock-plugin"), Options{Insecure: true, KeepaliveTime: time.Minute})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+	if state := conn.GetState(); state != connectivity.Ready {
+		t.Errorf("expected a ready connection, got %s", state)
+	}
+}
+
+func TestWatchReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	server := grpc.NewServer()
+	go server.Serve(listener)
+
+	conn, err := Connect("tcp://"+address, metrics.NewCSIMetricsManager("csi-mock-plugin"), Options{Insecure: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer conn.Close()
+
+	reconnected := make(chan struct{}, 1)
+	stopCh := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		WatchReconnects(conn, stopCh, func() { reconnected <- struct{}{} })
+		close(done)
+	}()
+
+	// Restart the driver.
+	server.Stop()
+	for conn.GetState() == connectivity.Ready {
+		time.Sleep(10 * time.Millisecond)
+	}
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Fatalf("failed to listen again: %v", err)
+	}
+	server = grpc.NewServer()
+	go server.Serve(listener)
+	defer server.Stop()
+
+	select {
+	case <-reconnected:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected onReconnect to be called after the driver restarted")
+	}
+
+	close(stopCh)
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Errorf("expected WatchReconnects to return once stopped")
+	}
+}
+
+func TestConnectErrors(t *testing.T) {
+	caFile := writeCA(t)
+	tests := []struct {
+		name    string
+		address string
+		opts    Options
+	}{
+		{
+			name:    "TLS with a unix socket",
+			address: "/run/csi/socket",
+			opts:    Options{CAFile: caFile},
+		},
+		{
+			name:    "insecure with a unix socket",
+			address: "/run/csi/socket",
+			opts:    Options{Insecure: true},
+		},
+		{
+			name:    "neither TLS nor insecure",
+			address: "tcp://csi-driver:10000",
+		},
+		{
+			name:    "insecure with a CA",
+			address: "tcp://csi-driver:10000",
+			opts:    Options{CAFile: caFile, Insecure: true},
+		},
+		{
+			name:    "client certificate without CA",
+			address: "tcp://csi-driver:10000",
+			opts:    Options{CertFile: "tls.crt", KeyFile: "tls.key"},
+		},
+		{
+			name:    "missing CA file",
+			address: "tcp://csi-driver:10000",
+			opts:    Options{CAFile: filepath.Join(t.TempDir(), "missing.crt")},
+		},
+		{
+			name:    "missing client certificate",
+			address: "tcp://csi-driver:10000",
+			opts:    Options{CAFile: caFile, CertFile: "missing.crt", KeyFile: "missing.key"},
+		},
+	}
+	for _, test := range tests {
+		if _, err := Connect(test.address, metrics.NewCSIMetricsManager("csi-mock-plugin"), test.opts); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestConnectDialTimeout(t *testing.T) {
+	// Nothing listens on the address once the listener is closed.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	start := time.Now()
+	if _, err := Connect("tcp://"+address, metrics.NewCSIMetricsManager("csi-mock-plugin"), Options{Insecure: true, DialTimeout: 100 * time.Millisecond}); err == nil {
+		t.Errorf("expected an error")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected Connect to give up after the dial timeout, took %v", elapsed)
+	}
+}
+
+func TestTLSConfigServerName(t *testing.T) {
+	caFile := writeCA(t)
+	tests := []struct {
+		target     string
+		serverName string
+		expected   string
+	}{
+		{target: "csi-driver:10000", expected: "csi-driver"},
+		{target: "[fd00::1]:10000", expected: "fd00::1"},
+		{target: "csi-driver:10000", serverName: "csi-driver.storage.svc", expected: "csi-driver.storage.svc"},
+	}
+	for _, test := range tests {
+		config, err := tlsConfig(test.target, Options{CAFile: caFile, ServerName: test.serverName})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.target, err)
+		}
+		if config.ServerName != test.expected {
+			t.Errorf("%s: expected server name %q, got %q", test.target, test.expected, config.ServerName)
+		}
+	}
+}
+
+// writeCA writes a self-signed CA certificate to a temporary file and returns
+// its path.
+func writeCA(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "csi-driver-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	return path
+}
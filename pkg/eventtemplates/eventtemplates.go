@@ -0,0 +1,150 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package eventtemplates customizes the messages of the events emitted by the
+// controllers, e.g. to link them to the runbooks of a platform, without
+// changing the controllers. Templates are read from a YAML file mapping event
+// reasons to Go text templates:
+//
+//	NfsExportCreationFailed: "{{.Message}} See https://runbooks.example.com/{{.Reason}}"
+//	NfsExportDeletePending: "Export {{.Namespace}}/{{.Name}} is still used to restore a PVC"
+//
+// The reasons of the events are never changed, automation can keep matching
+// them. Events whose reason has no template keep their message.
+package eventtemplates
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"reflect"
+	"text/template"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	klog "k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// Data is what a template is executed with.
+type Data struct {
+	// Type is Normal or Warning.
+	Type   string
+	Reason string
+	// Message is the message the controller would have emitted.
+	Message string
+	// Kind, Namespace and Name are those of the object of the event.
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// Templates are the message templates of the events, by reason.
+type Templates struct {
+	templates map[string]*template.Template
+}
+
+// Load reads the templates from the YAML file at path. It fails if a template
+// cannot be parsed.
+func Load(path string) (*Templates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sources map[string]string
+	if err := yaml.UnmarshalStrict(data, &sources); err != nil {
+		return nil, fmt.Errorf("failed to parse event templates %s: %v", path, err)
+	}
+	return Parse(sources)
+}
+
+// Parse returns the templates of sources, which maps reasons to Go text
+// templates.
+func Parse(sources map[string]string) (*Templates, error) {
+	t := &Templates{templates: make(map[string]*template.Template, len(sources))}
+	for reason, source := range sources {
+		tmpl, err := template.New(reason).Option("missingkey=error").Parse(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the template of event reason %s: %v", reason, err)
+		}
+		t.templates[reason] = tmpl
+	}
+	return t, nil
+}
+
+// Message returns the message of an event with the given data. It is the
+// default message of data if there is no template for its reason or if the
+// template fails.
+func (t *Templates) Message(data Data) string {
+	tmpl, ok := t.templates[data.Reason]
+	if !ok {
+		return data.Message
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		klog.Errorf("Failed to execute the template of event reason %s, using the default message: %v", data.Reason, err)
+		return data.Message
+	}
+	return out.String()
+}
+
+// NewRecorder returns a recorder emitting the events of recorder with their
+// messages replaced by templates.
+func NewRecorder(recorder record.EventRecorder, templates *Templates) record.EventRecorder {
+	return &templateRecorder{recorder: recorder, templates: templates}
+}
+
+type templateRecorder struct {
+	recorder  record.EventRecorder
+	templates *Templates
+}
+
+var _ record.EventRecorder = &templateRecorder{}
+
+func (r *templateRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.recorder.Event(object, eventtype, reason, r.message(object, eventtype, reason, message))
+}
+
+func (r *templateRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.Event(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *templateRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	message := r.message(object, eventtype, reason, fmt.Sprintf(messageFmt, args...))
+	r.recorder.AnnotatedEventf(object, annotations, eventtype, reason, "%s", message)
+}
+
+func (r *templateRecorder) message(object runtime.Object, eventtype, reason, message string) string {
+	data := Data{Type: eventtype, Reason: reason, Message: message}
+	// Objects read from the informers have no TypeMeta.
+	data.Kind = object.GetObjectKind().GroupVersionKind().Kind
+	if data.Kind == "" {
+		data.Kind = reflect.Indirect(reflect.ValueOf(object)).Type().Name()
+	}
+	// Events of an object known only by its reference, e.g. the
+	// VolumeNfsExport of a content in the sidecar, are emitted on the
+	// reference.
+	if ref, ok := object.(*v1.ObjectReference); ok {
+		data.Namespace = ref.Namespace
+		data.Name = ref.Name
+	} else if accessor, err := meta.Accessor(object); err == nil {
+		data.Namespace = accessor.GetNamespace()
+		data.Name = accessor.GetName()
+	}
+	return r.templates.Message(data)
+}
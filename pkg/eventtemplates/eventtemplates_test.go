@@ -0,0 +1,70 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package eventtemplates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestTemplateRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "templates.yaml")
+	templates := `
+NfsExportCreationFailed: "{{.Message}}. See https://runbooks.example.com/{{.Reason}}"
+NfsExportDeletePending: "{{.Kind}} {{.Namespace}}/{{.Name}} waits for a restore"
+Broken: "{{.Unknown}}"
+`
+	if err := os.WriteFile(path, []byte(templates), 0600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load templates: %v", err)
+	}
+	fake := record.NewFakeRecorder(10)
+	recorder := NewRecorder(fake, loaded)
+	nfsexport := &crdv1.VolumeNfsExport{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "snap1"}}
+
+	recorder.Eventf(nfsexport, v1.EventTypeWarning, "NfsExportCreationFailed", "Failed to create export: %s", "timeout")
+	recorder.Event(nfsexport, v1.EventTypeWarning, "NfsExportDeletePending", "NfsExport is being used to restore a PVC")
+	recorder.Event(nfsexport, v1.EventTypeNormal, "NfsExportReady", "NfsExport is ready to use")
+	recorder.Event(nfsexport, v1.EventTypeWarning, "Broken", "default message")
+	recorder.Event(&v1.ObjectReference{Kind: "VolumeNfsExport", Namespace: "team-a", Name: "snap2"}, v1.EventTypeWarning, "NfsExportDeletePending", "NfsExport is being used to restore a PVC")
+
+	expected := []string{
+		"Warning NfsExportCreationFailed Failed to create export: timeout. See https://runbooks.example.com/NfsExportCreationFailed",
+		"Warning NfsExportDeletePending VolumeNfsExport team-a/snap1 waits for a restore",
+		"Normal NfsExportReady NfsExport is ready to use",
+		"Warning Broken default message",
+		"Warning NfsExportDeletePending VolumeNfsExport team-a/snap2 waits for a restore",
+	}
+	for _, e := range expected {
+		if got := <-fake.Events; got != e {
+			t.Errorf("expected event %q, got %q", e, got)
+		}
+	}
+
+	if _, err := Parse(map[string]string{"Invalid": "{{.Message"}); err == nil {
+		t.Errorf("expected an error for an invalid template")
+	}
+}
@@ -0,0 +1,402 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exportset
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	klog "k8s.io/klog/v2"
+)
+
+// Design:
+//
+// The reconciler maintains the VolumeNfsExports of the NfsExportSets: one per
+// PersistentVolumeClaim of the namespace of a set matched by its selector,
+// created from its template with the claim as source. The work queue is
+// keyed by the namespace/name of the NfsExportSet: events of a set, of the
+// claims of its namespace and of the VolumeNfsExports it controls enqueue it,
+// and a sync compares the selected claims with the VolumeNfsExports the set
+// controls in the informer cache. VolumeNfsExports are created for the claims
+// that have none and deleted when their claim is no longer selected. The
+// VolumeNfsExports are matched to their claims by source, not by name, and
+// are owned by their set, so that they are garbage collected with it even if
+// the reconciler is not running.
+
+// nfsExportSetKind is the kind of the owner references of the
+// VolumeNfsExports of a NfsExportSet.
+const nfsExportSetKind = "NfsExportSet"
+
+// Reconciler maintains the VolumeNfsExports of the NfsExportSets.
+type Reconciler struct {
+	clientset clientset.Interface
+	queue     workqueue.RateLimitingInterface
+
+	setLister             storagelisters.NfsExportSetLister
+	setListerSynced       cache.InformerSynced
+	nfsexportLister       storagelisters.VolumeNfsExportLister
+	nfsexportListerSynced cache.InformerSynced
+	pvcLister             corelisters.PersistentVolumeClaimLister
+	pvcListerSynced       cache.InformerSynced
+}
+
+// NewReconciler returns a new *Reconciler that maintains the VolumeNfsExports
+// of the sets of nfsExportSetInformer with clientset.
+func NewReconciler(
+	clientset clientset.Interface,
+	nfsExportSetInformer storageinformers.NfsExportSetInformer,
+	volumeNfsExportInformer storageinformers.VolumeNfsExportInformer,
+	pvcInformer coreinformers.PersistentVolumeClaimInformer,
+	resyncPeriod time.Duration,
+	rateLimiter workqueue.RateLimiter,
+) *Reconciler {
+	r := &Reconciler{
+		clientset: clientset,
+		queue:     workqueue.NewNamedRateLimitingQueue(rateLimiter, "nfsexport-set"),
+	}
+
+	nfsExportSetInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { r.enqueueSet(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { r.enqueueSet(newObj) },
+		},
+		resyncPeriod,
+	)
+	r.setLister = nfsExportSetInformer.Lister()
+	r.setListerSynced = nfsExportSetInformer.Informer().HasSynced
+
+	volumeNfsExportInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { r.enqueueNfsExportSet(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { r.enqueueNfsExportSet(newObj) },
+			DeleteFunc: func(obj interface{}) { r.enqueueNfsExportSet(obj) },
+		},
+	)
+	r.nfsexportLister = volumeNfsExportInformer.Lister()
+	r.nfsexportListerSynced = volumeNfsExportInformer.Informer().HasSynced
+
+	pvcInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { r.enqueueClaimSets(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { r.enqueueClaimSets(newObj) },
+			DeleteFunc: func(obj interface{}) { r.enqueueClaimSets(obj) },
+		},
+	)
+	r.pvcLister = pvcInformer.Lister()
+	r.pvcListerSynced = pvcInformer.Informer().HasSynced
+
+	return r
+}
+
+// Run starts the set workers and blocks until stopCh is closed.
+func (r *Reconciler) Run(workers int, stopCh <-chan struct{}) {
+	defer r.queue.ShutDown()
+
+	klog.Infof("Starting nfsexport set reconciler")
+	defer klog.Infof("Shutting nfsexport set reconciler")
+
+	if !cache.WaitForCacheSync(stopCh, r.setListerSynced, r.nfsexportListerSynced, r.pvcListerSynced) {
+		klog.Errorf("Cannot sync caches")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(r.setWorker, 0, stopCh)
+	}
+
+	<-stopCh
+}
+
+// enqueueSet adds the key of a set to the work queue.
+func (r *Reconciler) enqueueSet(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.Errorf("failed to get key from object: %v, %v", err, obj)
+		return
+	}
+	klog.V(5).Infof("enqueued %q for nfsexport set", key)
+	r.queue.Add(key)
+}
+
+// enqueueNfsExportSet adds the set controlling a nfsexport, if any, to the
+// work queue.
+func (r *Reconciler) enqueueNfsExportSet(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	nfsexport, ok := obj.(*crdv1.VolumeNfsExport)
+	if !ok {
+		return
+	}
+	owner := metav1.GetControllerOf(nfsexport)
+	if owner == nil || owner.Kind != nfsExportSetKind || owner.APIVersion != crdv1.SchemeGroupVersion.String() {
+		return
+	}
+	key := nfsexport.Namespace + "/" + owner.Name
+	klog.V(5).Infof("enqueued %q for nfsexport set, nfsexport %s changed", key, nfsexport.Name)
+	r.queue.Add(key)
+}
+
+// enqueueClaimSets adds the sets of the namespace of a claim to the work
+// queue, the claim may start or stop matching their selectors.
+func (r *Reconciler) enqueueClaimSets(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	claim, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return
+	}
+	sets, err := r.setLister.NfsExportSets(claim.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list the nfsexport sets of namespace %s: %v", claim.Namespace, err)
+		return
+	}
+	for _, set := range sets {
+		r.enqueueSet(set)
+	}
+}
+
+// setWorker is the main worker for reconciling sets.
+func (r *Reconciler) setWorker() {
+	keyObj, quit := r.queue.Get()
+	if quit {
+		return
+	}
+	defer r.queue.Done(keyObj)
+
+	if err := r.syncSet(keyObj.(string)); err != nil {
+		r.queue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to sync nfsexport set %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		r.queue.Forget(keyObj)
+	}
+}
+
+// syncSet brings the VolumeNfsExports of a set in line with the claims it
+// selects: it creates the VolumeNfsExports of the selected claims that have
+// none and deletes the VolumeNfsExports of the claims that are no longer
+// selected, then updates the status of the set.
+func (r *Reconciler) syncSet(key string) error {
+	klog.V(5).Infof("syncSet[%s]", key)
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		klog.Errorf("error getting namespace & name of nfsexport set %q: %v", key, err)
+		return nil
+	}
+	set, err := r.setLister.NfsExportSets(namespace).Get(name)
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			// The VolumeNfsExports of the set are garbage collected.
+			return nil
+		}
+		return err
+	}
+	if set.DeletionTimestamp != nil {
+		return nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(&set.Spec.Selector)
+	if err != nil {
+		// Retrying does not help, the set is synced again when it changes.
+		klog.Errorf("invalid selector of nfsexport set %s: %v", key, err)
+		return nil
+	}
+
+	claims, err := r.pvcLister.PersistentVolumeClaims(namespace).List(selector)
+	if err != nil {
+		return err
+	}
+	selected := make(map[string]bool, len(claims))
+	for _, claim := range claims {
+		if claim.DeletionTimestamp == nil {
+			selected[claim.Name] = true
+		}
+	}
+	nfsexports, err := r.nfsexportLister.VolumeNfsExports(namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	status := &crdv1.NfsExportSetStatus{}
+	exported := make(map[string]bool, len(selected))
+	for _, nfsexport := range nfsexports {
+		if !metav1.IsControlledBy(nfsexport, set) {
+			continue
+		}
+		claimName := ""
+		if nfsexport.Spec.Source.PersistentVolumeClaimName != nil {
+			claimName = *nfsexport.Spec.Source.PersistentVolumeClaimName
+		}
+		if !selected[claimName] {
+			if nfsexport.DeletionTimestamp == nil {
+				klog.V(4).Infof("deleting nfsexport %s/%s of set %s, claim %q is no longer selected", namespace, nfsexport.Name, key, claimName)
+				err := r.clientset.NfsExportV1().VolumeNfsExports(namespace).Delete(context.TODO(), nfsexport.Name, metav1.DeleteOptions{})
+				if err != nil && !apierrs.IsNotFound(err) {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		// A nfsexport being deleted is replaced once it is gone.
+		exported[claimName] = true
+		if nfsexport.DeletionTimestamp != nil {
+			continue
+		}
+		status.Exports++
+		if nfsexport.Status != nil && nfsexport.Status.ReadyToUse != nil && *nfsexport.Status.ReadyToUse {
+			status.ReadyExports++
+		}
+	}
+
+	claimNames := make([]string, 0, len(selected))
+	for claimName := range selected {
+		if !exported[claimName] {
+			claimNames = append(claimNames, claimName)
+		}
+	}
+	sort.Strings(claimNames)
+	for _, claimName := range claimNames {
+		nfsexport := newNfsExport(set, claimName)
+		if existing, err := r.nfsexportLister.VolumeNfsExports(namespace).Get(nfsexport.Name); err == nil {
+			// The nfsexport is not controlled by the set, else it would
+			// have been matched to its claim, and is left alone.
+			klog.Warningf("nfsexport %s/%s of set %s for claim %q already exists and is not controlled by the set", namespace, existing.Name, key, claimName)
+			status.ConflictingClaims = append(status.ConflictingClaims, claimName)
+			continue
+		}
+		klog.V(4).Infof("creating nfsexport %s/%s of set %s for claim %q", namespace, nfsexport.Name, key, claimName)
+		_, err := r.clientset.NfsExportV1().VolumeNfsExports(namespace).Create(context.TODO(), nfsexport, metav1.CreateOptions{})
+		switch {
+		case apierrs.IsAlreadyExists(err):
+			// The informer has not seen the nfsexport yet, check whether
+			// it is the one of the set.
+			existing, err := r.clientset.NfsExportV1().VolumeNfsExports(namespace).Get(context.TODO(), nfsexport.Name, metav1.GetOptions{})
+			switch {
+			case err != nil:
+				errs = append(errs, err)
+			case metav1.IsControlledBy(existing, set):
+				status.Exports++
+			default:
+				klog.Warningf("nfsexport %s/%s of set %s for claim %q already exists and is not controlled by the set", namespace, existing.Name, key, claimName)
+				status.ConflictingClaims = append(status.ConflictingClaims, claimName)
+			}
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			status.Exports++
+		}
+	}
+
+	if err := r.updateSetStatus(set, status); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// updateSetStatus updates the status of set if it changed.
+func (r *Reconciler) updateSetStatus(set *crdv1.NfsExportSet, status *crdv1.NfsExportSetStatus) error {
+	if apiequality.Semantic.DeepEqual(set.Status, status) {
+		return nil
+	}
+	setClone := set.DeepCopy()
+	setClone.Status = status
+	if _, err := r.clientset.NfsExportV1().NfsExportSets(set.Namespace).UpdateStatus(context.TODO(), setClone, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	klog.V(5).Infof("updated status of nfsexport set %s/%s", set.Namespace, set.Name)
+	return nil
+}
+
+// newNfsExport returns the VolumeNfsExport of set for claimName, created
+// from the template of the set.
+func newNfsExport(set *crdv1.NfsExportSet, claimName string) *crdv1.VolumeNfsExport {
+	template := set.Spec.Template.DeepCopy()
+	return &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            nfsExportName(set.Name, claimName),
+			Namespace:       set.Namespace,
+			Labels:          withoutReservedKeys(template.Labels),
+			Annotations:     withoutReservedKeys(template.Annotations),
+			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(set, crdv1.SchemeGroupVersion.WithKind(nfsExportSetKind))},
+		},
+		Spec: crdv1.VolumeNfsExportSpec{
+			Source: crdv1.VolumeNfsExportSource{
+				PersistentVolumeClaimName: &claimName,
+			},
+			VolumeNfsExportClassName: template.VolumeNfsExportClassName,
+			Mode:                     template.Mode,
+		},
+	}
+}
+
+// withoutReservedKeys returns the labels or annotations of a template without
+// the keys managed by the controllers, see utils.IsReservedMetadataKey.
+func withoutReservedKeys(metadata map[string]string) map[string]string {
+	if metadata == nil {
+		return nil
+	}
+	result := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		if !utils.IsReservedMetadataKey(key) {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// nfsExportName returns <set name>-<claim name>-<hash>, where hash is a hash
+// of the set and claim names. Both names may contain dashes, the hash keeps
+// the names of different sets and claims apart, e.g. set a-b with claim c
+// and set a with claim b-c. Names longer than allowed are truncated before
+// the hash.
+func nfsExportName(setName, claimName string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(setName + "/" + claimName))
+	suffix := fmt.Sprintf("-%08x", hash.Sum32())
+	name := setName + "-" + claimName
+	if len(name)+len(suffix) > validation.DNS1123SubdomainMaxLength {
+		// A label must not end with a dash or a dot.
+		name = strings.TrimRight(name[:validation.DNS1123SubdomainMaxLength-len(suffix)], ".-")
+	}
+	return name + suffix
+}
@@ -0,0 +1,302 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exportset
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	coreinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const testNamespace = "default"
+
+var (
+	True       = true
+	className  = "class1"
+	backupOnly = map[string]string{"backup": "true"}
+)
+
+func newSet(status *crdv1.NfsExportSetStatus) *crdv1.NfsExportSet {
+	return &crdv1.NfsExportSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "set1", Namespace: testNamespace, UID: "setuid1"},
+		Spec: crdv1.NfsExportSetSpec{
+			Selector: metav1.LabelSelector{MatchLabels: backupOnly},
+			Template: crdv1.NfsExportSetTemplate{
+				Labels:                   map[string]string{"team": "a"},
+				VolumeNfsExportClassName: &className,
+			},
+		},
+		Status: status,
+	}
+}
+
+func newClaim(name string, labels map[string]string) *v1.PersistentVolumeClaim {
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: labels},
+	}
+}
+
+func withDeletionTimestamp(claim *v1.PersistentVolumeClaim) *v1.PersistentVolumeClaim {
+	now := metav1.Now()
+	claim.DeletionTimestamp = &now
+	return claim
+}
+
+func newSetNfsExport(claimName string, ready bool) *crdv1.VolumeNfsExport {
+	nfsexport := newNfsExport(newSet(nil), claimName)
+	if ready {
+		nfsexport.Status = &crdv1.VolumeNfsExportStatus{ReadyToUse: &True}
+	}
+	return nfsexport
+}
+
+func newTestReconciler(t *testing.T, set *crdv1.NfsExportSet, nfsexports []*crdv1.VolumeNfsExport, claims []*v1.PersistentVolumeClaim) (*Reconciler, *fake.Clientset) {
+	objects := []runtime.Object{set}
+	for _, nfsexport := range nfsexports {
+		objects = append(objects, nfsexport)
+	}
+	client := fake.NewSimpleClientset(objects...)
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	coreFactory := coreinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	setInformer := factory.NfsExport().V1().NfsExportSets()
+	nfsexportInformer := factory.NfsExport().V1().VolumeNfsExports()
+	pvcInformer := coreFactory.Core().V1().PersistentVolumeClaims()
+	r := NewReconciler(client, setInformer, nfsexportInformer, pvcInformer, 0, workqueue.DefaultControllerRateLimiter())
+	if err := setInformer.Informer().GetIndexer().Add(set); err != nil {
+		t.Fatalf("failed to add set %s to informer: %v", set.Name, err)
+	}
+	for _, nfsexport := range nfsexports {
+		if err := nfsexportInformer.Informer().GetIndexer().Add(nfsexport); err != nil {
+			t.Fatalf("failed to add nfsexport %s to informer: %v", nfsexport.Name, err)
+		}
+	}
+	for _, claim := range claims {
+		if err := pvcInformer.Informer().GetIndexer().Add(claim); err != nil {
+			t.Fatalf("failed to add claim %s to informer: %v", claim.Name, err)
+		}
+	}
+	return r, client
+}
+
+func TestSyncSet(t *testing.T) {
+	other := newSetNfsExport("claim1", false)
+	other.Name = "other"
+	other.OwnerReferences = nil
+	taken := newSetNfsExport("claim3", false)
+	taken.Name = nfsExportName("set1", "claim1")
+	taken.OwnerReferences = nil
+
+	tests := []struct {
+		name               string
+		set                *crdv1.NfsExportSet
+		nfsexports         []*crdv1.VolumeNfsExport
+		claims             []*v1.PersistentVolumeClaim
+		expectedNfsExports []string
+		expectedStatus     *crdv1.NfsExportSetStatus
+	}{
+		{
+			name:               "nfsexports of the selected claims are created",
+			set:                newSet(nil),
+			claims:             []*v1.PersistentVolumeClaim{newClaim("claim1", backupOnly), newClaim("claim2", backupOnly), newClaim("claim3", nil)},
+			expectedNfsExports: []string{nfsExportName("set1", "claim1"), nfsExportName("set1", "claim2")},
+			expectedStatus:     &crdv1.NfsExportSetStatus{Exports: 2},
+		},
+		{
+			name:               "nfsexports of claims no longer selected are deleted",
+			set:                newSet(&crdv1.NfsExportSetStatus{Exports: 2, ReadyExports: 1}),
+			nfsexports:         []*crdv1.VolumeNfsExport{newSetNfsExport("claim1", true), newSetNfsExport("claim2", true)},
+			claims:             []*v1.PersistentVolumeClaim{newClaim("claim1", nil), newClaim("claim2", backupOnly)},
+			expectedNfsExports: []string{nfsExportName("set1", "claim2")},
+			expectedStatus:     &crdv1.NfsExportSetStatus{Exports: 1, ReadyExports: 1},
+		},
+		{
+			name:               "nfsexports of deleted claims are deleted",
+			set:                newSet(nil),
+			nfsexports:         []*crdv1.VolumeNfsExport{newSetNfsExport("claim1", false), newSetNfsExport("claim2", false)},
+			claims:             []*v1.PersistentVolumeClaim{withDeletionTimestamp(newClaim("claim1", backupOnly))},
+			expectedNfsExports: nil,
+			expectedStatus:     &crdv1.NfsExportSetStatus{},
+		},
+		{
+			name:               "up to date set is left alone",
+			set:                newSet(&crdv1.NfsExportSetStatus{Exports: 1, ReadyExports: 1}),
+			nfsexports:         []*crdv1.VolumeNfsExport{newSetNfsExport("claim1", true)},
+			claims:             []*v1.PersistentVolumeClaim{newClaim("claim1", backupOnly)},
+			expectedNfsExports: []string{nfsExportName("set1", "claim1")},
+			expectedStatus:     &crdv1.NfsExportSetStatus{Exports: 1, ReadyExports: 1},
+		},
+		{
+			name:               "nfsexports not controlled by the set are left alone",
+			set:                newSet(nil),
+			nfsexports:         []*crdv1.VolumeNfsExport{other},
+			expectedNfsExports: []string{"other"},
+			expectedStatus:     &crdv1.NfsExportSetStatus{},
+		},
+		{
+			name:               "claims whose nfsexport name is taken are reported",
+			set:                newSet(nil),
+			nfsexports:         []*crdv1.VolumeNfsExport{taken},
+			claims:             []*v1.PersistentVolumeClaim{newClaim("claim1", backupOnly), newClaim("claim2", backupOnly)},
+			expectedNfsExports: []string{nfsExportName("set1", "claim1"), nfsExportName("set1", "claim2")},
+			expectedStatus:     &crdv1.NfsExportSetStatus{Exports: 1, ConflictingClaims: []string{"claim1"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, client := newTestReconciler(t, test.set, test.nfsexports, test.claims)
+			if err := r.syncSet(testNamespace + "/set1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			nfsexports, err := client.NfsExportV1().VolumeNfsExports(testNamespace).List(context.TODO(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatalf("failed to list nfsexports: %v", err)
+			}
+			var names []string
+			for _, nfsexport := range nfsexports.Items {
+				names = append(names, nfsexport.Name)
+			}
+			sort.Strings(names)
+			if !reflect.DeepEqual(names, test.expectedNfsExports) {
+				t.Errorf("expected nfsexports %v, got %v", test.expectedNfsExports, names)
+			}
+
+			set, err := client.NfsExportV1().NfsExportSets(testNamespace).Get(context.TODO(), "set1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get set: %v", err)
+			}
+			if !reflect.DeepEqual(set.Status, test.expectedStatus) {
+				t.Errorf("expected status %+v, got %+v", test.expectedStatus, set.Status)
+			}
+			if test.set.Status != nil && reflect.DeepEqual(test.set.Status, test.expectedStatus) {
+				for _, action := range client.Actions() {
+					if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+						t.Errorf("expected no status update, got %+v", action)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestSyncSetAlreadyExists tests nfsexports created while the informer has
+// not seen them yet.
+func TestSyncSetAlreadyExists(t *testing.T) {
+	tests := []struct {
+		name           string
+		owned          bool
+		expectedStatus *crdv1.NfsExportSetStatus
+	}{
+		{
+			name:           "nfsexport of the set is counted",
+			owned:          true,
+			expectedStatus: &crdv1.NfsExportSetStatus{Exports: 1},
+		},
+		{
+			name:           "nfsexport not controlled by the set is reported",
+			expectedStatus: &crdv1.NfsExportSetStatus{ConflictingClaims: []string{"claim1"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, client := newTestReconciler(t, newSet(nil), nil, []*v1.PersistentVolumeClaim{newClaim("claim1", backupOnly)})
+			nfsexport := newSetNfsExport("claim1", false)
+			if !test.owned {
+				nfsexport.OwnerReferences = nil
+			}
+			if _, err := client.NfsExportV1().VolumeNfsExports(testNamespace).Create(context.TODO(), nfsexport, metav1.CreateOptions{}); err != nil {
+				t.Fatalf("failed to create nfsexport: %v", err)
+			}
+			if err := r.syncSet(testNamespace + "/set1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			set, err := client.NfsExportV1().NfsExportSets(testNamespace).Get(context.TODO(), "set1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("failed to get set: %v", err)
+			}
+			if !reflect.DeepEqual(set.Status, test.expectedStatus) {
+				t.Errorf("expected status %+v, got %+v", test.expectedStatus, set.Status)
+			}
+		})
+	}
+}
+
+func TestNewNfsExport(t *testing.T) {
+	set := newSet(nil)
+	set.Spec.Template.Labels[utils.ReservedMetadataPrefix+"managed-by"] = "someone"
+	set.Spec.Template.Annotations = map[string]string{
+		utils.AnnVolumeNfsExportBeingDeleted: "yes",
+		utils.AnnSkipValidation:              "true",
+	}
+	nfsexport := newNfsExport(set, "claim1")
+	if nfsexport.Name != nfsExportName("set1", "claim1") || nfsexport.Namespace != testNamespace {
+		t.Errorf("expected nfsexport default/%s, got %s/%s", nfsExportName("set1", "claim1"), nfsexport.Namespace, nfsexport.Name)
+	}
+	if claimName := nfsexport.Spec.Source.PersistentVolumeClaimName; claimName == nil || *claimName != "claim1" {
+		t.Errorf("expected the claim as source, got %v", claimName)
+	}
+	if class := nfsexport.Spec.VolumeNfsExportClassName; class == nil || *class != className {
+		t.Errorf("expected the class of the template, got %v", class)
+	}
+	if !reflect.DeepEqual(nfsexport.Labels, map[string]string{"team": "a"}) {
+		t.Errorf("expected the labels of the template without reserved keys, got %v", nfsexport.Labels)
+	}
+	if !reflect.DeepEqual(nfsexport.Annotations, map[string]string{utils.AnnSkipValidation: "true"}) {
+		t.Errorf("expected the annotations of the template without reserved keys, got %v", nfsexport.Annotations)
+	}
+	owner := metav1.GetControllerOf(nfsexport)
+	if owner == nil || owner.Kind != "NfsExportSet" || owner.UID != types.UID("setuid1") {
+		t.Errorf("expected the nfsexport to be controlled by its set, got %+v", owner)
+	}
+}
+
+func TestNfsExportName(t *testing.T) {
+	long := strings.Repeat("a", validation.DNS1123SubdomainMaxLength)
+	name := nfsExportName(long, "claim1")
+	if len(name) != validation.DNS1123SubdomainMaxLength {
+		t.Errorf("expected a name of %d characters, got %d", validation.DNS1123SubdomainMaxLength, len(name))
+	}
+	if name == nfsExportName(long, "claim2") {
+		t.Errorf("expected truncated names of different claims to differ, got %s", name)
+	}
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		t.Errorf("expected a valid name, got %v", errs)
+	}
+	if nfsExportName("a-b", "c") == nfsExportName("a", "b-c") {
+		t.Errorf("expected the names of different sets and claims to differ, got %s", nfsExportName("a", "b-c"))
+	}
+	if name := nfsExportName("set1", "claim1"); !strings.HasPrefix(name, "set1-claim1-") {
+		t.Errorf("expected a name starting with the set and claim names, got %s", name)
+	}
+}
@@ -0,0 +1,435 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fakeapiserver simulates API server failure modes on the fake
+// clientsets of client-go and of this repository. Hooks are registered per
+// verb and resource and fail, delay or intercept the matching actions before
+// the reactors of the fake clientset handle them:
+//
+//	hooks := fakeapiserver.NewHooks(
+//		fakeapiserver.Error(fakeapiserver.VerbUpdate, fakeapiserver.ResourceVolumeNfsExports, errors.New("etcd unavailable")),
+//		fakeapiserver.Latency(fakeapiserver.VerbAny, fakeapiserver.ResourceAny, 100*time.Millisecond),
+//	)
+//	hooks.Install(&client.Fake)
+package fakeapiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+	klog "k8s.io/klog/v2"
+)
+
+// Verb is an API verb hooks match on.
+type Verb string
+
+const (
+	VerbAny    Verb = "*"
+	VerbGet    Verb = "get"
+	VerbList   Verb = "list"
+	VerbWatch  Verb = "watch"
+	VerbCreate Verb = "create"
+	VerbUpdate Verb = "update"
+	VerbPatch  Verb = "patch"
+	VerbDelete Verb = "delete"
+)
+
+// Resource is an API resource hooks match on, optionally followed by a
+// subresource, e.g. "volumenfsexports/status".
+type Resource string
+
+const (
+	ResourceAny                     Resource = "*"
+	ResourceVolumeNfsExports        Resource = "volumenfsexports"
+	ResourceVolumeNfsExportContents Resource = "volumenfsexportcontents"
+	ResourceVolumeNfsExportClasses  Resource = "volumenfsexportclasses"
+	ResourceNfsExportMounts         Resource = "nfsexportmounts"
+	ResourcePersistentVolumes       Resource = "persistentvolumes"
+	ResourcePersistentVolumeClaims  Resource = "persistentvolumeclaims"
+	ResourceSecrets                 Resource = "secrets"
+)
+
+// The status of volume nfsexports and contents is written with server-side
+// apply patches of their status subresource.
+const (
+	ResourceVolumeNfsExportsStatus        Resource = "volumenfsexports/status"
+	ResourceVolumeNfsExportContentsStatus Resource = "volumenfsexportcontents/status"
+)
+
+// Interceptor handles an action matching a hook, like a reactor of a fake
+// clientset. When handled is false, the action goes on to the next hooks and
+// to the reactors of the clientset.
+type Interceptor func(action core.Action) (handled bool, ret runtime.Object, err error)
+
+// Hook fails, delays or intercepts the actions of a verb on a resource.
+type Hook struct {
+	Verb     Verb
+	Resource Resource
+	// Times is the number of matching actions the hook applies to before it
+	// is removed. Zero applies it to all of them.
+	Times int
+	// Latency delays the matching actions.
+	Latency time.Duration
+	// Err is returned for the matching actions, if set.
+	Err error
+	// Intercept is called with the matching actions if Err is not set.
+	Intercept Interceptor
+}
+
+// Error returns a hook failing the next matching action with err.
+func Error(verb Verb, resource Resource, err error) Hook {
+	return Hook{Verb: verb, Resource: resource, Times: 1, Err: err}
+}
+
+// Latency returns a hook delaying all matching actions by latency.
+func Latency(verb Verb, resource Resource, latency time.Duration) Hook {
+	return Hook{Verb: verb, Resource: resource, Latency: latency}
+}
+
+// Intercept returns a hook calling interceptor with all matching actions.
+func Intercept(verb Verb, resource Resource, interceptor Interceptor) Hook {
+	return Hook{Verb: verb, Resource: resource, Intercept: interceptor}
+}
+
+// Matches returns true if the hook applies to the action.
+func (h *Hook) Matches(action core.Action) bool {
+	if h.Verb != VerbAny && !strings.EqualFold(string(h.Verb), action.GetVerb()) {
+		return false
+	}
+	if h.Resource == ResourceAny {
+		return true
+	}
+	return action.Matches(action.GetVerb(), string(h.Resource))
+}
+
+// Hooks is an ordered list of hooks, evaluated in the order they were added.
+// It is safe for concurrent use.
+type Hooks struct {
+	lock  sync.Mutex
+	hooks []*Hook
+}
+
+// NewHooks returns new *Hooks with the given hooks.
+func NewHooks(hooks ...Hook) *Hooks {
+	h := &Hooks{}
+	for _, hook := range hooks {
+		h.Add(hook)
+	}
+	return h
+}
+
+// Add appends a hook to the list.
+func (h *Hooks) Add(hook Hook) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.hooks = append(h.hooks, &hook)
+}
+
+// Len returns the number of hooks that were not removed yet.
+func (h *Hooks) Len() int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.hooks)
+}
+
+// ReactorChain is implemented by the Fake embedded in fake clientsets.
+type ReactorChain interface {
+	PrependReactor(verb, resource string, reaction core.ReactionFunc)
+}
+
+// Install makes the hooks apply to all actions of a fake clientset, before
+// its other reactors.
+func (h *Hooks) Install(fake ReactorChain) {
+	fake.PrependReactor(string(VerbAny), string(ResourceAny), h.React)
+}
+
+// React applies the matching hooks to an action, in order, until one of them
+// returns an error or handles the action. It can be used as the reaction of
+// a reactor.
+func (h *Hooks) React(action core.Action) (handled bool, ret runtime.Object, err error) {
+	for next := 0; ; {
+		var hook *Hook
+		hook, next = h.match(action, next)
+		if hook == nil {
+			return false, nil, nil
+		}
+		if hook.Latency > 0 {
+			klog.V(4).Infof("delaying %q on %q by %v", action.GetVerb(), action.GetResource(), hook.Latency)
+			time.Sleep(hook.Latency)
+		}
+		if hook.Err != nil {
+			klog.V(4).Infof("failing %q on %q with %v", action.GetVerb(), action.GetResource(), hook.Err)
+			return true, nil, hook.Err
+		}
+		if hook.Intercept != nil {
+			if handled, ret, err := hook.Intercept(action); handled {
+				return true, ret, err
+			}
+		}
+	}
+}
+
+// match returns a copy of the first hook from index from on that matches the
+// action, and the index to continue from. Hooks whose Times is exhausted are
+// removed.
+func (h *Hooks) match(action core.Action, from int) (*Hook, int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for i := from; i < len(h.hooks); i++ {
+		hook := h.hooks[i]
+		if !hook.Matches(action) {
+			continue
+		}
+		matched := *hook
+		if hook.Times > 0 {
+			hook.Times--
+			if hook.Times == 0 {
+				h.hooks = append(h.hooks[:i], h.hooks[i+1:]...)
+				return &matched, i
+			}
+		}
+		return &matched, i + 1
+	}
+	return nil, len(h.hooks)
+}
+
+// PatchApplyError returns the error the API server responds with when a JSON
+// patch cannot be applied, e.g. when a test operation fails.
+func PatchApplyError(action core.PatchAction, err error) error {
+	return apierrs.NewGenericServerResponse(http.StatusUnprocessableEntity, "patch", action.GetResource().GroupResource(), action.GetName(), err.Error(), 0, false)
+}
+
+// MergePatch applies the JSON merge patch of action to the JSON of the stored
+// object. Like the API server, it rejects the patch with a conflict if it sets
+// a resourceVersion other than the one of the stored object.
+func MergePatch(action core.PatchAction, stored []byte) ([]byte, error) {
+	if action.GetPatchType() != types.MergePatchType {
+		return nil, fmt.Errorf("only JSON merge patches are supported, got a %s patch", action.GetPatchType())
+	}
+	var patch, object struct {
+		Metadata struct {
+			ResourceVersion string `json:"resourceVersion"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(action.GetPatch(), &patch); err != nil {
+		return nil, apierrs.NewBadRequest(err.Error())
+	}
+	if err := json.Unmarshal(stored, &object); err != nil {
+		return nil, err
+	}
+	if patch.Metadata.ResourceVersion != "" && patch.Metadata.ResourceVersion != object.Metadata.ResourceVersion {
+		return nil, apierrs.NewConflict(action.GetResource().GroupResource(), action.GetName(), fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+	return jsonpatch.MergePatch(stored, action.GetPatch())
+}
+
+// ApplyStatus applies the server-side apply patch of a status action by
+// fieldManager to the JSON of the stored object. Like the API server, it
+// records the owner of each field of the status in the managed fields of the
+// object: the fields set by the patch are owned by fieldManager afterwards,
+// the fields fieldManager owned and no longer sets are removed, and the
+// fields owned by other managers are left untouched. The apply is forced, as
+// the applies of the controllers are. Fields without an owner, e.g. set by a
+// test fixture, are considered owned by fieldManager. The metadata other than
+// the managed fields and the spec are left untouched. Like the API server, it
+// rejects the patch with a conflict if it sets a resourceVersion other than
+// the one of the stored object.
+func ApplyStatus(action core.PatchAction, stored []byte, fieldManager string) ([]byte, error) {
+	if action.GetPatchType() != types.ApplyPatchType || action.GetSubresource() != "status" {
+		return nil, fmt.Errorf("only server-side apply of the status is supported, got a %s patch of %q", action.GetPatchType(), action.GetSubresource())
+	}
+	var object, applied map[string]json.RawMessage
+	if err := json.Unmarshal(stored, &object); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(action.GetPatch(), &applied); err != nil {
+		return nil, apierrs.NewBadRequest(err.Error())
+	}
+	var patch, storedObject struct {
+		APIVersion string `json:"apiVersion"`
+		Metadata   struct {
+			ResourceVersion string                      `json:"resourceVersion"`
+			ManagedFields   []metav1.ManagedFieldsEntry `json:"managedFields"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(action.GetPatch(), &patch); err != nil {
+		return nil, apierrs.NewBadRequest(err.Error())
+	}
+	if err := json.Unmarshal(stored, &storedObject); err != nil {
+		return nil, err
+	}
+	if patch.Metadata.ResourceVersion != "" && patch.Metadata.ResourceVersion != storedObject.Metadata.ResourceVersion {
+		return nil, apierrs.NewConflict(action.GetResource().GroupResource(), action.GetName(), fmt.Errorf("the object has been modified; please apply your changes to the latest version and try again"))
+	}
+
+	var storedStatus, appliedStatus map[string]json.RawMessage
+	if err := unmarshalOptional(object["status"], &storedStatus); err != nil {
+		return nil, err
+	}
+	if err := unmarshalOptional(applied["status"], &appliedStatus); err != nil {
+		return nil, apierrs.NewBadRequest(err.Error())
+	}
+	owners := map[string]string{}
+	for _, entry := range storedObject.Metadata.ManagedFields {
+		fields, err := statusFields(entry)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			owners[field] = entry.Manager
+		}
+	}
+	status := map[string]json.RawMessage{}
+	for field, value := range storedStatus {
+		if owner, ok := owners[field]; ok && owner != fieldManager {
+			status[field] = value
+		}
+	}
+	for field, value := range appliedStatus {
+		status[field] = value
+		owners[field] = fieldManager
+	}
+	for field := range owners {
+		if _, ok := status[field]; !ok {
+			delete(owners, field)
+		}
+	}
+	if len(status) > 0 {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return nil, err
+		}
+		object["status"] = data
+	} else {
+		delete(object, "status")
+	}
+
+	managedFields, err := statusManagedFields(storedObject.Metadata.ManagedFields, owners, fieldManager, patch.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+	var metadata map[string]json.RawMessage
+	if err := unmarshalOptional(object["metadata"], &metadata); err != nil {
+		return nil, err
+	}
+	if metadata == nil {
+		metadata = map[string]json.RawMessage{}
+	}
+	if len(managedFields) > 0 {
+		data, err := json.Marshal(managedFields)
+		if err != nil {
+			return nil, err
+		}
+		metadata["managedFields"] = data
+	} else {
+		delete(metadata, "managedFields")
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	object["metadata"] = data
+	return json.Marshal(object)
+}
+
+// unmarshalOptional unmarshals data into v unless data is empty or null.
+func unmarshalOptional(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 || string(data) == "null" {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// statusFields returns the fields of the status owned by a managed fields
+// entry of the status subresource. Ownership is tracked per field of the
+// status, not per nested field.
+func statusFields(entry metav1.ManagedFieldsEntry) ([]string, error) {
+	if entry.Subresource != "status" || entry.FieldsV1 == nil {
+		return nil, nil
+	}
+	var fieldSet struct {
+		Status map[string]json.RawMessage `json:"f:status"`
+	}
+	if err := json.Unmarshal(entry.FieldsV1.Raw, &fieldSet); err != nil {
+		return nil, err
+	}
+	var fields []string
+	for field := range fieldSet.Status {
+		fields = append(fields, strings.TrimPrefix(field, "f:"))
+	}
+	return fields, nil
+}
+
+// statusManagedFields returns managedFields with the entries of the status
+// subresource replaced by the entries recording owners, one per manager.
+func statusManagedFields(managedFields []metav1.ManagedFieldsEntry, owners map[string]string, fieldManager, apiVersion string) ([]metav1.ManagedFieldsEntry, error) {
+	fieldsByManager := map[string]map[string]struct{}{}
+	for field, owner := range owners {
+		if fieldsByManager[owner] == nil {
+			fieldsByManager[owner] = map[string]struct{}{}
+		}
+		fieldsByManager[owner]["f:"+field] = struct{}{}
+	}
+	fieldsV1 := func(manager string) (*metav1.FieldsV1, error) {
+		data, err := json.Marshal(map[string]interface{}{"f:status": fieldsByManager[manager]})
+		if err != nil {
+			return nil, err
+		}
+		return &metav1.FieldsV1{Raw: data}, nil
+	}
+
+	var result []metav1.ManagedFieldsEntry
+	for _, entry := range managedFields {
+		if entry.Subresource != "status" {
+			result = append(result, entry)
+			continue
+		}
+		if entry.Manager == fieldManager || len(fieldsByManager[entry.Manager]) == 0 {
+			continue
+		}
+		fields, err := fieldsV1(entry.Manager)
+		if err != nil {
+			return nil, err
+		}
+		entry.FieldsV1 = fields
+		result = append(result, entry)
+	}
+	if len(fieldsByManager[fieldManager]) > 0 {
+		fields, err := fieldsV1(fieldManager)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, metav1.ManagedFieldsEntry{
+			Manager:     fieldManager,
+			Operation:   metav1.ManagedFieldsOperationApply,
+			APIVersion:  apiVersion,
+			FieldsType:  "FieldsV1",
+			FieldsV1:    fields,
+			Subresource: "status",
+		})
+	}
+	return result, nil
+}
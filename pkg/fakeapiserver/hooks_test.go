@@ -0,0 +1,208 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fakeapiserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientsetfake "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+)
+
+func newClient(hooks *Hooks) *clientsetfake.Clientset {
+	client := clientsetfake.NewSimpleClientset(&crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"},
+	})
+	hooks.Install(&client.Fake)
+	return client
+}
+
+func getNfsExport(client *clientsetfake.Clientset) (*crdv1.VolumeNfsExport, error) {
+	return client.NfsExportV1().VolumeNfsExports("default").Get(context.TODO(), "snap1", metav1.GetOptions{})
+}
+
+func TestErrorReturnedOnce(t *testing.T) {
+	mockErr := errors.New("mock get error")
+	hooks := NewHooks(
+		Error(VerbUpdate, ResourceVolumeNfsExports, errors.New("mock update error")),
+		Error(VerbGet, ResourceVolumeNfsExports, mockErr),
+	)
+	client := newClient(hooks)
+
+	if _, err := getNfsExport(client); err != mockErr {
+		t.Errorf("expected %v, got %v", mockErr, err)
+	}
+	if _, err := getNfsExport(client); err != nil {
+		t.Errorf("expected the error to be returned once, got %v", err)
+	}
+	if hooks.Len() != 1 {
+		t.Errorf("expected the update hook to remain, got %d hooks", hooks.Len())
+	}
+}
+
+func TestErrorMatching(t *testing.T) {
+	tests := []struct {
+		name        string
+		hook        Hook
+		expectError bool
+	}{
+		{name: "verb and resource", hook: Error(VerbGet, ResourceVolumeNfsExports, errors.New("mock")), expectError: true},
+		{name: "any verb", hook: Error(VerbAny, ResourceVolumeNfsExports, errors.New("mock")), expectError: true},
+		{name: "any resource", hook: Error(VerbGet, ResourceAny, errors.New("mock")), expectError: true},
+		{name: "other verb", hook: Error(VerbDelete, ResourceVolumeNfsExports, errors.New("mock"))},
+		{name: "other resource", hook: Error(VerbGet, ResourceVolumeNfsExportContents, errors.New("mock"))},
+		{name: "subresource", hook: Error(VerbGet, ResourceVolumeNfsExports+"/status", errors.New("mock"))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := getNfsExport(newClient(NewHooks(test.hook)))
+			if (err != nil) != test.expectError {
+				t.Errorf("expected error: %v, got %v", test.expectError, err)
+			}
+		})
+	}
+}
+
+func TestLatency(t *testing.T) {
+	client := newClient(NewHooks(Latency(VerbAny, ResourceAny, 50*time.Millisecond)))
+
+	start := time.Now()
+	nfsexport, err := getNfsExport(client)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nfsexport.Name != "snap1" {
+		t.Errorf("expected the delayed action to reach the clientset, got %+v", nfsexport)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected the action to be delayed by 50ms, took %v", elapsed)
+	}
+}
+
+func TestIntercept(t *testing.T) {
+	calls := 0
+	passThrough := Intercept(VerbGet, ResourceVolumeNfsExports, func(action core.Action) (bool, runtime.Object, error) {
+		calls++
+		return false, nil, nil
+	})
+	replace := Intercept(VerbGet, ResourceVolumeNfsExports, func(action core.Action) (bool, runtime.Object, error) {
+		return true, &crdv1.VolumeNfsExport{ObjectMeta: metav1.ObjectMeta{Name: "intercepted"}}, nil
+	})
+	replace.Times = 1
+	client := newClient(NewHooks(passThrough, replace))
+
+	nfsexport, err := getNfsExport(client)
+	if err != nil || nfsexport.Name != "intercepted" {
+		t.Errorf("expected the interceptor to handle the action, got %+v, %v", nfsexport, err)
+	}
+	nfsexport, err = getNfsExport(client)
+	if err != nil || nfsexport.Name != "snap1" {
+		t.Errorf("expected the clientset to handle the action, got %+v, %v", nfsexport, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected the pass-through interceptor to be called twice, got %d", calls)
+	}
+}
+
+func TestApplyStatus(t *testing.T) {
+	resource := schema.GroupVersionResource{Group: "nfsexport.storage.k8s.io", Version: "v1", Resource: "volumenfsexports"}
+	stored := []byte(`{"metadata":{"name":"snap1","labels":{"foo":"bar"}},"spec":{"volumeNfsExportClassName":"gold"},"status":{"readyToUse":false,"restoreSize":"1Gi"}}`)
+
+	action := core.NewPatchSubresourceAction(resource, "default", "snap1", types.ApplyPatchType, []byte(`{"metadata":{"name":"snap1"},"status":{"readyToUse":true}}`), "status")
+	modified, err := ApplyStatus(action, stored, "controller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"metadata":{"labels":{"foo":"bar"},"managedFields":[{"manager":"controller","operation":"Apply","fieldsType":"FieldsV1","fieldsV1":{"f:status":{"f:readyToUse":{}}},"subresource":"status"}],"name":"snap1"},"spec":{"volumeNfsExportClassName":"gold"},"status":{"readyToUse":true}}`
+	if string(modified) != expected {
+		t.Errorf("expected %s, got %s", expected, modified)
+	}
+
+	action = core.NewPatchAction(resource, "default", "snap1", types.ApplyPatchType, []byte(`{"metadata":{"name":"snap1"}}`))
+	if _, err := ApplyStatus(action, stored, "controller"); err == nil {
+		t.Errorf("expected an error for an apply of the whole object")
+	}
+
+	stored = []byte(`{"metadata":{"name":"snap1","resourceVersion":"3"}}`)
+	action = core.NewPatchSubresourceAction(resource, "default", "snap1", types.ApplyPatchType, []byte(`{"metadata":{"name":"snap1","resourceVersion":"2"},"status":{"readyToUse":true}}`), "status")
+	if _, err := ApplyStatus(action, stored, "controller"); !apierrs.IsConflict(err) {
+		t.Errorf("expected a conflict for a stale resourceVersion, got %v", err)
+	}
+}
+
+func TestApplyStatusFieldOwnership(t *testing.T) {
+	resource := schema.GroupVersionResource{Group: "nfsexport.storage.k8s.io", Version: "v1", Resource: "volumenfsexports"}
+	stored := []byte(`{"metadata":{"name":"snap1","managedFields":[{"manager":"other","operation":"Apply","apiVersion":"nfsexport.storage.k8s.io/v1","fieldsType":"FieldsV1","fieldsV1":{"f:status":{"f:restoreSize":{}}},"subresource":"status"}]},"status":{"readyToUse":false,"restoreSize":"1Gi"}}`)
+
+	// The field owned by the other manager is kept.
+	action := core.NewPatchSubresourceAction(resource, "default", "snap1", types.ApplyPatchType, []byte(`{"apiVersion":"nfsexport.storage.k8s.io/v1","metadata":{"name":"snap1"},"status":{"readyToUse":true,"error":{"message":"failed"}}}`), "status")
+	modified, err := ApplyStatus(action, stored, "controller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"metadata":{"managedFields":[{"manager":"other","operation":"Apply","apiVersion":"nfsexport.storage.k8s.io/v1","fieldsType":"FieldsV1","fieldsV1":{"f:status":{"f:restoreSize":{}}},"subresource":"status"},{"manager":"controller","operation":"Apply","apiVersion":"nfsexport.storage.k8s.io/v1","fieldsType":"FieldsV1","fieldsV1":{"f:status":{"f:error":{},"f:readyToUse":{}}},"subresource":"status"}],"name":"snap1"},"status":{"error":{"message":"failed"},"readyToUse":true,"restoreSize":"1Gi"}}`
+	if string(modified) != expected {
+		t.Errorf("expected %s, got %s", expected, modified)
+	}
+
+	// A field owned by the manager and no longer applied is removed, a
+	// field owned by the other manager is taken over when applied.
+	action = core.NewPatchSubresourceAction(resource, "default", "snap1", types.ApplyPatchType, []byte(`{"apiVersion":"nfsexport.storage.k8s.io/v1","metadata":{"name":"snap1"},"status":{"readyToUse":true,"restoreSize":"2Gi"}}`), "status")
+	modified, err = ApplyStatus(action, modified, "controller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `{"metadata":{"managedFields":[{"manager":"controller","operation":"Apply","apiVersion":"nfsexport.storage.k8s.io/v1","fieldsType":"FieldsV1","fieldsV1":{"f:status":{"f:readyToUse":{},"f:restoreSize":{}}},"subresource":"status"}],"name":"snap1"},"status":{"readyToUse":true,"restoreSize":"2Gi"}}`
+	if string(modified) != expected {
+		t.Errorf("expected %s, got %s", expected, modified)
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	resource := schema.GroupVersionResource{Group: "nfsexport.storage.k8s.io", Version: "v1", Resource: "volumenfsexports"}
+	stored := []byte(`{"metadata":{"name":"snap1","resourceVersion":"3","finalizers":["foo"]}}`)
+
+	action := core.NewPatchAction(resource, "default", "snap1", types.MergePatchType, []byte(`{"metadata":{"finalizers":["foo","bar"],"resourceVersion":"3"}}`))
+	modified, err := MergePatch(action, stored)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"metadata":{"finalizers":["foo","bar"],"name":"snap1","resourceVersion":"3"}}`
+	if string(modified) != expected {
+		t.Errorf("expected %s, got %s", expected, modified)
+	}
+
+	action = core.NewPatchAction(resource, "default", "snap1", types.MergePatchType, []byte(`{"metadata":{"finalizers":["bar"],"resourceVersion":"2"}}`))
+	if _, err := MergePatch(action, stored); !apierrs.IsConflict(err) {
+		t.Errorf("expected a conflict for a stale resource version, got %v", err)
+	}
+
+	action = core.NewPatchAction(resource, "default", "snap1", types.JSONPatchType, []byte(`[]`))
+	if _, err := MergePatch(action, stored); err == nil {
+		t.Errorf("expected an error for a JSON patch")
+	}
+}
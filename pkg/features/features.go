@@ -0,0 +1,219 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package features defines the feature gates shared by the nfsexport
+// controller, the sidecar and the validation webhook, set with the
+// --feature-gates flag.
+package features
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"k8s.io/component-base/featuregate"
+	k8smetrics "k8s.io/component-base/metrics"
+	klog "k8s.io/klog/v2"
+)
+
+const (
+	// DistributedExporting enables each node to handle the nfsexports of the
+	// local volumes created on that node.
+	DistributedExporting featuregate.Feature = "DistributedExporting"
+
+	// PreventVolumeModeConversion prevents an unauthorised user from
+	// modifying the volume mode when creating a PVC from an existing
+	// VolumeNfsExport.
+	PreventVolumeModeConversion featuregate.Feature = "PreventVolumeModeConversion"
+
+	// DriverScopedContentInformer makes the sidecar cache only the
+	// VolumeNfsExportContents labeled with its own driver. The nfsexport
+	// controller always labels the contents with the name of their driver;
+	// upgrade it before enabling the gate on the sidecars, so that existing
+	// contents are labeled by the time the sidecars filter on the label.
+	DriverScopedContentInformer featuregate.Feature = "DriverScopedContentInformer"
+
+	// NfsExportSummaries makes the nfsexport controller maintain a
+	// NfsExportSummary in each namespace with VolumeNfsExports.
+	NfsExportSummaries featuregate.Feature = "NfsExportSummaries"
+
+	// NfsExportContentViews makes the nfsexport controller maintain a
+	// NfsExportContentView for each bound VolumeNfsExport.
+	NfsExportContentViews featuregate.Feature = "NfsExportContentViews"
+
+	// NfsExportSets makes the nfsexport controller maintain the
+	// VolumeNfsExports of the NfsExportSets.
+	NfsExportSets featuregate.Feature = "NfsExportSets"
+
+	// FallbackToDefaultClass makes the nfsexport controller switch a
+	// VolumeNfsExport whose VolumeNfsExportClass is deleted before its export
+	// is created to the default class of the driver of its source volume.
+	FallbackToDefaultClass featuregate.Feature = "FallbackToDefaultClass"
+
+	// StaticContentsReady makes the sidecar mark pre-provisioned
+	// VolumeNfsExportContents without a VolumeNfsExportClass ready to use
+	// without getting the status of their nfsexport from the CSI driver.
+	StaticContentsReady featuregate.Feature = "StaticContentsReady"
+)
+
+var defaultFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
+	DistributedExporting:        {Default: false, PreRelease: featuregate.Alpha},
+	PreventVolumeModeConversion: {Default: false, PreRelease: featuregate.Alpha},
+	DriverScopedContentInformer: {Default: false, PreRelease: featuregate.Alpha},
+	NfsExportSummaries:          {Default: false, PreRelease: featuregate.Alpha},
+	NfsExportContentViews:       {Default: false, PreRelease: featuregate.Alpha},
+	NfsExportSets:               {Default: false, PreRelease: featuregate.Alpha},
+	FallbackToDefaultClass:      {Default: false, PreRelease: featuregate.Alpha},
+	StaticContentsReady:         {Default: false, PreRelease: featuregate.Alpha},
+}
+
+// deprecatedFlags maps the boolean flags replaced by a feature gate to it.
+var deprecatedFlags = map[string]featuregate.Feature{
+	"enable-distributed-nfsexportting": DistributedExporting,
+	"prevent-volume-mode-conversion":   PreventVolumeModeConversion,
+	"enable-nfsexport-summaries":       NfsExportSummaries,
+	"enable-nfsexport-content-views":   NfsExportContentViews,
+	"enable-nfsexport-sets":            NfsExportSets,
+	"fallback-to-default-class":        FallbackToDefaultClass,
+	"static-contents-ready":            StaticContentsReady,
+}
+
+// DefaultMutableFeatureGate is the feature gate of the binary, to be set from
+// the command line.
+var DefaultMutableFeatureGate featuregate.MutableFeatureGate = featuregate.NewFeatureGate()
+
+// DefaultFeatureGate is the read-only view of DefaultMutableFeatureGate.
+var DefaultFeatureGate featuregate.FeatureGate = DefaultMutableFeatureGate
+
+func init() {
+	if err := DefaultMutableFeatureGate.Add(defaultFeatureGates); err != nil {
+		panic(err)
+	}
+}
+
+// Enabled returns true if the given feature is enabled.
+func Enabled(feature featuregate.Feature) bool {
+	return DefaultFeatureGate.Enabled(feature)
+}
+
+// AddFlag adds the --feature-gates flag to fs.
+func AddFlag(fs *flag.FlagSet) {
+	fs.Var(gateFlag{DefaultMutableFeatureGate}, "feature-gates", flagUsage())
+}
+
+// AddPFlag adds the --feature-gates flag to fs, for the binaries parsing
+// their flags with pflag.
+func AddPFlag(fs *pflag.FlagSet) {
+	fs.Var(gateFlag{DefaultMutableFeatureGate}, "feature-gates", flagUsage())
+}
+
+func flagUsage() string {
+	return "A set of key=value pairs that describe feature gates for alpha/experimental features. Options are:\n" +
+		strings.Join(DefaultMutableFeatureGate.KnownFeatures(), "\n")
+}
+
+// gateFlag is a flag.Value and a pflag.Value setting a feature gate.
+type gateFlag struct {
+	gate featuregate.MutableFeatureGate
+}
+
+func (f gateFlag) String() string {
+	return ""
+}
+
+func (f gateFlag) Set(value string) error {
+	return f.gate.Set(value)
+}
+
+func (f gateFlag) Type() string {
+	return "mapStringBool"
+}
+
+// SetFromDeprecatedFlags sets the feature gates replaced by the deprecated
+// boolean flags of fs that were set on the command line, which take
+// precedence over --feature-gates.
+func SetFromDeprecatedFlags(fs *flag.FlagSet) error {
+	gates := map[string]bool{}
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		feature, ok := deprecatedFlags[f.Name]
+		if !ok {
+			return
+		}
+		getter, ok := f.Value.(flag.Getter)
+		if !ok {
+			return
+		}
+		enabled, ok := getter.Get().(bool)
+		if !ok {
+			err = fmt.Errorf("flag --%s is not a boolean", f.Name)
+			return
+		}
+		klog.Warningf("--%s is deprecated, use --feature-gates=%s=%t instead", f.Name, feature, enabled)
+		gates[string(feature)] = enabled
+	})
+	if err != nil {
+		return err
+	}
+	return DefaultMutableFeatureGate.SetFromMap(gates)
+}
+
+// SetFromDeprecatedPFlags is SetFromDeprecatedFlags for the binaries parsing
+// their flags with pflag.
+func SetFromDeprecatedPFlags(fs *pflag.FlagSet) error {
+	gates := map[string]bool{}
+	var err error
+	fs.Visit(func(f *pflag.Flag) {
+		feature, ok := deprecatedFlags[f.Name]
+		if !ok {
+			return
+		}
+		enabled, parseErr := strconv.ParseBool(f.Value.String())
+		if parseErr != nil {
+			err = fmt.Errorf("flag --%s is not a boolean", f.Name)
+			return
+		}
+		klog.Warningf("--%s is deprecated, use --feature-gates=%s=%t instead", f.Name, feature, enabled)
+		gates[string(feature)] = enabled
+	})
+	if err != nil {
+		return err
+	}
+	return DefaultMutableFeatureGate.SetFromMap(gates)
+}
+
+// RegisterMetrics registers a gauge reporting whether each feature gate is
+// enabled to registry.
+func RegisterMetrics(registry k8smetrics.KubeRegistry) {
+	featureEnabled := k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Subsystem: "nfsexport",
+			Name:      "feature_enabled",
+			Help:      "Whether a feature gate is enabled (1) or not (0)",
+		},
+		[]string{"name", "stage"},
+	)
+	registry.MustRegister(featureEnabled)
+	for feature, spec := range defaultFeatureGates {
+		value := 0.0
+		if DefaultFeatureGate.Enabled(feature) {
+			value = 1.0
+		}
+		featureEnabled.WithLabelValues(string(feature), string(spec.PreRelease)).Set(value)
+	}
+}
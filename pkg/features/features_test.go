@@ -0,0 +1,202 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package features
+
+import (
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+func newFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	AddFlag(fs)
+	fs.Bool("enable-distributed-nfsexportting", false, "")
+	fs.Bool("prevent-volume-mode-conversion", false, "")
+	return fs
+}
+
+func newPFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	AddPFlag(fs)
+	fs.Bool("prevent-volume-mode-conversion", false, "")
+	return fs
+}
+
+func resetGates(t *testing.T) {
+	t.Cleanup(func() {
+		gates := map[string]bool{}
+		for feature, spec := range defaultFeatureGates {
+			gates[string(feature)] = spec.Default
+		}
+		DefaultMutableFeatureGate.SetFromMap(gates)
+	})
+}
+
+func TestFeatureGates(t *testing.T) {
+	tests := []struct {
+		name              string
+		args              []string
+		expectErr         bool
+		expectDistributed bool
+		expectPrevent     bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:              "feature gates",
+			args:              []string{"--feature-gates=DistributedExporting=true,PreventVolumeModeConversion=true"},
+			expectDistributed: true,
+			expectPrevent:     true,
+		},
+		{
+			name:              "deprecated flags",
+			args:              []string{"--enable-distributed-nfsexportting", "--prevent-volume-mode-conversion=true"},
+			expectDistributed: true,
+			expectPrevent:     true,
+		},
+		{
+			name:          "deprecated flag takes precedence",
+			args:          []string{"--feature-gates=PreventVolumeModeConversion=true", "--prevent-volume-mode-conversion=false"},
+			expectPrevent: false,
+		},
+		{
+			name:      "unknown feature gate",
+			args:      []string{"--feature-gates=Unknown=true"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetGates(t)
+			fs := newFlagSet()
+			err := fs.Parse(test.args)
+			if err == nil {
+				err = SetFromDeprecatedFlags(fs)
+			}
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got %v", test.expectErr, err)
+			}
+			if test.expectErr {
+				return
+			}
+			if Enabled(DistributedExporting) != test.expectDistributed {
+				t.Errorf("expected %s to be %v", DistributedExporting, test.expectDistributed)
+			}
+			if Enabled(PreventVolumeModeConversion) != test.expectPrevent {
+				t.Errorf("expected %s to be %v", PreventVolumeModeConversion, test.expectPrevent)
+			}
+		})
+	}
+}
+
+func TestPFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectErr     bool
+		expectPrevent bool
+	}{
+		{
+			name: "defaults",
+		},
+		{
+			name:          "feature gates",
+			args:          []string{"--feature-gates=PreventVolumeModeConversion=true"},
+			expectPrevent: true,
+		},
+		{
+			name:          "deprecated flag",
+			args:          []string{"--prevent-volume-mode-conversion"},
+			expectPrevent: true,
+		},
+		{
+			name:          "deprecated flag takes precedence",
+			args:          []string{"--feature-gates=PreventVolumeModeConversion=true", "--prevent-volume-mode-conversion=false"},
+			expectPrevent: false,
+		},
+		{
+			name:      "unknown feature gate",
+			args:      []string{"--feature-gates=Unknown=true"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resetGates(t)
+			fs := newPFlagSet()
+			err := fs.Parse(test.args)
+			if err == nil {
+				err = SetFromDeprecatedPFlags(fs)
+			}
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got %v", test.expectErr, err)
+			}
+			if test.expectErr {
+				return
+			}
+			if Enabled(PreventVolumeModeConversion) != test.expectPrevent {
+				t.Errorf("expected %s to be %v", PreventVolumeModeConversion, test.expectPrevent)
+			}
+		})
+	}
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	resetGates(t)
+	if err := DefaultMutableFeatureGate.Set("DistributedExporting=true"); err != nil {
+		t.Fatal(err)
+	}
+	registry := k8smetrics.NewKubeRegistry()
+	RegisterMetrics(registry)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "nfsexport_feature_enabled" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			var labels []string
+			for _, label := range metric.GetLabel() {
+				labels = append(labels, label.GetName()+"="+label.GetValue())
+			}
+			values[strings.Join(labels, ",")] = metric.GetGauge().GetValue()
+		}
+	}
+	expected := map[string]float64{
+		"name=DistributedExporting,stage=ALPHA":        1,
+		"name=PreventVolumeModeConversion,stage=ALPHA": 0,
+	}
+	for labels, value := range expected {
+		if got, ok := values[labels]; !ok || got != value {
+			t.Errorf("expected nfsexport_feature_enabled{%s} to be %v, got %v", labels, value, values)
+		}
+	}
+}
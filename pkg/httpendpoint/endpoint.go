@@ -0,0 +1,210 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package httpendpoint listens for the HTTP server for diagnostics of the
+// nfsexport-controller and the csi-nfsexporter, which serves the metrics and
+// the leader election health check, and of the validation webhook, which
+// serves its metrics. The server listens on a TCP address, optionally secured
+// with TLS and client certificates, or on a unix socket for environments that
+// forbid additional TCP listeners.
+package httpendpoint
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const unixPrefix = "unix://"
+
+// Options configures the TLS of a TCP endpoint. TLS is not supported on unix
+// sockets, whose access is controlled by their file permissions.
+type Options struct {
+	// CertFile and KeyFile are the PEM files of the certificate and key of
+	// the server. TLS is used when they are set.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the PEM file of the certificate authorities used to
+	// verify the certificates of the clients, which are then required.
+	ClientCAFile string
+}
+
+// files returns the files of opts which are set.
+func (opts Options) files() []string {
+	var files []string
+	for _, file := range []string{opts.CertFile, opts.KeyFile, opts.ClientCAFile} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// IsUnix returns true if endpoint is a unix:// socket.
+func IsUnix(endpoint string) bool {
+	return strings.HasPrefix(endpoint, unixPrefix)
+}
+
+// Listen listens on endpoint, which is either a TCP address such as :8080 or
+// a unix:// socket path. A socket left over by a previous run is removed
+// first, any other file at the path is an error. The TLS certificate, key and
+// client CAs are reloaded when their files change, e.g. when the Secret
+// volume they are mounted from is updated.
+func Listen(endpoint string, opts Options) (net.Listener, error) {
+	if IsUnix(endpoint) {
+		if len(opts.files()) > 0 {
+			return nil, fmt.Errorf("TLS is only supported for TCP endpoints, got %q", endpoint)
+		}
+		path := strings.TrimPrefix(endpoint, unixPrefix)
+		if err := removeStaleSocket(path); err != nil {
+			return nil, err
+		}
+		return net.Listen("unix", path)
+	}
+
+	if opts.CertFile == "" && opts.KeyFile == "" {
+		if opts.ClientCAFile != "" {
+			return nil, fmt.Errorf("a server certificate is required to verify client certificates")
+		}
+		return net.Listen("tcp", endpoint)
+	}
+	reloader := &tlsReloader{opts: opts}
+	if _, err := reloader.getConfigForClient(nil); err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return tls.NewListener(listener, &tls.Config{GetConfigForClient: reloader.getConfigForClient}), nil
+}
+
+// removeStaleSocket removes the socket at path, if any. Other files are not
+// removed, so that a mistyped endpoint cannot delete them.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check stale socket %s: %v", path, err)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket %s: %v", path, err)
+	}
+	return nil
+}
+
+// tlsReloader returns the TLS configuration of a TCP endpoint, reloaded
+// whenever the modification time of one of its files changes. The files are
+// checked at each TLS handshake. If the files cannot be loaded, e.g. while
+// they are being replaced, the previous configuration is kept and loading is
+// retried at the next handshake.
+type tlsReloader struct {
+	opts Options
+
+	lock     sync.Mutex
+	config   *tls.Config
+	modTimes []time.Time
+}
+
+func (r *tlsReloader) getConfigForClient(_ *tls.ClientHelloInfo) (*tls.Config, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	modTimes, err := modTimes(r.opts.files())
+	if err == nil && r.config != nil && equalTimes(modTimes, r.modTimes) {
+		return r.config, nil
+	}
+	var config *tls.Config
+	if err == nil {
+		config, err = tlsConfig(r.opts)
+	}
+	if err != nil {
+		if r.config == nil {
+			return nil, err
+		}
+		klog.Errorf("Failed to reload the TLS configuration of the HTTP endpoint, keeping the previous one: %v", err)
+		return r.config, nil
+	}
+	if r.config != nil {
+		klog.Infof("Reloaded the TLS configuration of the HTTP endpoint")
+	}
+	r.config, r.modTimes = config, modTimes
+	return config, nil
+}
+
+// modTimes returns the modification times of files, following symlinks.
+func modTimes(files []string) ([]time.Time, error) {
+	times := make([]time.Time, 0, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		times = append(times, info.ModTime())
+	}
+	return times, nil
+}
+
+func equalTimes(a, b []time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// tlsConfig returns the TLS configuration of a TCP endpoint.
+func tlsConfig(opts Options) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %v", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if opts.ClientCAFile != "" {
+		caData, err := os.ReadFile(opts.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA file: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caData) {
+			return nil, fmt.Errorf("no certificate found in client CA file %s", opts.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
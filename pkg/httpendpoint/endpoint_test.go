@@ -0,0 +1,298 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package httpendpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIsUnix(t *testing.T) {
+	tests := map[string]bool{
+		":8080":                           false,
+		"127.0.0.1:8080":                  false,
+		"unix:///run/nfsexport/http.sock": true,
+	}
+	for endpoint, expected := range tests {
+		if unix := IsUnix(endpoint); unix != expected {
+			t.Errorf("%s: expected unix %v, got %v", endpoint, expected, unix)
+		}
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "http.sock")
+	// A socket left over by a previous run.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to create stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+	listener, err := Listen("unix://"+path, Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+	go http.Serve(listener, okHandler())
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	expectOK(t, client, "http://localhost/metrics")
+}
+
+func TestListenMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey, caFile := writeCA(t, dir)
+	certFile, keyFile := writeCert(t, dir, "server", ca, caKey, x509.ExtKeyUsageServerAuth)
+	clientCertFile, clientKeyFile := writeCert(t, dir, "client", ca, caKey, x509.ExtKeyUsageClientAuth)
+
+	listener, err := Listen("127.0.0.1:0", Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+	go http.Serve(listener, okHandler())
+	url := "https://" + listener.Addr().String() + "/metrics"
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	clientCert, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	if err != nil {
+		t.Fatalf("failed to load client certificate: %v", err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+	expectOK(t, client, url)
+
+	anonymous := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	if resp, err := anonymous.Get(url); err == nil {
+		resp.Body.Close()
+		t.Errorf("expected a client without certificate to be rejected")
+	}
+}
+
+func TestListenReloadsCertificate(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey, _ := writeCA(t, dir)
+	certFile, keyFile := writeCert(t, dir, "server", ca, caKey, x509.ExtKeyUsageServerAuth)
+
+	listener, err := Listen("127.0.0.1:0", Options{CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+	go http.Serve(listener, okHandler())
+	url := "https://" + listener.Addr().String() + "/metrics"
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: pool},
+		DisableKeepAlives: true,
+	}}
+	serverCert := func() []byte {
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].Raw
+	}
+	original := serverCert()
+
+	// Rotate the certificate, and make sure that its modification time
+	// changes even on file systems with a coarse resolution.
+	writeCert(t, dir, "server", ca, caKey, x509.ExtKeyUsageServerAuth)
+	later := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, later, later); err != nil {
+			t.Fatalf("failed to touch %s: %v", file, err)
+		}
+	}
+	rotated := serverCert()
+	if bytes.Equal(rotated, original) {
+		t.Errorf("expected the rotated certificate to be served")
+	}
+
+	// A broken certificate keeps the previous one.
+	if err := os.WriteFile(certFile, []byte("broken"), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.Chtimes(certFile, later.Add(time.Minute), later.Add(time.Minute)); err != nil {
+		t.Fatalf("failed to touch %s: %v", certFile, err)
+	}
+	if !bytes.Equal(serverCert(), rotated) {
+		t.Errorf("expected the previous certificate to be served")
+	}
+}
+
+func TestListenErrors(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey, caFile := writeCA(t, dir)
+	certFile, keyFile := writeCert(t, dir, "server", ca, caKey, x509.ExtKeyUsageServerAuth)
+	notASocket := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(notASocket, nil, 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	tests := []struct {
+		name     string
+		endpoint string
+		opts     Options
+	}{
+		{
+			name:     "TLS with a unix socket",
+			endpoint: "unix://" + filepath.Join(dir, "http.sock"),
+			opts:     Options{CertFile: certFile, KeyFile: keyFile},
+		},
+		{
+			name:     "unix socket at a regular file",
+			endpoint: "unix://" + notASocket,
+		},
+		{
+			name:     "client CA without server certificate",
+			endpoint: "127.0.0.1:0",
+			opts:     Options{ClientCAFile: caFile},
+		},
+		{
+			name:     "missing server certificate",
+			endpoint: "127.0.0.1:0",
+			opts:     Options{CertFile: filepath.Join(dir, "missing.crt"), KeyFile: filepath.Join(dir, "missing.key")},
+		},
+		{
+			name:     "missing client CA file",
+			endpoint: "127.0.0.1:0",
+			opts:     Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: filepath.Join(dir, "missing.crt")},
+		},
+	}
+	for _, test := range tests {
+		if listener, err := Listen(test.endpoint, test.opts); err == nil {
+			listener.Close()
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+	if _, err := os.Stat(notASocket); err != nil {
+		t.Errorf("expected %s not to be removed: %v", notASocket, err)
+	}
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, "ok")
+	})
+}
+
+func expectOK(t *testing.T, client *http.Client, url string) {
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("expected 200 ok, got %d %q", resp.StatusCode, body)
+	}
+}
+
+// writeCA writes a self-signed CA certificate to dir and returns it, its key
+// and the path of its file.
+func writeCA(t *testing.T, dir string) (*x509.Certificate, *ecdsa.PrivateKey, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "nfsexport-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	path := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write CA file: %v", err)
+	}
+	return ca, key, path
+}
+
+// writeCert writes a certificate for 127.0.0.1 signed by ca, and its key, to
+// dir and returns the paths of their files.
+func writeCert(t *testing.T, dir, name string, ca *x509.Certificate, caKey *ecdsa.PrivateKey, usage x509.ExtKeyUsage) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: name},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	certFile := filepath.Join(dir, name+".crt")
+	keyFile := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
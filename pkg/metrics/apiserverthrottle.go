@@ -0,0 +1,77 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+const (
+	apiServerThrottledMetricName = "apiserver_throttled_responses_total"
+	apiServerThrottledHelpMsg    = "Total number of 429 Too Many Requests responses of the API server to the controller"
+	apiServerBackoffMetricName   = "apiserver_backoff_seconds"
+	apiServerBackoffHelpMsg      = "Number of seconds the work queues of the controller still back off because the API server throttles it, 0 if they do not"
+)
+
+var (
+	apiServerThrottledDesc = k8smetrics.NewDesc(
+		k8smetrics.BuildFQName("", subSystem, apiServerThrottledMetricName),
+		apiServerThrottledHelpMsg,
+		nil, nil,
+		k8smetrics.ALPHA, "",
+	)
+	apiServerBackoffDesc = k8smetrics.NewDesc(
+		k8smetrics.BuildFQName("", subSystem, apiServerBackoffMetricName),
+		apiServerBackoffHelpMsg,
+		nil, nil,
+		k8smetrics.ALPHA, "",
+	)
+)
+
+// APIServerThrottle is the state of the backoff of a controller throttled by
+// the API server, see utils.APIServerThrottle.
+type APIServerThrottle interface {
+	ThrottledResponses() int64
+	Backoff() time.Duration
+}
+
+// RegisterAPIServerThrottleMetrics registers a counter of the 429 responses
+// recorded by throttle and a gauge of its current backoff to registry. They
+// are read when the metrics are scraped.
+func RegisterAPIServerThrottleMetrics(registry k8smetrics.KubeRegistry, throttle APIServerThrottle) {
+	registry.CustomMustRegister(&apiServerThrottleCollector{throttle: throttle})
+}
+
+type apiServerThrottleCollector struct {
+	k8smetrics.BaseStableCollector
+
+	throttle APIServerThrottle
+}
+
+var _ k8smetrics.StableCollector = &apiServerThrottleCollector{}
+
+func (c *apiServerThrottleCollector) DescribeWithStability(ch chan<- *k8smetrics.Desc) {
+	ch <- apiServerThrottledDesc
+	ch <- apiServerBackoffDesc
+}
+
+func (c *apiServerThrottleCollector) CollectWithStability(ch chan<- k8smetrics.Metric) {
+	ch <- k8smetrics.NewLazyConstMetric(apiServerThrottledDesc, k8smetrics.CounterValue, float64(c.throttle.ThrottledResponses()))
+	ch <- k8smetrics.NewLazyConstMetric(apiServerBackoffDesc, k8smetrics.GaugeValue, c.throttle.Backoff().Seconds())
+}
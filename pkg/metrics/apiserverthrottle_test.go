@@ -0,0 +1,66 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+type fakeAPIServerThrottle struct {
+	throttled int64
+	backoff   time.Duration
+}
+
+func (f *fakeAPIServerThrottle) ThrottledResponses() int64 { return f.throttled }
+
+func (f *fakeAPIServerThrottle) Backoff() time.Duration { return f.backoff }
+
+func TestAPIServerThrottleMetrics(t *testing.T) {
+	registry := k8smetrics.NewKubeRegistry()
+	RegisterAPIServerThrottleMetrics(registry, &fakeAPIServerThrottle{throttled: 3, backoff: 2 * time.Second})
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			if metric.GetCounter() != nil {
+				values[family.GetName()] = metric.GetCounter().GetValue()
+			} else {
+				values[family.GetName()] = metric.GetGauge().GetValue()
+			}
+		}
+	}
+
+	expected := map[string]float64{
+		"nfsexport_controller_apiserver_throttled_responses_total": 3,
+		"nfsexport_controller_apiserver_backoff_seconds":           2,
+	}
+	for key, value := range expected {
+		got, ok := values[key]
+		if !ok {
+			t.Errorf("expected metric %s, got none", key)
+		} else if got != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, got)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+	"sort"
+
+	"k8s.io/client-go/tools/cache"
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+const (
+	cacheObjectsMetricName = "cache_objects"
+	cacheObjectsHelpMsg    = "Number of objects in the informer cache of a resource"
+	cacheBytesMetricName   = "cache_estimated_bytes"
+	cacheBytesHelpMsg      = "Estimated size in bytes of the informer cache of a resource, extrapolated from the JSON size of a sample of its objects"
+
+	// cacheSampleSize is the number of objects of a cache whose size is
+	// measured on each scrape to estimate the size of the cache.
+	cacheSampleSize = 100
+)
+
+var (
+	cacheObjectsDesc = k8smetrics.NewDesc(
+		k8smetrics.BuildFQName("", subSystem, cacheObjectsMetricName),
+		cacheObjectsHelpMsg,
+		[]string{labelResource}, nil,
+		k8smetrics.ALPHA, "",
+	)
+	cacheBytesDesc = k8smetrics.NewDesc(
+		k8smetrics.BuildFQName("", subSystem, cacheBytesMetricName),
+		cacheBytesHelpMsg,
+		[]string{labelResource}, nil,
+		k8smetrics.ALPHA, "",
+	)
+)
+
+// RegisterCacheMetrics registers gauges reporting the number of objects and
+// the estimated size of the given informer caches, by resource, to
+// registry. The caches are read when the metrics are scraped.
+func RegisterCacheMetrics(registry k8smetrics.KubeRegistry, stores map[string]cache.Store) {
+	registry.CustomMustRegister(&cacheCollector{stores: stores})
+}
+
+type cacheCollector struct {
+	k8smetrics.BaseStableCollector
+
+	stores map[string]cache.Store
+}
+
+var _ k8smetrics.StableCollector = &cacheCollector{}
+
+func (c *cacheCollector) DescribeWithStability(ch chan<- *k8smetrics.Desc) {
+	ch <- cacheObjectsDesc
+	ch <- cacheBytesDesc
+}
+
+func (c *cacheCollector) CollectWithStability(ch chan<- k8smetrics.Metric) {
+	resources := make([]string, 0, len(c.stores))
+	for resource := range c.stores {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	for _, resource := range resources {
+		objects := c.stores[resource].List()
+		ch <- k8smetrics.NewLazyConstMetric(cacheObjectsDesc, k8smetrics.GaugeValue, float64(len(objects)), resource)
+		ch <- k8smetrics.NewLazyConstMetric(cacheBytesDesc, k8smetrics.GaugeValue, float64(estimateSize(objects)), resource)
+	}
+}
+
+// estimateSize estimates the size of objects from the JSON size of up to
+// cacheSampleSize of them, evenly spread across the list.
+func estimateSize(objects []interface{}) int64 {
+	if len(objects) == 0 {
+		return 0
+	}
+	step := 1
+	if len(objects) > cacheSampleSize {
+		step = len(objects) / cacheSampleSize
+	}
+	var sampled, size int64
+	for i := 0; i < len(objects) && sampled < cacheSampleSize; i += step {
+		data, err := json.Marshal(objects[i])
+		if err != nil {
+			continue
+		}
+		size += int64(len(data))
+		sampled++
+	}
+	if sampled == 0 {
+		return 0
+	}
+	return size * int64(len(objects)) / sampled
+}
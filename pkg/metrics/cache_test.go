@@ -0,0 +1,76 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+func TestCacheMetrics(t *testing.T) {
+	claims := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for i := 0; i < 3; i++ {
+		claims.Add(&v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: fmt.Sprintf("claim%d", i)}})
+	}
+	registry := k8smetrics.NewKubeRegistry()
+	RegisterCacheMetrics(registry, map[string]cache.Store{
+		"persistentvolumeclaims": claims,
+		"volumenfsexports":       cache.NewStore(cache.MetaNamespaceKeyFunc),
+	})
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			values[family.GetName()+"/"+metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+
+	if got := values["nfsexport_controller_cache_objects/persistentvolumeclaims"]; got != 3 {
+		t.Errorf("expected 3 cached PVCs, got %v", got)
+	}
+	if got := values["nfsexport_controller_cache_objects/volumenfsexports"]; got != 0 {
+		t.Errorf("expected 0 cached nfsexports, got %v", got)
+	}
+	if got := values["nfsexport_controller_cache_estimated_bytes/persistentvolumeclaims"]; got <= 0 {
+		t.Errorf("expected a positive size for the PVC cache, got %v", got)
+	}
+	if got := values["nfsexport_controller_cache_estimated_bytes/volumenfsexports"]; got != 0 {
+		t.Errorf("expected a size of 0 for the empty nfsexport cache, got %v", got)
+	}
+}
+
+func TestEstimateSize(t *testing.T) {
+	objects := make([]interface{}, 1000)
+	for i := range objects {
+		objects[i] = "0123456789" // 12 bytes once quoted
+	}
+	if got := estimateSize(objects); got != 12000 {
+		t.Errorf("expected 12000 bytes, got %d", got)
+	}
+	if got := estimateSize(nil); got != 0 {
+		t.Errorf("expected 0 bytes for no objects, got %d", got)
+	}
+}
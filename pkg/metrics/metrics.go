@@ -18,6 +18,7 @@ package metrics
 
 import (
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -36,6 +37,17 @@ const (
 	operationLatencyMetricHelpMsg = "Total number of seconds spent by the controller on an operation"
 	operationInFlightName         = "operations_in_flight"
 	operationInFlightHelpMsg      = "Total number of operations in flight"
+	coalescedEventsMetricName     = "coalesced_events_total"
+	coalescedEventsHelpMsg        = "Total number of informer events dropped because an event for the same object was already pending"
+	labelResource                 = "resource"
+	invalidLabelTogglesMetricName = "invalid_label_toggles_total"
+	invalidLabelTogglesHelpMsg    = "Total number of times the invalid label was added to or removed from an object, and of toggles suppressed because the object reached the toggle limit"
+	labelSuppressed               = "suppressed"
+	labelOperationPhase           = "operation_phase"
+	operationPhaseMetricName      = "operation_phase_seconds"
+	operationPhaseHelpMsg         = "Number of seconds spent by an operation in each of its phases"
+	trackedOperationsMetricName   = "tracked_operations"
+	trackedOperationsHelpMsg      = "Number of operations tracked by the controller, including the operations of deleted objects not cleaned up yet"
 	unknownDriverName             = "unknown"
 
 	// CreateNfsExportOperationName is the operation that tracks how long the controller takes to create a nfsexport.
@@ -56,6 +68,17 @@ const (
 	// - End_time: controller removed all finalizers on the VolumeNfsExport CR such that the CR is ready to be removed in the API server.
 	DeleteNfsExportOperationName = "DeleteNfsExport"
 
+	// OperationPhaseQueueWait is the time a nfsexport waited in the work queue
+	// before a worker synced it.
+	OperationPhaseQueueWait = "queue_wait"
+	// OperationPhaseCSI is the time between the creation of a dynamically
+	// provisioned VolumeNfsExportContent and the CSI driver reporting it ready
+	// to use, as recorded by the sidecar on the content status.
+	OperationPhaseCSI = "csi"
+	// OperationPhaseKubernetesWrite is the time the controller spent writing
+	// the VolumeNfsExportContent and the VolumeNfsExport status to the API server.
+	OperationPhaseKubernetesWrite = "kubernetes_write"
+
 	// DynamicNfsExportType represents a nfsexport that is being dynamically provisioned
 	DynamicNfsExportType = nfsexportProvisionType("dynamic")
 	// PreProvisionedNfsExportType represents a nfsexport that is pre-provisioned
@@ -71,10 +94,18 @@ const (
 	// NfsExportStatusTypeCancel represents that a CreateNfsExport, CreateNfsExportAndReady,
 	// or DeleteNfsExport has been deleted before finishing.
 	NfsExportStatusTypeCancel nfsexportStatusType = "cancel"
+	// NfsExportStatusTypeTimeout represents that a CreateNfsExportAndReady
+	// did not finish within the creation timeout of its VolumeNfsExportClass.
+	NfsExportStatusTypeTimeout nfsexportStatusType = "timeout"
 )
 
 var (
 	inFlightCheckInterval = 30 * time.Second
+
+	// deletedOperationTTL is the time the operations of a deleted object
+	// stay in the cache, so that late calls of RecordMetrics still find
+	// them, before they are cleaned up.
+	deletedOperationTTL = 5 * time.Minute
 )
 
 // OperationStatus is the interface type for representing an operation's execution
@@ -110,6 +141,25 @@ type MetricsManager interface {
 
 	// GetRegistry() returns the metrics.KubeRegistry used by this metrics manager.
 	GetRegistry() k8smetrics.KubeRegistry
+
+	// RecordCoalescedEvent counts an informer event for the given resource
+	// that was coalesced with an event already pending in the work queue.
+	RecordCoalescedEvent(resource string)
+
+	// RecordInvalidLabelToggle counts a toggle of the invalid label of an
+	// object of the given resource. suppressed is true if the toggle was
+	// skipped because the object reached the toggle limit.
+	RecordInvalidLabelToggle(resource string, suppressed bool)
+
+	// RecordOperationPhase records the time an operation spent in the given
+	// phase. It is an no-op if the operation has NOT been marked "Started"
+	// previously via invoking "OperationStart", or has already been recorded.
+	RecordOperationPhase(op OperationKey, phase string, duration time.Duration)
+
+	// ObjectDeleted marks the operations of a deleted object. The ones that
+	// are neither recorded nor dropped within a TTL are removed from the
+	// cache without recording metrics.
+	ObjectDeleted(resourceID types.UID)
 }
 
 // OperationKey is a structure which holds information to
@@ -130,6 +180,8 @@ type OperationValue struct {
 
 	// startTime is the time when the operation first started
 	startTime time.Time
+	// deletedTime is the time when the object of the operation was deleted
+	deletedTime time.Time
 }
 
 // NewOperationKey initializes a new OperationKey
@@ -170,6 +222,18 @@ type operationMetricsManager struct {
 
 	// opInFlight is a Gauge metric for the number of operations in flight
 	opInFlight *k8smetrics.Gauge
+
+	// coalescedEvents is a Counter metric for the number of coalesced informer events
+	coalescedEvents *k8smetrics.CounterVec
+
+	// invalidLabelToggles is a Counter metric for the number of invalid label toggles
+	invalidLabelToggles *k8smetrics.CounterVec
+
+	// opPhaseMetrics is a Histogram metrics for the time spent in each phase of an operation
+	opPhaseMetrics *k8smetrics.HistogramVec
+
+	// trackedOperations is a Gauge metric for the number of operations in cache, by operation name
+	trackedOperations *k8smetrics.GaugeVec
 }
 
 // NewMetricsManager creates a new MetricsManager instance
@@ -282,10 +346,48 @@ func (opMgr *operationMetricsManager) init() {
 		},
 	)
 	opMgr.registry.MustRegister(opMgr.opInFlight)
+	opMgr.coalescedEvents = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Subsystem: subSystem,
+			Name:      coalescedEventsMetricName,
+			Help:      coalescedEventsHelpMsg,
+		},
+		[]string{labelResource},
+	)
+	opMgr.registry.MustRegister(opMgr.coalescedEvents)
+	opMgr.invalidLabelToggles = k8smetrics.NewCounterVec(
+		&k8smetrics.CounterOpts{
+			Subsystem: subSystem,
+			Name:      invalidLabelTogglesMetricName,
+			Help:      invalidLabelTogglesHelpMsg,
+		},
+		[]string{labelResource, labelSuppressed},
+	)
+	opMgr.registry.MustRegister(opMgr.invalidLabelToggles)
+	opMgr.opPhaseMetrics = k8smetrics.NewHistogramVec(
+		&k8smetrics.HistogramOpts{
+			Subsystem: subSystem,
+			Name:      operationPhaseMetricName,
+			Help:      operationPhaseHelpMsg,
+			Buckets:   metricBuckets,
+		},
+		[]string{labelDriverName, labelOperationName, labelNfsExportType, labelOperationPhase},
+	)
+	opMgr.registry.MustRegister(opMgr.opPhaseMetrics)
+	opMgr.trackedOperations = k8smetrics.NewGaugeVec(
+		&k8smetrics.GaugeOpts{
+			Subsystem: subSystem,
+			Name:      trackedOperationsMetricName,
+			Help:      trackedOperationsHelpMsg,
+		},
+		[]string{labelOperationName},
+	)
+	opMgr.registry.MustRegister(opMgr.trackedOperations)
 
 	// While we always maintain the number of operations in flight
 	// for every metrics operation start/finish, if any are leaked,
-	// this scheduled routine will catch any leaked operations.
+	// this scheduled routine will catch any leaked operations. It also
+	// cleans up the operations of deleted objects.
 	go opMgr.scheduleOpsInFlightMetric()
 }
 
@@ -294,11 +396,31 @@ func (opMgr *operationMetricsManager) scheduleOpsInFlightMetric() {
 		func() {
 			opMgr.mu.Lock()
 			defer opMgr.mu.Unlock()
+			opMgr.cleanupDeletedOperationsLocked(time.Now())
 			opMgr.opInFlight.Set(float64(len(opMgr.cache)))
 		}()
 	}
 }
 
+// cleanupDeletedOperationsLocked removes the operations of objects deleted
+// for longer than deletedOperationTTL from the cache and updates the gauge
+// of tracked operations.
+// This function must be called with opMgr mutex locked.
+func (opMgr *operationMetricsManager) cleanupDeletedOperationsLocked(now time.Time) {
+	tracked := map[string]int{}
+	for key, val := range opMgr.cache {
+		if !val.deletedTime.IsZero() && now.Sub(val.deletedTime) >= deletedOperationTTL {
+			delete(opMgr.cache, key)
+			continue
+		}
+		tracked[key.Name]++
+	}
+	opMgr.trackedOperations.Reset()
+	for name, count := range tracked {
+		opMgr.trackedOperations.WithLabelValues(name).Set(float64(count))
+	}
+}
+
 func (opMgr *operationMetricsManager) PrepareMetricsPath(mux *http.ServeMux, pattern string, logger promhttp.Logger) error {
 	mux.Handle(pattern, k8smetrics.HandlerFor(
 		opMgr.registry,
@@ -314,6 +436,40 @@ func (opMgr *operationMetricsManager) GetRegistry() k8smetrics.KubeRegistry {
 	return opMgr.registry
 }
 
+// RecordCoalescedEvent counts a coalesced informer event
+func (opMgr *operationMetricsManager) RecordCoalescedEvent(resource string) {
+	opMgr.coalescedEvents.WithLabelValues(resource).Inc()
+}
+
+// RecordInvalidLabelToggle counts a toggle of the invalid label
+func (opMgr *operationMetricsManager) RecordInvalidLabelToggle(resource string, suppressed bool) {
+	opMgr.invalidLabelToggles.WithLabelValues(resource, strconv.FormatBool(suppressed)).Inc()
+}
+
+// RecordOperationPhase records the time spent in a phase of a started operation
+func (opMgr *operationMetricsManager) RecordOperationPhase(op OperationKey, phase string, duration time.Duration) {
+	opMgr.mu.Lock()
+	defer opMgr.mu.Unlock()
+	opVal, exists := opMgr.cache[op]
+	if !exists {
+		return
+	}
+	opMgr.opPhaseMetrics.WithLabelValues(opVal.Driver, op.Name, opVal.NfsExportType, phase).Observe(duration.Seconds())
+}
+
+// ObjectDeleted marks the cached operations of a deleted object
+func (opMgr *operationMetricsManager) ObjectDeleted(resourceID types.UID) {
+	opMgr.mu.Lock()
+	defer opMgr.mu.Unlock()
+	now := time.Now()
+	for key, val := range opMgr.cache {
+		if key.ResourceID == resourceID && val.deletedTime.IsZero() {
+			val.deletedTime = now
+			opMgr.cache[key] = val
+		}
+	}
+}
+
 // nfsexportProvisionType represents which kind of nfsexport a metric is
 type nfsexportProvisionType string
 
@@ -719,6 +719,105 @@ func containsMetrics(expectedMfs, gotMfs []*cmg.MetricFamily) bool {
 	return true
 }
 
+func TestRecordCoalescedEvent(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+
+	mgr.RecordCoalescedEvent("volumenfsexportcontents")
+	mgr.RecordCoalescedEvent("volumenfsexportcontents")
+
+	if err := verifyInFlightMetric(`nfsexport_controller_coalesced_events_total{resource="volumenfsexportcontents"} 2`, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+}
+
+func TestRecordInvalidLabelToggle(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+
+	mgr.RecordInvalidLabelToggle("volumenfsexports", false)
+	mgr.RecordInvalidLabelToggle("volumenfsexports", true)
+	mgr.RecordInvalidLabelToggle("volumenfsexports", true)
+
+	expected := []string{
+		`nfsexport_controller_invalid_label_toggles_total{resource="volumenfsexports",suppressed="false"} 1`,
+		`nfsexport_controller_invalid_label_toggles_total{resource="volumenfsexports",suppressed="true"} 2`,
+	}
+	for _, metric := range expected {
+		if err := verifyInFlightMetric(metric, srvAddr); err != nil {
+			t.Errorf("failed testing [%v]", err)
+		}
+	}
+}
+
+func TestRecordOperationPhase(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+
+	opKey := NewOperationKey(CreateNfsExportAndReadyOperationName, "uid1")
+	// Phases of an operation that has not been started are not recorded.
+	mgr.RecordOperationPhase(opKey, OperationPhaseCSI, 3*time.Second)
+
+	mgr.OperationStart(opKey, NewOperationValue("driver", DynamicNfsExportType))
+	mgr.RecordOperationPhase(opKey, OperationPhaseQueueWait, 200*time.Millisecond)
+	mgr.RecordOperationPhase(opKey, OperationPhaseCSI, 2*time.Second)
+
+	expected := []string{
+		`nfsexport_controller_operation_phase_seconds_count{driver_name="driver",nfsexport_type="dynamic",operation_name="CreateNfsExportAndReady",operation_phase="queue_wait"} 1`,
+		`nfsexport_controller_operation_phase_seconds_sum{driver_name="driver",nfsexport_type="dynamic",operation_name="CreateNfsExportAndReady",operation_phase="queue_wait"} 0.2`,
+		`nfsexport_controller_operation_phase_seconds_count{driver_name="driver",nfsexport_type="dynamic",operation_name="CreateNfsExportAndReady",operation_phase="csi"} 1`,
+		`nfsexport_controller_operation_phase_seconds_sum{driver_name="driver",nfsexport_type="dynamic",operation_name="CreateNfsExportAndReady",operation_phase="csi"} 2`,
+	}
+	for _, metric := range expected {
+		if err := verifyInFlightMetric(metric, srvAddr); err != nil {
+			t.Errorf("failed testing [%v]", err)
+		}
+	}
+}
+
+func TestObjectDeleted(t *testing.T) {
+	mgr, srv := initMgr()
+	srvAddr := "http://" + srv.Addr + httpPattern
+	defer shutdown(srv)
+
+	createKey := NewOperationKey(CreateNfsExportOperationName, "uid1")
+	createAndReadyKey := NewOperationKey(CreateNfsExportAndReadyOperationName, "uid1")
+	otherKey := NewOperationKey(CreateNfsExportOperationName, "uid2")
+	for _, key := range []OperationKey{createKey, createAndReadyKey, otherKey} {
+		mgr.OperationStart(key, NewOperationValue("driver", DynamicNfsExportType))
+	}
+	mgr.ObjectDeleted("uid1")
+
+	opMgr := mgr.(*operationMetricsManager)
+	cleanup := func(now time.Time) {
+		opMgr.mu.Lock()
+		defer opMgr.mu.Unlock()
+		opMgr.cleanupDeletedOperationsLocked(now)
+	}
+
+	// The operations of the deleted object are kept until the TTL elapsed.
+	cleanup(time.Now())
+	if err := verifyInFlightMetric(`nfsexport_controller_tracked_operations{operation_name="CreateNfsExport"} 2`, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+
+	cleanup(time.Now().Add(deletedOperationTTL))
+	if err := verifyInFlightMetric(`nfsexport_controller_tracked_operations{operation_name="CreateNfsExport"} 1`, srvAddr); err != nil {
+		t.Errorf("failed testing [%v]", err)
+	}
+	if err := verifyInFlightMetric(`nfsexport_controller_tracked_operations{operation_name="CreateNfsExportAndReady"}`, srvAddr); err == nil {
+		t.Errorf("expected no tracked CreateNfsExportAndReady operations")
+	}
+	opMgr.mu.Lock()
+	defer opMgr.mu.Unlock()
+	if _, exists := opMgr.cache[otherKey]; !exists || len(opMgr.cache) != 1 {
+		t.Errorf("expected only the operation of uid2 to be left, got %+v", opMgr.cache)
+	}
+}
+
 func TestProcessStartTimeMetricExist(t *testing.T) {
 	mgr, srv := initMgr()
 	defer shutdown(srv)
@@ -0,0 +1,84 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sort"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"k8s.io/client-go/tools/cache"
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+const (
+	restoreSizeMetricName = "restore_size_bytes"
+	restoreSizeHelpMsg    = "Sum of the restore sizes of the VolumeNfsExports of a namespace that are ready to use, as reported in the totalRestoreSize of its NfsExportSummary"
+
+	labelNamespace = "namespace"
+)
+
+var restoreSizeDesc = k8smetrics.NewDesc(
+	k8smetrics.BuildFQName("", subSystem, restoreSizeMetricName),
+	restoreSizeHelpMsg,
+	[]string{labelNamespace}, nil,
+	k8smetrics.ALPHA, "",
+)
+
+// RegisterRestoreSizeMetrics registers a gauge reporting, by namespace, the
+// restore size of the ready VolumeNfsExports of the given informer cache to
+// registry, so that the space used by exports on the storage systems can be
+// followed without querying them. The cache is read when the metric is
+// scraped. Namespaces without ready VolumeNfsExports are not reported.
+func RegisterRestoreSizeMetrics(registry k8smetrics.KubeRegistry, nfsexportStore cache.Store) {
+	registry.CustomMustRegister(&restoreSizeCollector{store: nfsexportStore})
+}
+
+type restoreSizeCollector struct {
+	k8smetrics.BaseStableCollector
+
+	store cache.Store
+}
+
+var _ k8smetrics.StableCollector = &restoreSizeCollector{}
+
+func (c *restoreSizeCollector) DescribeWithStability(ch chan<- *k8smetrics.Desc) {
+	ch <- restoreSizeDesc
+}
+
+func (c *restoreSizeCollector) CollectWithStability(ch chan<- k8smetrics.Metric) {
+	sizes := map[string]int64{}
+	for _, obj := range c.store.List() {
+		nfsexport, ok := obj.(*crdv1.VolumeNfsExport)
+		if !ok || nfsexport.DeletionTimestamp != nil || nfsexport.Status == nil ||
+			nfsexport.Status.ReadyToUse == nil || !*nfsexport.Status.ReadyToUse {
+			continue
+		}
+		size := sizes[nfsexport.Namespace]
+		if nfsexport.Status.RestoreSize != nil {
+			size += nfsexport.Status.RestoreSize.Value()
+		}
+		sizes[nfsexport.Namespace] = size
+	}
+	namespaces := make([]string, 0, len(sizes))
+	for namespace := range sizes {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		ch <- k8smetrics.NewLazyConstMetric(restoreSizeDesc, k8smetrics.GaugeValue, float64(sizes[namespace]), namespace)
+	}
+}
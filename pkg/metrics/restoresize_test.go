@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	k8smetrics "k8s.io/component-base/metrics"
+)
+
+func TestRestoreSizeMetrics(t *testing.T) {
+	newNfsExport := func(namespace, name string, ready bool, size string) *crdv1.VolumeNfsExport {
+		nfsexport := &crdv1.VolumeNfsExport{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Status:     &crdv1.VolumeNfsExportStatus{ReadyToUse: &ready},
+		}
+		if size != "" {
+			restoreSize := resource.MustParse(size)
+			nfsexport.Status.RestoreSize = &restoreSize
+		}
+		return nfsexport
+	}
+	deleted := newNfsExport("team-a", "deleted", true, "1Gi")
+	deleted.DeletionTimestamp = &metav1.Time{}
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, nfsexport := range []*crdv1.VolumeNfsExport{
+		newNfsExport("team-a", "one", true, "1Gi"),
+		newNfsExport("team-a", "two", true, "2Gi"),
+		newNfsExport("team-a", "pending", false, "4Gi"),
+		newNfsExport("team-a", "no-size", true, ""),
+		deleted,
+		newNfsExport("team-b", "one", true, "512Mi"),
+		newNfsExport("team-c", "pending", false, "1Gi"),
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "team-d", Name: "new"}},
+	} {
+		store.Add(nfsexport)
+	}
+	registry := k8smetrics.NewKubeRegistry()
+	RegisterRestoreSizeMetrics(registry, store)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == labelNamespace {
+					values[family.GetName()+"/"+label.GetValue()] = metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+
+	expected := map[string]float64{
+		"nfsexport_controller_restore_size_bytes/team-a": 3 << 30,
+		"nfsexport_controller_restore_size_bytes/team-b": 512 << 20,
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("expected %v, got %v", expected, values)
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/utils/clock"
+)
+
+const (
+	stuckDeletionsMetricName      = "stuck_deletions"
+	stuckDeletionsHelpMsg         = "Number of objects of a resource with a deletion timestamp but remaining finalizers, by time since the deletion was requested"
+	stuckDeletionOldestMetricName = "stuck_deletion_oldest_age_seconds"
+	stuckDeletionOldestHelpMsg    = "Time in seconds since the deletion of the oldest object of a resource still blocked by finalizers was requested, 0 if there is none"
+
+	labelAge = "age"
+)
+
+// stuckDeletionAgeBuckets are the age buckets of the stuck_deletions metric.
+// Each bucket counts the objects deleted for at least the upper bound of the
+// previous bucket and less than its own upper bound. The last bucket has no
+// upper bound.
+var stuckDeletionAgeBuckets = []struct {
+	label      string
+	upperBound time.Duration
+}{
+	{"0s-5m", 5 * time.Minute},
+	{"5m-1h", time.Hour},
+	{"1h-24h", 24 * time.Hour},
+	{"24h+", 0},
+}
+
+var (
+	stuckDeletionsDesc = k8smetrics.NewDesc(
+		k8smetrics.BuildFQName("", subSystem, stuckDeletionsMetricName),
+		stuckDeletionsHelpMsg,
+		[]string{labelResource, labelAge}, nil,
+		k8smetrics.ALPHA, "",
+	)
+	stuckDeletionOldestDesc = k8smetrics.NewDesc(
+		k8smetrics.BuildFQName("", subSystem, stuckDeletionOldestMetricName),
+		stuckDeletionOldestHelpMsg,
+		[]string{labelResource}, nil,
+		k8smetrics.ALPHA, "",
+	)
+)
+
+// RegisterStuckDeletionMetrics registers gauges reporting, by resource, the
+// objects of the given informer caches whose deletion is blocked by
+// finalizers, to registry. The caches are read when the metrics are scraped.
+func RegisterStuckDeletionMetrics(registry k8smetrics.KubeRegistry, stores map[string]cache.Store, clock clock.PassiveClock) {
+	registry.CustomMustRegister(&stuckDeletionCollector{stores: stores, clock: clock})
+}
+
+type stuckDeletionCollector struct {
+	k8smetrics.BaseStableCollector
+
+	stores map[string]cache.Store
+	clock  clock.PassiveClock
+}
+
+var _ k8smetrics.StableCollector = &stuckDeletionCollector{}
+
+func (c *stuckDeletionCollector) DescribeWithStability(ch chan<- *k8smetrics.Desc) {
+	ch <- stuckDeletionsDesc
+	ch <- stuckDeletionOldestDesc
+}
+
+func (c *stuckDeletionCollector) CollectWithStability(ch chan<- k8smetrics.Metric) {
+	resources := make([]string, 0, len(c.stores))
+	for resource := range c.stores {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	now := c.clock.Now()
+	for _, resource := range resources {
+		counts := make([]int, len(stuckDeletionAgeBuckets))
+		var oldest time.Duration
+		for _, obj := range c.stores[resource].List() {
+			accessor, err := meta.Accessor(obj)
+			if err != nil || accessor.GetDeletionTimestamp() == nil || len(accessor.GetFinalizers()) == 0 {
+				continue
+			}
+			age := now.Sub(accessor.GetDeletionTimestamp().Time)
+			if age > oldest {
+				oldest = age
+			}
+			counts[stuckDeletionAgeBucket(age)]++
+		}
+		for i, bucket := range stuckDeletionAgeBuckets {
+			ch <- k8smetrics.NewLazyConstMetric(stuckDeletionsDesc, k8smetrics.GaugeValue, float64(counts[i]), resource, bucket.label)
+		}
+		ch <- k8smetrics.NewLazyConstMetric(stuckDeletionOldestDesc, k8smetrics.GaugeValue, oldest.Seconds(), resource)
+	}
+}
+
+// stuckDeletionAgeBucket returns the index of the age bucket of an object
+// deleted for age.
+func stuckDeletionAgeBucket(age time.Duration) int {
+	last := len(stuckDeletionAgeBuckets) - 1
+	for i, bucket := range stuckDeletionAgeBuckets[:last] {
+		if age < bucket.upperBound {
+			return i
+		}
+	}
+	return last
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	k8smetrics "k8s.io/component-base/metrics"
+	clocktesting "k8s.io/utils/clock/testing"
+)
+
+func TestStuckDeletionMetrics(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	newClaim := func(name string, deletedFor time.Duration, finalizers ...string) *v1.PersistentVolumeClaim {
+		claim := &v1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Finalizers: finalizers}}
+		if deletedFor > 0 {
+			deleted := metav1.NewTime(now.Add(-deletedFor))
+			claim.DeletionTimestamp = &deleted
+		}
+		return claim
+	}
+	claims := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for _, claim := range []*v1.PersistentVolumeClaim{
+		newClaim("live", 0, "kubernetes.io/pvc-protection"),
+		newClaim("no-finalizer", time.Hour),
+		newClaim("recent", time.Minute, "kubernetes.io/pvc-protection"),
+		newClaim("hour", 2*time.Hour, "kubernetes.io/pvc-protection"),
+		newClaim("day", 48*time.Hour, "kubernetes.io/pvc-protection"),
+		newClaim("days", 72*time.Hour, "kubernetes.io/pvc-protection"),
+	} {
+		claims.Add(claim)
+	}
+	registry := k8smetrics.NewKubeRegistry()
+	RegisterStuckDeletionMetrics(registry, map[string]cache.Store{
+		"persistentvolumeclaims": claims,
+		"volumenfsexports":       cache.NewStore(cache.MetaNamespaceKeyFunc),
+	}, clocktesting.NewFakePassiveClock(now))
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather metrics: %v", err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			key := family.GetName() + "/" + labels[labelResource]
+			if age, ok := labels[labelAge]; ok {
+				key += "/" + age
+			}
+			values[key] = metric.GetGauge().GetValue()
+		}
+	}
+
+	expected := map[string]float64{
+		"nfsexport_controller_stuck_deletions/persistentvolumeclaims/0s-5m":             1,
+		"nfsexport_controller_stuck_deletions/persistentvolumeclaims/5m-1h":             0,
+		"nfsexport_controller_stuck_deletions/persistentvolumeclaims/1h-24h":            1,
+		"nfsexport_controller_stuck_deletions/persistentvolumeclaims/24h+":              2,
+		"nfsexport_controller_stuck_deletions/volumenfsexports/0s-5m":                   0,
+		"nfsexport_controller_stuck_deletions/volumenfsexports/5m-1h":                   0,
+		"nfsexport_controller_stuck_deletions/volumenfsexports/1h-24h":                  0,
+		"nfsexport_controller_stuck_deletions/volumenfsexports/24h+":                    0,
+		"nfsexport_controller_stuck_deletion_oldest_age_seconds/persistentvolumeclaims": (72 * time.Hour).Seconds(),
+		"nfsexport_controller_stuck_deletion_oldest_age_seconds/volumenfsexports":       0,
+	}
+	for key, value := range expected {
+		got, ok := values[key]
+		if !ok {
+			t.Errorf("expected metric %s, got none", key)
+		} else if got != value {
+			t.Errorf("expected %s to be %v, got %v", key, value, got)
+		}
+	}
+}
@@ -0,0 +1,515 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mountagent implements the node agent serving NfsExportMounts. It
+// mounts ready VolumeNfsExports at host paths for legacy workloads that
+// cannot consume PersistentVolumeClaims.
+package mountagent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/notify"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	klog "k8s.io/klog/v2"
+)
+
+// Design:
+//
+// Every node runs one agent, which serves the NfsExportMounts whose
+// spec.nodeName is the name of the node. The agent keeps the export mounted
+// at spec.path while the referenced VolumeNfsExport is bound and ready to
+// use, and unmounts it as soon as the VolumeNfsExport is no longer ready or
+// is deleted. The mount source is the nfsexport handle of the bound
+// VolumeNfsExportContent, which NFS exporting drivers report in the form
+// server:/path.
+//
+// The agent adds a finalizer to every NfsExportMount it serves so that a
+// deleted NfsExportMount is only removed after the export is unmounted. The
+// mount table of the host, not the status of the NfsExportMount, is the
+// source of truth for whether the export is mounted, so a restarted agent
+// picks up where it left off.
+//
+// NfsExportMounts can be created by namespaced users, so the agent only
+// mounts below its allowed root, and never at the path of another
+// NfsExportMount of the node: the oldest NfsExportMount of a path wins. It
+// only unmounts a path if the mount table lists the source recorded in the
+// status of the NfsExportMount, so a filesystem mounted there by someone else
+// is left alone.
+
+const (
+	// NfsExportMountFinalizer is added to NfsExportMounts by the mount agent
+	// and removed once the export is unmounted.
+	NfsExportMountFinalizer = "nfsexport.storage.kubernetes.io/nfsexportmount-protection"
+
+	// Event reasons recorded on the NfsExportMount.
+	reasonMounted       = "Mounted"
+	reasonUnmounted     = "Unmounted"
+	reasonMountFailed   = "MountFailed"
+	reasonUnmountFailed = "UnmountFailed"
+	reasonInvalidMount  = "InvalidMount"
+	reasonForeignMount  = "ForeignMount"
+)
+
+// Agent mounts the NfsExportMounts of one node.
+type Agent struct {
+	nodeName      string
+	allowedRoot   string
+	client        clientset.Interface
+	mounter       Mounter
+	eventRecorder record.EventRecorder
+	queue         workqueue.RateLimitingInterface
+
+	mountLister         storagelisters.NfsExportMountLister
+	mountListerSynced   cache.InformerSynced
+	nfsexportLister     storagelisters.VolumeNfsExportLister
+	nfsexportSynced     cache.InformerSynced
+	contentLister       storagelisters.VolumeNfsExportContentLister
+	contentListerSynced cache.InformerSynced
+}
+
+// NewAgent returns a new *Agent that serves the NfsExportMounts of the node
+// nodeName. Only NfsExportMounts with a path below allowedRoot are mounted.
+func NewAgent(
+	nodeName string,
+	allowedRoot string,
+	client clientset.Interface,
+	mounter Mounter,
+	eventRecorder record.EventRecorder,
+	nfsExportMountInformer storageinformers.NfsExportMountInformer,
+	volumeNfsExportInformer storageinformers.VolumeNfsExportInformer,
+	volumeNfsExportContentInformer storageinformers.VolumeNfsExportContentInformer,
+	rateLimiter workqueue.RateLimiter,
+) *Agent {
+	a := &Agent{
+		nodeName:      nodeName,
+		allowedRoot:   filepath.Clean(allowedRoot),
+		client:        client,
+		mounter:       mounter,
+		eventRecorder: eventRecorder,
+		queue:         workqueue.NewNamedRateLimitingQueue(rateLimiter, "nfsexport-mount-agent"),
+	}
+
+	nfsExportMountInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { a.enqueueMountWork(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { a.enqueueMountWork(newObj) },
+			DeleteFunc: func(obj interface{}) { a.enqueueMountWork(obj) },
+		},
+	)
+	a.mountLister = nfsExportMountInformer.Lister()
+	a.mountListerSynced = nfsExportMountInformer.Informer().HasSynced
+
+	notify.NewNotifier(volumeNfsExportInformer).AddHandler(func(event notify.Event) {
+		a.enqueueNfsExportMounts(event.NfsExport)
+	})
+	a.nfsexportLister = volumeNfsExportInformer.Lister()
+	a.nfsexportSynced = volumeNfsExportInformer.Informer().HasSynced
+
+	a.contentLister = volumeNfsExportContentInformer.Lister()
+	a.contentListerSynced = volumeNfsExportContentInformer.Informer().HasSynced
+
+	return a
+}
+
+// Run starts the mount workers and blocks until stopCh is closed.
+func (a *Agent) Run(workers int, stopCh <-chan struct{}) {
+	defer a.queue.ShutDown()
+
+	klog.Infof("Starting nfsexport mount agent on node %q", a.nodeName)
+	defer klog.Infof("Shutting nfsexport mount agent")
+
+	if !cache.WaitForCacheSync(stopCh, a.mountListerSynced, a.nfsexportSynced, a.contentListerSynced) {
+		klog.Errorf("Cannot sync caches")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(a.mountWorker, 0, stopCh)
+	}
+
+	<-stopCh
+}
+
+// enqueueMountWork adds the key of a NfsExportMount of this node to the work
+// queue.
+func (a *Agent) enqueueMountWork(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	mount, ok := obj.(*crdv1.NfsExportMount)
+	if !ok || mount.Spec.NodeName != a.nodeName {
+		return
+	}
+	objName, err := cache.DeletionHandlingMetaNamespaceKeyFunc(mount)
+	if err != nil {
+		klog.Errorf("failed to get key from object: %v, %v", err, mount)
+		return
+	}
+	klog.V(5).Infof("enqueued %q for mount sync", objName)
+	a.queue.Add(objName)
+}
+
+// enqueueNfsExportMounts enqueues the NfsExportMounts of this node that
+// reference the given VolumeNfsExport, so that they follow its readiness.
+// It is called by the notify.Notifier of the VolumeNfsExport informer when
+// the VolumeNfsExport becomes ready, fails, is no longer ready or is deleted.
+func (a *Agent) enqueueNfsExportMounts(nfsexport *crdv1.VolumeNfsExport) {
+	mounts, err := a.mountLister.NfsExportMounts(nfsexport.Namespace).List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list NfsExportMounts of nfsexport %s: %v", utils.NfsExportKey(nfsexport), err)
+		return
+	}
+	for _, mount := range mounts {
+		if mount.Spec.VolumeNfsExportName == nfsexport.Name {
+			a.enqueueMountWork(mount)
+		}
+	}
+}
+
+// mountWorker is the main worker for NfsExportMounts.
+func (a *Agent) mountWorker() {
+	keyObj, quit := a.queue.Get()
+	if quit {
+		return
+	}
+	defer a.queue.Done(keyObj)
+
+	if err := a.syncMountByKey(keyObj.(string)); err != nil {
+		a.queue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to sync NfsExportMount %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		a.queue.Forget(keyObj)
+	}
+}
+
+// syncMountByKey mounts or unmounts the export of a NfsExportMount to match
+// the readiness of its VolumeNfsExport.
+func (a *Agent) syncMountByKey(key string) error {
+	klog.V(5).Infof("syncMountByKey[%s]", key)
+
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		klog.Errorf("error getting namespace & name of NfsExportMount %q from informer: %v", key, err)
+		return nil
+	}
+	mount, err := a.mountLister.NfsExportMounts(namespace).Get(name)
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			// The finalizer is removed only after the export is unmounted.
+			return nil
+		}
+		return err
+	}
+	if mount.Spec.NodeName != a.nodeName {
+		return nil
+	}
+
+	if mount.ObjectMeta.DeletionTimestamp != nil {
+		if !utils.ContainsString(mount.ObjectMeta.Finalizers, NfsExportMountFinalizer) {
+			return nil
+		}
+		if err := a.unmount(mount); err != nil {
+			return err
+		}
+		return a.removeFinalizer(mount)
+	}
+
+	if err := a.validateMount(mount); err != nil {
+		a.eventRecorder.Event(mount, v1.EventTypeWarning, reasonInvalidMount, err.Error())
+		// Retrying does not help until the NfsExportMount is recreated.
+		return a.updateStatus(mount, false, "", err)
+	}
+
+	if !utils.ContainsString(mount.ObjectMeta.Finalizers, NfsExportMountFinalizer) {
+		if mount, err = a.addFinalizer(mount); err != nil {
+			return err
+		}
+	}
+
+	source, err := a.getSource(mount)
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		if err := a.unmount(mount); err != nil {
+			return err
+		}
+		return a.updateStatus(mount, false, "", nil)
+	}
+	return a.mount(mount, source)
+}
+
+// validateMount checks the fields of a NfsExportMount that the API server
+// cannot validate: the path must be below the allowed root of the agent and
+// not be used by an older NfsExportMount of the node.
+func (a *Agent) validateMount(mount *crdv1.NfsExportMount) error {
+	if err := validateMountPath(mount, a.allowedRoot); err != nil {
+		return err
+	}
+	mounts, err := a.mountLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	for _, other := range mounts {
+		if (other.Namespace == mount.Namespace && other.Name == mount.Name) || other.Spec.NodeName != mount.Spec.NodeName || filepath.Clean(other.Spec.Path) != filepath.Clean(mount.Spec.Path) {
+			continue
+		}
+		if isOlderMount(other, mount) {
+			return fmt.Errorf("path %q is already used by NfsExportMount %s/%s", mount.Spec.Path, other.Namespace, other.Name)
+		}
+	}
+	return nil
+}
+
+// isOlderMount returns true if a was created before b. NfsExportMounts
+// created in the same second are ordered by namespace and name.
+func isOlderMount(a, b *crdv1.NfsExportMount) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// validateMountPath checks that the path of a NfsExportMount is below
+// allowedRoot and that its subPath stays within the export.
+func validateMountPath(mount *crdv1.NfsExportMount, allowedRoot string) error {
+	if !filepath.IsAbs(mount.Spec.Path) {
+		return fmt.Errorf("path %q must be an absolute path", mount.Spec.Path)
+	}
+	if !strings.HasPrefix(filepath.Clean(mount.Spec.Path), strings.TrimSuffix(allowedRoot, "/")+"/") {
+		return fmt.Errorf("path %q must be below %s", mount.Spec.Path, allowedRoot)
+	}
+	if mount.Spec.SubPath != "" {
+		if path.IsAbs(mount.Spec.SubPath) {
+			return fmt.Errorf("subPath %q must be a relative path", mount.Spec.SubPath)
+		}
+		for _, element := range strings.Split(mount.Spec.SubPath, "/") {
+			if element == ".." {
+				return fmt.Errorf("subPath %q must not contain '..'", mount.Spec.SubPath)
+			}
+		}
+	}
+	return nil
+}
+
+// getSource returns the NFS source of the export to mount, or an empty string
+// if the VolumeNfsExport is missing, being deleted or not ready to use.
+func (a *Agent) getSource(mount *crdv1.NfsExportMount) (string, error) {
+	nfsexport, err := a.nfsexportLister.VolumeNfsExports(mount.Namespace).Get(mount.Spec.VolumeNfsExportName)
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if nfsexport.ObjectMeta.DeletionTimestamp != nil || !utils.IsBoundVolumeNfsExportContentNameSet(nfsexport) || !utils.IsNfsExportReady(nfsexport) {
+		return "", nil
+	}
+	content, err := a.contentLister.Get(*nfsexport.Status.BoundVolumeNfsExportContentName)
+	if err != nil {
+		if apierrs.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if content.Status == nil || content.Status.NfsExportHandle == nil || *content.Status.NfsExportHandle == "" {
+		return "", nil
+	}
+	return nfsSource(*content.Status.NfsExportHandle, mount.Spec.SubPath)
+}
+
+// nfsSource appends subPath to the export path of an NFS source in the form
+// server:/path.
+func nfsSource(handle, subPath string) (string, error) {
+	i := strings.Index(handle, ":/")
+	if i <= 0 {
+		return "", fmt.Errorf("nfsexport handle %q is not an NFS source in the form server:/path", handle)
+	}
+	if subPath == "" {
+		return handle, nil
+	}
+	return handle[:i+1] + path.Join(handle[i+1:], subPath), nil
+}
+
+// mount mounts source at the path of the NfsExportMount. An export mounted
+// by the agent from a different source is unmounted first.
+func (a *Agent) mount(mount *crdv1.NfsExportMount, source string) error {
+	target := mount.Spec.Path
+	mountedSource, err := a.mounter.MountSource(target)
+	if err != nil {
+		return err
+	}
+	if mountedSource == source {
+		return a.updateStatus(mount, true, source, nil)
+	}
+	if mountedSource != "" {
+		if !isMountedFrom(mount, mountedSource) {
+			return a.mountFailed(mount, reasonForeignMount, fmt.Errorf("%s is already mounted at %s", mountedSource, target))
+		}
+		klog.V(2).Infof("source of NfsExportMount %s/%s changed to %s, remounting", mount.Namespace, mount.Name, source)
+		if err := a.unmount(mount); err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(target, 0750); err != nil {
+		return a.mountFailed(mount, reasonMountFailed, fmt.Errorf("failed to create %s: %v", target, err))
+	}
+	if err := a.mounter.Mount(source, target, mount.Spec.MountOptions); err != nil {
+		return a.mountFailed(mount, reasonMountFailed, err)
+	}
+	klog.V(2).Infof("mounted %s at %s for NfsExportMount %s/%s", source, target, mount.Namespace, mount.Name)
+	a.eventRecorder.Eventf(mount, v1.EventTypeNormal, reasonMounted, "Mounted %s at %s", source, target)
+	return a.updateStatus(mount, true, source, nil)
+}
+
+// unmount unmounts the path of the NfsExportMount if the export mounted
+// there by the agent is listed in the mount table. Anything else mounted there
+// is left alone.
+func (a *Agent) unmount(mount *crdv1.NfsExportMount) error {
+	target := mount.Spec.Path
+	mountedSource, err := a.mounter.MountSource(target)
+	if err != nil {
+		return err
+	}
+	if mountedSource == "" {
+		return nil
+	}
+	if !isMountedFrom(mount, mountedSource) {
+		klog.V(2).Infof("not unmounting %s mounted at %s, it was not mounted for NfsExportMount %s/%s", mountedSource, target, mount.Namespace, mount.Name)
+		return nil
+	}
+	if err := a.mounter.Unmount(target); err != nil {
+		return a.mountFailed(mount, reasonUnmountFailed, err)
+	}
+	klog.V(2).Infof("unmounted %s for NfsExportMount %s/%s", target, mount.Namespace, mount.Name)
+	a.eventRecorder.Eventf(mount, v1.EventTypeNormal, reasonUnmounted, "Unmounted %s", target)
+	return nil
+}
+
+// isMountedFrom returns true if the status of the NfsExportMount records
+// source as the source mounted by the agent.
+func isMountedFrom(mount *crdv1.NfsExportMount, source string) bool {
+	return mount.Status != nil && mount.Status.Source != nil && *mount.Status.Source == source
+}
+
+// mountFailed records err on the NfsExportMount and returns it, so that the
+// NfsExportMount is retried.
+func (a *Agent) mountFailed(mount *crdv1.NfsExportMount, reason string, err error) error {
+	a.eventRecorder.Event(mount, v1.EventTypeWarning, reason, err.Error())
+	mounted := mount.Status != nil && mount.Status.Mounted != nil && *mount.Status.Mounted
+	var source string
+	if mounted && mount.Status.Source != nil {
+		source = *mount.Status.Source
+	}
+	if updateErr := a.updateStatus(mount, mounted, source, err); updateErr != nil {
+		klog.Errorf("failed to update status of NfsExportMount %s/%s: %v", mount.Namespace, mount.Name, updateErr)
+	}
+	return err
+}
+
+// updateStatus updates the status of the NfsExportMount if it changed.
+func (a *Agent) updateStatus(mount *crdv1.NfsExportMount, mounted bool, source string, mountErr error) error {
+	status := &crdv1.NfsExportMountStatus{Mounted: &mounted}
+	if source != "" {
+		status.Source = &source
+	}
+	now := metav1.Now()
+	wasMounted := false
+	if mount.Status != nil {
+		status.LastTransitionTime = mount.Status.LastTransitionTime
+		wasMounted = mount.Status.Mounted != nil && *mount.Status.Mounted
+	}
+	if mount.Status == nil || mount.Status.Mounted == nil || wasMounted != mounted {
+		status.LastTransitionTime = &now
+	}
+	if mountErr != nil {
+		message := mountErr.Error()
+		status.Error = &crdv1.VolumeNfsExportError{Time: &now, Message: &message}
+		if mount.Status != nil && mount.Status.Error != nil && mount.Status.Error.Message != nil && *mount.Status.Error.Message == message {
+			// Keep the time of the first occurrence of a repeated error.
+			status.Error.Time = mount.Status.Error.Time
+		}
+	}
+	if mount.Status != nil && equalStatus(mount.Status, status) {
+		return nil
+	}
+
+	mountClone := mount.DeepCopy()
+	mountClone.Status = status
+	if _, err := a.client.NfsExportV1().NfsExportMounts(mount.Namespace).UpdateStatus(context.TODO(), mountClone, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update status of NfsExportMount %s/%s: %v", mount.Namespace, mount.Name, err)
+	}
+	return nil
+}
+
+func equalStatus(a, b *crdv1.NfsExportMountStatus) bool {
+	equalString := func(x, y *string) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && *x == *y)
+	}
+	if (a.Mounted == nil) != (b.Mounted == nil) || (a.Mounted != nil && *a.Mounted != *b.Mounted) {
+		return false
+	}
+	if !equalString(a.Source, b.Source) {
+		return false
+	}
+	if (a.Error == nil) != (b.Error == nil) {
+		return false
+	}
+	return a.Error == nil || equalString(a.Error.Message, b.Error.Message)
+}
+
+func (a *Agent) addFinalizer(mount *crdv1.NfsExportMount) (*crdv1.NfsExportMount, error) {
+	mountClone := mount.DeepCopy()
+	mountClone.ObjectMeta.Finalizers = append(mountClone.ObjectMeta.Finalizers, NfsExportMountFinalizer)
+	updated, err := a.client.NfsExportV1().NfsExportMounts(mount.Namespace).Update(context.TODO(), mountClone, metav1.UpdateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add finalizer to NfsExportMount %s/%s: %v", mount.Namespace, mount.Name, err)
+	}
+	return updated, nil
+}
+
+func (a *Agent) removeFinalizer(mount *crdv1.NfsExportMount) error {
+	mountClone := mount.DeepCopy()
+	mountClone.ObjectMeta.Finalizers = utils.RemoveString(mountClone.ObjectMeta.Finalizers, NfsExportMountFinalizer)
+	if _, err := a.client.NfsExportV1().NfsExportMounts(mount.Namespace).Update(context.TODO(), mountClone, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to remove finalizer from NfsExportMount %s/%s: %v", mount.Namespace, mount.Name, err)
+	}
+	return nil
+}
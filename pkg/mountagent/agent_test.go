@@ -0,0 +1,404 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mountagent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientsetfake "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const testNode = "node1"
+
+type fakeMounter struct {
+	mounts    map[string]string // target -> source
+	mountErr  error
+	unmounted []string
+}
+
+func (m *fakeMounter) Mount(source, target string, options []string) error {
+	if m.mountErr != nil {
+		return m.mountErr
+	}
+	m.mounts[target] = source
+	return nil
+}
+
+func (m *fakeMounter) Unmount(target string) error {
+	delete(m.mounts, target)
+	m.unmounted = append(m.unmounted, target)
+	return nil
+}
+
+func (m *fakeMounter) MountSource(target string) (string, error) {
+	return m.mounts[target], nil
+}
+
+func newNfsExportMount(name, nodeName, target string, finalizers ...string) *crdv1.NfsExportMount {
+	return &crdv1.NfsExportMount{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Finalizers: finalizers},
+		Spec: crdv1.NfsExportMountSpec{
+			VolumeNfsExportName: "snap1",
+			NodeName:            nodeName,
+			Path:                target,
+		},
+	}
+}
+
+func newNfsExport(ready bool) *crdv1.VolumeNfsExport {
+	contentName := "content1"
+	return &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"},
+		Status: &crdv1.VolumeNfsExportStatus{
+			BoundVolumeNfsExportContentName: &contentName,
+			ReadyToUse:                      &ready,
+		},
+	}
+}
+
+func newNfsExportContent(handle string) *crdv1.VolumeNfsExportContent {
+	return &crdv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "content1"},
+		Status:     &crdv1.VolumeNfsExportContentStatus{NfsExportHandle: &handle},
+	}
+}
+
+type agentFixture struct {
+	agent        *Agent
+	client       *clientsetfake.Clientset
+	mounter      *fakeMounter
+	mountIndexer cache.Indexer
+}
+
+func newAgentFixture(t *testing.T, mount *crdv1.NfsExportMount, nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) *agentFixture {
+	mountIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	nfsexportIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	contentIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	mountIndexer.Add(mount)
+	if nfsexport != nil {
+		nfsexportIndexer.Add(nfsexport)
+	}
+	if content != nil {
+		contentIndexer.Add(content)
+	}
+	client := clientsetfake.NewSimpleClientset(mount)
+	mounter := &fakeMounter{mounts: map[string]string{}}
+	return &agentFixture{
+		agent: &Agent{
+			nodeName:        testNode,
+			allowedRoot:     filepath.Clean(os.TempDir()),
+			client:          client,
+			mounter:         mounter,
+			eventRecorder:   record.NewFakeRecorder(10),
+			mountLister:     storagelisters.NewNfsExportMountLister(mountIndexer),
+			nfsexportLister: storagelisters.NewVolumeNfsExportLister(nfsexportIndexer),
+			contentLister:   storagelisters.NewVolumeNfsExportContentLister(contentIndexer),
+		},
+		client:       client,
+		mounter:      mounter,
+		mountIndexer: mountIndexer,
+	}
+}
+
+func (f *agentFixture) get(t *testing.T, name string) *crdv1.NfsExportMount {
+	mount, err := f.client.NfsExportV1().NfsExportMounts("default").Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get NfsExportMount %s: %v", name, err)
+	}
+	return mount
+}
+
+func isMounted(mount *crdv1.NfsExportMount) bool {
+	return mount.Status != nil && mount.Status.Mounted != nil && *mount.Status.Mounted
+}
+
+func TestSyncMountReadyNfsExport(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "mnt")
+	mount := newNfsExportMount("mount1", testNode, target)
+	mount.Spec.SubPath = "data"
+	f := newAgentFixture(t, mount, newNfsExport(true), newNfsExportContent("server:/exports/vol1"))
+
+	if err := f.agent.syncMountByKey("default/mount1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source := f.mounter.mounts[target]; source != "server:/exports/vol1/data" {
+		t.Errorf("expected server:/exports/vol1/data to be mounted at %s, got %q", target, source)
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("expected %s to be created: %v", target, err)
+	}
+	updated := f.get(t, "mount1")
+	if !utils.ContainsString(updated.Finalizers, NfsExportMountFinalizer) {
+		t.Errorf("expected finalizer to be added, got %v", updated.Finalizers)
+	}
+	if !isMounted(updated) || updated.Status.Source == nil || *updated.Status.Source != "server:/exports/vol1/data" {
+		t.Errorf("expected status to report the mount, got %+v", updated.Status)
+	}
+}
+
+func TestSyncMountFollowsReadiness(t *testing.T) {
+	tests := []struct {
+		name      string
+		nfsexport *crdv1.VolumeNfsExport
+		content   *crdv1.VolumeNfsExportContent
+	}{
+		{
+			name:      "nfsexport not ready",
+			nfsexport: newNfsExport(false),
+			content:   newNfsExportContent("server:/exports/vol1"),
+		},
+		{
+			name:    "nfsexport deleted",
+			content: newNfsExportContent("server:/exports/vol1"),
+		},
+		{
+			name:      "content without handle",
+			nfsexport: newNfsExport(true),
+			content:   &crdv1.VolumeNfsExportContent{ObjectMeta: metav1.ObjectMeta{Name: "content1"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target := t.TempDir()
+			mounted := true
+			source := "server:/exports/vol1"
+			mount := newNfsExportMount("mount1", testNode, target, NfsExportMountFinalizer)
+			mount.Status = &crdv1.NfsExportMountStatus{Mounted: &mounted, Source: &source}
+			f := newAgentFixture(t, mount, test.nfsexport, test.content)
+			f.mounter.mounts[target] = source
+
+			if err := f.agent.syncMountByKey("default/mount1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, ok := f.mounter.mounts[target]; ok {
+				t.Errorf("expected %s to be unmounted", target)
+			}
+			if updated := f.get(t, "mount1"); isMounted(updated) || updated.Status.Source != nil {
+				t.Errorf("expected status to report no mount, got %+v", updated.Status)
+			}
+		})
+	}
+}
+
+func TestSyncMountDeleted(t *testing.T) {
+	target := t.TempDir()
+	mount := newNfsExportMount("mount1", testNode, target, NfsExportMountFinalizer)
+	mounted := true
+	source := "server:/exports/vol1"
+	mount.Status = &crdv1.NfsExportMountStatus{Mounted: &mounted, Source: &source}
+	now := metav1.Now()
+	mount.DeletionTimestamp = &now
+	f := newAgentFixture(t, mount, newNfsExport(true), newNfsExportContent("server:/exports/vol1"))
+	f.mounter.mounts[target] = "server:/exports/vol1"
+
+	if err := f.agent.syncMountByKey("default/mount1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := f.mounter.mounts[target]; ok {
+		t.Errorf("expected %s to be unmounted", target)
+	}
+	if updated := f.get(t, "mount1"); utils.ContainsString(updated.Finalizers, NfsExportMountFinalizer) {
+		t.Errorf("expected finalizer to be removed, got %v", updated.Finalizers)
+	}
+}
+
+func TestSyncMountLeavesForeignMounts(t *testing.T) {
+	t.Run("not unmounted", func(t *testing.T) {
+		target := t.TempDir()
+		mount := newNfsExportMount("mount1", testNode, target, NfsExportMountFinalizer)
+		now := metav1.Now()
+		mount.DeletionTimestamp = &now
+		f := newAgentFixture(t, mount, newNfsExport(true), newNfsExportContent("server:/exports/vol1"))
+		f.mounter.mounts[target] = "/dev/sda1"
+
+		if err := f.agent.syncMountByKey("default/mount1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(f.mounter.unmounted) != 0 {
+			t.Errorf("expected nothing to be unmounted, got %v", f.mounter.unmounted)
+		}
+		if updated := f.get(t, "mount1"); utils.ContainsString(updated.Finalizers, NfsExportMountFinalizer) {
+			t.Errorf("expected finalizer to be removed, got %v", updated.Finalizers)
+		}
+	})
+
+	t.Run("not mounted over", func(t *testing.T) {
+		target := t.TempDir()
+		mount := newNfsExportMount("mount1", testNode, target, NfsExportMountFinalizer)
+		f := newAgentFixture(t, mount, newNfsExport(true), newNfsExportContent("server:/exports/vol1"))
+		f.mounter.mounts[target] = "/dev/sda1"
+
+		if err := f.agent.syncMountByKey("default/mount1"); err == nil {
+			t.Fatalf("expected error, got none")
+		}
+		if source := f.mounter.mounts[target]; source != "/dev/sda1" || len(f.mounter.unmounted) != 0 {
+			t.Errorf("expected /dev/sda1 to stay mounted at %s, got %q", target, source)
+		}
+		if updated := f.get(t, "mount1"); isMounted(updated) || updated.Status.Error == nil {
+			t.Errorf("expected status to report the foreign mount, got %+v", updated.Status)
+		}
+	})
+}
+
+func TestSyncMountConflictingPath(t *testing.T) {
+	target := t.TempDir()
+	older := newNfsExportMount("mount1", testNode, target)
+	older.CreationTimestamp = metav1.Unix(100, 0)
+	newer := newNfsExportMount("mount2", testNode, target+"/")
+	newer.CreationTimestamp = metav1.Unix(200, 0)
+	f := newAgentFixture(t, newer, newNfsExport(true), newNfsExportContent("server:/exports/vol1"))
+	f.mountIndexer.Add(older)
+	f.client.Tracker().Add(older)
+
+	if err := f.agent.syncMountByKey("default/mount2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.mounter.mounts) != 0 {
+		t.Errorf("expected no mounts, got %v", f.mounter.mounts)
+	}
+	if updated := f.get(t, "mount2"); isMounted(updated) || updated.Status.Error == nil {
+		t.Errorf("expected status to report the conflict, got %+v", updated.Status)
+	}
+
+	if err := f.agent.syncMountByKey("default/mount1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if source := f.mounter.mounts[target]; source != "server:/exports/vol1" {
+		t.Errorf("expected the older NfsExportMount to be mounted at %s, got %q", target, source)
+	}
+}
+
+func TestSyncMountIgnoresOtherNodes(t *testing.T) {
+	mount := newNfsExportMount("mount1", "node2", t.TempDir())
+	f := newAgentFixture(t, mount, newNfsExport(true), newNfsExportContent("server:/exports/vol1"))
+
+	if err := f.agent.syncMountByKey("default/mount1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.mounter.mounts) != 0 {
+		t.Errorf("expected no mounts, got %v", f.mounter.mounts)
+	}
+	if len(f.client.Actions()) != 0 {
+		t.Errorf("expected no API calls, got %v", f.client.Actions())
+	}
+}
+
+func TestSyncMountFailure(t *testing.T) {
+	target := t.TempDir()
+	f := newAgentFixture(t, newNfsExportMount("mount1", testNode, target), newNfsExport(true), newNfsExportContent("server:/exports/vol1"))
+	f.mounter.mountErr = errors.New("connection refused")
+
+	if err := f.agent.syncMountByKey("default/mount1"); err == nil {
+		t.Fatalf("expected error, got none")
+	}
+	updated := f.get(t, "mount1")
+	if isMounted(updated) || updated.Status.Error == nil || *updated.Status.Error.Message != "connection refused" {
+		t.Errorf("expected status to report the mount error, got %+v", updated.Status)
+	}
+}
+
+func TestValidateMount(t *testing.T) {
+	tests := []struct {
+		path      string
+		subPath   string
+		expectErr bool
+	}{
+		{path: "/mnt/nfsexports/export", subPath: "a/b"},
+		{path: "mnt/nfsexports/export", expectErr: true},
+		{path: "/", expectErr: true},
+		{path: "/mnt/nfsexports", expectErr: true},
+		{path: "/mnt/nfsexports/../../etc", expectErr: true},
+		{path: "/mnt/nfsexports-other/export", expectErr: true},
+		{path: "/mnt/nfsexports/export", subPath: "/a", expectErr: true},
+		{path: "/mnt/nfsexports/export", subPath: "a/../../b", expectErr: true},
+	}
+	for _, test := range tests {
+		mount := newNfsExportMount("mount1", testNode, test.path)
+		mount.Spec.SubPath = test.subPath
+		if err := validateMountPath(mount, "/mnt/nfsexports"); (err != nil) != test.expectErr {
+			t.Errorf("validateMount(%q, %q) returned %v, expected error: %v", test.path, test.subPath, err, test.expectErr)
+		}
+	}
+}
+
+func TestNfsSource(t *testing.T) {
+	tests := []struct {
+		handle    string
+		subPath   string
+		expected  string
+		expectErr bool
+	}{
+		{handle: "server:/exports/vol1", expected: "server:/exports/vol1"},
+		{handle: "server:/exports/vol1", subPath: "a/b/", expected: "server:/exports/vol1/a/b"},
+		{handle: "10.0.0.1:/", subPath: "a", expected: "10.0.0.1:/a"},
+		{handle: "vol1", expectErr: true},
+		{handle: ":/exports", expectErr: true},
+	}
+	for _, test := range tests {
+		source, err := nfsSource(test.handle, test.subPath)
+		if (err != nil) != test.expectErr {
+			t.Errorf("nfsSource(%q, %q) returned error %v, expected error: %v", test.handle, test.subPath, err, test.expectErr)
+			continue
+		}
+		if source != test.expected {
+			t.Errorf("nfsSource(%q, %q) = %q, expected %q", test.handle, test.subPath, source, test.expected)
+		}
+	}
+}
+
+func TestMountSource(t *testing.T) {
+	mountInfo := `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+36 22 0:40 / /mnt/nfsexports/a rw,relatime shared:20 - nfs server:/exports/vol1 rw,vers=4.2
+37 22 0:41 / /mnt/nfsexports/with\040space rw,relatime - nfs4 server:/exports/vol2 rw
+38 36 0:42 / /mnt/nfsexports/a rw,relatime shared:21 master:3 - nfs server:/exports/vol3 rw
+`
+	mountInfoFile := filepath.Join(t.TempDir(), "mountinfo")
+	if err := os.WriteFile(mountInfoFile, []byte(mountInfo), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", mountInfoFile, err)
+	}
+	m := &execMounter{mountInfoFile: mountInfoFile}
+
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{target: "/mnt/nfsexports/a/", expected: "server:/exports/vol3"},
+		{target: "/mnt/nfsexports/with space", expected: "server:/exports/vol2"},
+		{target: "/mnt/nfsexports/b", expected: ""},
+	}
+	for _, test := range tests {
+		source, err := m.MountSource(test.target)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if source != test.expected {
+			t.Errorf("MountSource(%q) = %q, expected %q", test.target, source, test.expected)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mountagent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Mounter mounts and unmounts NFS exports on the local host.
+type Mounter interface {
+	// Mount mounts the NFS source, in the form server:/path, at target.
+	Mount(source, target string, options []string) error
+	// Unmount unmounts target.
+	Unmount(target string) error
+	// MountSource returns the source of the filesystem mounted at target as
+	// listed in the mount table, or an empty string if nothing is mounted
+	// there.
+	MountSource(target string) (string, error)
+}
+
+// execMounter implements Mounter with the mount and umount binaries of the
+// host, and reads the mount table from mountInfoFile.
+type execMounter struct {
+	mountInfoFile string
+}
+
+// NewMounter returns a Mounter that calls the mount and umount binaries.
+// The agent must run in the host mount namespace, or with the host paths
+// mounted with bidirectional mount propagation, for the mounts to be visible
+// to other workloads.
+func NewMounter() Mounter {
+	return &execMounter{mountInfoFile: "/proc/self/mountinfo"}
+}
+
+func (m *execMounter) Mount(source, target string, options []string) error {
+	args := []string{"-t", "nfs"}
+	if len(options) > 0 {
+		args = append(args, "-o", strings.Join(options, ","))
+	}
+	args = append(args, source, target)
+	if output, err := exec.Command("mount", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("mount %s at %s failed: %v, output: %s", source, target, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (m *execMounter) Unmount(target string) error {
+	if output, err := exec.Command("umount", target).CombinedOutput(); err != nil {
+		return fmt.Errorf("umount %s failed: %v, output: %s", target, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// MountSource parses the mount table in the format of /proc/self/mountinfo:
+//
+//	36 35 98:0 /mnt1 /mnt/parent rw,noatime master:1 - nfs server:/export rw
+//
+// The fifth field is the mount point, and the source follows the separator
+// "-" and the filesystem type. If several filesystems are mounted at target,
+// the last one, which is the visible one, is returned.
+func (m *execMounter) MountSource(target string) (string, error) {
+	f, err := os.Open(m.mountInfoFile)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	target = filepath.Clean(target)
+	source := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || unescapeMountPath(fields[4]) != target {
+			continue
+		}
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				if i+2 < len(fields) {
+					source = unescapeMountPath(fields[i+2])
+				}
+				break
+			}
+		}
+	}
+	return source, scanner.Err()
+}
+
+// unescapeMountPath undoes the octal escaping of spaces, tabs, newlines and
+// backslashes in the mount table.
+func unescapeMountPath(path string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(path)
+}
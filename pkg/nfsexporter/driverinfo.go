@@ -0,0 +1,63 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfsexporter
+
+import (
+	"context"
+	"sort"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	csirpc "github.com/kubernetes-csi/csi-lib-utils/rpc"
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+
+	"google.golang.org/grpc"
+)
+
+// GetDriverInfo returns the version and the capabilities of the CSI driver,
+// from GetPluginInfo, GetPluginCapabilities and, if the driver has a
+// controller service, ControllerGetCapabilities.
+func GetDriverInfo(ctx context.Context, conn *grpc.ClientConn) (*crdv1.NfsExportDriverInfo, error) {
+	rsp, err := csi.NewIdentityClient(conn).GetPluginInfo(ctx, &csi.GetPluginInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+	info := &crdv1.NfsExportDriverInfo{
+		VendorVersion: rsp.GetVendorVersion(),
+	}
+
+	pluginCapabilities, err := csirpc.GetPluginCapabilities(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for capability := range pluginCapabilities {
+		info.PluginCapabilities = append(info.PluginCapabilities, capability.String())
+	}
+	sort.Strings(info.PluginCapabilities)
+
+	if !pluginCapabilities[csi.PluginCapability_Service_CONTROLLER_SERVICE] {
+		return info, nil
+	}
+	controllerCapabilities, err := csirpc.GetControllerCapabilities(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	for capability := range controllerCapabilities {
+		info.ControllerCapabilities = append(info.ControllerCapabilities, capability.String())
+	}
+	sort.Strings(info.ControllerCapabilities)
+	return info, nil
+}
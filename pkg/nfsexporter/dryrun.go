@@ -0,0 +1,110 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfsexporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	klog "k8s.io/klog/v2"
+)
+
+// DryRunRecord is one CSI RPC that a dry-run NfsExportter would have issued.
+type DryRunRecord struct {
+	RPC          string            `json:"rpc"`
+	Driver       string            `json:"driver"`
+	Name         string            `json:"name,omitempty"`
+	VolumeHandle string            `json:"volumeHandle,omitempty"`
+	NfsExportID  string            `json:"nfsexportId,omitempty"`
+	Parameters   map[string]string `json:"parameters,omitempty"`
+	// SecretKeys holds the keys of the secrets that would have been sent.
+	// Secret values are never recorded.
+	SecretKeys []string `json:"secretKeys,omitempty"`
+}
+
+type dryRunNfsExportter struct {
+	driverName string
+
+	lock sync.Mutex
+	out  *json.Encoder
+}
+
+// NewDryRunNfsExportter returns a NfsExportter that issues no CSI calls.
+// Instead it writes each RPC it would have issued to out as a JSON encoded
+// DryRunRecord, one per line, and reports every operation as successful with
+// a ready nfsexport.
+func NewDryRunNfsExportter(driverName string, out io.Writer) NfsExportter {
+	return &dryRunNfsExportter{
+		driverName: driverName,
+		out:        json.NewEncoder(out),
+	}
+}
+
+func (s *dryRunNfsExportter) CreateNfsExport(ctx context.Context, nfsexportName string, volumeHandle string, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, error) {
+	nfsexportID := "dry-run-" + nfsexportName
+	s.record(DryRunRecord{
+		RPC:          "CreateNfsExport",
+		Name:         nfsexportName,
+		VolumeHandle: volumeHandle,
+		Parameters:   parameters,
+		SecretKeys:   secretKeys(nfsexporterCredentials),
+	})
+	return s.driverName, nfsexportID, time.Now(), 0, true, nil
+}
+
+func (s *dryRunNfsExportter) DeleteNfsExport(ctx context.Context, nfsexportID string, nfsexporterCredentials map[string]string) error {
+	s.record(DryRunRecord{
+		RPC:         "DeleteNfsExport",
+		NfsExportID: nfsexportID,
+		SecretKeys:  secretKeys(nfsexporterCredentials),
+	})
+	return nil
+}
+
+func (s *dryRunNfsExportter) GetNfsExportStatus(ctx context.Context, nfsexportID string, nfsexporterListCredentials map[string]string) (bool, time.Time, int64, error) {
+	s.record(DryRunRecord{
+		RPC:         "ListNfsExports",
+		NfsExportID: nfsexportID,
+		SecretKeys:  secretKeys(nfsexporterListCredentials),
+	})
+	return true, time.Now(), 0, nil
+}
+
+func (s *dryRunNfsExportter) record(r DryRunRecord) {
+	r.Driver = s.driverName
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.out.Encode(r); err != nil {
+		klog.Errorf("failed to record dry-run %s call: %v", r.RPC, err)
+	}
+}
+
+func secretKeys(secrets map[string]string) []string {
+	if len(secrets) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(secrets))
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
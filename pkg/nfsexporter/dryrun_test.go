@@ -0,0 +1,68 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfsexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDryRunNfsExportter(t *testing.T) {
+	var out bytes.Buffer
+	s := NewDryRunNfsExportter(driverName, &out)
+	params := map[string]string{"param1": "value1"}
+	secrets := map[string]string{"password": "secret", "user": "admin"}
+
+	driver, id, _, _, ready, err := s.CreateNfsExport(context.Background(), "nfsexport-1", "volume-1", params, secrets)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if driver != driverName || id != "dry-run-nfsexport-1" || !ready {
+		t.Errorf("unexpected CreateNfsExport result: driver %q, id %q, ready %v", driver, id, ready)
+	}
+	if _, _, _, err := s.GetNfsExportStatus(context.Background(), id, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := s.DeleteNfsExport(context.Background(), id, secrets); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []DryRunRecord{
+		{RPC: "CreateNfsExport", Driver: driverName, Name: "nfsexport-1", VolumeHandle: "volume-1", Parameters: params, SecretKeys: []string{"password", "user"}},
+		{RPC: "ListNfsExports", Driver: driverName, NfsExportID: "dry-run-nfsexport-1"},
+		{RPC: "DeleteNfsExport", Driver: driverName, NfsExportID: "dry-run-nfsexport-1", SecretKeys: []string{"password", "user"}},
+	}
+	if bytes.Contains(out.Bytes(), []byte("admin")) || bytes.Contains(out.Bytes(), []byte(`"secret"`)) {
+		t.Errorf("secret values must not be recorded, got %s", out.String())
+	}
+	decoder := json.NewDecoder(&out)
+	for _, want := range expected {
+		var got DryRunRecord
+		if err := decoder.Decode(&got); err != nil {
+			t.Fatalf("failed to decode record: %v", err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected record %+v, got %+v", want, got)
+		}
+	}
+	if decoder.More() {
+		t.Errorf("unexpected extra records")
+	}
+}
@@ -32,7 +32,11 @@ import (
 
 // NfsExportter implements CreateNfsExport/DeleteNfsExport operations against a remote CSI driver.
 type NfsExportter interface {
-	// CreateNfsExport creates a nfsexport for a volume
+	// CreateNfsExport creates a nfsexport for a volume. If the driver fails
+	// with ALREADY_EXISTS and reports the existing nfsexport, its ID is
+	// returned along with the error so that the caller can adopt it.
+	// Non-fatal warnings the driver sends in the WarningsMetadataKey
+	// trailer are recorded with AddWarnings.
 	CreateNfsExport(ctx context.Context, nfsexportName string, volumeHandle string, parameters map[string]string, nfsexporterCredentials map[string]string) (driverName string, nfsexportId string, timestamp time.Time, size int64, readyToUse bool, err error)
 
 	// DeleteNfsExport deletes a nfsexport from a volume
@@ -42,10 +46,67 @@ type NfsExportter interface {
 	GetNfsExportStatus(ctx context.Context, nfsexportID string, nfsexporterListCredentials map[string]string) (bool, time.Time, int64, error)
 }
 
+// BulkNfsExportDeleter is implemented by NfsExportters that can delete
+// several nfsexports in a single call.
+type BulkNfsExportDeleter interface {
+	// SupportsDeleteNfsExports returns true if the driver advertises the
+	// DeleteNfsExports RPC.
+	SupportsDeleteNfsExports(ctx context.Context) (bool, error)
+
+	// DeleteNfsExports deletes nfsexports sharing the same credentials. It
+	// returns the errors of the nfsexports that were not deleted, by ID, or
+	// an error if the call failed as a whole.
+	DeleteNfsExports(ctx context.Context, nfsexportIDs []string, nfsexporterCredentials map[string]string) (map[string]error, error)
+}
+
+// NfsExportAttributesGetter is implemented by NfsExportters whose driver
+// reports attributes of the nfsexports it created, e.g. an ID of the export
+// on the storage system or the export options actually applied.
+type NfsExportAttributesGetter interface {
+	// GetNfsExportAttributes returns the attributes of a nfsexport.
+	GetNfsExportAttributes(ctx context.Context, nfsexportID string, nfsexporterCredentials map[string]string) (map[string]string, error)
+}
+
+// ExportCacheTierSupporter is implemented by NfsExportters whose driver can
+// serve nfsexports through a cache tier, see
+// VolumeNfsExportSpec.CacheTier.
+type ExportCacheTierSupporter interface {
+	// SupportsExportCacheTiers returns true if the driver advertises the
+	// export cache tier capability.
+	SupportsExportCacheTiers(ctx context.Context) (bool, error)
+}
+
+// NfsExportUpdater is implemented by NfsExportters whose driver can change
+// the settings of an existing nfsexport, e.g. to re-encrypt it with a rotated
+// KMS key.
+type NfsExportUpdater interface {
+	// SupportsUpdateNfsExport returns true if the driver advertises the
+	// UpdateNfsExport RPC.
+	SupportsUpdateNfsExport(ctx context.Context) (bool, error)
+
+	// UpdateNfsExport applies parameters to an existing nfsexport.
+	UpdateNfsExport(ctx context.Context, nfsexportID string, parameters map[string]string, nfsexporterCredentials map[string]string) error
+}
+
+// MultiSourceNfsExportter is implemented by NfsExportters whose driver can
+// merge several volumes into a single nfsexport, e.g. with a union mount.
+type MultiSourceNfsExportter interface {
+	// SupportsMultiSourceNfsExports returns true if the driver advertises
+	// the creation of nfsexports from several volumes.
+	SupportsMultiSourceNfsExports(ctx context.Context) (bool, error)
+
+	// CreateMultiSourceNfsExport creates a nfsexport exposing each of the
+	// volumes in its own subdirectory. It returns the subdirectories by
+	// volume handle, along with the results of CreateNfsExport.
+	CreateMultiSourceNfsExport(ctx context.Context, nfsexportName string, volumeHandles []string, parameters map[string]string, nfsexporterCredentials map[string]string) (driverName string, nfsexportId string, timestamp time.Time, size int64, readyToUse bool, subdirectories map[string]string, err error)
+}
+
 type nfsexport struct {
 	conn *grpc.ClientConn
 }
 
+var _ NfsExportAttributesGetter = &nfsexport{}
+
 func NewNfsExportter(conn *grpc.ClientConn) NfsExportter {
 	return &nfsexport{
 		conn: conn,
@@ -54,7 +115,7 @@ func NewNfsExportter(conn *grpc.ClientConn) NfsExportter {
 
 func (s *nfsexport) CreateNfsExport(ctx context.Context, nfsexportName string, volumeHandle string, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, error) {
 	klog.V(5).Infof("CSI CreateNfsExport: %s", nfsexportName)
-	// client := csi.NewControllerClient(s.conn)
+	// client := csi.NewControllerClient(warningsConn{s.conn})
 
 	// driverName, err := csirpc.GetDriverName(ctx, s.conn)
 	// if err != nil {
@@ -97,6 +158,138 @@ func (s *nfsexport) DeleteNfsExport(ctx context.Context, nfsexportID string, nfs
 	return nil
 }
 
+func (s *nfsexport) SupportsDeleteNfsExports(ctx context.Context) (bool, error) {
+	// client := csi.NewControllerClient(s.conn)
+	// capRsp, err := client.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
+	// if err != nil {
+	// 	return false, err
+	// }
+
+	// for _, cap := range capRsp.Capabilities {
+	// 	if cap.GetRpc().GetType() == csi.ControllerServiceCapability_RPC_DELETE_NFSEXPORTS {
+	// 		return true, nil
+	// 	}
+	// }
+
+	return false, nil
+}
+
+func (s *nfsexport) DeleteNfsExports(ctx context.Context, nfsexportIDs []string, nfsexporterCredentials map[string]string) (map[string]error, error) {
+	klog.V(5).Infof("CSI DeleteNfsExports: %d nfsexports", len(nfsexportIDs))
+	// client := csi.NewControllerClient(s.conn)
+
+	// req := csi.DeleteNfsExportsRequest{
+	// 	NfsExportIds: nfsexportIDs,
+	// 	Secrets:      nfsexporterCredentials,
+	// }
+
+	// rsp, err := client.DeleteNfsExports(ctx, &req)
+	// if err != nil {
+	// 	return nil, err
+	// }
+
+	// failed := map[string]error{}
+	// for _, entry := range rsp.Failures {
+	// 	failed[entry.NfsExportId] = status.ErrorProto(entry.Status)
+	// }
+	// return failed, nil
+	return nil, nil
+}
+
+func (s *nfsexport) SupportsUpdateNfsExport(ctx context.Context) (bool, error) {
+	// client := csi.NewControllerClient(s.conn)
+	// capRsp, err := client.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
+	// if err != nil {
+	// 	return false, err
+	// }
+
+	// for _, cap := range capRsp.Capabilities {
+	// 	if cap.GetRpc().GetType() == csi.ControllerServiceCapability_RPC_UPDATE_NFSEXPORT {
+	// 		return true, nil
+	// 	}
+	// }
+
+	return false, nil
+}
+
+func (s *nfsexport) UpdateNfsExport(ctx context.Context, nfsexportID string, parameters map[string]string, nfsexporterCredentials map[string]string) error {
+	klog.V(5).Infof("CSI UpdateNfsExport: %s", nfsexportID)
+	// client := csi.NewControllerClient(s.conn)
+
+	// req := csi.UpdateNfsExportRequest{
+	// 	NfsExportId: nfsexportID,
+	// 	Parameters:  parameters,
+	// 	Secrets:     nfsexporterCredentials,
+	// }
+
+	// if _, err := client.UpdateNfsExport(ctx, &req); err != nil {
+	// 	return err
+	// }
+
+	return nil
+}
+
+func (s *nfsexport) SupportsMultiSourceNfsExports(ctx context.Context) (bool, error) {
+	// client := csi.NewControllerClient(s.conn)
+	// capRsp, err := client.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
+	// if err != nil {
+	// 	return false, err
+	// }
+
+	// for _, cap := range capRsp.Capabilities {
+	// 	if cap.GetRpc().GetType() == csi.ControllerServiceCapability_RPC_CREATE_MULTI_SOURCE_NFSEXPORT {
+	// 		return true, nil
+	// 	}
+	// }
+
+	return true, nil
+}
+
+func (s *nfsexport) CreateMultiSourceNfsExport(ctx context.Context, nfsexportName string, volumeHandles []string, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, map[string]string, error) {
+	klog.V(5).Infof("CSI CreateMultiSourceNfsExport: %s from %d volumes", nfsexportName, len(volumeHandles))
+	// client := csi.NewControllerClient(warningsConn{s.conn})
+
+	// driverName, err := csirpc.GetDriverName(ctx, s.conn)
+	// if err != nil {
+	// 	return "", "", time.Time{}, 0, false, nil, err
+	// }
+
+	// req := csi.CreateNfsExportRequest{
+	// 	SourceVolumeIds: volumeHandles,
+	// 	Name:            nfsexportName,
+	// 	Parameters:      parameters,
+	// 	Secrets:         nfsexporterCredentials,
+	// }
+
+	// rsp, err := client.CreateNfsExport(ctx, &req)
+	// if err != nil {
+	// 	return "", "", time.Time{}, 0, false, nil, err
+	// }
+
+	// creationTime, err := ptypes.Timestamp(rsp.NfsExport.CreationTime)
+	// if err != nil {
+	// 	return "", "", time.Time{}, 0, false, nil, err
+	// }
+	// return driverName, rsp.NfsExport.NfsExportId, creationTime, rsp.NfsExport.SizeBytes, rsp.NfsExport.ReadyToUse, rsp.NfsExport.SourceSubdirectories, nil
+	return "", "", time.Time{}, 0, true, nil, nil
+}
+
+func (s *nfsexport) SupportsExportCacheTiers(ctx context.Context) (bool, error) {
+	// client := csi.NewControllerClient(s.conn)
+	// capRsp, err := client.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
+	// if err != nil {
+	// 	return false, err
+	// }
+
+	// for _, cap := range capRsp.Capabilities {
+	// 	if cap.GetRpc().GetType() == csi.ControllerServiceCapability_RPC_EXPORT_CACHE_TIER {
+	// 		return true, nil
+	// 	}
+	// }
+
+	return false, nil
+}
+
 func (s *nfsexport) isListNfsExportsSupported(ctx context.Context) (bool, error) {
 	// client := csi.NewControllerClient(s.conn)
 	// capRsp, err := client.ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{})
@@ -146,3 +339,31 @@ func (s *nfsexport) GetNfsExportStatus(ctx context.Context, nfsexportID string,
 	// return rsp.Entries[0].NfsExport.ReadyToUse, creationTime, rsp.Entries[0].NfsExport.SizeBytes, nil
 	return true, time.Time{}, 0, nil
 }
+
+func (s *nfsexport) GetNfsExportAttributes(ctx context.Context, nfsexportID string, nfsexporterCredentials map[string]string) (map[string]string, error) {
+	klog.V(5).Infof("CSI GetNfsExportAttributes: %s", nfsexportID)
+	// client := csi.NewControllerClient(s.conn)
+
+	// // Drivers without ListNfsExports report no attributes.
+	// listNfsExportsSupported, err := s.isListNfsExportsSupported(ctx)
+	// if err != nil {
+	// 	return nil, fmt.Errorf("failed to check if ListNfsExports is supported: %s", err.Error())
+	// }
+	// if !listNfsExportsSupported {
+	// 	return nil, nil
+	// }
+	// req := csi.ListNfsExportsRequest{
+	// 	NfsExportId: nfsexportID,
+	// 	Secrets:     nfsexporterCredentials,
+	// }
+	// rsp, err := client.ListNfsExports(ctx, &req)
+	// if err != nil {
+	// 	return nil, err
+	// }
+
+	// if rsp.Entries == nil || len(rsp.Entries) == 0 {
+	// 	return nil, fmt.Errorf("can not find nfsexport for nfsexportID %s", nfsexportID)
+	// }
+	// return rsp.Entries[0].NfsExport.Attributes, nil
+	return nil, nil
+}
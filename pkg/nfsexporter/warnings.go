@@ -0,0 +1,83 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nfsexporter
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// WarningsMetadataKey is the key of the gRPC trailer metadata in which a
+// driver reports non-fatal warnings along with a successful response, one
+// warning per value.
+const WarningsMetadataKey = "csi-warnings"
+
+type warningsKey struct{}
+
+// Warnings collects the non-fatal warnings a driver reports along with the
+// result of a successful call, e.g. "export created but quota nearly
+// exhausted".
+type Warnings struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+// WithWarnings returns a context collecting the warnings reported by the
+// NfsExportter calls made with it.
+func WithWarnings(ctx context.Context) (context.Context, *Warnings) {
+	warnings := &Warnings{}
+	return context.WithValue(ctx, warningsKey{}, warnings), warnings
+}
+
+// AddWarnings records warnings reported by a driver. It is a no-op if ctx
+// does not collect warnings.
+func AddWarnings(ctx context.Context, messages ...string) {
+	warnings, ok := ctx.Value(warningsKey{}).(*Warnings)
+	if !ok || len(messages) == 0 {
+		return
+	}
+	warnings.lock.Lock()
+	defer warnings.lock.Unlock()
+	warnings.messages = append(warnings.messages, messages...)
+}
+
+// Messages returns the warnings collected so far.
+func (w *Warnings) Messages() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return append([]string(nil), w.messages...)
+}
+
+// warningsConn is a connection to a CSI driver recording the warnings the
+// driver sends in the WarningsMetadataKey trailer of its responses with
+// AddWarnings.
+type warningsConn struct {
+	*grpc.ClientConn
+}
+
+// Invoke calls method and records the warnings of its response.
+func (c warningsConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	var trailer metadata.MD
+	if err := c.ClientConn.Invoke(ctx, method, args, reply, append(opts, grpc.Trailer(&trailer))...); err != nil {
+		return err
+	}
+	AddWarnings(ctx, trailer.Get(WarningsMetadataKey)...)
+	return nil
+}
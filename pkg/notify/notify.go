@@ -0,0 +1,264 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package notify tells callers when a VolumeNfsExport becomes ready, fails
+// or is deleted, driven by an informer instead of polling the API server. It
+// is used by the nfsexport mount agent to follow the readiness of the
+// exports it mounts.
+// A Notifier is created from the VolumeNfsExport informer of a shared
+// informer factory, before the factory is started:
+//
+//	n := notify.NewNotifier(factory.NfsExport().V1().VolumeNfsExports())
+//	factory.Start(stopCh)
+//	n.WaitForCacheSync(stopCh)
+//	event, err := n.Wait(ctx, "default", "my-export")
+package notify
+
+import (
+	"context"
+	"sync"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	"k8s.io/client-go/tools/cache"
+	klog "k8s.io/klog/v2"
+)
+
+// EventType is the state a VolumeNfsExport reached.
+type EventType string
+
+const (
+	// EventReady is sent when the nfsexport is bound and ready to use.
+	EventReady EventType = "Ready"
+	// EventFailed is sent when the nfsexport reports an error and is not
+	// ready. The controllers keep retrying, so it may become ready later.
+	EventFailed EventType = "Failed"
+	// EventDeleted is sent when the nfsexport is deleted.
+	EventDeleted EventType = "Deleted"
+	// EventNotReady is sent when a nfsexport that was ready is no longer
+	// ready and reports no error.
+	EventNotReady EventType = "NotReady"
+)
+
+// Event is a state a VolumeNfsExport reached.
+type Event struct {
+	Type      EventType
+	NfsExport *crdv1.VolumeNfsExport
+}
+
+// Callback receives the events of a subscription or handler. Callbacks are
+// invoked from the informer and must not block for long.
+type Callback func(Event)
+
+// StateOf returns the event type matching the current state of the
+// nfsexport, or an empty string if it is still being created.
+func StateOf(nfsexport *crdv1.VolumeNfsExport) EventType {
+	if utils.IsBoundVolumeNfsExportContentNameSet(nfsexport) && utils.IsNfsExportReady(nfsexport) {
+		return EventReady
+	}
+	if nfsexport.Status != nil && nfsexport.Status.Error != nil {
+		return EventFailed
+	}
+	return ""
+}
+
+// transition returns the event to deliver when a nfsexport in the state
+// last, as returned by StateOf, reaches state, or an empty string if there
+// is none.
+func transition(last, state EventType) EventType {
+	switch {
+	case state == last:
+		return ""
+	case state == "" && last == EventReady:
+		return EventNotReady
+	}
+	return state
+}
+
+// Notifier delivers the events of the VolumeNfsExports of an informer to
+// subscribers.
+type Notifier struct {
+	lister storagelisters.VolumeNfsExportLister
+	synced cache.InformerSynced
+
+	lock sync.Mutex
+	// subscriptions holds the subscriptions by nfsexport key.
+	subscriptions map[string]map[*subscription]bool
+	// handlers receive the events of all nfsexports, see AddHandler.
+	handlers []Callback
+	// states holds the last state of each nfsexport seen by the handlers,
+	// by nfsexport key. It is only kept while there are handlers.
+	states map[string]EventType
+}
+
+type subscription struct {
+	callback Callback
+	// last is the type of the last event delivered, so that an event is
+	// delivered once per change of state.
+	last EventType
+}
+
+// NewNotifier returns a new *Notifier for the nfsexports of the informer.
+func NewNotifier(volumeNfsExportInformer storageinformers.VolumeNfsExportInformer) *Notifier {
+	n := &Notifier{
+		lister:        volumeNfsExportInformer.Lister(),
+		synced:        volumeNfsExportInformer.Informer().HasSynced,
+		subscriptions: make(map[string]map[*subscription]bool),
+		states:        make(map[string]EventType),
+	}
+	volumeNfsExportInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { n.nfsexportUpdated(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { n.nfsexportUpdated(newObj) },
+			DeleteFunc: func(obj interface{}) { n.nfsexportDeleted(obj) },
+		},
+	)
+	return n
+}
+
+// WaitForCacheSync blocks until the informer has synced or stopCh is closed.
+// It returns false if the informer did not sync.
+func (n *Notifier) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	return cache.WaitForCacheSync(stopCh, n.synced)
+}
+
+// Subscribe calls callback each time the given nfsexport becomes ready,
+// fails, is no longer ready or is deleted, until the returned function is called. If the
+// nfsexport is already ready or failed, callback is called before Subscribe
+// returns.
+func (n *Notifier) Subscribe(namespace, name string, callback Callback) (unsubscribe func()) {
+	key := namespace + "/" + name
+	sub := &subscription{callback: callback}
+
+	n.lock.Lock()
+	if n.subscriptions[key] == nil {
+		n.subscriptions[key] = make(map[*subscription]bool)
+	}
+	n.subscriptions[key][sub] = true
+	nfsexport, err := n.lister.VolumeNfsExports(namespace).Get(name)
+	if err == nil {
+		sub.last = StateOf(nfsexport)
+	}
+	n.lock.Unlock()
+
+	if sub.last != "" {
+		callback(Event{Type: sub.last, NfsExport: nfsexport})
+	}
+	return func() {
+		n.lock.Lock()
+		defer n.lock.Unlock()
+		delete(n.subscriptions[key], sub)
+		if len(n.subscriptions[key]) == 0 {
+			delete(n.subscriptions, key)
+		}
+	}
+}
+
+// AddHandler calls callback each time any nfsexport of the informer becomes
+// ready, fails, is no longer ready or is deleted. It must be called before
+// the informer is started, so that the nfsexports that are already ready
+// are delivered too.
+func (n *Notifier) AddHandler(callback Callback) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.handlers = append(n.handlers, callback)
+}
+
+// Watch returns a channel receiving the first event of the given nfsexport.
+// Like the cancel function of a context, the returned function must be
+// called once the caller no longer waits for the event, to end the
+// subscription.
+func (n *Notifier) Watch(namespace, name string) (<-chan Event, func()) {
+	ch := make(chan Event, 1)
+	var once sync.Once
+	unsubscribe := n.Subscribe(namespace, name, func(event Event) {
+		once.Do(func() { ch <- event })
+	})
+	return ch, unsubscribe
+}
+
+// Wait blocks until the given nfsexport becomes ready, fails or is deleted,
+// and returns the event. It returns an error if ctx is done first.
+func (n *Notifier) Wait(ctx context.Context, namespace, name string) (Event, error) {
+	ch, cancel := n.Watch(namespace, name)
+	defer cancel()
+	select {
+	case event := <-ch:
+		return event, nil
+	case <-ctx.Done():
+		return Event{}, ctx.Err()
+	}
+}
+
+func (n *Notifier) nfsexportUpdated(obj interface{}) {
+	nfsexport, ok := obj.(*crdv1.VolumeNfsExport)
+	if !ok {
+		return
+	}
+	n.deliver(nfsexport, StateOf(nfsexport))
+}
+
+func (n *Notifier) nfsexportDeleted(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	nfsexport, ok := obj.(*crdv1.VolumeNfsExport)
+	if !ok {
+		return
+	}
+	n.deliver(nfsexport, EventDeleted)
+}
+
+// deliver calls the handlers and the callbacks of the subscriptions to the
+// nfsexport with the event of its transition to state, see transition.
+func (n *Notifier) deliver(nfsexport *crdv1.VolumeNfsExport, state EventType) {
+	key := utils.NfsExportKey(nfsexport)
+	type delivery struct {
+		eventType EventType
+		callback  Callback
+	}
+	var deliveries []delivery
+
+	n.lock.Lock()
+	if len(n.handlers) > 0 {
+		if eventType := transition(n.states[key], state); eventType != "" {
+			for _, handler := range n.handlers {
+				deliveries = append(deliveries, delivery{eventType, handler})
+			}
+		}
+		if state == EventDeleted {
+			delete(n.states, key)
+		} else {
+			n.states[key] = state
+		}
+	}
+	for sub := range n.subscriptions[key] {
+		if eventType := transition(sub.last, state); eventType != "" {
+			deliveries = append(deliveries, delivery{eventType, sub.callback})
+		}
+		sub.last = state
+	}
+	n.lock.Unlock()
+
+	for _, d := range deliveries {
+		klog.V(4).Infof("notify: nfsexport %s: %s", key, d.eventType)
+		d.callback(Event{Type: d.eventType, NfsExport: nfsexport})
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newNfsExport(name string, ready bool, message string) *crdv1.VolumeNfsExport {
+	contentName := "content-" + name
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Status: &crdv1.VolumeNfsExportStatus{
+			BoundVolumeNfsExportContentName: &contentName,
+			ReadyToUse:                      &ready,
+		},
+	}
+	if message != "" {
+		nfsexport.Status.Error = &crdv1.VolumeNfsExportError{Message: &message}
+	}
+	return nfsexport
+}
+
+func newTestNotifier(existing ...*crdv1.VolumeNfsExport) *Notifier {
+	informer := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0).NfsExport().V1().VolumeNfsExports()
+	for _, nfsexport := range existing {
+		informer.Informer().GetIndexer().Add(nfsexport)
+	}
+	return NewNotifier(informer)
+}
+
+func TestSubscribe(t *testing.T) {
+	tests := []struct {
+		name           string
+		existing       []*crdv1.VolumeNfsExport
+		events         func(n *Notifier)
+		expectedEvents []string
+	}{
+		{
+			name: "ready is delivered once",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", false, ""))
+				n.nfsexportUpdated(newNfsExport("snap1", true, ""))
+				n.nfsexportUpdated(newNfsExport("snap1", true, ""))
+			},
+			expectedEvents: []string{"Ready snap1"},
+		},
+		{
+			name: "failed, then ready, then deleted",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", false, "mock error"))
+				n.nfsexportUpdated(newNfsExport("snap1", false, ""))
+				n.nfsexportUpdated(newNfsExport("snap1", true, ""))
+				n.nfsexportDeleted(cache.DeletedFinalStateUnknown{Key: "default/snap1", Obj: newNfsExport("snap1", true, "")})
+			},
+			expectedEvents: []string{"Failed snap1", "Ready snap1", "Deleted snap1"},
+		},
+		{
+			name: "ready, then not ready",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", true, ""))
+				n.nfsexportUpdated(newNfsExport("snap1", false, ""))
+				n.nfsexportUpdated(newNfsExport("snap1", false, ""))
+			},
+			expectedEvents: []string{"Ready snap1", "NotReady snap1"},
+		},
+		{
+			name:     "already ready nfsexport is delivered on subscription",
+			existing: []*crdv1.VolumeNfsExport{newNfsExport("snap1", true, "")},
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap1", true, ""))
+			},
+			expectedEvents: []string{"Ready snap1"},
+		},
+		{
+			name: "other nfsexports are not delivered",
+			events: func(n *Notifier) {
+				n.nfsexportUpdated(newNfsExport("snap2", true, ""))
+				n.nfsexportDeleted(newNfsExport("snap2", true, ""))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			n := newTestNotifier(test.existing...)
+			var events []string
+			unsubscribe := n.Subscribe("default", "snap1", func(event Event) {
+				events = append(events, string(event.Type)+" "+event.NfsExport.Name)
+			})
+			test.events(n)
+			if !reflect.DeepEqual(events, test.expectedEvents) {
+				t.Errorf("expected events %v, got %v", test.expectedEvents, events)
+			}
+
+			unsubscribe()
+			if len(n.subscriptions) != 0 {
+				t.Errorf("expected no subscriptions after unsubscribe, got %v", n.subscriptions)
+			}
+			count := len(events)
+			n.nfsexportDeleted(newNfsExport("snap1", false, ""))
+			if len(events) != count {
+				t.Errorf("expected no events after unsubscribe, got %v", events[count:])
+			}
+		})
+	}
+}
+
+func TestWait(t *testing.T) {
+	n := newTestNotifier()
+	go n.nfsexportUpdated(newNfsExport("snap1", true, ""))
+	event, err := n.Wait(context.Background(), "default", "snap1")
+	if err == nil && event.Type != EventReady {
+		t.Errorf("expected event %s, got %s", EventReady, event.Type)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := n.Wait(ctx, "default", "snap2"); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestAddHandler(t *testing.T) {
+	n := newTestNotifier()
+	var events []string
+	n.AddHandler(func(event Event) {
+		events = append(events, string(event.Type)+" "+event.NfsExport.Name)
+	})
+	n.nfsexportUpdated(newNfsExport("snap1", false, ""))
+	n.nfsexportUpdated(newNfsExport("snap1", true, ""))
+	n.nfsexportUpdated(newNfsExport("snap2", false, "mock error"))
+	n.nfsexportUpdated(newNfsExport("snap1", true, ""))
+	n.nfsexportUpdated(newNfsExport("snap1", false, ""))
+	n.nfsexportDeleted(newNfsExport("snap2", false, "mock error"))
+	n.nfsexportDeleted(cache.DeletedFinalStateUnknown{Key: "default/snap1", Obj: newNfsExport("snap1", false, "")})
+
+	expectedEvents := []string{"Ready snap1", "Failed snap2", "NotReady snap1", "Deleted snap2", "Deleted snap1"}
+	if !reflect.DeepEqual(events, expectedEvents) {
+		t.Errorf("expected events %v, got %v", expectedEvents, events)
+	}
+	if len(n.states) != 0 {
+		t.Errorf("expected no states after the nfsexports are deleted, got %v", n.states)
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package params computes the effective parameters of an export, i.e. the
+// parameters passed to the CSI driver, from the flags of the sidecar, the
+// parameters of the VolumeNfsExportClass and the settings of the export
+// itself. Every component that needs them, e.g. the sidecar to create an
+// export and the validation webhook to check a class, computes them here so
+// that they cannot drift apart.
+package params
+
+import (
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+)
+
+// Layers are the parameters of an export by the level that sets them. A key
+// set by several levels takes the value of the most specific one: Export
+// takes precedence over Class, which takes precedence over Flags.
+type Layers struct {
+	// Flags are set by flags of the sidecar, see FlagParameters.
+	Flags map[string]string
+	// Class are the parameters of the VolumeNfsExportClass, as set in the
+	// class: the csi.storage.k8s.io/ keys are stripped by Effective.
+	Class map[string]string
+	// Export are set for a single export, see ExportParameters.
+	Export map[string]string
+}
+
+// Effective returns the parameters passed to the CSI driver. It fails if
+// the class sets an unknown csi.storage.k8s.io/ key.
+func (l Layers) Effective() (map[string]string, error) {
+	class, err := ClassParameters(l.Class)
+	if err != nil {
+		return nil, err
+	}
+	parameters := make(map[string]string, len(l.Flags)+len(class)+len(l.Export))
+	for _, layer := range []map[string]string{l.Flags, class, l.Export} {
+		for key, value := range layer {
+			parameters[key] = value
+		}
+	}
+	return parameters, nil
+}
+
+// ClassParameters returns the parameters of a VolumeNfsExportClass passed
+// to the CSI driver, i.e. without the csi.storage.k8s.io/ keys which are
+// interpreted by the nfsexporter. It fails on an unknown
+// csi.storage.k8s.io/ key.
+func ClassParameters(classParameters map[string]string) (map[string]string, error) {
+	return utils.RemovePrefixedParameters(classParameters)
+}
+
+// FlagParameters returns the parameters of content set by flags of the
+// sidecar: the name of the VolumeNfsExport and of the content with
+// --extra-create-metadata.
+func FlagParameters(content *crdv1.VolumeNfsExportContent, extraCreateMetadata bool) map[string]string {
+	parameters := map[string]string{}
+	if extraCreateMetadata {
+		parameters[utils.PrefixedVolumeNfsExportNameKey] = content.Spec.VolumeNfsExportRef.Name
+		parameters[utils.PrefixedVolumeNfsExportNamespaceKey] = content.Spec.VolumeNfsExportRef.Namespace
+		parameters[utils.PrefixedVolumeNfsExportContentNameKey] = content.Name
+	}
+	return parameters
+}
+
+// ClassExportParameters returns the parameters requested by class but
+// passed to the CSI driver as reserved parameters of each of its exports:
+// warm-up and encryption. keySecret is the KMS key Secret of class, see
+// utils.GetKMSKeySecret, or nil if it is not known. It fails if the warm-up
+// parameter of class is invalid.
+func ClassExportParameters(class *crdv1.VolumeNfsExportClass, keySecret *v1.Secret) (map[string]string, error) {
+	warmUp, err := utils.IsExportWarmUpRequested(class.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	parameters := utils.GetExportEncryptionParameters(class, keySecret)
+	if warmUp {
+		parameters[utils.PrefixedExportWarmUpKey] = "true"
+	}
+	return parameters, nil
+}
+
+// ExportParameters returns the parameters set for content: its export path
+// hint, mode and cache tier, the security context, the metadata propagated
+// from its VolumeNfsExport and the ClassExportParameters of class. It fails
+// if the propagated metadata cannot be read or the warm-up parameter of
+// class is invalid.
+func ExportParameters(content *crdv1.VolumeNfsExportContent, class *crdv1.VolumeNfsExportClass, keySecret *v1.Secret) (map[string]string, error) {
+	propagated, err := utils.GetPropagatedMetadataFromContent(content.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	parameters, err := ClassExportParameters(class, keySecret)
+	if err != nil {
+		return nil, err
+	}
+	if content.Spec.ExportPathHint != nil {
+		parameters[utils.PrefixedExportPathHintKey] = *content.Spec.ExportPathHint
+	}
+	if content.Spec.Mode != nil {
+		parameters[utils.PrefixedExportModeKey] = string(*content.Spec.Mode)
+	}
+	if content.Spec.CacheTier != nil {
+		parameters[utils.PrefixedExportCacheTierKey] = string(*content.Spec.CacheTier)
+	}
+	for key, value := range utils.GetExportSecurityContextParameters(content.Annotations) {
+		parameters[key] = value
+	}
+	for key, value := range propagated {
+		parameters[key] = value
+	}
+	return parameters, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package params
+
+import (
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEffective(t *testing.T) {
+	tests := []struct {
+		name     string
+		layers   Layers
+		expected map[string]string
+		err      bool
+	}{
+		{
+			name:     "no parameters",
+			expected: map[string]string{},
+		},
+		{
+			name: "export takes precedence over class, which takes precedence over flags",
+			layers: Layers{
+				Flags:  map[string]string{"a": "flags", "b": "flags", "c": "flags"},
+				Class:  map[string]string{"b": "class", "c": "class"},
+				Export: map[string]string{"c": "export"},
+			},
+			expected: map[string]string{"a": "flags", "b": "class", "c": "export"},
+		},
+		{
+			name: "prefixed keys of the class are stripped",
+			layers: Layers{
+				Class: map[string]string{
+					"a":                                     "class",
+					utils.PrefixedNfsExportterSecretNameKey: "secret",
+					utils.PrefixedExportWarmUpKey:           "true",
+				},
+				Export: map[string]string{utils.PrefixedExportWarmUpKey: "true"},
+			},
+			expected: map[string]string{"a": "class", utils.PrefixedExportWarmUpKey: "true"},
+		},
+		{
+			name: "unknown prefixed key of the class",
+			layers: Layers{
+				Class: map[string]string{"csi.storage.k8s.io/unknown": "value"},
+			},
+			err: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parameters, err := test.layers.Effective()
+			if test.err {
+				if err == nil {
+					t.Errorf("expected error, got parameters %v", parameters)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(parameters, test.expected) {
+				t.Errorf("expected parameters %v, got %v", test.expected, parameters)
+			}
+		})
+	}
+}
+
+func TestFlagAndExportParameters(t *testing.T) {
+	hint := "team-a/data"
+	mode := crdv1.VolumeNfsExportModeLive
+	content := &crdv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "content"},
+		Spec: crdv1.VolumeNfsExportContentSpec{
+			VolumeNfsExportRef: v1.ObjectReference{Name: "nfsexport", Namespace: "default"},
+			ExportPathHint:     &hint,
+			Mode:               &mode,
+		},
+	}
+
+	if parameters := FlagParameters(content, false); len(parameters) != 0 {
+		t.Errorf("expected no flag parameters without extra create metadata, got %v", parameters)
+	}
+	expected := map[string]string{
+		utils.PrefixedVolumeNfsExportNameKey:        "nfsexport",
+		utils.PrefixedVolumeNfsExportNamespaceKey:   "default",
+		utils.PrefixedVolumeNfsExportContentNameKey: "content",
+	}
+	if parameters := FlagParameters(content, true); !reflect.DeepEqual(parameters, expected) {
+		t.Errorf("expected flag parameters %v, got %v", expected, parameters)
+	}
+
+	class := &crdv1.VolumeNfsExportClass{ObjectMeta: metav1.ObjectMeta{Name: "class"}}
+	expected = map[string]string{
+		utils.PrefixedExportPathHintKey: hint,
+		utils.PrefixedExportModeKey:     string(mode),
+	}
+	parameters, err := ExportParameters(content, class, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(parameters, expected) {
+		t.Errorf("expected export parameters %v, got %v", expected, parameters)
+	}
+
+	transport := crdv1.NfsExportTransportKrb5p
+	class.Parameters = map[string]string{utils.PrefixedExportWarmUpKey: "true"}
+	class.Encryption = &crdv1.NfsExportEncryption{Transport: &transport}
+	expected[utils.PrefixedExportWarmUpKey] = "true"
+	expected[utils.PrefixedExportTransportKey] = string(transport)
+	parameters, err = ExportParameters(content, class, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(parameters, expected) {
+		t.Errorf("expected export parameters with the warm-up and encryption of the class %v, got %v", expected, parameters)
+	}
+
+	class.Parameters[utils.PrefixedExportWarmUpKey] = "maybe"
+	if _, err := ExportParameters(content, class, nil); err == nil {
+		t.Errorf("expected error for invalid warm-up parameter")
+	}
+
+	class.Parameters = nil
+	content.Annotations = map[string]string{utils.AnnExportPropagatedMetadata: "not json"}
+	if _, err := ExportParameters(content, class, nil); err == nil {
+		t.Errorf("expected error for invalid propagated metadata")
+	}
+}
@@ -0,0 +1,383 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replication
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	klog "k8s.io/klog/v2"
+)
+
+// Design:
+//
+// The replicator mirrors ready VolumeNfsExportContents of the local cluster
+// into a peer cluster as pre-provisioned contents, so that a DR cluster can
+// bind and mount the same backend exports. Mirrors keep the local content
+// name, always use the Retain deletion policy so that the peer cluster can
+// never delete the backend export, and are labeled with the ID of the source
+// cluster so that the replicator only ever touches objects it created.
+//
+// A peer content with the same name that was not created by this replicator
+// is a conflict: it is reported and left alone. A mirror whose spec no longer
+// matches the local content has drifted: mutable fields are corrected, while
+// drift in immutable or binding fields is reported only. Mirrors whose local
+// content is gone are deleted by a periodic drift sweep.
+
+const (
+	// LabelReplicatedFromCluster is set on mirrored contents in the peer
+	// cluster and holds the ID of the cluster they were replicated from.
+	LabelReplicatedFromCluster = "nfsexport.storage.kubernetes.io/replicated-from-cluster"
+
+	// AnnReplicatedFromUID is set on mirrored contents in the peer cluster and
+	// holds the UID of the local content they were replicated from.
+	AnnReplicatedFromUID = "nfsexport.storage.kubernetes.io/replicated-from-uid"
+
+	// Event reasons recorded on the local content.
+	reasonReplicated          = "ReplicationSucceeded"
+	reasonReplicationFailed   = "ReplicationFailed"
+	reasonReplicationConflict = "ReplicationConflict"
+	reasonDriftCorrected      = "ReplicationDriftCorrected"
+	reasonDriftDetected       = "ReplicationDriftDetected"
+)
+
+// Replicator mirrors ready VolumeNfsExportContents into a peer cluster.
+type Replicator struct {
+	clusterID     string
+	peerClient    clientset.Interface
+	eventRecorder record.EventRecorder
+	queue         workqueue.RateLimitingInterface
+
+	contentLister       storagelisters.VolumeNfsExportContentLister
+	contentListerSynced cache.InformerSynced
+
+	// sweepPeriod is the interval of sweepOrphanedMirrors, 0 disables it.
+	sweepPeriod time.Duration
+}
+
+// NewReplicator returns a new *Replicator that mirrors the contents of the
+// local informer into the cluster reached by peerClient. clusterID must be
+// unique among the clusters replicating into the same peer. Mirrors orphaned
+// in the peer cluster are looked for every sweepPeriod, 0 disables the sweep.
+func NewReplicator(
+	clusterID string,
+	peerClient clientset.Interface,
+	eventRecorder record.EventRecorder,
+	volumeNfsExportContentInformer storageinformers.VolumeNfsExportContentInformer,
+	resyncPeriod time.Duration,
+	sweepPeriod time.Duration,
+	rateLimiter workqueue.RateLimiter,
+) *Replicator {
+	r := &Replicator{
+		clusterID:     clusterID,
+		peerClient:    peerClient,
+		eventRecorder: eventRecorder,
+		queue:         workqueue.NewNamedRateLimitingQueue(rateLimiter, "nfsexport-replicator-content"),
+		sweepPeriod:   sweepPeriod,
+	}
+
+	volumeNfsExportContentInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { r.enqueueContentWork(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { r.enqueueContentWork(newObj) },
+			DeleteFunc: func(obj interface{}) { r.enqueueContentWork(obj) },
+		},
+		resyncPeriod,
+	)
+	r.contentLister = volumeNfsExportContentInformer.Lister()
+	r.contentListerSynced = volumeNfsExportContentInformer.Informer().HasSynced
+
+	return r
+}
+
+// Run starts the replication workers and the drift sweep and blocks until
+// stopCh is closed.
+func (r *Replicator) Run(workers int, stopCh <-chan struct{}) {
+	defer r.queue.ShutDown()
+
+	klog.Infof("Starting nfsexport replicator for cluster %q", r.clusterID)
+	defer klog.Infof("Shutting nfsexport replicator")
+
+	if !cache.WaitForCacheSync(stopCh, r.contentListerSynced) {
+		klog.Errorf("Cannot sync caches")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(r.contentWorker, 0, stopCh)
+	}
+	if r.sweepPeriod > 0 {
+		go wait.Until(r.sweepOrphanedMirrors, r.sweepPeriod, stopCh)
+	}
+
+	<-stopCh
+}
+
+// enqueueContentWork adds the name of a nfsexport content to the work queue.
+func (r *Replicator) enqueueContentWork(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	if content, ok := obj.(*crdv1.VolumeNfsExportContent); ok {
+		objName, err := cache.DeletionHandlingMetaNamespaceKeyFunc(content)
+		if err != nil {
+			klog.Errorf("failed to get key from object: %v, %v", err, content)
+			return
+		}
+		klog.V(5).Infof("enqueued %q for replication", objName)
+		r.queue.Add(objName)
+	}
+}
+
+// contentWorker is the main worker for replicating VolumeNfsExportContents.
+func (r *Replicator) contentWorker() {
+	keyObj, quit := r.queue.Get()
+	if quit {
+		return
+	}
+	defer r.queue.Done(keyObj)
+
+	if err := r.syncContentByKey(keyObj.(string)); err != nil {
+		r.queue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to replicate content %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		r.queue.Forget(keyObj)
+	}
+}
+
+// syncContentByKey brings the peer mirror of a content in line with the local
+// content: it creates or corrects the mirror of a ready content and deletes
+// the mirror of a content that is gone or being deleted.
+func (r *Replicator) syncContentByKey(key string) error {
+	klog.V(5).Infof("syncContentByKey[%s]", key)
+
+	_, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		klog.Errorf("error getting name of content %q from informer: %v", key, err)
+		return nil
+	}
+	content, err := r.contentLister.Get(name)
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return err
+		}
+		return r.deleteMirror(name)
+	}
+	if content.ObjectMeta.DeletionTimestamp != nil {
+		return r.deleteMirror(name)
+	}
+	if !isReplicable(content) {
+		klog.V(5).Infof("content %s is not ready to be replicated", name)
+		return nil
+	}
+	return r.ensureMirror(content)
+}
+
+// isReplicable returns true if the content is ready and its backend export
+// handle is known. Mirrors are never replicated again, so that two clusters
+// replicating into each other do not bounce contents back and forth.
+func isReplicable(content *crdv1.VolumeNfsExportContent) bool {
+	if _, ok := content.Labels[LabelReplicatedFromCluster]; ok {
+		return false
+	}
+	return content.Status != nil &&
+		content.Status.ReadyToUse != nil && *content.Status.ReadyToUse &&
+		content.Status.NfsExportHandle != nil && *content.Status.NfsExportHandle != ""
+}
+
+// ensureMirror creates the mirror of a ready content in the peer cluster, or
+// checks an existing mirror for conflicts and drift.
+func (r *Replicator) ensureMirror(content *crdv1.VolumeNfsExportContent) error {
+	expected := r.mirrorFor(content)
+
+	mirror, err := r.peerClient.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		if _, err = r.peerClient.NfsExportV1().VolumeNfsExportContents().Create(context.TODO(), expected, metav1.CreateOptions{}); err != nil {
+			r.eventRecorder.Event(content, v1.EventTypeWarning, reasonReplicationFailed, fmt.Sprintf("Failed to create mirror in peer cluster: %v", err))
+			return err
+		}
+		klog.V(4).Infof("ensureMirror: created mirror of content %s", content.Name)
+		r.eventRecorder.Event(content, v1.EventTypeNormal, reasonReplicated, "Mirrored content into peer cluster")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get mirror of content %s from peer cluster: %v", content.Name, err)
+	}
+
+	if !r.ownsMirror(mirror) {
+		// Never take over an object that someone else created in the peer
+		// cluster; retrying will not resolve this, so don't requeue.
+		msg := fmt.Sprintf("Peer cluster already has a VolumeNfsExportContent %s that was not replicated from cluster %q", content.Name, r.clusterID)
+		klog.Warningf("ensureMirror: %s", msg)
+		r.eventRecorder.Event(content, v1.EventTypeWarning, reasonReplicationConflict, msg)
+		return nil
+	}
+	if mirror.Annotations[AnnReplicatedFromUID] != string(content.UID) {
+		// The local content was deleted and re-created with the same name
+		// while the old mirror survived. Replace the stale mirror.
+		klog.V(4).Infof("ensureMirror: mirror of content %s belongs to a previous incarnation, deleting it", content.Name)
+		if err := r.deleteMirror(content.Name); err != nil {
+			return err
+		}
+		// Requeue so that the new mirror is created once the stale one is gone.
+		return fmt.Errorf("waiting for stale mirror of content %s to be deleted from peer cluster", content.Name)
+	}
+
+	correctable, reportOnly := detectDrift(expected, mirror)
+	if len(reportOnly) > 0 {
+		msg := fmt.Sprintf("Mirror in peer cluster has drifted in fields that cannot be corrected: %s", strings.Join(reportOnly, ", "))
+		klog.Warningf("ensureMirror: content %s: %s", content.Name, msg)
+		r.eventRecorder.Event(content, v1.EventTypeWarning, reasonDriftDetected, msg)
+	}
+	if len(correctable) == 0 {
+		return nil
+	}
+
+	mirrorClone := mirror.DeepCopy()
+	mirrorClone.Spec.Driver = expected.Spec.Driver
+	mirrorClone.Spec.DeletionPolicy = expected.Spec.DeletionPolicy
+	mirrorClone.Spec.VolumeNfsExportClassName = expected.Spec.VolumeNfsExportClassName
+	if _, err = r.peerClient.NfsExportV1().VolumeNfsExportContents().Update(context.TODO(), mirrorClone, metav1.UpdateOptions{}); err != nil {
+		// A conflict means the mirror changed under us; the retry will
+		// re-read it.
+		return fmt.Errorf("failed to correct drift of mirror %s: %v", content.Name, err)
+	}
+	r.eventRecorder.Event(content, v1.EventTypeNormal, reasonDriftCorrected, fmt.Sprintf("Corrected drifted fields of mirror in peer cluster: %s", strings.Join(correctable, ", ")))
+	return nil
+}
+
+// deleteMirror deletes the mirror with the given name from the peer cluster,
+// provided it was created by this replicator.
+func (r *Replicator) deleteMirror(name string) error {
+	mirror, err := r.peerClient.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get mirror %s from peer cluster: %v", name, err)
+	}
+	if !r.ownsMirror(mirror) {
+		klog.V(5).Infof("deleteMirror: content %s in peer cluster was not replicated from cluster %q, skipping", name, r.clusterID)
+		return nil
+	}
+	// Guard with the UID so that a mirror re-created in the meantime is not
+	// deleted.
+	err = r.peerClient.NfsExportV1().VolumeNfsExportContents().Delete(context.TODO(), name, metav1.DeleteOptions{
+		Preconditions: metav1.NewUIDPreconditions(string(mirror.UID)),
+	})
+	if err != nil && !apierrs.IsNotFound(err) {
+		return fmt.Errorf("failed to delete mirror %s from peer cluster: %v", name, err)
+	}
+	klog.V(4).Infof("deleteMirror: deleted mirror %s from peer cluster", name)
+	return nil
+}
+
+// sweepOrphanedMirrors enqueues every mirror in the peer cluster that was
+// replicated from this cluster, so that mirrors whose local content was
+// deleted while the replicator was not running are cleaned up, and drift
+// introduced in the peer cluster is detected.
+func (r *Replicator) sweepOrphanedMirrors() {
+	selector := labels.SelectorFromSet(labels.Set{LabelReplicatedFromCluster: r.clusterID})
+	mirrors, err := r.peerClient.NfsExportV1().VolumeNfsExportContents().List(context.TODO(), metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		klog.Errorf("sweepOrphanedMirrors: failed to list mirrors in peer cluster: %v", err)
+		return
+	}
+	for i := range mirrors.Items {
+		r.queue.Add(mirrors.Items[i].Name)
+	}
+}
+
+// ownsMirror returns true if the peer content was replicated from this cluster.
+func (r *Replicator) ownsMirror(mirror *crdv1.VolumeNfsExportContent) bool {
+	return mirror.Labels[LabelReplicatedFromCluster] == r.clusterID
+}
+
+// mirrorFor returns the pre-provisioned content that mirrors content in the
+// peer cluster.
+func (r *Replicator) mirrorFor(content *crdv1.VolumeNfsExportContent) *crdv1.VolumeNfsExportContent {
+	handle := *content.Status.NfsExportHandle
+	ref := content.Spec.VolumeNfsExportRef
+	mirror := &crdv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: content.Name,
+			Labels: map[string]string{
+				LabelReplicatedFromCluster: r.clusterID,
+			},
+			Annotations: map[string]string{
+				AnnReplicatedFromUID: string(content.UID),
+			},
+		},
+		Spec: crdv1.VolumeNfsExportContentSpec{
+			VolumeNfsExportRef: v1.ObjectReference{
+				Kind:       ref.Kind,
+				APIVersion: ref.APIVersion,
+				Namespace:  ref.Namespace,
+				Name:       ref.Name,
+			},
+			// The peer cluster must never delete the backend export.
+			DeletionPolicy:           crdv1.VolumeNfsExportContentRetain,
+			Driver:                   content.Spec.Driver,
+			VolumeNfsExportClassName: content.Spec.VolumeNfsExportClassName,
+			Source: crdv1.VolumeNfsExportContentSource{
+				NfsExportHandle: &handle,
+			},
+			SourceVolumeMode: content.Spec.SourceVolumeMode,
+		},
+	}
+	return mirror
+}
+
+// detectDrift compares a mirror with its expected spec. It returns the names
+// of drifted fields the replicator can correct, and of drifted fields that
+// are immutable or affect binding in the peer cluster and are only reported.
+func detectDrift(expected, mirror *crdv1.VolumeNfsExportContent) (correctable, reportOnly []string) {
+	if mirror.Spec.Driver != expected.Spec.Driver {
+		correctable = append(correctable, "spec.driver")
+	}
+	if mirror.Spec.DeletionPolicy != expected.Spec.DeletionPolicy {
+		correctable = append(correctable, "spec.deletionPolicy")
+	}
+	if !reflect.DeepEqual(mirror.Spec.VolumeNfsExportClassName, expected.Spec.VolumeNfsExportClassName) {
+		correctable = append(correctable, "spec.volumeNfsExportClassName")
+	}
+	if !reflect.DeepEqual(mirror.Spec.Source, expected.Spec.Source) {
+		reportOnly = append(reportOnly, "spec.source")
+	}
+	if mirror.Spec.VolumeNfsExportRef.Namespace != expected.Spec.VolumeNfsExportRef.Namespace ||
+		mirror.Spec.VolumeNfsExportRef.Name != expected.Spec.VolumeNfsExportRef.Name {
+		reportOnly = append(reportOnly, "spec.volumeNfsExportRef")
+	}
+	return correctable, reportOnly
+}
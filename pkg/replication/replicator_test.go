@@ -0,0 +1,226 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package replication
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const testClusterID = "cluster-a"
+
+var (
+	True  = true
+	False = false
+)
+
+func newLocalContent(name, handle string, ready *bool) *crdv1.VolumeNfsExportContent {
+	class := "class"
+	volumeHandle := "volume-handle"
+	content := &crdv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name,
+			UID:  types.UID("uid-" + name),
+		},
+		Spec: crdv1.VolumeNfsExportContentSpec{
+			VolumeNfsExportRef: v1.ObjectReference{
+				Kind:      "VolumeNfsExport",
+				Namespace: "default",
+				Name:      "snap-" + name,
+				UID:       types.UID("snapuid-" + name),
+			},
+			DeletionPolicy:           crdv1.VolumeNfsExportContentDelete,
+			Driver:                   "driver",
+			VolumeNfsExportClassName: &class,
+			Source: crdv1.VolumeNfsExportContentSource{
+				VolumeHandle: &volumeHandle,
+			},
+		},
+	}
+	if ready != nil {
+		content.Status = &crdv1.VolumeNfsExportContentStatus{
+			ReadyToUse:      ready,
+			NfsExportHandle: &handle,
+		}
+	}
+	return content
+}
+
+func newMirror(name, handle, clusterID string) *crdv1.VolumeNfsExportContent {
+	r := &Replicator{clusterID: clusterID}
+	mirror := r.mirrorFor(newLocalContent(name, handle, &True))
+	mirror.UID = types.UID("mirror-uid-" + name)
+	return mirror
+}
+
+func newTestReplicator(t *testing.T, local []*crdv1.VolumeNfsExportContent, peer []runtime.Object) (*Replicator, *fake.Clientset, *record.FakeRecorder) {
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	contentInformer := factory.NfsExport().V1().VolumeNfsExportContents()
+	peerClient := fake.NewSimpleClientset(peer...)
+	recorder := record.NewFakeRecorder(10)
+	r := NewReplicator(testClusterID, peerClient, recorder, contentInformer, 0, 0, workqueue.DefaultControllerRateLimiter())
+	for _, content := range local {
+		if err := contentInformer.Informer().GetIndexer().Add(content); err != nil {
+			t.Fatalf("failed to add content %s to informer: %v", content.Name, err)
+		}
+	}
+	return r, peerClient, recorder
+}
+
+func getMirror(t *testing.T, peerClient *fake.Clientset, name string) *crdv1.VolumeNfsExportContent {
+	mirror, err := peerClient.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("failed to get mirror %s: %v", name, err)
+	}
+	return mirror
+}
+
+func TestSyncContentByKey(t *testing.T) {
+	driftedPolicy := newMirror("content1", "handle1", testClusterID)
+	driftedPolicy.Spec.DeletionPolicy = crdv1.VolumeNfsExportContentDelete
+	driftedHandle := newMirror("content1", "other-handle", testClusterID)
+	staleMirror := newMirror("content1", "handle1", testClusterID)
+	staleMirror.Annotations[AnnReplicatedFromUID] = "old-uid"
+	localMirror := newLocalContent("content1", "handle1", &True)
+	localMirror.Labels = map[string]string{LabelReplicatedFromCluster: "cluster-b"}
+
+	tests := []struct {
+		name           string
+		local          []*crdv1.VolumeNfsExportContent
+		peer           []runtime.Object
+		expectedMirror *crdv1.VolumeNfsExportContent
+		expectedEvents []string
+		expectErr      bool
+	}{
+		{
+			name:           "ready content is mirrored",
+			local:          []*crdv1.VolumeNfsExportContent{newLocalContent("content1", "handle1", &True)},
+			expectedMirror: newMirror("content1", "handle1", testClusterID),
+			expectedEvents: []string{"Normal " + reasonReplicated},
+		},
+		{
+			name:  "content that is not ready is not mirrored",
+			local: []*crdv1.VolumeNfsExportContent{newLocalContent("content1", "handle1", &False)},
+		},
+		{
+			name:  "content without status is not mirrored",
+			local: []*crdv1.VolumeNfsExportContent{newLocalContent("content1", "", nil)},
+		},
+		{
+			name:  "mirror from another cluster is not replicated again",
+			local: []*crdv1.VolumeNfsExportContent{localMirror},
+		},
+		{
+			name:           "existing mirror in sync is left alone",
+			local:          []*crdv1.VolumeNfsExportContent{newLocalContent("content1", "handle1", &True)},
+			peer:           []runtime.Object{newMirror("content1", "handle1", testClusterID)},
+			expectedMirror: newMirror("content1", "handle1", testClusterID),
+		},
+		{
+			name:           "content created by someone else in the peer is a conflict",
+			local:          []*crdv1.VolumeNfsExportContent{newLocalContent("content1", "handle1", &True)},
+			peer:           []runtime.Object{newMirror("content1", "handle1", "cluster-b")},
+			expectedMirror: newMirror("content1", "handle1", "cluster-b"),
+			expectedEvents: []string{"Warning " + reasonReplicationConflict},
+		},
+		{
+			name:           "drift in a mutable field is corrected",
+			local:          []*crdv1.VolumeNfsExportContent{newLocalContent("content1", "handle1", &True)},
+			peer:           []runtime.Object{driftedPolicy},
+			expectedMirror: newMirror("content1", "handle1", testClusterID),
+			expectedEvents: []string{"Normal " + reasonDriftCorrected},
+		},
+		{
+			name:           "drift in an immutable field is reported only",
+			local:          []*crdv1.VolumeNfsExportContent{newLocalContent("content1", "handle1", &True)},
+			peer:           []runtime.Object{driftedHandle},
+			expectedMirror: driftedHandle,
+			expectedEvents: []string{"Warning " + reasonDriftDetected},
+		},
+		{
+			name:      "mirror of a re-created content is replaced",
+			local:     []*crdv1.VolumeNfsExportContent{newLocalContent("content1", "handle1", &True)},
+			peer:      []runtime.Object{staleMirror},
+			expectErr: true,
+		},
+		{
+			name: "mirror of a deleted content is deleted",
+			peer: []runtime.Object{newMirror("content1", "handle1", testClusterID)},
+		},
+		{
+			name:           "content of another cluster is not deleted",
+			peer:           []runtime.Object{newMirror("content1", "handle1", "cluster-b")},
+			expectedMirror: newMirror("content1", "handle1", "cluster-b"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, peerClient, recorder := newTestReplicator(t, test.local, test.peer)
+
+			err := r.syncContentByKey("content1")
+			if test.expectErr && err == nil {
+				t.Errorf("expected error, got none")
+			}
+			if !test.expectErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			mirror := getMirror(t, peerClient, "content1")
+			if test.expectedMirror == nil {
+				if mirror != nil {
+					t.Errorf("expected no mirror, got %+v", mirror)
+				}
+			} else if mirror == nil {
+				t.Errorf("expected mirror %+v, got none", test.expectedMirror)
+			} else if !reflect.DeepEqual(mirror.Spec, test.expectedMirror.Spec) || !reflect.DeepEqual(mirror.Labels, test.expectedMirror.Labels) {
+				t.Errorf("expected mirror %+v, got %+v", test.expectedMirror, mirror)
+			}
+
+			close(recorder.Events)
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+			if len(events) != len(test.expectedEvents) {
+				t.Fatalf("expected events %v, got %v", test.expectedEvents, events)
+			}
+			for i := range events {
+				if !strings.HasPrefix(events[i], test.expectedEvents[i]) {
+					t.Errorf("expected event %q, got %q", test.expectedEvents[i], events[i])
+				}
+			}
+		})
+	}
+}
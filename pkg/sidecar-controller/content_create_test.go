@@ -18,14 +18,21 @@ package sidecar_controller
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/fakeapiserver"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const creationTimeoutMessage = "The export was not ready to use within the creation timeout 1h0m0s of VolumeNfsExportClass timeout-class"
+
 func TestSyncContent(t *testing.T) {
 	tests := []controllerTest{
 		{
@@ -94,6 +101,7 @@ func TestSyncContent(t *testing.T) {
 					RestoreSize:    nil,
 					ReadyToUse:     &False,
 					Error:          newNfsExportError("Failed to check and update nfsexport content: failed to get input parameters to create nfsexport for content content1-3: \"cannot retrieve secrets for nfsexport content \\\"content1-3\\\", err: secret name or namespace not specified\""),
+					ErrorHistory:   []crdv1.VolumeNfsExportError{*newNfsExportError("Failed to check and update nfsexport content: failed to get input parameters to create nfsexport for content content1-3: \"cannot retrieve secrets for nfsexport content \\\"content1-3\\\", err: secret name or namespace not specified\"")},
 				}), map[string]string{
 				utils.AnnDeletionSecretRefName:      "",
 				utils.AnnDeletionSecretRefNamespace: "",
@@ -158,15 +166,16 @@ func TestSyncContent(t *testing.T) {
 					RestoreSize:    nil,
 					ReadyToUse:     &False,
 					Error:          newNfsExportError(`Failed to check and update nfsexport content: failed to get input parameters to create nfsexport for content content1-5: "cannot get credentials for nfsexport content \"content1-5\""`),
+					ErrorHistory:   []crdv1.VolumeNfsExportError{*newNfsExportError(`Failed to check and update nfsexport content: failed to get input parameters to create nfsexport for content content1-5: "cannot get credentials for nfsexport content \"content1-5\""`)},
 				}), map[string]string{
 				utils.AnnDeletionSecretRefName:      "secret",
 				utils.AnnDeletionSecretRefNamespace: "default",
 			}), initialSecrets: []*v1.Secret{}, // no initial secret created
 			expectedEvents: []string{"Warning NfsExportContentCheckandUpdateFailed"},
-			errors: []reactorError{
+			errors: []fakeapiserver.Hook{
 				// Inject error to the first client.VolumenfsexportV1().VolumeNfsExports().Update call.
 				// All other calls will succeed.
-				{"get", "secrets", errors.New("mock secrets error")},
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourceSecrets, errors.New("mock secrets error")),
 			},
 			test: testSyncContent,
 		},
@@ -179,6 +188,7 @@ func TestSyncContent(t *testing.T) {
 					RestoreSize:    &defaultSize,
 					ReadyToUse:     &False,
 					Error:          newNfsExportError("Failed to check and update nfsexport content: failed to get input parameters to create nfsexport for content content1-6: \"volumenfsexportclass.nfsexport.storage.k8s.io \\\"bad-class\\\" not found\""),
+					ErrorHistory:   []crdv1.VolumeNfsExportError{*newNfsExportError("Failed to check and update nfsexport content: failed to get input parameters to create nfsexport for content content1-6: \"volumenfsexportclass.nfsexport.storage.k8s.io \\\"bad-class\\\" not found\"")},
 				}),
 			expectedEvents: []string{"Warning NfsExportContentCheckandUpdateFailed"},
 			expectedCreateCalls: []createCall{
@@ -195,6 +205,469 @@ func TestSyncContent(t *testing.T) {
 			errors:            noerrors,
 			test:              testSyncContent,
 		},
+		{
+			name: "1-7: content error history drops the oldest entry when full",
+			initialContents: withContentStatus(newContentArray("content1-7", "snapuid1-7", "snap1-7", "sid1-7", "bad-class", "", "volume-handle-1-7", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{
+					NfsExportHandle: toStringPointer("sid1-7"),
+					RestoreSize:     &defaultSize,
+					ReadyToUse:      &False,
+					Error:           newNfsExportError("error 5"),
+					ErrorHistory:    newNfsExportErrorHistory("error 1", "error 2", "error 3", "error 4", "error 5"),
+				}),
+			expectedContents: withContentStatus(newContentArray("content1-7", "snapuid1-7", "snap1-7", "sid1-7", "bad-class", "", "volume-handle-1-7", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{
+					NfsExportHandle: toStringPointer("sid1-7"),
+					RestoreSize:     &defaultSize,
+					ReadyToUse:      &False,
+					Error:           newNfsExportError("Failed to check and update nfsexport content: failed to get input parameters to create nfsexport for content content1-7: \"volumenfsexportclass.nfsexport.storage.k8s.io \\\"bad-class\\\" not found\""),
+					ErrorHistory: newNfsExportErrorHistory("error 2", "error 3", "error 4", "error 5",
+						"Failed to check and update nfsexport content: failed to get input parameters to create nfsexport for content content1-7: \"volumenfsexportclass.nfsexport.storage.k8s.io \\\"bad-class\\\" not found\""),
+				}),
+			expectedEvents: []string{"Warning NfsExportContentCheckandUpdateFailed"},
+			errors:         noerrors,
+			test:           testSyncContent,
+		},
+		{
+			name: "1-8: existing nfsexport returned with ALREADY_EXISTS is adopted",
+			initialContents: withContentStatus(newContentArray("content1-8", "snapuid1-8", "snap1-8", "sid1-8", defaultClass, "", "volume-handle-1-8", retainPolicy, nil, &defaultSize, true),
+				nil),
+			expectedContents: withContentAnnotations(withContentStatus(newContentArray("content1-8", "snapuid1-8", "snap1-8", "sid1-8", defaultClass, "", "volume-handle-1-8", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("existing1-8"), RestoreSize: &defaultSize, ReadyToUse: &True}),
+				map[string]string{}),
+			expectedEvents: []string{"Normal NfsExportAdopted"},
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-8",
+					nfsexportName: "nfsexport-snapuid1-8",
+					nfsexportId:   "existing1-8",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:        "snap1-8",
+						utils.PrefixedVolumeNfsExportNamespaceKey:   "default",
+						utils.PrefixedVolumeNfsExportContentNameKey: "content1-8",
+					},
+					err: status.Error(codes.AlreadyExists, "nfsexport already exists"),
+				},
+			},
+			expectedListCalls: []listCall{{"existing1-8", map[string]string{"foo": "bar"}, true, timeNow, defaultSize, nil}},
+			initialSecrets:    []*v1.Secret{secret()},
+			errors:            noerrors,
+			test:              testSyncContent,
+		},
+		{
+			name: "1-9: sync content create nfsexport records the zone of the class",
+			initialContents: withContentStatus(newContentArray("content1-9", "snapuid1-9", "snap1-9", "sid1-9", zoneClass, "", "volume-handle-1-9", retainPolicy, nil, &defaultSize, true),
+				nil),
+			expectedContents: withContentAnnotations(withContentStatus(newContentArray("content1-9", "snapuid1-9", "snap1-9", "sid1-9", zoneClass, "", "volume-handle-1-9", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("snapuid1-9"), RestoreSize: &defaultSize, ReadyToUse: &True, Zone: toStringPointer("zone-a")}),
+				map[string]string{}),
+			expectedEvents: noevents,
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-9",
+					nfsexportName: "nfsexport-snapuid1-9",
+					driverName:    mockDriverName,
+					nfsexportId:   "snapuid1-9",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:        "snap1-9",
+						utils.PrefixedVolumeNfsExportNamespaceKey:   "default",
+						utils.PrefixedVolumeNfsExportContentNameKey: "content1-9",
+					},
+					creationTime: timeNow,
+					readyToUse:   true,
+					size:         defaultSize,
+				},
+			},
+			expectedListCalls: []listCall{{"sid1-9", map[string]string{}, true, time.Now(), 1, nil}},
+			errors:            noerrors,
+			test:              testSyncContent,
+		},
+		{
+			name: "1-10: sync content create nfsexport requests a warm-up and reports it while not ready",
+			initialContents: withContentStatus(newContentArray("content1-10", "snapuid1-10", "snap1-10", "sid1-10", warmUpClass, "", "volume-handle-1-10", retainPolicy, nil, &defaultSize, true),
+				nil),
+			expectedContents: withContentAnnotations(withContentStatus(newContentArray("content1-10", "snapuid1-10", "snap1-10", "sid1-10", warmUpClass, "", "volume-handle-1-10", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("snapuid1-10"), RestoreSize: &defaultSize, ReadyToUse: &False,
+					Conditions: []metav1.Condition{{Type: crdv1.ConditionWarming, Status: metav1.ConditionTrue, Reason: crdv1.WarmingReasonInProgress, Message: "The CSI driver is warming the export up"}}}),
+				map[string]string{}),
+			expectedEvents: noevents,
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-10",
+					nfsexportName: "nfsexport-snapuid1-10",
+					driverName:    mockDriverName,
+					nfsexportId:   "snapuid1-10",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:        "snap1-10",
+						utils.PrefixedVolumeNfsExportNamespaceKey:   "default",
+						utils.PrefixedVolumeNfsExportContentNameKey: "content1-10",
+						utils.PrefixedExportWarmUpKey:               "true",
+					},
+					creationTime: timeNow,
+					readyToUse:   false,
+					size:         defaultSize,
+				},
+			},
+			errors: noerrors,
+			test:   testSyncContent,
+		},
+		{
+			// The content has no creation timestamp, so it is older than the timeout of its class.
+			name: "1-11: sync content not ready after the creation timeout of its class marks it failed without calling the driver",
+			initialContents: withContentStatus(newContentArray("content1-11", "snapuid1-11", "snap1-11", "sid1-11", timeoutClass, "", "volume-handle-1-11", retainPolicy, nil, &defaultSize, true),
+				nil),
+			expectedContents: withContentStatus(newContentArray("content1-11", "snapuid1-11", "snap1-11", "sid1-11", timeoutClass, "", "volume-handle-1-11", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{ReadyToUse: &False,
+					Error:        newNfsExportError(creationTimeoutMessage),
+					ErrorHistory: newNfsExportErrorHistory(creationTimeoutMessage),
+					Conditions:   []metav1.Condition{{Type: crdv1.ConditionFailed, Status: metav1.ConditionTrue, Reason: crdv1.FailedReasonCreationTimedOut, Message: creationTimeoutMessage}}}),
+			expectedEvents: []string{"Warning NfsExportCreationTimedOut"},
+			errors:         noerrors,
+			test:           testSyncContent,
+		},
+		{
+			name: "1-12: sync failed content does not call the driver",
+			initialContents: withContentStatus(newContentArray("content1-12", "snapuid1-12", "snap1-12", "sid1-12", timeoutClass, "", "volume-handle-1-12", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{ReadyToUse: &False,
+					Conditions: []metav1.Condition{{Type: crdv1.ConditionFailed, Status: metav1.ConditionTrue, Reason: crdv1.FailedReasonCreationTimedOut, Message: creationTimeoutMessage}}}),
+			expectedContents: withContentStatus(newContentArray("content1-12", "snapuid1-12", "snap1-12", "sid1-12", timeoutClass, "", "volume-handle-1-12", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{ReadyToUse: &False,
+					Conditions: []metav1.Condition{{Type: crdv1.ConditionFailed, Status: metav1.ConditionTrue, Reason: crdv1.FailedReasonCreationTimedOut, Message: creationTimeoutMessage}}}),
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test:           testSyncContent,
+		},
+		{
+			// The common controller annotates the content after the sidecar
+			// read it. The error status computed by the sidecar from its
+			// stale copy is rejected with a conflict, and must not reset the
+			// annotation.
+			name: "1-13: content error status from a stale copy does not reset metadata written by the common controller",
+			initialContents: withContentAnnotations(withContentStatus(newContentArray("content1-13", "snapuid1-13", "snap1-13", "sid1-13", invalidSecretClass, "", "volume-handle-1-13", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{}), map[string]string{
+				utils.AnnDeletionSecretRefName:      "",
+				utils.AnnDeletionSecretRefNamespace: "",
+			}),
+			expectedContents: withContentAnnotations(withContentStatus(newContentArray("content1-13", "snapuid1-13", "snap1-13", "sid1-13", invalidSecretClass, "", "volume-handle-1-13", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{}), map[string]string{
+				utils.AnnDeletionSecretRefName:       "",
+				utils.AnnDeletionSecretRefNamespace:  "",
+				utils.AnnVolumeNfsExportBeingDeleted: "yes",
+			}),
+			expectedEvents: []string{"Warning NfsExportContentCheckandUpdateFailed"},
+			errors:         noerrors,
+			test: wrapTestWithInjectedOperation(testSyncContent, func(ctrl *csiNfsExportSideCarController, reactor *nfsexportReactor) {
+				reactor.lock.Lock()
+				defer reactor.lock.Unlock()
+				content := reactor.contents["content1-13"].DeepCopy()
+				content.Annotations[utils.AnnVolumeNfsExportBeingDeleted] = "yes"
+				content.ResourceVersion = "2"
+				reactor.contents[content.Name] = content
+			}),
+		},
+		{
+			name:             "1-14: sync ready content records the generation of its spec without calling the driver",
+			initialContents:  withContentGeneration(newContentArrayWithReadyToUse("content1-14", "snapuid1-14", "snap1-14", "sid1-14", defaultClass, "", "volume-handle-1-14", retainPolicy, nil, &defaultSize, &True, true), 2, 1),
+			expectedContents: withContentGeneration(newContentArrayWithReadyToUse("content1-14", "snapuid1-14", "snap1-14", "sid1-14", defaultClass, "", "volume-handle-1-14", retainPolicy, nil, &defaultSize, &True, true), 2, 2),
+			expectedEvents:   noevents,
+			errors:           noerrors,
+			test: func(ctrl *csiNfsExportSideCarController, reactor *nfsexportReactor, test controllerTest) error {
+				if err := testSyncContent(ctrl, reactor, test); err != nil {
+					return err
+				}
+				reactor.lock.Lock()
+				defer reactor.lock.Unlock()
+				if reactor.contents["content1-14"].Status.LastSyncTime == nil {
+					return errors.New("expected lastSyncTime to be set")
+				}
+				return nil
+			},
+		},
+		{
+			name: "1-15: sync content create nfsexport passes the propagated metadata and records the attributes reported by the driver",
+			initialContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-15", "snapuid1-15", "snap1-15", "sid1-15", defaultClass, "", "volume-handle-1-15", retainPolicy, nil, &defaultSize, &False, true),
+				map[string]string{
+					utils.AnnExportPropagatedMetadata: `{"csi.storage.k8s.io/volumenfsexport/annotation/example.com/ticket":"OPS-42"}`,
+				}),
+			expectedContents: withContentAttributes(withContentAnnotations(newContentArrayWithReadyToUse("content1-15", "snapuid1-15", "snap1-15", "sid1-15", defaultClass, "", "volume-handle-1-15", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{
+					utils.AnnExportPropagatedMetadata: `{"csi.storage.k8s.io/volumenfsexport/annotation/example.com/ticket":"OPS-42"}`,
+				}), map[string]string{"backendID": "fs-1-15"}),
+			expectedEvents: noevents,
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-15",
+					nfsexportName: "nfsexport-snapuid1-15",
+					driverName:    mockDriverName,
+					nfsexportId:   "snapuid1-15",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:                               "snap1-15",
+						utils.PrefixedVolumeNfsExportNamespaceKey:                          "default",
+						utils.PrefixedVolumeNfsExportContentNameKey:                        "content1-15",
+						"csi.storage.k8s.io/volumenfsexport/annotation/example.com/ticket": "OPS-42",
+					},
+					creationTime: timeNow,
+					readyToUse:   true,
+					attributes:   map[string]string{"backendID": "fs-1-15"},
+				},
+			},
+			errors: noerrors,
+			test:   testSyncContent,
+		},
+		{
+			// The controller clock is at timeNow, one minute before the creation timeout of the class elapses.
+			name: "1-16: sync content not ready just before the creation timeout of its class creates the nfsexport",
+			initialContents: withContentCreationTimestamp(withContentStatus(newContentArray("content1-16", "snapuid1-16", "snap1-16", "sid1-16", timeoutClass, "", "volume-handle-1-16", retainPolicy, nil, &defaultSize, true),
+				nil), timeNow.Add(-time.Hour+time.Minute)),
+			expectedContents: withContentAnnotations(withContentCreationTimestamp(withContentStatus(newContentArray("content1-16", "snapuid1-16", "snap1-16", "sid1-16", timeoutClass, "", "volume-handle-1-16", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("snapuid1-16"), RestoreSize: &defaultSize, ReadyToUse: &True}), timeNow.Add(-time.Hour+time.Minute)),
+				map[string]string{}),
+			expectedEvents: noevents,
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-16",
+					nfsexportName: "nfsexport-snapuid1-16",
+					driverName:    mockDriverName,
+					nfsexportId:   "snapuid1-16",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:        "snap1-16",
+						utils.PrefixedVolumeNfsExportNamespaceKey:   "default",
+						utils.PrefixedVolumeNfsExportContentNameKey: "content1-16",
+					},
+					creationTime: timeNow,
+					readyToUse:   true,
+					size:         defaultSize,
+				},
+			},
+			errors: noerrors,
+			test:   testSyncContent,
+		},
+		{
+			name: "1-17: sync content create nfsexport passes the encryption of its class and records the KMS key version",
+			initialContents: withContentStatus(newContentArray("content1-17", "snapuid1-17", "snap1-17", "sid1-17", encryptedClass, "", "volume-handle-1-17", retainPolicy, nil, &defaultSize, true),
+				nil),
+			expectedContents: withContentAnnotations(withContentStatus(newContentArray("content1-17", "snapuid1-17", "snap1-17", "sid1-17", encryptedClass, "", "volume-handle-1-17", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("snapuid1-17"), RestoreSize: &defaultSize, ReadyToUse: &True}),
+				map[string]string{utils.AnnExportKMSKeyVersion: "1"}),
+			initialSecrets: []*v1.Secret{kmsKeySecret("1")},
+			expectedEvents: noevents,
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-17",
+					nfsexportName: "nfsexport-snapuid1-17",
+					driverName:    mockDriverName,
+					nfsexportId:   "snapuid1-17",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:         "snap1-17",
+						utils.PrefixedVolumeNfsExportNamespaceKey:    "default",
+						utils.PrefixedVolumeNfsExportContentNameKey:  "content1-17",
+						utils.PrefixedExportTransportKey:             "stunnel",
+						utils.PrefixedExportKMSKeySecretNameKey:      "kms-key",
+						utils.PrefixedExportKMSKeySecretNamespaceKey: "default",
+						utils.PrefixedExportKMSKeyVersionKey:         "1",
+					},
+					secrets:      map[string]string{"kms-key/keyID": "key-1", "kms-key/version": "1"},
+					creationTime: timeNow,
+					readyToUse:   true,
+					size:         defaultSize,
+				},
+			},
+			errors: noerrors,
+			test:   testSyncContent,
+		},
+		{
+			name: "1-18: sync ready content updates the nfsexport when the KMS key of its class was rotated",
+			initialContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-18", "snapuid1-18", "snap1-18", "sid1-18", encryptedClass, "", "volume-handle-1-18", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{utils.AnnExportKMSKeyVersion: "1"}),
+			expectedContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-18", "snapuid1-18", "snap1-18", "sid1-18", encryptedClass, "", "volume-handle-1-18", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{utils.AnnExportKMSKeyVersion: "2"}),
+			initialSecrets: []*v1.Secret{kmsKeySecret("2")},
+			expectedEvents: []string{"Normal KMSKeyRotated"},
+			expectedUpdateCalls: []updateCall{
+				{
+					nfsexportID: "sid1-18",
+					parameters: map[string]string{
+						utils.PrefixedExportTransportKey:             "stunnel",
+						utils.PrefixedExportKMSKeySecretNameKey:      "kms-key",
+						utils.PrefixedExportKMSKeySecretNamespaceKey: "default",
+						utils.PrefixedExportKMSKeyVersionKey:         "2",
+					},
+					secrets: map[string]string{"kms-key/keyID": "key-2", "kms-key/version": "2"},
+				},
+			},
+			errors: noerrors,
+			test:   testSyncContent,
+		},
+		{
+			name: "1-19: sync ready content does not update the nfsexport when the KMS key of its class is unchanged",
+			initialContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-19", "snapuid1-19", "snap1-19", "sid1-19", encryptedClass, "", "volume-handle-1-19", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{utils.AnnExportKMSKeyVersion: "2"}),
+			expectedContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-19", "snapuid1-19", "snap1-19", "sid1-19", encryptedClass, "", "volume-handle-1-19", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{utils.AnnExportKMSKeyVersion: "2"}),
+			initialSecrets: []*v1.Secret{kmsKeySecret("2")},
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test:           testSyncContent,
+		},
+		{
+			name: "1-20: sync ready content fails when the KMS key update of the nfsexport fails",
+			initialContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-20", "snapuid1-20", "snap1-20", "sid1-20", encryptedClass, "", "volume-handle-1-20", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{utils.AnnExportKMSKeyVersion: "1"}),
+			expectedContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-20", "snapuid1-20", "snap1-20", "sid1-20", encryptedClass, "", "volume-handle-1-20", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{utils.AnnExportKMSKeyVersion: "1"}),
+			initialSecrets: []*v1.Secret{kmsKeySecret("2")},
+			expectedEvents: []string{"Warning KMSKeyRotationFailed"},
+			expectedUpdateCalls: []updateCall{
+				{
+					nfsexportID: "sid1-20",
+					parameters: map[string]string{
+						utils.PrefixedExportTransportKey:             "stunnel",
+						utils.PrefixedExportKMSKeySecretNameKey:      "kms-key",
+						utils.PrefixedExportKMSKeySecretNamespaceKey: "default",
+						utils.PrefixedExportKMSKeyVersionKey:         "2",
+					},
+					secrets: map[string]string{"kms-key/keyID": "key-2", "kms-key/version": "2"},
+					err:     errors.New("mock update error"),
+				},
+			},
+			errors: noerrors,
+			test:   testSyncContentError,
+		},
+		{
+			name: "1-21: sync content create nfsexport emits the warnings of the driver and proceeds",
+			initialContents: withContentStatus(newContentArray("content1-21", "snapuid1-21", "snap1-21", "sid1-21", defaultClass, "", "volume-handle-1-21", retainPolicy, nil, &defaultSize, true),
+				nil),
+			expectedContents: withContentAnnotations(withContentStatus(newContentArray("content1-21", "snapuid1-21", "snap1-21", "sid1-21", defaultClass, "", "volume-handle-1-21", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("snapuid1-21"), RestoreSize: &defaultSize, ReadyToUse: &True}),
+				map[string]string{}),
+			expectedEvents: []string{"Warning NfsExportCreationWarning", "Warning NfsExportCreationWarning"},
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-21",
+					nfsexportName: "nfsexport-snapuid1-21",
+					driverName:    mockDriverName,
+					nfsexportId:   "snapuid1-21",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:        "snap1-21",
+						utils.PrefixedVolumeNfsExportNamespaceKey:   "default",
+						utils.PrefixedVolumeNfsExportContentNameKey: "content1-21",
+					},
+					creationTime: timeNow,
+					readyToUse:   true,
+					size:         defaultSize,
+					warnings:     []string{"export created but quota nearly exhausted"},
+				},
+			},
+			expectedListCalls: []listCall{{"sid1-21", map[string]string{}, true, time.Now(), 1, nil}},
+			errors:            noerrors,
+			test:              testSyncContent,
+		},
+		{
+			name: "1-22: sync pre-provisioned content without class marks it ready without calling the driver when static contents are ready",
+			initialContents: withContentCreationTimestamp(withContentStatus(newContentArray("content1-22", "snapuid1-22", "snap1-22", "", "", "sid1-22", "", retainPolicy, nil, nil, true),
+				nil), timeNow.Add(-time.Hour)),
+			expectedContents: withContentCreationTimestamp(withContentStatus(newContentArray("content1-22", "snapuid1-22", "snap1-22", "", "", "sid1-22", "", retainPolicy, nil, nil, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("sid1-22"), RestoreSize: &emptySize, ReadyToUse: &True}), timeNow.Add(-time.Hour)),
+			expectedEvents: noevents,
+			errors:         noerrors,
+			test: func(ctrl *csiNfsExportSideCarController, reactor *nfsexportReactor, test controllerTest) error {
+				ctrl.SetStaticContentsReady(true)
+				if err := testSyncContent(ctrl, reactor, test); err != nil {
+					return err
+				}
+				reactor.lock.Lock()
+				defer reactor.lock.Unlock()
+				content := reactor.contents["content1-22"]
+				if creationTime := content.Status.CreationTime; creationTime == nil || *creationTime != content.CreationTimestamp.UnixNano() {
+					return fmt.Errorf("expected creationTime to be the creation timestamp %d of the content, got %v", content.CreationTimestamp.UnixNano(), creationTime)
+				}
+				return nil
+			},
+		},
+		{
+			name: "1-23: sync content create nfsexport passes the cache tier and reports that the driver did not achieve it",
+			initialContents: withContentCacheTier(withContentStatus(newContentArray("content1-23", "snapuid1-23", "snap1-23", "sid1-23", cacheTierClass, "", "volume-handle-1-23", retainPolicy, nil, &defaultSize, true),
+				nil), crdv1.VolumeNfsExportCacheTierNodeLocal),
+			expectedContents: withContentAttributes(withContentAnnotations(withContentCacheTier(withContentStatus(newContentArray("content1-23", "snapuid1-23", "snap1-23", "sid1-23", cacheTierClass, "", "volume-handle-1-23", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("snapuid1-23"), RestoreSize: &defaultSize, ReadyToUse: &True}), crdv1.VolumeNfsExportCacheTierNodeLocal),
+				map[string]string{}), map[string]string{utils.PrefixedExportCacheTierKey: "None"}),
+			expectedEvents: []string{"Warning ExportCacheTierNotAchieved"},
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-23",
+					nfsexportName: "nfsexport-snapuid1-23",
+					driverName:    mockDriverName,
+					nfsexportId:   "snapuid1-23",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:        "snap1-23",
+						utils.PrefixedVolumeNfsExportNamespaceKey:   "default",
+						utils.PrefixedVolumeNfsExportContentNameKey: "content1-23",
+						utils.PrefixedExportCacheTierKey:            "NodeLocal",
+					},
+					creationTime: timeNow,
+					readyToUse:   true,
+					size:         defaultSize,
+					attributes:   map[string]string{utils.PrefixedExportCacheTierKey: "None"},
+				},
+			},
+			errors: noerrors,
+			test:   testSyncContent,
+		},
+		{
+			name: "1-24: sync ready content keeps the KMS key version when the driver cannot update the nfsexport",
+			initialContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-24", "snapuid1-24", "snap1-24", "sid1-24", encryptedClass, "", "volume-handle-1-24", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{utils.AnnExportKMSKeyVersion: "1"}),
+			expectedContents: withContentAnnotations(newContentArrayWithReadyToUse("content1-24", "snapuid1-24", "snap1-24", "sid1-24", encryptedClass, "", "volume-handle-1-24", retainPolicy, nil, &defaultSize, &True, true),
+				map[string]string{utils.AnnExportKMSKeyVersion: "1"}),
+			initialSecrets:      []*v1.Secret{kmsKeySecret("2")},
+			expectedEvents:      []string{"Warning KMSKeyRotationNotSupported"},
+			expectedUpdateCalls: []updateCall{{unsupported: true}},
+			errors:              noerrors,
+			test:                testSyncContent,
+		},
+		{
+			name: "1-25: sync content create nfsexport fails when the driver does not support cache tiers",
+			initialContents: withContentCacheTier(withContentStatus(newContentArray("content1-25", "snapuid1-25", "snap1-25", "sid1-25", cacheTierClass, "", "volume-handle-1-25", retainPolicy, nil, &defaultSize, true),
+				nil), crdv1.VolumeNfsExportCacheTierNodeLocal),
+			expectedContents: withContentCacheTier(withContentStatus(newContentArray("content1-25", "snapuid1-25", "snap1-25", "sid1-25", cacheTierClass, "", "volume-handle-1-25", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{
+					ReadyToUse:   &False,
+					Error:        newNfsExportError("Failed to create nfsexport: failed to validate cache tier of content content1-25: the CSI driver does not support export cache tiers"),
+					ErrorHistory: []crdv1.VolumeNfsExportError{*newNfsExportError("Failed to create nfsexport: failed to validate cache tier of content content1-25: the CSI driver does not support export cache tiers")},
+				}), crdv1.VolumeNfsExportCacheTierNodeLocal),
+			cacheTiersUnsupported: true,
+			expectedEvents:        []string{"Warning NfsExportCreationFailed"},
+			errors:                noerrors,
+			test:                  testSyncContent,
+		},
+		{
+			name: "1-26: sync content create nfsexport reports that the driver did not report the achieved cache tier",
+			initialContents: withContentCacheTier(withContentStatus(newContentArray("content1-26", "snapuid1-26", "snap1-26", "sid1-26", cacheTierClass, "", "volume-handle-1-26", retainPolicy, nil, &defaultSize, true),
+				nil), crdv1.VolumeNfsExportCacheTierNodeLocal),
+			expectedContents: withContentAnnotations(withContentCacheTier(withContentStatus(newContentArray("content1-26", "snapuid1-26", "snap1-26", "sid1-26", cacheTierClass, "", "volume-handle-1-26", retainPolicy, nil, &defaultSize, true),
+				&crdv1.VolumeNfsExportContentStatus{NfsExportHandle: toStringPointer("snapuid1-26"), RestoreSize: &defaultSize, ReadyToUse: &True}), crdv1.VolumeNfsExportCacheTierNodeLocal),
+				map[string]string{}),
+			expectedEvents: []string{"Warning ExportCacheTierNotAchieved"},
+			expectedCreateCalls: []createCall{
+				{
+					volumeHandle:  "volume-handle-1-26",
+					nfsexportName: "nfsexport-snapuid1-26",
+					driverName:    mockDriverName,
+					nfsexportId:   "snapuid1-26",
+					parameters: map[string]string{
+						utils.PrefixedVolumeNfsExportNameKey:        "snap1-26",
+						utils.PrefixedVolumeNfsExportNamespaceKey:   "default",
+						utils.PrefixedVolumeNfsExportContentNameKey: "content1-26",
+						utils.PrefixedExportCacheTierKey:            "NodeLocal",
+					},
+					creationTime: timeNow,
+					readyToUse:   true,
+					size:         defaultSize,
+				},
+			},
+			errors: noerrors,
+			test:   testSyncContent,
+		},
 	}
 
 	runSyncContentTests(t, tests, nfsexportClasses)
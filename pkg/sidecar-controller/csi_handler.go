@@ -18,27 +18,68 @@ package sidecar_controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/audit"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/nfsexporter"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Handler is responsible for handling VolumeNfsExport events from informer.
 type Handler interface {
-	CreateNfsExport(content *crdv1.VolumeNfsExportContent, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, error)
+	// CreateNfsExport creates the nfsexport of a content. Along with the
+	// results of the driver, it returns the non-fatal warnings the driver
+	// reported, e.g. "export created but quota nearly exhausted".
+	CreateNfsExport(content *crdv1.VolumeNfsExportContent, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, []string, error)
+	// CreateMultiSourceNfsExport creates the nfsexport of a content merging
+	// several volumes, see VolumeNfsExportContentSource.VolumeHandles, and
+	// returns the subdirectory of each volume. It fails with
+	// errMultiSourceNotSupported if the driver cannot merge volumes. Like
+	// CreateNfsExport, it returns the warnings of the driver.
+	CreateMultiSourceNfsExport(content *crdv1.VolumeNfsExportContent, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, []crdv1.NfsExportSourceVolume, []string, error)
 	DeleteNfsExport(content *crdv1.VolumeNfsExportContent, nfsexporterCredentials map[string]string) error
+	// DeleteNfsExports deletes the nfsexports of contents sharing the same
+	// credentials, in a single call if the driver supports it. It returns the
+	// errors of the contents whose nfsexport was not deleted, by content name.
+	DeleteNfsExports(contents []*crdv1.VolumeNfsExportContent, nfsexporterCredentials map[string]string) map[string]error
 	GetNfsExportStatus(content *crdv1.VolumeNfsExportContent, nfsexporterListCredentials map[string]string) (bool, time.Time, int64, error)
+	// GetNfsExportAttributes returns the attributes the driver reports for
+	// a nfsexport, or nil if the driver does not report any.
+	GetNfsExportAttributes(nfsexportHandle string, nfsexporterCredentials map[string]string) (map[string]string, error)
+	// UpdateNfsExport applies parameters to the nfsexport of a content. It
+	// returns errUpdateNotSupported if the driver cannot update nfsexports.
+	UpdateNfsExport(content *crdv1.VolumeNfsExportContent, parameters map[string]string, nfsexporterCredentials map[string]string) error
+	// SupportsExportCacheTiers returns true if the driver can serve
+	// nfsexports through a cache tier.
+	SupportsExportCacheTiers() (bool, error)
 }
 
+// errUpdateNotSupported is returned by UpdateNfsExport when the driver does
+// not support the UpdateNfsExport RPC.
+var errUpdateNotSupported = errors.New("the CSI driver does not support UpdateNfsExport")
+
+// errMultiSourceNotSupported is returned by CreateMultiSourceNfsExport when
+// the driver cannot create nfsexports from several volumes. It is a final
+// error: the driver is not called.
+var errMultiSourceNotSupported = status.Error(codes.Unimplemented, "the CSI driver does not support nfsexports from several volumes")
+
 // csiHandler is a handler that calls CSI to create/delete volume nfsexport.
 type csiHandler struct {
-	nfsexporter            nfsexporter.NfsExportter
-	timeout                time.Duration
+	nfsexporter nfsexporter.NfsExportter
+	// timeout is the time.Duration of the CSI calls. It is accessed
+	// atomically, as it can be changed while the workers run.
+	timeout                 int64
 	nfsexportNamePrefix     string
 	nfsexportNameUUIDLength int
+	// auditSink receives the operations issued to the driver, if set.
+	auditSink audit.Sink
 }
 
 // NewCSIHandler returns a handler which includes the csi connection and NfsExport name details
@@ -50,46 +91,124 @@ func NewCSIHandler(
 ) Handler {
 	return &csiHandler{
 		nfsexporter:            nfsexporter,
-		timeout:                timeout,
+		timeout:                 int64(timeout),
 		nfsexportNamePrefix:     nfsexportNamePrefix,
 		nfsexportNameUUIDLength: nfsexportNameUUIDLength,
 	}
 }
 
-func (handler *csiHandler) CreateNfsExport(content *crdv1.VolumeNfsExportContent, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), handler.timeout)
+// setTimeout changes the timeout of the CSI calls.
+func (handler *csiHandler) setTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&handler.timeout, int64(timeout))
+}
+
+func (handler *csiHandler) getTimeout() time.Duration {
+	return time.Duration(atomic.LoadInt64(&handler.timeout))
+}
+
+// recordAudit records an operation issued to the driver for the nfsexport
+// of content to the audit sink, if any.
+func (handler *csiHandler) recordAudit(operation audit.Operation, content *crdv1.VolumeNfsExportContent, nfsexportHandle string, start time.Time, err error) {
+	if handler.auditSink == nil {
+		return
+	}
+	record := audit.Record{
+		Time:                   start.UTC(),
+		Operation:              operation,
+		Driver:                 content.Spec.Driver,
+		NfsExportHandle:        nfsexportHandle,
+		VolumeNfsExportContent: content.Name,
+		User:                   content.Annotations[utils.AnnRequestedBy],
+		Result:                 audit.ResultSuccess,
+		DurationSeconds:        time.Since(start).Seconds(),
+	}
+	if ref := content.Spec.VolumeNfsExportRef; ref.Name != "" {
+		record.VolumeNfsExport = ref.Namespace + "/" + ref.Name
+		record.VolumeNfsExportUID = string(ref.UID)
+	}
+	if err != nil {
+		record.Result = audit.ResultFailure
+		record.Error = err.Error()
+	}
+	handler.auditSink.Record(record)
+}
+
+func (handler *csiHandler) CreateNfsExport(content *crdv1.VolumeNfsExportContent, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, []string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), handler.getTimeout())
 	defer cancel()
+	ctx, warnings := nfsexporter.WithWarnings(ctx)
 
 	if content.Spec.VolumeNfsExportRef.UID == "" {
-		return "", "", time.Time{}, 0, false, fmt.Errorf("cannot create nfsexport. NfsExport content %s not bound to a nfsexport", content.Name)
+		return "", "", time.Time{}, 0, false, nil, fmt.Errorf("cannot create nfsexport. NfsExport content %s not bound to a nfsexport", content.Name)
 	}
 
 	if content.Spec.Source.VolumeHandle == nil {
-		return "", "", time.Time{}, 0, false, fmt.Errorf("cannot create nfsexport. Volume handle not found in nfsexport content %s", content.Name)
+		return "", "", time.Time{}, 0, false, nil, fmt.Errorf("cannot create nfsexport. Volume handle not found in nfsexport content %s", content.Name)
 	}
 
 	nfsexportName, err := makeNfsExportName(handler.nfsexportNamePrefix, string(content.Spec.VolumeNfsExportRef.UID), handler.nfsexportNameUUIDLength)
 	if err != nil {
-		return "", "", time.Time{}, 0, false, err
+		return "", "", time.Time{}, 0, false, nil, err
 	}
-	return handler.nfsexporter.CreateNfsExport(ctx, nfsexportName, *content.Spec.Source.VolumeHandle, parameters, nfsexporterCredentials)
+	start := time.Now()
+	driverName, nfsexportID, creationTime, size, readyToUse, err := handler.nfsexporter.CreateNfsExport(ctx, nfsexportName, *content.Spec.Source.VolumeHandle, parameters, nfsexporterCredentials)
+	handler.recordAudit(audit.OperationCreate, content, nfsexportID, start, err)
+	return driverName, nfsexportID, creationTime, size, readyToUse, warnings.Messages(), err
+}
+
+func (handler *csiHandler) CreateMultiSourceNfsExport(content *crdv1.VolumeNfsExportContent, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, []crdv1.NfsExportSourceVolume, []string, error) {
+	multiSource, ok := handler.nfsexporter.(nfsexporter.MultiSourceNfsExportter)
+	if !ok {
+		return "", "", time.Time{}, 0, false, nil, nil, errMultiSourceNotSupported
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handler.getTimeout())
+	defer cancel()
+	ctx, warnings := nfsexporter.WithWarnings(ctx)
+
+	if content.Spec.VolumeNfsExportRef.UID == "" {
+		return "", "", time.Time{}, 0, false, nil, nil, fmt.Errorf("cannot create nfsexport. NfsExport content %s not bound to a nfsexport", content.Name)
+	}
+
+	if content.Spec.Source.VolumeHandle == nil {
+		return "", "", time.Time{}, 0, false, nil, nil, fmt.Errorf("cannot create nfsexport. Volume handle not found in nfsexport content %s", content.Name)
+	}
+
+	supported, err := multiSource.SupportsMultiSourceNfsExports(ctx)
+	if err != nil {
+		return "", "", time.Time{}, 0, false, nil, nil, fmt.Errorf("failed to check if nfsexports from several volumes are supported: %q", err)
+	}
+	if !supported {
+		return "", "", time.Time{}, 0, false, nil, nil, errMultiSourceNotSupported
+	}
+
+	nfsexportName, err := makeNfsExportName(handler.nfsexportNamePrefix, string(content.Spec.VolumeNfsExportRef.UID), handler.nfsexportNameUUIDLength)
+	if err != nil {
+		return "", "", time.Time{}, 0, false, nil, nil, err
+	}
+	volumeHandles := append([]string{*content.Spec.Source.VolumeHandle}, content.Spec.Source.VolumeHandles...)
+	start := time.Now()
+	driverName, nfsexportID, creationTime, size, readyToUse, subdirectories, err := multiSource.CreateMultiSourceNfsExport(ctx, nfsexportName, volumeHandles, parameters, nfsexporterCredentials)
+	handler.recordAudit(audit.OperationCreate, content, nfsexportID, start, err)
+	sourceVolumes := make([]crdv1.NfsExportSourceVolume, 0, len(volumeHandles))
+	for _, volumeHandle := range volumeHandles {
+		sourceVolumes = append(sourceVolumes, crdv1.NfsExportSourceVolume{VolumeHandle: volumeHandle, Subdirectory: subdirectories[volumeHandle]})
+	}
+	return driverName, nfsexportID, creationTime, size, readyToUse, sourceVolumes, warnings.Messages(), err
 }
 
 func (handler *csiHandler) DeleteNfsExport(content *crdv1.VolumeNfsExportContent, nfsexporterCredentials map[string]string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), handler.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), handler.getTimeout())
 	defer cancel()
 
-	var nfsexportHandle string
-	var err error
-	if content.Status != nil && content.Status.NfsExportHandle != nil {
-		nfsexportHandle = *content.Status.NfsExportHandle
-	} else if content.Spec.Source.NfsExportHandle != nil {
-		nfsexportHandle = *content.Spec.Source.NfsExportHandle
-	} else {
+	nfsexportHandle := getNfsExportHandle(content)
+	if nfsexportHandle == "" {
 		return fmt.Errorf("failed to delete nfsexport content %s: nfsexportHandle is missing", content.Name)
 	}
 
-	err = handler.nfsexporter.DeleteNfsExport(ctx, nfsexportHandle, nfsexporterCredentials)
+	start := time.Now()
+	err := handler.nfsexporter.DeleteNfsExport(ctx, nfsexportHandle, nfsexporterCredentials)
+	handler.recordAudit(audit.OperationDelete, content, nfsexportHandle, start, err)
 	if err != nil {
 		return fmt.Errorf("failed to delete nfsexport content %s: %q", content.Name, err)
 	}
@@ -97,8 +216,67 @@ func (handler *csiHandler) DeleteNfsExport(content *crdv1.VolumeNfsExportContent
 	return nil
 }
 
+func (handler *csiHandler) DeleteNfsExports(contents []*crdv1.VolumeNfsExportContent, nfsexporterCredentials map[string]string) map[string]error {
+	bulkDeleter, ok := handler.nfsexporter.(nfsexporter.BulkNfsExportDeleter)
+	if !ok || len(contents) == 1 {
+		failed := map[string]error{}
+		for _, content := range contents {
+			if err := handler.DeleteNfsExport(content, nfsexporterCredentials); err != nil {
+				failed[content.Name] = err
+			}
+		}
+		return failed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handler.getTimeout())
+	defer cancel()
+
+	failed := map[string]error{}
+	contentNames := map[string][]string{}
+	var nfsexportHandles []string
+	for _, content := range contents {
+		nfsexportHandle := getNfsExportHandle(content)
+		if nfsexportHandle == "" {
+			failed[content.Name] = fmt.Errorf("failed to delete nfsexport content %s: nfsexportHandle is missing", content.Name)
+			continue
+		}
+		if _, found := contentNames[nfsexportHandle]; !found {
+			nfsexportHandles = append(nfsexportHandles, nfsexportHandle)
+		}
+		contentNames[nfsexportHandle] = append(contentNames[nfsexportHandle], content.Name)
+	}
+	if len(nfsexportHandles) == 0 {
+		return failed
+	}
+
+	start := time.Now()
+	handleErrors, err := bulkDeleter.DeleteNfsExports(ctx, nfsexportHandles, nfsexporterCredentials)
+	for _, content := range contents {
+		if nfsexportHandle := getNfsExportHandle(content); nfsexportHandle != "" {
+			handleErr := err
+			if handleErr == nil {
+				handleErr = handleErrors[nfsexportHandle]
+			}
+			handler.recordAudit(audit.OperationDelete, content, nfsexportHandle, start, handleErr)
+		}
+	}
+	for _, nfsexportHandle := range nfsexportHandles {
+		handleErr := err
+		if handleErr == nil {
+			handleErr = handleErrors[nfsexportHandle]
+		}
+		if handleErr == nil {
+			continue
+		}
+		for _, contentName := range contentNames[nfsexportHandle] {
+			failed[contentName] = fmt.Errorf("failed to delete nfsexport content %s: %q", contentName, handleErr)
+		}
+	}
+	return failed
+}
+
 func (handler *csiHandler) GetNfsExportStatus(content *crdv1.VolumeNfsExportContent, nfsexporterListCredentials map[string]string) (bool, time.Time, int64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), handler.timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), handler.getTimeout())
 	defer cancel()
 
 	var nfsexportHandle string
@@ -119,6 +297,80 @@ func (handler *csiHandler) GetNfsExportStatus(content *crdv1.VolumeNfsExportCont
 	return csiNfsExportStatus, timestamp, size, nil
 }
 
+func (handler *csiHandler) GetNfsExportAttributes(nfsexportHandle string, nfsexporterCredentials map[string]string) (map[string]string, error) {
+	attributesGetter, ok := handler.nfsexporter.(nfsexporter.NfsExportAttributesGetter)
+	if !ok {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handler.getTimeout())
+	defer cancel()
+
+	attributes, err := attributesGetter.GetNfsExportAttributes(ctx, nfsexportHandle, nfsexporterCredentials)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attributes of nfsexport %s: %q", nfsexportHandle, err)
+	}
+	return attributes, nil
+}
+
+func (handler *csiHandler) SupportsExportCacheTiers() (bool, error) {
+	supporter, ok := handler.nfsexporter.(nfsexporter.ExportCacheTierSupporter)
+	if !ok {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handler.getTimeout())
+	defer cancel()
+
+	supported, err := supporter.SupportsExportCacheTiers(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if export cache tiers are supported: %q", err)
+	}
+	return supported, nil
+}
+
+func (handler *csiHandler) UpdateNfsExport(content *crdv1.VolumeNfsExportContent, parameters map[string]string, nfsexporterCredentials map[string]string) error {
+	updater, ok := handler.nfsexporter.(nfsexporter.NfsExportUpdater)
+	if !ok {
+		return errUpdateNotSupported
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), handler.getTimeout())
+	defer cancel()
+
+	supported, err := updater.SupportsUpdateNfsExport(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check if UpdateNfsExport is supported: %q", err)
+	}
+	if !supported {
+		return errUpdateNotSupported
+	}
+
+	nfsexportHandle := getNfsExportHandle(content)
+	if nfsexportHandle == "" {
+		return fmt.Errorf("failed to update nfsexport content %s: nfsexportHandle is missing", content.Name)
+	}
+	start := time.Now()
+	err = updater.UpdateNfsExport(ctx, nfsexportHandle, parameters, nfsexporterCredentials)
+	handler.recordAudit(audit.OperationUpdate, content, nfsexportHandle, start, err)
+	if err != nil {
+		return fmt.Errorf("failed to update nfsexport content %s: %q", content.Name, err)
+	}
+	return nil
+}
+
+// getNfsExportHandle returns the nfsexport handle of a content, or an empty
+// string if it has none.
+func getNfsExportHandle(content *crdv1.VolumeNfsExportContent) string {
+	if content.Status != nil && content.Status.NfsExportHandle != nil {
+		return *content.Status.NfsExportHandle
+	}
+	if content.Spec.Source.NfsExportHandle != nil {
+		return *content.Spec.Source.NfsExportHandle
+	}
+	return ""
+}
+
 func makeNfsExportName(prefix, nfsexportUID string, nfsexportNameUUIDLength int) (string, error) {
 	// create persistent name based on a volumeNamePrefix and volumeNameUUIDLength
 	// of PVC's UID
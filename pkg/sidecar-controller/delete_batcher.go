@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+
+	klog "k8s.io/klog/v2"
+)
+
+// deleteBatcher groups the deletions of contents requested by concurrent
+// workers into DeleteNfsExports calls. Contents are grouped by credentials,
+// and a group is deleted once it holds maxSize contents or window after its
+// first content was added. As each worker waits for the deletion of its
+// content, a group never holds more contents than there are workers.
+type deleteBatcher struct {
+	handler Handler
+	maxSize int
+	window  time.Duration
+
+	lock sync.Mutex
+	// pending holds the groups being filled, by credentials.
+	pending map[string]*deleteBatch
+}
+
+// deleteBatch is a group of contents deleted in a single call.
+type deleteBatch struct {
+	credentials map[string]string
+	contents    []*crdv1.VolumeNfsExportContent
+	flushed     bool
+	// failed holds the errors of the contents that were not deleted, by
+	// content name. It is set before done is closed.
+	failed map[string]error
+	done   chan struct{}
+}
+
+func newDeleteBatcher(handler Handler, maxSize int, window time.Duration) *deleteBatcher {
+	return &deleteBatcher{
+		handler: handler,
+		maxSize: maxSize,
+		window:  window,
+		pending: make(map[string]*deleteBatch),
+	}
+}
+
+// DeleteNfsExport adds the content to the group of its credentials and waits
+// until the group is deleted.
+func (b *deleteBatcher) DeleteNfsExport(content *crdv1.VolumeNfsExportContent, nfsexporterCredentials map[string]string) error {
+	key := credentialsKey(nfsexporterCredentials)
+
+	b.lock.Lock()
+	batch, found := b.pending[key]
+	if !found {
+		batch = &deleteBatch{
+			credentials: nfsexporterCredentials,
+			done:        make(chan struct{}),
+		}
+		b.pending[key] = batch
+		time.AfterFunc(b.window, func() { b.flush(key, batch) })
+	}
+	batch.contents = append(batch.contents, content)
+	full := len(batch.contents) >= b.maxSize
+	b.lock.Unlock()
+
+	if full {
+		b.flush(key, batch)
+	}
+	<-batch.done
+	return batch.failed[content.Name]
+}
+
+// flush deletes the contents of a group, unless it was already deleted.
+func (b *deleteBatcher) flush(key string, batch *deleteBatch) {
+	b.lock.Lock()
+	if batch.flushed {
+		b.lock.Unlock()
+		return
+	}
+	batch.flushed = true
+	if b.pending[key] == batch {
+		delete(b.pending, key)
+	}
+	b.lock.Unlock()
+
+	klog.V(4).Infof("deleting nfsexports of %d contents in a single call", len(batch.contents))
+	batch.failed = b.handler.DeleteNfsExports(batch.contents, batch.credentials)
+	close(batch.done)
+}
+
+// credentialsKey returns a key identifying the credentials.
+func credentialsKey(credentials map[string]string) string {
+	keys := make([]string, 0, len(credentials))
+	for key := range credentials {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(key)
+		sb.WriteByte(0)
+		sb.WriteString(credentials[key])
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// bulkDeleteHandler is a Handler recording DeleteNfsExports calls.
+type bulkDeleteHandler struct {
+	Handler
+
+	lock   sync.Mutex
+	calls  [][]string
+	failed map[string]error
+}
+
+func (h *bulkDeleteHandler) DeleteNfsExports(contents []*crdv1.VolumeNfsExportContent, nfsexporterCredentials map[string]string) map[string]error {
+	var names []string
+	failed := map[string]error{}
+	for _, content := range contents {
+		names = append(names, content.Name)
+		if err, found := h.failed[content.Name]; found {
+			failed[content.Name] = err
+		}
+	}
+	sort.Strings(names)
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.calls = append(h.calls, names)
+	return failed
+}
+
+func batchContent(name string) *crdv1.VolumeNfsExportContent {
+	return &crdv1.VolumeNfsExportContent{ObjectMeta: metav1.ObjectMeta{Name: name}}
+}
+
+// deleteConcurrently deletes the contents from concurrent workers and returns
+// their errors by content name.
+func deleteConcurrently(b *deleteBatcher, contents []*crdv1.VolumeNfsExportContent, credentials []map[string]string) map[string]error {
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	errs := map[string]error{}
+	for i := range contents {
+		wg.Add(1)
+		go func(content *crdv1.VolumeNfsExportContent, credentials map[string]string) {
+			defer wg.Done()
+			err := b.DeleteNfsExport(content, credentials)
+			lock.Lock()
+			errs[content.Name] = err
+			lock.Unlock()
+		}(contents[i], credentials[i])
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestDeleteBatcherGroupsByCredentials(t *testing.T) {
+	handler := &bulkDeleteHandler{failed: map[string]error{"content2": errors.New("mock delete error")}}
+	b := newDeleteBatcher(handler, 10, 50*time.Millisecond)
+	gold := map[string]string{"user": "gold"}
+	silver := map[string]string{"user": "silver"}
+
+	errs := deleteConcurrently(b,
+		[]*crdv1.VolumeNfsExportContent{batchContent("content1"), batchContent("content2"), batchContent("content3")},
+		[]map[string]string{gold, gold, silver})
+
+	if errs["content1"] != nil || errs["content3"] != nil {
+		t.Errorf("expected content1 and content3 to be deleted, got %v", errs)
+	}
+	if errs["content2"] == nil {
+		t.Errorf("expected the error of content2 to be returned")
+	}
+	sort.Slice(handler.calls, func(i, j int) bool { return len(handler.calls[i]) > len(handler.calls[j]) })
+	if len(handler.calls) != 2 || len(handler.calls[0]) != 2 || handler.calls[0][0] != "content1" || handler.calls[0][1] != "content2" || handler.calls[1][0] != "content3" {
+		t.Errorf("expected one call per credentials, got %v", handler.calls)
+	}
+}
+
+func TestDeleteBatcherFlushesFullBatch(t *testing.T) {
+	handler := &bulkDeleteHandler{}
+	// The window is longer than the test timeout, so only a full batch can
+	// complete the deletions.
+	b := newDeleteBatcher(handler, 2, time.Hour)
+
+	deleteConcurrently(b,
+		[]*crdv1.VolumeNfsExportContent{batchContent("content1"), batchContent("content2")},
+		[]map[string]string{nil, nil})
+
+	if len(handler.calls) != 1 || len(handler.calls[0]) != 2 {
+		t.Errorf("expected a single call with both contents, got %v", handler.calls)
+	}
+	if len(b.pending) != 0 {
+		t.Errorf("expected no pending batch, got %d", len(b.pending))
+	}
+}
+
+func TestCredentialsKey(t *testing.T) {
+	if credentialsKey(map[string]string{"a": "b", "c": "d"}) != credentialsKey(map[string]string{"c": "d", "a": "b"}) {
+		t.Errorf("expected the key not to depend on the order of the credentials")
+	}
+	if credentialsKey(map[string]string{"a": "bc"}) == credentialsKey(map[string]string{"ab": "c"}) {
+		t.Errorf("expected different credentials to have different keys")
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"reflect"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	klog "k8s.io/klog/v2"
+)
+
+// DriverInfoPublisher publishes the version and the capabilities of the CSI
+// driver on the status of the VolumeNfsExportClasses of the driver, so that
+// UIs can show what the backend supports.
+type DriverInfoPublisher struct {
+	clientset  clientset.Interface
+	driverName string
+	info       *crdv1.NfsExportDriverInfo
+
+	classLister       storagelisters.VolumeNfsExportClassLister
+	classListerSynced cache.InformerSynced
+	classQueue        workqueue.RateLimitingInterface
+}
+
+// NewDriverInfoPublisher returns a new *DriverInfoPublisher publishing info
+// on the classes of driverName.
+func NewDriverInfoPublisher(
+	clientset clientset.Interface,
+	driverName string,
+	info *crdv1.NfsExportDriverInfo,
+	volumeNfsExportClassInformer storageinformers.VolumeNfsExportClassInformer,
+	classRateLimiter workqueue.RateLimiter,
+) *DriverInfoPublisher {
+	p := &DriverInfoPublisher{
+		clientset:         clientset,
+		driverName:        driverName,
+		info:              info,
+		classLister:       volumeNfsExportClassInformer.Lister(),
+		classListerSynced: volumeNfsExportClassInformer.Informer().HasSynced,
+		classQueue:        workqueue.NewNamedRateLimitingQueue(classRateLimiter, "csi-nfsexporter-class"),
+	}
+	volumeNfsExportClassInformer.Informer().AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { p.enqueueClassWork(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { p.enqueueClassWork(newObj) },
+		},
+	)
+	return p
+}
+
+// Run publishes the driver information until stopCh is closed.
+func (p *DriverInfoPublisher) Run(stopCh <-chan struct{}) {
+	defer p.classQueue.ShutDown()
+
+	klog.Infof("Starting driver info publisher for %s", p.driverName)
+	defer klog.Infof("Shutting down driver info publisher for %s", p.driverName)
+
+	if !cache.WaitForCacheSync(stopCh, p.classListerSynced) {
+		klog.Errorf("Cannot sync caches")
+		return
+	}
+
+	go wait.Until(p.classWorker, 0, stopCh)
+
+	<-stopCh
+}
+
+// enqueueClassWork adds the classes of the driver to the work queue.
+func (p *DriverInfoPublisher) enqueueClassWork(obj interface{}) {
+	class, ok := obj.(*crdv1.VolumeNfsExportClass)
+	if !ok || class.Driver != p.driverName {
+		return
+	}
+	p.classQueue.Add(class.Name)
+}
+
+func (p *DriverInfoPublisher) classWorker() {
+	for p.processNextClass() {
+	}
+}
+
+func (p *DriverInfoPublisher) processNextClass() bool {
+	keyObj, quit := p.classQueue.Get()
+	if quit {
+		return false
+	}
+	defer p.classQueue.Done(keyObj)
+
+	if err := p.syncClassByKey(keyObj.(string)); err != nil {
+		p.classQueue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to publish driver info on class %q, will retry again: %v", keyObj.(string), err)
+		return true
+	}
+	p.classQueue.Forget(keyObj)
+	return true
+}
+
+// syncClassByKey updates the driver information of the status of the class
+// if it is out of date.
+func (p *DriverInfoPublisher) syncClassByKey(name string) error {
+	class, err := p.classLister.Get(name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if class.Driver != p.driverName {
+		return nil
+	}
+	if class.Status != nil && reflect.DeepEqual(class.Status.DriverInfo, p.info) {
+		return nil
+	}
+
+	patch := []utils.PatchOp{{Op: "add", Path: "/status/driverInfo", Value: p.info}}
+	if class.Status == nil {
+		patch = []utils.PatchOp{{Op: "add", Path: "/status", Value: &crdv1.VolumeNfsExportClassStatus{DriverInfo: p.info}}}
+	}
+	if _, err := utils.PatchVolumeNfsExportClass(class, patch, p.clientset, "status"); err != nil {
+		return err
+	}
+	klog.V(4).Infof("Published driver info %+v on class %s", p.info, name)
+	return nil
+}
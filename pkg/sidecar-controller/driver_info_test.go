@@ -0,0 +1,102 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sidecar_controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientsetfake "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestPublishDriverInfo(t *testing.T) {
+	info := &crdv1.NfsExportDriverInfo{
+		VendorVersion:          "v1.2.3",
+		PluginCapabilities:     []string{"CONTROLLER_SERVICE"},
+		ControllerCapabilities: []string{"CREATE_DELETE_VOLUME"},
+	}
+	oldInfo := &crdv1.NfsExportDriverInfo{VendorVersion: "v1.2.2"}
+
+	tests := []struct {
+		name         string
+		class        *crdv1.VolumeNfsExportClass
+		expectPatch  bool
+		expectedInfo *crdv1.NfsExportDriverInfo
+	}{
+		{
+			name:         "class without status",
+			class:        &crdv1.VolumeNfsExportClass{ObjectMeta: metav1.ObjectMeta{Name: "class1"}, Driver: mockDriverName},
+			expectPatch:  true,
+			expectedInfo: info,
+		},
+		{
+			name: "class with outdated driver info",
+			class: &crdv1.VolumeNfsExportClass{ObjectMeta: metav1.ObjectMeta{Name: "class1"}, Driver: mockDriverName,
+				Status: &crdv1.VolumeNfsExportClassStatus{DriverInfo: oldInfo}},
+			expectPatch:  true,
+			expectedInfo: info,
+		},
+		{
+			name: "class with current driver info",
+			class: &crdv1.VolumeNfsExportClass{ObjectMeta: metav1.ObjectMeta{Name: "class1"}, Driver: mockDriverName,
+				Status: &crdv1.VolumeNfsExportClassStatus{DriverInfo: info}},
+			expectedInfo: info,
+		},
+		{
+			name:  "class of another driver",
+			class: &crdv1.VolumeNfsExportClass{ObjectMeta: metav1.ObjectMeta{Name: "class1"}, Driver: "other.driver"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			indexer.Add(test.class)
+			client := clientsetfake.NewSimpleClientset(test.class)
+			p := &DriverInfoPublisher{
+				clientset:   client,
+				driverName:  mockDriverName,
+				info:        info,
+				classLister: storagelisters.NewVolumeNfsExportClassLister(indexer),
+			}
+
+			if err := p.syncClassByKey("class1"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if patched := len(client.Actions()) > 0; patched != test.expectPatch {
+				t.Errorf("expected patch: %v, got actions %v", test.expectPatch, client.Actions())
+			}
+			class, err := client.NfsExportV1().VolumeNfsExportClasses().Get(context.TODO(), "class1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got *crdv1.NfsExportDriverInfo
+			if class.Status != nil {
+				got = class.Status.DriverInfo
+			}
+			if !reflect.DeepEqual(got, test.expectedInfo) {
+				t.Errorf("expected driver info %+v, got %+v", test.expectedInfo, got)
+			}
+		})
+	}
+}
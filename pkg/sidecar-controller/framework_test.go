@@ -33,11 +33,15 @@ import (
 	nfsexportscheme "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/scheme"
 	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
 	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/fakeapiserver"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/nfsexporter"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/diff"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -45,11 +49,13 @@ import (
 	"k8s.io/client-go/kubernetes"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/scheme"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	klog "k8s.io/klog/v2"
+	clocktesting "k8s.io/utils/clock/testing"
 )
 
 // This is a unit test framework for nfsexport sidecar controller.
@@ -82,17 +88,25 @@ type controllerTest struct {
 	expectedContents []*crdv1.VolumeNfsExportContent
 	// Initial content of controller Secret cache.
 	initialSecrets []*v1.Secret
+	// Initial PVCs and nfsexports in the API server.
+	initialClaims     []*v1.PersistentVolumeClaim
+	initialNfsExports []*crdv1.VolumeNfsExport
 	// Expected events - any event with prefix will pass, we don't check full
 	// event message.
 	expectedEvents []string
 	// Errors to produce on matching action
-	errors []reactorError
+	errors []fakeapiserver.Hook
 	// List of expected CSI Create nfsexport calls
 	expectedCreateCalls []createCall
 	// List of expected CSI Delete nfsexport calls
 	expectedDeleteCalls []deleteCall
 	// List of expected CSI list nfsexport calls
 	expectedListCalls []listCall
+	// List of expected CSI update nfsexport calls
+	expectedUpdateCalls []updateCall
+	// cacheTiersUnsupported makes the CSI driver report that it does not
+	// support export cache tiers.
+	cacheTiersUnsupported bool
 	// Function to call as the test.
 	test          testCall
 	expectSuccess bool
@@ -109,7 +123,7 @@ var (
 	errVersionConflict = errors.New("VersionError")
 	nocontents         []*crdv1.VolumeNfsExportContent
 	noevents           = []string{}
-	noerrors           = []reactorError{}
+	noerrors           = []fakeapiserver.Hook{}
 )
 
 // nfsexportReactor is a core.Reactor that simulates etcd and API server. It
@@ -123,13 +137,13 @@ var (
 //   used by the controller. Any time an event function like deleteContentEvent
 //   is called to simulate an event, the reactor's stores are updated and the
 //   controller is sent the event via the fake watcher.
-// - Optionally, list of error that should be returned by reactor, simulating
-//   etcd / API server failures. These errors are evaluated in order and every
-//   error is returned only once. I.e. when the reactor finds matching
-//   reactorError, it return appropriate error and removes the reactorError from
-//   the list.
+// - Optionally, hooks that fail the matching actions, simulating etcd / API
+//   server failures. They are installed on the fake clients before the
+//   reactor, see fakeapiserver.Hooks.
 type nfsexportReactor struct {
 	secrets              map[string]*v1.Secret
+	claims               map[string]*v1.PersistentVolumeClaim
+	nfsexports           map[string]*crdv1.VolumeNfsExport
 	nfsexportClasses      map[string]*crdv1.VolumeNfsExportClass
 	contents             map[string]*crdv1.VolumeNfsExportContent
 	changedObjects       []interface{}
@@ -137,17 +151,7 @@ type nfsexportReactor struct {
 	ctrl                 *csiNfsExportSideCarController
 	fakeContentWatch     *watch.FakeWatcher
 	lock                 sync.Mutex
-	errors               []reactorError
-}
-
-// reactorError is an error that is returned by test reactor (=simulated
-// etcd+/API server) when an action performed by the reactor matches given verb
-// ("get", "update", "create", "delete" or "*"") on given resource
-// ("volumenfsexportcontents" or "*").
-type reactorError struct {
-	verb     string
-	resource string
-	error    error
+	hooks                *fakeapiserver.Hooks
 }
 
 func withContentFinalizer(content *crdv1.VolumeNfsExportContent) *crdv1.VolumeNfsExportContent {
@@ -170,13 +174,8 @@ func (r *nfsexportReactor) React(action core.Action) (handled bool, ret runtime.
 
 	klog.V(4).Infof("reactor got operation %q on %q", action.GetVerb(), action.GetResource())
 
-	// Inject error when requested
-	err = r.injectReactError(action)
-	if err != nil {
-		return true, nil, err
-	}
-
-	// Test did not request to inject an error, continue simulating API server.
+	// Errors requested by the test are injected by the hooks, continue
+	// simulating API server.
 	switch {
 	case action.Matches("create", "volumenfsexportcontents"):
 		obj := action.(core.UpdateAction).GetObject()
@@ -233,14 +232,22 @@ func (r *nfsexportReactor) React(action core.Action) (handled bool, ret runtime.
 			if err != nil {
 				return true, nil, err
 			}
-			contentPatch, err := jsonpatch.DecodePatch(action.GetPatch())
-			if err != nil {
-				return true, nil, err
-			}
-
-			modified, err := contentPatch.Apply(storedNfsExportBytes)
-			if err != nil {
-				return true, nil, err
+			var modified []byte
+			if action.GetPatchType() == types.ApplyPatchType {
+				modified, err = fakeapiserver.ApplyStatus(action, storedNfsExportBytes, utils.SidecarFieldManager)
+				if err != nil {
+					return true, nil, err
+				}
+			} else {
+				contentPatch, err := jsonpatch.DecodePatch(action.GetPatch())
+				if err != nil {
+					return true, nil, err
+				}
+
+				modified, err = contentPatch.Apply(storedNfsExportBytes)
+				if err != nil {
+					return true, nil, fakeapiserver.PatchApplyError(action, err)
+				}
 			}
 
 			err = json.Unmarshal(modified, content)
@@ -292,29 +299,50 @@ func (r *nfsexportReactor) React(action core.Action) (handled bool, ret runtime.
 		klog.V(4).Infof("GetSecret: secret %s not found", name)
 		return true, nil, fmt.Errorf("cannot find secret %s", name)
 
+	case action.Matches("list", "persistentvolumeclaims"):
+		claims := &v1.PersistentVolumeClaimList{}
+		for _, claim := range r.claims {
+			if claim.Namespace == action.GetNamespace() {
+				claims.Items = append(claims.Items, *claim.DeepCopy())
+			}
+		}
+		return true, claims, nil
+
+	case action.Matches("get", "volumenfsexports"):
+		name := action.(core.GetAction).GetName()
+		nfsexport, found := r.nfsexports[name]
+		if found {
+			return true, nfsexport.DeepCopy(), nil
+		}
+		return true, nil, apierrs.NewNotFound(crdv1.Resource("volumenfsexports"), name)
+
 	}
 
 	return false, nil, nil
 }
 
-// injectReactError returns an error when the test requested given action to
-// fail. nil is returned otherwise.
-func (r *nfsexportReactor) injectReactError(action core.Action) error {
-	if len(r.errors) == 0 {
-		// No more errors to inject, everything should succeed.
-		return nil
-	}
+// conflictError returns the error the API server responds with when an
+// object has been modified concurrently. Tests inject it to simulate
+// conflicting writers.
+func conflictError(resource, name string) error {
+	return apierrs.NewConflict(schema.GroupResource{Group: crdv1.GroupName, Resource: resource}, name, errors.New("the object has been modified"))
+}
 
-	for i, expected := range r.errors {
-		klog.V(4).Infof("trying to match %q %q with %q %q", expected.verb, expected.resource, action.GetVerb(), action.GetResource())
-		if action.Matches(expected.verb, expected.resource) {
-			// That's the action we're waiting for, remove it from injectedErrors
-			r.errors = append(r.errors[:i], r.errors[i+1:]...)
-			klog.V(4).Infof("reactor found matching error at index %d: %q %q, returning %v", i, expected.verb, expected.resource, expected.error)
-			return expected.error
-		}
+// normalizeObjectMeta clears ResourceVersion and the differences in
+// representation an object gets after it has been serialized by a patch:
+// timestamps are stored with second precision and empty finalizers are dropped.
+// The managed fields recorded by a status apply are cleared as well.
+func normalizeObjectMeta(meta *metav1.ObjectMeta) {
+	meta.ResourceVersion = ""
+	meta.ManagedFields = nil
+	meta.CreationTimestamp = meta.CreationTimestamp.Rfc3339Copy()
+	if meta.DeletionTimestamp != nil {
+		deletionTimestamp := meta.DeletionTimestamp.Rfc3339Copy()
+		meta.DeletionTimestamp = &deletionTimestamp
+	}
+	if len(meta.Finalizers) == 0 {
+		meta.Finalizers = nil
 	}
-	return nil
 }
 
 // checkContents compares all expectedContents with set of contents at the end of
@@ -329,10 +357,14 @@ func (r *nfsexportReactor) checkContents(expectedContents []*crdv1.VolumeNfsExpo
 	for _, v := range expectedContents {
 		// Don't modify the existing object
 		v := v.DeepCopy()
-		v.ResourceVersion = ""
+		normalizeObjectMeta(&v.ObjectMeta)
 		v.Spec.VolumeNfsExportRef.ResourceVersion = ""
 		if v.Status != nil {
 			v.Status.CreationTime = nil
+			v.Status.LastTransitionTime = nil
+			v.Status.LastSyncTime = nil
+			normalizeErrorHistory(v.Status.ErrorHistory)
+			normalizeConditions(v.Status.Conditions)
 		}
 		if v.Status.Error != nil {
 			v.Status.Error.Time = &metav1.Time{}
@@ -343,10 +375,14 @@ func (r *nfsexportReactor) checkContents(expectedContents []*crdv1.VolumeNfsExpo
 		// We must clone the content because of golang race check - it was
 		// written by the controller without any locks on it.
 		v := v.DeepCopy()
-		v.ResourceVersion = ""
+		normalizeObjectMeta(&v.ObjectMeta)
 		v.Spec.VolumeNfsExportRef.ResourceVersion = ""
 		if v.Status != nil {
 			v.Status.CreationTime = nil
+			v.Status.LastTransitionTime = nil
+			v.Status.LastSyncTime = nil
+			normalizeErrorHistory(v.Status.ErrorHistory)
+			normalizeConditions(v.Status.Conditions)
 			if v.Status.Error != nil {
 				v.Status.Error.Time = &metav1.Time{}
 			}
@@ -362,6 +398,22 @@ func (r *nfsexportReactor) checkContents(expectedContents []*crdv1.VolumeNfsExpo
 	return nil
 }
 
+// normalizeConditions clears the transition times of conditions, which depend
+// on when the test ran.
+func normalizeConditions(conditions []metav1.Condition) {
+	for i := range conditions {
+		conditions[i].LastTransitionTime = metav1.Time{}
+	}
+}
+
+// normalizeErrorHistory clears the timestamps of errorHistory entries, which
+// depend on when the test ran.
+func normalizeErrorHistory(history []crdv1.VolumeNfsExportError) {
+	for i := range history {
+		history[i].Time = &metav1.Time{}
+	}
+}
+
 // checkEvents compares all expectedEvents with events generated during the test
 // and reports differences.
 func checkEvents(t *testing.T, expectedEvents []string, ctrl *csiNfsExportSideCarController) error {
@@ -522,22 +574,29 @@ func (r *nfsexportReactor) modifyContentEvent(content *crdv1.VolumeNfsExportCont
 	}
 }
 
-func newNfsExportReactor(kubeClient *kubefake.Clientset, client *fake.Clientset, ctrl *csiNfsExportSideCarController, fakeVolumeWatch, fakeClaimWatch *watch.FakeWatcher, errors []reactorError) *nfsexportReactor {
+func newNfsExportReactor(kubeClient *kubefake.Clientset, client *fake.Clientset, ctrl *csiNfsExportSideCarController, fakeVolumeWatch, fakeClaimWatch *watch.FakeWatcher, errors []fakeapiserver.Hook) *nfsexportReactor {
 	reactor := &nfsexportReactor{
 		secrets:          make(map[string]*v1.Secret),
+		claims:           make(map[string]*v1.PersistentVolumeClaim),
+		nfsexports:       make(map[string]*crdv1.VolumeNfsExport),
 		nfsexportClasses:  make(map[string]*crdv1.VolumeNfsExportClass),
 		contents:         make(map[string]*crdv1.VolumeNfsExportContent),
 		ctrl:             ctrl,
 		fakeContentWatch: fakeVolumeWatch,
-		errors:           errors,
+		hooks:            fakeapiserver.NewHooks(errors...),
 	}
 
+	reactor.hooks.Install(&client.Fake)
+	reactor.hooks.Install(&kubeClient.Fake)
+
 	client.AddReactor("create", "volumenfsexportcontents", reactor.React)
 	client.AddReactor("update", "volumenfsexportcontents", reactor.React)
 	client.AddReactor("patch", "volumenfsexportcontents", reactor.React)
 	client.AddReactor("get", "volumenfsexportcontents", reactor.React)
 	client.AddReactor("delete", "volumenfsexportcontents", reactor.React)
 	kubeClient.AddReactor("get", "secrets", reactor.React)
+	kubeClient.AddReactor("list", "persistentvolumeclaims", reactor.React)
+	client.AddReactor("get", "volumenfsexports", reactor.React)
 
 	return reactor
 }
@@ -556,9 +615,13 @@ func newTestController(kubeClient kubernetes.Interface, clientset clientset.Inte
 		listCalls:   test.expectedListCalls,
 		createCalls: test.expectedCreateCalls,
 		deleteCalls: test.expectedDeleteCalls,
+		updateCalls: test.expectedUpdateCalls,
+
+		cacheTiersUnsupported: test.cacheTiersUnsupported,
 	}
 
 	ctrl := NewCSINfsExportSideCarController(
+		clientset,
 		clientset,
 		kubeClient,
 		mockDriverName,
@@ -571,9 +634,13 @@ func newTestController(kubeClient kubernetes.Interface, clientset clientset.Inte
 		-1,
 		true,
 		workqueue.NewItemExponentialFailureRateLimiter(1*time.Millisecond, 1*time.Minute),
+		0,
+		0,
+		time.Minute,
 	)
 
 	ctrl.eventRecorder = record.NewFakeRecorder(1000)
+	ctrl.clock = clocktesting.NewFakeClock(timeNow)
 
 	ctrl.contentListerSynced = alwaysReady
 	ctrl.classListerSynced = alwaysReady
@@ -682,6 +749,38 @@ func withContentAnnotations(content []*crdv1.VolumeNfsExportContent, annotations
 	return content
 }
 
+func withContentCacheTier(content []*crdv1.VolumeNfsExportContent, tier crdv1.VolumeNfsExportCacheTier) []*crdv1.VolumeNfsExportContent {
+	for i := range content {
+		content[i].Spec.CacheTier = &tier
+	}
+	return content
+}
+
+func withContentAttributes(content []*crdv1.VolumeNfsExportContent, attributes map[string]string) []*crdv1.VolumeNfsExportContent {
+	for i := range content {
+		content[i].Status.Attributes = attributes
+	}
+
+	return content
+}
+
+func withContentGeneration(content []*crdv1.VolumeNfsExportContent, generation, observedGeneration int64) []*crdv1.VolumeNfsExportContent {
+	for i := range content {
+		content[i].ObjectMeta.Generation = generation
+		content[i].Status.ObservedGeneration = observedGeneration
+	}
+
+	return content
+}
+
+func withContentCreationTimestamp(content []*crdv1.VolumeNfsExportContent, creationTimestamp time.Time) []*crdv1.VolumeNfsExportContent {
+	for i := range content {
+		content[i].ObjectMeta.CreationTimestamp = metav1.NewTime(creationTimestamp)
+	}
+
+	return content
+}
+
 func testSyncContent(ctrl *csiNfsExportSideCarController, reactor *nfsexportReactor, test controllerTest) error {
 	return ctrl.syncContent(test.initialContents[0])
 }
@@ -703,6 +802,11 @@ var (
 	emptySecretClass   = "empty-secret-class"
 	invalidSecretClass = "invalid-secret-class"
 	validSecretClass   = "valid-secret-class"
+	zoneClass          = "zone-class"
+	warmUpClass        = "warm-up-class"
+	timeoutClass       = "timeout-class"
+	encryptedClass     = "encrypted-class"
+	cacheTierClass     = "cache-tier-class"
 	sameDriver         = "sameDriver"
 	diffDriver         = "diffDriver"
 	noClaim            = ""
@@ -783,8 +887,20 @@ func runSyncContentTests(t *testing.T, tests []controllerTest, nfsexportClasses
 			}
 		}
 
+		secretIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
 		for _, secret := range test.initialSecrets {
 			reactor.secrets[secret.Name] = secret
+			secretIndexer.Add(secret)
+		}
+		ctrl.secretLister = corelisters.NewSecretLister(secretIndexer)
+		pvcIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{utils.ClaimSourceNfsExportIndex: utils.ClaimSourceNfsExportIndexFunc})
+		for _, claim := range test.initialClaims {
+			reactor.claims[claim.Name] = claim
+			pvcIndexer.Add(claim)
+		}
+		ctrl.pvcIndexer = pvcIndexer
+		for _, nfsexport := range test.initialNfsExports {
+			reactor.nfsexports[nfsexport.Name] = nfsexport
 		}
 
 		// Inject classes into controller via a custom lister.
@@ -836,6 +952,21 @@ func secret() *v1.Secret {
 	}
 }
 
+// kmsKeySecret is the KMS key Secret of encryptedClass with the given key
+// version.
+func kmsKeySecret(version string) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "kms-key",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"keyID":                      []byte("key-" + version),
+			utils.KMSKeySecretVersionKey: []byte(version),
+		},
+	}
+}
+
 func secretAnnotations() map[string]string {
 	return map[string]string{
 		utils.AnnDeletionSecretRefName:      "secret",
@@ -874,6 +1005,16 @@ type deleteCall struct {
 	err        error
 }
 
+type updateCall struct {
+	nfsexportID string
+	parameters  map[string]string
+	secrets     map[string]string
+	err         error
+	// unsupported makes SupportsUpdateNfsExport report that the driver
+	// cannot update nfsexports, UpdateNfsExport is then not called.
+	unsupported bool
+}
+
 type createCall struct {
 	// expected request parameter
 	nfsexportName string
@@ -887,6 +1028,10 @@ type createCall struct {
 	size         int64
 	readyToUse   bool
 	err          error
+	// attributes of the nfsexport reported after its creation
+	attributes map[string]string
+	// non-fatal warnings reported along with the nfsexport
+	warnings []string
 }
 
 // Fake NfsExporter implementation that check that Attach/Detach is called
@@ -898,7 +1043,12 @@ type fakeNfsExportter struct {
 	deleteCallCounter int
 	listCalls         []listCall
 	listCallCounter   int
+	updateCalls       []updateCall
+	updateCallCounter int
+	attributes        map[string]map[string]string
 	t                 *testing.T
+
+	cacheTiersUnsupported bool
 }
 
 func (f *fakeNfsExportter) CreateNfsExport(ctx context.Context, nfsexportName string, volumeHandle string, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, error) {
@@ -933,9 +1083,20 @@ func (f *fakeNfsExportter) CreateNfsExport(ctx context.Context, nfsexportName st
 	if err != nil {
 		return "", "", time.Time{}, 0, false, fmt.Errorf("unexpected call")
 	}
+	if call.attributes != nil {
+		if f.attributes == nil {
+			f.attributes = map[string]map[string]string{}
+		}
+		f.attributes[call.nfsexportId] = call.attributes
+	}
+	nfsexporter.AddWarnings(ctx, call.warnings...)
 	return call.driverName, call.nfsexportId, call.creationTime, call.size, call.readyToUse, call.err
 }
 
+func (f *fakeNfsExportter) GetNfsExportAttributes(ctx context.Context, nfsexportID string, nfsexporterCredentials map[string]string) (map[string]string, error) {
+	return f.attributes[nfsexportID], nil
+}
+
 func (f *fakeNfsExportter) DeleteNfsExport(ctx context.Context, nfsexportID string, nfsexporterCredentials map[string]string) error {
 	if f.deleteCallCounter >= len(f.deleteCalls) {
 		f.t.Errorf("Unexpected CSI Delete NfsExport call: nfsexportID=%s, index: %d, calls: %+v", nfsexportID, f.createCallCounter, f.createCalls)
@@ -962,6 +1123,49 @@ func (f *fakeNfsExportter) DeleteNfsExport(ctx context.Context, nfsexportID stri
 	return call.err
 }
 
+func (f *fakeNfsExportter) SupportsExportCacheTiers(ctx context.Context) (bool, error) {
+	return !f.cacheTiersUnsupported, nil
+}
+
+func (f *fakeNfsExportter) SupportsUpdateNfsExport(ctx context.Context) (bool, error) {
+	if f.updateCallCounter < len(f.updateCalls) && f.updateCalls[f.updateCallCounter].unsupported {
+		f.updateCallCounter++
+		return false, nil
+	}
+	return true, nil
+}
+
+func (f *fakeNfsExportter) UpdateNfsExport(ctx context.Context, nfsexportID string, parameters map[string]string, nfsexporterCredentials map[string]string) error {
+	if f.updateCallCounter >= len(f.updateCalls) {
+		f.t.Errorf("Unexpected CSI Update NfsExport call: nfsexportID=%s, index: %d, calls: %+v", nfsexportID, f.updateCallCounter, f.updateCalls)
+		return fmt.Errorf("unexpected UpdateNfsExport call")
+	}
+	call := f.updateCalls[f.updateCallCounter]
+	f.updateCallCounter++
+
+	var err error
+	if call.nfsexportID != nfsexportID {
+		f.t.Errorf("Wrong CSI Update NfsExport call: nfsexportID=%s, expected nfsexportID: %s", nfsexportID, call.nfsexportID)
+		err = fmt.Errorf("unexpected Update nfsexport call")
+	}
+
+	if !reflect.DeepEqual(call.parameters, parameters) {
+		f.t.Errorf("Wrong CSI Update NfsExport call: nfsexportID=%s, expected parameters %+v, got %+v", nfsexportID, call.parameters, parameters)
+		err = fmt.Errorf("unexpected Update nfsexport call")
+	}
+
+	if !reflect.DeepEqual(call.secrets, nfsexporterCredentials) && !(len(call.secrets) == 0 && len(nfsexporterCredentials) == 0) {
+		f.t.Errorf("Wrong CSI Update NfsExport call: nfsexportID=%s, expected secrets %+v, got %+v", nfsexportID, call.secrets, nfsexporterCredentials)
+		err = fmt.Errorf("unexpected Update nfsexport call")
+	}
+
+	if err != nil {
+		return fmt.Errorf("unexpected call")
+	}
+
+	return call.err
+}
+
 func (f *fakeNfsExportter) GetNfsExportStatus(ctx context.Context, nfsexportID string, nfsexporterListCredentials map[string]string) (bool, time.Time, int64, error) {
 	if f.listCallCounter >= len(f.listCalls) {
 		f.t.Errorf("Unexpected CSI list NfsExport call: nfsexportID=%s, index: %d, calls: %+v", nfsexportID, f.createCallCounter, f.createCalls)
@@ -995,4 +1199,12 @@ func newNfsExportError(message string) *crdv1.VolumeNfsExportError {
 	}
 }
 
+func newNfsExportErrorHistory(messages ...string) []crdv1.VolumeNfsExportError {
+	history := make([]crdv1.VolumeNfsExportError, 0, len(messages))
+	for _, message := range messages {
+		history = append(history, *newNfsExportError(message))
+	}
+	return history
+}
+
 func toStringPointer(str string) *string { return &str }
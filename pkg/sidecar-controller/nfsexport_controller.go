@@ -18,15 +18,20 @@ package sidecar_controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/params"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	codes "google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	klog "k8s.io/klog/v2"
 )
@@ -49,7 +54,11 @@ import (
 // to indicate the nfsexport is ready to be used to restore a volume.
 // If the creation failed for any reason, the Error status is set accordingly.
 
-const controllerUpdateFailMsg = "nfsexport controller failed to update"
+const controllerUpdateFailMsg = utils.ControllerUpdateFailMsg
+
+// maxContentErrorHistory is the number of errors kept in the errorHistory of
+// a VolumeNfsExportContent status.
+const maxContentErrorHistory = 5
 
 // syncContent deals with one key off the queue.  It returns false when it's time to quit.
 func (ctrl *csiNfsExportSideCarController) syncContent(content *crdv1.VolumeNfsExportContent) error {
@@ -63,6 +72,9 @@ func (ctrl *csiNfsExportSideCarController) syncContent(content *crdv1.VolumeNfsE
 			// underlying storage system. Note that the deletion nfsexport operation will
 			// update content NfsExportHandle to nil upon a successful deletion. At this
 			// point, the finalizer on content should NOT be removed to avoid leaking.
+			if inUse, err := ctrl.checkContentInUse(content); inUse || err != nil {
+				return err
+			}
 			return ctrl.deleteCSINfsExport(content)
 		}
 		// otherwise, either the nfsexport has been deleted from the underlying
@@ -71,6 +83,13 @@ func (ctrl *csiNfsExportSideCarController) syncContent(content *crdv1.VolumeNfsE
 		// no other finalizer.
 		return ctrl.removeContentFinalizer(content)
 	}
+	if isContentFailed(content) {
+		klog.V(4).Infof("VolumeNfsExportContent[%s]: the export failed, not polling the CSI driver", content.Name)
+		return nil
+	}
+	if timedOut, err := ctrl.checkCreationTimeout(content); timedOut || err != nil {
+		return err
+	}
 	if content.Spec.Source.VolumeHandle != nil && content.Status == nil {
 		klog.V(5).Infof("syncContent: Call CreateNfsExport for content %s", content.Name)
 		return ctrl.createNfsExport(content)
@@ -82,8 +101,15 @@ func (ctrl *csiNfsExportSideCarController) syncContent(content *crdv1.VolumeNfsE
 	var err error
 	if content.Status != nil && content.Status.ReadyToUse != nil && *content.Status.ReadyToUse == true {
 		// Try to remove AnnVolumeNfsExportBeingCreated if it is not removed yet for some reason
-		_, err = ctrl.removeAnnVolumeNfsExportBeingCreated(content)
-		return err
+		content, err = ctrl.removeAnnVolumeNfsExportBeingCreated(content)
+		if err != nil {
+			return err
+		}
+		content, err = ctrl.checkKMSKeyRotation(content)
+		if err != nil {
+			return err
+		}
+		return ctrl.updateContentObservedGeneration(content)
 	}
 	return ctrl.checkandUpdateContentStatus(content)
 }
@@ -147,40 +173,25 @@ func (ctrl *csiNfsExportSideCarController) updateContentErrorStatusWithEvent(con
 		return nil
 	}
 
-	var patches []utils.PatchOp
 	ready := false
+	now := metav1.NewTime(ctrl.clock.Now())
 	contentStatusError := &crdv1.VolumeNfsExportError{
-		Time: &metav1.Time{
-			Time: time.Now(),
-		},
+		Time:    &now,
 		Message: &message,
 	}
-	if content.Status == nil {
-		// Initialize status if nil
-		patches = append(patches, utils.PatchOp{
-			Op:   "replace",
-			Path: "/status",
-			Value: &crdv1.VolumeNfsExportContentStatus{
-				ReadyToUse: &ready,
-				Error:      contentStatusError,
-			},
-		})
-	} else {
-		// Patch status if non-nil
-		patches = append(patches, utils.PatchOp{
-			Op:    "replace",
-			Path:  "/status/error",
-			Value: contentStatusError,
-		})
-		patches = append(patches, utils.PatchOp{
-			Op:    "replace",
-			Path:  "/status/readyToUse",
-			Value: &ready,
-		})
-
+	newStatus := &crdv1.VolumeNfsExportContentStatus{}
+	if content.Status != nil {
+		newStatus = content.Status.DeepCopy()
 	}
+	if newStatus.ReadyToUse == nil || *newStatus.ReadyToUse {
+		newStatus.LastTransitionTime = &now
+	}
+	newStatus.Error = contentStatusError
+	newStatus.ReadyToUse = &ready
+	newStatus.ErrorHistory = appendContentErrorHistory(newStatus.ErrorHistory, *contentStatusError)
+	ctrl.markContentStatusSynced(newStatus, content)
 
-	newContent, err := utils.PatchVolumeNfsExportContent(content, patches, ctrl.clientset, "status")
+	newContent, err := utils.ApplyVolumeNfsExportContentStatus(content, newStatus, ctrl.statusClientset, utils.SidecarFieldManager)
 
 	// Emit the event even if the status update fails so that user can see the error
 	ctrl.eventRecorder.Event(newContent, eventtype, reason, message)
@@ -199,6 +210,19 @@ func (ctrl *csiNfsExportSideCarController) updateContentErrorStatusWithEvent(con
 	return nil
 }
 
+// appendContentErrorHistory returns a copy of history with err appended,
+// dropping the oldest entries so that at most maxContentErrorHistory remain.
+func appendContentErrorHistory(history []crdv1.VolumeNfsExportError, err crdv1.VolumeNfsExportError) []crdv1.VolumeNfsExportError {
+	if len(history) >= maxContentErrorHistory {
+		history = history[len(history)-maxContentErrorHistory+1:]
+	}
+	newHistory := make([]crdv1.VolumeNfsExportError, 0, len(history)+1)
+	for i := range history {
+		newHistory = append(newHistory, *history[i].DeepCopy())
+	}
+	return append(newHistory, *err.DeepCopy())
+}
+
 func (ctrl *csiNfsExportSideCarController) getCSINfsExportInput(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportClass, map[string]string, error) {
 	className := content.Spec.VolumeNfsExportClassName
 	klog.V(5).Infof("getCSINfsExportInput for content [%s]", content.Name)
@@ -237,6 +261,15 @@ func (ctrl *csiNfsExportSideCarController) checkandUpdateContentStatusOperation(
 	var driverName string
 	var nfsexportID string
 	var nfsexporterListCredentials map[string]string
+	var zone string
+
+	if content.Spec.Source.NfsExportHandle != nil && content.Spec.VolumeNfsExportClassName == nil && ctrl.staticContentsReady {
+		// The driver may have no RPC to get the status of nfsexports, so
+		// the pre-provisioned nfsexport is assumed to be ready as it is, and
+		// to have been created with its content.
+		klog.V(5).Infof("checkandUpdateContentStatusOperation: marking pre-provisioned content [%s] without class ready to use", content.Name)
+		return ctrl.updateNfsExportContentStatus(content, *content.Spec.Source.NfsExportHandle, true, content.CreationTimestamp.UnixNano(), 0, "", false, nil, nil)
+	}
 
 	if content.Spec.Source.NfsExportHandle != nil {
 		klog.V(5).Infof("checkandUpdateContentStatusOperation: call GetNfsExportStatus for nfsexport which is pre-bound to content [%s]", content.Name)
@@ -248,18 +281,11 @@ func (ctrl *csiNfsExportSideCarController) checkandUpdateContentStatusOperation(
 				return content, fmt.Errorf("failed to get nfsexport class %s for nfsexport content %s: %v", *content.Spec.VolumeNfsExportClassName, content.Name, err)
 			}
 
-			nfsexporterListSecretRef, err := utils.GetSecretReference(utils.NfsExportterListSecretParams, class.Parameters, content.GetObjectMeta().GetName(), nil)
-			if err != nil {
-				klog.Errorf("Failed to get secret reference for nfsexport content %s: %v", content.Name, err)
-				return content, fmt.Errorf("failed to get secret reference for nfsexport content %s: %v", content.Name, err)
-			}
-
-			nfsexporterListCredentials, err = utils.GetCredentials(ctrl.client, nfsexporterListSecretRef)
+			nfsexporterListCredentials, err = ctrl.getNfsExportterListCredentials(class, content)
 			if err != nil {
-				// Continue with deletion, as the secret may have already been deleted.
-				klog.Errorf("Failed to get credentials for nfsexport content %s: %v", content.Name, err)
-				return content, fmt.Errorf("failed to get credentials for nfsexport content %s: %v", content.Name, err)
+				return content, err
 			}
+			zone = class.Parameters[utils.PrefixedExportZoneKey]
 		}
 
 		readyToUse, creationTime, size, err = ctrl.handler.GetNfsExportStatus(content, nfsexporterListCredentials)
@@ -273,10 +299,11 @@ func (ctrl *csiNfsExportSideCarController) checkandUpdateContentStatusOperation(
 		klog.V(5).Infof("checkandUpdateContentStatusOperation: driver %s, nfsexportId %s, creationTime %v, size %d, readyToUse %t", driverName, nfsexportID, creationTime, size, readyToUse)
 
 		if creationTime.IsZero() {
-			creationTime = time.Now()
+			creationTime = ctrl.clock.Now()
 		}
 
-		updatedContent, err := ctrl.updateNfsExportContentStatus(content, nfsexportID, readyToUse, creationTime.UnixNano(), size)
+		attributes := ctrl.getNfsExportAttributes(content, nfsexportID, nfsexporterListCredentials)
+		updatedContent, err := ctrl.updateNfsExportContentStatus(content, nfsexportID, readyToUse, creationTime.UnixNano(), size, zone, false, attributes, nil)
 		if err != nil {
 			return content, err
 		}
@@ -293,6 +320,43 @@ func (ctrl *csiNfsExportSideCarController) createNfsExportWrapper(content *crdv1
 	if err != nil {
 		return content, fmt.Errorf("failed to get input parameters to create nfsexport for content %s: %q", content.Name, err)
 	}
+	if content.Spec.ExportPathHint != nil {
+		if err := utils.ValidateExportPathHint(*content.Spec.ExportPathHint, class.Parameters); err != nil {
+			return content, fmt.Errorf("failed to validate export path hint of content %s: %v", content.Name, err)
+		}
+	}
+	// The default Live mode must be supported by the class too.
+	if err := utils.ValidateExportMode(utils.GetExportMode(content.Spec.Mode), class.Parameters); err != nil {
+		return content, fmt.Errorf("failed to validate export mode of content %s: %v", content.Name, err)
+	}
+	if content.Spec.CacheTier != nil {
+		if err := utils.ValidateExportCacheTier(*content.Spec.CacheTier, class.Parameters); err != nil {
+			return content, fmt.Errorf("failed to validate cache tier of content %s: %v", content.Name, err)
+		}
+		supported, err := ctrl.handler.SupportsExportCacheTiers()
+		if err != nil {
+			return content, fmt.Errorf("failed to validate cache tier of content %s: %v", content.Name, err)
+		}
+		if !supported {
+			return content, fmt.Errorf("failed to validate cache tier of content %s: the CSI driver does not support export cache tiers", content.Name)
+		}
+	}
+	keySecret, err := utils.GetKMSKeySecret(ctrl.client, ctrl.secretLister, class)
+	if err != nil {
+		return content, fmt.Errorf("failed to get KMS key of content %s: %v", content.Name, err)
+	}
+	exportParameters, err := params.ExportParameters(content, class, keySecret)
+	if err != nil {
+		return content, fmt.Errorf("failed to get export parameters of content %s: %v", content.Name, err)
+	}
+	parameters, err := params.Layers{
+		Flags:  params.FlagParameters(content, ctrl.extraCreateMetadata),
+		Class:  class.Parameters,
+		Export: exportParameters,
+	}.Effective()
+	if err != nil {
+		return content, fmt.Errorf("failed to get the parameters of content %s passed to the CSI driver: %v", content.Name, err)
+	}
 
 	// NOTE(xyang): handle create timeout
 	// Add an annotation to indicate the nfsexport creation request has been
@@ -306,17 +370,23 @@ func (ctrl *csiNfsExportSideCarController) createNfsExportWrapper(content *crdv1
 		return content, fmt.Errorf("failed to add VolumeNfsExportBeingCreated annotation on the content %s: %q", content.Name, err)
 	}
 
-	parameters, err := utils.RemovePrefixedParameters(class.Parameters)
-	if err != nil {
-		return content, fmt.Errorf("failed to remove CSI Parameters of prefixed keys: %v", err)
+	var driverName, nfsexportID string
+	var creationTime time.Time
+	var size int64
+	var readyToUse bool
+	var sourceVolumes []crdv1.NfsExportSourceVolume
+	var warnings []string
+	if len(content.Spec.Source.VolumeHandles) > 0 {
+		driverName, nfsexportID, creationTime, size, readyToUse, sourceVolumes, warnings, err = ctrl.handler.CreateMultiSourceNfsExport(content, parameters, utils.WithKMSKeyCredentials(nfsexporterCredentials, keySecret))
+	} else {
+		driverName, nfsexportID, creationTime, size, readyToUse, warnings, err = ctrl.handler.CreateNfsExport(content, parameters, utils.WithKMSKeyCredentials(nfsexporterCredentials, keySecret))
 	}
-	if ctrl.extraCreateMetadata {
-		parameters[utils.PrefixedVolumeNfsExportNameKey] = content.Spec.VolumeNfsExportRef.Name
-		parameters[utils.PrefixedVolumeNfsExportNamespaceKey] = content.Spec.VolumeNfsExportRef.Namespace
-		parameters[utils.PrefixedVolumeNfsExportContentNameKey] = content.Name
+	if err != nil && nfsexportID != "" && isAlreadyExistsError(err) {
+		readyToUse, creationTime, size, err = ctrl.adoptExistingNfsExport(content, class, nfsexportID)
+		if driverName == "" {
+			driverName = content.Spec.Driver
+		}
 	}
-
-	driverName, nfsexportID, creationTime, size, readyToUse, err := ctrl.handler.CreateNfsExport(content, parameters, nfsexporterCredentials)
 	if err != nil {
 		// NOTE(xyang): handle create timeout
 		// If it is a final error, remove annotation to indicate
@@ -334,17 +404,38 @@ func (ctrl *csiNfsExportSideCarController) createNfsExportWrapper(content *crdv1
 
 	klog.V(5).Infof("Created nfsexport: driver %s, nfsexportId %s, creationTime %v, size %d, readyToUse %t", driverName, nfsexportID, creationTime, size, readyToUse)
 
+	// Warnings of the driver do not fail the creation, they are only
+	// surfaced to the users, on the content and on the VolumeNfsExport
+	// they look at.
+	nfsexportRef := content.Spec.VolumeNfsExportRef
+	for _, warning := range warnings {
+		klog.Warningf("createNfsExportWrapper: CreateNfsExport for content %s returned warning: %s", content.Name, warning)
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportCreationWarning", warning)
+		ctrl.eventRecorder.Event(&nfsexportRef, v1.EventTypeWarning, "NfsExportCreationWarning", warning)
+	}
+
 	if creationTime.IsZero() {
-		creationTime = time.Now()
+		creationTime = ctrl.clock.Now()
 	}
 
-	newContent, err := ctrl.updateNfsExportContentStatus(content, nfsexportID, readyToUse, creationTime.UnixNano(), size)
+	attributes := ctrl.getNfsExportAttributes(content, nfsexportID, nfsexporterCredentials)
+	ctrl.checkExportCacheTier(content, attributes)
+	// The warm-up was requested from the driver by ClassExportParameters.
+	warmUp := parameters[utils.PrefixedExportWarmUpKey] == "true"
+	newContent, err := ctrl.updateNfsExportContentStatus(content, nfsexportID, readyToUse, creationTime.UnixNano(), size, class.Parameters[utils.PrefixedExportZoneKey], warmUp, attributes, sourceVolumes)
 	if err != nil {
 		klog.Errorf("error updating status for volume nfsexport content %s: %v.", content.Name, err)
 		return content, fmt.Errorf("error updating status for volume nfsexport content %s: %v", content.Name, err)
 	}
 	content = newContent
 
+	if keySecret != nil {
+		content, err = ctrl.setContentAnnotation(content, utils.AnnExportKMSKeyVersion, utils.GetKMSKeyVersion(keySecret))
+		if err != nil {
+			return content, fmt.Errorf("failed to record the KMS key version of content %s: %v", content.Name, err)
+		}
+	}
+
 	// NOTE(xyang): handle create timeout
 	// Remove annotation to indicate storage system has successfully
 	// cut the nfsexport
@@ -353,20 +444,82 @@ func (ctrl *csiNfsExportSideCarController) createNfsExportWrapper(content *crdv1
 		return content, fmt.Errorf("failed to remove VolumeNfsExportBeingCreated annotation on the content %s: %q", content.Name, err)
 	}
 
+	if content.Status != nil {
+		if cond := meta.FindStatusCondition(content.Status.Conditions, crdv1.ConditionWarming); cond != nil && cond.Reason == crdv1.WarmingReasonTimedOut {
+			return content, fmt.Errorf("nfsexport %s is not ready: %s", nfsexportID, cond.Message)
+		}
+	}
+
 	return content, nil
 }
 
+// adoptExistingNfsExport handles a CreateNfsExport call that failed with
+// ALREADY_EXISTS and returned the handle of the existing nfsexport on the
+// backend. Like external-provisioner does for volumes, the existing nfsexport
+// is adopted and its status is used as the result of the create call. If the
+// status cannot be retrieved, the nfsexport is recorded as not ready and its
+// readiness is polled on the next sync.
+func (ctrl *csiNfsExportSideCarController) adoptExistingNfsExport(content *crdv1.VolumeNfsExportContent, class *crdv1.VolumeNfsExportClass, nfsexportID string) (bool, time.Time, int64, error) {
+	klog.Infof("adoptExistingNfsExport: nfsexport %s already exists for content %s, adopting it", nfsexportID, content.Name)
+	ctrl.eventRecorder.Event(content, v1.EventTypeNormal, "NfsExportAdopted", fmt.Sprintf("Adopted existing nfsexport %s", nfsexportID))
+
+	nfsexporterListCredentials, err := ctrl.getNfsExportterListCredentials(class, content)
+	if err != nil {
+		return false, time.Time{}, 0, err
+	}
+	contentCopy := content.DeepCopy()
+	if contentCopy.Status == nil {
+		contentCopy.Status = &crdv1.VolumeNfsExportContentStatus{}
+	}
+	contentCopy.Status.NfsExportHandle = &nfsexportID
+	readyToUse, creationTime, size, err := ctrl.handler.GetNfsExportStatus(contentCopy, nfsexporterListCredentials)
+	if err != nil {
+		klog.Warningf("adoptExistingNfsExport: failed to get status of adopted nfsexport %s for content %s: %v", nfsexportID, content.Name, err)
+		return false, time.Time{}, 0, nil
+	}
+	return readyToUse, creationTime, size, nil
+}
+
+// getNfsExportterListCredentials resolves the credentials to list nfsexports
+// of the given class.
+func (ctrl *csiNfsExportSideCarController) getNfsExportterListCredentials(class *crdv1.VolumeNfsExportClass, content *crdv1.VolumeNfsExportContent) (map[string]string, error) {
+	nfsexporterListSecretRef, err := utils.GetSecretReference(utils.NfsExportterListSecretParams, class.Parameters, content.GetObjectMeta().GetName(), nil)
+	if err != nil {
+		klog.Errorf("Failed to get secret reference for nfsexport content %s: %v", content.Name, err)
+		return nil, fmt.Errorf("failed to get secret reference for nfsexport content %s: %v", content.Name, err)
+	}
+
+	nfsexporterListCredentials, err := utils.GetCredentials(ctrl.client, nfsexporterListSecretRef)
+	if err != nil {
+		// Continue with deletion, as the secret may have already been deleted.
+		klog.Errorf("Failed to get credentials for nfsexport content %s: %v", content.Name, err)
+		return nil, fmt.Errorf("failed to get credentials for nfsexport content %s: %v", content.Name, err)
+	}
+	return nfsexporterListCredentials, nil
+}
+
 // Delete a nfsexport: Ask the backend to remove the nfsexport device
 func (ctrl *csiNfsExportSideCarController) deleteCSINfsExportOperation(content *crdv1.VolumeNfsExportContent) error {
 	klog.V(5).Infof("deleteCSINfsExportOperation [%s] started", content.Name)
 
 	nfsexporterCredentials, err := ctrl.GetCredentialsFromAnnotation(content)
 	if err != nil {
-		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportDeleteError", "Failed to get nfsexport credentials")
+		var forbiddenErr *secretForbiddenError
+		if errors.As(err, &forbiddenErr) {
+			if updateErr := ctrl.updateContentPermissionDenied(content, forbiddenErr.ref); updateErr != nil {
+				return updateErr
+			}
+		} else {
+			ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportDeleteError", "Failed to get nfsexport credentials")
+		}
 		return fmt.Errorf("failed to get input parameters to delete nfsexport for content %s: %q", content.Name, err)
 	}
 
-	err = ctrl.handler.DeleteNfsExport(content, nfsexporterCredentials)
+	if ctrl.deleteBatcher != nil {
+		err = ctrl.deleteBatcher.DeleteNfsExport(content, nfsexporterCredentials)
+	} else {
+		err = ctrl.handler.DeleteNfsExport(content, nfsexporterCredentials)
+	}
 	if err != nil {
 		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportDeleteError", "Failed to delete nfsexport")
 		return fmt.Errorf("failed to delete nfsexport %#v, err: %v", content.Name, err)
@@ -395,14 +548,20 @@ func (ctrl *csiNfsExportSideCarController) clearVolumeContentStatus(
 		return nil, fmt.Errorf("error get nfsexport content %s from api server: %v", contentName, err)
 	}
 	if content.Status != nil {
+		if content.Status.ReadyToUse != nil {
+			now := metav1.NewTime(ctrl.clock.Now())
+			content.Status.LastTransitionTime = &now
+		}
 		content.Status.NfsExportHandle = nil
 		content.Status.ReadyToUse = nil
 		content.Status.CreationTime = nil
 		content.Status.RestoreSize = nil
+		meta.RemoveStatusCondition(&content.Status.Conditions, crdv1.ConditionPermissionDenied)
+		ctrl.markContentStatusSynced(content.Status, content)
 	}
-	newContent, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().UpdateStatus(context.TODO(), content, metav1.UpdateOptions{})
+	newContent, err := utils.ApplyVolumeNfsExportContentStatus(content, content.Status, ctrl.statusClientset, utils.SidecarFieldManager)
 	if err != nil {
-		return content, newControllerUpdateError(contentName, err.Error())
+		return content, utils.NewControllerUpdateError(contentName, err)
 	}
 	return newContent, nil
 }
@@ -412,22 +571,45 @@ func (ctrl *csiNfsExportSideCarController) updateNfsExportContentStatus(
 	nfsexportHandle string,
 	readyToUse bool,
 	createdAt int64,
-	size int64) (*crdv1.VolumeNfsExportContent, error) {
-	klog.V(5).Infof("updateNfsExportContentStatus: updating VolumeNfsExportContent [%s], nfsexportHandle %s, readyToUse %v, createdAt %v, size %d", content.Name, nfsexportHandle, readyToUse, createdAt, size)
+	size int64,
+	zone string,
+	warmUp bool,
+	attributes map[string]string,
+	sourceVolumes []crdv1.NfsExportSourceVolume) (*crdv1.VolumeNfsExportContent, error) {
+	klog.V(5).Infof("updateNfsExportContentStatus: updating VolumeNfsExportContent [%s], nfsexportHandle %s, readyToUse %v, createdAt %v, size %d, zone %q", content.Name, nfsexportHandle, readyToUse, createdAt, size, zone)
 
 	contentObj, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("error get nfsexport content %s from api server: %v", content.Name, err)
 	}
 
+	var exportPath *string
+	if path := utils.GetExportPathFromHandle(nfsexportHandle); path != "" {
+		exportPath = &path
+	}
+	var exportZone *string
+	if zone != "" {
+		exportZone = &zone
+	}
+
 	var newStatus *crdv1.VolumeNfsExportContentStatus
 	updated := false
+	now := metav1.NewTime(ctrl.clock.Now())
 	if contentObj.Status == nil {
 		newStatus = &crdv1.VolumeNfsExportContentStatus{
-			NfsExportHandle: &nfsexportHandle,
-			ReadyToUse:     &readyToUse,
-			CreationTime:   &createdAt,
-			RestoreSize:    &size,
+			NfsExportHandle:    &nfsexportHandle,
+			ReadyToUse:         &readyToUse,
+			CreationTime:       &createdAt,
+			RestoreSize:        &size,
+			LastTransitionTime: &now,
+			ExportPath:         exportPath,
+			Zone:               exportZone,
+		}
+		if len(attributes) > 0 {
+			newStatus.Attributes = attributes
+		}
+		if len(sourceVolumes) > 0 {
+			newStatus.SourceVolumes = sourceVolumes
 		}
 		updated = true
 	} else {
@@ -438,6 +620,7 @@ func (ctrl *csiNfsExportSideCarController) updateNfsExportContentStatus(
 		}
 		if newStatus.ReadyToUse == nil || *newStatus.ReadyToUse != readyToUse {
 			newStatus.ReadyToUse = &readyToUse
+			newStatus.LastTransitionTime = &now
 			updated = true
 			if readyToUse && newStatus.Error != nil {
 				newStatus.Error = nil
@@ -451,14 +634,35 @@ func (ctrl *csiNfsExportSideCarController) updateNfsExportContentStatus(
 			newStatus.RestoreSize = &size
 			updated = true
 		}
+		if newStatus.ExportPath == nil && exportPath != nil {
+			newStatus.ExportPath = exportPath
+			updated = true
+		}
+		if newStatus.Zone == nil && exportZone != nil {
+			newStatus.Zone = exportZone
+			updated = true
+		}
+		if len(attributes) > 0 && !reflect.DeepEqual(newStatus.Attributes, attributes) {
+			newStatus.Attributes = attributes
+			updated = true
+		}
+		if len(sourceVolumes) > 0 && !reflect.DeepEqual(newStatus.SourceVolumes, sourceVolumes) {
+			newStatus.SourceVolumes = sourceVolumes
+			updated = true
+		}
+	}
+	if warmUp && ctrl.updateWarmingCondition(newStatus, readyToUse, now) {
+		updated = true
+	}
+	if newStatus.ObservedGeneration != contentObj.Generation {
+		updated = true
 	}
 
 	if updated {
-		contentClone := contentObj.DeepCopy()
-		contentClone.Status = newStatus
-		newContent, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().UpdateStatus(context.TODO(), contentClone, metav1.UpdateOptions{})
+		ctrl.markContentStatusSynced(newStatus, contentObj)
+		newContent, err := utils.ApplyVolumeNfsExportContentStatus(contentObj, newStatus, ctrl.statusClientset, utils.SidecarFieldManager)
 		if err != nil {
-			return contentObj, newControllerUpdateError(content.Name, err.Error())
+			return contentObj, utils.NewControllerUpdateError(content.Name, err)
 		}
 		return newContent, nil
 	}
@@ -466,6 +670,210 @@ func (ctrl *csiNfsExportSideCarController) updateNfsExportContentStatus(
 	return contentObj, nil
 }
 
+// updateContentObservedGeneration records the current generation of a ready
+// content in its status, so that a change of its spec is reported as
+// processed although the CSI driver is not called again.
+func (ctrl *csiNfsExportSideCarController) updateContentObservedGeneration(content *crdv1.VolumeNfsExportContent) error {
+	if content.Status.ObservedGeneration == content.Generation {
+		return nil
+	}
+	newStatus := content.Status.DeepCopy()
+	ctrl.markContentStatusSynced(newStatus, content)
+	newContent, err := utils.ApplyVolumeNfsExportContentStatus(content, newStatus, ctrl.statusClientset, utils.SidecarFieldManager)
+	if err != nil {
+		return utils.NewControllerUpdateError(content.Name, err)
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("updateContentObservedGeneration [%s]: cannot update internal cache: %v", content.Name, err)
+	}
+	return nil
+}
+
+// markContentStatusSynced records in status that it is written now for the
+// current generation of content.
+func (ctrl *csiNfsExportSideCarController) markContentStatusSynced(status *crdv1.VolumeNfsExportContentStatus, content *crdv1.VolumeNfsExportContent) {
+	status.ObservedGeneration = content.Generation
+	status.LastSyncTime = &metav1.Time{Time: ctrl.clock.Now()}
+}
+
+// getNfsExportAttributes returns the attributes the driver reports for the
+// nfsexport of content. The attributes are informational: a failure to get
+// them is logged and does not fail the sync of content.
+func (ctrl *csiNfsExportSideCarController) getNfsExportAttributes(content *crdv1.VolumeNfsExportContent, nfsexportID string, credentials map[string]string) map[string]string {
+	attributes, err := ctrl.handler.GetNfsExportAttributes(nfsexportID, credentials)
+	if err != nil {
+		klog.Warningf("getNfsExportAttributes [%s]: %v", content.Name, err)
+		return nil
+	}
+	return attributes
+}
+
+// checkExportCacheTier reports with an event that the driver did not achieve
+// the cache tier requested for content, according to the
+// csi.storage.k8s.io/export-cache-tier attribute it reports. The export is
+// usable without the cache, so this does not fail its creation. It is only
+// called for drivers supporting cache tiers, which must report the
+// attribute.
+func (ctrl *csiNfsExportSideCarController) checkExportCacheTier(content *crdv1.VolumeNfsExportContent, attributes map[string]string) {
+	if content.Spec.CacheTier == nil {
+		return
+	}
+	achieved, ok := attributes[utils.PrefixedExportCacheTierKey]
+	if ok && achieved == string(*content.Spec.CacheTier) {
+		return
+	}
+	msg := fmt.Sprintf("Requested cache tier %q, the driver achieved %q", *content.Spec.CacheTier, achieved)
+	if !ok {
+		msg = fmt.Sprintf("Requested cache tier %q, the driver did not report the tier it achieved in the %s attribute", *content.Spec.CacheTier, utils.PrefixedExportCacheTierKey)
+	}
+	klog.Warningf("checkExportCacheTier [%s]: %s", content.Name, msg)
+	ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "ExportCacheTierNotAchieved", msg)
+}
+
+// updateWarmingCondition updates the Warming condition of the status of a
+// content whose class requests a warm-up: True while the export is not ready,
+// False once it is ready or once it has been warming for longer than
+// warmUpTimeout. It returns true if the condition changed.
+func (ctrl *csiNfsExportSideCarController) updateWarmingCondition(status *crdv1.VolumeNfsExportContentStatus, readyToUse bool, now metav1.Time) bool {
+	cond := metav1.Condition{
+		Type:               crdv1.ConditionWarming,
+		LastTransitionTime: now,
+	}
+	current := meta.FindStatusCondition(status.Conditions, crdv1.ConditionWarming)
+	switch {
+	case readyToUse:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = crdv1.WarmingReasonCompleted
+		cond.Message = "The export is warmed up and ready to use"
+	case current == nil:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = crdv1.WarmingReasonInProgress
+		cond.Message = "The CSI driver is warming the export up"
+	case current.Status == metav1.ConditionTrue && now.Sub(current.LastTransitionTime.Time) > ctrl.warmUpTimeout:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = crdv1.WarmingReasonTimedOut
+		cond.Message = fmt.Sprintf("The export did not warm up within %v", ctrl.warmUpTimeout)
+	default:
+		return false
+	}
+	if current != nil && current.Status == cond.Status && current.Reason == cond.Reason {
+		return false
+	}
+	meta.SetStatusCondition(&status.Conditions, cond)
+	return true
+}
+
+// isContentFailed returns true if the Failed condition of content is True.
+func isContentFailed(content *crdv1.VolumeNfsExportContent) bool {
+	return content.Status != nil && meta.IsStatusConditionTrue(content.Status.Conditions, crdv1.ConditionFailed)
+}
+
+// checkCreationTimeout marks content as failed if it is not ready to use
+// after the creationTimeout of its class, measured from the creation of the
+// content. It returns true if content timed out.
+func (ctrl *csiNfsExportSideCarController) checkCreationTimeout(content *crdv1.VolumeNfsExportContent) (bool, error) {
+	if content.Spec.VolumeNfsExportClassName == nil {
+		return false, nil
+	}
+	if content.Status != nil && content.Status.ReadyToUse != nil && *content.Status.ReadyToUse {
+		return false, nil
+	}
+	class, err := ctrl.getNfsExportClass(*content.Spec.VolumeNfsExportClassName)
+	if err != nil {
+		// Reported by the sync of the content.
+		return false, nil
+	}
+	if class.CreationTimeout == nil || ctrl.clock.Since(content.CreationTimestamp.Time) < class.CreationTimeout.Duration {
+		return false, nil
+	}
+
+	message := fmt.Sprintf("The export was not ready to use within the creation timeout %v of VolumeNfsExportClass %s", class.CreationTimeout.Duration, class.Name)
+	klog.Warningf("VolumeNfsExportContent[%s]: %s", content.Name, message)
+	contentObj, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
+	if err != nil {
+		return true, fmt.Errorf("error get nfsexport content %s from api server: %v", content.Name, err)
+	}
+	now := metav1.NewTime(ctrl.clock.Now())
+	ready := false
+	newStatus := &crdv1.VolumeNfsExportContentStatus{}
+	if contentObj.Status != nil {
+		newStatus = contentObj.Status.DeepCopy()
+	}
+	if newStatus.ReadyToUse == nil || *newStatus.ReadyToUse {
+		newStatus.LastTransitionTime = &now
+	}
+	newStatus.ReadyToUse = &ready
+	newStatus.Error = &crdv1.VolumeNfsExportError{Time: &now, Message: &message}
+	newStatus.ErrorHistory = appendContentErrorHistory(newStatus.ErrorHistory, *newStatus.Error)
+	meta.SetStatusCondition(&newStatus.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionFailed,
+		Status:             metav1.ConditionTrue,
+		Reason:             crdv1.FailedReasonCreationTimedOut,
+		Message:            message,
+		LastTransitionTime: now,
+	})
+	ctrl.markContentStatusSynced(newStatus, contentObj)
+
+	newContent, err := utils.ApplyVolumeNfsExportContentStatus(contentObj, newStatus, ctrl.statusClientset, utils.SidecarFieldManager)
+	if err != nil {
+		return true, utils.NewControllerUpdateError(content.Name, err)
+	}
+	ctrl.eventRecorder.Event(newContent, v1.EventTypeWarning, "NfsExportCreationTimedOut", message)
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("checkCreationTimeout [%s]: cannot update internal cache: %v", content.Name, err)
+	}
+	return true, nil
+}
+
+// checkKMSKeyRotation updates the nfsexport of a ready, dynamically
+// provisioned content when the KMS key Secret of its class changed since the
+// nfsexport was created or last updated, e.g. because the key was rotated.
+// Changes of the Secret are noticed when the content is resynced. The new
+// version is recorded only once the nfsexport is updated: if the driver cannot
+// update nfsexports, a warning event is emitted on every resync and the
+// nfsexport keeps its key.
+func (ctrl *csiNfsExportSideCarController) checkKMSKeyRotation(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
+	if content.Spec.Source.VolumeHandle == nil || content.Spec.VolumeNfsExportClassName == nil {
+		return content, nil
+	}
+	class, err := ctrl.getNfsExportClass(*content.Spec.VolumeNfsExportClassName)
+	if err != nil {
+		// The key of nfsexports whose class was deleted is not rotated.
+		return content, nil
+	}
+	keySecret, err := utils.GetKMSKeySecret(ctrl.client, ctrl.secretLister, class)
+	if err != nil {
+		return content, fmt.Errorf("failed to get KMS key of content %s: %v", content.Name, err)
+	}
+	if keySecret == nil {
+		return content, nil
+	}
+	version := utils.GetKMSKeyVersion(keySecret)
+	if content.Annotations[utils.AnnExportKMSKeyVersion] == version {
+		return content, nil
+	}
+
+	nfsexporterCredentials, err := ctrl.GetCredentialsFromAnnotation(content)
+	if err != nil {
+		return content, err
+	}
+	parameters := utils.GetExportEncryptionParameters(class, keySecret)
+	err = ctrl.handler.UpdateNfsExport(content, parameters, utils.WithKMSKeyCredentials(nfsexporterCredentials, keySecret))
+	switch {
+	case errors.Is(err, errUpdateNotSupported):
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "KMSKeyRotationNotSupported",
+			fmt.Sprintf("KMS key Secret %s/%s changed but the CSI driver cannot update exports, the export keeps its key", keySecret.Namespace, keySecret.Name))
+		return content, nil
+	case err != nil:
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "KMSKeyRotationFailed", fmt.Sprintf("Failed to update the export with the KMS key: %v", err))
+		return content, err
+	default:
+		ctrl.eventRecorder.Event(content, v1.EventTypeNormal, "KMSKeyRotated",
+			fmt.Sprintf("Updated the export to version %s of KMS key Secret %s/%s", version, keySecret.Namespace, keySecret.Name))
+	}
+	return ctrl.setContentAnnotation(content, utils.AnnExportKMSKeyVersion, version)
+}
+
 // getNfsExportClass is a helper function to get nfsexport class from the class name.
 func (ctrl *csiNfsExportSideCarController) getNfsExportClass(className string) (*crdv1.VolumeNfsExportClass, error) {
 	klog.V(5).Infof("getNfsExportClass: VolumeNfsExportClassName [%s]", className)
@@ -479,20 +887,180 @@ func (ctrl *csiNfsExportSideCarController) getNfsExportClass(className string) (
 	return class, nil
 }
 
-var _ error = controllerUpdateError{}
+// secretForbiddenError is returned when the sidecar is not allowed by its
+// RBAC rules to get the deletion secret of a content.
+type secretForbiddenError struct {
+	contentName string
+	ref         *v1.SecretReference
+	err         error
+}
+
+func (e *secretForbiddenError) Error() string {
+	return fmt.Sprintf("cannot get credentials for nfsexport content %#v: %v", e.contentName, e.err)
+}
 
-type controllerUpdateError struct {
-	message string
+func (e *secretForbiddenError) Unwrap() error {
+	return e.err
 }
 
-func newControllerUpdateError(name, message string) error {
-	return controllerUpdateError{
-		message: fmt.Sprintf("%s %s on API server: %s", controllerUpdateFailMsg, name, message),
+// updateContentPermissionDenied sets the PermissionDenied condition and the
+// error of content, and emits an event naming the deletion secret the
+// sidecar is not allowed to get and the permission it is missing. Nothing is
+// written nor emitted when the condition already reports the secret, so
+// that the retries of the deletion do not flood the content with events.
+func (ctrl *csiNfsExportSideCarController) updateContentPermissionDenied(content *crdv1.VolumeNfsExportContent, ref *v1.SecretReference) error {
+	message := fmt.Sprintf("Permission denied getting deletion secret %s/%s: grant the service account of the nfsexporter sidecar \"get\" on secrets in namespace %s, e.g. with the secrets rule of ClusterRole external-nfsexporter-runner or with a Role in that namespace",
+		ref.Namespace, ref.Name, ref.Namespace)
+	if content.Status != nil {
+		if cond := meta.FindStatusCondition(content.Status.Conditions, crdv1.ConditionPermissionDenied); cond != nil && cond.Status == metav1.ConditionTrue && cond.Message == message {
+			klog.V(4).Infof("updateContentPermissionDenied [%s]: permission denied on secret %s/%s already reported", content.Name, ref.Namespace, ref.Name)
+			return nil
+		}
+	}
+
+	now := metav1.NewTime(ctrl.clock.Now())
+	newStatus := &crdv1.VolumeNfsExportContentStatus{}
+	if content.Status != nil {
+		newStatus = content.Status.DeepCopy()
+	}
+	newStatus.Error = &crdv1.VolumeNfsExportError{Time: &now, Message: &message}
+	newStatus.ErrorHistory = appendContentErrorHistory(newStatus.ErrorHistory, *newStatus.Error)
+	meta.SetStatusCondition(&newStatus.Conditions, metav1.Condition{
+		Type:               crdv1.ConditionPermissionDenied,
+		Status:             metav1.ConditionTrue,
+		Reason:             crdv1.PermissionDeniedReasonDeletionSecretForbidden,
+		Message:            message,
+		LastTransitionTime: now,
+	})
+	ctrl.markContentStatusSynced(newStatus, content)
+
+	newContent, err := utils.ApplyVolumeNfsExportContentStatus(content, newStatus, ctrl.statusClientset, utils.SidecarFieldManager)
+	// Emit the event even if the status update fails so that user can see the error
+	ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportDeleteSecretForbidden", message)
+	if err != nil {
+		klog.V(4).Infof("updateContentPermissionDenied [%s]: updating status failed %v", content.Name, err)
+		return utils.NewControllerUpdateError(content.Name, err)
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("updateContentPermissionDenied [%s]: cannot update internal cache: %v", content.Name, err)
 	}
+	return nil
+}
+
+// checkContentInUse returns true and an error, so that the deletion is
+// retried with backoff, when a PVC is still being restored from the
+// VolumeNfsExport content is bound to. The common controller keeps the
+// VolumeNfsExport while a PVC is restored from it, but the content can get
+// deleted right after it, under a provisioning PVC.
+func (ctrl *csiNfsExportSideCarController) checkContentInUse(content *crdv1.VolumeNfsExportContent) (bool, error) {
+	claimName, err := ctrl.getClaimRestoredFromContent(content)
+	if err != nil {
+		return true, fmt.Errorf("failed to check whether a PVC is restored from content %s: %v", content.Name, err)
+	}
+	if err := ctrl.updateContentInUse(content, claimName); err != nil {
+		return true, err
+	}
+	if claimName != "" {
+		return true, fmt.Errorf("PVC %s/%s is being restored from content %s", content.Spec.VolumeNfsExportRef.Namespace, claimName, content.Name)
+	}
+	return false, nil
 }
 
-func (e controllerUpdateError) Error() string {
-	return e.message
+// getClaimRestoredFromContent returns the name of a pending PVC whose
+// dataSourceRef or dataSource is the VolumeNfsExport content is bound to, or
+// an empty string if there is none or the VolumeNfsExport no longer exists.
+// Only the namespace of the VolumeNfsExport is checked. Without a claim
+// informer, see SetClaimInformer, restores cannot be detected and an empty
+// string is returned.
+func (ctrl *csiNfsExportSideCarController) getClaimRestoredFromContent(content *crdv1.VolumeNfsExportContent) (string, error) {
+	ref := content.Spec.VolumeNfsExportRef
+	if ctrl.pvcIndexer == nil || ref.Name == "" || ref.Namespace == "" {
+		return "", nil
+	}
+	objs, err := ctrl.pvcIndexer.ByIndex(utils.ClaimSourceNfsExportIndex, ref.Namespace+"/"+ref.Name)
+	if err != nil {
+		return "", err
+	}
+	claimName := ""
+	for _, obj := range objs {
+		claim, ok := obj.(*v1.PersistentVolumeClaim)
+		if ok && claim.Status.Phase == v1.ClaimPending && utils.ClaimSourceNfsExportName(claim) == ref.Name {
+			claimName = claim.Name
+			break
+		}
+	}
+	if claimName == "" {
+		klog.V(5).Infof("getClaimRestoredFromContent [%s]: no PVC is being restored from nfsexport %s/%s", content.Name, ref.Namespace, ref.Name)
+		return "", nil
+	}
+
+	// A PVC restored from a VolumeNfsExport recreated under the same name is
+	// restored from another content.
+	nfsexport, err := ctrl.clientset.NfsExportV1().VolumeNfsExports(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	switch {
+	case apierrs.IsNotFound(err):
+		klog.V(5).Infof("getClaimRestoredFromContent [%s]: nfsexport %s/%s of PVC %s/%s no longer exists", content.Name, ref.Namespace, ref.Name, ref.Namespace, claimName)
+		return "", nil
+	case err != nil:
+		return "", err
+	case ref.UID != "" && nfsexport.UID != ref.UID:
+		klog.V(5).Infof("getClaimRestoredFromContent [%s]: PVC %s/%s is restored from another nfsexport %s/%s", content.Name, ref.Namespace, claimName, ref.Namespace, ref.Name)
+		return "", nil
+	}
+	klog.V(4).Infof("getClaimRestoredFromContent [%s]: PVC %s/%s is being restored from nfsexport %s/%s", content.Name, ref.Namespace, claimName, ref.Namespace, ref.Name)
+	return claimName, nil
+}
+
+// updateContentInUse sets the ContentInUse condition of content to "True"
+// and emits an event when claimName is being restored from it, and to
+// "False" when claimName is empty and the condition was "True". Nothing is
+// written nor emitted when the condition already reports it.
+func (ctrl *csiNfsExportSideCarController) updateContentInUse(content *crdv1.VolumeNfsExportContent, claimName string) error {
+	ref := content.Spec.VolumeNfsExportRef
+	condition := metav1.Condition{
+		Type:    crdv1.ConditionContentInUse,
+		Status:  metav1.ConditionFalse,
+		Reason:  crdv1.ContentInUseReasonResolved,
+		Message: fmt.Sprintf("No PVC is being restored from VolumeNfsExport %s/%s", ref.Namespace, ref.Name),
+	}
+	if claimName != "" {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = crdv1.ContentInUseReasonRestoreInProgress
+		condition.Message = fmt.Sprintf("PVC %s/%s is being restored from VolumeNfsExport %s/%s, the export is deleted once the PVC is bound", ref.Namespace, claimName, ref.Namespace, ref.Name)
+	}
+	var cond *metav1.Condition
+	if content.Status != nil {
+		cond = meta.FindStatusCondition(content.Status.Conditions, crdv1.ConditionContentInUse)
+	}
+	if claimName == "" && (cond == nil || cond.Status != metav1.ConditionTrue) {
+		return nil
+	}
+	if cond != nil && cond.Status == condition.Status && cond.Message == condition.Message {
+		klog.V(4).Infof("updateContentInUse [%s]: condition %s already reported", content.Name, crdv1.ConditionContentInUse)
+		return nil
+	}
+
+	condition.LastTransitionTime = metav1.NewTime(ctrl.clock.Now())
+	newStatus := &crdv1.VolumeNfsExportContentStatus{}
+	if content.Status != nil {
+		newStatus = content.Status.DeepCopy()
+	}
+	meta.SetStatusCondition(&newStatus.Conditions, condition)
+	ctrl.markContentStatusSynced(newStatus, content)
+
+	newContent, err := utils.ApplyVolumeNfsExportContentStatus(content, newStatus, ctrl.statusClientset, utils.SidecarFieldManager)
+	if claimName != "" {
+		// Emit the event even if the status update fails so that user can see it
+		ctrl.eventRecorder.Event(content, v1.EventTypeWarning, "NfsExportContentInUse", condition.Message)
+	}
+	if err != nil {
+		klog.V(4).Infof("updateContentInUse [%s]: updating status failed %v", content.Name, err)
+		return utils.NewControllerUpdateError(content.Name, err)
+	}
+	if _, err := ctrl.storeContentUpdate(newContent); err != nil {
+		klog.V(4).Infof("updateContentInUse [%s]: cannot update internal cache: %v", content.Name, err)
+	}
+	return nil
 }
 
 func isControllerUpdateFailError(err *crdv1.VolumeNfsExportError) bool {
@@ -527,6 +1095,9 @@ func (ctrl *csiNfsExportSideCarController) GetCredentialsFromAnnotation(content
 		if err != nil {
 			// Continue with deletion, as the secret may have already been deleted.
 			klog.Errorf("Failed to get credentials for nfsexport %s: %s", content.Name, err.Error())
+			if apierrs.IsForbidden(err) {
+				return nil, &secretForbiddenError{contentName: content.Name, ref: nfsexporterSecretRef, err: err}
+			}
 			return nil, fmt.Errorf("cannot get credentials for nfsexport content %#v", content.Name)
 		}
 	}
@@ -541,12 +1112,9 @@ func (ctrl csiNfsExportSideCarController) removeContentFinalizer(content *crdv1.
 		// the finalizer does not exit, return directly
 		return nil
 	}
-	contentClone := content.DeepCopy()
-	contentClone.ObjectMeta.Finalizers = utils.RemoveString(contentClone.ObjectMeta.Finalizers, utils.VolumeNfsExportContentFinalizer)
-
-	updatedContent, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Update(context.TODO(), contentClone, metav1.UpdateOptions{})
+	updatedContent, err := utils.RemoveVolumeNfsExportContentFinalizers(content, ctrl.clientset, utils.VolumeNfsExportContentFinalizer)
 	if err != nil {
-		return newControllerUpdateError(content.Name, err.Error())
+		return utils.NewControllerUpdateError(content.Name, err)
 	}
 
 	klog.V(5).Infof("Removed protection finalizer from volume nfsexport content %s", updatedContent.Name)
@@ -615,7 +1183,7 @@ func (ctrl *csiNfsExportSideCarController) setAnnVolumeNfsExportBeingCreated(con
 
 	patchedContent, err := utils.PatchVolumeNfsExportContent(content, patches, ctrl.clientset)
 	if err != nil {
-		return content, newControllerUpdateError(content.Name, err.Error())
+		return content, utils.NewControllerUpdateError(content.Name, err)
 	}
 	// update content if update is successful
 	content = patchedContent
@@ -629,6 +1197,33 @@ func (ctrl *csiNfsExportSideCarController) setAnnVolumeNfsExportBeingCreated(con
 	return content, nil
 }
 
+// setContentAnnotation sets an annotation on a content.
+func (ctrl *csiNfsExportSideCarController) setContentAnnotation(content *crdv1.VolumeNfsExportContent, key, value string) (*crdv1.VolumeNfsExportContent, error) {
+	if current, ok := content.Annotations[key]; ok && current == value {
+		return content, nil
+	}
+	klog.V(5).Infof("setContentAnnotation: set annotation [%s:%s] on content [%s].", key, value, content.Name)
+	patchedAnnotations := make(map[string]string)
+	for k, v := range content.GetAnnotations() {
+		patchedAnnotations[k] = v
+	}
+	patchedAnnotations[key] = value
+
+	patches := []utils.PatchOp{{
+		Op:    "replace",
+		Path:  "/metadata/annotations",
+		Value: patchedAnnotations,
+	}}
+	patchedContent, err := utils.PatchVolumeNfsExportContent(content, patches, ctrl.clientset)
+	if err != nil {
+		return content, utils.NewControllerUpdateError(content.Name, err)
+	}
+	if _, err := ctrl.storeContentUpdate(patchedContent); err != nil {
+		klog.V(4).Infof("setContentAnnotation for content [%s]: cannot update internal cache %v", content.Name, err)
+	}
+	return patchedContent, nil
+}
+
 // removeAnnVolumeNfsExportBeingCreated removes the VolumeNfsExportBeingCreated
 // annotation from a content if there exists one.
 func (ctrl csiNfsExportSideCarController) removeAnnVolumeNfsExportBeingCreated(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
@@ -641,7 +1236,7 @@ func (ctrl csiNfsExportSideCarController) removeAnnVolumeNfsExportBeingCreated(c
 
 	updatedContent, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Update(context.TODO(), contentClone, metav1.UpdateOptions{})
 	if err != nil {
-		return content, newControllerUpdateError(content.Name, err.Error())
+		return content, utils.NewControllerUpdateError(content.Name, err)
 	}
 
 	klog.V(5).Infof("Removed VolumeNfsExportBeingCreated annotation from volume nfsexport content %s", content.Name)
@@ -652,6 +1247,12 @@ func (ctrl csiNfsExportSideCarController) removeAnnVolumeNfsExportBeingCreated(c
 	return updatedContent, nil
 }
 
+// isAlreadyExistsError returns true if the error is a gRPC ALREADY_EXISTS error.
+func isAlreadyExistsError(err error) bool {
+	st, ok := status.FromError(err)
+	return ok && st.Code() == codes.AlreadyExists
+}
+
 // This function checks if the error is final
 func isCSIFinalError(err error) bool {
 	// Sources:
@@ -17,6 +17,7 @@ limitations under the License.
 package sidecar_controller
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -24,24 +25,33 @@ import (
 	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
 	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
 	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/audit"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/eventtemplates"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/nfsexporter"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
+	coreinformers "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	klog "k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 )
 
 type csiNfsExportSideCarController struct {
-	clientset           clientset.Interface
+	clientset clientset.Interface
+	// statusClientset is used for status updates, so that they are
+	// throttled separately from the other requests.
+	statusClientset     clientset.Interface
 	client              kubernetes.Interface
 	driverName          string
 	eventRecorder       record.EventRecorder
@@ -52,17 +62,42 @@ type csiNfsExportSideCarController struct {
 	contentListerSynced cache.InformerSynced
 	classLister         storagelisters.VolumeNfsExportClassLister
 	classListerSynced   cache.InformerSynced
+	// pvcIndexer indexes the cached claims by the VolumeNfsExport they are
+	// restored from, see utils.ClaimSourceNfsExportIndex. It is nil if the
+	// claims are not watched.
+	pvcIndexer      cache.Indexer
+	pvcListerSynced cache.InformerSynced
+	// secretLister caches the KMS key Secrets of the classes encrypting
+	// exports at rest. It is nil if the Secrets are not watched, they are
+	// then read from the API server.
+	secretLister       corelisters.SecretLister
+	secretListerSynced cache.InformerSynced
 
 	contentStore cache.Store
 
 	handler Handler
+	// deleteBatcher groups nfsexport deletions into bulk calls, nil if
+	// batching is disabled.
+	deleteBatcher *deleteBatcher
 
 	resyncPeriod time.Duration
+	// warmUpTimeout is how long an export whose class requests a warm-up
+	// may stay warming before the warm-up is reported as timed out.
+	warmUpTimeout time.Duration
+	// staticContentsReady is true if pre-provisioned contents without a
+	// class are marked ready to use without calling the driver.
+	staticContentsReady bool
+
+	// clock is the source of the current time of the controller, e.g. for
+	// status timestamps and the creation and warm-up timeouts. Tests
+	// replace it with a fake clock.
+	clock clock.Clock
 }
 
 // NewCSINfsExportSideCarController returns a new *csiNfsExportSideCarController
 func NewCSINfsExportSideCarController(
 	clientset clientset.Interface,
+	statusClientset clientset.Interface,
 	client kubernetes.Interface,
 	driverName string,
 	volumeNfsExportContentInformer storageinformers.VolumeNfsExportContentInformer,
@@ -74,6 +109,9 @@ func NewCSINfsExportSideCarController(
 	nfsexportNameUUIDLength int,
 	extraCreateMetadata bool,
 	contentRateLimiter workqueue.RateLimiter,
+	deleteBatchSize int,
+	deleteBatchWindow time.Duration,
+	warmUpTimeout time.Duration,
 ) *csiNfsExportSideCarController {
 	broadcaster := record.NewBroadcaster()
 	broadcaster.StartLogging(klog.Infof)
@@ -83,6 +121,7 @@ func NewCSINfsExportSideCarController(
 
 	ctrl := &csiNfsExportSideCarController{
 		clientset:           clientset,
+		statusClientset:     statusClientset,
 		client:              client,
 		driverName:          driverName,
 		eventRecorder:       eventRecorder,
@@ -91,6 +130,11 @@ func NewCSINfsExportSideCarController(
 		contentStore:        cache.NewStore(cache.DeletionHandlingMetaNamespaceKeyFunc),
 		contentQueue:        workqueue.NewNamedRateLimitingQueue(contentRateLimiter, "csi-nfsexporter-content"),
 		extraCreateMetadata: extraCreateMetadata,
+		warmUpTimeout:       warmUpTimeout,
+		clock:               clock.RealClock{},
+	}
+	if deleteBatchSize > 1 {
+		ctrl.deleteBatcher = newDeleteBatcher(ctrl.handler, deleteBatchSize, deleteBatchWindow)
 	}
 
 	volumeNfsExportContentInformer.Informer().AddEventHandlerWithResyncPeriod(
@@ -127,13 +171,70 @@ func NewCSINfsExportSideCarController(
 	return ctrl
 }
 
+// SetCSITimeout changes the timeout of the CSI calls of the controller, e.g.
+// when it is reloaded from a config file.
+func (ctrl *csiNfsExportSideCarController) SetCSITimeout(timeout time.Duration) {
+	if handler, ok := ctrl.handler.(*csiHandler); ok {
+		handler.setTimeout(timeout)
+	}
+}
+
+// SetAuditSink makes the controller record each export operation it issues
+// to the CSI driver to sink. It must be called before Run.
+func (ctrl *csiNfsExportSideCarController) SetAuditSink(sink audit.Sink) {
+	if handler, ok := ctrl.handler.(*csiHandler); ok {
+		handler.auditSink = sink
+	}
+}
+
+// SetStaticContentsReady makes the controller mark pre-provisioned contents
+// without a class ready to use as soon as it syncs them, without getting the
+// status of their nfsexport from the driver. It must be called before Run.
+func (ctrl *csiNfsExportSideCarController) SetStaticContentsReady(ready bool) {
+	ctrl.staticContentsReady = ready
+}
+
+// SetClaimInformer makes the controller keep a content that is being deleted
+// while a PVC in pvcInformer is restored from its VolumeNfsExport, see
+// checkContentInUse. It must be called before Run.
+func (ctrl *csiNfsExportSideCarController) SetClaimInformer(pvcInformer coreinformers.PersistentVolumeClaimInformer) {
+	if err := pvcInformer.Informer().AddIndexers(cache.Indexers{
+		utils.ClaimSourceNfsExportIndex: utils.ClaimSourceNfsExportIndexFunc,
+	}); err != nil {
+		klog.Errorf("failed to add the %s index to the claim informer: %v", utils.ClaimSourceNfsExportIndex, err)
+	}
+	ctrl.pvcIndexer = pvcInformer.Informer().GetIndexer()
+	ctrl.pvcListerSynced = pvcInformer.Informer().HasSynced
+}
+
+// SetSecretInformer makes the controller read the KMS key Secrets of the
+// classes encrypting exports at rest from secretInformer instead of getting
+// them from the API server on every resync. It must be called before Run.
+func (ctrl *csiNfsExportSideCarController) SetSecretInformer(secretInformer coreinformers.SecretInformer) {
+	ctrl.secretLister = secretInformer.Lister()
+	ctrl.secretListerSynced = secretInformer.Informer().HasSynced
+}
+
+// SetEventTemplates makes the controller emit its events with the messages of
+// templates. It must be called before Run.
+func (ctrl *csiNfsExportSideCarController) SetEventTemplates(templates *eventtemplates.Templates) {
+	ctrl.eventRecorder = eventtemplates.NewRecorder(ctrl.eventRecorder, templates)
+}
+
 func (ctrl *csiNfsExportSideCarController) Run(workers int, stopCh <-chan struct{}) {
 	defer ctrl.contentQueue.ShutDown()
 
 	klog.Infof("Starting CSI nfsexporter")
 	defer klog.Infof("Shutting CSI nfsexporter")
 
-	if !cache.WaitForCacheSync(stopCh, ctrl.contentListerSynced, ctrl.classListerSynced) {
+	informersSynced := []cache.InformerSynced{ctrl.contentListerSynced, ctrl.classListerSynced}
+	if ctrl.pvcListerSynced != nil {
+		informersSynced = append(informersSynced, ctrl.pvcListerSynced)
+	}
+	if ctrl.secretListerSynced != nil {
+		informersSynced = append(informersSynced, ctrl.secretListerSynced)
+	}
+	if !cache.WaitForCacheSync(stopCh, informersSynced...) {
 		klog.Errorf("Cannot sync caches")
 		return
 	}
@@ -164,6 +265,26 @@ func (ctrl *csiNfsExportSideCarController) enqueueContentWork(obj interface{}) {
 	}
 }
 
+// EnqueueBeingCreatedContents enqueues the contents of the driver whose
+// nfsexport is being created. The CSI driver forgets the creations in flight
+// when it restarts, call it when the connection to the driver is
+// re-established so that they are retried now rather than on the next
+// resync.
+func (ctrl *csiNfsExportSideCarController) EnqueueBeingCreatedContents() {
+	contents, err := ctrl.contentLister.List(labels.Everything())
+	if err != nil {
+		klog.Errorf("failed to list contents to retry the nfsexports being created: %v", err)
+		return
+	}
+	for _, content := range contents {
+		if content.Spec.Driver != ctrl.driverName || !metav1.HasAnnotation(content.ObjectMeta, utils.AnnVolumeNfsExportBeingCreated) {
+			continue
+		}
+		klog.V(4).Infof("retrying creation of content %s after the CSI driver reconnected", content.Name)
+		ctrl.enqueueContentWork(content)
+	}
+}
+
 // contentWorker processes items from contentQueue. It must run only once,
 // syncContent is not assured to be reentrant.
 func (ctrl *csiNfsExportSideCarController) contentWorker() {
@@ -179,6 +300,14 @@ func (ctrl *csiNfsExportSideCarController) processNextItem() bool {
 	defer ctrl.contentQueue.Done(keyObj)
 
 	if err := ctrl.syncContentByKey(keyObj.(string)); err != nil {
+		if utils.IsTerminalUpdateError(err) {
+			// Retrying right away would fail the same way, until the
+			// content or the RBAC rules of the controller change.
+			ctrl.contentQueue.Forget(keyObj)
+			ctrl.contentQueue.AddAfter(keyObj, utils.TerminalUpdateErrorRetryDelay)
+			klog.Errorf("Failed to sync content %q, will retry in %v: %v", keyObj.(string), utils.TerminalUpdateErrorRetryDelay, err)
+			return true
+		}
 		// Rather than wait for a full resync, re-add the key to the
 		// queue to be processed.
 		ctrl.contentQueue.AddRateLimited(keyObj)
@@ -274,6 +403,15 @@ func (ctrl *csiNfsExportSideCarController) updateContentInInformerCache(content
 		return nil
 	}
 	err = ctrl.syncContent(content)
+	if errors.IsConflict(err) {
+		// The content in the informer cache is stale, retry right away
+		// with its latest version rather than wait for the informer.
+		klog.V(3).Infof("could not sync content %q, retrying with its latest version: %+v", content.Name, err)
+		var latest *crdv1.VolumeNfsExportContent
+		if latest, err = ctrl.getLatestContent(content); err == nil {
+			err = ctrl.syncContent(latest)
+		}
+	}
 	if err != nil {
 		if errors.IsConflict(err) {
 			// Version conflict error happens quite often and the controller
@@ -287,6 +425,19 @@ func (ctrl *csiNfsExportSideCarController) updateContentInInformerCache(content
 	return nil
 }
 
+// getLatestContent gets the latest version of content from the API server and
+// stores it in the controller cache.
+func (ctrl *csiNfsExportSideCarController) getLatestContent(content *crdv1.VolumeNfsExportContent) (*crdv1.VolumeNfsExportContent, error) {
+	latest, err := ctrl.clientset.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), content.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err = ctrl.storeContentUpdate(latest); err != nil {
+		klog.V(4).Infof("getLatestContent [%s]: cannot update internal cache %v", latest.Name, err)
+	}
+	return latest, nil
+}
+
 // deleteContent runs in worker thread and handles "content deleted" event.
 func (ctrl *csiNfsExportSideCarController) deleteContentInCacheStore(content *crdv1.VolumeNfsExportContent) {
 	_ = ctrl.contentStore.Delete(content)
@@ -14,12 +14,20 @@ limitations under the License.
 package sidecar_controller
 
 import (
+	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/audit"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
 var deletionPolicy = crdv1.VolumeNfsExportContentDelete
@@ -140,3 +148,210 @@ func TestShouldDelete(t *testing.T) {
 
 	}
 }
+
+func TestUpdateWarmingCondition(t *testing.T) {
+	now := metav1.Now()
+	warming := func(status metav1.ConditionStatus, reason string, since time.Duration) []metav1.Condition {
+		return []metav1.Condition{{
+			Type:               crdv1.ConditionWarming,
+			Status:             status,
+			Reason:             reason,
+			LastTransitionTime: metav1.NewTime(now.Add(-since)),
+		}}
+	}
+	tests := []struct {
+		name            string
+		conditions      []metav1.Condition
+		readyToUse      bool
+		expectedUpdated bool
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+	}{
+		{
+			name:            "starts warming",
+			expectedUpdated: true,
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  crdv1.WarmingReasonInProgress,
+		},
+		{
+			name:           "still warming",
+			conditions:     warming(metav1.ConditionTrue, crdv1.WarmingReasonInProgress, time.Minute),
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: crdv1.WarmingReasonInProgress,
+		},
+		{
+			name:            "warmed up",
+			conditions:      warming(metav1.ConditionTrue, crdv1.WarmingReasonInProgress, time.Minute),
+			readyToUse:      true,
+			expectedUpdated: true,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  crdv1.WarmingReasonCompleted,
+		},
+		{
+			name:            "timed out",
+			conditions:      warming(metav1.ConditionTrue, crdv1.WarmingReasonInProgress, time.Hour),
+			expectedUpdated: true,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  crdv1.WarmingReasonTimedOut,
+		},
+		{
+			name:           "already timed out",
+			conditions:     warming(metav1.ConditionFalse, crdv1.WarmingReasonTimedOut, time.Hour),
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: crdv1.WarmingReasonTimedOut,
+		},
+		{
+			name:            "ready after timing out",
+			conditions:      warming(metav1.ConditionFalse, crdv1.WarmingReasonTimedOut, time.Hour),
+			readyToUse:      true,
+			expectedUpdated: true,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  crdv1.WarmingReasonCompleted,
+		},
+	}
+
+	ctrl := &csiNfsExportSideCarController{warmUpTimeout: 30 * time.Minute}
+	for _, test := range tests {
+		status := &crdv1.VolumeNfsExportContentStatus{Conditions: test.conditions}
+		updated := ctrl.updateWarmingCondition(status, test.readyToUse, now)
+		if updated != test.expectedUpdated {
+			t.Errorf("%s: expected updated %v, got %v", test.name, test.expectedUpdated, updated)
+		}
+		cond := meta.FindStatusCondition(status.Conditions, crdv1.ConditionWarming)
+		if cond == nil || cond.Status != test.expectedStatus || cond.Reason != test.expectedReason {
+			t.Errorf("%s: expected Warming condition %s/%s, got %+v", test.name, test.expectedStatus, test.expectedReason, cond)
+		}
+	}
+}
+
+func TestEnqueueBeingCreatedContents(t *testing.T) {
+	beingCreated := newContent("content-being-created", "snapuid1", "snap1", "", classGold, "", "pv-handle-1", deletionPolicy, nil, nil, false, nil)
+	beingCreated.Annotations = map[string]string{utils.AnnVolumeNfsExportBeingCreated: "yes"}
+	otherDriver := newContent("content-other-driver", "snapuid3", "snap3", "", classGold, "", "pv-handle-3", deletionPolicy, nil, nil, false, nil)
+	otherDriver.Annotations = map[string]string{utils.AnnVolumeNfsExportBeingCreated: "yes"}
+	otherDriver.Spec.Driver = "other-driver"
+	contents := []*crdv1.VolumeNfsExportContent{
+		beingCreated,
+		newContent("content-ready", "snapuid2", "snap2", "sid2", classGold, "", "pv-handle-2", deletionPolicy, nil, nil, false, nil),
+		otherDriver,
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, content := range contents {
+		if err := indexer.Add(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctrl := &csiNfsExportSideCarController{
+		driverName:    mockDriverName,
+		contentLister: storagelisters.NewVolumeNfsExportContentLister(indexer),
+		contentQueue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test"),
+	}
+	defer ctrl.contentQueue.ShutDown()
+
+	ctrl.EnqueueBeingCreatedContents()
+	if length := ctrl.contentQueue.Len(); length != 1 {
+		t.Fatalf("expected 1 content to be enqueued, got %d", length)
+	}
+	if key, _ := ctrl.contentQueue.Get(); key != "content-being-created" {
+		t.Errorf("expected content-being-created to be enqueued, got %v", key)
+	}
+}
+
+type recordingSink struct {
+	records []audit.Record
+}
+
+func (s *recordingSink) Record(r audit.Record) {
+	s.records = append(s.records, r)
+}
+
+func TestAuditRecords(t *testing.T) {
+	fakeNfsExport := &fakeNfsExportter{
+		t: t,
+		deleteCalls: []deleteCall{
+			{nfsexportID: "sid1", secrets: map[string]string{}},
+			{nfsexportID: "sid2", secrets: map[string]string{}, err: errors.New("mock delete error")},
+		},
+	}
+	sink := &recordingSink{}
+	ctrl := &csiNfsExportSideCarController{handler: NewCSIHandler(fakeNfsExport, time.Minute, "nfsexport", -1)}
+	ctrl.SetAuditSink(sink)
+
+	content1 := newContent("content1", "snapuid1", "snap1", "sid1", classGold, "", "pv-handle-1", deletionPolicy, nil, nil, false, nil)
+	content2 := newContent("content2", "snapuid2", "snap2", "sid2", classGold, "", "pv-handle-2", deletionPolicy, nil, nil, false, nil)
+	metav1.SetMetaDataAnnotation(&content1.ObjectMeta, utils.AnnRequestedBy, "alice")
+	if err := ctrl.handler.DeleteNfsExport(content1, map[string]string{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ctrl.handler.DeleteNfsExport(content2, map[string]string{}); err == nil {
+		t.Fatalf("expected an error")
+	}
+
+	if len(sink.records) != 2 {
+		t.Fatalf("expected 2 audit records, got %+v", sink.records)
+	}
+	for i, expected := range []audit.Record{
+		{Operation: audit.OperationDelete, Driver: mockDriverName, NfsExportHandle: "sid1", VolumeNfsExportContent: "content1", VolumeNfsExport: testNamespace + "/snap1", VolumeNfsExportUID: "snapuid1", User: "alice", Result: audit.ResultSuccess},
+		{Operation: audit.OperationDelete, Driver: mockDriverName, NfsExportHandle: "sid2", VolumeNfsExportContent: "content2", VolumeNfsExport: testNamespace + "/snap2", VolumeNfsExportUID: "snapuid2", Result: audit.ResultFailure, Error: "mock delete error"},
+	} {
+		record := sink.records[i]
+		record.Time = time.Time{}
+		record.DurationSeconds = 0
+		if !reflect.DeepEqual(record, expected) {
+			t.Errorf("expected audit record %+v, got %+v", expected, record)
+		}
+	}
+}
+
+type multiSourceNfsExportter struct {
+	*fakeNfsExportter
+	supported     bool
+	volumeHandles []string
+}
+
+func (f *multiSourceNfsExportter) SupportsMultiSourceNfsExports(ctx context.Context) (bool, error) {
+	return f.supported, nil
+}
+
+func (f *multiSourceNfsExportter) CreateMultiSourceNfsExport(ctx context.Context, nfsexportName string, volumeHandles []string, parameters map[string]string, nfsexporterCredentials map[string]string) (string, string, time.Time, int64, bool, map[string]string, error) {
+	f.volumeHandles = volumeHandles
+	subdirectories := map[string]string{"pv-handle-1": "claim1", "pv-handle-2": "claim2"}
+	return mockDriverName, "sid1", time.Time{}, 0, true, subdirectories, nil
+}
+
+func TestCreateMultiSourceNfsExport(t *testing.T) {
+	content := newContent("content1", "snapuid1", "snap1", "", classGold, "", "pv-handle-1", deletionPolicy, nil, nil, false, nil)
+	content.Spec.Source.VolumeHandles = []string{"pv-handle-2"}
+
+	handler := NewCSIHandler(&fakeNfsExportter{t: t}, time.Minute, "nfsexport", -1)
+	if _, _, _, _, _, _, _, err := handler.CreateMultiSourceNfsExport(content, map[string]string{}, map[string]string{}); err != errMultiSourceNotSupported || !isCSIFinalError(err) {
+		t.Errorf("expected final error %v for a driver without multi-source support, got %v", errMultiSourceNotSupported, err)
+	}
+	fake := &multiSourceNfsExportter{fakeNfsExportter: &fakeNfsExportter{t: t}}
+	handler = NewCSIHandler(fake, time.Minute, "nfsexport", -1)
+	if _, _, _, _, _, _, _, err := handler.CreateMultiSourceNfsExport(content, map[string]string{}, map[string]string{}); err != errMultiSourceNotSupported {
+		t.Errorf("expected error %v for a driver not advertising multi-source support, got %v", errMultiSourceNotSupported, err)
+	}
+	if fake.volumeHandles != nil {
+		t.Errorf("expected the driver not to be called, got volume handles %v", fake.volumeHandles)
+	}
+
+	fake.supported = true
+	_, nfsexportID, _, _, _, sourceVolumes, _, err := handler.CreateMultiSourceNfsExport(content, map[string]string{}, map[string]string{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if nfsexportID != "sid1" {
+		t.Errorf("expected nfsexport sid1, got %q", nfsexportID)
+	}
+	if expected := []string{"pv-handle-1", "pv-handle-2"}; !reflect.DeepEqual(fake.volumeHandles, expected) {
+		t.Errorf("expected volume handles %v, got %v", expected, fake.volumeHandles)
+	}
+	expected := []crdv1.NfsExportSourceVolume{
+		{VolumeHandle: "pv-handle-1", Subdirectory: "claim1"},
+		{VolumeHandle: "pv-handle-2", Subdirectory: "claim2"},
+	}
+	if !reflect.DeepEqual(sourceVolumes, expected) {
+		t.Errorf("expected source volumes %+v, got %+v", expected, sourceVolumes)
+	}
+}
@@ -23,8 +23,10 @@ import (
 	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/fakeapiserver"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -37,6 +39,8 @@ var (
 	timeNowMetav1 = metav1.Now()
 	False         = false
 	True          = true
+
+	stunnelTransport = crdv1.NfsExportTransportStunnel
 )
 
 var class1Parameters = map[string]string{
@@ -69,6 +73,18 @@ var class6Parameters = map[string]string{
 	utils.PrefixedNfsExportterListSecretNamespaceKey: "default",
 }
 
+var class8Parameters = map[string]string{
+	utils.PrefixedExportZoneKey: "zone-a",
+}
+
+var class9Parameters = map[string]string{
+	utils.PrefixedExportWarmUpKey: "true",
+}
+
+var class10Parameters = map[string]string{
+	utils.PrefixedExportCacheTiersKey: "NodeLocal",
+}
+
 var class7Annotations = map[string]string{
 	utils.AnnDeletionSecretRefName:      "secret-x",
 	utils.AnnDeletionSecretRefNamespace: "default-x",
@@ -143,6 +159,66 @@ var nfsexportClasses = []*crdv1.VolumeNfsExportClass{
 		Parameters:     class6Parameters,
 		DeletionPolicy: crdv1.VolumeNfsExportContentDelete,
 	},
+	{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "VolumeNfsExportClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: zoneClass,
+		},
+		Driver:         mockDriverName,
+		Parameters:     class8Parameters,
+		DeletionPolicy: crdv1.VolumeNfsExportContentDelete,
+	},
+	{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "VolumeNfsExportClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: warmUpClass,
+		},
+		Driver:         mockDriverName,
+		Parameters:     class9Parameters,
+		DeletionPolicy: crdv1.VolumeNfsExportContentDelete,
+	},
+	{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "VolumeNfsExportClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: timeoutClass,
+		},
+		Driver:          mockDriverName,
+		DeletionPolicy:  crdv1.VolumeNfsExportContentDelete,
+		CreationTimeout: &metav1.Duration{Duration: time.Hour},
+	},
+	{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "VolumeNfsExportClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: encryptedClass,
+		},
+		Driver:         mockDriverName,
+		DeletionPolicy: crdv1.VolumeNfsExportContentDelete,
+		Encryption: &crdv1.NfsExportEncryption{
+			Transport: &stunnelTransport,
+			AtRest: &crdv1.NfsExportAtRestEncryption{
+				KMSKeySecretRef: v1.SecretReference{Name: "kms-key", Namespace: "default"},
+			},
+		},
+	},
+	{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "VolumeNfsExportClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: cacheTierClass,
+		},
+		Driver:         mockDriverName,
+		Parameters:     class10Parameters,
+		DeletionPolicy: crdv1.VolumeNfsExportContentDelete,
+	},
 }
 
 // Test single call to syncContent, expecting deleting to happen.
@@ -226,10 +302,10 @@ func TestDeleteSync(t *testing.T) {
 			expectedContents:    newContentArrayWithDeletionTimestamp("content1-1", "snapuid1-1", "snap1-1", "sid1-1", "invalid", "", "snap1-4-volumehandle", deletionPolicy, nil, nil, true, &timeNowMetav1),
 			expectedEvents:      noevents,
 			expectedDeleteCalls: []deleteCall{{"sid1-1", nil, fmt.Errorf("mock csi driver delete error")}},
-			errors: []reactorError{
+			errors: []fakeapiserver.Hook{
 				// Inject error to the first client.VolumenfsexportV1().VolumeNfsExportContents().Delete call.
 				// All other calls will succeed.
-				{"get", "secrets", errors.New("mock get invalid secret error")},
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourceSecrets, errors.New("mock get invalid secret error")),
 			},
 			test: testSyncContent,
 		},
@@ -344,6 +420,215 @@ func TestDeleteSync(t *testing.T) {
 			expectedDeleteCalls: []deleteCall{{"sid1-15", nil, nil}},
 			test:                testSyncContent,
 		},
+		{
+			name:              "1-16 - (dynamic)deletion of content with retain policy should remove bound finalizer after a conflict",
+			initialContents:   newContentArrayWithDeletionTimestamp("content1-16", "sid1-16", "snap1-16", "sid1-16", emptySecretClass, "", "snap1-16-volumehandle", retainPolicy, nil, &defaultSize, true, &timeNowMetav1),
+			expectedContents:  newContentArrayWithDeletionTimestamp("content1-16", "sid1-16", "snap1-16", "sid1-16", emptySecretClass, "", "snap1-16-volumehandle", retainPolicy, nil, &defaultSize, false, &timeNowMetav1),
+			expectedEvents:    noevents,
+			expectedListCalls: []listCall{{"sid1-16", map[string]string{}, true, time.Now(), 0, nil}},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContents, conflictError("volumenfsexportcontents", "content1-16")),
+			},
+			initialSecrets: []*v1.Secret{},
+			test:           testSyncContent,
+		},
+		{
+			name:              "1-17 - (dynamic)deletion of content with retain policy should keep bound finalizer after repeated conflicts",
+			initialContents:   newContentArrayWithDeletionTimestamp("content1-17", "sid1-17", "snap1-17", "sid1-17", emptySecretClass, "", "snap1-17-volumehandle", retainPolicy, nil, &defaultSize, true, &timeNowMetav1),
+			expectedContents:  newContentArrayWithDeletionTimestamp("content1-17", "sid1-17", "snap1-17", "sid1-17", emptySecretClass, "", "snap1-17-volumehandle", retainPolicy, nil, &defaultSize, true, &timeNowMetav1),
+			expectedEvents:    noevents,
+			expectedListCalls: []listCall{{"sid1-17", map[string]string{}, true, time.Now(), 0, nil}},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContents, conflictError("volumenfsexportcontents", "content1-17")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContents, conflictError("volumenfsexportcontents", "content1-17")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContents, conflictError("volumenfsexportcontents", "content1-17")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContents, conflictError("volumenfsexportcontents", "content1-17")),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContents, conflictError("volumenfsexportcontents", "content1-17")),
+			},
+			initialSecrets: []*v1.Secret{},
+			test:           testSyncContentError,
+		},
+	}
+	runSyncContentTests(t, tests, nfsexportClasses)
+}
+
+func TestDeleteSecretForbidden(t *testing.T) {
+	forbiddenMessage := `Permission denied getting deletion secret default/secret: grant the service account of the nfsexporter sidecar "get" on secrets in namespace default, e.g. with the secrets rule of ClusterRole external-nfsexporter-runner or with a Role in that namespace`
+	forbiddenErr := apierrs.NewForbidden(v1.Resource("secrets"), "secret", errors.New("no RBAC policy matched"))
+	annotations := secretAnnotations()
+	annotations[utils.AnnVolumeNfsExportBeingDeleted] = "yes"
+	permissionDenied := []metav1.Condition{{
+		Type:    crdv1.ConditionPermissionDenied,
+		Status:  metav1.ConditionTrue,
+		Reason:  crdv1.PermissionDeniedReasonDeletionSecretForbidden,
+		Message: forbiddenMessage,
+	}}
+	nfsexportHandle := "sid2-2"
+
+	tests := []controllerTest{
+		{
+			name: "2-1 - deletion secret forbidden sets the PermissionDenied condition and does not call the driver",
+			initialContents: withContentAnnotations(newContentArrayWithDeletionTimestamp("content2-1", "snapuid2-1", "snap2-1", "sid2-1", emptySecretClass, "", "snap2-1-volumehandle", deletionPolicy, nil, nil, true, &timeNowMetav1),
+				annotations),
+			expectedContents: withContentStatus(withContentAnnotations(newContentArrayWithDeletionTimestamp("content2-1", "snapuid2-1", "snap2-1", "sid2-1", emptySecretClass, "", "snap2-1-volumehandle", deletionPolicy, nil, nil, true, &timeNowMetav1),
+				annotations),
+				&crdv1.VolumeNfsExportContentStatus{
+					NfsExportHandle: toStringPointer("sid2-1"),
+					Error:           newNfsExportError(forbiddenMessage),
+					ErrorHistory:    newNfsExportErrorHistory(forbiddenMessage),
+					Conditions:      permissionDenied,
+				}),
+			expectedEvents: []string{"Warning NfsExportDeleteSecretForbidden"},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourceSecrets, forbiddenErr),
+			},
+			test: testSyncContentError,
+		},
+		{
+			name: "2-2 - deletion secret still forbidden is not reported again",
+			initialContents: withContentStatus(withContentAnnotations(newContentArrayWithDeletionTimestamp("content2-2", "snapuid2-2", "snap2-2", "sid2-2", emptySecretClass, "", "snap2-2-volumehandle", deletionPolicy, nil, nil, true, &timeNowMetav1),
+				annotations),
+				&crdv1.VolumeNfsExportContentStatus{
+					NfsExportHandle: &nfsexportHandle,
+					Conditions:      permissionDenied,
+				}),
+			expectedContents: withContentStatus(withContentAnnotations(newContentArrayWithDeletionTimestamp("content2-2", "snapuid2-2", "snap2-2", "sid2-2", emptySecretClass, "", "snap2-2-volumehandle", deletionPolicy, nil, nil, true, &timeNowMetav1),
+				annotations),
+				&crdv1.VolumeNfsExportContentStatus{
+					NfsExportHandle: &nfsexportHandle,
+					Conditions:      permissionDenied,
+				}),
+			expectedEvents: noevents,
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourceSecrets, forbiddenErr),
+			},
+			test: testSyncContentError,
+		},
+		{
+			name: "2-3 - deletion secret forbidden and status update error",
+			initialContents: withContentAnnotations(newContentArrayWithDeletionTimestamp("content2-3", "snapuid2-3", "snap2-3", "sid2-3", emptySecretClass, "", "snap2-3-volumehandle", deletionPolicy, nil, nil, true, &timeNowMetav1),
+				annotations),
+			expectedContents: withContentAnnotations(newContentArrayWithDeletionTimestamp("content2-3", "snapuid2-3", "snap2-3", "sid2-3", emptySecretClass, "", "snap2-3-volumehandle", deletionPolicy, nil, nil, true, &timeNowMetav1),
+				annotations),
+			expectedEvents: []string{"Warning NfsExportDeleteSecretForbidden"},
+			errors: []fakeapiserver.Hook{
+				fakeapiserver.Error(fakeapiserver.VerbGet, fakeapiserver.ResourceSecrets, forbiddenErr),
+				fakeapiserver.Error(fakeapiserver.VerbPatch, fakeapiserver.ResourceVolumeNfsExportContentsStatus, errors.New("mock update error")),
+			},
+			test: testSyncContentError,
+		},
+	}
+	runSyncContentTests(t, tests, nfsexportClasses)
+}
+
+func newRestoredClaim(name, nfsexportName string, phase v1.PersistentVolumeClaimPhase) *v1.PersistentVolumeClaim {
+	apiGroup := crdv1.GroupName
+	return &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Spec: v1.PersistentVolumeClaimSpec{
+			DataSourceRef: &v1.TypedLocalObjectReference{APIGroup: &apiGroup, Kind: "VolumeNfsExport", Name: nfsexportName},
+		},
+		Status: v1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+func TestDeleteContentInUse(t *testing.T) {
+	inUseMessage := "PVC default/claim3-1 is being restored from VolumeNfsExport default/snap3-1, the export is deleted once the PVC is bound"
+	inUse := []metav1.Condition{{
+		Type:    crdv1.ConditionContentInUse,
+		Status:  metav1.ConditionTrue,
+		Reason:  crdv1.ContentInUseReasonRestoreInProgress,
+		Message: inUseMessage,
+	}}
+	notInUse := []metav1.Condition{{
+		Type:    crdv1.ConditionContentInUse,
+		Status:  metav1.ConditionFalse,
+		Reason:  crdv1.ContentInUseReasonResolved,
+		Message: "No PVC is being restored from VolumeNfsExport default/snap3-3",
+	}}
+	nfsexportHandle := "sid3-3"
+
+	tests := []controllerTest{
+		{
+			name:            "3-1 - content restored to a pending PVC gets the ContentInUse condition and is not deleted",
+			initialContents: newContentArrayWithDeletionTimestamp("content3-1", "snapuid3-1", "snap3-1", "sid3-1", emptySecretClass, "", "snap3-1-volumehandle", deletePolicy, nil, nil, true, &timeNowMetav1),
+			expectedContents: withContentStatus(newContentArrayWithDeletionTimestamp("content3-1", "snapuid3-1", "snap3-1", "sid3-1", emptySecretClass, "", "snap3-1-volumehandle", deletePolicy, nil, nil, true, &timeNowMetav1),
+				&crdv1.VolumeNfsExportContentStatus{
+					NfsExportHandle: toStringPointer("sid3-1"),
+					Conditions:      inUse,
+				}),
+			initialClaims:     []*v1.PersistentVolumeClaim{newRestoredClaim("claim3-1", "snap3-1", v1.ClaimPending)},
+			initialNfsExports: []*crdv1.VolumeNfsExport{{ObjectMeta: metav1.ObjectMeta{Name: "snap3-1", Namespace: testNamespace, UID: "snapuid3-1"}}},
+			expectedEvents:    []string{"Warning NfsExportContentInUse"},
+			errors:            noerrors,
+			test:              testSyncContentError,
+		},
+		{
+			name:                "3-2 - content restored to a bound PVC is deleted",
+			initialContents:     newContentArrayWithDeletionTimestamp("content3-2", "snapuid3-2", "snap3-2", "sid3-2", emptySecretClass, "", "snap3-2-volumehandle", deletePolicy, nil, &defaultSize, true, &timeNowMetav1),
+			expectedContents:    newContentArrayWithDeletionTimestamp("content3-2", "snapuid3-2", "snap3-2", "", emptySecretClass, "", "snap3-2-volumehandle", deletePolicy, nil, nil, false, &timeNowMetav1),
+			initialClaims:       []*v1.PersistentVolumeClaim{newRestoredClaim("claim3-2", "snap3-2", v1.ClaimBound)},
+			expectedEvents:      noevents,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{{"sid3-2", nil, nil}},
+			test:                testSyncContent,
+		},
+		{
+			name: "3-3 - content no longer in use gets the ContentInUse condition cleared and is deleted",
+			initialContents: withContentStatus(newContentArrayWithDeletionTimestamp("content3-3", "snapuid3-3", "snap3-3", "sid3-3", emptySecretClass, "", "snap3-3-volumehandle", deletePolicy, nil, nil, true, &timeNowMetav1),
+				&crdv1.VolumeNfsExportContentStatus{
+					NfsExportHandle: &nfsexportHandle,
+					Conditions: []metav1.Condition{{
+						Type:    crdv1.ConditionContentInUse,
+						Status:  metav1.ConditionTrue,
+						Reason:  crdv1.ContentInUseReasonRestoreInProgress,
+						Message: "PVC default/claim3-3 is being restored from VolumeNfsExport default/snap3-3, the export is deleted once the PVC is bound",
+					}},
+				}),
+			expectedContents: withContentStatus(newContentArrayWithDeletionTimestamp("content3-3", "snapuid3-3", "snap3-3", "", emptySecretClass, "", "snap3-3-volumehandle", deletePolicy, nil, nil, false, &timeNowMetav1),
+				&crdv1.VolumeNfsExportContentStatus{
+					Conditions: notInUse,
+				}),
+			expectedEvents:      noevents,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{{"sid3-3", nil, nil}},
+			test:                testSyncContent,
+		},
+		{
+			name:                "3-4 - content is deleted when the PVC is restored from a recreated nfsexport",
+			initialContents:     newContentArrayWithDeletionTimestamp("content3-4", "snapuid3-4", "snap3-4", "sid3-4", emptySecretClass, "", "snap3-4-volumehandle", deletePolicy, nil, &defaultSize, true, &timeNowMetav1),
+			expectedContents:    newContentArrayWithDeletionTimestamp("content3-4", "snapuid3-4", "snap3-4", "", emptySecretClass, "", "snap3-4-volumehandle", deletePolicy, nil, nil, false, &timeNowMetav1),
+			initialClaims:       []*v1.PersistentVolumeClaim{newRestoredClaim("claim3-4", "snap3-4", v1.ClaimPending)},
+			initialNfsExports:   []*crdv1.VolumeNfsExport{{ObjectMeta: metav1.ObjectMeta{Name: "snap3-4", Namespace: testNamespace, UID: "snapuid3-4-recreated"}}},
+			expectedEvents:      noevents,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{{"sid3-4", nil, nil}},
+			test:                testSyncContent,
+		},
+		{
+			name:                "3-5 - content is deleted when the sidecar does not watch PVCs",
+			initialContents:     newContentArrayWithDeletionTimestamp("content3-5", "snapuid3-5", "snap3-5", "sid3-5", emptySecretClass, "", "snap3-5-volumehandle", deletePolicy, nil, &defaultSize, true, &timeNowMetav1),
+			expectedContents:    newContentArrayWithDeletionTimestamp("content3-5", "snapuid3-5", "snap3-5", "", emptySecretClass, "", "snap3-5-volumehandle", deletePolicy, nil, nil, false, &timeNowMetav1),
+			initialClaims:       []*v1.PersistentVolumeClaim{newRestoredClaim("claim3-5", "snap3-5", v1.ClaimPending)},
+			initialNfsExports:   []*crdv1.VolumeNfsExport{{ObjectMeta: metav1.ObjectMeta{Name: "snap3-5", Namespace: testNamespace, UID: "snapuid3-5"}}},
+			expectedEvents:      noevents,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{{"sid3-5", nil, nil}},
+			test: func(ctrl *csiNfsExportSideCarController, reactor *nfsexportReactor, test controllerTest) error {
+				ctrl.pvcIndexer = nil
+				return testSyncContent(ctrl, reactor, test)
+			},
+		},
+		{
+			name:                "3-6 - content is deleted when the PVC is restored from a deleted nfsexport",
+			initialContents:     newContentArrayWithDeletionTimestamp("content3-6", "snapuid3-6", "snap3-6", "sid3-6", emptySecretClass, "", "snap3-6-volumehandle", deletePolicy, nil, &defaultSize, true, &timeNowMetav1),
+			expectedContents:    newContentArrayWithDeletionTimestamp("content3-6", "snapuid3-6", "snap3-6", "", emptySecretClass, "", "snap3-6-volumehandle", deletePolicy, nil, nil, false, &timeNowMetav1),
+			initialClaims:       []*v1.PersistentVolumeClaim{newRestoredClaim("claim3-6", "snap3-6", v1.ClaimPending)},
+			expectedEvents:      noevents,
+			errors:              noerrors,
+			expectedDeleteCalls: []deleteCall{{"sid3-6", nil, nil}},
+			test:                testSyncContent,
+		},
 	}
 	runSyncContentTests(t, tests, nfsexportClasses)
 }
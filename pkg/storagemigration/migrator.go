@@ -0,0 +1,280 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagemigration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+	klog "k8s.io/klog/v2"
+)
+
+// Design:
+//
+// The API server stores a custom resource in the version that was the
+// storage version of its CRD when the resource was last written, and lists
+// all such versions in status.storedVersions of the CRD. A version can only
+// be removed from a CRD once it is no longer listed there, otherwise the
+// objects still encoded in it become unreadable.
+//
+// The migrator writes every VolumeNfsExportClass, VolumeNfsExportContent and
+// VolumeNfsExport back, which makes the API server encode it in the current
+// storage version. The objects are read and written as JSON through a REST
+// client rather than decoded into the types of this binary, so that fields
+// it does not know about are written back as stored. Only the fields listed
+// in DeprecatedFields are stripped on the way. Once all objects are
+// migrated, status.storedVersions of the CRDs is reduced to the storage
+// version, after which older versions can be removed from the CRDs by an
+// upgrade.
+//
+// The validation webhook admits updates which change neither the spec nor
+// the labels and annotations of an object without validating it, so that
+// objects which do not pass the current validation can be migrated too.
+
+// crdBasePath is the API path of the CustomResourceDefinitions.
+const crdBasePath = "/apis/apiextensions.k8s.io/v1/customresourcedefinitions"
+
+// DeprecatedFields are the paths of the fields stripped from the objects of
+// each resource when they are migrated, by plural name of the resource. A
+// field must be listed here when it is removed from the schema of a CRD
+// version. No field has been removed from any version so far.
+var DeprecatedFields = map[string][][]string{
+	"volumenfsexportclasses":  nil,
+	"volumenfsexportcontents": nil,
+	"volumenfsexports":        nil,
+}
+
+// Progress is the progress of the migration of the objects of a resource.
+type Progress struct {
+	// Resource is the plural name of the resource, e.g. volumenfsexports.
+	Resource string
+	// Migrated objects were written in the storage version, by the
+	// migrator or by someone else while it was running.
+	Migrated int
+	// Skipped objects were deleted while the migrator was running.
+	Skipped int
+	// Failed objects could not be written and remain in the version they
+	// are stored in.
+	Failed int
+}
+
+// resource is a migrated resource.
+type resource struct {
+	// name is the plural name of the resource.
+	name string
+	kind string
+}
+
+// resources are the migrated resources. Classes come first, as contents and
+// nfsexports refer to them.
+var resources = []resource{
+	{name: "volumenfsexportclasses", kind: "VolumeNfsExportClass"},
+	{name: "volumenfsexportcontents", kind: "VolumeNfsExportContent"},
+	{name: "volumenfsexports", kind: "VolumeNfsExport"},
+}
+
+// Migrator rewrites the VolumeNfsExport* objects in the storage version of
+// their CRD.
+type Migrator struct {
+	// client reads and writes the objects as JSON.
+	client rest.Interface
+	// crdClient is used to update the stored versions of the CRDs. If it
+	// is nil, the stored versions are not updated.
+	crdClient rest.Interface
+	pageSize  int64
+	// deprecatedFields are the fields stripped from the objects, see
+	// DeprecatedFields.
+	deprecatedFields map[string][][]string
+	// report is called with the progress of a resource after each page.
+	report func(Progress)
+}
+
+// NewMigrator returns a migrator reading and writing the objects with
+// client, listing pageSize objects at a time and calling report after each
+// page. If crdClient is nil, the stored versions of the CRDs are not updated.
+func NewMigrator(client rest.Interface, crdClient rest.Interface, pageSize int64, report func(Progress)) *Migrator {
+	if report == nil {
+		report = func(Progress) {}
+	}
+	return &Migrator{
+		client:           client,
+		crdClient:        crdClient,
+		pageSize:         pageSize,
+		deprecatedFields: DeprecatedFields,
+		report:           report,
+	}
+}
+
+// Run migrates the objects of all resources, and then updates the stored
+// versions of their CRDs if no object failed. It returns the progress of
+// each resource, and an error if an object failed or the migration could not
+// complete.
+func (m *Migrator) Run(ctx context.Context) ([]Progress, error) {
+	var all []Progress
+	failed := 0
+	for _, r := range resources {
+		progress, err := m.migrateResource(ctx, r)
+		all = append(all, progress)
+		if err != nil {
+			return all, fmt.Errorf("failed to migrate %s: %v", r.name, err)
+		}
+		failed += progress.Failed
+	}
+	if failed > 0 {
+		return all, fmt.Errorf("failed to migrate %d objects, the stored versions of the CRDs are not updated", failed)
+	}
+	if m.crdClient == nil {
+		return all, nil
+	}
+	for _, r := range resources {
+		if err := m.updateStoredVersions(ctx, r.name+"."+crdv1.GroupName); err != nil {
+			return all, err
+		}
+	}
+	return all, nil
+}
+
+// migrateResource writes back all the objects of a resource.
+func (m *Migrator) migrateResource(ctx context.Context, r resource) (Progress, error) {
+	progress := Progress{Resource: r.name}
+	cont := ""
+	for {
+		items, next, err := m.list(ctx, r, cont)
+		if err != nil {
+			return progress, err
+		}
+		for _, item := range items {
+			switch err := m.update(ctx, r, item); {
+			case err == nil, apierrs.IsConflict(err):
+				// A conflict means the object was written meanwhile, in the
+				// storage version.
+				progress.Migrated++
+			case apierrs.IsNotFound(err):
+				progress.Skipped++
+			default:
+				klog.Errorf("migrateResource [%s]: %v", r.name, err)
+				progress.Failed++
+			}
+		}
+		m.report(progress)
+		if next == "" {
+			return progress, nil
+		}
+		cont = next
+	}
+}
+
+// list returns a page of the objects of a resource, and the continue token
+// of the next page.
+func (m *Migrator) list(ctx context.Context, r resource, cont string) ([]map[string]interface{}, string, error) {
+	request := m.client.Get().AbsPath("/apis", crdv1.GroupName, crdv1.SchemeGroupVersion.Version, r.name).
+		Param("limit", strconv.FormatInt(m.pageSize, 10))
+	if cont != "" {
+		request = request.Param("continue", cont)
+	}
+	data, err := request.Do(ctx).Raw()
+	if err != nil {
+		return nil, "", err
+	}
+	var list struct {
+		Metadata metav1.ListMeta          `json:"metadata"`
+		Items    []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, "", fmt.Errorf("failed to decode the list of %s: %v", r.name, err)
+	}
+	return list.Items, list.Metadata.Continue, nil
+}
+
+// update writes an object back without its deprecated fields.
+func (m *Migrator) update(ctx context.Context, r resource, obj map[string]interface{}) error {
+	for _, path := range m.deprecatedFields[r.name] {
+		unstructured.RemoveNestedField(obj, path...)
+	}
+	// The items of a list may omit their type.
+	obj["apiVersion"] = crdv1.SchemeGroupVersion.String()
+	obj["kind"] = r.kind
+	namespace, _, _ := unstructured.NestedString(obj, "metadata", "namespace")
+	name, _, _ := unstructured.NestedString(obj, "metadata", "name")
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	path := []string{"/apis", crdv1.GroupName, crdv1.SchemeGroupVersion.Version}
+	if namespace != "" {
+		path = append(path, "namespaces", namespace)
+	}
+	path = append(path, r.name, name)
+	return m.client.Put().AbsPath(path...).SetHeader("Content-Type", "application/json").Body(body).Do(ctx).Error()
+}
+
+// updateStoredVersions reduces the stored versions of a CRD to its storage
+// version.
+func (m *Migrator) updateStoredVersions(ctx context.Context, crdName string) error {
+	data, err := m.crdClient.Get().AbsPath(crdBasePath, crdName).Do(ctx).Raw()
+	if err != nil {
+		return fmt.Errorf("failed to get CRD %s: %v", crdName, err)
+	}
+	var crd struct {
+		Spec struct {
+			Versions []struct {
+				Name    string `json:"name"`
+				Storage bool   `json:"storage"`
+			} `json:"versions"`
+		} `json:"spec"`
+		Status struct {
+			StoredVersions []string `json:"storedVersions"`
+		} `json:"status"`
+	}
+	if err := json.Unmarshal(data, &crd); err != nil {
+		return fmt.Errorf("failed to decode CRD %s: %v", crdName, err)
+	}
+	storageVersion := ""
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			storageVersion = version.Name
+		}
+	}
+	if storageVersion == "" {
+		return fmt.Errorf("CRD %s has no storage version", crdName)
+	}
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion {
+		klog.V(4).Infof("updateStoredVersions [%s]: only %s is stored", crdName, storageVersion)
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{"storedVersions": []string{storageVersion}},
+	})
+	if err != nil {
+		return err
+	}
+	if err := m.crdClient.Patch(types.MergePatchType).AbsPath(crdBasePath, crdName, "status").Body(patch).Do(ctx).Error(); err != nil {
+		return fmt.Errorf("failed to update the stored versions of CRD %s: %v", crdName, err)
+	}
+	klog.Infof("Updated the stored versions of CRD %s from %v to [%s]", crdName, crd.Status.StoredVersions, storageVersion)
+	return nil
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagemigration
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// apiServer serves the objects of the migrated resources, page by page, and
+// records the objects written back.
+type apiServer struct {
+	lock sync.Mutex
+	// objects are the stored objects by resource.
+	objects map[string][]map[string]interface{}
+	// updateErrors are the status codes of the updates failing, by name of
+	// the object.
+	updateErrors map[string]int
+	// updates are the bodies of the updates, by path.
+	updates map[string]map[string]interface{}
+}
+
+func newAPIServer(updateErrors map[string]int) *apiServer {
+	object := func(name, namespace string) map[string]interface{} {
+		metadata := map[string]interface{}{"name": name}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		return map[string]interface{}{"metadata": metadata, "spec": map[string]interface{}{}}
+	}
+	return &apiServer{
+		objects: map[string][]map[string]interface{}{
+			"volumenfsexportclasses":  {object("class", "")},
+			"volumenfsexportcontents": {object("content-1", ""), object("content-2", ""), object("content-3", "")},
+			"volumenfsexports":        {object("nfsexport", "default")},
+		},
+		updateErrors: updateErrors,
+		updates:      map[string]map[string]interface{}{},
+	}
+}
+
+func (s *apiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/apis/"+crdv1.GroupName+"/v1/"), "/")
+	switch r.Method {
+	case http.MethodGet:
+		items := s.objects[parts[0]]
+		start, _ := strconv.Atoi(r.URL.Query().Get("continue"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		end, cont := len(items), ""
+		if start+limit < end {
+			end = start + limit
+			cont = strconv.Itoa(end)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"metadata": map[string]interface{}{"continue": cont},
+			"items":    items[start:end],
+		})
+	case http.MethodPut:
+		name := parts[len(parts)-1]
+		if code, ok := s.updateErrors[name]; ok {
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(metav1.Status{
+				TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+				Status:   metav1.StatusFailure,
+				Reason:   metav1.StatusReason(http.StatusText(code)),
+				Code:     int32(code),
+			})
+			return
+		}
+		var obj map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&obj)
+		s.updates[r.URL.Path] = obj
+		json.NewEncoder(w).Encode(obj)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newRESTClient(t *testing.T, server *httptest.Server) rest.Interface {
+	kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return kubeClient.Discovery().RESTClient()
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name         string
+		updateErrors map[string]int
+		expected     []Progress
+		expectErr    bool
+	}{
+		{
+			name: "all objects are migrated",
+			expected: []Progress{
+				{Resource: "volumenfsexportclasses", Migrated: 1},
+				{Resource: "volumenfsexportcontents", Migrated: 3},
+				{Resource: "volumenfsexports", Migrated: 1},
+			},
+		},
+		{
+			name: "objects written or deleted meanwhile",
+			updateErrors: map[string]int{
+				"content-1": http.StatusConflict,
+				"content-2": http.StatusNotFound,
+			},
+			expected: []Progress{
+				{Resource: "volumenfsexportclasses", Migrated: 1},
+				{Resource: "volumenfsexportcontents", Migrated: 2, Skipped: 1},
+				{Resource: "volumenfsexports", Migrated: 1},
+			},
+		},
+		{
+			name: "failed objects do not stop the migration",
+			updateErrors: map[string]int{
+				"content-3": http.StatusInternalServerError,
+			},
+			expected: []Progress{
+				{Resource: "volumenfsexportclasses", Migrated: 1},
+				{Resource: "volumenfsexportcontents", Migrated: 2, Failed: 1},
+				{Resource: "volumenfsexports", Migrated: 1},
+			},
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(newAPIServer(test.updateErrors))
+			defer server.Close()
+			var reported []Progress
+			m := NewMigrator(newRESTClient(t, server), nil, 100, func(p Progress) {
+				reported = append(reported, p)
+			})
+			progress, err := m.Run(context.TODO())
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error %v, got %v", test.expectErr, err)
+			}
+			if !reflect.DeepEqual(progress, test.expected) {
+				t.Errorf("expected progress %+v, got %+v", test.expected, progress)
+			}
+			if !reflect.DeepEqual(reported, test.expected) {
+				t.Errorf("expected reported progress %+v, got %+v", test.expected, reported)
+			}
+		})
+	}
+}
+
+func TestRunPages(t *testing.T) {
+	server := httptest.NewServer(newAPIServer(nil))
+	defer server.Close()
+	var reported []Progress
+	m := NewMigrator(newRESTClient(t, server), nil, 2, func(p Progress) {
+		reported = append(reported, p)
+	})
+	if _, err := m.Run(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Progress{
+		{Resource: "volumenfsexportclasses", Migrated: 1},
+		{Resource: "volumenfsexportcontents", Migrated: 2},
+		{Resource: "volumenfsexportcontents", Migrated: 3},
+		{Resource: "volumenfsexports", Migrated: 1},
+	}
+	if !reflect.DeepEqual(reported, expected) {
+		t.Errorf("expected reported progress %+v, got %+v", expected, reported)
+	}
+}
+
+func TestRunStripsDeprecatedFields(t *testing.T) {
+	api := newAPIServer(nil)
+	api.objects["volumenfsexports"][0]["spec"] = map[string]interface{}{
+		"source":     map[string]interface{}{"persistentVolumeClaimName": "claim"},
+		"deprecated": "value",
+		"unknown":    "value",
+	}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	m := NewMigrator(newRESTClient(t, server), nil, 100, nil)
+	m.deprecatedFields = map[string][][]string{"volumenfsexports": {{"spec", "deprecated"}}}
+	if _, err := m.Run(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"apiVersion": crdv1.SchemeGroupVersion.String(),
+		"kind":       "VolumeNfsExport",
+		"metadata":   map[string]interface{}{"name": "nfsexport", "namespace": "default"},
+		"spec": map[string]interface{}{
+			"source":  map[string]interface{}{"persistentVolumeClaimName": "claim"},
+			"unknown": "value",
+		},
+	}
+	path := "/apis/" + crdv1.GroupName + "/v1/namespaces/default/volumenfsexports/nfsexport"
+	if got := api.updates[path]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected update %v, got %v", expected, got)
+	}
+	if _, ok := api.updates["/apis/"+crdv1.GroupName+"/v1/volumenfsexportclasses/class"]; !ok {
+		t.Errorf("expected cluster scoped class to be updated, got updates %v", api.updates)
+	}
+}
+
+func TestUpdateStoredVersions(t *testing.T) {
+	var lock sync.Mutex
+	patches := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			storedVersions := `["v1beta1","v1"]`
+			if r.URL.Path == crdBasePath+"/volumenfsexportclasses."+crdv1.GroupName {
+				storedVersions = `["v1"]`
+			}
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"spec":{"versions":[{"name":"v1beta1","storage":false},{"name":"v1","storage":true}]},"status":{"storedVersions":`+storedVersions+`}}`)
+		case http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			patches[r.URL.Path] = string(body)
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	api := httptest.NewServer(newAPIServer(nil))
+	defer api.Close()
+	m := NewMigrator(newRESTClient(t, api), newRESTClient(t, server), 100, nil)
+	if _, err := m.Run(context.TODO()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[string]string{
+		crdBasePath + "/volumenfsexportcontents." + crdv1.GroupName + "/status": `{"status":{"storedVersions":["v1"]}}`,
+		crdBasePath + "/volumenfsexports." + crdv1.GroupName + "/status":        `{"status":{"storedVersions":["v1"]}}`,
+	}
+	if !reflect.DeepEqual(patches, expected) {
+		t.Errorf("expected patches %v, got %v", expected, patches)
+	}
+}
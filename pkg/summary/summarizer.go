@@ -0,0 +1,262 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summary
+
+import (
+	"context"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	storageinformers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions/volumenfsexport/v1"
+	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	klog "k8s.io/klog/v2"
+)
+
+// Design:
+//
+// The summarizer maintains one NfsExportSummary per namespace holding at
+// least one VolumeNfsExport. The work queue is keyed by namespace: every
+// event of a VolumeNfsExport, or of the summary itself, enqueues its
+// namespace, and a sync recomputes the whole summary from the informer cache.
+// The summary of a namespace without VolumeNfsExports is deleted. Objects of
+// the kind with another name are left alone.
+
+// Summarizer maintains the NfsExportSummary of each namespace.
+type Summarizer struct {
+	clientset clientset.Interface
+	queue     workqueue.RateLimitingInterface
+
+	nfsexportLister       storagelisters.VolumeNfsExportLister
+	nfsexportListerSynced cache.InformerSynced
+	summaryLister         storagelisters.NfsExportSummaryLister
+	summaryListerSynced   cache.InformerSynced
+}
+
+// NewSummarizer returns a new *Summarizer that maintains the summaries of the
+// VolumeNfsExports of volumeNfsExportInformer with clientset.
+func NewSummarizer(
+	clientset clientset.Interface,
+	volumeNfsExportInformer storageinformers.VolumeNfsExportInformer,
+	nfsExportSummaryInformer storageinformers.NfsExportSummaryInformer,
+	resyncPeriod time.Duration,
+	rateLimiter workqueue.RateLimiter,
+) *Summarizer {
+	s := &Summarizer{
+		clientset: clientset,
+		queue:     workqueue.NewNamedRateLimitingQueue(rateLimiter, "nfsexport-summarizer-namespace"),
+	}
+
+	volumeNfsExportInformer.Informer().AddEventHandlerWithResyncPeriod(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { s.enqueueNamespace(obj) },
+			UpdateFunc: func(oldObj, newObj interface{}) { s.enqueueNamespace(newObj) },
+			DeleteFunc: func(obj interface{}) { s.enqueueNamespace(obj) },
+		},
+		resyncPeriod,
+	)
+	s.nfsexportLister = volumeNfsExportInformer.Lister()
+	s.nfsexportListerSynced = volumeNfsExportInformer.Informer().HasSynced
+
+	// Summaries changed or deleted by someone else are repaired.
+	nfsExportSummaryInformer.Informer().AddEventHandler(
+		cache.FilteringResourceEventHandler{
+			FilterFunc: isSummary,
+			Handler: cache.ResourceEventHandlerFuncs{
+				UpdateFunc: func(oldObj, newObj interface{}) { s.enqueueNamespace(newObj) },
+				DeleteFunc: func(obj interface{}) { s.enqueueNamespace(obj) },
+			},
+		},
+	)
+	s.summaryLister = nfsExportSummaryInformer.Lister()
+	s.summaryListerSynced = nfsExportSummaryInformer.Informer().HasSynced
+
+	return s
+}
+
+// Run starts the summary workers and blocks until stopCh is closed.
+func (s *Summarizer) Run(workers int, stopCh <-chan struct{}) {
+	defer s.queue.ShutDown()
+
+	klog.Infof("Starting nfsexport summarizer")
+	defer klog.Infof("Shutting nfsexport summarizer")
+
+	if !cache.WaitForCacheSync(stopCh, s.nfsexportListerSynced, s.summaryListerSynced) {
+		klog.Errorf("Cannot sync caches")
+		return
+	}
+
+	for i := 0; i < workers; i++ {
+		go wait.Until(s.namespaceWorker, 0, stopCh)
+	}
+
+	<-stopCh
+}
+
+// isSummary returns true if obj is the NfsExportSummary of its namespace.
+func isSummary(obj interface{}) bool {
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	summary, ok := obj.(*crdv1.NfsExportSummary)
+	return ok && summary.Name == crdv1.NfsExportSummaryName
+}
+
+// enqueueNamespace adds the namespace of a nfsexport or summary to the work
+// queue.
+func (s *Summarizer) enqueueNamespace(obj interface{}) {
+	// Beware of "xxx deleted" events
+	if unknown, ok := obj.(cache.DeletedFinalStateUnknown); ok && unknown.Obj != nil {
+		obj = unknown.Obj
+	}
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		klog.Errorf("failed to get namespace of object %v: %v", obj, err)
+		return
+	}
+	klog.V(5).Infof("enqueued namespace %q for summary", object.GetNamespace())
+	s.queue.Add(object.GetNamespace())
+}
+
+// namespaceWorker is the main worker for summarizing namespaces.
+func (s *Summarizer) namespaceWorker() {
+	keyObj, quit := s.queue.Get()
+	if quit {
+		return
+	}
+	defer s.queue.Done(keyObj)
+
+	if err := s.syncNamespace(keyObj.(string)); err != nil {
+		s.queue.AddRateLimited(keyObj)
+		klog.V(4).Infof("Failed to summarize namespace %q, will retry again: %v", keyObj.(string), err)
+	} else {
+		s.queue.Forget(keyObj)
+	}
+}
+
+// syncNamespace brings the summary of a namespace in line with its
+// VolumeNfsExports: it creates or updates the summary of a namespace with
+// VolumeNfsExports and deletes the summary of a namespace without.
+func (s *Summarizer) syncNamespace(namespace string) error {
+	klog.V(5).Infof("syncNamespace[%s]", namespace)
+
+	nfsexports, err := s.nfsexportLister.VolumeNfsExports(namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	summary, err := s.summaryLister.NfsExportSummaries(namespace).Get(crdv1.NfsExportSummaryName)
+	if err != nil {
+		if !apierrs.IsNotFound(err) {
+			return err
+		}
+		summary = nil
+	}
+
+	if len(nfsexports) == 0 {
+		if summary == nil {
+			return nil
+		}
+		klog.V(4).Infof("deleting summary of namespace %s without nfsexports", namespace)
+		err := s.clientset.NfsExportV1().NfsExportSummaries(namespace).Delete(context.TODO(), crdv1.NfsExportSummaryName, metav1.DeleteOptions{})
+		if err != nil && !apierrs.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	status := summarize(nfsexports)
+	if summary == nil {
+		klog.V(4).Infof("creating summary of namespace %s", namespace)
+		summary, err = s.clientset.NfsExportV1().NfsExportSummaries(namespace).Create(context.TODO(), &crdv1.NfsExportSummary{
+			ObjectMeta: metav1.ObjectMeta{Name: crdv1.NfsExportSummaryName, Namespace: namespace},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	if statusEqual(summary.Status, status) {
+		return nil
+	}
+
+	now := metav1.Now()
+	status.LastUpdateTime = &now
+	summaryClone := summary.DeepCopy()
+	summaryClone.Status = status
+	if _, err := s.clientset.NfsExportV1().NfsExportSummaries(namespace).UpdateStatus(context.TODO(), summaryClone, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+	klog.V(5).Infof("updated summary of namespace %s: %d ready, %d pending, %d failed", namespace, status.ReadyCount, status.PendingCount, status.FailedCount)
+	return nil
+}
+
+// summarize counts the nfsexports by state and sums the restore sizes of the
+// ready ones. Nfsexports being deleted are not counted.
+func summarize(nfsexports []*crdv1.VolumeNfsExport) *crdv1.NfsExportSummaryStatus {
+	status := &crdv1.NfsExportSummaryStatus{}
+	totalRestoreSize := resource.NewQuantity(0, resource.BinarySI)
+	for _, nfsexport := range nfsexports {
+		if nfsexport.DeletionTimestamp != nil {
+			continue
+		}
+		switch {
+		case isReady(nfsexport):
+			status.ReadyCount++
+			if nfsexport.Status.RestoreSize != nil {
+				totalRestoreSize.Add(*nfsexport.Status.RestoreSize)
+			}
+		case isFailed(nfsexport):
+			status.FailedCount++
+		default:
+			status.PendingCount++
+		}
+	}
+	status.TotalRestoreSize = totalRestoreSize
+	return status
+}
+
+func isReady(nfsexport *crdv1.VolumeNfsExport) bool {
+	return nfsexport.Status != nil && nfsexport.Status.ReadyToUse != nil && *nfsexport.Status.ReadyToUse
+}
+
+func isFailed(nfsexport *crdv1.VolumeNfsExport) bool {
+	return nfsexport.Status != nil &&
+		(nfsexport.Status.Error != nil || meta.IsStatusConditionTrue(nfsexport.Status.Conditions, crdv1.ConditionFailed))
+}
+
+// statusEqual returns true if the counts and the total restore size of the
+// statuses are equal. The update time is ignored.
+func statusEqual(current, desired *crdv1.NfsExportSummaryStatus) bool {
+	if current == nil {
+		return false
+	}
+	if current.ReadyCount != desired.ReadyCount || current.PendingCount != desired.PendingCount || current.FailedCount != desired.FailedCount {
+		return false
+	}
+	if current.TotalRestoreSize == nil {
+		return desired.TotalRestoreSize == nil
+	}
+	return desired.TotalRestoreSize != nil && current.TotalRestoreSize.Cmp(*desired.TotalRestoreSize) == 0
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summary
+
+import (
+	"context"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const testNamespace = "default"
+
+var (
+	True  = true
+	False = false
+)
+
+func newNfsExport(name string, ready *bool, restoreSize string, failed bool) *crdv1.VolumeNfsExport {
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+	}
+	if ready != nil {
+		nfsexport.Status = &crdv1.VolumeNfsExportStatus{ReadyToUse: ready}
+		if restoreSize != "" {
+			size := resource.MustParse(restoreSize)
+			nfsexport.Status.RestoreSize = &size
+		}
+		if failed {
+			nfsexport.Status.Conditions = []metav1.Condition{{Type: crdv1.ConditionFailed, Status: metav1.ConditionTrue}}
+		}
+	}
+	return nfsexport
+}
+
+func newSummary(ready, pending, failed int32, totalRestoreSize string) *crdv1.NfsExportSummary {
+	size := resource.MustParse(totalRestoreSize)
+	return &crdv1.NfsExportSummary{
+		ObjectMeta: metav1.ObjectMeta{Name: crdv1.NfsExportSummaryName, Namespace: testNamespace},
+		Status: &crdv1.NfsExportSummaryStatus{
+			ReadyCount:       ready,
+			PendingCount:     pending,
+			FailedCount:      failed,
+			TotalRestoreSize: &size,
+		},
+	}
+}
+
+func newTestSummarizer(t *testing.T, nfsexports []*crdv1.VolumeNfsExport, summaries []*crdv1.NfsExportSummary) (*Summarizer, *fake.Clientset) {
+	var objects []runtime.Object
+	for _, summary := range summaries {
+		objects = append(objects, summary)
+	}
+	client := fake.NewSimpleClientset(objects...)
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	nfsexportInformer := factory.NfsExport().V1().VolumeNfsExports()
+	summaryInformer := factory.NfsExport().V1().NfsExportSummaries()
+	s := NewSummarizer(client, nfsexportInformer, summaryInformer, 0, workqueue.DefaultControllerRateLimiter())
+	for _, nfsexport := range nfsexports {
+		if err := nfsexportInformer.Informer().GetIndexer().Add(nfsexport); err != nil {
+			t.Fatalf("failed to add nfsexport %s to informer: %v", nfsexport.Name, err)
+		}
+	}
+	for _, summary := range summaries {
+		if err := summaryInformer.Informer().GetIndexer().Add(summary); err != nil {
+			t.Fatalf("failed to add summary to informer: %v", err)
+		}
+	}
+	return s, client
+}
+
+func TestSyncNamespace(t *testing.T) {
+	deleting := newNfsExport("deleting", &True, "1Gi", false)
+	deleting.DeletionTimestamp = &metav1.Time{}
+
+	tests := []struct {
+		name            string
+		nfsexports      []*crdv1.VolumeNfsExport
+		summaries       []*crdv1.NfsExportSummary
+		expectedSummary *crdv1.NfsExportSummary
+		expectUpdate    bool
+	}{
+		{
+			name: "summary is created",
+			nfsexports: []*crdv1.VolumeNfsExport{
+				newNfsExport("ready1", &True, "1Gi", false),
+				newNfsExport("ready2", &True, "512Mi", false),
+				newNfsExport("pending1", nil, "", false),
+				newNfsExport("pending2", &False, "", false),
+				newNfsExport("failed", &False, "", true),
+				deleting,
+			},
+			expectedSummary: newSummary(2, 2, 1, "1536Mi"),
+			expectUpdate:    true,
+		},
+		{
+			name:            "outdated summary is updated",
+			nfsexports:      []*crdv1.VolumeNfsExport{newNfsExport("ready1", &True, "1Gi", false)},
+			summaries:       []*crdv1.NfsExportSummary{newSummary(0, 1, 0, "0")},
+			expectedSummary: newSummary(1, 0, 0, "1Gi"),
+			expectUpdate:    true,
+		},
+		{
+			name:            "up to date summary is left alone",
+			nfsexports:      []*crdv1.VolumeNfsExport{newNfsExport("ready1", &True, "1Gi", false)},
+			summaries:       []*crdv1.NfsExportSummary{newSummary(1, 0, 0, "1Gi")},
+			expectedSummary: newSummary(1, 0, 0, "1Gi"),
+		},
+		{
+			name:      "summary of a namespace without nfsexports is deleted",
+			summaries: []*crdv1.NfsExportSummary{newSummary(1, 0, 0, "1Gi")},
+		},
+		{
+			name: "no summary is created for a namespace without nfsexports",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, client := newTestSummarizer(t, test.nfsexports, test.summaries)
+			if err := s.syncNamespace(testNamespace); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			summary, err := client.NfsExportV1().NfsExportSummaries(testNamespace).Get(context.TODO(), crdv1.NfsExportSummaryName, metav1.GetOptions{})
+			if test.expectedSummary == nil {
+				if !apierrs.IsNotFound(err) {
+					t.Errorf("expected no summary, got %+v, %v", summary, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get summary: %v", err)
+			}
+			if !statusEqual(summary.Status, test.expectedSummary.Status) {
+				t.Errorf("expected summary status %+v, got %+v", test.expectedSummary.Status, summary.Status)
+			}
+
+			updated := false
+			for _, action := range client.Actions() {
+				if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+					updated = true
+				}
+			}
+			if updated != test.expectUpdate {
+				t.Errorf("expected status update %v, got %v", test.expectUpdate, updated)
+			}
+			if test.expectUpdate && summary.Status.LastUpdateTime == nil {
+				t.Errorf("expected the update time to be set")
+			}
+		})
+	}
+}
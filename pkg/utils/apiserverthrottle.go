@@ -0,0 +1,124 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	klog "k8s.io/klog/v2"
+	"k8s.io/utils/clock"
+)
+
+// APIServerThrottle tracks the 429 Too Many Requests responses of the API
+// server to the clients of a controller, so that the controller backs off its
+// work queues while the API server sheds load instead of failing syncs over
+// and over. A 429 response starts a backoff of minBackoff, or of its
+// Retry-After if longer. A 429 response during a backoff, or within the
+// duration of the backoff after it ended, doubles the backoff up to
+// maxBackoff. After a quiet period the backoff starts again from minBackoff.
+type APIServerThrottle struct {
+	clock      clock.PassiveClock
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	lock      sync.Mutex
+	backoff   time.Duration
+	until     time.Time
+	throttled int64
+}
+
+// NewAPIServerThrottle returns a new *APIServerThrottle.
+func NewAPIServerThrottle(minBackoff, maxBackoff time.Duration, clock clock.PassiveClock) *APIServerThrottle {
+	if maxBackoff < minBackoff {
+		maxBackoff = minBackoff
+	}
+	return &APIServerThrottle{clock: clock, minBackoff: minBackoff, maxBackoff: maxBackoff}
+}
+
+// WrapTransport returns a transport recording the 429 responses of rt in t.
+// It is meant for rest.Config.Wrap, so that all the clients built from the
+// config are tracked.
+func (t *APIServerThrottle) WrapTransport(rt http.RoundTripper) http.RoundTripper {
+	return &throttleRoundTripper{rt: rt, throttle: t}
+}
+
+// Throttled records a 429 response asking to retry after retryAfter, 0 if
+// the response did not say.
+func (t *APIServerThrottle) Throttled(retryAfter time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.throttled++
+
+	now := t.clock.Now()
+	backoff := t.minBackoff
+	if t.backoff > 0 && now.Before(t.until.Add(t.backoff)) {
+		backoff = 2 * t.backoff
+	}
+	if backoff > t.maxBackoff {
+		backoff = t.maxBackoff
+	}
+	if retryAfter > backoff {
+		backoff = retryAfter
+	}
+	if until := now.Add(backoff); until.After(t.until) {
+		t.until = until
+	}
+	if backoff != t.backoff {
+		klog.Warningf("The API server is throttling the controller, backing off for %v", backoff)
+		t.backoff = backoff
+	}
+}
+
+// Backoff returns how long the work queues should wait before the next sync,
+// 0 if the API server is not throttling the controller.
+func (t *APIServerThrottle) Backoff() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if remaining := t.until.Sub(t.clock.Now()); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// ThrottledResponses returns the number of 429 responses recorded so far.
+func (t *APIServerThrottle) ThrottledResponses() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.throttled
+}
+
+type throttleRoundTripper struct {
+	rt       http.RoundTripper
+	throttle *APIServerThrottle
+}
+
+var _ http.RoundTripper = &throttleRoundTripper{}
+
+func (rt *throttleRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.rt.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		rt.throttle.Throttled(retryAfter)
+	}
+	return resp, err
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	testingclock "k8s.io/utils/clock/testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestAPIServerThrottle(t *testing.T) {
+	clock := testingclock.NewFakePassiveClock(time.Now())
+	throttle := NewAPIServerThrottle(time.Second, 4*time.Second, clock)
+
+	status, retryAfter := http.StatusOK, ""
+	rt := throttle.WrapTransport(roundTripperFunc(func(*http.Request) (*http.Response, error) {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp, nil
+	}))
+	roundTrip := func() {
+		if _, err := rt.RoundTrip(&http.Request{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	roundTrip()
+	if backoff := throttle.Backoff(); backoff != 0 {
+		t.Errorf("expected no backoff without 429 responses, got %v", backoff)
+	}
+
+	// Repeated 429 responses double the backoff up to the maximum.
+	status = http.StatusTooManyRequests
+	for _, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		roundTrip()
+		if backoff := throttle.Backoff(); backoff != expected {
+			t.Errorf("expected backoff %v, got %v", expected, backoff)
+		}
+	}
+
+	// The backoff ends, then starts again from the minimum after a quiet
+	// period.
+	clock.SetTime(clock.Now().Add(4 * time.Second))
+	if backoff := throttle.Backoff(); backoff != 0 {
+		t.Errorf("expected the backoff to be over, got %v", backoff)
+	}
+	clock.SetTime(clock.Now().Add(time.Minute))
+	roundTrip()
+	if backoff := throttle.Backoff(); backoff != time.Second {
+		t.Errorf("expected the backoff to start again from 1s, got %v", backoff)
+	}
+
+	// A longer Retry-After is honored.
+	clock.SetTime(clock.Now().Add(time.Minute))
+	retryAfter = "10"
+	roundTrip()
+	if backoff := throttle.Backoff(); backoff != 10*time.Second {
+		t.Errorf("expected the backoff of Retry-After, got %v", backoff)
+	}
+
+	if throttled := throttle.ThrottledResponses(); throttled != 6 {
+		t.Errorf("expected 6 throttled responses, got %d", throttled)
+	}
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+// BacklogPath is the HTTP path at which the nfsexport controller serves its
+// Backlog as JSON.
+const BacklogPath = "/backlog"
+
+// Backlog is the number of objects waiting in the work queues of the
+// nfsexport controller, which the validation webhook consults to reject
+// the creation of VolumeNfsExports while the controller is behind.
+type Backlog struct {
+	NfsExports int `json:"nfsexports"`
+	Contents   int `json:"contents"`
+}
+
+// Total returns the number of objects waiting in all the work queues.
+func (b Backlog) Total() int {
+	return b.NfsExports + b.Contents
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// ClaimSourceNfsExportIndex is the name of an index of a
+// PersistentVolumeClaim informer keyed by the namespace and name of the
+// VolumeNfsExport a claim is restored from, see ClaimSourceNfsExportName.
+const ClaimSourceNfsExportIndex = "sourceNfsExport"
+
+// ClaimSourceNfsExportName returns the name of the VolumeNfsExport in the
+// dataSourceRef, or the dataSource when it is not set, of claim, or an empty
+// string if claim is not restored from a VolumeNfsExport.
+func ClaimSourceNfsExportName(claim *v1.PersistentVolumeClaim) string {
+	ref := claim.Spec.DataSourceRef
+	if ref == nil {
+		ref = claim.Spec.DataSource
+	}
+	if ref == nil || ref.Kind != "VolumeNfsExport" || ref.APIGroup == nil || *ref.APIGroup != crdv1.GroupName {
+		return ""
+	}
+	return ref.Name
+}
+
+// ClaimSourceNfsExportIndexFunc indexes a claim by the namespace and name of
+// the VolumeNfsExport it is restored from. Other claims are not indexed.
+func ClaimSourceNfsExportIndexFunc(obj interface{}) ([]string, error) {
+	claim, ok := obj.(*v1.PersistentVolumeClaim)
+	if !ok {
+		return nil, nil
+	}
+	name := ClaimSourceNfsExportName(claim)
+	if name == "" {
+		return nil, nil
+	}
+	return []string{claim.Namespace + "/" + name}, nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// User agent suffixes of the API clients of each subsystem. They let API
+// Priority and Fairness and audit logs tell the traffic of the subsystems
+// apart, e.g. to give more priority to the writes, which include deletions
+// and finalizer removals, than to the informers.
+const (
+	UserAgentWrites         = "writes"
+	UserAgentStatus         = "status"
+	UserAgentInformers      = "informers"
+	UserAgentLeaderElection = "leader-election"
+)
+
+// SubsystemConfig returns a copy of config for the API client of a
+// subsystem. The copy has its user agent tagged with subsystem and, if qps
+// is greater than 0, its own QPS and burst. Clients built from different
+// configs are throttled independently.
+func SubsystemConfig(config *rest.Config, subsystem string, qps float32, burst int) *rest.Config {
+	subsystemConfig := rest.CopyConfig(config)
+	if qps > 0 {
+		subsystemConfig.QPS = qps
+		subsystemConfig.Burst = burst
+	}
+	return rest.AddUserAgent(subsystemConfig, subsystem)
+}
+
+// DryRunConfig returns a copy of config whose clients send every write with
+// dryRun=All, so that the API server validates and admits it without
+// persisting it. Reads are sent unchanged.
+func DryRunConfig(config *rest.Config) *rest.Config {
+	dryRunConfig := rest.CopyConfig(config)
+	dryRunConfig.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &dryRunRoundTripper{delegate: rt}
+	})
+	return dryRunConfig
+}
+
+type dryRunRoundTripper struct {
+	delegate http.RoundTripper
+}
+
+func (rt *dryRunRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		req = req.Clone(req.Context())
+		query := req.URL.Query()
+		query.Set("dryRun", metav1.DryRunAll)
+		req.URL.RawQuery = query.Encode()
+	}
+	return rt.delegate.RoundTrip(req)
+}
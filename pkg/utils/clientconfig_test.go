@@ -0,0 +1,109 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestSubsystemConfig(t *testing.T) {
+	config := &rest.Config{Host: "https://example.com", QPS: 5, Burst: 10}
+
+	tests := []struct {
+		name          string
+		qps           float32
+		burst         int
+		expectedQPS   float32
+		expectedBurst int
+	}{
+		{name: "own limits", qps: 20, burst: 40, expectedQPS: 20, expectedBurst: 40},
+		{name: "inherited limits", qps: 0, burst: 0, expectedQPS: 5, expectedBurst: 10},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := SubsystemConfig(config, UserAgentStatus, test.qps, test.burst)
+			if got == config {
+				t.Fatalf("expected a copy of the config")
+			}
+			if got.QPS != test.expectedQPS || got.Burst != test.expectedBurst {
+				t.Errorf("expected QPS %v and burst %d, got %v and %d", test.expectedQPS, test.expectedBurst, got.QPS, got.Burst)
+			}
+			if !strings.HasSuffix(got.UserAgent, "/"+UserAgentStatus) {
+				t.Errorf("expected user agent tagged with %q, got %q", UserAgentStatus, got.UserAgent)
+			}
+			if got.Host != config.Host {
+				t.Errorf("expected host %q, got %q", config.Host, got.Host)
+			}
+		})
+	}
+	if config.QPS != 5 || config.Burst != 10 || config.UserAgent != "" {
+		t.Errorf("expected the original config to be left untouched, got %+v", config)
+	}
+}
+
+func TestDryRunConfig(t *testing.T) {
+	dryRun := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dryRun[r.Method] = r.URL.Query().Get("dryRun")
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Success"}`))
+			return
+		}
+		w.Write([]byte(`{"kind":"Pod","apiVersion":"v1","metadata":{"name":"pod1","namespace":"default"}}`))
+	}))
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	client, err := kubernetes.NewForConfig(DryRunConfig(config))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	pods := client.CoreV1().Pods("default")
+	ctx := context.TODO()
+	if _, err := pods.Get(ctx, "pod1", metav1.GetOptions{}); err != nil {
+		t.Fatalf("unexpected get error: %v", err)
+	}
+	if _, err := pods.Patch(ctx, "pod1", "application/merge-patch+json", []byte(`{}`), metav1.PatchOptions{}); err != nil {
+		t.Fatalf("unexpected patch error: %v", err)
+	}
+	if err := pods.Delete(ctx, "pod1", metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("unexpected delete error: %v", err)
+	}
+
+	expected := map[string]string{
+		http.MethodGet:    "",
+		http.MethodPatch:  metav1.DryRunAll,
+		http.MethodDelete: metav1.DryRunAll,
+	}
+	for method, value := range expected {
+		if dryRun[method] != value {
+			t.Errorf("expected dryRun %q for %s, got %q", value, method, dryRun[method])
+		}
+	}
+	if config.WrapTransport != nil {
+		t.Errorf("expected the original config to be left untouched")
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+const (
+	// Parameters passed on CreateNfsExportRequest and UpdateNfsExportRequest
+	// calls with the encryption of the class of a nfsexport, see
+	// VolumeNfsExportClass.Encryption.
+	PrefixedExportTransportKey             = csiParameterPrefix + "export-transport"
+	PrefixedExportKMSKeySecretNameKey      = csiParameterPrefix + "export-kms-key-secret-name"
+	PrefixedExportKMSKeySecretNamespaceKey = csiParameterPrefix + "export-kms-key-secret-namespace"
+	// PrefixedExportKMSKeyVersionKey is the version of the key in the KMS
+	// key Secret, see KMSKeySecretVersionKey.
+	PrefixedExportKMSKeyVersionKey = csiParameterPrefix + "export-kms-key-version"
+
+	// KMSKeySecretVersionKey is the key of the data of the KMS key Secret
+	// holding the version of the key. It must be changed when the key is
+	// rotated, other changes of the Secret do not rotate the key of exports.
+	KMSKeySecretVersionKey = "version"
+
+	// KMSKeyCredentialPrefix prefixes the keys of the data of the KMS key
+	// Secret in the credentials passed to the driver, so that they do not
+	// collide with the nfsexporter credentials.
+	KMSKeyCredentialPrefix = "kms-key/"
+
+	// AnnExportKMSKeyVersion annotation applies to VolumeNfsExportContents.
+	// It records the version of the KMS key Secret the export was created or
+	// last updated with, see PrefixedExportKMSKeyVersionKey. The sidecar
+	// updates the export when the Secret moves to another version.
+	AnnExportKMSKeyVersion = "nfsexport.storage.kubernetes.io/export-kms-key-version"
+)
+
+// GetKMSKeySecret returns the KMS key Secret referenced by the encryption at
+// rest of a nfsexport class, or nil if the class does not encrypt exports at
+// rest. The Secret is read from secretLister, or from the API server if
+// secretLister is nil. It fails if the Secret has no KMSKeySecretVersionKey.
+func GetKMSKeySecret(k8s kubernetes.Interface, secretLister corelisters.SecretLister, class *crdv1.VolumeNfsExportClass) (*v1.Secret, error) {
+	if class.Encryption == nil || class.Encryption.AtRest == nil {
+		return nil, nil
+	}
+	ref := class.Encryption.AtRest.KMSKeySecretRef
+	var secret *v1.Secret
+	var err error
+	if secretLister != nil {
+		secret, err = secretLister.Secrets(ref.Namespace).Get(ref.Name)
+	} else {
+		secret, err = k8s.CoreV1().Secrets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting KMS key secret %s in namespace %s: %w", ref.Name, ref.Namespace, err)
+	}
+	if len(secret.Data[KMSKeySecretVersionKey]) == 0 {
+		return nil, fmt.Errorf("KMS key secret %s in namespace %s has no %q key", ref.Name, ref.Namespace, KMSKeySecretVersionKey)
+	}
+	return secret, nil
+}
+
+// GetKMSKeyVersion returns the version of the key in a KMS key Secret
+// returned by GetKMSKeySecret.
+func GetKMSKeyVersion(keySecret *v1.Secret) string {
+	return string(keySecret.Data[KMSKeySecretVersionKey])
+}
+
+// GetExportEncryptionParameters returns the parameters to pass to the driver
+// for the encryption of a nfsexport class. keySecret is the KMS key Secret of
+// the class, see GetKMSKeySecret.
+func GetExportEncryptionParameters(class *crdv1.VolumeNfsExportClass, keySecret *v1.Secret) map[string]string {
+	parameters := map[string]string{}
+	if class.Encryption == nil {
+		return parameters
+	}
+	if class.Encryption.Transport != nil {
+		parameters[PrefixedExportTransportKey] = string(*class.Encryption.Transport)
+	}
+	if keySecret != nil {
+		parameters[PrefixedExportKMSKeySecretNameKey] = keySecret.Name
+		parameters[PrefixedExportKMSKeySecretNamespaceKey] = keySecret.Namespace
+		parameters[PrefixedExportKMSKeyVersionKey] = GetKMSKeyVersion(keySecret)
+	}
+	return parameters
+}
+
+// WithKMSKeyCredentials returns a copy of credentials with the data of the
+// KMS key Secret added under KMSKeyCredentialPrefix.
+func WithKMSKeyCredentials(credentials map[string]string, keySecret *v1.Secret) map[string]string {
+	if keySecret == nil {
+		return credentials
+	}
+	merged := make(map[string]string, len(credentials)+len(keySecret.Data))
+	for key, value := range credentials {
+		merged[key] = value
+	}
+	for key, value := range keySecret.Data {
+		merged[KMSKeyCredentialPrefix+key] = string(value)
+	}
+	return merged
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestExportEncryption(t *testing.T) {
+	keySecret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "kms-key", Namespace: "kms", ResourceVersion: "42"},
+		Data:       map[string][]byte{"keyID": []byte("key-1"), KMSKeySecretVersionKey: []byte("1")},
+	}
+	transport := crdv1.NfsExportTransportKrb5p
+	class := &crdv1.VolumeNfsExportClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "encrypted"},
+		Encryption: &crdv1.NfsExportEncryption{
+			Transport: &transport,
+			AtRest: &crdv1.NfsExportAtRestEncryption{
+				KMSKeySecretRef: v1.SecretReference{Name: "kms-key", Namespace: "kms"},
+			},
+		},
+	}
+
+	secret, err := GetKMSKeySecret(fake.NewSimpleClientset(keySecret), nil, class)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secret.ResourceVersion != "42" || GetKMSKeyVersion(secret) != "1" {
+		t.Errorf("expected the KMS key secret, got %+v", secret)
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	indexer.Add(keySecret)
+	secret, err = GetKMSKeySecret(fake.NewSimpleClientset(), corelisters.NewSecretLister(indexer), class)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if secret.ResourceVersion != "42" {
+		t.Errorf("expected the KMS key secret from the lister, got %+v", secret)
+	}
+	if _, err := GetKMSKeySecret(fake.NewSimpleClientset(), nil, class); err == nil {
+		t.Errorf("expected an error for a missing KMS key secret")
+	}
+	unversioned := keySecret.DeepCopy()
+	delete(unversioned.Data, KMSKeySecretVersionKey)
+	if _, err := GetKMSKeySecret(fake.NewSimpleClientset(unversioned), nil, class); err == nil {
+		t.Errorf("expected an error for a KMS key secret without version")
+	}
+	if secret, err := GetKMSKeySecret(fake.NewSimpleClientset(keySecret), nil, &crdv1.VolumeNfsExportClass{}); secret != nil || err != nil {
+		t.Errorf("expected no KMS key secret for a class without encryption at rest, got %v, %v", secret, err)
+	}
+
+	expectedParameters := map[string]string{
+		PrefixedExportTransportKey:             "krb5p",
+		PrefixedExportKMSKeySecretNameKey:      "kms-key",
+		PrefixedExportKMSKeySecretNamespaceKey: "kms",
+		PrefixedExportKMSKeyVersionKey:         "1",
+	}
+	if parameters := GetExportEncryptionParameters(class, keySecret); !reflect.DeepEqual(parameters, expectedParameters) {
+		t.Errorf("expected parameters %v, got %v", expectedParameters, parameters)
+	}
+	if parameters := GetExportEncryptionParameters(&crdv1.VolumeNfsExportClass{}, nil); len(parameters) != 0 {
+		t.Errorf("expected no parameters for a class without encryption, got %v", parameters)
+	}
+
+	credentials := map[string]string{"keyID": "nfsexporter"}
+	expectedCredentials := map[string]string{"keyID": "nfsexporter", "kms-key/keyID": "key-1", "kms-key/version": "1"}
+	if merged := WithKMSKeyCredentials(credentials, keySecret); !reflect.DeepEqual(merged, expectedCredentials) {
+		t.Errorf("expected credentials %v, got %v", expectedCredentials, merged)
+	}
+	if len(credentials) != 1 {
+		t.Errorf("expected the original credentials to be left untouched, got %v", credentials)
+	}
+}
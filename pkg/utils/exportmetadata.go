@@ -0,0 +1,129 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// Nfsexport class parameters listing, separated by commas, the keys of
+	// the annotations and labels of a VolumeNfsExport that are passed to the
+	// driver when its export is created, e.g. a ticket ID to tag the export
+	// with on the storage system.
+	PrefixedExportPropagatedAnnotationsKey = csiParameterPrefix + "export-propagated-annotations"
+	PrefixedExportPropagatedLabelsKey      = csiParameterPrefix + "export-propagated-labels"
+
+	// Prefixes of the parameters passed on CreateNfsExportRequest calls with
+	// the propagated annotations and labels. The key of the annotation or
+	// label follows the prefix, e.g. a "example.com/ticket" annotation is
+	// passed as "csi.storage.k8s.io/volumenfsexport/annotation/example.com/ticket".
+	PrefixedVolumeNfsExportAnnotationPrefix = csiParameterPrefix + "volumenfsexport/annotation/"
+	PrefixedVolumeNfsExportLabelPrefix      = csiParameterPrefix + "volumenfsexport/label/"
+
+	// AnnExportPropagatedMetadata annotation applies to
+	// VolumeNfsExportContents. It records, as a JSON object, the parameters
+	// derived by the nfsexport controller from the annotations and labels of
+	// the VolumeNfsExport. The sidecar passes them to the driver.
+	AnnExportPropagatedMetadata = "nfsexport.storage.kubernetes.io/export-propagated-metadata"
+)
+
+// GetPropagatedMetadataKeys returns the annotation and label keys listed in
+// the parameters of a nfsexport class.
+func GetPropagatedMetadataKeys(nfsexportClassParams map[string]string) (annotations []string, labels []string, err error) {
+	annotations, err = parsePropagatedKeys(nfsexportClassParams, PrefixedExportPropagatedAnnotationsKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	labels, err = parsePropagatedKeys(nfsexportClassParams, PrefixedExportPropagatedLabelsKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return annotations, labels, nil
+}
+
+func parsePropagatedKeys(nfsexportClassParams map[string]string, param string) ([]string, error) {
+	value, ok := nfsexportClassParams[param]
+	if !ok {
+		return nil, nil
+	}
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		key = strings.TrimSpace(key)
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid %s %q: key %q: %s", param, value, key, strings.Join(errs, ", "))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// GetPropagatedMetadataParameters returns the parameters to pass to the
+// driver for the annotations and labels of nfsexport selected by the
+// parameters of its class. Keys missing on nfsexport are skipped.
+func GetPropagatedMetadataParameters(nfsexport *crdv1.VolumeNfsExport, nfsexportClassParams map[string]string) (map[string]string, error) {
+	annotations, labels, err := GetPropagatedMetadataKeys(nfsexportClassParams)
+	if err != nil {
+		return nil, err
+	}
+	parameters := map[string]string{}
+	for _, key := range annotations {
+		if value, ok := nfsexport.Annotations[key]; ok {
+			parameters[PrefixedVolumeNfsExportAnnotationPrefix+key] = value
+		}
+	}
+	for _, key := range labels {
+		if value, ok := nfsexport.Labels[key]; ok {
+			parameters[PrefixedVolumeNfsExportLabelPrefix+key] = value
+		}
+	}
+	return parameters, nil
+}
+
+// EncodePropagatedMetadata returns the value of the
+// AnnExportPropagatedMetadata annotation for parameters.
+func EncodePropagatedMetadata(parameters map[string]string) (string, error) {
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GetPropagatedMetadataFromContent returns the parameters recorded in the
+// AnnExportPropagatedMetadata annotation of a content.
+func GetPropagatedMetadataFromContent(contentAnnotations map[string]string) (map[string]string, error) {
+	value, ok := contentAnnotations[AnnExportPropagatedMetadata]
+	if !ok {
+		return nil, nil
+	}
+	parameters := map[string]string{}
+	if err := json.Unmarshal([]byte(value), &parameters); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s: %v", AnnExportPropagatedMetadata, err)
+	}
+	for key := range parameters {
+		if !strings.HasPrefix(key, PrefixedVolumeNfsExportAnnotationPrefix) && !strings.HasPrefix(key, PrefixedVolumeNfsExportLabelPrefix) {
+			return nil, fmt.Errorf("invalid annotation %s: unexpected parameter %q", AnnExportPropagatedMetadata, key)
+		}
+	}
+	return parameters, nil
+}
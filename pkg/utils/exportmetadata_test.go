@@ -0,0 +1,103 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetPropagatedMetadataParameters(t *testing.T) {
+	nfsexport := &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "nfsexport",
+			Annotations: map[string]string{"example.com/ticket": "OPS-42", "owner": "team-a"},
+			Labels:      map[string]string{"app": "db"},
+		},
+	}
+	tests := []struct {
+		name      string
+		params    map[string]string
+		expected  map[string]string
+		expectErr bool
+	}{
+		{
+			name:     "nothing propagated",
+			params:   map[string]string{},
+			expected: map[string]string{},
+		},
+		{
+			name: "selected annotations and labels",
+			params: map[string]string{
+				PrefixedExportPropagatedAnnotationsKey: "example.com/ticket, missing",
+				PrefixedExportPropagatedLabelsKey:      "app",
+			},
+			expected: map[string]string{
+				"csi.storage.k8s.io/volumenfsexport/annotation/example.com/ticket": "OPS-42",
+				"csi.storage.k8s.io/volumenfsexport/label/app":                     "db",
+			},
+		},
+		{
+			name:      "invalid key",
+			params:    map[string]string{PrefixedExportPropagatedLabelsKey: "app,,owner"},
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		parameters, err := GetPropagatedMetadataParameters(nfsexport, test.params)
+		if test.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(parameters, test.expected) {
+			t.Errorf("%s: expected parameters %v, got %v", test.name, test.expected, parameters)
+		}
+	}
+}
+
+func TestGetPropagatedMetadataFromContent(t *testing.T) {
+	parameters := map[string]string{PrefixedVolumeNfsExportAnnotationPrefix + "owner": "team-a"}
+	value, err := EncodePropagatedMetadata(parameters)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := GetPropagatedMetadataFromContent(map[string]string{AnnExportPropagatedMetadata: value})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, parameters) {
+		t.Errorf("expected parameters %v, got %v", parameters, got)
+	}
+
+	if got, err := GetPropagatedMetadataFromContent(map[string]string{}); got != nil || err != nil {
+		t.Errorf("expected no parameters for a content without annotation, got %v, %v", got, err)
+	}
+	for _, invalid := range []string{"{", `{"csi.storage.k8s.io/export-anonuid": "0"}`} {
+		if _, err := GetPropagatedMetadataFromContent(map[string]string{AnnExportPropagatedMetadata: invalid}); err == nil {
+			t.Errorf("expected an error for annotation %s", invalid)
+		}
+	}
+}
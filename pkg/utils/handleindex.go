@@ -0,0 +1,68 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sort"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ContentHandleIndex is the name of an index of a VolumeNfsExportContent
+// informer keyed by the driver and the nfsexport handle of pre-provisioned
+// contents, see ContentHandleKey.
+const ContentHandleIndex = "nfsexportHandle"
+
+// ContentHandleKey returns the key of the ContentHandleIndex for the given
+// driver and nfsexport handle.
+func ContentHandleKey(driver, nfsexportHandle string) string {
+	return driver + "/" + nfsexportHandle
+}
+
+// ContentHandleIndexFunc indexes a pre-provisioned content by its driver and
+// nfsexport handle. Dynamically provisioned contents are not indexed.
+func ContentHandleIndexFunc(obj interface{}) ([]string, error) {
+	content, ok := obj.(*crdv1.VolumeNfsExportContent)
+	if !ok || content.Spec.Source.NfsExportHandle == nil {
+		return nil, nil
+	}
+	return []string{ContentHandleKey(content.Spec.Driver, *content.Spec.Source.NfsExportHandle)}, nil
+}
+
+// GetDuplicateHandleContents returns, sorted, the names of the other
+// pre-provisioned contents in indexer with the same driver and nfsexport
+// handle as content. The indexer must have the ContentHandleIndex.
+func GetDuplicateHandleContents(indexer cache.Indexer, content *crdv1.VolumeNfsExportContent) ([]string, error) {
+	if content.Spec.Source.NfsExportHandle == nil {
+		return nil, nil
+	}
+	objs, err := indexer.ByIndex(ContentHandleIndex, ContentHandleKey(content.Spec.Driver, *content.Spec.Source.NfsExportHandle))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, obj := range objs {
+		other, ok := obj.(*crdv1.VolumeNfsExportContent)
+		if !ok || other.Name == content.Name {
+			continue
+		}
+		names = append(names, other.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
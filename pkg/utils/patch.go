@@ -3,13 +3,26 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
 	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+// finalizerPatchBackoff bounds the retries of a finalizer patch which failed
+// because the object has been modified concurrently.
+var finalizerPatchBackoff = wait.Backoff{
+	Duration: 10 * time.Millisecond,
+	Factor:   2,
+	Jitter:   0.1,
+	Steps:    5,
+}
+
 // PatchOp represents a json patch operation
 type PatchOp struct {
 	Op    string      `json:"op"`
@@ -17,6 +30,18 @@ type PatchOp struct {
 	Value interface{} `json:"value,omitempty"`
 }
 
+// Field managers of the controllers. The status of each object is owned by a
+// single controller, which writes it with server-side apply:
+//   - the common controller owns the status of volume nfsexports,
+//   - the sidecar owns the status of volume nfsexport contents.
+//
+// A controller writing an object from a stale copy then never resets the
+// fields owned by the other controller.
+const (
+	CommonControllerFieldManager = "nfsexport-controller"
+	SidecarFieldManager          = "csi-nfsexporter"
+)
+
 // PatchVolumeNfsExportContent patches a volume nfsexport content object
 func PatchVolumeNfsExportContent(
 	existingNfsExportContent *crdv1.VolumeNfsExportContent,
@@ -56,3 +81,348 @@ func PatchVolumeNfsExport(
 
 	return newNfsExport, nil
 }
+
+// PatchVolumeNfsExportClass patches a volume nfsexport class object
+func PatchVolumeNfsExportClass(
+	existingNfsExportClass *crdv1.VolumeNfsExportClass,
+	patch []PatchOp,
+	client clientset.Interface,
+	subresources ...string,
+) (*crdv1.VolumeNfsExportClass, error) {
+	data, err := json.Marshal(patch)
+	if nil != err {
+		return existingNfsExportClass, err
+	}
+
+	newNfsExportClass, err := client.NfsExportV1().VolumeNfsExportClasses().Patch(context.TODO(), existingNfsExportClass.Name, types.JSONPatchType, data, metav1.PatchOptions{}, subresources...)
+	if err != nil {
+		return existingNfsExportClass, err
+	}
+
+	return newNfsExportClass, nil
+}
+
+// AddVolumeNfsExportFinalizers adds the given finalizers to a volume nfsexport
+// object with a JSON merge patch, see addFinalizersPatch. If the patch fails
+// because the object has been modified concurrently, the object is fetched
+// again and the patch is retried a bounded number of times.
+func AddVolumeNfsExportFinalizers(
+	existingNfsExport *crdv1.VolumeNfsExport,
+	client clientset.Interface,
+	finalizers ...string,
+) (*crdv1.VolumeNfsExport, error) {
+	nfsexport := existingNfsExport
+	var lastErr error
+	err := wait.ExponentialBackoff(finalizerPatchBackoff, func() (bool, error) {
+		data, err := addFinalizersPatch(nfsexport.ObjectMeta, finalizers)
+		if err != nil || data == nil {
+			return true, err
+		}
+		newNfsExport, err := client.NfsExportV1().VolumeNfsExports(nfsexport.Namespace).Patch(context.TODO(), nfsexport.Name, types.MergePatchType, data, metav1.PatchOptions{})
+		if err == nil {
+			nfsexport = newNfsExport
+			return true, nil
+		}
+		if !isPatchConflict(err) {
+			return false, err
+		}
+		lastErr = err
+		nfsexport, err = client.NfsExportV1().VolumeNfsExports(existingNfsExport.Namespace).Get(context.TODO(), existingNfsExport.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		err = fmt.Errorf("failed to add finalizers after %d attempts: %v", finalizerPatchBackoff.Steps, lastErr)
+	}
+	if err != nil {
+		return existingNfsExport, err
+	}
+	return nfsexport, nil
+}
+
+// AddVolumeNfsExportContentFinalizers adds the given finalizers to a volume
+// nfsexport content object with a JSON merge patch, see addFinalizersPatch. If
+// the patch fails because the object has been modified concurrently, the
+// object is fetched again and the patch is retried a bounded number of times.
+func AddVolumeNfsExportContentFinalizers(
+	existingNfsExportContent *crdv1.VolumeNfsExportContent,
+	client clientset.Interface,
+	finalizers ...string,
+) (*crdv1.VolumeNfsExportContent, error) {
+	content := existingNfsExportContent
+	var lastErr error
+	err := wait.ExponentialBackoff(finalizerPatchBackoff, func() (bool, error) {
+		data, err := addFinalizersPatch(content.ObjectMeta, finalizers)
+		if err != nil || data == nil {
+			return true, err
+		}
+		newContent, err := client.NfsExportV1().VolumeNfsExportContents().Patch(context.TODO(), content.Name, types.MergePatchType, data, metav1.PatchOptions{})
+		if err == nil {
+			content = newContent
+			return true, nil
+		}
+		if !isPatchConflict(err) {
+			return false, err
+		}
+		lastErr = err
+		content, err = client.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), existingNfsExportContent.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		err = fmt.Errorf("failed to add finalizers after %d attempts: %v", finalizerPatchBackoff.Steps, lastErr)
+	}
+	if err != nil {
+		return existingNfsExportContent, err
+	}
+	return content, nil
+}
+
+// RemoveVolumeNfsExportFinalizers removes the given finalizers from a volume
+// nfsexport object with a JSON patch. If the patch fails because the object has
+// been modified concurrently, the object is fetched again and the patch is
+// retried a bounded number of times.
+func RemoveVolumeNfsExportFinalizers(
+	existingNfsExport *crdv1.VolumeNfsExport,
+	client clientset.Interface,
+	finalizers ...string,
+) (*crdv1.VolumeNfsExport, error) {
+	nfsexport := existingNfsExport
+	var lastErr error
+	err := wait.ExponentialBackoff(finalizerPatchBackoff, func() (bool, error) {
+		patch := removeFinalizersPatch(nfsexport.ObjectMeta.Finalizers, finalizers)
+		if len(patch) == 0 {
+			return true, nil
+		}
+		newNfsExport, err := PatchVolumeNfsExport(nfsexport, patch, client)
+		if err == nil {
+			nfsexport = newNfsExport
+			return true, nil
+		}
+		if !isPatchConflict(err) {
+			return false, err
+		}
+		lastErr = err
+		nfsexport, err = client.NfsExportV1().VolumeNfsExports(existingNfsExport.Namespace).Get(context.TODO(), existingNfsExport.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		err = fmt.Errorf("failed to remove finalizers after %d attempts: %v", finalizerPatchBackoff.Steps, lastErr)
+	}
+	if err != nil {
+		return existingNfsExport, err
+	}
+	return nfsexport, nil
+}
+
+// RemoveVolumeNfsExportContentFinalizers removes the given finalizers from a
+// volume nfsexport content object with a JSON patch. If the patch fails because
+// the object has been modified concurrently, the object is fetched again and
+// the patch is retried a bounded number of times.
+func RemoveVolumeNfsExportContentFinalizers(
+	existingNfsExportContent *crdv1.VolumeNfsExportContent,
+	client clientset.Interface,
+	finalizers ...string,
+) (*crdv1.VolumeNfsExportContent, error) {
+	content := existingNfsExportContent
+	var lastErr error
+	err := wait.ExponentialBackoff(finalizerPatchBackoff, func() (bool, error) {
+		patch := removeFinalizersPatch(content.ObjectMeta.Finalizers, finalizers)
+		if len(patch) == 0 {
+			return true, nil
+		}
+		newContent, err := PatchVolumeNfsExportContent(content, patch, client)
+		if err == nil {
+			content = newContent
+			return true, nil
+		}
+		if !isPatchConflict(err) {
+			return false, err
+		}
+		lastErr = err
+		content, err = client.NfsExportV1().VolumeNfsExportContents().Get(context.TODO(), existingNfsExportContent.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return false, nil
+	})
+	if err == wait.ErrWaitTimeout {
+		err = fmt.Errorf("failed to remove finalizers after %d attempts: %v", finalizerPatchBackoff.Steps, lastErr)
+	}
+	if err != nil {
+		return existingNfsExportContent, err
+	}
+	return content, nil
+}
+
+// removeFinalizersPatch returns JSON patch operations removing the given
+// finalizers from the current list of finalizers. Every removal is preceded
+// by a test operation, so that the patch is rejected instead of removing a
+// wrong entry if the list has changed in the meantime. Entries are removed
+// from the end of the list so that the indexes of the remaining ones stay valid.
+func removeFinalizersPatch(current []string, finalizers []string) []PatchOp {
+	var patch []PatchOp
+	for i := len(current) - 1; i >= 0; i-- {
+		if !ContainsString(finalizers, current[i]) {
+			continue
+		}
+		path := fmt.Sprintf("/metadata/finalizers/%d", i)
+		patch = append(patch,
+			PatchOp{
+				Op:    "test",
+				Path:  path,
+				Value: current[i],
+			},
+			PatchOp{
+				Op:   "remove",
+				Path: path,
+			})
+	}
+	return patch
+}
+
+// addFinalizersPatch returns a JSON merge patch appending the missing given
+// finalizers to the finalizers of an object, or nil if none is missing. A
+// merge patch replaces the whole list, so it works the same whether the list
+// is empty or missing, unlike a JSON patch adding to /metadata/finalizers/-.
+// The resourceVersion of the object is a precondition of the patch: if the
+// object has changed in the meantime, e.g. another finalizer was added, the
+// API server rejects the patch with a conflict instead of dropping the
+// change. CRDs do not support strategic merge patches, which would merge the
+// list instead.
+func addFinalizersPatch(objectMeta metav1.ObjectMeta, finalizers []string) ([]byte, error) {
+	updated := append([]string{}, objectMeta.Finalizers...)
+	for _, finalizer := range finalizers {
+		if !ContainsString(updated, finalizer) {
+			updated = append(updated, finalizer)
+		}
+	}
+	if len(updated) == len(objectMeta.Finalizers) {
+		return nil, nil
+	}
+	metadata := map[string]interface{}{"finalizers": updated}
+	if objectMeta.ResourceVersion != "" {
+		metadata["resourceVersion"] = objectMeta.ResourceVersion
+	}
+	return json.Marshal(map[string]interface{}{"metadata": metadata})
+}
+
+// isPatchConflict returns true if a patch failed because the object has been
+// modified concurrently. The API server rejects a JSON patch with a failed
+// test operation as an invalid request.
+func isPatchConflict(err error) bool {
+	return apierrs.IsConflict(err) || apierrs.IsInvalid(err)
+}
+
+// ApplyVolumeNfsExportContentStatus applies status as the status of a volume
+// nfsexport content owned by fieldManager. Fields owned by fieldManager that
+// are not set in status are removed, fields owned by other managers are left
+// untouched. The status is computed from existingNfsExportContent, so its
+// resourceVersion is a precondition: if the content has been modified since
+// it was read, the API server rejects the apply with a conflict instead of
+// writing a status computed from a stale copy.
+func ApplyVolumeNfsExportContentStatus(
+	existingNfsExportContent *crdv1.VolumeNfsExportContent,
+	status *crdv1.VolumeNfsExportContentStatus,
+	client clientset.Interface,
+	fieldManager string,
+) (*crdv1.VolumeNfsExportContent, error) {
+	if status == nil {
+		status = &crdv1.VolumeNfsExportContentStatus{}
+	}
+	data, err := statusApplyPatch("VolumeNfsExportContent", existingNfsExportContent.ObjectMeta, existingNfsExportContent.ResourceVersion, status)
+	if err != nil {
+		return existingNfsExportContent, err
+	}
+
+	newNfsExportContent, err := client.NfsExportV1().VolumeNfsExportContents().Patch(context.TODO(), existingNfsExportContent.Name, types.ApplyPatchType, data, applyOptions(fieldManager), "status")
+	if err != nil {
+		return existingNfsExportContent, err
+	}
+
+	return newNfsExportContent, nil
+}
+
+// ApplyVolumeNfsExportStatus applies status as the status of a volume
+// nfsexport owned by fieldManager, see ApplyVolumeNfsExportContentStatus.
+func ApplyVolumeNfsExportStatus(
+	existingNfsExport *crdv1.VolumeNfsExport,
+	status *crdv1.VolumeNfsExportStatus,
+	client clientset.Interface,
+	fieldManager string,
+) (*crdv1.VolumeNfsExport, error) {
+	return applyVolumeNfsExportStatus(existingNfsExport, "", status, client, fieldManager)
+}
+
+// ApplyVolumeNfsExportStatusIfUnmodified is ApplyVolumeNfsExportStatus with
+// the resourceVersion of existingNfsExport as a precondition: if the volume
+// nfsexport has been modified since existingNfsExport was read, e.g. because
+// it was read from a stale informer cache, the API server rejects the apply
+// with a conflict instead of writing a status computed from a stale copy.
+func ApplyVolumeNfsExportStatusIfUnmodified(
+	existingNfsExport *crdv1.VolumeNfsExport,
+	status *crdv1.VolumeNfsExportStatus,
+	client clientset.Interface,
+	fieldManager string,
+) (*crdv1.VolumeNfsExport, error) {
+	return applyVolumeNfsExportStatus(existingNfsExport, existingNfsExport.ResourceVersion, status, client, fieldManager)
+}
+
+func applyVolumeNfsExportStatus(
+	existingNfsExport *crdv1.VolumeNfsExport,
+	resourceVersion string,
+	status *crdv1.VolumeNfsExportStatus,
+	client clientset.Interface,
+	fieldManager string,
+) (*crdv1.VolumeNfsExport, error) {
+	if status == nil {
+		status = &crdv1.VolumeNfsExportStatus{}
+	}
+	data, err := statusApplyPatch("VolumeNfsExport", existingNfsExport.ObjectMeta, resourceVersion, status)
+	if err != nil {
+		return existingNfsExport, err
+	}
+
+	newNfsExport, err := client.NfsExportV1().VolumeNfsExports(existingNfsExport.Namespace).Patch(context.TODO(), existingNfsExport.Name, types.ApplyPatchType, data, applyOptions(fieldManager), "status")
+	if err != nil {
+		return existingNfsExport, err
+	}
+
+	return newNfsExport, nil
+}
+
+// statusApplyPatch returns the server-side apply patch of the status of an
+// object. It identifies the object by kind, name and namespace only, so that
+// the field manager does not take ownership of its metadata or spec. A non
+// empty resourceVersion is a precondition of the patch.
+func statusApplyPatch(kind string, objectMeta metav1.ObjectMeta, resourceVersion string, status interface{}) ([]byte, error) {
+	metadata := map[string]string{"name": objectMeta.Name}
+	if objectMeta.Namespace != "" {
+		metadata["namespace"] = objectMeta.Namespace
+	}
+	if resourceVersion != "" {
+		metadata["resourceVersion"] = resourceVersion
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": crdv1.SchemeGroupVersion.String(),
+		"kind":       kind,
+		"metadata":   metadata,
+		"status":     status,
+	})
+}
+
+// applyOptions returns the options of a server-side apply by fieldManager.
+// The apply is forced: the status is only written by its owner, a conflict
+// means the fields were last written before the status was owned, e.g. by an
+// update of a previous version of the controller.
+func applyOptions(fieldManager string) metav1.PatchOptions {
+	force := true
+	return metav1.PatchOptions{FieldManager: fieldManager, Force: &force}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2022 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientsetfake "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned/fake"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	core "k8s.io/client-go/testing"
+)
+
+func TestRemoveFinalizersPatch(t *testing.T) {
+	testcases := []struct {
+		name       string
+		current    []string
+		finalizers []string
+		expected   []PatchOp
+	}{
+		{
+			name:       "no finalizers",
+			current:    nil,
+			finalizers: []string{VolumeNfsExportBoundFinalizer},
+			expected:   nil,
+		},
+		{
+			name:       "finalizer not present",
+			current:    []string{"foo"},
+			finalizers: []string{VolumeNfsExportBoundFinalizer},
+			expected:   nil,
+		},
+		{
+			name:       "remove a single finalizer",
+			current:    []string{"foo", VolumeNfsExportBoundFinalizer, "bar"},
+			finalizers: []string{VolumeNfsExportBoundFinalizer},
+			expected: []PatchOp{
+				{Op: "test", Path: "/metadata/finalizers/1", Value: VolumeNfsExportBoundFinalizer},
+				{Op: "remove", Path: "/metadata/finalizers/1"},
+			},
+		},
+		{
+			name:       "remove several finalizers from the end of the list first",
+			current:    []string{VolumeNfsExportAsSourceFinalizer, "foo", VolumeNfsExportBoundFinalizer},
+			finalizers: []string{VolumeNfsExportAsSourceFinalizer, VolumeNfsExportBoundFinalizer},
+			expected: []PatchOp{
+				{Op: "test", Path: "/metadata/finalizers/2", Value: VolumeNfsExportBoundFinalizer},
+				{Op: "remove", Path: "/metadata/finalizers/2"},
+				{Op: "test", Path: "/metadata/finalizers/0", Value: VolumeNfsExportAsSourceFinalizer},
+				{Op: "remove", Path: "/metadata/finalizers/0"},
+			},
+		},
+	}
+	for _, tc := range testcases {
+		got := removeFinalizersPatch(tc.current, tc.finalizers)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%s: expected patch %+v, got %+v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestAddFinalizersPatch(t *testing.T) {
+	testcases := []struct {
+		name            string
+		current         []string
+		resourceVersion string
+		finalizers      []string
+		expected        string
+	}{
+		{
+			name:            "missing finalizers",
+			current:         nil,
+			resourceVersion: "3",
+			finalizers:      []string{VolumeNfsExportBoundFinalizer},
+			expected:        `{"metadata":{"finalizers":["nfsexport.storage.kubernetes.io/volumenfsexport-bound-protection"],"resourceVersion":"3"}}`,
+		},
+		{
+			name:            "empty finalizers",
+			current:         []string{},
+			resourceVersion: "3",
+			finalizers:      []string{VolumeNfsExportBoundFinalizer},
+			expected:        `{"metadata":{"finalizers":["nfsexport.storage.kubernetes.io/volumenfsexport-bound-protection"],"resourceVersion":"3"}}`,
+		},
+		{
+			name:            "append to other finalizers",
+			current:         []string{"foo"},
+			resourceVersion: "3",
+			finalizers:      []string{VolumeNfsExportAsSourceFinalizer, VolumeNfsExportBoundFinalizer},
+			expected:        `{"metadata":{"finalizers":["foo","nfsexport.storage.kubernetes.io/volumenfsexport-as-source-protection","nfsexport.storage.kubernetes.io/volumenfsexport-bound-protection"],"resourceVersion":"3"}}`,
+		},
+		{
+			name:            "only missing finalizers are added",
+			current:         []string{VolumeNfsExportBoundFinalizer},
+			resourceVersion: "3",
+			finalizers:      []string{VolumeNfsExportBoundFinalizer, VolumeNfsExportAsSourceFinalizer},
+			expected:        `{"metadata":{"finalizers":["nfsexport.storage.kubernetes.io/volumenfsexport-bound-protection","nfsexport.storage.kubernetes.io/volumenfsexport-as-source-protection"],"resourceVersion":"3"}}`,
+		},
+		{
+			name:       "no precondition without resource version",
+			current:    nil,
+			finalizers: []string{VolumeNfsExportBoundFinalizer},
+			expected:   `{"metadata":{"finalizers":["nfsexport.storage.kubernetes.io/volumenfsexport-bound-protection"]}}`,
+		},
+		{
+			name:            "all finalizers present",
+			current:         []string{VolumeNfsExportBoundFinalizer},
+			resourceVersion: "3",
+			finalizers:      []string{VolumeNfsExportBoundFinalizer},
+		},
+		{
+			name:            "no finalizers to add",
+			current:         nil,
+			resourceVersion: "3",
+		},
+	}
+	for _, tc := range testcases {
+		objectMeta := metav1.ObjectMeta{Finalizers: tc.current, ResourceVersion: tc.resourceVersion}
+		data, err := addFinalizersPatch(objectMeta, tc.finalizers)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if string(data) != tc.expected {
+			t.Errorf("%s: expected patch %s, got %s", tc.name, tc.expected, data)
+		}
+	}
+}
+
+func TestAddVolumeNfsExportContentFinalizersRetry(t *testing.T) {
+	content := &crdv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "content1", ResourceVersion: "1"},
+	}
+	client := clientsetfake.NewSimpleClientset(content)
+	// The first patch conflicts with a finalizer added concurrently.
+	concurrent := content.DeepCopy()
+	concurrent.Finalizers = []string{"foo"}
+	concurrent.ResourceVersion = "2"
+	var patches []string
+	client.PrependReactor("patch", "volumenfsexportcontents", func(action core.Action) (bool, runtime.Object, error) {
+		patch := action.(core.PatchAction)
+		if patch.GetPatchType() != types.MergePatchType {
+			t.Errorf("expected a merge patch, got %s", patch.GetPatchType())
+		}
+		patches = append(patches, string(patch.GetPatch()))
+		if len(patches) == 1 {
+			if err := client.Tracker().Update(crdv1.SchemeGroupVersion.WithResource("volumenfsexportcontents"), concurrent, ""); err != nil {
+				t.Fatal(err)
+			}
+			return true, nil, apierrs.NewConflict(crdv1.Resource("volumenfsexportcontents"), "content1", errors.New("the object has been modified"))
+		}
+		return false, nil, nil
+	})
+
+	updated, err := AddVolumeNfsExportContentFinalizers(content, client, VolumeNfsExportContentFinalizer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedPatches := []string{
+		`{"metadata":{"finalizers":["nfsexport.storage.kubernetes.io/volumenfsexportcontent-bound-protection"],"resourceVersion":"1"}}`,
+		`{"metadata":{"finalizers":["foo","nfsexport.storage.kubernetes.io/volumenfsexportcontent-bound-protection"],"resourceVersion":"2"}}`,
+	}
+	if !reflect.DeepEqual(patches, expectedPatches) {
+		t.Errorf("expected patches %v, got %v", expectedPatches, patches)
+	}
+	if expected := []string{"foo", VolumeNfsExportContentFinalizer}; !reflect.DeepEqual(updated.Finalizers, expected) {
+		t.Errorf("expected finalizers %v, got %v", expected, updated.Finalizers)
+	}
+}
+
+func TestStatusApplyPatch(t *testing.T) {
+	ready := true
+	objectMeta := metav1.ObjectMeta{
+		Name:            "snap1",
+		Namespace:       "default",
+		ResourceVersion: "3",
+		Labels:          map[string]string{"foo": "bar"},
+		Finalizers:      []string{VolumeNfsExportBoundFinalizer},
+	}
+	data, err := statusApplyPatch("VolumeNfsExport", objectMeta, "", &crdv1.VolumeNfsExportStatus{ReadyToUse: &ready})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Only the status is applied, the metadata just identifies the object.
+	expected := `{"apiVersion":"nfsexport.storage.k8s.io/v1","kind":"VolumeNfsExport","metadata":{"name":"snap1","namespace":"default"},"status":{"readyToUse":true}}`
+	if string(data) != expected {
+		t.Errorf("expected patch %s, got %s", expected, data)
+	}
+
+	// The resourceVersion is only set as a precondition.
+	data, err = statusApplyPatch("VolumeNfsExport", objectMeta, objectMeta.ResourceVersion, &crdv1.VolumeNfsExportStatus{ReadyToUse: &ready})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `{"apiVersion":"nfsexport.storage.k8s.io/v1","kind":"VolumeNfsExport","metadata":{"name":"snap1","namespace":"default","resourceVersion":"3"},"status":{"readyToUse":true}}`
+	if string(data) != expected {
+		t.Errorf("expected patch %s, got %s", expected, data)
+	}
+}
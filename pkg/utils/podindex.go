@@ -0,0 +1,69 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	v1 "k8s.io/api/core/v1"
+)
+
+// PodClaimIndex is the name of an index of a Pod informer keyed by the
+// namespace and name of the PersistentVolumeClaims a pod uses, see
+// PodClaimKey.
+const PodClaimIndex = "claim"
+
+// PodClaimKey returns the key of the PodClaimIndex for the PVC claimName in
+// namespace.
+func PodClaimKey(namespace, claimName string) string {
+	return namespace + "/" + claimName
+}
+
+// PodClaimIndexFunc indexes a pod by the PVCs of its volumes. Pods without
+// PVC volumes are not indexed.
+func PodClaimIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok {
+		return nil, nil
+	}
+	var keys []string
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			keys = append(keys, PodClaimKey(pod.Namespace, volume.PersistentVolumeClaim.ClaimName))
+		}
+	}
+	return keys, nil
+}
+
+// PodQuiesceRequestIndex is the name of an index of a Pod informer keyed by
+// the namespace of a pod and the UID of the VolumeNfsExport it is asked to
+// quiesce for, see AnnQuiesceRequest.
+const PodQuiesceRequestIndex = "quiesceRequest"
+
+// PodQuiesceRequestKey returns the key of the PodQuiesceRequestIndex for the
+// VolumeNfsExport with the given UID in namespace.
+func PodQuiesceRequestKey(namespace, nfsexportUID string) string {
+	return namespace + "/" + nfsexportUID
+}
+
+// PodQuiesceRequestIndexFunc indexes a pod by its quiesce request. Pods
+// without a quiesce request are not indexed.
+func PodQuiesceRequestIndexFunc(obj interface{}) ([]string, error) {
+	pod, ok := obj.(*v1.Pod)
+	if !ok || pod.Annotations[AnnQuiesceRequest] == "" {
+		return nil, nil
+	}
+	return []string{PodQuiesceRequestKey(pod.Namespace, pod.Annotations[AnnQuiesceRequest])}, nil
+}
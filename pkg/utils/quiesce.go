@@ -0,0 +1,132 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PrefixedExportConsistencyKey is a nfsexport class parameter choosing the
+	// consistency of its PointInTime exports, ExportConsistencyCrash by
+	// default. Live exports are not affected.
+	PrefixedExportConsistencyKey = csiParameterPrefix + "export-consistency"
+	// ExportConsistencyCrash exports the source volume as it is, like after a
+	// crash of the workloads using it.
+	ExportConsistencyCrash = "CrashConsistent"
+	// ExportConsistencyApplication asks the pods using the source PVC to
+	// quiesce before the export is created, see AnnQuiesceRequest.
+	ExportConsistencyApplication = "ApplicationConsistent"
+
+	// PrefixedExportQuiesceTimeoutKey is a nfsexport class parameter setting
+	// how long the nfsexport controller waits for the pods to acknowledge a
+	// quiesce request, DefaultExportQuiesceTimeout by default. The export is
+	// crash consistent when it times out.
+	PrefixedExportQuiesceTimeoutKey = csiParameterPrefix + "export-quiesce-timeout"
+	DefaultExportQuiesceTimeout     = time.Minute
+
+	// AnnQuiesceRequest annotation is set by the nfsexport controller on the
+	// running pods using the source PVC of an application consistent
+	// nfsexport, before the export is created. Its value is the UID of the
+	// nfsexport. The workload flushes and holds its writes, acknowledges with
+	// AnnQuiesceAck, and resumes once the annotation is removed, which happens
+	// when the export has been cut. Workloads should also resume on their own
+	// after the quiesce timeout of the class.
+	AnnQuiesceRequest = "nfsexport.storage.kubernetes.io/quiesce-request"
+	// AnnQuiesceAck annotation is set by a workload on its pod, to the value
+	// of AnnQuiesceRequest, once it is quiesced.
+	AnnQuiesceAck = "nfsexport.storage.kubernetes.io/quiesce-ack"
+
+	// AnnQuiesceRequestedAt annotation is set by the nfsexport controller on
+	// a nfsexport whose source pods were asked to quiesce. It records the
+	// time of the request, in RFC 3339 format, to enforce the timeout.
+	AnnQuiesceRequestedAt = "nfsexport.storage.kubernetes.io/quiesce-requested-at"
+
+	// AnnExportConsistency annotation applies to VolumeNfsExportContents of
+	// application consistent nfsexports. It records the consistency actually
+	// achieved: ExportConsistencyApplication if all pods acknowledged the
+	// quiesce request, ExportConsistencyCrash if the request timed out.
+	AnnExportConsistency = "nfsexport.storage.kubernetes.io/export-consistency"
+)
+
+// GetExportConsistency returns the consistency requested by the parameters
+// of a nfsexport class for its PointInTime exports.
+func GetExportConsistency(nfsexportClassParams map[string]string) (string, error) {
+	consistency, ok := nfsexportClassParams[PrefixedExportConsistencyKey]
+	if !ok {
+		return ExportConsistencyCrash, nil
+	}
+	if consistency != ExportConsistencyCrash && consistency != ExportConsistencyApplication {
+		return "", fmt.Errorf("invalid %s %q, supported values are %q and %q", PrefixedExportConsistencyKey, consistency, ExportConsistencyCrash, ExportConsistencyApplication)
+	}
+	return consistency, nil
+}
+
+// IsQuiesceRequested returns true if the source pods of nfsexport must be
+// asked to quiesce before its export is created with the given class
+// parameters.
+func IsQuiesceRequested(nfsexport *crdv1.VolumeNfsExport, nfsexportClassParams map[string]string) (bool, error) {
+	if nfsexport.Spec.Mode == nil || *nfsexport.Spec.Mode != crdv1.VolumeNfsExportModePointInTime {
+		return false, nil
+	}
+	consistency, err := GetExportConsistency(nfsexportClassParams)
+	if err != nil {
+		return false, err
+	}
+	return consistency == ExportConsistencyApplication, nil
+}
+
+// GetExportQuiesceTimeout returns the quiesce timeout set in the parameters
+// of a nfsexport class.
+func GetExportQuiesceTimeout(nfsexportClassParams map[string]string) (time.Duration, error) {
+	value, ok := nfsexportClassParams[PrefixedExportQuiesceTimeoutKey]
+	if !ok {
+		return DefaultExportQuiesceTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", PrefixedExportQuiesceTimeoutKey, value, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be positive", PrefixedExportQuiesceTimeoutKey, value)
+	}
+	return timeout, nil
+}
+
+// FindRunningPodsUsingClaim returns, sorted by name, the running pods that
+// mount the PVC claimName.
+func FindRunningPodsUsingClaim(pods []v1.Pod, claimName string) []*v1.Pod {
+	var found []*v1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || pod.Status.Phase != v1.PodRunning {
+			continue
+		}
+		if claimVolumeName(pod, claimName) != "" {
+			found = append(found, pod)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].Name < found[j].Name
+	})
+	return found
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsQuiesceRequested(t *testing.T) {
+	pointInTime := crdv1.VolumeNfsExportModePointInTime
+	live := crdv1.VolumeNfsExportModeLive
+	application := map[string]string{PrefixedExportConsistencyKey: ExportConsistencyApplication}
+
+	tests := []struct {
+		name        string
+		mode        *crdv1.VolumeNfsExportMode
+		params      map[string]string
+		expected    bool
+		expectError bool
+	}{
+		{
+			name:   "crash consistent by default",
+			mode:   &pointInTime,
+			params: map[string]string{},
+		},
+		{
+			name:     "application consistent PointInTime export",
+			mode:     &pointInTime,
+			params:   application,
+			expected: true,
+		},
+		{
+			name:   "live exports are not quiesced",
+			mode:   &live,
+			params: application,
+		},
+		{
+			name:   "mode not set",
+			params: application,
+		},
+		{
+			name:        "invalid consistency",
+			mode:        &pointInTime,
+			params:      map[string]string{PrefixedExportConsistencyKey: "Consistent"},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		nfsexport := &crdv1.VolumeNfsExport{Spec: crdv1.VolumeNfsExportSpec{Mode: test.mode}}
+		requested, err := IsQuiesceRequested(nfsexport, test.params)
+		if test.expectError != (err != nil) {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.expectError, err)
+		}
+		if requested != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, requested)
+		}
+	}
+}
+
+func TestGetExportQuiesceTimeout(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{value: "", expected: DefaultExportQuiesceTimeout},
+		{value: "30s", expected: 30 * time.Second},
+		{value: "0s", expectError: true},
+		{value: "-1m", expectError: true},
+		{value: "soon", expectError: true},
+	}
+
+	for _, test := range tests {
+		params := map[string]string{}
+		if test.value != "" {
+			params[PrefixedExportQuiesceTimeoutKey] = test.value
+		}
+		timeout, err := GetExportQuiesceTimeout(params)
+		if test.expectError != (err != nil) {
+			t.Errorf("%q: expected error %v, got %v", test.value, test.expectError, err)
+		}
+		if timeout != test.expected {
+			t.Errorf("%q: expected %v, got %v", test.value, test.expected, timeout)
+		}
+	}
+}
+
+func TestFindRunningPodsUsingClaim(t *testing.T) {
+	deleting := newPodUsingClaim("deleting", "pvc1", v1.PodRunning)
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	pods := []v1.Pod{
+		newPodUsingClaim("b", "pvc1", v1.PodRunning),
+		newPodUsingClaim("pending", "pvc1", v1.PodPending),
+		deleting,
+		newPodUsingClaim("other", "pvc2", v1.PodRunning),
+		newPodUsingClaim("a", "pvc1", v1.PodRunning),
+	}
+
+	found := FindRunningPodsUsingClaim(pods, "pvc1")
+	var names []string
+	for _, pod := range found {
+		names = append(names, pod.Name)
+	}
+	if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("expected pods [a b], got %v", names)
+	}
+}
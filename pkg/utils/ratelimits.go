@@ -0,0 +1,120 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+)
+
+// ReloadableRateLimits are the QPS and burst of API clients that can be
+// changed while the clients are running, e.g. when a config file is
+// reloaded. Each client gets a rate limiter of its own, as it would from the
+// QPS and burst of its rest.Config.
+type ReloadableRateLimits struct {
+	lock     sync.Mutex
+	qps      float32
+	burst    int
+	limiters []*rate.Limiter
+}
+
+// NewReloadableRateLimits returns rate limits with the given QPS and burst,
+// which must be greater than 0.
+func NewReloadableRateLimits(qps float32, burst int) (*ReloadableRateLimits, error) {
+	if err := validateRateLimits(qps, burst); err != nil {
+		return nil, err
+	}
+	return &ReloadableRateLimits{qps: qps, burst: burst}, nil
+}
+
+// validateRateLimits returns an error for a QPS or burst of 0 or less, with
+// which a token bucket would throttle the clients forever.
+func validateRateLimits(qps float32, burst int) error {
+	if qps <= 0 || burst <= 0 {
+		return fmt.Errorf("QPS and burst must be greater than 0, got %v and %d", qps, burst)
+	}
+	return nil
+}
+
+// ClientConfig returns the config of a single API client of a subsystem,
+// see SubsystemConfig. Unless the subsystem has its own QPS, the client is
+// throttled by a new rate limiter following the QPS and burst of l.
+func (l *ReloadableRateLimits) ClientConfig(config *rest.Config, subsystemQPS float32) *rest.Config {
+	if subsystemQPS > 0 {
+		return config
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	limiter := rate.NewLimiter(rate.Limit(l.qps), l.burst)
+	l.limiters = append(l.limiters, limiter)
+
+	clientConfig := rest.CopyConfig(config)
+	clientConfig.QPS = l.qps
+	clientConfig.Burst = l.burst
+	clientConfig.RateLimiter = &reloadableRateLimiter{limiter: limiter}
+	return clientConfig
+}
+
+// Set changes the QPS and burst of all the clients of l.
+func (l *ReloadableRateLimits) Set(qps float32, burst int) error {
+	if err := validateRateLimits(qps, burst); err != nil {
+		return err
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.qps = qps
+	l.burst = burst
+	now := time.Now()
+	for _, limiter := range l.limiters {
+		limiter.SetLimitAt(now, rate.Limit(qps))
+		limiter.SetBurstAt(now, burst)
+	}
+	return nil
+}
+
+// reloadableRateLimiter is a token bucket rate limiter, like the one of
+// flowcontrol.NewTokenBucketRateLimiter, whose QPS and burst can be changed.
+type reloadableRateLimiter struct {
+	limiter *rate.Limiter
+}
+
+var _ flowcontrol.RateLimiter = &reloadableRateLimiter{}
+
+func (r *reloadableRateLimiter) TryAccept() bool {
+	return r.limiter.Allow()
+}
+
+func (r *reloadableRateLimiter) Accept() {
+	time.Sleep(r.limiter.Reserve().Delay())
+}
+
+func (r *reloadableRateLimiter) Stop() {
+}
+
+func (r *reloadableRateLimiter) QPS() float32 {
+	return float32(r.limiter.Limit())
+}
+
+func (r *reloadableRateLimiter) Wait(ctx context.Context) error {
+	return r.limiter.Wait(ctx)
+}
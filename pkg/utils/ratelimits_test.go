@@ -0,0 +1,85 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestReloadableRateLimits(t *testing.T) {
+	config := &rest.Config{Host: "https://example.com", QPS: 5, Burst: 10}
+	limits, err := NewReloadableRateLimits(5, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := limits.ClientConfig(config, 20); got != config {
+		t.Errorf("expected the config of a subsystem with its own QPS to be left untouched")
+	}
+
+	first := limits.ClientConfig(config, 0)
+	second := limits.ClientConfig(config, 0)
+	if first.RateLimiter == nil || first.RateLimiter == second.RateLimiter {
+		t.Fatalf("expected each client to get its own rate limiter")
+	}
+	if config.RateLimiter != nil {
+		t.Errorf("expected the original config to be left untouched")
+	}
+
+	// The whole burst is available, then the bucket is empty.
+	for i := 0; i < 10; i++ {
+		if !first.RateLimiter.TryAccept() {
+			t.Fatalf("expected request %d of the burst to be accepted", i)
+		}
+	}
+	if first.RateLimiter.TryAccept() {
+		t.Errorf("expected a request beyond the burst to be throttled")
+	}
+
+	if err := limits.Set(50, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, clientConfig := range []*rest.Config{first, second} {
+		if qps := clientConfig.RateLimiter.QPS(); qps != 50 {
+			t.Errorf("expected QPS 50 after reload, got %v", qps)
+		}
+	}
+	if third := limits.ClientConfig(config, 0); third.QPS != 50 || third.Burst != 100 {
+		t.Errorf("expected a new client with QPS 50 and burst 100, got %v and %d", third.QPS, third.Burst)
+	}
+
+	if err := limits.Set(0, 100); err == nil {
+		t.Errorf("expected an error for a QPS of 0")
+	}
+}
+
+func TestNewReloadableRateLimitsRejectsNonPositive(t *testing.T) {
+	for _, test := range []struct {
+		qps   float32
+		burst int
+	}{
+		{qps: 0, burst: 10},
+		{qps: -1, burst: 10},
+		{qps: 5, burst: 0},
+	} {
+		if _, err := NewReloadableRateLimits(test.qps, test.burst); err == nil {
+			t.Errorf("expected an error for QPS %v and burst %d", test.qps, test.burst)
+		}
+	}
+}
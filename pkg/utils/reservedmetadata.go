@@ -0,0 +1,65 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// ReservedMetadataPrefix is the prefix of the labels and annotations of
+// VolumeNfsExports and VolumeNfsExportContents managed by the nfsexport
+// controller and the csi-nfsexporter sidecar, e.g. the managed-by and invalid
+// labels or the being-created annotation. Manual edits of these keys confuse
+// the controllers.
+const ReservedMetadataPrefix = "nfsexport.storage.kubernetes.io/"
+
+// userSettableMetadataKeys are the keys with the ReservedMetadataPrefix that
+// users set themselves.
+var userSettableMetadataKeys = map[string]bool{
+	AnnSkipValidation:             true,
+	AnnBackupInclude:              true,
+	AnnVolumeNfsExportRebindTo:    true,
+	AnnDeletionSecretRefName:      true,
+	AnnDeletionSecretRefNamespace: true,
+}
+
+// IsReservedMetadataKey returns true if the label or annotation key of a
+// VolumeNfsExport or VolumeNfsExportContent may only be changed by the
+// nfsexport controller and the csi-nfsexporter sidecar.
+func IsReservedMetadataKey(key string) bool {
+	return strings.HasPrefix(key, ReservedMetadataPrefix) && !userSettableMetadataKeys[key]
+}
+
+// GetChangedReservedMetadataKeys returns, sorted, the reserved keys added,
+// removed or changed between the labels or annotations oldMetadata and
+// newMetadata.
+func GetChangedReservedMetadataKeys(oldMetadata, newMetadata map[string]string) []string {
+	var keys []string
+	for key, value := range newMetadata {
+		if oldValue, ok := oldMetadata[key]; (!ok || oldValue != value) && IsReservedMetadataKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range oldMetadata {
+		if _, ok := newMetadata[key]; !ok && IsReservedMetadataKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetChangedReservedMetadataKeys(t *testing.T) {
+	tests := []struct {
+		name        string
+		oldMetadata map[string]string
+		newMetadata map[string]string
+		expected    []string
+	}{
+		{
+			name:        "unchanged",
+			oldMetadata: map[string]string{VolumeNfsExportContentManagedByLabel: "node1"},
+			newMetadata: map[string]string{VolumeNfsExportContentManagedByLabel: "node1"},
+		},
+		{
+			name:        "added, changed and removed",
+			oldMetadata: map[string]string{VolumeNfsExportContentManagedByLabel: "node1", AnnVolumeNfsExportBeingCreated: "yes"},
+			newMetadata: map[string]string{VolumeNfsExportContentManagedByLabel: "node2", VolumeNfsExportInvalidLabel: ""},
+			expected:    []string{VolumeNfsExportInvalidLabel, VolumeNfsExportContentManagedByLabel, AnnVolumeNfsExportBeingCreated},
+		},
+		{
+			name:        "user settable and other keys",
+			oldMetadata: map[string]string{"app": "db"},
+			newMetadata: map[string]string{"app": "web", AnnSkipValidation: "true", AnnVolumeNfsExportRebindTo: "default/snap1", AnnBackupInclude: "true"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys := GetChangedReservedMetadataKeys(test.oldMetadata, test.newMetadata)
+			if !reflect.DeepEqual(keys, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// DeprecatedResyncPeriodFlag is the name of the single resync period flag
+// that was split into a flag per resource.
+const DeprecatedResyncPeriodFlag = "resync-period"
+
+// ResyncPeriod returns the value of the resync period flag name of fs. If
+// that flag is not set but the deprecated --resync-period flag is, the value
+// of the deprecated flag is returned instead, so that existing deployments
+// keep their resync period. 0 disables the periodic resync: the informers
+// request watch bookmarks, so they stay up to date without relisting.
+// Negative periods are rejected.
+func ResyncPeriod(fs *flag.FlagSet, name string) (time.Duration, error) {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	flagName := name
+	if !set[name] && set[DeprecatedResyncPeriodFlag] {
+		klog.Warningf("--%s is deprecated, use --%s instead", DeprecatedResyncPeriodFlag, name)
+		flagName = DeprecatedResyncPeriodFlag
+	}
+	f := fs.Lookup(flagName)
+	if f == nil {
+		return 0, fmt.Errorf("flag --%s is not defined", flagName)
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return 0, fmt.Errorf("flag --%s is not a duration", flagName)
+	}
+	period, ok := getter.Get().(time.Duration)
+	if !ok {
+		return 0, fmt.Errorf("flag --%s is not a duration", flagName)
+	}
+	if period < 0 {
+		return 0, fmt.Errorf("--%s must not be negative, got %v", flagName, period)
+	}
+	return period, nil
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"flag"
+	"testing"
+	"time"
+)
+
+func TestResyncPeriod(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    time.Duration
+		expectError bool
+	}{
+		{
+			name:     "default",
+			expected: 15 * time.Minute,
+		},
+		{
+			name:     "specific flag",
+			args:     []string{"--content-resync-period=1h"},
+			expected: time.Hour,
+		},
+		{
+			name:     "deprecated flag",
+			args:     []string{"--resync-period=5m"},
+			expected: 5 * time.Minute,
+		},
+		{
+			name:     "specific flag wins over deprecated flag",
+			args:     []string{"--resync-period=5m", "--content-resync-period=1h"},
+			expected: time.Hour,
+		},
+		{
+			name:     "disabled",
+			args:     []string{"--content-resync-period=0"},
+			expected: 0,
+		},
+		{
+			name:        "negative",
+			args:        []string{"--content-resync-period=-1m"},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := flag.NewFlagSet(test.name, flag.ContinueOnError)
+			fs.Duration(DeprecatedResyncPeriodFlag, 15*time.Minute, "")
+			fs.Duration("content-resync-period", 15*time.Minute, "")
+			if err := fs.Parse(test.args); err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+			period, err := ResyncPeriod(fs, "content-resync-period")
+			if test.expectError {
+				if err == nil {
+					t.Errorf("expected an error, got period %v", period)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if period != test.expected {
+				t.Errorf("expected period %v, got %v", test.expected, period)
+			}
+		})
+	}
+}
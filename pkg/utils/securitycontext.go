@@ -0,0 +1,200 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PrefixedExportSecurityContextSourceKey is a nfsexport class parameter
+	// that requests the security context of exported files to be aligned with
+	// the pods using the source PVC. The only supported value is
+	// ExportSecurityContextSourcePod.
+	PrefixedExportSecurityContextSourceKey = csiParameterPrefix + "export-security-context-source"
+	// ExportSecurityContextSourcePod derives the security context from a pod
+	// using the source PVC.
+	ExportSecurityContextSourcePod = "SourcePod"
+
+	// Parameters passed on CreateNfsExportRequest calls with the security
+	// context derived for the export.
+	PrefixedExportAnonUIDKey      = csiParameterPrefix + "export-anonuid"       // UID anonymous NFS clients are mapped to
+	PrefixedExportAnonGIDKey      = csiParameterPrefix + "export-anongid"       // GID anonymous NFS clients are mapped to
+	PrefixedExportSELinuxLabelKey = csiParameterPrefix + "export-selinux-label" // SELinux label of exported files
+
+	// Annotations recording the security context derived for a content by the
+	// nfsexport controller. The sidecar passes them to the driver as the
+	// corresponding prefixed parameters.
+	AnnExportAnonUID      = "nfsexport.storage.kubernetes.io/export-anonuid"
+	AnnExportAnonGID      = "nfsexport.storage.kubernetes.io/export-anongid"
+	AnnExportSELinuxLabel = "nfsexport.storage.kubernetes.io/export-selinux-label"
+
+	// Defaults for the parts of an SELinux label that pods usually leave
+	// empty in their seLinuxOptions.
+	defaultSELinuxUser = "system_u"
+	defaultSELinuxRole = "object_r"
+	defaultSELinuxType = "container_file_t"
+)
+
+// exportSecurityContextParameters maps the content annotations to the
+// parameters passed to the driver.
+var exportSecurityContextParameters = map[string]string{
+	AnnExportAnonUID:      PrefixedExportAnonUIDKey,
+	AnnExportAnonGID:      PrefixedExportAnonGIDKey,
+	AnnExportSELinuxLabel: PrefixedExportSELinuxLabelKey,
+}
+
+// IsExportSecurityContextFromPodRequested returns true if the parameters of a
+// nfsexport class request the security context of exported files to be
+// derived from the pods using the source PVC.
+func IsExportSecurityContextFromPodRequested(nfsexportClassParams map[string]string) (bool, error) {
+	source, ok := nfsexportClassParams[PrefixedExportSecurityContextSourceKey]
+	if !ok {
+		return false, nil
+	}
+	if source != ExportSecurityContextSourcePod {
+		return false, fmt.Errorf("invalid %s %q, the only supported value is %q", PrefixedExportSecurityContextSourceKey, source, ExportSecurityContextSourcePod)
+	}
+	return true, nil
+}
+
+// FindPodUsingClaim returns the pod whose security context is used for the
+// exports of the given PVC: the first running pod by name that mounts the
+// PVC, or the first pending one if none is running. It returns nil if no pod
+// mounts the PVC.
+func FindPodUsingClaim(pods []v1.Pod, claimName string) *v1.Pod {
+	var candidates []*v1.Pod
+	for i := range pods {
+		pod := &pods[i]
+		if pod.DeletionTimestamp != nil || (pod.Status.Phase != v1.PodRunning && pod.Status.Phase != v1.PodPending) {
+			continue
+		}
+		if claimVolumeName(pod, claimName) != "" {
+			candidates = append(candidates, pod)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if running := candidates[i].Status.Phase == v1.PodRunning; running != (candidates[j].Status.Phase == v1.PodRunning) {
+			return running
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[0]
+}
+
+// GetExportSecurityContextFromPod derives the security context of exported
+// files from a pod using the PVC claimName, as annotations for the content.
+// The security context of the first container mounting the PVC takes
+// precedence over the one of the pod. The anonymous GID is the fsGroup of the
+// pod if set, as files written through the PVC are owned by it, and the
+// runAsGroup otherwise.
+func GetExportSecurityContextFromPod(pod *v1.Pod, claimName string) map[string]string {
+	var uid, gid *int64
+	var seLinuxOptions *v1.SELinuxOptions
+	if psc := pod.Spec.SecurityContext; psc != nil {
+		uid, gid, seLinuxOptions = psc.RunAsUser, psc.RunAsGroup, psc.SELinuxOptions
+	}
+	if csc := getClaimContainerSecurityContext(pod, claimName); csc != nil {
+		if csc.RunAsUser != nil {
+			uid = csc.RunAsUser
+		}
+		if csc.RunAsGroup != nil {
+			gid = csc.RunAsGroup
+		}
+		if csc.SELinuxOptions != nil {
+			seLinuxOptions = csc.SELinuxOptions
+		}
+	}
+	if psc := pod.Spec.SecurityContext; psc != nil && psc.FSGroup != nil {
+		gid = psc.FSGroup
+	}
+
+	annotations := map[string]string{}
+	if uid != nil {
+		annotations[AnnExportAnonUID] = strconv.FormatInt(*uid, 10)
+	}
+	if gid != nil {
+		annotations[AnnExportAnonGID] = strconv.FormatInt(*gid, 10)
+	}
+	if label := seLinuxLabel(seLinuxOptions); label != "" {
+		annotations[AnnExportSELinuxLabel] = label
+	}
+	return annotations
+}
+
+// GetExportSecurityContextParameters returns the parameters to pass to the
+// driver for the security context annotations of a content.
+func GetExportSecurityContextParameters(contentAnnotations map[string]string) map[string]string {
+	parameters := map[string]string{}
+	for ann, param := range exportSecurityContextParameters {
+		if value, ok := contentAnnotations[ann]; ok {
+			parameters[param] = value
+		}
+	}
+	return parameters
+}
+
+// claimVolumeName returns the name of the pod volume referencing the PVC, or
+// an empty string if the pod does not use the PVC.
+func claimVolumeName(pod *v1.Pod, claimName string) string {
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claimName {
+			return volume.Name
+		}
+	}
+	return ""
+}
+
+// getClaimContainerSecurityContext returns the security context of the first
+// container of the pod that mounts the PVC.
+func getClaimContainerSecurityContext(pod *v1.Pod, claimName string) *v1.SecurityContext {
+	volumeName := claimVolumeName(pod, claimName)
+	for _, container := range pod.Spec.Containers {
+		for _, mount := range container.VolumeMounts {
+			if mount.Name == volumeName {
+				return container.SecurityContext
+			}
+		}
+	}
+	return nil
+}
+
+// seLinuxLabel formats SELinux options as a label. Options without a level
+// do not identify the files of a workload and yield no label.
+func seLinuxLabel(options *v1.SELinuxOptions) string {
+	if options == nil || options.Level == "" {
+		return ""
+	}
+	user, role, typ := options.User, options.Role, options.Type
+	if user == "" {
+		user = defaultSELinuxUser
+	}
+	if role == "" {
+		role = defaultSELinuxRole
+	}
+	if typ == "" {
+		typ = defaultSELinuxType
+	}
+	return fmt.Sprintf("%s:%s:%s:%s", user, role, typ, options.Level)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPodUsingClaim(name, claimName string, phase v1.PodPhase) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{{
+				Name: "data",
+				VolumeSource: v1.VolumeSource{
+					PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: claimName},
+				},
+			}},
+			Containers: []v1.Container{
+				{Name: "sidecar"},
+				{Name: "app", VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/data"}}},
+			},
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+func TestFindPodUsingClaim(t *testing.T) {
+	deleting := newPodUsingClaim("a-deleting", "pvc1", v1.PodRunning)
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+
+	tests := []struct {
+		name     string
+		pods     []v1.Pod
+		expected string
+	}{
+		{
+			name:     "no pods",
+			expected: "",
+		},
+		{
+			name: "running pod preferred over pending pod",
+			pods: []v1.Pod{
+				newPodUsingClaim("a-pending", "pvc1", v1.PodPending),
+				newPodUsingClaim("c-running", "pvc1", v1.PodRunning),
+				newPodUsingClaim("b-running", "pvc1", v1.PodRunning),
+			},
+			expected: "b-running",
+		},
+		{
+			name: "pending pod used if none is running",
+			pods: []v1.Pod{
+				newPodUsingClaim("a-succeeded", "pvc1", v1.PodSucceeded),
+				newPodUsingClaim("b-pending", "pvc1", v1.PodPending),
+			},
+			expected: "b-pending",
+		},
+		{
+			name: "deleting pods and pods of other claims are skipped",
+			pods: []v1.Pod{
+				deleting,
+				newPodUsingClaim("b-other", "pvc2", v1.PodRunning),
+			},
+			expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		pod := FindPodUsingClaim(test.pods, "pvc1")
+		name := ""
+		if pod != nil {
+			name = pod.Name
+		}
+		if name != test.expected {
+			t.Errorf("%s: expected pod %q, got %q", test.name, test.expected, name)
+		}
+	}
+}
+
+func TestGetExportSecurityContextFromPod(t *testing.T) {
+	uid, gid, fsGroup, containerUID := int64(1000), int64(2000), int64(3000), int64(1001)
+
+	tests := []struct {
+		name     string
+		pod      func() v1.Pod
+		expected map[string]string
+	}{
+		{
+			name:     "no security context",
+			pod:      func() v1.Pod { return newPodUsingClaim("pod1", "pvc1", v1.PodRunning) },
+			expected: map[string]string{},
+		},
+		{
+			name: "pod security context",
+			pod: func() v1.Pod {
+				pod := newPodUsingClaim("pod1", "pvc1", v1.PodRunning)
+				pod.Spec.SecurityContext = &v1.PodSecurityContext{
+					RunAsUser:      &uid,
+					RunAsGroup:     &gid,
+					SELinuxOptions: &v1.SELinuxOptions{Level: "s0:c1,c2"},
+				}
+				return pod
+			},
+			expected: map[string]string{
+				AnnExportAnonUID:      "1000",
+				AnnExportAnonGID:      "2000",
+				AnnExportSELinuxLabel: "system_u:object_r:container_file_t:s0:c1,c2",
+			},
+		},
+		{
+			name: "container security context and fsGroup take precedence",
+			pod: func() v1.Pod {
+				pod := newPodUsingClaim("pod1", "pvc1", v1.PodRunning)
+				pod.Spec.SecurityContext = &v1.PodSecurityContext{
+					RunAsUser:  &uid,
+					RunAsGroup: &gid,
+					FSGroup:    &fsGroup,
+				}
+				pod.Spec.Containers[1].SecurityContext = &v1.SecurityContext{
+					RunAsUser:      &containerUID,
+					SELinuxOptions: &v1.SELinuxOptions{Type: "spc_t", Level: "s0:c3,c4"},
+				}
+				return pod
+			},
+			expected: map[string]string{
+				AnnExportAnonUID:      "1001",
+				AnnExportAnonGID:      "3000",
+				AnnExportSELinuxLabel: "system_u:object_r:spc_t:s0:c3,c4",
+			},
+		},
+		{
+			name: "security context of containers not mounting the claim is ignored",
+			pod: func() v1.Pod {
+				pod := newPodUsingClaim("pod1", "pvc1", v1.PodRunning)
+				pod.Spec.Containers[0].SecurityContext = &v1.SecurityContext{RunAsUser: &containerUID}
+				return pod
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "SELinux options without level",
+			pod: func() v1.Pod {
+				pod := newPodUsingClaim("pod1", "pvc1", v1.PodRunning)
+				pod.Spec.SecurityContext = &v1.PodSecurityContext{SELinuxOptions: &v1.SELinuxOptions{Type: "spc_t"}}
+				return pod
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		pod := test.pod()
+		annotations := GetExportSecurityContextFromPod(&pod, "pvc1")
+		if !reflect.DeepEqual(annotations, test.expected) {
+			t.Errorf("%s: expected annotations %v, got %v", test.name, test.expected, annotations)
+		}
+		parameters := GetExportSecurityContextParameters(annotations)
+		if len(parameters) != len(annotations) {
+			t.Errorf("%s: expected %d parameters, got %v", test.name, len(annotations), parameters)
+		}
+	}
+}
+
+func TestIsExportSecurityContextFromPodRequested(t *testing.T) {
+	tests := []struct {
+		params    map[string]string
+		expected  bool
+		expectErr bool
+	}{
+		{params: map[string]string{}},
+		{params: map[string]string{PrefixedExportSecurityContextSourceKey: ExportSecurityContextSourcePod}, expected: true},
+		{params: map[string]string{PrefixedExportSecurityContextSourceKey: "sourcepod"}, expectErr: true},
+	}
+	for _, test := range tests {
+		requested, err := IsExportSecurityContextFromPodRequested(test.params)
+		if (err != nil) != test.expectErr || requested != test.expected {
+			t.Errorf("IsExportSecurityContextFromPodRequested(%v) = %v, %v, expected %v, error: %v", test.params, requested, err, test.expected, test.expectErr)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ControllerUpdateFailMsg starts the message of the errors returned by
+// NewControllerUpdateError.
+const ControllerUpdateFailMsg = "nfsexport controller failed to update"
+
+// TerminalUpdateErrorRetryDelay is the delay after which the workers retry
+// an object whose update failed with an error for which IsTerminalUpdateError
+// is true, instead of the short backoff of the other errors.
+const TerminalUpdateErrorRetryDelay = 5 * time.Minute
+
+var _ error = controllerUpdateError{}
+
+// controllerUpdateError is returned when a controller fails to save an
+// object to the API server. It wraps the error of the API server, so that the
+// workers can retry conflicts right away and back off from updates that are
+// refused as forbidden or invalid.
+type controllerUpdateError struct {
+	message string
+	err     error
+}
+
+// NewControllerUpdateError returns the error of a failed update of the
+// object name, wrapping the error err of the API server.
+func NewControllerUpdateError(name string, err error) error {
+	message := fmt.Sprintf("%s %s on API server: %v", ControllerUpdateFailMsg, name, err)
+	if reason := apierrs.ReasonForError(err); reason != metav1.StatusReasonUnknown {
+		message = fmt.Sprintf("%s %s on API server (%s): %v", ControllerUpdateFailMsg, name, reason, err)
+	}
+	return controllerUpdateError{
+		message: message,
+		err:     err,
+	}
+}
+
+func (e controllerUpdateError) Error() string {
+	return e.message
+}
+
+func (e controllerUpdateError) Unwrap() error {
+	return e.err
+}
+
+// IsTerminalUpdateError returns true if err was returned by
+// NewControllerUpdateError for an update refused by the API server as
+// forbidden or invalid. Such an update fails the same way when it is
+// retried, until the object or the RBAC rules of the controller change, so
+// it is retried after TerminalUpdateErrorRetryDelay.
+func IsTerminalUpdateError(err error) bool {
+	var updateErr controllerUpdateError
+	if !errors.As(err, &updateErr) {
+		return false
+	}
+	return apierrs.IsForbidden(updateErr.err) || apierrs.IsInvalid(updateErr.err)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+)
+
+func TestControllerUpdateError(t *testing.T) {
+	resource := crdv1.Resource("volumenfsexportcontents")
+	tests := []struct {
+		name           string
+		err            error
+		expectMessage  string
+		expectConflict bool
+		expectTerminal bool
+	}{
+		{
+			name:           "conflict is retried",
+			err:            apierrs.NewConflict(resource, "content1", fmt.Errorf("object was modified")),
+			expectMessage:  `nfsexport controller failed to update content1 on API server (Conflict): Operation cannot be fulfilled on volumenfsexportcontents.nfsexport.storage.k8s.io "content1": object was modified`,
+			expectConflict: true,
+		},
+		{
+			name:           "forbidden is terminal",
+			err:            apierrs.NewForbidden(resource, "content1", fmt.Errorf("denied")),
+			expectMessage:  `nfsexport controller failed to update content1 on API server (Forbidden): volumenfsexportcontents.nfsexport.storage.k8s.io "content1" is forbidden: denied`,
+			expectTerminal: true,
+		},
+		{
+			name:           "invalid is terminal",
+			err:            apierrs.NewInvalid(crdv1.SchemeGroupVersion.WithKind("VolumeNfsExportContent").GroupKind(), "content1", nil),
+			expectMessage:  `nfsexport controller failed to update content1 on API server (Invalid): VolumeNfsExportContent.nfsexport.storage.k8s.io "content1" is invalid`,
+			expectTerminal: true,
+		},
+		{
+			name:          "other errors are retried",
+			err:           fmt.Errorf("mock update error"),
+			expectMessage: "nfsexport controller failed to update content1 on API server: mock update error",
+		},
+	}
+
+	for _, test := range tests {
+		err := NewControllerUpdateError("content1", test.err)
+		if err.Error() != test.expectMessage {
+			t.Errorf("%s: expected message %q, got %q", test.name, test.expectMessage, err.Error())
+		}
+		if conflict := apierrs.IsConflict(err); conflict != test.expectConflict {
+			t.Errorf("%s: expected conflict %v, got %v", test.name, test.expectConflict, conflict)
+		}
+		if terminal := IsTerminalUpdateError(err); terminal != test.expectTerminal {
+			t.Errorf("%s: expected terminal %v, got %v", test.name, test.expectTerminal, terminal)
+		}
+		if IsTerminalUpdateError(test.err) {
+			t.Errorf("%s: expected an error not returned by an update not to be terminal", test.name)
+		}
+	}
+}
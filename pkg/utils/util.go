@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -62,6 +63,19 @@ const (
 	PrefixedVolumeNfsExportNamespaceKey   = csiParameterPrefix + "volumenfsexport/namespace"   // Prefixed VolumeNfsExport namespace key
 	PrefixedVolumeNfsExportContentNameKey = csiParameterPrefix + "volumenfsexportcontent/name" // Prefixed VolumeNfsExportContent name key
 
+	PrefixedExportPathHintPatternKey = csiParameterPrefix + "export-path-hint-pattern" // Prefixed key for the regular expression export path hints must match
+	PrefixedExportPathHintKey        = csiParameterPrefix + "export-path-hint"         // Prefixed export path hint key, passed on CreateNfsExportRequest calls
+
+	PrefixedExportZoneKey = csiParameterPrefix + "export-zone" // Prefixed key for the zone or region the exports of a class live in
+
+	PrefixedExportModesKey = csiParameterPrefix + "export-modes" // Prefixed key for the comma separated list of export modes a class supports
+	PrefixedExportModeKey  = csiParameterPrefix + "export-mode"  // Prefixed export mode key, passed on CreateNfsExportRequest calls
+
+	PrefixedExportWarmUpKey = csiParameterPrefix + "export-warm-up" // Prefixed key requesting the driver to warm exports up before they are ready, also passed on CreateNfsExportRequest calls
+
+	PrefixedExportCacheTiersKey = csiParameterPrefix + "export-cache-tiers" // Prefixed key for the comma separated list of cache tiers a class supports
+	PrefixedExportCacheTierKey  = csiParameterPrefix + "export-cache-tier"  // Prefixed cache tier key, passed on CreateNfsExportRequest calls and reported by the driver in the attributes with the tier it achieved
+
 	// Name of finalizer on VolumeNfsExportContents that are bound by VolumeNfsExports
 	VolumeNfsExportContentFinalizer = "nfsexport.storage.kubernetes.io/volumenfsexportcontent-bound-protection"
 	// Name of finalizer on VolumeNfsExport that is being used as a source to create a PVC
@@ -94,11 +108,70 @@ const (
 	// nfsexports.
 	AnnVolumeNfsExportBeingCreated = "nfsexport.storage.kubernetes.io/volumenfsexport-being-created"
 
+	// AnnVolumeNfsExportCreationTimestamp annotation applies to VolumeNfsExportContents.
+	// It records the creation timestamp of the VolumeNfsExport the content is
+	// bound to. Binding checks compare it in addition to the name, namespace and
+	// UID of the VolumeNfsExport, so that a VolumeNfsExport restored into the API
+	// server with a reused name and UID is not mistaken for the original one.
+	// Contents without this annotation are bound by name, namespace and UID only.
+	AnnVolumeNfsExportCreationTimestamp = "nfsexport.storage.kubernetes.io/volumenfsexport-creation-timestamp"
+
+	// AnnVolumeNfsExportRebindTo annotation applies to VolumeNfsExportContents.
+	// It is set by users on a content retained after its VolumeNfsExport was
+	// deleted, with the value <namespace>/<name> of a new VolumeNfsExport that
+	// refers to the content in Spec.Source.VolumeNfsExportContentName. The new
+	// VolumeNfsExport may be in another namespace than the deleted one, to hand
+	// the content over to another team. The common nfsexport controller then
+	// binds the content to the new VolumeNfsExport, removes the annotation and
+	// records the previous VolumeNfsExport in AnnVolumeNfsExportReboundFrom.
+	// Only contents with the Retain deletion policy are rebound.
+	AnnVolumeNfsExportRebindTo = "nfsexport.storage.kubernetes.io/rebind-to"
+
+	// AnnVolumeNfsExportReboundFrom annotation applies to
+	// VolumeNfsExportContents. It is set by the common nfsexport controller
+	// when it rebinds a content, with the value <namespace>/<name> of the
+	// VolumeNfsExport the content was bound to before.
+	AnnVolumeNfsExportReboundFrom = "nfsexport.storage.kubernetes.io/rebound-from"
+
+	// AnnSkipValidation annotation applies to VolumeNfsExports and
+	// VolumeNfsExportContents. If set to "true" by a user allowed the
+	// "skip-validation" verb on the resource, the validation webhook admits
+	// the object without validating it, for break-glass repairs.
+	AnnSkipValidation = "nfsexport.storage.kubernetes.io/skip-validation"
+
+	// AnnBackupInclude annotation applies to VolumeNfsExports. It is set to
+	// "true" by backup tools to include a VolumeNfsExport in their backups,
+	// see the backup package.
+	AnnBackupInclude = "nfsexport.storage.kubernetes.io/backup-include"
+
+	// AnnDeleteSourceWhenBound annotation applies to PersistentVolumeClaims
+	// provisioned from a VolumeNfsExport. If set to "true", the common
+	// nfsexport controller deletes the VolumeNfsExport once the claim is
+	// Bound, for exports taken only to be restored once. The export itself
+	// is kept or deleted according to the deletion policy of its content.
+	AnnDeleteSourceWhenBound = "nfsexport.storage.kubernetes.io/delete-source-when-bound"
+
 	// Annotation for secret name and namespace will be added to the content
 	// and used at nfsexport content deletion time.
 	AnnDeletionSecretRefName      = "nfsexport.storage.kubernetes.io/deletion-secret-name"
 	AnnDeletionSecretRefNamespace = "nfsexport.storage.kubernetes.io/deletion-secret-namespace"
 
+	// AnnSourceVolumeName and AnnSourceVolumeReclaimPolicy annotations apply
+	// to dynamically created VolumeNfsExportContents. They record the source
+	// PV of the content and its reclaim policy when the content was created,
+	// so that the deletion of the nfsexport of a Retain PV deleted in the
+	// meantime is reported, and can be traced when looking for lost data.
+	AnnSourceVolumeName          = "nfsexport.storage.kubernetes.io/source-volume-name"
+	AnnSourceVolumeReclaimPolicy = "nfsexport.storage.kubernetes.io/source-volume-reclaim-policy"
+
+	// AnnRequestedBy annotation applies to VolumeNfsExports and
+	// VolumeNfsExportContents. It records the name of the user who created
+	// the object or last changed its spec, set by the validation webhook run
+	// with --record-requesters. The common nfsexport controller copies it
+	// from a VolumeNfsExport to its dynamically created content, and the
+	// csi-nfsexporter sidecar reports it in its audit records.
+	AnnRequestedBy = "nfsexport.storage.kubernetes.io/requested-by"
+
 	// VolumeNfsExportContentInvalidLabel is applied to invalid content as a label key. The value does not matter.
 	// See https://github.com/kubernetes/enhancements/blob/master/keps/sig-storage/177-volume-nfsexport/tighten-validation-webhook-crd.md#automatic-labelling-of-invalid-objects
 	VolumeNfsExportContentInvalidLabel = "nfsexport.storage.kubernetes.io/invalid-nfsexport-content-resource"
@@ -108,6 +181,9 @@ const (
 	// VolumeNfsExportContentManagedByLabel is applied by the nfsexport controller to the VolumeNfsExportContent object in case distributed nfsexportting is enabled.
 	// The value contains the name of the node that handles the nfsexport for the volume local to that node.
 	VolumeNfsExportContentManagedByLabel = "nfsexport.storage.kubernetes.io/managed-by"
+	// VolumeNfsExportContentDriverLabel is applied by the nfsexport controller to every VolumeNfsExportContent object.
+	// The value contains the name of the CSI driver of the content, so that each sidecar only caches the contents of its own driver.
+	VolumeNfsExportContentDriverLabel = "nfsexport.storage.kubernetes.io/driver"
 )
 
 var NfsExportterSecretParams = secretParamsMap{
@@ -267,6 +343,65 @@ func verifyAndGetSecretNameAndNamespaceTemplate(secret secretParamsMap, nfsexpor
 	return "", "", fmt.Errorf("unknown error with getting secret name and namespace templates")
 }
 
+// GetSecretNamespaceTemplates verifies the secret references in the parameters
+// of a nfsexport class and returns the namespace templates of the referenced
+// secrets, keyed by the kind of secret, e.g. "NfsExportter".
+func GetSecretNamespaceTemplates(nfsexportClassParams map[string]string) (map[string]string, error) {
+	templates := map[string]string{}
+	for _, secretParams := range []secretParamsMap{NfsExportterSecretParams, NfsExportterListSecretParams} {
+		_, namespaceTemplate, err := verifyAndGetSecretNameAndNamespaceTemplate(secretParams, nfsexportClassParams)
+		if err != nil {
+			return nil, err
+		}
+		if namespaceTemplate != "" {
+			templates[secretParams.name] = namespaceTemplate
+		}
+	}
+	return templates, nil
+}
+
+// VolumeNfsExportNamespaceTemplate is the secret namespace template resolved to
+// the namespace of the VolumeNfsExport, so that the credentials of a tenant
+// are read from its own namespace.
+const VolumeNfsExportNamespaceTemplate = "${volumenfsexport.namespace}"
+
+// GetCrossNamespaceSecretErrors returns an error, keyed by parameter key, for
+// each secret reference in the parameters of a nfsexport class that lets the
+// user of a VolumeNfsExport read a secret of another namespace:
+//   - a name template using the name or the namespace of the VolumeNfsExport,
+//     which its user chooses, with another namespace template than
+//     ${volumenfsexport.namespace}
+//   - a namespace template combining ${volumenfsexport.namespace} with other
+//     text, which resolves to another namespace than the one of the
+//     VolumeNfsExport
+func GetCrossNamespaceSecretErrors(nfsexportClassParams map[string]string) map[string]error {
+	errs := map[string]error{}
+	for _, secretParams := range []secretParamsMap{NfsExportterSecretParams, NfsExportterListSecretParams} {
+		nameTemplate, namespaceTemplate, err := verifyAndGetSecretNameAndNamespaceTemplate(secretParams, nfsexportClassParams)
+		if err != nil || nameTemplate == "" {
+			continue
+		}
+		if namespaceTemplate != VolumeNfsExportNamespaceTemplate && templateTokens(namespaceTemplate).Has("volumenfsexport.namespace") {
+			errs[secretParams.secretNamespaceKey] = fmt.Errorf("%s secret namespace %q combines %s with other text and may resolve to the namespace of another tenant", secretParams.name, namespaceTemplate, VolumeNfsExportNamespaceTemplate)
+			continue
+		}
+		if namespaceTemplate != VolumeNfsExportNamespaceTemplate && templateTokens(nameTemplate).HasAny("volumenfsexport.name", "volumenfsexport.namespace") {
+			errs[secretParams.secretNameKey] = fmt.Errorf("%s secret name %q is chosen by the user of the VolumeNfsExport, who could read any secret of namespace %q", secretParams.name, nameTemplate, namespaceTemplate)
+		}
+	}
+	return errs
+}
+
+// templateTokens returns the tokens of a secret template.
+func templateTokens(template string) sets.String {
+	tokens := sets.NewString()
+	os.Expand(template, func(k string) string {
+		tokens.Insert(k)
+		return ""
+	})
+	return tokens
+}
+
 // getSecretReference returns a reference to the secret specified in the given nameTemplate
 //  and namespaceTemplate, or an error if the templates are not specified correctly.
 // No lookup of the referenced secret is performed, and the secret may or may not exist.
@@ -366,7 +501,7 @@ func GetCredentials(k8s kubernetes.Interface, ref *v1.SecretReference) (map[stri
 
 	secret, err := k8s.CoreV1().Secrets(ref.Namespace).Get(context.TODO(), ref.Name, metav1.GetOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("error getting secret %s in namespace %s: %v", ref.Name, ref.Namespace, err)
+		return nil, fmt.Errorf("error getting secret %s in namespace %s: %w", ref.Name, ref.Namespace, err)
 	}
 
 	credentials := map[string]string{}
@@ -413,6 +548,12 @@ func deprecationWarning(deprecatedParam, newParam, removalVersion string) string
 	return fmt.Sprintf("\"%s\" is deprecated and will be removed in %s%s", deprecatedParam, removalVersion, newParamPhrase)
 }
 
+// IsPrefixedParameter returns true if key is in the reserved
+// csi.storage.k8s.io/ namespace of the parameters.
+func IsPrefixedParameter(key string) bool {
+	return strings.HasPrefix(key, csiParameterPrefix)
+}
+
 func RemovePrefixedParameters(param map[string]string) (map[string]string, error) {
 	newParam := map[string]string{}
 	for k, v := range param {
@@ -423,6 +564,18 @@ func RemovePrefixedParameters(param map[string]string) (map[string]string, error
 			case PrefixedNfsExportterSecretNamespaceKey:
 			case PrefixedNfsExportterListSecretNameKey:
 			case PrefixedNfsExportterListSecretNamespaceKey:
+			case PrefixedExportPathHintPatternKey:
+			case PrefixedExportSecurityContextSourceKey:
+			case PrefixedExportConsistencyKey:
+			case PrefixedExportQuiesceTimeoutKey:
+			case PrefixedExportConsistencyGateKey:
+			case PrefixedExportConsistencyGateTimeoutKey:
+			case PrefixedExportZoneKey:
+			case PrefixedExportModesKey:
+			case PrefixedExportWarmUpKey:
+			case PrefixedExportCacheTiersKey:
+			case PrefixedExportPropagatedAnnotationsKey:
+			case PrefixedExportPropagatedLabelsKey:
 			default:
 				return map[string]string{}, fmt.Errorf("found unknown parameter key \"%s\" with reserved namespace %s", k, csiParameterPrefix)
 			}
@@ -435,6 +588,158 @@ func RemovePrefixedParameters(param map[string]string) (map[string]string, error
 	return newParam, nil
 }
 
+// GetExportPathHintPattern compiles the export path hint pattern set in the
+// parameters of a nfsexport class. It returns nil if the class does not set a
+// pattern. The pattern must match a hint as a whole.
+func GetExportPathHintPattern(nfsexportClassParams map[string]string) (*regexp.Regexp, error) {
+	pattern, ok := nfsexportClassParams[PrefixedExportPathHintPatternKey]
+	if !ok {
+		return nil, nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %v", PrefixedExportPathHintPatternKey, pattern, err)
+	}
+	return re, nil
+}
+
+// ValidateExportPathHint checks an export path hint against the pattern set
+// in the parameters of a nfsexport class. Classes without a pattern do not
+// allow hints.
+func ValidateExportPathHint(hint string, nfsexportClassParams map[string]string) error {
+	re, err := GetExportPathHintPattern(nfsexportClassParams)
+	if err != nil {
+		return err
+	}
+	if re == nil {
+		return fmt.Errorf("export path hint %q is not allowed: the nfsexport class does not set %s", hint, PrefixedExportPathHintPatternKey)
+	}
+	if !re.MatchString(hint) {
+		return fmt.Errorf("export path hint %q does not match %s %q", hint, PrefixedExportPathHintPatternKey, nfsexportClassParams[PrefixedExportPathHintPatternKey])
+	}
+	return nil
+}
+
+// IsExportWarmUpRequested returns true if the parameters of a nfsexport class
+// request the driver to pre-stage exports, e.g. hydrate them from a cold
+// tier, before they are ready.
+func IsExportWarmUpRequested(nfsexportClassParams map[string]string) (bool, error) {
+	value, ok := nfsexportClassParams[PrefixedExportWarmUpKey]
+	if !ok {
+		return false, nil
+	}
+	warmUp, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %v", PrefixedExportWarmUpKey, value, err)
+	}
+	return warmUp, nil
+}
+
+// GetSupportedExportModes returns the export modes listed in the parameters
+// of a nfsexport class. Classes without the parameter only support the Live
+// mode.
+func GetSupportedExportModes(nfsexportClassParams map[string]string) ([]crdv1.VolumeNfsExportMode, error) {
+	value, ok := nfsexportClassParams[PrefixedExportModesKey]
+	if !ok {
+		return []crdv1.VolumeNfsExportMode{crdv1.VolumeNfsExportModeLive}, nil
+	}
+	var modes []crdv1.VolumeNfsExportMode
+	for _, mode := range strings.Split(value, ",") {
+		switch m := crdv1.VolumeNfsExportMode(strings.TrimSpace(mode)); m {
+		case crdv1.VolumeNfsExportModeLive, crdv1.VolumeNfsExportModePointInTime:
+			modes = append(modes, m)
+		default:
+			return nil, fmt.Errorf("invalid %s %q: unknown mode %q, supported modes are %q and %q", PrefixedExportModesKey, value, m, crdv1.VolumeNfsExportModeLive, crdv1.VolumeNfsExportModePointInTime)
+		}
+	}
+	return modes, nil
+}
+
+// GetExportMode returns the export mode set in the spec of a nfsexport or
+// content, or the default Live mode if it is not set.
+func GetExportMode(mode *crdv1.VolumeNfsExportMode) crdv1.VolumeNfsExportMode {
+	if mode == nil {
+		return crdv1.VolumeNfsExportModeLive
+	}
+	return *mode
+}
+
+// ValidateExportMode checks that the export mode is supported by a nfsexport
+// class.
+func ValidateExportMode(mode crdv1.VolumeNfsExportMode, nfsexportClassParams map[string]string) error {
+	modes, err := GetSupportedExportModes(nfsexportClassParams)
+	if err != nil {
+		return err
+	}
+	for _, m := range modes {
+		if m == mode {
+			return nil
+		}
+	}
+	if _, ok := nfsexportClassParams[PrefixedExportModesKey]; !ok {
+		return fmt.Errorf("export mode %q is not supported: the nfsexport class does not set %s and only supports %q", mode, PrefixedExportModesKey, crdv1.VolumeNfsExportModeLive)
+	}
+	return fmt.Errorf("export mode %q is not supported: %s of the nfsexport class is %q", mode, PrefixedExportModesKey, nfsexportClassParams[PrefixedExportModesKey])
+}
+
+// GetSupportedExportCacheTiers returns the cache tiers listed in the
+// parameters of a nfsexport class. Classes without the parameter support no
+// cache tier.
+func GetSupportedExportCacheTiers(nfsexportClassParams map[string]string) ([]crdv1.VolumeNfsExportCacheTier, error) {
+	value, ok := nfsexportClassParams[PrefixedExportCacheTiersKey]
+	if !ok {
+		return nil, nil
+	}
+	var tiers []crdv1.VolumeNfsExportCacheTier
+	for _, tier := range strings.Split(value, ",") {
+		switch t := crdv1.VolumeNfsExportCacheTier(strings.TrimSpace(tier)); t {
+		case crdv1.VolumeNfsExportCacheTierNodeLocal:
+			tiers = append(tiers, t)
+		default:
+			return nil, fmt.Errorf("invalid %s %q: unknown cache tier %q, the supported cache tier is %q", PrefixedExportCacheTiersKey, value, t, crdv1.VolumeNfsExportCacheTierNodeLocal)
+		}
+	}
+	return tiers, nil
+}
+
+// ValidateExportCacheTier checks that the cache tier is supported by a
+// nfsexport class.
+func ValidateExportCacheTier(tier crdv1.VolumeNfsExportCacheTier, nfsexportClassParams map[string]string) error {
+	tiers, err := GetSupportedExportCacheTiers(nfsexportClassParams)
+	if err != nil {
+		return err
+	}
+	for _, t := range tiers {
+		if t == tier {
+			return nil
+		}
+	}
+	if _, ok := nfsexportClassParams[PrefixedExportCacheTiersKey]; !ok {
+		return fmt.Errorf("cache tier %q is not supported: the nfsexport class does not set %s", tier, PrefixedExportCacheTiersKey)
+	}
+	return fmt.Errorf("cache tier %q is not supported: %s of the nfsexport class is %q", tier, PrefixedExportCacheTiersKey, nfsexportClassParams[PrefixedExportCacheTiersKey])
+}
+
+// GetExportPathFromHandle returns the export path of a nfsexport handle in the
+// form server:/path, or an empty string for other handles.
+func GetExportPathFromHandle(handle string) string {
+	i := strings.Index(handle, ":/")
+	if i <= 0 {
+		return ""
+	}
+	return handle[i+1:]
+}
+
+// GetExportServerFromHandle returns the server of a nfsexport handle in the
+// form server:/path, or an empty string for other handles.
+func GetExportServerFromHandle(handle string) string {
+	i := strings.Index(handle, ":/")
+	if i <= 0 {
+		return ""
+	}
+	return handle[:i]
+}
+
 // Stateless functions
 func GetNfsExportStatusForLogging(nfsexport *crdv1.VolumeNfsExport) string {
 	nfsexportContentName := ""
@@ -451,12 +756,38 @@ func GetNfsExportStatusForLogging(nfsexport *crdv1.VolumeNfsExport) string {
 func IsVolumeNfsExportRefSet(nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) bool {
 	if content.Spec.VolumeNfsExportRef.Name == nfsexport.Name &&
 		content.Spec.VolumeNfsExportRef.Namespace == nfsexport.Namespace &&
-		content.Spec.VolumeNfsExportRef.UID == nfsexport.UID {
+		content.Spec.VolumeNfsExportRef.UID == nfsexport.UID &&
+		IsVolumeNfsExportCreationTimestampMatched(nfsexport, content) {
 		return true
 	}
 	return false
 }
 
+// GetNfsExportCreationTimestampForContent returns the value of the
+// AnnVolumeNfsExportCreationTimestamp annotation to set on a content bound to
+// the given nfsexport, or an empty string if the nfsexport has no creation timestamp.
+func GetNfsExportCreationTimestampForContent(nfsexport *crdv1.VolumeNfsExport) string {
+	if nfsexport.CreationTimestamp.IsZero() {
+		return ""
+	}
+	return nfsexport.CreationTimestamp.UTC().Format(time.RFC3339)
+}
+
+// IsVolumeNfsExportCreationTimestampMatched returns false if the content records
+// a creation timestamp of its VolumeNfsExport which differs from the creation
+// timestamp of the given nfsexport. It returns true if either one is unknown.
+func IsVolumeNfsExportCreationTimestampMatched(nfsexport *crdv1.VolumeNfsExport, content *crdv1.VolumeNfsExportContent) bool {
+	recorded, ok := content.ObjectMeta.Annotations[AnnVolumeNfsExportCreationTimestamp]
+	if !ok || recorded == "" {
+		return true
+	}
+	current := GetNfsExportCreationTimestampForContent(nfsexport)
+	if current == "" {
+		return true
+	}
+	return recorded == current
+}
+
 func IsBoundVolumeNfsExportContentNameSet(nfsexport *crdv1.VolumeNfsExport) bool {
 	if nfsexport.Status == nil || nfsexport.Status.BoundVolumeNfsExportContentName == nil || *nfsexport.Status.BoundVolumeNfsExportContentName == "" {
 		return false
@@ -475,3 +806,60 @@ func IsNfsExportReady(nfsexport *crdv1.VolumeNfsExport) bool {
 func IsNfsExportCreated(nfsexport *crdv1.VolumeNfsExport) bool {
 	return nfsexport.Status != nil && nfsexport.Status.CreationTime != nil
 }
+
+// IsVolumeNfsExportErrorEqual returns true if both errors are nil, or if both
+// are set with the same message. The time of the errors is ignored, as it is
+// refreshed every time the same error is reported again and would otherwise
+// cause a status update on every sync.
+func IsVolumeNfsExportErrorEqual(a, b *crdv1.VolumeNfsExportError) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if a.Message == nil || b.Message == nil {
+		return a.Message == b.Message
+	}
+	return *a.Message == *b.Message
+}
+
+// maxErrorSummaryLength is the number of characters of an error message kept
+// in the errorSummary status field of a VolumeNfsExport.
+const maxErrorSummaryLength = 60
+
+// GetVolumeNfsExportErrorSummary returns the first characters of the message
+// of the error, as shown in the Error printer column of VolumeNfsExports, or
+// nil if there is no error message.
+func GetVolumeNfsExportErrorSummary(err *crdv1.VolumeNfsExportError) *string {
+	if err == nil || err.Message == nil {
+		return nil
+	}
+	summary := *err.Message
+	if runes := []rune(summary); len(runes) > maxErrorSummaryLength {
+		summary = string(runes[:maxErrorSummaryLength])
+	}
+	return &summary
+}
+
+// CSIPersistentVolumeTransform returns an informer transform function that
+// keeps CSI PersistentVolumes of the given drivers, or of all CSI drivers if
+// none are given, and strips every other PersistentVolume down to its object
+// metadata. This keeps the memory cost of a PV informer low in clusters with
+// many unrelated volumes.
+func CSIPersistentVolumeTransform(drivers []string) cache.TransformFunc {
+	driverSet := sets.NewString(drivers...)
+	return func(obj interface{}) (interface{}, error) {
+		pv, ok := obj.(*v1.PersistentVolume)
+		if !ok {
+			return obj, nil
+		}
+		if pv.Spec.CSI != nil && (driverSet.Len() == 0 || driverSet.Has(pv.Spec.CSI.Driver)) {
+			return pv, nil
+		}
+		return &v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            pv.Name,
+				UID:             pv.UID,
+				ResourceVersion: pv.ResourceVersion,
+			},
+		}, nil
+	}
+}
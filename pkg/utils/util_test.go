@@ -18,11 +18,14 @@ package utils
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestContainsString(t *testing.T) {
@@ -149,6 +152,63 @@ func TestGetSecretReference(t *testing.T) {
 	}
 }
 
+func TestGetCrossNamespaceSecretErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       map[string]string
+		expectedKeys []string
+	}{
+		{
+			name: "fixed secret",
+			params: map[string]string{
+				PrefixedNfsExportterSecretNameKey:      "secret",
+				PrefixedNfsExportterSecretNamespaceKey: "kube-system",
+			},
+		},
+		{
+			name: "secret of the namespace of the nfsexport",
+			params: map[string]string{
+				PrefixedNfsExportterSecretNameKey:      "${volumenfsexport.name}-creds",
+				PrefixedNfsExportterSecretNamespaceKey: "${volumenfsexport.namespace}",
+			},
+		},
+		{
+			name: "secret per content",
+			params: map[string]string{
+				PrefixedNfsExportterSecretNameKey:      "${volumenfsexportcontent.name}",
+				PrefixedNfsExportterSecretNamespaceKey: "kube-system",
+			},
+		},
+		{
+			name: "name chosen by the user of the nfsexport",
+			params: map[string]string{
+				PrefixedNfsExportterSecretNameKey:      "${volumenfsexport.name}",
+				PrefixedNfsExportterSecretNamespaceKey: "kube-system",
+			},
+			expectedKeys: []string{PrefixedNfsExportterSecretNameKey},
+		},
+		{
+			name: "namespace derived from the namespace of the nfsexport",
+			params: map[string]string{
+				PrefixedNfsExportterListSecretNameKey:      "creds",
+				PrefixedNfsExportterListSecretNamespaceKey: "${volumenfsexport.namespace}-secrets",
+			},
+			expectedKeys: []string{PrefixedNfsExportterListSecretNamespaceKey},
+		},
+	}
+
+	for _, test := range tests {
+		errs := GetCrossNamespaceSecretErrors(test.params)
+		keys := []string{}
+		for key := range errs {
+			keys = append(keys, key)
+		}
+		if len(keys) != len(test.expectedKeys) || len(keys) > 0 && !reflect.DeepEqual(keys, test.expectedKeys) {
+			t.Errorf("%s: expected errors for %v, got %v", test.name, test.expectedKeys, errs)
+		}
+	}
+}
+
 func TestRemovePrefixedCSIParams(t *testing.T) {
 	testcases := []struct {
 		name           string
@@ -173,6 +233,14 @@ func TestRemovePrefixedCSIParams(t *testing.T) {
 				PrefixedNfsExportterSecretNamespaceKey:     "csiBar",
 				PrefixedNfsExportterListSecretNameKey:      "csiBar",
 				PrefixedNfsExportterListSecretNamespaceKey: "csiBar",
+				PrefixedExportPathHintPatternKey:           "csiBar",
+				PrefixedExportSecurityContextSourceKey:     "csiBar",
+				PrefixedExportZoneKey:                      "csiBar",
+				PrefixedExportModesKey:                     "csiBar",
+				PrefixedExportWarmUpKey:                    "csiBar",
+				PrefixedExportPropagatedAnnotationsKey:     "csiBar",
+				PrefixedExportPropagatedLabelsKey:          "csiBar",
+				PrefixedExportCacheTiersKey:                "csiBar",
 			},
 			expectedParams: map[string]string{},
 		},
@@ -207,3 +275,392 @@ func TestRemovePrefixedCSIParams(t *testing.T) {
 		}
 	}
 }
+
+func TestIsVolumeNfsExportRefSet(t *testing.T) {
+	created := metav1.NewTime(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC))
+	recreated := metav1.NewTime(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	testcases := []struct {
+		name        string
+		uid         string
+		created     metav1.Time
+		annotations map[string]string
+		expected    bool
+	}{
+		{
+			name:     "uid matches, no recorded creation timestamp",
+			uid:      "uid1",
+			created:  created,
+			expected: true,
+		},
+		{
+			name:     "uid does not match",
+			uid:      "uid2",
+			created:  created,
+			expected: false,
+		},
+		{
+			name:        "uid and recorded creation timestamp match",
+			uid:         "uid1",
+			created:     created,
+			annotations: map[string]string{AnnVolumeNfsExportCreationTimestamp: "2020-01-02T03:04:05Z"},
+			expected:    true,
+		},
+		{
+			name:        "uid matches, recorded creation timestamp differs",
+			uid:         "uid1",
+			created:     recreated,
+			annotations: map[string]string{AnnVolumeNfsExportCreationTimestamp: "2020-01-02T03:04:05Z"},
+			expected:    false,
+		},
+		{
+			name:        "uid matches, nfsexport has no creation timestamp",
+			uid:         "uid1",
+			annotations: map[string]string{AnnVolumeNfsExportCreationTimestamp: "2020-01-02T03:04:05Z"},
+			expected:    true,
+		},
+	}
+	for _, tc := range testcases {
+		nfsexport := &crdv1.VolumeNfsExport{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "snap",
+				Namespace:         "ns",
+				UID:               "uid1",
+				CreationTimestamp: tc.created,
+			},
+		}
+		content := &crdv1.VolumeNfsExportContent{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "content",
+				Annotations: tc.annotations,
+			},
+			Spec: crdv1.VolumeNfsExportContentSpec{
+				VolumeNfsExportRef: v1.ObjectReference{
+					Name:      "snap",
+					Namespace: "ns",
+					UID:       types.UID(tc.uid),
+				},
+			},
+		}
+		if got := IsVolumeNfsExportRefSet(nfsexport, content); got != tc.expected {
+			t.Errorf("%s: expected IsVolumeNfsExportRefSet to return %v, got %v", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func TestCSIPersistentVolumeTransform(t *testing.T) {
+	csiPV := func(name, driver string) *v1.PersistentVolume {
+		return &v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name + "-uid"), ResourceVersion: "1"},
+			Spec: v1.PersistentVolumeSpec{
+				PersistentVolumeSource: v1.PersistentVolumeSource{
+					CSI: &v1.CSIPersistentVolumeSource{Driver: driver, VolumeHandle: name + "-handle"},
+				},
+			},
+		}
+	}
+	stripped := func(name string) *v1.PersistentVolume {
+		return &v1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name, UID: types.UID(name + "-uid"), ResourceVersion: "1"},
+		}
+	}
+	nonCSIPV := stripped("pv3")
+	nonCSIPV.Spec.HostPath = &v1.HostPathVolumeSource{Path: "/tmp"}
+
+	tests := []struct {
+		name     string
+		drivers  []string
+		input    interface{}
+		expected interface{}
+	}{
+		{
+			name:     "CSI PV kept when no drivers are given",
+			input:    csiPV("pv1", "driver1"),
+			expected: csiPV("pv1", "driver1"),
+		},
+		{
+			name:     "CSI PV of a listed driver kept",
+			drivers:  []string{"driver1"},
+			input:    csiPV("pv1", "driver1"),
+			expected: csiPV("pv1", "driver1"),
+		},
+		{
+			name:     "CSI PV of another driver stripped",
+			drivers:  []string{"driver1"},
+			input:    csiPV("pv2", "driver2"),
+			expected: stripped("pv2"),
+		},
+		{
+			name:     "non-CSI PV stripped",
+			input:    nonCSIPV,
+			expected: stripped("pv3"),
+		},
+		{
+			name:     "other objects passed through",
+			input:    "not a pv",
+			expected: "not a pv",
+		},
+	}
+
+	for _, test := range tests {
+		got, err := CSIPersistentVolumeTransform(test.drivers)(test.input)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestValidateExportPathHint(t *testing.T) {
+	tests := []struct {
+		name      string
+		hint      string
+		params    map[string]string
+		expectErr bool
+	}{
+		{
+			name:   "hint matches pattern",
+			hint:   "team-a/db",
+			params: map[string]string{PrefixedExportPathHintPatternKey: `team-a/[a-z]+`},
+		},
+		{
+			name:      "pattern must match the whole hint",
+			hint:      "team-a/db/../../etc",
+			params:    map[string]string{PrefixedExportPathHintPatternKey: `team-a/[a-z]+`},
+			expectErr: true,
+		},
+		{
+			name:      "class without pattern",
+			hint:      "team-a/db",
+			params:    map[string]string{},
+			expectErr: true,
+		},
+		{
+			name:      "invalid pattern",
+			hint:      "team-a/db",
+			params:    map[string]string{PrefixedExportPathHintPatternKey: `team-a/[`},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateExportPathHint(test.hint, test.params)
+		if (err != nil) != test.expectErr {
+			t.Errorf("%s: expected error: %v, got: %v", test.name, test.expectErr, err)
+		}
+	}
+}
+
+func TestValidateExportMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      crdv1.VolumeNfsExportMode
+		params    map[string]string
+		expectErr bool
+	}{
+		{
+			name:   "class without modes supports live",
+			mode:   crdv1.VolumeNfsExportModeLive,
+			params: map[string]string{},
+		},
+		{
+			name:      "class without modes does not support point in time",
+			mode:      crdv1.VolumeNfsExportModePointInTime,
+			params:    map[string]string{},
+			expectErr: true,
+		},
+		{
+			name:   "listed mode",
+			mode:   crdv1.VolumeNfsExportModePointInTime,
+			params: map[string]string{PrefixedExportModesKey: "Live, PointInTime"},
+		},
+		{
+			name:      "mode not listed",
+			mode:      crdv1.VolumeNfsExportModeLive,
+			params:    map[string]string{PrefixedExportModesKey: "PointInTime"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown mode",
+			mode:      crdv1.VolumeNfsExportModeLive,
+			params:    map[string]string{PrefixedExportModesKey: "Live,Clone"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateExportMode(test.mode, test.params)
+		if (err != nil) != test.expectErr {
+			t.Errorf("%s: expected error: %v, got: %v", test.name, test.expectErr, err)
+		}
+	}
+}
+
+func TestGetExportMode(t *testing.T) {
+	if mode := GetExportMode(nil); mode != crdv1.VolumeNfsExportModeLive {
+		t.Errorf("expected unset mode to default to %q, got %q", crdv1.VolumeNfsExportModeLive, mode)
+	}
+	pointInTime := crdv1.VolumeNfsExportModePointInTime
+	if mode := GetExportMode(&pointInTime); mode != pointInTime {
+		t.Errorf("expected mode %q, got %q", pointInTime, mode)
+	}
+	// A class that only lists PointInTime rejects nfsexports without mode.
+	if err := ValidateExportMode(GetExportMode(nil), map[string]string{PrefixedExportModesKey: "PointInTime"}); err == nil {
+		t.Errorf("expected the default mode to be rejected by a class without %q", crdv1.VolumeNfsExportModeLive)
+	}
+}
+
+func TestValidateExportCacheTier(t *testing.T) {
+	tests := []struct {
+		name      string
+		tier      crdv1.VolumeNfsExportCacheTier
+		params    map[string]string
+		expectErr bool
+	}{
+		{
+			name:      "class without cache tiers",
+			tier:      crdv1.VolumeNfsExportCacheTierNodeLocal,
+			params:    map[string]string{},
+			expectErr: true,
+		},
+		{
+			name:   "listed cache tier",
+			tier:   crdv1.VolumeNfsExportCacheTierNodeLocal,
+			params: map[string]string{PrefixedExportCacheTiersKey: " NodeLocal "},
+		},
+		{
+			name:      "cache tier not listed",
+			tier:      crdv1.VolumeNfsExportCacheTier("Remote"),
+			params:    map[string]string{PrefixedExportCacheTiersKey: "NodeLocal"},
+			expectErr: true,
+		},
+		{
+			name:      "unknown cache tier",
+			tier:      crdv1.VolumeNfsExportCacheTierNodeLocal,
+			params:    map[string]string{PrefixedExportCacheTiersKey: "NodeLocal,Remote"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		err := ValidateExportCacheTier(test.tier, test.params)
+		if (err != nil) != test.expectErr {
+			t.Errorf("%s: expected error: %v, got: %v", test.name, test.expectErr, err)
+		}
+	}
+}
+
+func TestIsExportWarmUpRequested(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    map[string]string
+		expected  bool
+		expectErr bool
+	}{
+		{
+			name:   "not set",
+			params: map[string]string{},
+		},
+		{
+			name:     "requested",
+			params:   map[string]string{PrefixedExportWarmUpKey: "true"},
+			expected: true,
+		},
+		{
+			name:   "not requested",
+			params: map[string]string{PrefixedExportWarmUpKey: "false"},
+		},
+		{
+			name:      "invalid",
+			params:    map[string]string{PrefixedExportWarmUpKey: "eager"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		warmUp, err := IsExportWarmUpRequested(test.params)
+		if (err != nil) != test.expectErr {
+			t.Errorf("%s: expected error: %v, got: %v", test.name, test.expectErr, err)
+		}
+		if warmUp != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, warmUp)
+		}
+	}
+}
+
+func TestGetExportPathFromHandle(t *testing.T) {
+	tests := map[string]string{
+		"server:/exports/team-a/db": "/exports/team-a/db",
+		"10.0.0.1:/":                "/",
+		"snapshot-1234":             "",
+		":/exports":                 "",
+	}
+	for handle, expected := range tests {
+		if got := GetExportPathFromHandle(handle); got != expected {
+			t.Errorf("GetExportPathFromHandle(%q) = %q, expected %q", handle, got, expected)
+		}
+	}
+}
+
+func TestGetExportServerFromHandle(t *testing.T) {
+	tests := map[string]string{
+		"server:/exports/team-a/db": "server",
+		"10.0.0.1:/":                "10.0.0.1",
+		"snapshot-1234":             "",
+		":/exports":                 "",
+	}
+	for handle, expected := range tests {
+		if got := GetExportServerFromHandle(handle); got != expected {
+			t.Errorf("GetExportServerFromHandle(%q) = %q, expected %q", handle, got, expected)
+		}
+	}
+}
+
+func TestIsVolumeNfsExportErrorEqual(t *testing.T) {
+	message, other := "mock error", "other error"
+	earlier := metav1.NewTime(time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC))
+	later := metav1.NewTime(time.Date(2018, 1, 2, 3, 5, 5, 0, time.UTC))
+	tests := []struct {
+		name     string
+		a, b     *crdv1.VolumeNfsExportError
+		expected bool
+	}{
+		{name: "both nil", expected: true},
+		{name: "one nil", a: &crdv1.VolumeNfsExportError{Message: &message}, expected: false},
+		{name: "same message, different time", a: &crdv1.VolumeNfsExportError{Time: &earlier, Message: &message}, b: &crdv1.VolumeNfsExportError{Time: &later, Message: &message}, expected: true},
+		{name: "different message", a: &crdv1.VolumeNfsExportError{Time: &earlier, Message: &message}, b: &crdv1.VolumeNfsExportError{Time: &earlier, Message: &other}, expected: false},
+		{name: "one message nil", a: &crdv1.VolumeNfsExportError{Message: &message}, b: &crdv1.VolumeNfsExportError{}, expected: false},
+		{name: "both messages nil", a: &crdv1.VolumeNfsExportError{Time: &earlier}, b: &crdv1.VolumeNfsExportError{Time: &later}, expected: true},
+	}
+	for _, test := range tests {
+		if got := IsVolumeNfsExportErrorEqual(test.a, test.b); got != test.expected {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+		if got := IsVolumeNfsExportErrorEqual(test.b, test.a); got != test.expected {
+			t.Errorf("%s (swapped): expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestGetVolumeNfsExportErrorSummary(t *testing.T) {
+	short := "mock error"
+	long, truncated := strings.Repeat("é", 70), strings.Repeat("é", 60)
+	tests := []struct {
+		name     string
+		err      *crdv1.VolumeNfsExportError
+		expected *string
+	}{
+		{name: "nil error"},
+		{name: "nil message", err: &crdv1.VolumeNfsExportError{}},
+		{name: "short message", err: &crdv1.VolumeNfsExportError{Message: &short}, expected: &short},
+		{name: "long message", err: &crdv1.VolumeNfsExportError{Message: &long}, expected: &truncated},
+	}
+	for _, test := range tests {
+		got := GetVolumeNfsExportErrorSummary(test.err)
+		if (got == nil) != (test.expected == nil) || (got != nil && *got != *test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PrefixedExportConsistencyGateKey is a nfsexport class parameter
+	// deferring the creation of exports until a condition holds on the source
+	// PVC. It is meant for backends without snapshot semantics, whose exports
+	// are only consistent if nothing writes to the volume while they are
+	// created. There is no gate by default.
+	PrefixedExportConsistencyGateKey = csiParameterPrefix + "export-consistency-gate"
+	// ExportConsistencyGateNoWriters waits until no running pod mounts the
+	// source PVC read-write.
+	ExportConsistencyGateNoWriters = "NoWriters"
+
+	// PrefixedExportConsistencyGateTimeoutKey is a nfsexport class parameter
+	// setting how long the nfsexport controller waits for the gate to open,
+	// DefaultExportConsistencyGateTimeout by default. The nfsexport fails
+	// when it times out, and the gate is retried with backoff.
+	PrefixedExportConsistencyGateTimeoutKey = csiParameterPrefix + "export-consistency-gate-timeout"
+	DefaultExportConsistencyGateTimeout     = 10 * time.Minute
+
+	// AnnConsistencyGateWaitingSince annotation is set by the nfsexport
+	// controller on a nfsexport waiting for its consistency gate. It records
+	// when the wait started, in RFC 3339 format, to enforce the timeout, and
+	// is removed once the gate opens.
+	AnnConsistencyGateWaitingSince = "nfsexport.storage.kubernetes.io/consistency-gate-waiting-since"
+)
+
+// GetExportConsistencyGate returns the consistency gate set in the
+// parameters of a nfsexport class, or an empty string if there is none.
+func GetExportConsistencyGate(nfsexportClassParams map[string]string) (string, error) {
+	gate, ok := nfsexportClassParams[PrefixedExportConsistencyGateKey]
+	if !ok {
+		return "", nil
+	}
+	if gate != ExportConsistencyGateNoWriters {
+		return "", fmt.Errorf("invalid %s %q, the supported value is %q", PrefixedExportConsistencyGateKey, gate, ExportConsistencyGateNoWriters)
+	}
+	return gate, nil
+}
+
+// GetExportConsistencyGateTimeout returns the consistency gate timeout set
+// in the parameters of a nfsexport class.
+func GetExportConsistencyGateTimeout(nfsexportClassParams map[string]string) (time.Duration, error) {
+	value, ok := nfsexportClassParams[PrefixedExportConsistencyGateTimeoutKey]
+	if !ok {
+		return DefaultExportConsistencyGateTimeout, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %v", PrefixedExportConsistencyGateTimeoutKey, value, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be positive", PrefixedExportConsistencyGateTimeoutKey, value)
+	}
+	return timeout, nil
+}
+
+// FindRunningPodsWritingClaim returns, sorted by name, the running pods that
+// mount the PVC claimName read-write in at least one of their containers.
+func FindRunningPodsWritingClaim(pods []v1.Pod, claimName string) []*v1.Pod {
+	var found []*v1.Pod
+	for _, pod := range FindRunningPodsUsingClaim(pods, claimName) {
+		if podWritesClaim(pod, claimName) {
+			found = append(found, pod)
+		}
+	}
+	return found
+}
+
+// podWritesClaim returns true if a container of the pod mounts the PVC
+// read-write. A PVC volume source marked read-only is mounted read-only in
+// all containers.
+func podWritesClaim(pod *v1.Pod, claimName string) bool {
+	for _, volume := range pod.Spec.Volumes {
+		source := volume.PersistentVolumeClaim
+		if source == nil || source.ClaimName != claimName || source.ReadOnly {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			for _, mount := range container.VolumeMounts {
+				if mount.Name == volume.Name && !mount.ReadOnly {
+					return true
+				}
+			}
+		}
+		for _, container := range pod.Spec.EphemeralContainers {
+			for _, mount := range container.VolumeMounts {
+				if mount.Name == volume.Name && !mount.ReadOnly {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestGetExportConsistencyGate(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:   "no gate by default",
+			params: map[string]string{},
+		},
+		{
+			name:     "NoWriters",
+			params:   map[string]string{PrefixedExportConsistencyGateKey: ExportConsistencyGateNoWriters},
+			expected: ExportConsistencyGateNoWriters,
+		},
+		{
+			name:        "unknown gate",
+			params:      map[string]string{PrefixedExportConsistencyGateKey: "NoReaders"},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gate, err := GetExportConsistencyGate(test.params)
+			if (err != nil) != test.expectError {
+				t.Fatalf("expected error %v, got %v", test.expectError, err)
+			}
+			if gate != test.expected {
+				t.Errorf("expected gate %q, got %q", test.expected, gate)
+			}
+		})
+	}
+}
+
+func TestGetExportConsistencyGateTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]string
+		expected    time.Duration
+		expectError bool
+	}{
+		{
+			name:     "default",
+			params:   map[string]string{},
+			expected: DefaultExportConsistencyGateTimeout,
+		},
+		{
+			name:     "set",
+			params:   map[string]string{PrefixedExportConsistencyGateTimeoutKey: "1h"},
+			expected: time.Hour,
+		},
+		{
+			name:        "not a duration",
+			params:      map[string]string{PrefixedExportConsistencyGateTimeoutKey: "forever"},
+			expectError: true,
+		},
+		{
+			name:        "zero",
+			params:      map[string]string{PrefixedExportConsistencyGateTimeoutKey: "0s"},
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			timeout, err := GetExportConsistencyGateTimeout(test.params)
+			if (err != nil) != test.expectError {
+				t.Fatalf("expected error %v, got %v", test.expectError, err)
+			}
+			if timeout != test.expected {
+				t.Errorf("expected timeout %v, got %v", test.expected, timeout)
+			}
+		})
+	}
+}
+
+func TestFindRunningPodsWritingClaim(t *testing.T) {
+	readOnlySource := newPodUsingClaim("read-only-source", "pvc1", v1.PodRunning)
+	readOnlySource.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly = true
+	readOnlyMount := newPodUsingClaim("read-only-mount", "pvc1", v1.PodRunning)
+	readOnlyMount.Spec.Containers[1].VolumeMounts[0].ReadOnly = true
+	mixed := newPodUsingClaim("b-mixed", "pvc1", v1.PodRunning)
+	mixed.Spec.Containers[1].VolumeMounts[0].ReadOnly = true
+	mixed.Spec.Containers = append(mixed.Spec.Containers, v1.Container{
+		Name:         "writer",
+		VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/data"}},
+	})
+	debug := newPodUsingClaim("c-debug", "pvc1", v1.PodRunning)
+	debug.Spec.Containers[1].VolumeMounts[0].ReadOnly = true
+	debug.Spec.EphemeralContainers = []v1.EphemeralContainer{{
+		EphemeralContainerCommon: v1.EphemeralContainerCommon{
+			Name:         "debugger",
+			VolumeMounts: []v1.VolumeMount{{Name: "data", MountPath: "/data"}},
+		},
+	}}
+	pods := []v1.Pod{
+		readOnlySource,
+		readOnlyMount,
+		mixed,
+		debug,
+		newPodUsingClaim("pending", "pvc1", v1.PodPending),
+		newPodUsingClaim("other", "pvc2", v1.PodRunning),
+		newPodUsingClaim("a-writer", "pvc1", v1.PodRunning),
+	}
+
+	found := FindRunningPodsWritingClaim(pods, "pvc1")
+	var names []string
+	for _, pod := range found {
+		names = append(names, pod.Name)
+	}
+	if len(names) != 3 || names[0] != "a-writer" || names[1] != "b-mixed" || names[2] != "c-debug" {
+		t.Errorf("expected pods [a-writer b-mixed c-debug], got %v", names)
+	}
+}
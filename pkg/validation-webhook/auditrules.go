@@ -0,0 +1,92 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// auditedRulesAnnotation is the audit annotation listing the rules a request
+// admitted by the ruleAuditor failed. The API server prefixes it with the
+// name of the webhook in the audit log.
+const auditedRulesAnnotation = "audited-rules"
+
+// violatedRulesAnnotation is the audit annotation listing the validation
+// rules a denied request violated, see rejectRulesV1.
+const violatedRulesAnnotation = "violated-rules"
+
+// rejectRulesV1 returns a response denying an object of the given kind for
+// errs, like rejectV1, when errs are violations of validation rules. Only
+// such denials can be audited instead of enforced: the rules are listed in
+// the violatedRulesAnnotation. Denials for immutable fields, reserved
+// metadata, skipping validation or errors use rejectV1 and are always
+// enforced.
+func rejectRulesV1(kind, name string, errs field.ErrorList) *v1.AdmissionResponse {
+	response := rejectV1(kind, name, errs)
+	response.AuditAnnotations = map[string]string{violatedRulesAnnotation: strings.Join(decisionRules(response), ",")}
+	return response
+}
+
+// ruleAuditor admits the requests denied only for validation rules that are
+// audited instead of enforced, so that the requests a new rule would deny can
+// be found before it is enforced. Rules are named as by decisionRules, and
+// only the denials of rejectRulesV1 are audited.
+type ruleAuditor struct {
+	NfsExportAdmitter
+	rules map[string]bool
+}
+
+// auditRules returns an admitter admitting the requests admit denies only for
+// the given rules, with a warning and the auditedRulesAnnotation. It returns
+// admit unchanged if there are no rules to audit.
+func auditRules(admit NfsExportAdmitter, rules []string) NfsExportAdmitter {
+	if len(rules) == 0 {
+		return admit
+	}
+	a := &ruleAuditor{NfsExportAdmitter: admit, rules: map[string]bool{}}
+	for _, rule := range rules {
+		a.rules[rule] = true
+	}
+	return a
+}
+
+func (a *ruleAuditor) Admit(ar v1.AdmissionReview) *v1.AdmissionResponse {
+	response := a.NfsExportAdmitter.Admit(ar)
+	if response.Allowed {
+		return response
+	}
+	violated, ok := response.AuditAnnotations[violatedRulesAnnotation]
+	if !ok {
+		return response
+	}
+	for _, rule := range strings.Split(violated, ",") {
+		if !a.rules[rule] {
+			return response
+		}
+	}
+	return &v1.AdmissionResponse{
+		Allowed:          true,
+		Result:           &metav1.Status{},
+		Warnings:         append(response.Warnings, fmt.Sprintf("would be denied once the audited rules are enforced: %s", response.Result.Message)),
+		AuditAnnotations: map[string]string{auditedRulesAnnotation: violated},
+	}
+}
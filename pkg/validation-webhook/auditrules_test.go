@@ -0,0 +1,135 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func nfsexportReview(t *testing.T, nfsexport *volumenfsexportv1.VolumeNfsExport) v1.AdmissionReview {
+	raw, err := json.Marshal(nfsexport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v1.AdmissionReview{
+		Request: &v1.AdmissionRequest{
+			Object:    runtime.RawExtension{Raw: raw},
+			Kind:      metav1.GroupVersionKind{Group: "nfsexport.storage.k8s.io", Version: "v1", Kind: "VolumeNfsExport"},
+			Resource:  NfsExportV1GVR,
+			Operation: v1.Create,
+		},
+	}
+}
+
+func TestAuditRules(t *testing.T) {
+	contentName := "content1"
+	emptyClassName := ""
+	sourcesOnly := &volumenfsexportv1.VolumeNfsExport{
+		Spec: volumenfsexportv1.VolumeNfsExportSpec{
+			Source:  volumenfsexportv1.VolumeNfsExportSource{VolumeNfsExportContentName: &contentName},
+			Sources: []string{"pvc2"},
+		},
+	}
+	sourcesAndClass := sourcesOnly.DeepCopy()
+	sourcesAndClass.Spec.VolumeNfsExportClassName = &emptyClassName
+
+	admit := auditRules(NewNfsExportAdmitter(nil, nil), []string{"spec.sources"})
+
+	response := admit.Admit(nfsexportReview(t, sourcesOnly))
+	if !response.Allowed {
+		t.Errorf("expected a request failing only audited rules to be admitted, got %v", response.Result)
+	}
+	if audited := response.AuditAnnotations[auditedRulesAnnotation]; audited != "spec.sources" {
+		t.Errorf("expected audited rules spec.sources, got %q", audited)
+	}
+	if len(response.Warnings) != 1 || !strings.Contains(response.Warnings[0], "spec.sources: Forbidden") {
+		t.Errorf("expected a warning for spec.sources, got %v", response.Warnings)
+	}
+
+	response = admit.Admit(nfsexportReview(t, sourcesAndClass))
+	if response.Allowed {
+		t.Errorf("expected a request also failing an enforced rule to be denied")
+	}
+
+	if unchanged := NewNfsExportAdmitter(nil, nil); auditRules(unchanged, nil) != unchanged {
+		t.Errorf("expected the admitter to be unchanged without audited rules")
+	}
+}
+
+func TestAuditRulesEnforcesOtherDenials(t *testing.T) {
+	pvcName := "pvc1"
+	nfsexport := &volumenfsexportv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"},
+		Spec: volumenfsexportv1.VolumeNfsExportSpec{
+			Source:  volumenfsexportv1.VolumeNfsExportSource{PersistentVolumeClaimName: &pvcName},
+			Sources: []string{"pvc1"},
+		},
+	}
+	moved := nfsexport.DeepCopy()
+	moved.Spec.Sources = []string{"pvc2"}
+	relabeled := nfsexport.DeepCopy()
+	relabeled.Labels = map[string]string{utils.VolumeNfsExportInvalidLabel: ""}
+
+	// The rules of the denials below are audited, but the denials are not
+	// for validation rules.
+	admit := auditRules(&admitter{reservedMetadata: newReservedMetadataGuard([]string{"controller"})},
+		[]string{"spec.sources", "metadata.labels[*]", "error"})
+
+	testCases := []struct {
+		name      string
+		nfsexport *volumenfsexportv1.VolumeNfsExport
+	}{
+		{
+			name:      "immutable field changed",
+			nfsexport: moved,
+		},
+		{
+			name:      "reserved label changed",
+			nfsexport: relabeled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			review := nfsexportReview(t, tc.nfsexport)
+			oldRaw, err := json.Marshal(nfsexport)
+			if err != nil {
+				t.Fatal(err)
+			}
+			review.Request.OldObject = runtime.RawExtension{Raw: oldRaw}
+			review.Request.Operation = v1.Update
+			response := admit.Admit(review)
+			if response.Allowed {
+				t.Errorf("expected the request to be denied, got audit annotations %v", response.AuditAnnotations)
+			}
+		})
+	}
+
+	// An undecodable object is denied with the "error" rule.
+	review := nfsexportReview(t, nfsexport)
+	review.Request.Object.Raw = []byte("not json")
+	if response := admit.Admit(review); response.Allowed {
+		t.Errorf("expected an error to be denied")
+	}
+}
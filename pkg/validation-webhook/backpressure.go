@@ -0,0 +1,168 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// backpressure denies the creation of VolumeNfsExports with a 429 status
+// while the backlog of the nfsexport controller, polled from its
+// utils.BacklogPath endpoint, exceeds a threshold, e.g. to protect the
+// cluster while it recovers from an incident. VolumeNfsExports of the
+// priority classes are still admitted. Creations are admitted while the
+// backlog is unknown, so that the webhook does not block exports when the
+// controller cannot be reached. With leader election, only the leader serves
+// the backlog: the polls answered by a standby replica fail with
+// errStandbyReplica and leave the last backlog in place.
+type backpressure struct {
+	url             string
+	threshold       int
+	priorityClasses sets.String
+	interval        time.Duration
+	client          *http.Client
+
+	lock sync.Mutex
+	// backlog is the total backlog of the last successful poll, at
+	// polledAt.
+	backlog  int
+	polledAt time.Time
+}
+
+// errStandbyReplica is returned by poll when the backlog is served by a
+// replica of the nfsexport controller that is not the leader.
+var errStandbyReplica = errors.New("the nfsexport controller replica is not the leader")
+
+// newBackpressure returns a backpressure polling url every interval and
+// denying creations while the backlog exceeds threshold, or nil if url is
+// empty, which disables it.
+func newBackpressure(url string, threshold int, priorityClasses []string, interval time.Duration) *backpressure {
+	if url == "" {
+		return nil
+	}
+	return &backpressure{
+		url:             url,
+		threshold:       threshold,
+		priorityClasses: sets.NewString(priorityClasses...),
+		interval:        interval,
+		client:          &http.Client{Timeout: interval},
+	}
+}
+
+// run polls the backlog until ctx is done.
+func (b *backpressure) run(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		err := b.poll(ctx)
+		if errors.Is(err, errStandbyReplica) {
+			klog.V(4).Infof("Polled a standby replica of the nfsexport controller at %s, keeping the last backlog", b.url)
+			return
+		}
+		if err != nil {
+			klog.Warningf("Failed to get the backlog of the nfsexport controller from %s, creations are admitted until it is known: %v", b.url, err)
+		}
+	}, b.interval)
+}
+
+// poll gets the backlog of the nfsexport controller.
+func (b *backpressure) poll(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		return errStandbyReplica
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	var backlog utils.Backlog
+	if err := json.NewDecoder(resp.Body).Decode(&backlog); err != nil {
+		return fmt.Errorf("failed to decode the backlog: %v", err)
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.backlog = backlog.Total()
+	b.polledAt = time.Now()
+	return nil
+}
+
+// currentBacklog returns the backlog of the last poll, and false if it is
+// unknown because no poll succeeded within the last three intervals.
+func (b *backpressure) currentBacklog() (int, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.polledAt.IsZero() || time.Since(b.polledAt) > 3*b.interval {
+		return 0, false
+	}
+	return b.backlog, true
+}
+
+// admit returns a response denying the creation of nfsexport if the backlog
+// of the nfsexport controller exceeds the threshold, or nil if it may be
+// created. It returns nil if b is nil.
+func (b *backpressure) admit(request *v1.AdmissionRequest, nfsexport *volumenfsexportv1.VolumeNfsExport) *v1.AdmissionResponse {
+	if b == nil || request.Operation != v1.Create {
+		return nil
+	}
+	if className := nfsexport.Spec.VolumeNfsExportClassName; className != nil && b.priorityClasses.Has(*className) {
+		return nil
+	}
+	backlog, known := b.currentBacklog()
+	if !known || backlog <= b.threshold {
+		return nil
+	}
+
+	klog.V(4).Infof("Denying the creation of VolumeNfsExport %s/%s, the backlog of the nfsexport controller is %d", request.Namespace, nfsexport.Name, backlog)
+	message := fmt.Sprintf("the nfsexport controller has %d objects waiting, more than %d, retry the creation of VolumeNfsExport %s later", backlog, b.threshold, nfsexport.Name)
+	if b.priorityClasses.Len() > 0 {
+		message += fmt.Sprintf(" or use one of the VolumeNfsExportClasses %s", strings.Join(b.priorityClasses.List(), ", "))
+	}
+	return &v1.AdmissionResponse{
+		Allowed: false,
+		Result: &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    http.StatusTooManyRequests,
+			Reason:  metav1.StatusReasonTooManyRequests,
+			Message: message,
+			Details: &metav1.StatusDetails{
+				Name:              nfsexport.Name,
+				Kind:              "VolumeNfsExport",
+				RetryAfterSeconds: int32(b.interval / time.Second),
+			},
+		},
+	}
+}
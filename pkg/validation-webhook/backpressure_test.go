@@ -0,0 +1,160 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestAdmitBackpressure(t *testing.T) {
+	backlog := utils.Backlog{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		json.NewEncoder(w).Encode(backlog)
+	}))
+	defer server.Close()
+
+	pvcName := "pvc1"
+	testCases := []struct {
+		name          string
+		backlog       utils.Backlog
+		polled        bool
+		className     string
+		operation     v1.Operation
+		expectAllowed bool
+	}{
+		{
+			name:          "creation is admitted below the threshold",
+			backlog:       utils.Backlog{NfsExports: 5, Contents: 5},
+			polled:        true,
+			className:     "standard",
+			operation:     v1.Create,
+			expectAllowed: true,
+		},
+		{
+			name:          "creation is denied above the threshold",
+			backlog:       utils.Backlog{NfsExports: 6, Contents: 5},
+			polled:        true,
+			className:     "standard",
+			operation:     v1.Create,
+			expectAllowed: false,
+		},
+		{
+			name:          "creation of a priority class is admitted above the threshold",
+			backlog:       utils.Backlog{NfsExports: 6, Contents: 5},
+			polled:        true,
+			className:     "critical",
+			operation:     v1.Create,
+			expectAllowed: true,
+		},
+		{
+			name:          "update is admitted above the threshold",
+			backlog:       utils.Backlog{NfsExports: 6, Contents: 5},
+			polled:        true,
+			className:     "standard",
+			operation:     v1.Update,
+			expectAllowed: true,
+		},
+		{
+			name:          "creation is admitted while the backlog is unknown",
+			className:     "standard",
+			operation:     v1.Create,
+			expectAllowed: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newBackpressure(server.URL, 10, []string{"critical"}, time.Minute)
+			if tc.polled {
+				backlog = tc.backlog
+				if err := b.poll(context.Background()); err != nil {
+					t.Fatalf("failed to poll the backlog: %v", err)
+				}
+			}
+			nfsexport := &volumenfsexportv1.VolumeNfsExport{
+				ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"},
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source:                   volumenfsexportv1.VolumeNfsExportSource{PersistentVolumeClaimName: &pvcName},
+					VolumeNfsExportClassName: &tc.className,
+				},
+			}
+			raw, err := json.Marshal(nfsexport)
+			if err != nil {
+				t.Fatal(err)
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Name:      "snap1",
+					Namespace: "default",
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: raw},
+					Resource:  NfsExportV1GVR,
+					Operation: tc.operation,
+				},
+			}
+
+			response := (&admitter{backpressure: b}).Admit(review)
+			if response.Allowed != tc.expectAllowed {
+				t.Fatalf("expected allowed %v, got %+v", tc.expectAllowed, response.Result)
+			}
+			if !tc.expectAllowed {
+				if response.Result.Code != http.StatusTooManyRequests || response.Result.Reason != metav1.StatusReasonTooManyRequests {
+					t.Errorf("expected a 429 status, got %+v", response.Result)
+				}
+				if response.Result.Details == nil || response.Result.Details.RetryAfterSeconds != 60 {
+					t.Errorf("expected a retry after the poll interval, got %+v", response.Result.Details)
+				}
+			}
+		})
+	}
+}
+
+func TestPollStandbyReplica(t *testing.T) {
+	standby := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if standby {
+			http.Error(w, "not the leader", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(utils.Backlog{NfsExports: 7})
+	}))
+	defer server.Close()
+
+	b := newBackpressure(server.URL, 10, nil, time.Minute)
+	if err := b.poll(context.Background()); err != nil {
+		t.Fatalf("failed to poll the backlog: %v", err)
+	}
+	standby = true
+	if err := b.poll(context.Background()); !errors.Is(err, errStandbyReplica) {
+		t.Errorf("expected errStandbyReplica, got %v", err)
+	}
+	if backlog, known := b.currentBacklog(); !known || backlog != 7 {
+		t.Errorf("expected the last backlog 7 to be kept, got %d, known %v", backlog, known)
+	}
+}
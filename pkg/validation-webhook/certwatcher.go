@@ -17,11 +17,14 @@ limitations under the License.
 package webhook
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 )
 
@@ -41,15 +44,23 @@ type CertWatcher struct {
 
 	certPath string
 	keyPath  string
+
+	// pollInterval is the interval at which the certificate and key are
+	// re-read in addition to the file events, as events can be missed when
+	// the files are replaced, e.g. by the kubelet swapping the symlinks of a
+	// Secret volume. Polling is disabled if it is 0.
+	pollInterval time.Duration
 }
 
-// NewCertWatcher returns a new CertWatcher watching the given certificate and key.
-func NewCertWatcher(certPath, keyPath string) (*CertWatcher, error) {
+// NewCertWatcher returns a new CertWatcher watching the given certificate and
+// key, and re-reading them every pollInterval if it is not 0.
+func NewCertWatcher(certPath, keyPath string, pollInterval time.Duration) (*CertWatcher, error) {
 	var err error
 
 	cw := &CertWatcher{
-		certPath: certPath,
-		keyPath:  keyPath,
+		certPath:     certPath,
+		keyPath:      keyPath,
+		pollInterval: pollInterval,
 	}
 
 	// Initial read of certificate and key.
@@ -84,6 +95,14 @@ func (cw *CertWatcher) Start(ctx context.Context) error {
 
 	go cw.Watch()
 
+	if cw.pollInterval > 0 {
+		go wait.Until(func() {
+			if err := cw.ReadCertificate(); err != nil {
+				klog.Errorf("error re-reading certificate: %v", err)
+			}
+		}, cw.pollInterval, ctx.Done())
+	}
+
 	// Block until the context is done.
 	<-ctx.Done()
 
@@ -114,8 +133,7 @@ func (cw *CertWatcher) Watch() {
 }
 
 // ReadCertificate reads the certificate and key files from disk, parses them,
-// and updates the current certificate on the watcher.  If a callback is set, it
-// is invoked with the new certificate.
+// and updates the current certificate on the watcher if it changed.
 func (cw *CertWatcher) ReadCertificate() error {
 	cert, err := tls.LoadX509KeyPair(cw.certPath, cw.keyPath)
 	if err != nil {
@@ -123,6 +141,10 @@ func (cw *CertWatcher) ReadCertificate() error {
 	}
 
 	cw.Lock()
+	if cw.currentCert != nil && isSameCertificate(cw.currentCert, &cert) {
+		cw.Unlock()
+		return nil
+	}
 	cw.currentCert = &cert
 	cw.Unlock()
 
@@ -151,6 +173,20 @@ func (cw *CertWatcher) handleEvent(event fsnotify.Event) {
 	}
 }
 
+// isSameCertificate returns true if both certificates have the same chain.
+// A new key always comes with a new certificate.
+func isSameCertificate(a, b *tls.Certificate) bool {
+	if len(a.Certificate) != len(b.Certificate) {
+		return false
+	}
+	for i := range a.Certificate {
+		if !bytes.Equal(a.Certificate[i], b.Certificate[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func isWrite(event fsnotify.Event) bool {
 	return event.Op&fsnotify.Write == fsnotify.Write
 }
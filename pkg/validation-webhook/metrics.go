@@ -0,0 +1,205 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8smetrics "k8s.io/component-base/metrics"
+	"k8s.io/klog/v2"
+)
+
+const (
+	metricsSubSystem = "nfsexport_webhook"
+
+	labelResource  = "resource"
+	labelOperation = "operation"
+	labelDecision  = "decision"
+	labelRule      = "rule"
+	labelKind      = "kind"
+	labelEnforced  = "enforced"
+
+	// Decisions of the webhook on a request.
+	decisionAllowed = "allowed"
+	decisionDenied  = "denied"
+	// decisionMarked is an invalid object admitted with the invalid label in
+	// --mark-only mode.
+	decisionMarked = "marked"
+	// decisionAudited is a request admitted because the rules it failed are
+	// audited, see auditRules.
+	decisionAudited = "audited"
+)
+
+var admissionLatencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// mapKeyPattern matches the map keys of field paths, e.g. the key of
+// "parameters[csi.storage.k8s.io/foo]".
+var mapKeyPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// webhookMetrics holds the metrics of the webhook server.
+type webhookMetrics struct {
+	registry k8smetrics.KubeRegistry
+	// latency is the time spent admitting a request, by resource and operation.
+	latency *k8smetrics.HistogramVec
+	// decisions counts the decisions by resource, decision and rule. A request
+	// rejected by several rules is counted once per rule.
+	decisions *k8smetrics.CounterVec
+	// violations counts the failed rules by object kind and rule, and whether
+	// the rule was enforced or the object admitted anyway.
+	violations *k8smetrics.CounterVec
+	// panics counts the requests whose handling panicked.
+	panics *k8smetrics.Counter
+}
+
+func newWebhookMetrics() *webhookMetrics {
+	m := &webhookMetrics{
+		registry: k8smetrics.NewKubeRegistry(),
+		latency: k8smetrics.NewHistogramVec(
+			&k8smetrics.HistogramOpts{
+				Subsystem: metricsSubSystem,
+				Name:      "admission_duration_seconds",
+				Help:      "Number of seconds spent by the webhook admitting a request",
+				Buckets:   admissionLatencyBuckets,
+			},
+			[]string{labelResource, labelOperation},
+		),
+		decisions: k8smetrics.NewCounterVec(
+			&k8smetrics.CounterOpts{
+				Subsystem: metricsSubSystem,
+				Name:      "admission_decisions_total",
+				Help:      "Number of admission decisions of the webhook by validation rule",
+			},
+			[]string{labelResource, labelDecision, labelRule},
+		),
+		violations: k8smetrics.NewCounterVec(
+			&k8smetrics.CounterOpts{
+				Subsystem: metricsSubSystem,
+				Name:      "rule_violations_total",
+				Help:      "Number of objects failing a validation rule of the webhook by object kind and rule, and whether the rule denied the object",
+			},
+			[]string{labelKind, labelRule, labelEnforced},
+		),
+		panics: k8smetrics.NewCounter(
+			&k8smetrics.CounterOpts{
+				Subsystem: metricsSubSystem,
+				Name:      "panics_total",
+				Help:      "Number of admission requests whose handling panicked",
+			},
+		),
+	}
+	m.registry.MustRegister(m.latency)
+	m.registry.MustRegister(m.decisions)
+	m.registry.MustRegister(m.violations)
+	m.registry.MustRegister(m.panics)
+	return m
+}
+
+// handler returns the http handler exposing the metrics.
+func (m *webhookMetrics) handler() http.Handler {
+	return k8smetrics.HandlerFor(m.registry, k8smetrics.HandlerOpts{ErrorHandling: k8smetrics.ContinueOnError})
+}
+
+// instrument returns an admitter recording the latency and the decisions of
+// admit. It returns admit unchanged if m is nil.
+func (m *webhookMetrics) instrument(admit NfsExportAdmitter) NfsExportAdmitter {
+	if m == nil {
+		return admit
+	}
+	return &instrumentedAdmitter{NfsExportAdmitter: admit, metrics: m}
+}
+
+// recoverPanics returns a handler which answers requests whose handling
+// panics with an internal server error, so that the API server applies the
+// failurePolicy of the webhook, instead of dropping the connection.
+func (m *webhookMetrics) recoverPanics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := recover(); err != nil {
+				klog.Errorf("panic while handling admission request: %v\n%s", err, debug.Stack())
+				if m != nil {
+					m.panics.Inc()
+				}
+				http.Error(w, fmt.Sprintf("internal error: %v", err), http.StatusInternalServerError)
+			}
+		}()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+type instrumentedAdmitter struct {
+	NfsExportAdmitter
+	metrics *webhookMetrics
+}
+
+func (a *instrumentedAdmitter) Admit(ar v1.AdmissionReview) *v1.AdmissionResponse {
+	start := time.Now()
+	response := a.NfsExportAdmitter.Admit(ar)
+	resource := ar.Request.Resource.Resource
+	a.metrics.latency.WithLabelValues(resource, string(ar.Request.Operation)).Observe(time.Since(start).Seconds())
+
+	decision := decisionAllowed
+	rules := decisionRules(response)
+	if !response.Allowed {
+		decision = decisionDenied
+	} else if response.Result != nil && response.Result.Reason == metav1.StatusReasonInvalid {
+		decision = decisionMarked
+	} else if audited := response.AuditAnnotations[auditedRulesAnnotation]; audited != "" {
+		decision = decisionAudited
+		rules = strings.Split(audited, ",")
+	}
+	for _, rule := range rules {
+		a.metrics.decisions.WithLabelValues(resource, decision, rule).Inc()
+		if rule != "" && rule != "error" {
+			enforced := strconv.FormatBool(decision == decisionDenied)
+			a.metrics.violations.WithLabelValues(ar.Request.Kind.Kind, rule, enforced).Inc()
+		}
+	}
+	return response
+}
+
+// decisionRules returns the rules behind the decision of a response: the
+// paths of the rejected fields, with map keys replaced by "*" to bound the
+// cardinality of the metric. An allowed request has an empty rule, and a
+// request denied for another reason than a field, e.g. an error listing the
+// classes, has the rule "error".
+func decisionRules(response *v1.AdmissionResponse) []string {
+	if response.Result == nil || response.Result.Details == nil || len(response.Result.Details.Causes) == 0 {
+		if response.Allowed {
+			return []string{""}
+		}
+		return []string{"error"}
+	}
+	rules := map[string]bool{}
+	for _, cause := range response.Result.Details.Causes {
+		rules[mapKeyPattern.ReplaceAllString(cause.Field, "[*]")] = true
+	}
+	ret := make([]string, 0, len(rules))
+	for rule := range rules {
+		ret = append(ret, rule)
+	}
+	sort.Strings(ret)
+	return ret
+}
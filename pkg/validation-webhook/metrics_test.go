@@ -0,0 +1,132 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func scrape(t *testing.T, m *webhookMetrics) string {
+	rec := httptest.NewRecorder()
+	m.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := ioutil.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestInstrumentedAdmitter(t *testing.T) {
+	class := &volumenfsexportv1.VolumeNfsExportClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "class1"},
+		Driver:     "driver",
+		Parameters: map[string]string{"csi.storage.k8s.io/unknown-1": "a", "csi.storage.k8s.io/unknown-2": "b"},
+	}
+	raw, err := json.Marshal(class)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newWebhookMetrics()
+	admit := m.instrument(NewNfsExportAdmitter(&fakeNfsExportLister{}, nil))
+	for _, operation := range []v1.Operation{v1.Create, v1.Delete} {
+		admit.Admit(v1.AdmissionReview{
+			Request: &v1.AdmissionRequest{
+				Object:    runtime.RawExtension{Raw: raw},
+				Resource:  NfsExportClassV1GVR,
+				Operation: operation,
+			},
+		})
+	}
+
+	metrics := scrape(t, m)
+	for _, expected := range []string{
+		`nfsexport_webhook_admission_decisions_total{decision="denied",resource="volumenfsexportclasses",rule="parameters[*]"} 1`,
+		`nfsexport_webhook_admission_decisions_total{decision="allowed",resource="volumenfsexportclasses",rule=""} 1`,
+		`nfsexport_webhook_admission_duration_seconds_count{operation="CREATE",resource="volumenfsexportclasses"} 1`,
+		`nfsexport_webhook_admission_duration_seconds_count{operation="DELETE",resource="volumenfsexportclasses"} 1`,
+	} {
+		if !strings.Contains(metrics, expected) {
+			t.Errorf("expected metrics to contain %s, got:\n%s", expected, metrics)
+		}
+	}
+}
+
+func TestRuleViolationMetrics(t *testing.T) {
+	contentName := "content1"
+	emptyClassName := ""
+	nfsexport := &volumenfsexportv1.VolumeNfsExport{
+		Spec: volumenfsexportv1.VolumeNfsExportSpec{
+			Source:                   volumenfsexportv1.VolumeNfsExportSource{VolumeNfsExportContentName: &contentName},
+			Sources:                  []string{"pvc2"},
+			VolumeNfsExportClassName: &emptyClassName,
+		},
+	}
+	audited := nfsexport.DeepCopy()
+	audited.Spec.VolumeNfsExportClassName = nil
+
+	m := newWebhookMetrics()
+	admit := m.instrument(auditRules(NewNfsExportAdmitter(nil, nil), []string{"spec.sources"}))
+	admit.Admit(nfsexportReview(t, nfsexport))
+	admit.Admit(nfsexportReview(t, audited))
+
+	metrics := scrape(t, m)
+	for _, expected := range []string{
+		`nfsexport_webhook_rule_violations_total{enforced="true",kind="VolumeNfsExport",rule="spec.sources"} 1`,
+		`nfsexport_webhook_rule_violations_total{enforced="true",kind="VolumeNfsExport",rule="spec.volumeNfsExportClassName"} 1`,
+		`nfsexport_webhook_rule_violations_total{enforced="false",kind="VolumeNfsExport",rule="spec.sources"} 1`,
+		`nfsexport_webhook_admission_decisions_total{decision="audited",resource="volumenfsexports",rule="spec.sources"} 1`,
+	} {
+		if !strings.Contains(metrics, expected) {
+			t.Errorf("expected metrics to contain %s, got:\n%s", expected, metrics)
+		}
+	}
+}
+
+func TestRecoverPanics(t *testing.T) {
+	m := newWebhookMetrics()
+	handler := m.recoverPanics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/volumenfsexport", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+	if metrics := scrape(t, m); !strings.Contains(metrics, "nfsexport_webhook_panics_total 1") {
+		t.Errorf("expected the panic to be counted, got:\n%s", metrics)
+	}
+
+	// Without metrics, panics are still recovered.
+	var disabled *webhookMetrics
+	rec = httptest.NewRecorder()
+	disabled.recoverPanics(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/volumenfsexport", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+}
@@ -17,15 +17,24 @@ limitations under the License.
 package webhook
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 
 	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
 	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/features"
 	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	v1 "k8s.io/api/admission/v1"
+	core_v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 )
 
@@ -38,17 +47,50 @@ var (
 	NfsExportClassV1GVR = metav1.GroupVersionResource{Group: volumenfsexportv1.GroupName, Version: "v1", Resource: "volumenfsexportclasses"}
 )
 
+// statusIgnoredOnCreateWarning is returned to clients which create an object
+// with a status block, e.g. manifests exported from another cluster and applied
+// by a GitOps tool. The status subresource makes the API server drop the status
+// on create, and the controller never trusts a binding it did not make itself.
+const statusIgnoredOnCreateWarning = "status is ignored on create and will be populated by the nfsexport controller"
+
 type NfsExportAdmitter interface {
 	Admit(v1.AdmissionReview) *v1.AdmissionResponse
 }
 
 type admitter struct {
 	lister storagelisters.VolumeNfsExportClassLister
+	// namespaceLister is used to warn about secrets of a class in namespaces
+	// that do not exist. It is nil if the check is disabled.
+	namespaceLister corelisters.NamespaceLister
+	// contentIndexer is used to deny pre-provisioned contents whose nfsexport
+	// handle is already used by another content of the same driver. It has
+	// the utils.ContentHandleIndex, and is nil if the check is disabled.
+	contentIndexer cache.Indexer
+	// schemaLister lists the ConfigMaps in which drivers publish the schema
+	// of their class parameters, see ParametersSchemaDriverLabel. It is nil
+	// if the parameters of classes are not validated against them.
+	schemaLister corelisters.ConfigMapNamespaceLister
+	// markOnly admits nfsexports and contents failing validation, and labels
+	// them as invalid instead, like the nfsexport controller does.
+	markOnly bool
+	// skipper admits objects skipping validation. It is nil if skipping
+	// validation is disabled.
+	skipper *validationSkipper
+	// reservedMetadata denies changes to the reserved labels and annotations
+	// of nfsexports and contents. It is nil if they are not protected.
+	reservedMetadata *reservedMetadataGuard
+	// backpressure denies the creation of nfsexports while the nfsexport
+	// controller is behind. It is nil if creations are never denied.
+	backpressure *backpressure
+	// storageMigrators admits the unchanged objects written back by the
+	// storage version migrator. It is nil if they are validated.
+	storageMigrators *storageMigrators
 }
 
-func NewNfsExportAdmitter(lister storagelisters.VolumeNfsExportClassLister) NfsExportAdmitter {
+func NewNfsExportAdmitter(lister storagelisters.VolumeNfsExportClassLister, namespaceLister corelisters.NamespaceLister) NfsExportAdmitter {
 	return &admitter{
-		lister: lister,
+		lister:          lister,
+		namespaceLister: namespaceLister,
 	}
 }
 
@@ -83,7 +125,25 @@ func (a admitter) Admit(ar v1.AdmissionReview) *v1.AdmissionResponse {
 			klog.Error(err)
 			return toV1AdmissionResponse(err)
 		}
-		return decideNfsExportV1(nfsexport, oldNfsExport, isUpdate)
+		if response := a.storageMigrators.admit(ar.Request, nfsexport.Spec, oldNfsExport.Spec, nfsexport, oldNfsExport); response != nil {
+			return response
+		}
+		if response := a.backpressure.admit(ar.Request, nfsexport); response != nil {
+			return response
+		}
+		if response := a.skipper.admit(ar.Request, "VolumeNfsExport", nfsexport, oldNfsExport); response != nil {
+			return response
+		}
+		if isUpdate {
+			if errs := a.reservedMetadata.check(ar.Request, nfsexport, oldNfsExport); len(errs) > 0 {
+				return rejectV1("VolumeNfsExport", nfsexport.Name, errs)
+			}
+		}
+		response := decideNfsExportV1(nfsexport, oldNfsExport, isUpdate, a.markOnly)
+		if !isUpdate && nfsexport.Status != nil {
+			response.Warnings = append(response.Warnings, statusIgnoredOnCreateWarning)
+		}
+		return response
 	case NfsExportContentV1GVR:
 		snapcontent := &volumenfsexportv1.VolumeNfsExportContent{}
 		if _, _, err := deserializer.Decode(raw, nil, snapcontent); err != nil {
@@ -95,7 +155,22 @@ func (a admitter) Admit(ar v1.AdmissionReview) *v1.AdmissionResponse {
 			klog.Error(err)
 			return toV1AdmissionResponse(err)
 		}
-		return decideNfsExportContentV1(snapcontent, oldSnapcontent, isUpdate)
+		if response := a.storageMigrators.admit(ar.Request, snapcontent.Spec, oldSnapcontent.Spec, snapcontent, oldSnapcontent); response != nil {
+			return response
+		}
+		if response := a.skipper.admit(ar.Request, "VolumeNfsExportContent", snapcontent, oldSnapcontent); response != nil {
+			return response
+		}
+		if isUpdate {
+			if errs := a.reservedMetadata.check(ar.Request, snapcontent, oldSnapcontent); len(errs) > 0 {
+				return rejectV1("VolumeNfsExportContent", snapcontent.Name, errs)
+			}
+		}
+		response := decideNfsExportContentV1(snapcontent, oldSnapcontent, isUpdate, a.markOnly, a.contentIndexer)
+		if !isUpdate && snapcontent.Status != nil {
+			response.Warnings = append(response.Warnings, statusIgnoredOnCreateWarning)
+		}
+		return response
 	case NfsExportClassV1GVR:
 		snapClass := &volumenfsexportv1.VolumeNfsExportClass{}
 		if _, _, err := deserializer.Decode(raw, nil, snapClass); err != nil {
@@ -107,7 +182,7 @@ func (a admitter) Admit(ar v1.AdmissionReview) *v1.AdmissionResponse {
 			klog.Error(err)
 			return toV1AdmissionResponse(err)
 		}
-		return decideNfsExportClassV1(snapClass, oldSnapClass, a.lister)
+		return decideNfsExportClassV1(snapClass, oldSnapClass, a.lister, a.namespaceLister, a.schemaLister)
 	default:
 		err := fmt.Errorf("expect resource to be %s, %s or %s", NfsExportV1GVR, NfsExportContentV1GVR, NfsExportClassV1GVR)
 		klog.Error(err)
@@ -115,7 +190,7 @@ func (a admitter) Admit(ar v1.AdmissionReview) *v1.AdmissionResponse {
 	}
 }
 
-func decideNfsExportV1(nfsexport, oldNfsExport *volumenfsexportv1.VolumeNfsExport, isUpdate bool) *v1.AdmissionResponse {
+func decideNfsExportV1(nfsexport, oldNfsExport *volumenfsexportv1.VolumeNfsExport, isUpdate, markOnly bool) *v1.AdmissionResponse {
 	reviewResponse := &v1.AdmissionResponse{
 		Allowed: true,
 		Result:  &metav1.Status{},
@@ -123,22 +198,24 @@ func decideNfsExportV1(nfsexport, oldNfsExport *volumenfsexportv1.VolumeNfsExpor
 
 	if isUpdate {
 		// if it is an UPDATE and oldNfsExport is valid, check immutable fields
-		if err := checkNfsExportImmutableFieldsV1(nfsexport, oldNfsExport); err != nil {
-			reviewResponse.Allowed = false
-			reviewResponse.Result.Message = err.Error()
-			return reviewResponse
+		if errs := checkNfsExportImmutableFieldsV1(nfsexport, oldNfsExport); len(errs) > 0 {
+			return rejectV1("VolumeNfsExport", nfsexport.Name, errs)
 		}
 	}
 	// Enforce strict validation for CREATE requests. Immutable checks don't apply for CREATE requests.
 	// Enforce strict validation for UPDATE requests where old is valid and passes immutability check.
-	if err := ValidateV1NfsExport(nfsexport); err != nil {
-		reviewResponse.Allowed = false
-		reviewResponse.Result.Message = err.Error()
+	// In mark-only mode, only label the nfsexport.
+	errs := validateV1NfsExport(nfsexport)
+	if markOnly {
+		return markV1("VolumeNfsExport", nfsexport.Name, nfsexport.Labels, utils.VolumeNfsExportInvalidLabel, errs)
+	}
+	if len(errs) > 0 {
+		return rejectRulesV1("VolumeNfsExport", nfsexport.Name, errs)
 	}
 	return reviewResponse
 }
 
-func decideNfsExportContentV1(snapcontent, oldSnapcontent *volumenfsexportv1.VolumeNfsExportContent, isUpdate bool) *v1.AdmissionResponse {
+func decideNfsExportContentV1(snapcontent, oldSnapcontent *volumenfsexportv1.VolumeNfsExportContent, isUpdate, markOnly bool, contentIndexer cache.Indexer) *v1.AdmissionResponse {
 	reviewResponse := &v1.AdmissionResponse{
 		Allowed: true,
 		Result:  &metav1.Status{},
@@ -146,27 +223,66 @@ func decideNfsExportContentV1(snapcontent, oldSnapcontent *volumenfsexportv1.Vol
 
 	if isUpdate {
 		// if it is an UPDATE and oldSnapcontent is valid, check immutable fields
-		if err := checkNfsExportContentImmutableFieldsV1(snapcontent, oldSnapcontent); err != nil {
-			reviewResponse.Allowed = false
-			reviewResponse.Result.Message = err.Error()
-			return reviewResponse
+		if errs := checkNfsExportContentImmutableFieldsV1(snapcontent, oldSnapcontent); len(errs) > 0 {
+			return rejectV1("VolumeNfsExportContent", snapcontent.Name, errs)
+		}
+	} else if contentIndexer != nil {
+		// The nfsexport handle is immutable, so only a new content can
+		// duplicate the handle of another one.
+		if errs := checkDuplicateNfsExportHandleV1(snapcontent, contentIndexer); len(errs) > 0 {
+			return rejectV1("VolumeNfsExportContent", snapcontent.Name, errs)
 		}
 	}
 	// Enforce strict validation for all CREATE requests. Immutable checks don't apply for CREATE requests.
 	// Enforce strict validation for UPDATE requests where old is valid and passes immutability check.
-	if err := ValidateV1NfsExportContent(snapcontent); err != nil {
-		reviewResponse.Allowed = false
-		reviewResponse.Result.Message = err.Error()
+	// In mark-only mode, only label the content.
+	errs := validateV1NfsExportContent(snapcontent)
+	if markOnly {
+		return markV1("VolumeNfsExportContent", snapcontent.Name, snapcontent.Labels, utils.VolumeNfsExportContentInvalidLabel, errs)
+	}
+	if len(errs) > 0 {
+		return rejectRulesV1("VolumeNfsExportContent", snapcontent.Name, errs)
 	}
 	return reviewResponse
 }
 
-func decideNfsExportClassV1(snapClass, oldSnapClass *volumenfsexportv1.VolumeNfsExportClass, lister storagelisters.VolumeNfsExportClassLister) *v1.AdmissionResponse {
+func decideNfsExportClassV1(snapClass, oldSnapClass *volumenfsexportv1.VolumeNfsExportClass, lister storagelisters.VolumeNfsExportClassLister, namespaceLister corelisters.NamespaceLister, schemaLister corelisters.ConfigMapNamespaceLister) *v1.AdmissionResponse {
 	reviewResponse := &v1.AdmissionResponse{
 		Allowed: true,
 		Result:  &metav1.Status{},
 	}
 
+	// Only validate parameters that are being set, so that existing classes
+	// with invalid parameters can still be updated otherwise.
+	if !reflect.DeepEqual(snapClass.Parameters, oldSnapClass.Parameters) {
+		if errs := validateV1NfsExportClass(snapClass); len(errs) > 0 {
+			return rejectRulesV1("VolumeNfsExportClass", snapClass.Name, errs)
+		}
+		if namespaceLister != nil {
+			reviewResponse.Warnings = missingSecretNamespaceWarnings(snapClass, namespaceLister)
+		}
+	}
+
+	// The driver is only set on create, unless the class is moved to another
+	// driver.
+	if schemaLister != nil && (!reflect.DeepEqual(snapClass.Parameters, oldSnapClass.Parameters) || snapClass.Driver != oldSnapClass.Driver) {
+		if errs := validateV1NfsExportClassParametersSchema(snapClass, schemaLister); len(errs) > 0 {
+			return rejectRulesV1("VolumeNfsExportClass", snapClass.Name, errs)
+		}
+	}
+
+	if !reflect.DeepEqual(snapClass.CreationTimeout, oldSnapClass.CreationTimeout) {
+		if errs := validateV1NfsExportClassCreationTimeout(snapClass); len(errs) > 0 {
+			return rejectRulesV1("VolumeNfsExportClass", snapClass.Name, errs)
+		}
+	}
+
+	if !reflect.DeepEqual(snapClass.Encryption, oldSnapClass.Encryption) {
+		if errs := validateV1NfsExportClassEncryption(snapClass); len(errs) > 0 {
+			return rejectRulesV1("VolumeNfsExportClass", snapClass.Name, errs)
+		}
+	}
+
 	// Only Validate when a new snapClass is being set as a default.
 	if snapClass.Annotations[utils.IsDefaultNfsExportClassAnnotation] != "true" {
 		return reviewResponse
@@ -189,15 +305,100 @@ func decideNfsExportClassV1(snapClass, oldSnapClass *volumenfsexportv1.VolumeNfs
 			continue
 		}
 		if nfsexportClass.Driver == snapClass.Driver {
-			reviewResponse.Allowed = false
-			reviewResponse.Result.Message = fmt.Sprintf("default nfsexport class: %v already exits for driver: %v", nfsexportClass.Name, snapClass.Driver)
-			return reviewResponse
+			detail := fmt.Sprintf("default nfsexport class %s already exists for driver %s", nfsexportClass.Name, snapClass.Driver)
+			return rejectV1("VolumeNfsExportClass", snapClass.Name, field.ErrorList{
+				field.Invalid(field.NewPath("metadata", "annotations").Key(utils.IsDefaultNfsExportClassAnnotation), "true",
+					withHint(detail, "only one VolumeNfsExportClass per driver can be the default", nfsexportClassDocsURL)),
+			})
 		}
 	}
 
 	return reviewResponse
 }
 
+// rejectV1 returns a response denying an object of the given kind for errs.
+// The status of the response lists the path of each rejected field as a cause.
+func rejectV1(kind, name string, errs field.ErrorList) *v1.AdmissionResponse {
+	status := toInvalidError(kind, name, errs).ErrStatus
+	return &v1.AdmissionResponse{
+		Allowed: false,
+		Result:  &status,
+	}
+}
+
+// markV1 returns a response admitting an object of the given kind, with a
+// patch adding the invalid label to its labels if errs is not empty, and
+// removing it otherwise, like the nfsexport controller. Each error is returned
+// as a warning, and the status of the response lists the rejected fields as
+// for rejectV1.
+func markV1(kind, name string, objLabels map[string]string, invalidLabel string, errs field.ErrorList) *v1.AdmissionResponse {
+	reviewResponse := &v1.AdmissionResponse{
+		Allowed: true,
+		Result:  &metav1.Status{},
+	}
+	if len(errs) > 0 {
+		status := toInvalidError(kind, name, errs).ErrStatus
+		reviewResponse.Result = &status
+		for _, err := range errs {
+			reviewResponse.Warnings = append(reviewResponse.Warnings, err.Error())
+		}
+	}
+
+	_, hasLabel := objLabels[invalidLabel]
+	labelPath := "/metadata/labels/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(invalidLabel)
+	var patch []map[string]interface{}
+	switch {
+	case len(errs) > 0 && objLabels == nil:
+		patch = []map[string]interface{}{{"op": "add", "path": "/metadata/labels", "value": map[string]string{invalidLabel: ""}}}
+	case len(errs) > 0 && !hasLabel:
+		patch = []map[string]interface{}{{"op": "add", "path": labelPath, "value": ""}}
+	case len(errs) == 0 && hasLabel:
+		patch = []map[string]interface{}{{"op": "remove", "path": labelPath}}
+	default:
+		return reviewResponse
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		klog.Errorf("failed to marshal the patch of %s %s: %v", kind, name, err)
+		return reviewResponse
+	}
+	patchType := v1.PatchTypeJSONPatch
+	reviewResponse.Patch = patchBytes
+	reviewResponse.PatchType = &patchType
+	return reviewResponse
+}
+
+// missingSecretNamespaceWarnings returns a warning for each secret of the class
+// that references a namespace that does not exist. Namespaces that are
+// templates are resolved per nfsexport and are not checked.
+func missingSecretNamespaceWarnings(snapClass *volumenfsexportv1.VolumeNfsExportClass, namespaceLister corelisters.NamespaceLister) []string {
+	templates, err := utils.GetSecretNamespaceTemplates(snapClass.Parameters)
+	if err != nil {
+		return nil
+	}
+	secretKinds := make([]string, 0, len(templates))
+	for secretKind := range templates {
+		secretKinds = append(secretKinds, secretKind)
+	}
+	sort.Strings(secretKinds)
+
+	var warnings []string
+	for _, secretKind := range secretKinds {
+		namespace := templates[secretKind]
+		if strings.Contains(namespace, "${") {
+			continue
+		}
+		if _, err := namespaceLister.Get(namespace); err != nil {
+			if apierrors.IsNotFound(err) {
+				warnings = append(warnings, fmt.Sprintf("namespace %q of the %s secret does not exist", namespace, secretKind))
+			} else {
+				klog.Errorf("failed to get namespace %s: %v", namespace, err)
+			}
+		}
+	}
+	return warnings
+}
+
 func strPtrDereference(s *string) string {
 	if s == nil {
 		return "<nil string pointer>"
@@ -205,50 +406,89 @@ func strPtrDereference(s *string) string {
 	return *s
 }
 
-func checkNfsExportImmutableFieldsV1(nfsexport, oldNfsExport *volumenfsexportv1.VolumeNfsExport) error {
-	if nfsexport == nil {
-		return fmt.Errorf("VolumeNfsExport is nil")
+// cacheTierString converts a cache tier for validateImmutableField.
+func cacheTierString(tier *volumenfsexportv1.VolumeNfsExportCacheTier) *string {
+	if tier == nil {
+		return nil
 	}
-	if oldNfsExport == nil {
-		return fmt.Errorf("old VolumeNfsExport is nil")
+	s := string(*tier)
+	return &s
+}
+
+// exportModeString converts an export mode for validateImmutableField.
+func exportModeString(mode *volumenfsexportv1.VolumeNfsExportMode) *string {
+	if mode == nil {
+		return nil
 	}
+	s := string(*mode)
+	return &s
+}
+
+func checkNfsExportImmutableFieldsV1(nfsexport, oldNfsExport *volumenfsexportv1.VolumeNfsExport) field.ErrorList {
+	sourcePath := field.NewPath("spec", "source")
+	hint := "create a new VolumeNfsExport instead"
 
 	source := nfsexport.Spec.Source
 	oldSource := oldNfsExport.Spec.Source
 
-	if !reflect.DeepEqual(source.PersistentVolumeClaimName, oldSource.PersistentVolumeClaimName) {
-		return fmt.Errorf("Spec.Source.PersistentVolumeClaimName is immutable but was changed from %s to %s", strPtrDereference(oldSource.PersistentVolumeClaimName), strPtrDereference(source.PersistentVolumeClaimName))
-	}
-	if !reflect.DeepEqual(source.VolumeNfsExportContentName, oldSource.VolumeNfsExportContentName) {
-		return fmt.Errorf("Spec.Source.VolumeNfsExportContentName is immutable but was changed from %s to %s", strPtrDereference(oldSource.VolumeNfsExportContentName), strPtrDereference(source.VolumeNfsExportContentName))
-	}
-
-	return nil
+	var errs field.ErrorList
+	errs = append(errs, validateImmutableField(source.PersistentVolumeClaimName, oldSource.PersistentVolumeClaimName, sourcePath.Child("persistentVolumeClaimName"), hint)...)
+	errs = append(errs, validateImmutableField(source.VolumeNfsExportContentName, oldSource.VolumeNfsExportContentName, sourcePath.Child("volumeNfsExportContentName"), hint)...)
+	errs = append(errs, validateImmutableField(nfsexport.Spec.ExportPathHint, oldNfsExport.Spec.ExportPathHint, field.NewPath("spec", "exportPathHint"), hint)...)
+	errs = append(errs, validateImmutableField(exportModeString(nfsexport.Spec.Mode), exportModeString(oldNfsExport.Spec.Mode), field.NewPath("spec", "mode"), hint)...)
+	errs = append(errs, validateImmutableField(cacheTierString(nfsexport.Spec.CacheTier), cacheTierString(oldNfsExport.Spec.CacheTier), field.NewPath("spec", "cacheTier"), hint)...)
+	errs = append(errs, validateImmutableList(nfsexport.Spec.Sources, oldNfsExport.Spec.Sources, field.NewPath("spec", "sources"), hint)...)
+	return errs
 }
 
-func checkNfsExportContentImmutableFieldsV1(snapcontent, oldSnapcontent *volumenfsexportv1.VolumeNfsExportContent) error {
-	if snapcontent == nil {
-		return fmt.Errorf("VolumeNfsExportContent is nil")
-	}
-	if oldSnapcontent == nil {
-		return fmt.Errorf("old VolumeNfsExportContent is nil")
-	}
+func checkNfsExportContentImmutableFieldsV1(snapcontent, oldSnapcontent *volumenfsexportv1.VolumeNfsExportContent) field.ErrorList {
+	sourcePath := field.NewPath("spec", "source")
+	hint := "create a new VolumeNfsExportContent instead"
 
 	source := snapcontent.Spec.Source
 	oldSource := oldSnapcontent.Spec.Source
 
-	if !reflect.DeepEqual(source.VolumeHandle, oldSource.VolumeHandle) {
-		return fmt.Errorf("Spec.Source.VolumeHandle is immutable but was changed from %s to %s", strPtrDereference(oldSource.VolumeHandle), strPtrDereference(source.VolumeHandle))
-	}
-	if !reflect.DeepEqual(source.NfsExportHandle, oldSource.NfsExportHandle) {
-		return fmt.Errorf("Spec.Source.NfsExportHandle is immutable but was changed from %s to %s", strPtrDereference(oldSource.NfsExportHandle), strPtrDereference(source.NfsExportHandle))
-	}
+	var errs field.ErrorList
+	errs = append(errs, validateImmutableField(source.VolumeHandle, oldSource.VolumeHandle, sourcePath.Child("volumeHandle"), hint)...)
+	errs = append(errs, validateImmutableField(source.NfsExportHandle, oldSource.NfsExportHandle, sourcePath.Child("nfsexportHandle"), hint)...)
+	errs = append(errs, validateImmutableField(snapcontent.Spec.ExportPathHint, oldSnapcontent.Spec.ExportPathHint, field.NewPath("spec", "exportPathHint"), hint)...)
+	errs = append(errs, validateImmutableField(exportModeString(snapcontent.Spec.Mode), exportModeString(oldSnapcontent.Spec.Mode), field.NewPath("spec", "mode"), hint)...)
+	errs = append(errs, validateImmutableField(cacheTierString(snapcontent.Spec.CacheTier), cacheTierString(oldSnapcontent.Spec.CacheTier), field.NewPath("spec", "cacheTier"), hint)...)
+	errs = append(errs, validateImmutableList(source.VolumeHandles, oldSource.VolumeHandles, sourcePath.Child("volumeHandles"), hint)...)
 
-	if preventVolumeModeConversion {
+	if features.Enabled(features.PreventVolumeModeConversion) {
 		if !reflect.DeepEqual(snapcontent.Spec.SourceVolumeMode, oldSnapcontent.Spec.SourceVolumeMode) {
-			return fmt.Errorf("Spec.SourceVolumeMode is immutable but was changed from %v to %v", *oldSnapcontent.Spec.SourceVolumeMode, *snapcontent.Spec.SourceVolumeMode)
+			detail := fmt.Sprintf("field is immutable but was changed from %v", volumeModeDereference(oldSnapcontent.Spec.SourceVolumeMode))
+			errs = append(errs, field.Invalid(field.NewPath("spec", "sourceVolumeMode"), volumeModeDereference(snapcontent.Spec.SourceVolumeMode), withHint(detail, hint, nfsexportDocsURL)))
 		}
 	}
 
-	return nil
+	return errs
+}
+
+// checkDuplicateNfsExportHandleV1 returns an error if the nfsexport handle of a
+// pre-provisioned content is already used by another content of the same
+// driver. Two contents of the same backend export would be bound and deleted
+// independently of each other.
+func checkDuplicateNfsExportHandleV1(snapcontent *volumenfsexportv1.VolumeNfsExportContent, contentIndexer cache.Indexer) field.ErrorList {
+	duplicates, err := utils.GetDuplicateHandleContents(contentIndexer, snapcontent)
+	if err != nil {
+		klog.Errorf("failed to get the contents with the nfsexport handle of content %s: %v", snapcontent.Name, err)
+		return nil
+	}
+	if len(duplicates) == 0 {
+		return nil
+	}
+	detail := fmt.Sprintf("already used by VolumeNfsExportContent %s of driver %s", strings.Join(duplicates, ", "), snapcontent.Spec.Driver)
+	return field.ErrorList{
+		field.Invalid(field.NewPath("spec", "source", "nfsexportHandle"), *snapcontent.Spec.Source.NfsExportHandle,
+			withHint(detail, "bind a VolumeNfsExport to the existing VolumeNfsExportContent instead", nfsexportDocsURL)),
+	}
+}
+
+func volumeModeDereference(mode *core_v1.PersistentVolumeMode) string {
+	if mode == nil {
+		return "<nil volume mode pointer>"
+	}
+	return string(*mode)
 }
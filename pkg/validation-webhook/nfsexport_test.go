@@ -19,7 +19,9 @@ package webhook
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
 	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
@@ -29,6 +31,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestAdmitVolumeNfsExportV1(t *testing.T) {
@@ -37,6 +41,8 @@ func TestAdmitVolumeNfsExportV1(t *testing.T) {
 	contentname := "snapcontent1"
 	volumeNfsExportClassName := "volume-nfsexport-class-1"
 	emptyVolumeNfsExportClassName := ""
+	pointInTime := volumenfsexportv1.VolumeNfsExportModePointInTime
+	nodeLocal := volumenfsexportv1.VolumeNfsExportCacheTierNodeLocal
 
 	testCases := []struct {
 		name              string
@@ -85,7 +91,7 @@ func TestAdmitVolumeNfsExportV1(t *testing.T) {
 			},
 			shouldAdmit: false,
 			operation:   v1.Update,
-			msg:         "Spec.VolumeNfsExportClassName must not be the empty string",
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.volumeNfsExportClassName: Invalid value: \"\": must not be the empty string; omit the field to use the default VolumeNfsExportClass of the driver, see %s", nfsexportDocsURL),
 		},
 		{
 			name: "Update: old is valid and new is valid",
@@ -126,7 +132,160 @@ func TestAdmitVolumeNfsExportV1(t *testing.T) {
 			},
 			shouldAdmit: false,
 			operation:   v1.Update,
-			msg:         fmt.Sprintf("Spec.Source.VolumeNfsExportContentName is immutable but was changed from %s to %s", contentname, mutatedField),
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.source.volumeNfsExportContentName: Invalid value: \"%s\": field is immutable but was changed from %s; create a new VolumeNfsExport instead, see %s", mutatedField, contentname, nfsexportDocsURL),
+		},
+		{
+			name: "Update: old is valid and new is valid but changes immutable field spec.exportPathHint",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						VolumeNfsExportContentName: &contentname,
+					},
+					ExportPathHint: &mutatedField,
+				},
+			},
+			oldVolumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						VolumeNfsExportContentName: &contentname,
+					},
+				},
+			},
+			shouldAdmit: false,
+			operation:   v1.Update,
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.exportPathHint: Invalid value: \"%s\": field is immutable but was changed from <nil string pointer>; create a new VolumeNfsExport instead, see %s", mutatedField, nfsexportDocsURL),
+		},
+		{
+			name: "Update: old is valid and new is valid but changes immutable field spec.mode",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						VolumeNfsExportContentName: &contentname,
+					},
+					Mode: &pointInTime,
+				},
+			},
+			oldVolumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						VolumeNfsExportContentName: &contentname,
+					},
+				},
+			},
+			shouldAdmit: false,
+			operation:   v1.Update,
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.mode: Invalid value: \"PointInTime\": field is immutable but was changed from <nil string pointer>; create a new VolumeNfsExport instead, see %s", nfsexportDocsURL),
+		},
+		{
+			name: "Update: old is valid and new is valid but changes immutable field spec.cacheTier",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						PersistentVolumeClaimName: &pvcname,
+					},
+					CacheTier: &nodeLocal,
+				},
+			},
+			oldVolumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						PersistentVolumeClaimName: &pvcname,
+					},
+				},
+			},
+			shouldAdmit: false,
+			operation:   v1.Update,
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.cacheTier: Invalid value: \"NodeLocal\": field is immutable but was changed from <nil string pointer>; create a new VolumeNfsExport instead, see %s", nfsexportDocsURL),
+		},
+		{
+			name: "Create: cache tier of a pre-provisioned nfsexport",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						VolumeNfsExportContentName: &contentname,
+					},
+					CacheTier: &nodeLocal,
+				},
+			},
+			shouldAdmit: false,
+			operation:   v1.Create,
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.cacheTier: Forbidden: may only be set with spec.source.persistentVolumeClaimName; remove the field, the cache tier of a pre-provisioned export is set when it is created on the storage system, see %s", nfsexportDocsURL),
+		},
+		{
+			name: "Create: cache tier of a dynamically provisioned nfsexport",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						PersistentVolumeClaimName: &pvcname,
+					},
+					CacheTier: &nodeLocal,
+				},
+			},
+			shouldAdmit: true,
+			operation:   v1.Create,
+		},
+		{
+			name: "Create: sources without a source PVC",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						VolumeNfsExportContentName: &contentname,
+					},
+					Sources: []string{"pvcname2"},
+				},
+			},
+			shouldAdmit: false,
+			operation:   v1.Create,
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.sources: Forbidden: may only be set with spec.source.persistentVolumeClaimName; set the first PVC to export in spec.source.persistentVolumeClaimName, see %s", nfsexportDocsURL),
+		},
+		{
+			name: "Create: sources repeating the source PVC",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						PersistentVolumeClaimName: &pvcname,
+					},
+					Sources: []string{"pvcname2", pvcname},
+				},
+			},
+			shouldAdmit: false,
+			operation:   v1.Create,
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.sources[1]: Duplicate value: \"%s\"", pvcname),
+		},
+		{
+			name: "Create: sources merged with the source PVC",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						PersistentVolumeClaimName: &pvcname,
+					},
+					Sources: []string{"pvcname2", "pvcname3"},
+				},
+			},
+			shouldAdmit: true,
+			operation:   v1.Create,
+		},
+		{
+			name: "Update: old is valid and new is valid but changes immutable field spec.sources",
+			volumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						PersistentVolumeClaimName: &pvcname,
+					},
+					Sources: []string{"pvcname2", "pvcname3"},
+				},
+			},
+			oldVolumeNfsExport: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						PersistentVolumeClaimName: &pvcname,
+					},
+					Sources: []string{"pvcname2"},
+				},
+			},
+			shouldAdmit: false,
+			operation:   v1.Update,
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.sources: Invalid value: []string{\"pvcname2\", \"pvcname3\"}: field is immutable but was changed from [pvcname2]; create a new VolumeNfsExport instead, see %s", nfsexportDocsURL),
 		},
 		{
 			name: "Update: old is invalid and new is valid",
@@ -147,7 +306,7 @@ func TestAdmitVolumeNfsExportV1(t *testing.T) {
 			},
 			shouldAdmit: false,
 			operation:   v1.Update,
-			msg:         fmt.Sprintf("Spec.Source.PersistentVolumeClaimName is immutable but was changed from %s to <nil string pointer>", pvcname),
+			msg:         fmt.Sprintf("VolumeNfsExport.nfsexport.storage.k8s.io \"\" is invalid: spec.source.persistentVolumeClaimName: Invalid value: \"<nil string pointer>\": field is immutable but was changed from %s; create a new VolumeNfsExport instead, see %s", pvcname, nfsexportDocsURL),
 		},
 		{
 			// will be handled by schema validation
@@ -197,7 +356,7 @@ func TestAdmitVolumeNfsExportV1(t *testing.T) {
 					Operation: tc.operation,
 				},
 			}
-			sa := NewNfsExportAdmitter(nil)
+			sa := NewNfsExportAdmitter(nil, nil)
 			response := sa.Admit(review)
 			shouldAdmit := response.Allowed
 			msg := response.Result.Message
@@ -273,7 +432,7 @@ func TestAdmitVolumeNfsExportContentV1(t *testing.T) {
 			oldVolumeNfsExportContent: validContent,
 			shouldAdmit:              false,
 			operation:                v1.Update,
-			msg:                      fmt.Sprintf("Spec.Source.VolumeHandle is immutable but was changed from %s to %s", strPtrDereference(nil), volumeHandle),
+			msg:                      fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"\" is invalid: spec.source.volumeHandle: Invalid value: \"%s\": field is immutable but was changed from %s; create a new VolumeNfsExportContent instead, see %s", volumeHandle, strPtrDereference(nil), nfsexportDocsURL),
 		},
 		{
 			name:                     "Update: old is valid and new is valid",
@@ -298,7 +457,25 @@ func TestAdmitVolumeNfsExportContentV1(t *testing.T) {
 			oldVolumeNfsExportContent: validContent,
 			shouldAdmit:              false,
 			operation:                v1.Update,
-			msg:                      fmt.Sprintf("Spec.Source.NfsExportHandle is immutable but was changed from %s to %s", nfsexportHandle, modifiedField),
+			msg:                      fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"\" is invalid: spec.source.nfsexportHandle: Invalid value: \"%s\": field is immutable but was changed from %s; create a new VolumeNfsExportContent instead, see %s", modifiedField, nfsexportHandle, nfsexportDocsURL),
+		},
+		{
+			name: "Create: volume handles without a volume handle",
+			volumeNfsExportContent: &volumenfsexportv1.VolumeNfsExportContent{
+				Spec: volumenfsexportv1.VolumeNfsExportContentSpec{
+					Source: volumenfsexportv1.VolumeNfsExportContentSource{
+						NfsExportHandle: &nfsexportHandle,
+						VolumeHandles:   []string{"volumeHandle2"},
+					},
+					VolumeNfsExportRef: core_v1.ObjectReference{
+						Name:      "nfsexport-ref",
+						Namespace: "default-ns",
+					},
+				},
+			},
+			shouldAdmit: false,
+			operation:   v1.Create,
+			msg:         fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"\" is invalid: spec.source.volumeHandles: Forbidden: may only be set with spec.source.volumeHandle; set the handle of the first volume to export in spec.source.volumeHandle, see %s", nfsexportDocsURL),
 		},
 		{
 			name:                     "Update: old is invalid and new is valid",
@@ -306,7 +483,7 @@ func TestAdmitVolumeNfsExportContentV1(t *testing.T) {
 			oldVolumeNfsExportContent: invalidContent,
 			shouldAdmit:              false,
 			operation:                v1.Update,
-			msg:                      fmt.Sprintf("Spec.Source.VolumeHandle is immutable but was changed from %s to <nil string pointer>", volumeHandle),
+			msg:                      fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"\" is invalid: spec.source.volumeHandle: Invalid value: \"<nil string pointer>\": field is immutable but was changed from %s; create a new VolumeNfsExportContent instead, see %s", volumeHandle, nfsexportDocsURL),
 		},
 		{
 			name:                     "Update: old is invalid and new is invalid",
@@ -314,7 +491,30 @@ func TestAdmitVolumeNfsExportContentV1(t *testing.T) {
 			oldVolumeNfsExportContent: invalidContent,
 			shouldAdmit:              false,
 			operation:                v1.Update,
-			msg:                      fmt.Sprintf("both Spec.VolumeNfsExportRef.Name =  and Spec.VolumeNfsExportRef.Namespace = default-ns must be set"),
+			msg:                      fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"\" is invalid: spec.volumeNfsExportRef.name: Required value: must be set; set both the name and the namespace of the VolumeNfsExport the content is bound to, see %s", nfsexportDocsURL),
+		},
+		{
+			name:                     "Update: retained content handed over to another namespace",
+			volumeNfsExportContent:    withRebindTo(validContent, volumenfsexportv1.VolumeNfsExportContentRetain, "other-ns/nfsexport-ref"),
+			oldVolumeNfsExportContent: validContent,
+			shouldAdmit:              true,
+			operation:                v1.Update,
+		},
+		{
+			name:                     "Update: content with Delete policy handed over",
+			volumeNfsExportContent:    withRebindTo(validContent, volumenfsexportv1.VolumeNfsExportContentDelete, "other-ns/nfsexport-ref"),
+			oldVolumeNfsExportContent: validContent,
+			shouldAdmit:              false,
+			operation:                v1.Update,
+			msg:                      fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"\" is invalid: metadata.annotations[nfsexport.storage.kubernetes.io/rebind-to]: Invalid value: \"other-ns/nfsexport-ref\": may only be set on a content with deletion policy Retain; set spec.deletionPolicy to Retain first, see %s", nfsexportDocsURL),
+		},
+		{
+			name:                     "Create: retained content handed over to an invalid target",
+			volumeNfsExportContent:    withRebindTo(validContent, volumenfsexportv1.VolumeNfsExportContentRetain, "Other_NS/nfsexport-ref"),
+			oldVolumeNfsExportContent: nil,
+			shouldAdmit:              false,
+			operation:                v1.Create,
+			msg:                      fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"\" is invalid: metadata.annotations[nfsexport.storage.kubernetes.io/rebind-to]: Invalid value: \"Other_NS/nfsexport-ref\": must be <namespace>/<name> of a VolumeNfsExport; set it to the <namespace>/<name> of the VolumeNfsExport to hand the content over to, see %s", nfsexportDocsURL),
 		},
 	}
 
@@ -342,7 +542,7 @@ func TestAdmitVolumeNfsExportContentV1(t *testing.T) {
 					Operation: tc.operation,
 				},
 			}
-			sa := NewNfsExportAdmitter(nil)
+			sa := NewNfsExportAdmitter(nil, nil)
 			response := sa.Admit(review)
 			shouldAdmit := response.Allowed
 			msg := response.Result.Message
@@ -360,6 +560,15 @@ func TestAdmitVolumeNfsExportContentV1(t *testing.T) {
 	}
 }
 
+// withRebindTo returns a copy of content with the given deletion policy, handed
+// over to the VolumeNfsExport target.
+func withRebindTo(content *volumenfsexportv1.VolumeNfsExportContent, policy volumenfsexportv1.DeletionPolicy, target string) *volumenfsexportv1.VolumeNfsExportContent {
+	content = content.DeepCopy()
+	content.Spec.DeletionPolicy = policy
+	content.Annotations = map[string]string{utils.AnnVolumeNfsExportRebindTo: target}
+	return content
+}
+
 type fakeNfsExportLister struct {
 	values []*volumenfsexportv1.VolumeNfsExportClass
 }
@@ -378,6 +587,8 @@ func (f *fakeNfsExportLister) Get(name string) (*volumenfsexportv1.VolumeNfsExpo
 }
 
 func TestAdmitVolumeNfsExportClassV1(t *testing.T) {
+	krb5pTransport := volumenfsexportv1.NfsExportTransportKrb5p
+	tlsTransport := volumenfsexportv1.NfsExportTransport("tls")
 	testCases := []struct {
 		name                   string
 		volumeNfsExportClass    *volumenfsexportv1.VolumeNfsExportClass
@@ -387,6 +598,77 @@ func TestAdmitVolumeNfsExportClassV1(t *testing.T) {
 		operation              v1.Operation
 		lister                 storagelisters.VolumeNfsExportClassLister
 	}{
+		{
+			name: "class with a creation timeout",
+			volumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{
+				Driver:          "test.csi.io",
+				CreationTimeout: &metav1.Duration{Duration: time.Hour},
+			},
+			oldVolumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{},
+			shouldAdmit:            true,
+			msg:                    "",
+			operation:              v1.Create,
+			lister:                 &fakeNfsExportLister{values: []*volumenfsexportv1.VolumeNfsExportClass{}},
+		},
+		{
+			name: "class with a negative creation timeout",
+			volumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{
+				Driver:          "test.csi.io",
+				CreationTimeout: &metav1.Duration{Duration: -time.Hour},
+			},
+			oldVolumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{},
+			shouldAdmit:            false,
+			msg:                    fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: creationTimeout: Invalid value: \"-1h0m0s\": must be greater than 0; omit the field to wait for exports indefinitely, see %s", nfsexportClassDocsURL),
+			operation:              v1.Create,
+			lister:                 &fakeNfsExportLister{values: []*volumenfsexportv1.VolumeNfsExportClass{}},
+		},
+		{
+			name: "class with encryption",
+			volumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{
+				Driver: "test.csi.io",
+				Encryption: &volumenfsexportv1.NfsExportEncryption{
+					Transport: &krb5pTransport,
+					AtRest: &volumenfsexportv1.NfsExportAtRestEncryption{
+						KMSKeySecretRef: core_v1.SecretReference{Name: "kms-key", Namespace: "kms"},
+					},
+				},
+			},
+			oldVolumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{},
+			shouldAdmit:            true,
+			msg:                    "",
+			operation:              v1.Create,
+			lister:                 &fakeNfsExportLister{values: []*volumenfsexportv1.VolumeNfsExportClass{}},
+		},
+		{
+			name: "class with an unknown transport",
+			volumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{
+				Driver: "test.csi.io",
+				Encryption: &volumenfsexportv1.NfsExportEncryption{
+					Transport: &tlsTransport,
+				},
+			},
+			oldVolumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{},
+			shouldAdmit:            false,
+			msg:                    fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: encryption.transport: Invalid value: \"tls\": must be one of krb5p, stunnel, none; omit the field to use the default of the driver, see %s", nfsexportClassDocsURL),
+			operation:              v1.Create,
+			lister:                 &fakeNfsExportLister{values: []*volumenfsexportv1.VolumeNfsExportClass{}},
+		},
+		{
+			name: "class with a KMS key secret without a namespace",
+			volumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{
+				Driver: "test.csi.io",
+				Encryption: &volumenfsexportv1.NfsExportEncryption{
+					AtRest: &volumenfsexportv1.NfsExportAtRestEncryption{
+						KMSKeySecretRef: core_v1.SecretReference{Name: "kms-key"},
+					},
+				},
+			},
+			oldVolumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{},
+			shouldAdmit:            false,
+			msg:                    fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: encryption.atRest.kmsKeySecretRef.namespace: Required value: must be set; set both the name and the namespace of the Secret holding the KMS key reference, see %s", nfsexportClassDocsURL),
+			operation:              v1.Create,
+			lister:                 &fakeNfsExportLister{values: []*volumenfsexportv1.VolumeNfsExportClass{}},
+		},
 		{
 			name: "new default for class with no existing classes",
 			volumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{
@@ -444,7 +726,7 @@ func TestAdmitVolumeNfsExportClassV1(t *testing.T) {
 			},
 			oldVolumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{},
 			shouldAdmit:            false,
-			msg:                    "default nfsexport class: driver-a already exits for driver: test.csi.io",
+			msg:                    fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: metadata.annotations[%s]: Invalid value: \"true\": default nfsexport class driver-a already exists for driver test.csi.io; only one VolumeNfsExportClass per driver can be the default, see %s", utils.IsDefaultNfsExportClassAnnotation, nfsexportClassDocsURL),
 			operation:              v1.Create,
 			lister: &fakeNfsExportLister{values: []*volumenfsexportv1.VolumeNfsExportClass{
 				{
@@ -530,7 +812,7 @@ func TestAdmitVolumeNfsExportClassV1(t *testing.T) {
 			},
 			oldVolumeNfsExportClass: &volumenfsexportv1.VolumeNfsExportClass{},
 			shouldAdmit:            false,
-			msg:                    "default nfsexport class: driver-is-default already exits for driver: test.csi.io",
+			msg:                    fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: metadata.annotations[%s]: Invalid value: \"true\": default nfsexport class driver-is-default already exists for driver test.csi.io; only one VolumeNfsExportClass per driver can be the default, see %s", utils.IsDefaultNfsExportClassAnnotation, nfsexportClassDocsURL),
 			operation:              v1.Create,
 			lister: &fakeNfsExportLister{values: []*volumenfsexportv1.VolumeNfsExportClass{
 				{
@@ -575,7 +857,7 @@ func TestAdmitVolumeNfsExportClassV1(t *testing.T) {
 				Driver: "test.csi.io",
 			},
 			shouldAdmit: false,
-			msg:         "default nfsexport class: driver-test-default already exits for driver: driver.test.csi.io",
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: metadata.annotations[%s]: Invalid value: \"true\": default nfsexport class driver-test-default already exists for driver driver.test.csi.io; only one VolumeNfsExportClass per driver can be the default, see %s", utils.IsDefaultNfsExportClassAnnotation, nfsexportClassDocsURL),
 			operation:   v1.Update,
 			lister: &fakeNfsExportLister{values: []*volumenfsexportv1.VolumeNfsExportClass{
 				{
@@ -626,7 +908,7 @@ func TestAdmitVolumeNfsExportClassV1(t *testing.T) {
 					Operation: tc.operation,
 				},
 			}
-			sa := NewNfsExportAdmitter(tc.lister)
+			sa := NewNfsExportAdmitter(tc.lister, nil)
 			response := sa.Admit(review)
 
 			shouldAdmit := response.Allowed
@@ -644,3 +926,606 @@ func TestAdmitVolumeNfsExportClassV1(t *testing.T) {
 		})
 	}
 }
+
+func TestAdmitVolumeNfsExportClassParametersV1(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(&core_v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}); err != nil {
+		t.Fatal(err)
+	}
+	namespaceLister := corelisters.NewNamespaceLister(indexer)
+
+	testCases := []struct {
+		name             string
+		parameters       map[string]string
+		oldParameters    map[string]string
+		shouldAdmit      bool
+		msg              string
+		expectedWarnings []string
+	}{
+		{
+			name: "complete secret references",
+			parameters: map[string]string{
+				utils.PrefixedNfsExportterSecretNameKey:          "secret",
+				utils.PrefixedNfsExportterSecretNamespaceKey:     "default",
+				utils.PrefixedNfsExportterListSecretNameKey:      "${volumenfsexportcontent.name}",
+				utils.PrefixedNfsExportterListSecretNamespaceKey: "${volumenfsexport.namespace}",
+				"driver-param": "value",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "secret name without namespace",
+			parameters: map[string]string{
+				utils.PrefixedNfsExportterSecretNameKey: "secret",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters: Required value: either name and namespace for NfsExportter secrets specified, Both must be specified; set both the name and the namespace parameters of the secret, see %s", secretsDocsURL),
+		},
+		{
+			name: "empty secret namespace",
+			parameters: map[string]string{
+				utils.PrefixedNfsExportterListSecretNameKey:      "secret",
+				utils.PrefixedNfsExportterListSecretNamespaceKey: "",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters: Required value: NfsExportterList secrets specified in parameters but value of either namespace or name is empty; set both the name and the namespace parameters of the secret, see %s", secretsDocsURL),
+		},
+		{
+			name: "secret of the namespace of the nfsexport",
+			parameters: map[string]string{
+				utils.PrefixedNfsExportterSecretNameKey:      "${volumenfsexport.name}-creds",
+				utils.PrefixedNfsExportterSecretNamespaceKey: "${volumenfsexport.namespace}",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "secret name chosen by the user of the nfsexport in another namespace",
+			parameters: map[string]string{
+				utils.PrefixedNfsExportterSecretNameKey:      "${volumenfsexport.name}",
+				utils.PrefixedNfsExportterSecretNamespaceKey: "default",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/nfsexporter-secret-name]: Forbidden: NfsExportter secret name \"${volumenfsexport.name}\" is chosen by the user of the VolumeNfsExport, who could read any secret of namespace \"default\"; set the secret namespace to ${volumenfsexport.namespace} to read the secrets of the namespace of each VolumeNfsExport, see %s", secretsDocsURL),
+		},
+		{
+			name: "unknown prefixed key",
+			parameters: map[string]string{
+				"csi.storage.k8s.io/nfsexporter-secret-nmae": "secret",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/nfsexporter-secret-nmae]: Invalid value: \"secret\": found unknown parameter key \"csi.storage.k8s.io/nfsexporter-secret-nmae\" with reserved namespace csi.storage.k8s.io/; check the spelling of the key or remove it, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "valid export path hint pattern",
+			parameters: map[string]string{
+				utils.PrefixedExportPathHintPatternKey: `team-[a-z]+/[a-z0-9-]+`,
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "invalid export path hint pattern",
+			parameters: map[string]string{
+				utils.PrefixedExportPathHintPatternKey: `team-[a-z+`,
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-path-hint-pattern]: Invalid value: \"team-[a-z+\": invalid csi.storage.k8s.io/export-path-hint-pattern \"team-[a-z+\": error parsing regexp: missing closing ]: `[a-z+)$`; set a valid RE2 regular expression, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "valid export modes",
+			parameters: map[string]string{
+				utils.PrefixedExportModesKey: "Live,PointInTime",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "invalid export modes",
+			parameters: map[string]string{
+				utils.PrefixedExportModesKey: "Live,Clone",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-modes]: Invalid value: \"Live,Clone\": invalid csi.storage.k8s.io/export-modes \"Live,Clone\": unknown mode \"Clone\", supported modes are \"Live\" and \"PointInTime\"; list modes among Live and PointInTime, separated by commas, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "valid export cache tiers",
+			parameters: map[string]string{
+				utils.PrefixedExportCacheTiersKey: "NodeLocal",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "invalid export cache tiers",
+			parameters: map[string]string{
+				utils.PrefixedExportCacheTiersKey: "NodeLocal,Remote",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-cache-tiers]: Invalid value: \"NodeLocal,Remote\": invalid csi.storage.k8s.io/export-cache-tiers \"NodeLocal,Remote\": unknown cache tier \"Remote\", the supported cache tier is \"NodeLocal\"; list the cache tiers the driver supports, separated by commas, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "export warm-up requested",
+			parameters: map[string]string{
+				utils.PrefixedExportWarmUpKey: "true",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "invalid export warm-up",
+			parameters: map[string]string{
+				utils.PrefixedExportWarmUpKey: "eager",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-warm-up]: Invalid value: \"eager\": invalid csi.storage.k8s.io/export-warm-up \"eager\": strconv.ParseBool: parsing \"eager\": invalid syntax; set it to true or false, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "export security context from the source pod",
+			parameters: map[string]string{
+				utils.PrefixedExportSecurityContextSourceKey: utils.ExportSecurityContextSourcePod,
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "invalid export security context source",
+			parameters: map[string]string{
+				utils.PrefixedExportSecurityContextSourceKey: "Node",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-security-context-source]: Invalid value: \"Node\": invalid csi.storage.k8s.io/export-security-context-source \"Node\", the only supported value is \"SourcePod\"; remove the parameter to keep the security context of the driver, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "application consistent exports",
+			parameters: map[string]string{
+				utils.PrefixedExportConsistencyKey:    utils.ExportConsistencyApplication,
+				utils.PrefixedExportQuiesceTimeoutKey: "30s",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "invalid export consistency",
+			parameters: map[string]string{
+				utils.PrefixedExportConsistencyKey: "FileSystemConsistent",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-consistency]: Invalid value: \"FileSystemConsistent\": invalid csi.storage.k8s.io/export-consistency \"FileSystemConsistent\", supported values are \"CrashConsistent\" and \"ApplicationConsistent\"; set it to CrashConsistent or ApplicationConsistent, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "invalid export quiesce timeout",
+			parameters: map[string]string{
+				utils.PrefixedExportQuiesceTimeoutKey: "-1m",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-quiesce-timeout]: Invalid value: \"-1m\": invalid csi.storage.k8s.io/export-quiesce-timeout \"-1m\": must be positive; set a positive duration such as 30s, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "no writers consistency gate",
+			parameters: map[string]string{
+				utils.PrefixedExportConsistencyGateKey:        utils.ExportConsistencyGateNoWriters,
+				utils.PrefixedExportConsistencyGateTimeoutKey: "30m",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "invalid export consistency gate",
+			parameters: map[string]string{
+				utils.PrefixedExportConsistencyGateKey: "NoReaders",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-consistency-gate]: Invalid value: \"NoReaders\": invalid csi.storage.k8s.io/export-consistency-gate \"NoReaders\", the supported value is \"NoWriters\"; set it to NoWriters or remove the parameter, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "invalid export consistency gate timeout",
+			parameters: map[string]string{
+				utils.PrefixedExportConsistencyGateTimeoutKey: "soon",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-consistency-gate-timeout]: Invalid value: \"soon\": invalid csi.storage.k8s.io/export-consistency-gate-timeout \"soon\": time: invalid duration \"soon\"; set a positive duration such as 10m, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "propagated annotations and labels",
+			parameters: map[string]string{
+				utils.PrefixedExportPropagatedAnnotationsKey: "example.com/ticket, owner",
+				utils.PrefixedExportPropagatedLabelsKey:      "app",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "invalid propagated annotation key",
+			parameters: map[string]string{
+				utils.PrefixedExportPropagatedAnnotationsKey: "example.com/ticket,",
+			},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"\" is invalid: parameters[csi.storage.k8s.io/export-propagated-annotations]: Invalid value: \"example.com/ticket,\": invalid csi.storage.k8s.io/export-propagated-annotations \"example.com/ticket,\": key \"\": name part must be non-empty, name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]'); list qualified annotation or label keys, separated by commas, see %s", nfsexportClassDocsURL),
+		},
+		{
+			name: "unchanged invalid parameters are not validated",
+			parameters: map[string]string{
+				"csi.storage.k8s.io/nfsexporter-secret-nmae": "secret",
+			},
+			oldParameters: map[string]string{
+				"csi.storage.k8s.io/nfsexporter-secret-nmae": "secret",
+			},
+			shouldAdmit: true,
+		},
+		{
+			name: "missing secret namespace is a warning",
+			parameters: map[string]string{
+				utils.PrefixedNfsExportterSecretNameKey:      "secret",
+				utils.PrefixedNfsExportterSecretNamespaceKey: "missing",
+			},
+			shouldAdmit:      true,
+			expectedWarnings: []string{`namespace "missing" of the NfsExportter secret does not exist`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExportClass{Driver: "test.csi.io", Parameters: tc.parameters})
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldRaw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExportClass{Driver: "test.csi.io", Parameters: tc.oldParameters})
+			if err != nil {
+				t.Fatal(err)
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw: raw,
+					},
+					OldObject: runtime.RawExtension{
+						Raw: oldRaw,
+					},
+					Resource:  NfsExportClassV1GVR,
+					Operation: v1.Update,
+				},
+			}
+			sa := NewNfsExportAdmitter(&fakeNfsExportLister{}, namespaceLister)
+			response := sa.Admit(review)
+
+			if response.Allowed != tc.shouldAdmit {
+				t.Errorf("expected \"%v\" to equal \"%v\"", response.Allowed, tc.shouldAdmit)
+			}
+			if response.Result.Message != tc.msg {
+				t.Errorf("expected \"%v\" to equal \"%v\"", response.Result.Message, tc.msg)
+			}
+			if !reflect.DeepEqual(response.Warnings, tc.expectedWarnings) {
+				t.Errorf("expected warnings %v, got %v", tc.expectedWarnings, response.Warnings)
+			}
+		})
+	}
+}
+
+func TestAdmitStatusOnCreate(t *testing.T) {
+	contentname := "snapcontent1"
+	nfsexportHandle := "nfsexportHandle1"
+	ready := true
+
+	testCases := []struct {
+		name            string
+		obj             interface{}
+		resource        metav1.GroupVersionResource
+		operation       v1.Operation
+		expectedWarning bool
+	}{
+		{
+			name: "Create nfsexport without status",
+			obj: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						VolumeNfsExportContentName: &contentname,
+					},
+				},
+			},
+			resource:        NfsExportV1GVR,
+			operation:       v1.Create,
+			expectedWarning: false,
+		},
+		{
+			name: "Create nfsexport with a bound status",
+			obj: &volumenfsexportv1.VolumeNfsExport{
+				Spec: volumenfsexportv1.VolumeNfsExportSpec{
+					Source: volumenfsexportv1.VolumeNfsExportSource{
+						VolumeNfsExportContentName: &contentname,
+					},
+				},
+				Status: &volumenfsexportv1.VolumeNfsExportStatus{
+					BoundVolumeNfsExportContentName: &contentname,
+					ReadyToUse:                      &ready,
+				},
+			},
+			resource:        NfsExportV1GVR,
+			operation:       v1.Create,
+			expectedWarning: true,
+		},
+		{
+			name: "Create content with status",
+			obj: &volumenfsexportv1.VolumeNfsExportContent{
+				Spec: volumenfsexportv1.VolumeNfsExportContentSpec{
+					Source: volumenfsexportv1.VolumeNfsExportContentSource{
+						NfsExportHandle: &nfsexportHandle,
+					},
+					VolumeNfsExportRef: core_v1.ObjectReference{
+						Name:      "nfsexport-ref",
+						Namespace: "default-ns",
+					},
+				},
+				Status: &volumenfsexportv1.VolumeNfsExportContentStatus{
+					ReadyToUse: &ready,
+				},
+			},
+			resource:        NfsExportContentV1GVR,
+			operation:       v1.Create,
+			expectedWarning: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(tc.obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Object: runtime.RawExtension{
+						Raw: raw,
+					},
+					Resource:  tc.resource,
+					Operation: tc.operation,
+				},
+			}
+			sa := NewNfsExportAdmitter(nil, nil)
+			response := sa.Admit(review)
+			if !response.Allowed {
+				t.Errorf("expected request to be admitted, got: %v", response.Result.Message)
+			}
+			gotWarning := len(response.Warnings) == 1 && response.Warnings[0] == statusIgnoredOnCreateWarning
+			if gotWarning != tc.expectedWarning {
+				t.Errorf("expected warning %v, got warnings %v", tc.expectedWarning, response.Warnings)
+			}
+		})
+	}
+}
+
+func TestAdmitRejectionCauses(t *testing.T) {
+	raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "content1"},
+		Spec: volumenfsexportv1.VolumeNfsExportContentSpec{
+			VolumeNfsExportRef: core_v1.ObjectReference{},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := v1.AdmissionReview{
+		Request: &v1.AdmissionRequest{
+			Object:    runtime.RawExtension{Raw: raw},
+			Resource:  NfsExportContentV1GVR,
+			Operation: v1.Create,
+		},
+	}
+	response := NewNfsExportAdmitter(nil, nil).Admit(review)
+	if response.Allowed {
+		t.Fatalf("expected request to be denied")
+	}
+	if response.Result.Reason != metav1.StatusReasonInvalid || response.Result.Details == nil {
+		t.Fatalf("expected an Invalid status with details, got %+v", response.Result)
+	}
+	var fields []string
+	for _, cause := range response.Result.Details.Causes {
+		fields = append(fields, cause.Field)
+	}
+	expected := []string{"spec.volumeNfsExportRef.name", "spec.volumeNfsExportRef.namespace"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected causes for fields %v, got %v", expected, fields)
+	}
+	if response.Result.Details.Name != "content1" || response.Result.Details.Kind != "VolumeNfsExportContent" {
+		t.Errorf("expected details of VolumeNfsExportContent content1, got %+v", response.Result.Details)
+	}
+}
+
+func TestAdmitMarkOnly(t *testing.T) {
+	pvcname := "pvcname1"
+	emptyVolumeNfsExportClassName := ""
+	invalidSpec := volumenfsexportv1.VolumeNfsExportSpec{
+		Source: volumenfsexportv1.VolumeNfsExportSource{
+			PersistentVolumeClaimName: &pvcname,
+		},
+		VolumeNfsExportClassName: &emptyVolumeNfsExportClassName,
+	}
+	validSpec := volumenfsexportv1.VolumeNfsExportSpec{
+		Source: volumenfsexportv1.VolumeNfsExportSource{
+			PersistentVolumeClaimName: &pvcname,
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		labels        map[string]string
+		spec          volumenfsexportv1.VolumeNfsExportSpec
+		expectedPatch string
+		expectWarning bool
+	}{
+		{
+			name:          "invalid without labels",
+			spec:          invalidSpec,
+			expectedPatch: `[{"op":"add","path":"/metadata/labels","value":{"nfsexport.storage.kubernetes.io/invalid-nfsexport-resource":""}}]`,
+			expectWarning: true,
+		},
+		{
+			name:          "invalid with other labels",
+			labels:        map[string]string{"app": "db"},
+			spec:          invalidSpec,
+			expectedPatch: `[{"op":"add","path":"/metadata/labels/nfsexport.storage.kubernetes.io~1invalid-nfsexport-resource","value":""}]`,
+			expectWarning: true,
+		},
+		{
+			name:          "invalid and already labeled",
+			labels:        map[string]string{utils.VolumeNfsExportInvalidLabel: ""},
+			spec:          invalidSpec,
+			expectWarning: true,
+		},
+		{
+			name:          "valid and labeled",
+			labels:        map[string]string{utils.VolumeNfsExportInvalidLabel: ""},
+			spec:          validSpec,
+			expectedPatch: `[{"op":"remove","path":"/metadata/labels/nfsexport.storage.kubernetes.io~1invalid-nfsexport-resource"}]`,
+		},
+		{
+			name: "valid",
+			spec: validSpec,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExport{
+				ObjectMeta: metav1.ObjectMeta{Name: "snap1", Labels: tc.labels},
+				Spec:       tc.spec,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Object:    runtime.RawExtension{Raw: raw},
+					Resource:  NfsExportV1GVR,
+					Operation: v1.Create,
+				},
+			}
+			sa := &admitter{markOnly: true}
+			response := sa.Admit(review)
+			if !response.Allowed {
+				t.Fatalf("expected request to be admitted, got %+v", response.Result)
+			}
+			if string(response.Patch) != tc.expectedPatch {
+				t.Errorf("expected patch %s, got %s", tc.expectedPatch, response.Patch)
+			}
+			if (response.PatchType != nil) != (tc.expectedPatch != "") {
+				t.Errorf("expected patch type to be set only with a patch, got %v", response.PatchType)
+			}
+			if (len(response.Warnings) > 0) != tc.expectWarning {
+				t.Errorf("expected warnings: %v, got %v", tc.expectWarning, response.Warnings)
+			}
+		})
+	}
+}
+
+func TestAdmitMarkOnlyImmutableFields(t *testing.T) {
+	pvcname := "pvcname1"
+	mutatedField := "changed-immutable-field"
+	nfsexport := func(source string) []byte {
+		raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExport{
+			ObjectMeta: metav1.ObjectMeta{Name: "snap1"},
+			Spec: volumenfsexportv1.VolumeNfsExportSpec{
+				Source: volumenfsexportv1.VolumeNfsExportSource{PersistentVolumeClaimName: &source},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return raw
+	}
+	review := v1.AdmissionReview{
+		Request: &v1.AdmissionRequest{
+			Object:    runtime.RawExtension{Raw: nfsexport(mutatedField)},
+			OldObject: runtime.RawExtension{Raw: nfsexport(pvcname)},
+			Resource:  NfsExportV1GVR,
+			Operation: v1.Update,
+		},
+	}
+	sa := &admitter{markOnly: true}
+	if response := sa.Admit(review); response.Allowed {
+		t.Errorf("expected a change of an immutable field to be denied in mark-only mode")
+	}
+}
+
+func TestAdmitDuplicateNfsExportHandle(t *testing.T) {
+	newContent := func(name, driver, nfsexportHandle string) *volumenfsexportv1.VolumeNfsExportContent {
+		return &volumenfsexportv1.VolumeNfsExportContent{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: volumenfsexportv1.VolumeNfsExportContentSpec{
+				Driver: driver,
+				Source: volumenfsexportv1.VolumeNfsExportContentSource{NfsExportHandle: &nfsexportHandle},
+				VolumeNfsExportRef: core_v1.ObjectReference{
+					Name:      "nfsexport-ref",
+					Namespace: "default-ns",
+				},
+			},
+		}
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{utils.ContentHandleIndex: utils.ContentHandleIndexFunc})
+	if err := indexer.Add(newContent("content1", "driver1", "handle1")); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name        string
+		content     *volumenfsexportv1.VolumeNfsExportContent
+		operation   v1.Operation
+		markOnly    bool
+		shouldAdmit bool
+		msg         string
+	}{
+		{
+			name:        "Create: handle used by a content of the same driver",
+			content:     newContent("content2", "driver1", "handle1"),
+			operation:   v1.Create,
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"content2\" is invalid: spec.source.nfsexportHandle: Invalid value: \"handle1\": already used by VolumeNfsExportContent content1 of driver driver1; bind a VolumeNfsExport to the existing VolumeNfsExportContent instead, see %s", nfsexportDocsURL),
+		},
+		{
+			name:        "Create: handle used by a content of the same driver in mark-only mode",
+			content:     newContent("content2", "driver1", "handle1"),
+			operation:   v1.Create,
+			markOnly:    true,
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"content2\" is invalid: spec.source.nfsexportHandle: Invalid value: \"handle1\": already used by VolumeNfsExportContent content1 of driver driver1; bind a VolumeNfsExport to the existing VolumeNfsExportContent instead, see %s", nfsexportDocsURL),
+		},
+		{
+			name:        "Create: handle used by a content of another driver",
+			content:     newContent("content2", "driver2", "handle1"),
+			operation:   v1.Create,
+			shouldAdmit: true,
+		},
+		{
+			name:        "Create: content already in the cache",
+			content:     newContent("content1", "driver1", "handle1"),
+			operation:   v1.Create,
+			shouldAdmit: true,
+		},
+		{
+			name:        "Update: existing duplicates are not checked",
+			content:     newContent("content2", "driver1", "handle1"),
+			operation:   v1.Update,
+			shouldAdmit: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(tc.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldRaw := []byte("null")
+			if tc.operation == v1.Update {
+				oldRaw = raw
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+					Resource:  NfsExportContentV1GVR,
+					Operation: tc.operation,
+				},
+			}
+			sa := &admitter{contentIndexer: indexer, markOnly: tc.markOnly}
+			response := sa.Admit(review)
+			if response.Allowed != tc.shouldAdmit {
+				t.Errorf("expected allowed %v, got %v: %s", tc.shouldAdmit, response.Allowed, response.Result.Message)
+			}
+			if !tc.shouldAdmit && response.Result.Message != tc.msg {
+				t.Errorf("expected message %q, got %q", tc.msg, response.Result.Message)
+			}
+		})
+	}
+}
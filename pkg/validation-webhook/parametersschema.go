@@ -0,0 +1,227 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/params"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ParametersSchemaDriverLabel is the label of the ConfigMaps in which
+	// CSI drivers publish the schema of their VolumeNfsExportClass
+	// parameters. Its value is the name of the driver.
+	ParametersSchemaDriverLabel = "nfsexport.storage.kubernetes.io/parameters-schema-driver"
+
+	// ParametersSchemaKey is the key of the JSON schema in the data of the
+	// ConfigMap of a driver.
+	ParametersSchemaKey = "schema.json"
+)
+
+// parametersSchema is the subset of JSON schema supported for the parameters
+// of a VolumeNfsExportClass, e.g.
+//
+//	{
+//	  "properties": {
+//	    "tier": {"type": "string", "enum": ["hot", "cold"]},
+//	    "replicas": {"type": "integer"}
+//	  },
+//	  "required": ["tier"],
+//	  "additionalProperties": false
+//	}
+//
+// Other keywords are ignored. As parameters are strings, the type of a
+// property is the type its value must parse as. The csi.storage.k8s.io/
+// parameters are handled by the nfsexporter rather than the driver and are
+// not validated against the schema.
+type parametersSchema struct {
+	Properties           map[string]*parameterSchema `json:"properties"`
+	Required             []string                    `json:"required"`
+	AdditionalProperties *bool                       `json:"additionalProperties"`
+}
+
+type parameterSchema struct {
+	Type    string   `json:"type"`
+	Enum    []string `json:"enum"`
+	Pattern string   `json:"pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// parseParametersSchema parses the JSON schema of the parameters of a driver.
+func parseParametersSchema(data string) (*parametersSchema, error) {
+	schema := &parametersSchema{}
+	if err := json.Unmarshal([]byte(data), schema); err != nil {
+		return nil, err
+	}
+	for name, property := range schema.Properties {
+		if property == nil {
+			return nil, fmt.Errorf("property %q: schema must be an object", name)
+		}
+		switch property.Type {
+		case "", "string", "integer", "number", "boolean":
+		default:
+			return nil, fmt.Errorf("property %q: unsupported type %q", name, property.Type)
+		}
+		if property.Pattern != "" {
+			pattern, err := regexp.Compile(property.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("property %q: invalid pattern: %v", name, err)
+			}
+			property.pattern = pattern
+		}
+	}
+	return schema, nil
+}
+
+// getParametersSchema returns the schema of the parameters published by
+// driver in the ConfigMaps of lister, and the name of its ConfigMap. It
+// returns nil if the driver does not publish a schema.
+func getParametersSchema(lister corelisters.ConfigMapNamespaceLister, driver string) (*parametersSchema, string, error) {
+	selector := labels.SelectorFromSet(labels.Set{ParametersSchemaDriverLabel: driver})
+	configMaps, err := lister.List(selector)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(configMaps) == 0 {
+		return nil, "", nil
+	}
+	if len(configMaps) > 1 {
+		var names []string
+		for _, configMap := range configMaps {
+			names = append(names, configMap.Name)
+		}
+		sort.Strings(names)
+		return nil, "", fmt.Errorf("ConfigMaps %s all publish a parameters schema", strings.Join(names, ", "))
+	}
+	configMap := configMaps[0]
+	data, ok := configMap.Data[ParametersSchemaKey]
+	if !ok {
+		return nil, "", fmt.Errorf("ConfigMap %s has no %s", configMap.Name, ParametersSchemaKey)
+	}
+	schema, err := parseParametersSchema(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid %s of ConfigMap %s: %v", ParametersSchemaKey, configMap.Name, err)
+	}
+	return schema, configMap.Name, nil
+}
+
+// validateV1NfsExportClassParametersSchema validates the parameters of a
+// class against the schema published by its driver. A schema that cannot be
+// read is logged and the parameters are not validated, so that a broken
+// ConfigMap does not block the classes of the driver.
+func validateV1NfsExportClassParametersSchema(class *crdv1.VolumeNfsExportClass, lister corelisters.ConfigMapNamespaceLister) field.ErrorList {
+	schema, configMapName, err := getParametersSchema(lister, class.Driver)
+	if err != nil {
+		klog.Errorf("failed to get the parameters schema of driver %s, not validating the parameters of VolumeNfsExportClass %s: %v", class.Driver, class.Name, err)
+		return nil
+	}
+	if schema == nil {
+		return nil
+	}
+	// The parameters are validated as passed to the driver for each export
+	// of the class, without the KMS key which is only known at creation.
+	// Unknown csi.storage.k8s.io/ parameters and invalid warm-up parameters
+	// are rejected by validateV1NfsExportClass.
+	exportParameters, err := params.ClassExportParameters(class, nil)
+	if err != nil {
+		return nil
+	}
+	parameters, err := params.Layers{Class: class.Parameters, Export: exportParameters}.Effective()
+	if err != nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	paramsPath := field.NewPath("parameters")
+	schemaRef := fmt.Sprintf("the parameters schema of driver %s in ConfigMap %s", class.Driver, configMapName)
+
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := parameters[key]
+		property, ok := schema.Properties[key]
+		if !ok {
+			// Reserved parameters are set by the nfsexporter, they are only
+			// validated if the schema describes them.
+			if schema.AdditionalProperties != nil && !*schema.AdditionalProperties && !utils.IsPrefixedParameter(key) {
+				errs = append(errs, field.Invalid(paramsPath.Key(key), value,
+					withHint(fmt.Sprintf("not a parameter of driver %s", class.Driver), "check the spelling of the key against "+schemaRef, nfsexportClassDocsURL)))
+			}
+			continue
+		}
+		if detail := property.validate(value); detail != "" {
+			errs = append(errs, field.Invalid(paramsPath.Key(key), value,
+				withHint(detail, "set a value allowed by "+schemaRef, nfsexportClassDocsURL)))
+		}
+	}
+	for _, key := range schema.Required {
+		if _, ok := parameters[key]; !ok {
+			errs = append(errs, field.Required(paramsPath.Key(key),
+				withHint(fmt.Sprintf("required by driver %s", class.Driver), "set the parameter as described in "+schemaRef, nfsexportClassDocsURL)))
+		}
+	}
+	return errs
+}
+
+// validate returns why value does not match the schema of a parameter, or
+// an empty string if it does.
+func (p *parameterSchema) validate(value string) string {
+	var err error
+	switch p.Type {
+	case "integer":
+		_, err = strconv.ParseInt(value, 10, 64)
+	case "number":
+		_, err = strconv.ParseFloat(value, 64)
+	case "boolean":
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return fmt.Sprintf("must be of type %s", p.Type)
+	}
+	if len(p.Enum) > 0 {
+		found := false
+		for _, allowed := range p.Enum {
+			if value == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("must be one of %s", strings.Join(p.Enum, ", "))
+		}
+	}
+	if p.pattern != nil && !p.pattern.MatchString(value) {
+		return fmt.Sprintf("must match %s", p.Pattern)
+	}
+	return ""
+}
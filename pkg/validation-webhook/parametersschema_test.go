@@ -0,0 +1,202 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	core_v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestAdmitNfsExportClassParametersSchema(t *testing.T) {
+	schemaNamespace := "kube-system"
+	newSchema := func(name, driver, schema string) *core_v1.ConfigMap {
+		return &core_v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: schemaNamespace,
+				Labels:    map[string]string{ParametersSchemaDriverLabel: driver},
+			},
+			Data: map[string]string{ParametersSchemaKey: schema},
+		}
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, configMap := range []*core_v1.ConfigMap{
+		newSchema("driver1-schema", "driver1", `{
+			"properties": {
+				"tier": {"type": "string", "enum": ["hot", "cold"]},
+				"replicas": {"type": "integer"},
+				"pool": {"pattern": "^pool-[0-9]+$"}
+			},
+			"required": ["tier"],
+			"additionalProperties": false
+		}`),
+		newSchema("driver2-schema", "driver2", `{"properties": {"replicas": {"type": "integer"}}}`),
+		newSchema("driver3-schema", "driver3", `{"properties": {"replicas": {"type": "array"}}, "required": ["tier"]}`),
+		newSchema("driver5-schema", "driver5", `{"required": ["`+utils.PrefixedExportWarmUpKey+`"], "additionalProperties": false}`),
+	} {
+		if err := indexer.Add(configMap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	schemaLister := corelisters.NewConfigMapLister(indexer).ConfigMaps(schemaNamespace)
+	schemaRef := func(driver string) string {
+		return fmt.Sprintf("the parameters schema of driver %s in ConfigMap %s-schema", driver, driver)
+	}
+
+	testCases := []struct {
+		name          string
+		driver        string
+		parameters    map[string]string
+		oldDriver     string
+		oldParameters map[string]string
+		shouldAdmit   bool
+		msg           string
+	}{
+		{
+			name:        "parameters matching the schema",
+			driver:      "driver1",
+			parameters:  map[string]string{"tier": "hot", "replicas": "3", "pool": "pool-1", utils.PrefixedNfsExportterSecretNameKey: "secret", utils.PrefixedNfsExportterSecretNamespaceKey: "default"},
+			shouldAdmit: true,
+		},
+		{
+			name:        "reserved parameters are not checked against additional properties",
+			driver:      "driver1",
+			parameters:  map[string]string{"tier": "hot", utils.PrefixedExportWarmUpKey: "true"},
+			shouldAdmit: true,
+		},
+		{
+			name:        "reserved parameter required by the schema",
+			driver:      "driver5",
+			parameters:  map[string]string{utils.PrefixedExportWarmUpKey: "true"},
+			shouldAdmit: true,
+		},
+		{
+			name:        "reserved parameter required by the schema but not passed to the driver",
+			driver:      "driver5",
+			parameters:  map[string]string{utils.PrefixedExportWarmUpKey: "false"},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"class1\" is invalid: parameters[%s]: Required value: required by driver driver5; set the parameter as described in %s, see %s", utils.PrefixedExportWarmUpKey, schemaRef("driver5"), nfsexportClassDocsURL),
+		},
+		{
+			name:        "misspelled parameter",
+			driver:      "driver1",
+			parameters:  map[string]string{"tier": "hot", "replica": "3"},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"class1\" is invalid: parameters[replica]: Invalid value: \"3\": not a parameter of driver driver1; check the spelling of the key against %s, see %s", schemaRef("driver1"), nfsexportClassDocsURL),
+		},
+		{
+			name:        "value not in the enum",
+			driver:      "driver1",
+			parameters:  map[string]string{"tier": "warm"},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"class1\" is invalid: parameters[tier]: Invalid value: \"warm\": must be one of hot, cold; set a value allowed by %s, see %s", schemaRef("driver1"), nfsexportClassDocsURL),
+		},
+		{
+			name:        "value of the wrong type",
+			driver:      "driver1",
+			parameters:  map[string]string{"tier": "hot", "replicas": "three"},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"class1\" is invalid: parameters[replicas]: Invalid value: \"three\": must be of type integer; set a value allowed by %s, see %s", schemaRef("driver1"), nfsexportClassDocsURL),
+		},
+		{
+			name:        "value not matching the pattern",
+			driver:      "driver1",
+			parameters:  map[string]string{"tier": "hot", "pool": "default"},
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"class1\" is invalid: parameters[pool]: Invalid value: \"default\": must match ^pool-[0-9]+$; set a value allowed by %s, see %s", schemaRef("driver1"), nfsexportClassDocsURL),
+		},
+		{
+			name:        "missing required parameter",
+			driver:      "driver1",
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportClass.nfsexport.storage.k8s.io \"class1\" is invalid: parameters[tier]: Required value: required by driver driver1; set the parameter as described in %s, see %s", schemaRef("driver1"), nfsexportClassDocsURL),
+		},
+		{
+			name:          "unchanged parameters are not validated",
+			driver:        "driver1",
+			parameters:    map[string]string{"replica": "3"},
+			oldDriver:     "driver1",
+			oldParameters: map[string]string{"replica": "3"},
+			shouldAdmit:   true,
+		},
+		{
+			name:        "additional parameters allowed by the schema",
+			driver:      "driver2",
+			parameters:  map[string]string{"tier": "warm"},
+			shouldAdmit: true,
+		},
+		{
+			name:        "invalid schema is ignored",
+			driver:      "driver3",
+			parameters:  map[string]string{"replicas": "three"},
+			shouldAdmit: true,
+		},
+		{
+			name:        "driver without a schema",
+			driver:      "driver4",
+			parameters:  map[string]string{"anything": "goes"},
+			shouldAdmit: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExportClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "class1"},
+				Driver:     tc.driver,
+				Parameters: tc.parameters,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldRaw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExportClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "class1"},
+				Driver:     tc.oldDriver,
+				Parameters: tc.oldParameters,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+					Resource:  NfsExportClassV1GVR,
+					Operation: v1.Create,
+				},
+			}
+			sa := &admitter{lister: &fakeNfsExportLister{}, schemaLister: schemaLister}
+			response := sa.Admit(review)
+			if response.Allowed != tc.shouldAdmit {
+				t.Errorf("expected allowed %v, got %v: %s", tc.shouldAdmit, response.Allowed, response.Result.Message)
+			}
+			if !tc.shouldAdmit && response.Result.Message != tc.msg {
+				t.Errorf("expected message %q, got %q", tc.msg, response.Result.Message)
+			}
+		})
+	}
+}
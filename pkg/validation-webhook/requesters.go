@@ -0,0 +1,115 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+)
+
+// requesterRecorder records the user who creates a VolumeNfsExport or
+// VolumeNfsExportContent, or changes its spec, in the utils.AnnRequestedBy
+// annotation of the object, with a patch added to the responses admitting the
+// request. The webhook must be registered with a MutatingWebhookConfiguration
+// for the patch to be applied. The changes of the managers of the reserved
+// metadata, i.e. the nfsexport controller and the csi-nfsexporter sidecars,
+// are not recorded, so that a content created by the controller keeps the
+// requester it copied from its VolumeNfsExport.
+type requesterRecorder struct {
+	NfsExportAdmitter
+	// managers is nil if the changes of all users are recorded.
+	managers *reservedMetadataGuard
+}
+
+// recordRequesters returns an admitter recording the requesters of the
+// objects admitted by admit, or admit unchanged if enabled is false.
+func recordRequesters(admit NfsExportAdmitter, enabled bool, managers *reservedMetadataGuard) NfsExportAdmitter {
+	if !enabled {
+		return admit
+	}
+	return &requesterRecorder{NfsExportAdmitter: admit, managers: managers}
+}
+
+// requesterObject is the part of a VolumeNfsExport or VolumeNfsExportContent
+// looked at by the requesterRecorder.
+type requesterObject struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              interface{} `json:"spec,omitempty"`
+}
+
+func (r *requesterRecorder) Admit(ar v1.AdmissionReview) *v1.AdmissionResponse {
+	response := r.NfsExportAdmitter.Admit(ar)
+	request := ar.Request
+	if !response.Allowed || !(request.Operation == v1.Create || request.Operation == v1.Update) {
+		return response
+	}
+	if request.Resource != NfsExportV1GVR && request.Resource != NfsExportContentV1GVR {
+		return response
+	}
+	user := request.UserInfo.Username
+	if user == "" || r.managers.isManager(request) {
+		return response
+	}
+
+	obj := &requesterObject{}
+	if err := json.Unmarshal(request.Object.Raw, obj); err != nil {
+		klog.Errorf("failed to decode %s %s to record its requester: %v", request.Resource.Resource, request.Name, err)
+		return response
+	}
+	if obj.Annotations[utils.AnnRequestedBy] == user {
+		return response
+	}
+	if request.Operation == v1.Update {
+		oldObj := &requesterObject{}
+		if err := json.Unmarshal(request.OldObject.Raw, oldObj); err != nil {
+			klog.Errorf("failed to decode the old %s %s to record its requester: %v", request.Resource.Resource, request.Name, err)
+			return response
+		}
+		if reflect.DeepEqual(obj.Spec, oldObj.Spec) {
+			return response
+		}
+	}
+
+	var patch []map[string]interface{}
+	if len(response.Patch) > 0 {
+		if err := json.Unmarshal(response.Patch, &patch); err != nil {
+			klog.Errorf("failed to decode the patch of %s %s to record its requester: %v", request.Resource.Resource, request.Name, err)
+			return response
+		}
+	}
+	if obj.Annotations == nil {
+		patch = append(patch, map[string]interface{}{"op": "add", "path": "/metadata/annotations", "value": map[string]string{utils.AnnRequestedBy: user}})
+	} else {
+		annotationPath := "/metadata/annotations/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(utils.AnnRequestedBy)
+		patch = append(patch, map[string]interface{}{"op": "add", "path": annotationPath, "value": user})
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		klog.Errorf("failed to marshal the patch of %s %s: %v", request.Resource.Resource, request.Name, err)
+		return response
+	}
+	patchType := v1.PatchTypeJSONPatch
+	response.Patch = patchBytes
+	response.PatchType = &patchType
+	return response
+}
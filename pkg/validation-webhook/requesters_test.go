@@ -0,0 +1,176 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestRecordRequesters(t *testing.T) {
+	className := "class1"
+	otherClassName := "class2"
+	pvcName := "pvc1"
+	spec := volumenfsexportv1.VolumeNfsExportSpec{
+		Source:                   volumenfsexportv1.VolumeNfsExportSource{PersistentVolumeClaimName: &pvcName},
+		VolumeNfsExportClassName: &className,
+	}
+	otherSpec := spec
+	otherSpec.VolumeNfsExportClassName = &otherClassName
+	controller := authenticationv1.UserInfo{Username: "system:serviceaccount:kube-system:nfsexport-controller"}
+	developer := authenticationv1.UserInfo{Username: "developer"}
+
+	testCases := []struct {
+		name           string
+		user           authenticationv1.UserInfo
+		oldSpec        volumenfsexportv1.VolumeNfsExportSpec
+		oldAnnotations map[string]string
+		annotations    map[string]string
+		labels         map[string]string
+		operation      v1.Operation
+		expectPatch    string
+	}{
+		{
+			name:        "user creates an nfsexport without annotations",
+			user:        developer,
+			operation:   v1.Create,
+			expectPatch: `[{"op":"add","path":"/metadata/annotations","value":{"nfsexport.storage.kubernetes.io/requested-by":"developer"}}]`,
+		},
+		{
+			name:        "user creates an nfsexport with annotations",
+			user:        developer,
+			annotations: map[string]string{"app": "db"},
+			operation:   v1.Create,
+			expectPatch: `[{"op":"add","path":"/metadata/annotations/nfsexport.storage.kubernetes.io~1requested-by","value":"developer"}]`,
+		},
+		{
+			name:           "user changes the spec of an nfsexport requested by another user",
+			user:           developer,
+			oldSpec:        otherSpec,
+			oldAnnotations: map[string]string{utils.AnnRequestedBy: "admin"},
+			annotations:    map[string]string{utils.AnnRequestedBy: "admin"},
+			operation:      v1.Update,
+			expectPatch:    `[{"op":"add","path":"/metadata/annotations/nfsexport.storage.kubernetes.io~1requested-by","value":"developer"}]`,
+		},
+		{
+			name:           "user changes only the labels of an nfsexport",
+			user:           developer,
+			oldSpec:        spec,
+			oldAnnotations: map[string]string{utils.AnnRequestedBy: "admin"},
+			annotations:    map[string]string{utils.AnnRequestedBy: "admin"},
+			labels:         map[string]string{"app": "db"},
+			operation:      v1.Update,
+		},
+		{
+			name:        "user creates an nfsexport already recording them",
+			user:        developer,
+			annotations: map[string]string{utils.AnnRequestedBy: developer.Username},
+			operation:   v1.Create,
+		},
+		{
+			name:      "controller creates an nfsexport",
+			user:      controller,
+			operation: v1.Create,
+		},
+		{
+			name:      "user deletes an nfsexport",
+			user:      developer,
+			operation: v1.Delete,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExport{
+				ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default", Labels: tc.labels, Annotations: tc.annotations},
+				Spec:       spec,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var oldRaw []byte
+			if tc.operation == v1.Update {
+				oldRaw, err = json.Marshal(&volumenfsexportv1.VolumeNfsExport{
+					ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default", Annotations: tc.oldAnnotations},
+					Spec:       tc.oldSpec,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Name:      "snap1",
+					Namespace: "default",
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+					Resource:  NfsExportV1GVR,
+					Operation: tc.operation,
+					UserInfo:  tc.user,
+				},
+			}
+			sa := recordRequesters(&admitter{}, true, newReservedMetadataGuard([]string{controller.Username}))
+			response := sa.Admit(review)
+			if !response.Allowed {
+				t.Fatalf("expected the request to be allowed: %s", response.Result.Message)
+			}
+			if string(response.Patch) != tc.expectPatch {
+				t.Errorf("expected patch %s, got %s", tc.expectPatch, response.Patch)
+			}
+			if tc.expectPatch != "" && (response.PatchType == nil || *response.PatchType != v1.PatchTypeJSONPatch) {
+				t.Errorf("expected a JSON patch, got %v", response.PatchType)
+			}
+		})
+	}
+}
+
+func TestRecordRequestersMarkOnly(t *testing.T) {
+	// Invalid, as spec.sources is set without spec.source.persistentVolumeClaimName.
+	raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default"},
+		Spec:       volumenfsexportv1.VolumeNfsExportSpec{Sources: []string{"pvc2"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	review := v1.AdmissionReview{
+		Request: &v1.AdmissionRequest{
+			Name:      "snap1",
+			Namespace: "default",
+			Object:    runtime.RawExtension{Raw: raw},
+			Resource:  NfsExportV1GVR,
+			Operation: v1.Create,
+			UserInfo:  authenticationv1.UserInfo{Username: "developer"},
+		},
+	}
+	sa := recordRequesters(&admitter{markOnly: true}, true, nil)
+	response := sa.Admit(review)
+	if !response.Allowed {
+		t.Fatalf("expected the request to be allowed: %s", response.Result.Message)
+	}
+	expectPatch := `[{"op":"add","path":"/metadata/labels","value":{"nfsexport.storage.kubernetes.io/invalid-nfsexport-resource":""}},{"op":"add","path":"/metadata/annotations","value":{"nfsexport.storage.kubernetes.io/requested-by":"developer"}}]`
+	if string(response.Patch) != expectPatch {
+		t.Errorf("expected patch %s, got %s", expectPatch, response.Patch)
+	}
+}
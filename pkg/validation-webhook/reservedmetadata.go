@@ -0,0 +1,82 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// reservedMetadataGuard denies changes to the reserved labels and
+// annotations of VolumeNfsExports and VolumeNfsExportContents, see
+// utils.IsReservedMetadataKey, by users other than its managers, i.e. the
+// nfsexport controller and the csi-nfsexporter sidecars.
+type reservedMetadataGuard struct {
+	// managers are the names of the users and groups allowed to change the
+	// reserved keys.
+	managers sets.String
+}
+
+// newReservedMetadataGuard returns a guard allowing only the given users and
+// groups to change the reserved keys, or nil if managers is empty, which
+// disables the guard.
+func newReservedMetadataGuard(managers []string) *reservedMetadataGuard {
+	if len(managers) == 0 {
+		return nil
+	}
+	return &reservedMetadataGuard{managers: sets.NewString(managers...)}
+}
+
+// isManager returns true if the user of request is a manager. It returns
+// false if g is nil.
+func (g *reservedMetadataGuard) isManager(request *v1.AdmissionRequest) bool {
+	return g != nil && (g.managers.Has(request.UserInfo.Username) || g.managers.HasAny(request.UserInfo.Groups...))
+}
+
+// check returns an error for each reserved label and annotation changed
+// between oldObj and obj, unless the user of request is a manager. Users may
+// set the requested-by annotation to their own name, as done by the
+// requesterRecorder. It returns nil if g is nil.
+func (g *reservedMetadataGuard) check(request *v1.AdmissionRequest, obj, oldObj metav1.Object) field.ErrorList {
+	if g == nil || g.isManager(request) {
+		return nil
+	}
+	var errs field.ErrorList
+	for _, metadata := range []struct {
+		path                 *field.Path
+		newValues, oldValues map[string]string
+		// requesterKey is the key users may set to their own name.
+		requesterKey string
+	}{
+		{field.NewPath("metadata", "labels"), obj.GetLabels(), oldObj.GetLabels(), ""},
+		{field.NewPath("metadata", "annotations"), obj.GetAnnotations(), oldObj.GetAnnotations(), utils.AnnRequestedBy},
+	} {
+		for _, key := range utils.GetChangedReservedMetadataKeys(metadata.oldValues, metadata.newValues) {
+			if key == metadata.requesterKey && metadata.newValues[key] == request.UserInfo.Username {
+				continue
+			}
+			detail := fmt.Sprintf("keys with the %s prefix are managed by the nfsexport controller and the csi-nfsexporter sidecar, user %q may not change them", utils.ReservedMetadataPrefix, request.UserInfo.Username)
+			errs = append(errs, field.Forbidden(metadata.path.Key(key), withHint(detail, "revert the change to "+key, nfsexportDocsURL)))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	core_v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestAdmitReservedMetadata(t *testing.T) {
+	nfsexportHandle := "handle1"
+	spec := volumenfsexportv1.VolumeNfsExportContentSpec{
+		Driver:             "driver1",
+		DeletionPolicy:     volumenfsexportv1.VolumeNfsExportContentRetain,
+		Source:             volumenfsexportv1.VolumeNfsExportContentSource{NfsExportHandle: &nfsexportHandle},
+		VolumeNfsExportRef: core_v1.ObjectReference{Name: "snap1", Namespace: "default"},
+	}
+	controller := authenticationv1.UserInfo{Username: "system:serviceaccount:kube-system:nfsexport-controller"}
+	sidecar := authenticationv1.UserInfo{Username: "system:serviceaccount:default:csi-nfsexporter", Groups: []string{"system:serviceaccounts:default"}}
+	developer := authenticationv1.UserInfo{Username: "developer"}
+	managers := []string{controller.Username, "system:serviceaccounts:default"}
+
+	testCases := []struct {
+		name           string
+		managers       []string
+		user           authenticationv1.UserInfo
+		oldLabels      map[string]string
+		labels         map[string]string
+		oldAnnotations map[string]string
+		annotations    map[string]string
+		operation      v1.Operation
+		shouldAdmit    bool
+		msg            string
+	}{
+		{
+			name:           "user removes the being-created annotation",
+			managers:       managers,
+			user:           developer,
+			oldAnnotations: map[string]string{utils.AnnVolumeNfsExportBeingCreated: "yes"},
+			operation:      v1.Update,
+			shouldAdmit:    false,
+			msg:            fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"content1\" is invalid: metadata.annotations[nfsexport.storage.kubernetes.io/volumenfsexport-being-created]: Forbidden: keys with the nfsexport.storage.kubernetes.io/ prefix are managed by the nfsexport controller and the csi-nfsexporter sidecar, user \"developer\" may not change them; revert the change to nfsexport.storage.kubernetes.io/volumenfsexport-being-created, see %s", nfsexportDocsURL),
+		},
+		{
+			name:        "user changes the managed-by label",
+			managers:    managers,
+			user:        developer,
+			oldLabels:   map[string]string{utils.VolumeNfsExportContentManagedByLabel: "node1"},
+			labels:      map[string]string{utils.VolumeNfsExportContentManagedByLabel: "node2"},
+			operation:   v1.Update,
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"content1\" is invalid: metadata.labels[nfsexport.storage.kubernetes.io/managed-by]: Forbidden: keys with the nfsexport.storage.kubernetes.io/ prefix are managed by the nfsexport controller and the csi-nfsexporter sidecar, user \"developer\" may not change them; revert the change to nfsexport.storage.kubernetes.io/managed-by, see %s", nfsexportDocsURL),
+		},
+		{
+			name:        "controller changes the managed-by label",
+			managers:    managers,
+			user:        controller,
+			oldLabels:   map[string]string{utils.VolumeNfsExportContentManagedByLabel: "node1"},
+			labels:      map[string]string{utils.VolumeNfsExportContentManagedByLabel: "node2"},
+			operation:   v1.Update,
+			shouldAdmit: true,
+		},
+		{
+			name:        "sidecar in a manager group sets the being-created annotation",
+			managers:    managers,
+			user:        sidecar,
+			annotations: map[string]string{utils.AnnVolumeNfsExportBeingCreated: "yes"},
+			operation:   v1.Update,
+			shouldAdmit: true,
+		},
+		{
+			name:        "user sets a user settable annotation and other labels",
+			managers:    managers,
+			user:        developer,
+			labels:      map[string]string{"app": "db"},
+			annotations: map[string]string{utils.AnnVolumeNfsExportRebindTo: "default/snap2"},
+			operation:   v1.Update,
+			shouldAdmit: true,
+		},
+		{
+			name:           "user sets the backup-include annotation",
+			managers:       managers,
+			user:           developer,
+			oldAnnotations: map[string]string{utils.AnnBackupInclude: "false"},
+			annotations:    map[string]string{utils.AnnBackupInclude: "true"},
+			operation:      v1.Update,
+			shouldAdmit:    true,
+		},
+		{
+			name:           "user sets the requested-by annotation to their own name",
+			managers:       managers,
+			user:           developer,
+			oldAnnotations: map[string]string{utils.AnnRequestedBy: "admin"},
+			annotations:    map[string]string{utils.AnnRequestedBy: developer.Username},
+			operation:      v1.Update,
+			shouldAdmit:    true,
+		},
+		{
+			name:        "user sets the requested-by annotation to another name",
+			managers:    managers,
+			user:        developer,
+			annotations: map[string]string{utils.AnnRequestedBy: "admin"},
+			operation:   v1.Update,
+			shouldAdmit: false,
+			msg:         fmt.Sprintf("VolumeNfsExportContent.nfsexport.storage.k8s.io \"content1\" is invalid: metadata.annotations[nfsexport.storage.kubernetes.io/requested-by]: Forbidden: keys with the nfsexport.storage.kubernetes.io/ prefix are managed by the nfsexport controller and the csi-nfsexporter sidecar, user \"developer\" may not change them; revert the change to nfsexport.storage.kubernetes.io/requested-by, see %s", nfsexportDocsURL),
+		},
+		{
+			name:        "user creates a content with reserved keys",
+			managers:    managers,
+			user:        developer,
+			labels:      map[string]string{utils.VolumeNfsExportContentManagedByLabel: "node1"},
+			operation:   v1.Create,
+			shouldAdmit: true,
+		},
+		{
+			name:        "reserved keys are not protected without managers",
+			user:        developer,
+			oldLabels:   map[string]string{utils.VolumeNfsExportContentManagedByLabel: "node1"},
+			operation:   v1.Update,
+			shouldAdmit: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExportContent{
+				ObjectMeta: metav1.ObjectMeta{Name: "content1", Labels: tc.labels, Annotations: tc.annotations},
+				Spec:       spec,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldRaw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExportContent{
+				ObjectMeta: metav1.ObjectMeta{Name: "content1", Labels: tc.oldLabels, Annotations: tc.oldAnnotations},
+				Spec:       spec,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Name:      "content1",
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+					Resource:  NfsExportContentV1GVR,
+					Operation: tc.operation,
+					UserInfo:  tc.user,
+				},
+			}
+			sa := &admitter{reservedMetadata: newReservedMetadataGuard(tc.managers)}
+			response := sa.Admit(review)
+			if response.Allowed != tc.shouldAdmit {
+				t.Errorf("expected allowed %v, got %v: %s", tc.shouldAdmit, response.Allowed, response.Result.Message)
+			}
+			if !tc.shouldAdmit && response.Result.Message != tc.msg {
+				t.Errorf("expected message %q, got %q", tc.msg, response.Result.Message)
+			}
+		})
+	}
+}
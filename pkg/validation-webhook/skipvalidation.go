@@ -0,0 +1,136 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	core_v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+)
+
+// skipValidationVerb is the verb a user must be allowed on a resource to
+// skip the validation of its objects with utils.AnnSkipValidation.
+const skipValidationVerb = "skip-validation"
+
+// skipValidationWarning is returned to clients whose object was admitted
+// without validation.
+const skipValidationWarning = "validation skipped with the " + utils.AnnSkipValidation + " annotation, remove it once the repair is done"
+
+// validationSkipper admits the VolumeNfsExports and VolumeNfsExportContents
+// annotated with utils.AnnSkipValidation without validating them, if the
+// requesting user is allowed the skip-validation verb on their resource.
+// Each skipped validation is recorded as an event on the object.
+type validationSkipper struct {
+	accessReviews authorizationv1client.SubjectAccessReviewInterface
+	eventRecorder record.EventRecorder
+}
+
+func newValidationSkipper(client kubernetes.Interface) *validationSkipper {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartLogging(klog.Infof)
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events(core_v1.NamespaceAll)})
+	return &validationSkipper{
+		accessReviews: client.AuthorizationV1().SubjectAccessReviews(),
+		eventRecorder: broadcaster.NewRecorder(scheme, core_v1.EventSource{Component: "nfsexport-validation-webhook"}),
+	}
+}
+
+// skippableObject is a VolumeNfsExport or a VolumeNfsExportContent.
+type skippableObject interface {
+	runtime.Object
+	metav1.Object
+}
+
+// admit returns the response to a request for obj if obj skips validation,
+// or nil if obj must be validated. oldObj is the object before an update,
+// and empty on create. The skip-validation verb is only checked when the
+// annotation is set: once set by an allowed user, the object skips
+// validation for all users until the annotation is removed. Skipping
+// validation is disabled if s is nil, and the annotation is then ignored.
+func (s *validationSkipper) admit(request *v1.AdmissionRequest, kind string, obj, oldObj skippableObject) *v1.AdmissionResponse {
+	if s == nil || obj.GetAnnotations()[utils.AnnSkipValidation] != "true" {
+		return nil
+	}
+	user := request.UserInfo.Username
+	if oldObj.GetAnnotations()[utils.AnnSkipValidation] != "true" {
+		allowed, err := s.allowed(request)
+		if err != nil {
+			klog.Errorf("failed to check whether user %q may skip the validation of %s %s: %v", user, kind, obj.GetName(), err)
+			return toV1AdmissionResponse(fmt.Errorf("failed to check whether user %q may skip validation: %v", user, err))
+		}
+		if !allowed {
+			detail := fmt.Sprintf("user %q is not allowed to %s %s", user, skipValidationVerb, request.Resource.Resource)
+			return rejectV1(kind, obj.GetName(), field.ErrorList{
+				field.Forbidden(field.NewPath("metadata", "annotations").Key(utils.AnnSkipValidation),
+					withHint(detail, "remove the annotation, or ask a cluster administrator for the "+skipValidationVerb+" verb", nfsexportDocsURL)),
+			})
+		}
+	}
+
+	klog.Infof("Admitting %s %s without validation for user %q", kind, obj.GetName(), user)
+	// A dry run request does not persist obj, so there is nothing to
+	// record.
+	if request.DryRun == nil || !*request.DryRun {
+		s.eventRecorder.Eventf(obj, core_v1.EventTypeWarning, "ValidationSkipped", "Validation of %s skipped by user %q with the %s annotation", request.Operation, user, utils.AnnSkipValidation)
+	}
+	return &v1.AdmissionResponse{
+		Allowed:  true,
+		Result:   &metav1.Status{},
+		Warnings: []string{skipValidationWarning},
+	}
+}
+
+// allowed checks with a SubjectAccessReview whether the user of request is
+// allowed the skip-validation verb on the requested object.
+func (s *validationSkipper) allowed(request *v1.AdmissionRequest) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(request.UserInfo.Extra))
+	for key, value := range request.UserInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   request.UserInfo.Username,
+			Groups: request.UserInfo.Groups,
+			UID:    request.UserInfo.UID,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: request.Namespace,
+				Verb:      skipValidationVerb,
+				Group:     request.Resource.Group,
+				Resource:  request.Resource.Resource,
+				Name:      request.Name,
+			},
+		},
+	}
+	result, err := s.accessReviews.Create(context.TODO(), review, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	return result.Status.Allowed, nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestAdmitSkipValidation(t *testing.T) {
+	emptyClassName := ""
+	pvcName := "pvc1"
+	// Invalid, as the class name is empty.
+	invalidSpec := volumenfsexportv1.VolumeNfsExportSpec{
+		Source:                   volumenfsexportv1.VolumeNfsExportSource{PersistentVolumeClaimName: &pvcName},
+		VolumeNfsExportClassName: &emptyClassName,
+	}
+
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		// oldAnnotations are the annotations of the object before an
+		// update. The object is created if it is nil.
+		oldAnnotations map[string]string
+		user           string
+		dryRun         bool
+		enabled        bool
+		expectAllowed  bool
+		expectReview   bool
+		expectEvent    bool
+	}{
+		{
+			name:          "authorized user skips validation",
+			annotations:   map[string]string{utils.AnnSkipValidation: "true"},
+			user:          "admin",
+			enabled:       true,
+			expectAllowed: true,
+			expectReview:  true,
+			expectEvent:   true,
+		},
+		{
+			name:          "unauthorized user is denied",
+			annotations:   map[string]string{utils.AnnSkipValidation: "true"},
+			user:          "developer",
+			enabled:       true,
+			expectAllowed: false,
+			expectReview:  true,
+		},
+		{
+			name:           "unauthorized user adding the annotation is denied",
+			annotations:    map[string]string{utils.AnnSkipValidation: "true"},
+			oldAnnotations: map[string]string{},
+			user:           "developer",
+			enabled:        true,
+			expectAllowed:  false,
+			expectReview:   true,
+		},
+		{
+			name:           "any user updates an object whose annotation is unchanged",
+			annotations:    map[string]string{utils.AnnSkipValidation: "true"},
+			oldAnnotations: map[string]string{utils.AnnSkipValidation: "true"},
+			user:           "developer",
+			enabled:        true,
+			expectAllowed:  true,
+			expectEvent:    true,
+		},
+		{
+			name:          "dry run skips validation without event",
+			annotations:   map[string]string{utils.AnnSkipValidation: "true"},
+			user:          "admin",
+			dryRun:        true,
+			enabled:       true,
+			expectAllowed: true,
+			expectReview:  true,
+		},
+		{
+			name:          "annotation not set to true is ignored",
+			annotations:   map[string]string{utils.AnnSkipValidation: "yes"},
+			user:          "admin",
+			enabled:       true,
+			expectAllowed: false,
+		},
+		{
+			name:          "annotation is ignored when skipping is disabled",
+			annotations:   map[string]string{utils.AnnSkipValidation: "true"},
+			user:          "admin",
+			enabled:       false,
+			expectAllowed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExport{
+				ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default", Annotations: tc.annotations},
+				Spec:       invalidSpec,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Name:      "snap1",
+					Namespace: "default",
+					Object:    runtime.RawExtension{Raw: raw},
+					Resource:  NfsExportV1GVR,
+					Operation: v1.Create,
+					UserInfo:  authenticationv1.UserInfo{Username: tc.user},
+					DryRun:    &tc.dryRun,
+				},
+			}
+			if tc.oldAnnotations != nil {
+				oldRaw, err := json.Marshal(&volumenfsexportv1.VolumeNfsExport{
+					ObjectMeta: metav1.ObjectMeta{Name: "snap1", Namespace: "default", Annotations: tc.oldAnnotations},
+					Spec:       invalidSpec,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				review.Request.OldObject = runtime.RawExtension{Raw: oldRaw}
+				review.Request.Operation = v1.Update
+			}
+
+			client := fake.NewSimpleClientset()
+			var reviewed *authorizationv1.SubjectAccessReview
+			client.PrependReactor("create", "subjectaccessreviews", func(action core.Action) (bool, runtime.Object, error) {
+				reviewed = action.(core.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				reviewed.Status.Allowed = reviewed.Spec.User == "admin"
+				return true, reviewed, nil
+			})
+			recorder := record.NewFakeRecorder(10)
+			sa := &admitter{}
+			if tc.enabled {
+				sa.skipper = &validationSkipper{
+					accessReviews: client.AuthorizationV1().SubjectAccessReviews(),
+					eventRecorder: recorder,
+				}
+			}
+
+			response := sa.Admit(review)
+			if response.Allowed != tc.expectAllowed {
+				t.Fatalf("expected allowed %v, got %+v", tc.expectAllowed, response.Result)
+			}
+			if tc.expectAllowed && (len(response.Warnings) != 1 || response.Warnings[0] != skipValidationWarning) {
+				t.Errorf("expected skip validation warning, got %v", response.Warnings)
+			}
+			if tc.enabled && (reviewed != nil) != tc.expectReview {
+				t.Errorf("expected access review %v, got %+v", tc.expectReview, reviewed)
+			}
+			if reviewed != nil {
+				attributes := reviewed.Spec.ResourceAttributes
+				if attributes.Verb != skipValidationVerb || attributes.Resource != "volumenfsexports" || attributes.Namespace != "default" || attributes.Name != "snap1" {
+					t.Errorf("unexpected resource attributes in access review: %+v", attributes)
+				}
+			}
+			select {
+			case event := <-recorder.Events:
+				if !tc.expectEvent {
+					t.Errorf("unexpected event %q", event)
+				} else if !strings.Contains(event, "ValidationSkipped") || !strings.Contains(event, `"`+tc.user+`"`) {
+					t.Errorf("unexpected event %q", event)
+				}
+			default:
+				if tc.expectEvent {
+					t.Errorf("expected a ValidationSkipped event")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"reflect"
+
+	v1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// storageMigrators admits the updates of VolumeNfsExports and
+// VolumeNfsExportContents by the storage version migrator without validating
+// them, if they change neither the spec nor the labels and annotations of
+// the object. The migrator writes every object back unchanged, including the
+// objects created before a validation rule was added and the objects whose
+// validation was skipped, which would otherwise be denied.
+type storageMigrators struct {
+	// users are the names of the users and groups of the migrator.
+	users sets.String
+}
+
+// newStorageMigrators returns the exemption of the given users and groups,
+// or nil if users is empty, which disables the exemption.
+func newStorageMigrators(users []string) *storageMigrators {
+	if len(users) == 0 {
+		return nil
+	}
+	return &storageMigrators{users: sets.NewString(users...)}
+}
+
+// admit returns the response to an update of obj by a migrator which
+// changes neither spec nor the labels and annotations of oldObj, or nil if
+// the request must be validated. It returns nil if m is nil.
+func (m *storageMigrators) admit(request *v1.AdmissionRequest, spec, oldSpec interface{}, obj, oldObj metav1.Object) *v1.AdmissionResponse {
+	if m == nil || request.Operation != v1.Update {
+		return nil
+	}
+	if !m.users.Has(request.UserInfo.Username) && !m.users.HasAny(request.UserInfo.Groups...) {
+		return nil
+	}
+	if !reflect.DeepEqual(spec, oldSpec) ||
+		!reflect.DeepEqual(obj.GetLabels(), oldObj.GetLabels()) ||
+		!reflect.DeepEqual(obj.GetAnnotations(), oldObj.GetAnnotations()) {
+		return nil
+	}
+	klog.V(4).Infof("Admitting the unchanged %s %s/%s of storage version migrator %q without validation", request.Resource.Resource, request.Namespace, obj.GetName(), request.UserInfo.Username)
+	return &v1.AdmissionResponse{
+		Allowed: true,
+		Result:  &metav1.Status{},
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+
+	volumenfsexportv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	v1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	core_v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestAdmitStorageMigrators(t *testing.T) {
+	const migrator = "system:serviceaccount:kube-system:storage-version-migrator"
+	nfsexportHandle := "nfsexportHandle1"
+	// Invalid, as the name of the VolumeNfsExport is empty.
+	invalidContent := &volumenfsexportv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "content1"},
+		Spec: volumenfsexportv1.VolumeNfsExportContentSpec{
+			Source:             volumenfsexportv1.VolumeNfsExportContentSource{NfsExportHandle: &nfsexportHandle},
+			VolumeNfsExportRef: core_v1.ObjectReference{Namespace: "default-ns"},
+		},
+	}
+	skipped := invalidContent.DeepCopy()
+	skipped.Annotations = map[string]string{utils.AnnSkipValidation: "true"}
+	relabeled := invalidContent.DeepCopy()
+	relabeled.Labels = map[string]string{"app": "db"}
+
+	testCases := []struct {
+		name          string
+		content       *volumenfsexportv1.VolumeNfsExportContent
+		oldContent    *volumenfsexportv1.VolumeNfsExportContent
+		user          string
+		groups        []string
+		migrators     []string
+		expectAllowed bool
+	}{
+		{
+			name:          "unchanged invalid content written back by the migrator",
+			content:       invalidContent,
+			oldContent:    invalidContent,
+			user:          migrator,
+			migrators:     []string{migrator},
+			expectAllowed: true,
+		},
+		{
+			name:          "unchanged invalid content written back by a group of the migrator",
+			content:       invalidContent,
+			oldContent:    invalidContent,
+			user:          migrator,
+			groups:        []string{"system:serviceaccounts:kube-system"},
+			migrators:     []string{"system:serviceaccounts:kube-system"},
+			expectAllowed: true,
+		},
+		{
+			name:          "unchanged content skipping validation written back by the migrator",
+			content:       skipped,
+			oldContent:    skipped,
+			user:          migrator,
+			migrators:     []string{migrator},
+			expectAllowed: true,
+		},
+		{
+			name:          "unchanged invalid content written back by another user",
+			content:       invalidContent,
+			oldContent:    invalidContent,
+			user:          "developer",
+			migrators:     []string{migrator},
+			expectAllowed: false,
+		},
+		{
+			name:          "labels of invalid content changed by the migrator",
+			content:       relabeled,
+			oldContent:    invalidContent,
+			user:          migrator,
+			migrators:     []string{migrator},
+			expectAllowed: false,
+		},
+		{
+			name:          "unchanged invalid content written back without exemption",
+			content:       invalidContent,
+			oldContent:    invalidContent,
+			user:          migrator,
+			expectAllowed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			raw, err := json.Marshal(tc.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldRaw, err := json.Marshal(tc.oldContent)
+			if err != nil {
+				t.Fatal(err)
+			}
+			review := v1.AdmissionReview{
+				Request: &v1.AdmissionRequest{
+					Name:      tc.content.Name,
+					Object:    runtime.RawExtension{Raw: raw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+					Resource:  NfsExportContentV1GVR,
+					Operation: v1.Update,
+					UserInfo:  authenticationv1.UserInfo{Username: tc.user, Groups: tc.groups},
+				},
+			}
+			// The access reviews of the fake client deny everything.
+			sa := &admitter{
+				skipper: &validationSkipper{
+					accessReviews: fake.NewSimpleClientset().AuthorizationV1().SubjectAccessReviews(),
+					eventRecorder: record.NewFakeRecorder(10),
+				},
+				storageMigrators: newStorageMigrators(tc.migrators),
+			}
+
+			response := sa.Admit(review)
+			if response.Allowed != tc.expectAllowed {
+				t.Errorf("expected allowed %v, got %+v", tc.expectAllowed, response.Result)
+			}
+		})
+	}
+}
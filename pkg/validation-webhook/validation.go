@@ -18,10 +18,38 @@ package webhook
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
 	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
+// Documentation linked from rejection messages, so that users know how to
+// fix the field that was rejected.
+const (
+	nfsexportDocsURL      = "https://kubernetes.io/docs/concepts/storage/volume-nfsexports/"
+	nfsexportClassDocsURL = "https://kubernetes.io/docs/concepts/storage/volume-nfsexport-classes/"
+	secretsDocsURL        = "https://kubernetes-csi.github.io/docs/secrets-and-credentials.html"
+)
+
+// withHint appends a remediation hint and a documentation link to the detail
+// of a field error.
+func withHint(detail, hint, docsURL string) string {
+	return fmt.Sprintf("%s; %s, see %s", detail, hint, docsURL)
+}
+
+// toInvalidError returns the error of an object of the given kind rejected
+// for errs, whose status lists each rejected field as a cause.
+func toInvalidError(kind, name string, errs field.ErrorList) *apierrors.StatusError {
+	return apierrors.NewInvalid(schema.GroupKind{Group: crdv1.GroupName, Kind: kind}, name, errs)
+}
+
 // ValidateV1NfsExport performs additional strict validation.
 // Do NOT rely on this function to fully validate nfsexport objects.
 // This function will only check the additional rules provided by the webhook.
@@ -29,12 +57,195 @@ func ValidateV1NfsExport(nfsexport *crdv1.VolumeNfsExport) error {
 	if nfsexport == nil {
 		return fmt.Errorf("VolumeNfsExport is nil")
 	}
+	return validateV1NfsExport(nfsexport).ToAggregate()
+}
 
+func validateV1NfsExport(nfsexport *crdv1.VolumeNfsExport) field.ErrorList {
+	var errs field.ErrorList
 	vscname := nfsexport.Spec.VolumeNfsExportClassName
 	if vscname != nil && *vscname == "" {
-		return fmt.Errorf("Spec.VolumeNfsExportClassName must not be the empty string")
+		errs = append(errs, field.Invalid(field.NewPath("spec", "volumeNfsExportClassName"), *vscname,
+			withHint("must not be the empty string", "omit the field to use the default VolumeNfsExportClass of the driver", nfsexportDocsURL)))
+	}
+	errs = append(errs, validateV1NfsExportSources(nfsexport)...)
+	errs = append(errs, validateV1NfsExportCacheTier(nfsexport)...)
+	return errs
+}
+
+// validateV1NfsExportCacheTier rejects the cache tier of a pre-provisioned
+// nfsexport: the driver is not called for its content, so the tier would
+// never be requested.
+func validateV1NfsExportCacheTier(nfsexport *crdv1.VolumeNfsExport) field.ErrorList {
+	if nfsexport.Spec.CacheTier == nil || nfsexport.Spec.Source.VolumeNfsExportContentName == nil {
+		return nil
+	}
+	return field.ErrorList{field.Forbidden(field.NewPath("spec", "cacheTier"),
+		withHint("may only be set with spec.source.persistentVolumeClaimName", "remove the field, the cache tier of a pre-provisioned export is set when it is created on the storage system", nfsexportDocsURL))}
+}
+
+// validateV1NfsExportSources validates the further source PVCs of a nfsexport
+// merging several volumes into one export.
+func validateV1NfsExportSources(nfsexport *crdv1.VolumeNfsExport) field.ErrorList {
+	if len(nfsexport.Spec.Sources) == 0 {
+		return nil
+	}
+	sourcesPath := field.NewPath("spec", "sources")
+	if nfsexport.Spec.Source.PersistentVolumeClaimName == nil {
+		return field.ErrorList{field.Forbidden(sourcesPath,
+			withHint("may only be set with spec.source.persistentVolumeClaimName", "set the first PVC to export in spec.source.persistentVolumeClaimName", nfsexportDocsURL))}
+	}
+	var errs field.ErrorList
+	hint := "list each PVC to merge into the export once, besides spec.source.persistentVolumeClaimName"
+	seen := map[string]bool{*nfsexport.Spec.Source.PersistentVolumeClaimName: true}
+	for i, claimName := range nfsexport.Spec.Sources {
+		switch {
+		case claimName == "":
+			errs = append(errs, field.Required(sourcesPath.Index(i), withHint("must not be the empty string", hint, nfsexportDocsURL)))
+		case seen[claimName]:
+			errs = append(errs, field.Duplicate(sourcesPath.Index(i), claimName))
+		}
+		seen[claimName] = true
 	}
-	return nil
+	return errs
+}
+
+// ValidateV1NfsExportClass performs additional strict validation of the
+// parameters, the creation timeout and the encryption of a nfsexport class.
+// Unknown csi.storage.k8s.io/ prefixed keys and incomplete secret references
+// are rejected here instead of failing when a nfsexport of the class is
+// created or deleted.
+func ValidateV1NfsExportClass(class *crdv1.VolumeNfsExportClass) error {
+	if class == nil {
+		return fmt.Errorf("VolumeNfsExportClass is nil")
+	}
+	errs := validateV1NfsExportClass(class)
+	errs = append(errs, validateV1NfsExportClassCreationTimeout(class)...)
+	errs = append(errs, validateV1NfsExportClassEncryption(class)...)
+	return errs.ToAggregate()
+}
+
+func validateV1NfsExportClassCreationTimeout(class *crdv1.VolumeNfsExportClass) field.ErrorList {
+	if class.CreationTimeout == nil || class.CreationTimeout.Duration > 0 {
+		return nil
+	}
+	return field.ErrorList{field.Invalid(field.NewPath("creationTimeout"), class.CreationTimeout.Duration.String(),
+		withHint("must be greater than 0", "omit the field to wait for exports indefinitely", nfsexportClassDocsURL))}
+}
+
+func validateV1NfsExportClassEncryption(class *crdv1.VolumeNfsExportClass) field.ErrorList {
+	if class.Encryption == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	encryptionPath := field.NewPath("encryption")
+	if transport := class.Encryption.Transport; transport != nil {
+		switch *transport {
+		case crdv1.NfsExportTransportKrb5p, crdv1.NfsExportTransportStunnel, crdv1.NfsExportTransportNone:
+		default:
+			errs = append(errs, field.Invalid(encryptionPath.Child("transport"), string(*transport),
+				withHint("must be one of krb5p, stunnel, none", "omit the field to use the default of the driver", nfsexportClassDocsURL)))
+		}
+	}
+	if atRest := class.Encryption.AtRest; atRest != nil {
+		refPath := encryptionPath.Child("atRest", "kmsKeySecretRef")
+		hint := "set both the name and the namespace of the Secret holding the KMS key reference"
+		ref := atRest.KMSKeySecretRef
+		if ref.Name == "" {
+			errs = append(errs, field.Required(refPath.Child("name"), withHint("must be set", hint, nfsexportClassDocsURL)))
+		} else if msgs := validation.IsDNS1123Subdomain(ref.Name); len(msgs) > 0 {
+			errs = append(errs, field.Invalid(refPath.Child("name"), ref.Name, withHint(strings.Join(msgs, ", "), hint, nfsexportClassDocsURL)))
+		}
+		if ref.Namespace == "" {
+			errs = append(errs, field.Required(refPath.Child("namespace"), withHint("must be set", hint, nfsexportClassDocsURL)))
+		} else if msgs := validation.IsDNS1123Label(ref.Namespace); len(msgs) > 0 {
+			errs = append(errs, field.Invalid(refPath.Child("namespace"), ref.Namespace, withHint(strings.Join(msgs, ", "), hint, nfsexportClassDocsURL)))
+		}
+	}
+	return errs
+}
+
+func validateV1NfsExportClass(class *crdv1.VolumeNfsExportClass) field.ErrorList {
+	var errs field.ErrorList
+	paramsPath := field.NewPath("parameters")
+
+	keys := make([]string, 0, len(class.Parameters))
+	for key := range class.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := utils.RemovePrefixedParameters(map[string]string{key: class.Parameters[key]}); err != nil {
+			errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+				withHint(err.Error(), "check the spelling of the key or remove it", nfsexportClassDocsURL)))
+		}
+	}
+	if _, err := utils.GetSecretNamespaceTemplates(class.Parameters); err != nil {
+		errs = append(errs, field.Required(paramsPath,
+			withHint(err.Error(), "set both the name and the namespace parameters of the secret", secretsDocsURL)))
+	}
+	crossNamespaceErrs := utils.GetCrossNamespaceSecretErrors(class.Parameters)
+	for _, key := range keys {
+		if err, ok := crossNamespaceErrs[key]; ok {
+			errs = append(errs, field.Forbidden(paramsPath.Key(key),
+				withHint(err.Error(), "set the secret namespace to "+utils.VolumeNfsExportNamespaceTemplate+" to read the secrets of the namespace of each VolumeNfsExport", secretsDocsURL)))
+		}
+	}
+	if _, err := utils.GetExportPathHintPattern(class.Parameters); err != nil {
+		key := utils.PrefixedExportPathHintPatternKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "set a valid RE2 regular expression", nfsexportClassDocsURL)))
+	}
+	if _, err := utils.GetSupportedExportModes(class.Parameters); err != nil {
+		key := utils.PrefixedExportModesKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "list modes among Live and PointInTime, separated by commas", nfsexportClassDocsURL)))
+	}
+	if _, err := utils.GetSupportedExportCacheTiers(class.Parameters); err != nil {
+		key := utils.PrefixedExportCacheTiersKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "list the cache tiers the driver supports, separated by commas", nfsexportClassDocsURL)))
+	}
+	if _, err := utils.IsExportWarmUpRequested(class.Parameters); err != nil {
+		key := utils.PrefixedExportWarmUpKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "set it to true or false", nfsexportClassDocsURL)))
+	}
+	if _, err := utils.IsExportSecurityContextFromPodRequested(class.Parameters); err != nil {
+		key := utils.PrefixedExportSecurityContextSourceKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "remove the parameter to keep the security context of the driver", nfsexportClassDocsURL)))
+	}
+	if _, err := utils.GetExportConsistency(class.Parameters); err != nil {
+		key := utils.PrefixedExportConsistencyKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "set it to CrashConsistent or ApplicationConsistent", nfsexportClassDocsURL)))
+	}
+	if _, err := utils.GetExportQuiesceTimeout(class.Parameters); err != nil {
+		key := utils.PrefixedExportQuiesceTimeoutKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "set a positive duration such as 30s", nfsexportClassDocsURL)))
+	}
+	if _, err := utils.GetExportConsistencyGate(class.Parameters); err != nil {
+		key := utils.PrefixedExportConsistencyGateKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "set it to NoWriters or remove the parameter", nfsexportClassDocsURL)))
+	}
+	if _, err := utils.GetExportConsistencyGateTimeout(class.Parameters); err != nil {
+		key := utils.PrefixedExportConsistencyGateTimeoutKey
+		errs = append(errs, field.Invalid(paramsPath.Key(key), class.Parameters[key],
+			withHint(err.Error(), "set a positive duration such as 10m", nfsexportClassDocsURL)))
+	}
+	for _, key := range []string{utils.PrefixedExportPropagatedAnnotationsKey, utils.PrefixedExportPropagatedLabelsKey} {
+		value, ok := class.Parameters[key]
+		if !ok {
+			continue
+		}
+		if _, _, err := utils.GetPropagatedMetadataKeys(map[string]string{key: value}); err != nil {
+			errs = append(errs, field.Invalid(paramsPath.Key(key), value,
+				withHint(err.Error(), "list qualified annotation or label keys, separated by commas", nfsexportClassDocsURL)))
+		}
+	}
+	return errs
 }
 
 // ValidateV1NfsExportContent performs additional strict validation.
@@ -44,12 +255,89 @@ func ValidateV1NfsExportContent(snapcontent *crdv1.VolumeNfsExportContent) error
 	if snapcontent == nil {
 		return fmt.Errorf("VolumeNfsExportContent is nil")
 	}
+	return validateV1NfsExportContent(snapcontent).ToAggregate()
+}
 
+func validateV1NfsExportContent(snapcontent *crdv1.VolumeNfsExportContent) field.ErrorList {
+	var errs field.ErrorList
 	vsref := snapcontent.Spec.VolumeNfsExportRef
+	refPath := field.NewPath("spec", "volumeNfsExportRef")
+	hint := "set both the name and the namespace of the VolumeNfsExport the content is bound to"
+	if vsref.Name == "" {
+		errs = append(errs, field.Required(refPath.Child("name"), withHint("must be set", hint, nfsexportDocsURL)))
+	}
+	if vsref.Namespace == "" {
+		errs = append(errs, field.Required(refPath.Child("namespace"), withHint("must be set", hint, nfsexportDocsURL)))
+	}
+	errs = append(errs, validateV1NfsExportContentRebindTo(snapcontent)...)
+	errs = append(errs, validateV1NfsExportContentVolumeHandles(snapcontent)...)
+	return errs
+}
+
+// validateV1NfsExportContentVolumeHandles validates the further volume handles
+// of a content merging several volumes into one export.
+func validateV1NfsExportContentVolumeHandles(snapcontent *crdv1.VolumeNfsExportContent) field.ErrorList {
+	source := snapcontent.Spec.Source
+	if len(source.VolumeHandles) == 0 {
+		return nil
+	}
+	handlesPath := field.NewPath("spec", "source", "volumeHandles")
+	if source.VolumeHandle == nil {
+		return field.ErrorList{field.Forbidden(handlesPath,
+			withHint("may only be set with spec.source.volumeHandle", "set the handle of the first volume to export in spec.source.volumeHandle", nfsexportDocsURL))}
+	}
+	var errs field.ErrorList
+	seen := map[string]bool{*source.VolumeHandle: true}
+	for i, handle := range source.VolumeHandles {
+		switch {
+		case handle == "":
+			errs = append(errs, field.Required(handlesPath.Index(i), withHint("must not be the empty string", "list each volume to merge into the export once", nfsexportDocsURL)))
+		case seen[handle]:
+			errs = append(errs, field.Duplicate(handlesPath.Index(i), handle))
+		}
+		seen[handle] = true
+	}
+	return errs
+}
 
-	if vsref.Name == "" || vsref.Namespace == "" {
-		return fmt.Errorf("both Spec.VolumeNfsExportRef.Name = %s and Spec.VolumeNfsExportRef.Namespace = %s must be set", vsref.Name, vsref.Namespace)
+// validateV1NfsExportContentRebindTo validates the rebind annotation of a
+// content handed over to another VolumeNfsExport, possibly in another
+// namespace.
+func validateV1NfsExportContentRebindTo(snapcontent *crdv1.VolumeNfsExportContent) field.ErrorList {
+	target, ok := snapcontent.Annotations[utils.AnnVolumeNfsExportRebindTo]
+	if !ok {
+		return nil
 	}
+	var errs field.ErrorList
+	annPath := field.NewPath("metadata", "annotations").Key(utils.AnnVolumeNfsExportRebindTo)
+	hint := "set it to the <namespace>/<name> of the VolumeNfsExport to hand the content over to"
+	namespace, name, found := strings.Cut(target, "/")
+	if !found || len(validation.IsDNS1123Label(namespace)) > 0 || len(validation.IsDNS1123Subdomain(name)) > 0 {
+		errs = append(errs, field.Invalid(annPath, target, withHint("must be <namespace>/<name> of a VolumeNfsExport", hint, nfsexportDocsURL)))
+	}
+	if snapcontent.Spec.DeletionPolicy != crdv1.VolumeNfsExportContentRetain {
+		detail := fmt.Sprintf("may only be set on a content with deletion policy %s", crdv1.VolumeNfsExportContentRetain)
+		errs = append(errs, field.Invalid(annPath, target, withHint(detail, "set spec.deletionPolicy to Retain first", nfsexportDocsURL)))
+	}
+	return errs
+}
 
-	return nil
+// validateImmutableField returns an error if an immutable string field was
+// changed by an update.
+func validateImmutableField(newValue, oldValue *string, fldPath *field.Path, hint string) field.ErrorList {
+	if newValue == nil && oldValue == nil || newValue != nil && oldValue != nil && *newValue == *oldValue {
+		return nil
+	}
+	detail := fmt.Sprintf("field is immutable but was changed from %s", strPtrDereference(oldValue))
+	return field.ErrorList{field.Invalid(fldPath, strPtrDereference(newValue), withHint(detail, hint, nfsexportDocsURL))}
+}
+
+// validateImmutableList returns an error if an immutable list of strings was
+// changed by an update.
+func validateImmutableList(newValue, oldValue []string, fldPath *field.Path, hint string) field.ErrorList {
+	if reflect.DeepEqual(newValue, oldValue) || len(newValue) == 0 && len(oldValue) == 0 {
+		return nil
+	}
+	detail := fmt.Sprintf("field is immutable but was changed from %v", oldValue)
+	return field.ErrorList{field.Invalid(fldPath, newValue, withHint(detail, hint, nfsexportDocsURL))}
 }
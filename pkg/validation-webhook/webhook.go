@@ -24,26 +24,54 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"time"
 
 	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
 	storagelisters "github.com/kubernetes-csi/external-nfsexporter/client/v6/listers/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/buildinfo"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/features"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/httpendpoint"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
 	"github.com/spf13/cobra"
 
 	informers "github.com/kubernetes-csi/external-nfsexporter/client/v6/informers/externalversions"
 	v1 "k8s.io/api/admission/v1"
 	"k8s.io/api/admission/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
+	coreinformers "k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 )
 
 var (
-	certFile                    string
-	keyFile                     string
-	kubeconfigFile              string
-	port                        int
-	preventVolumeModeConversion bool
+	certFile                  string
+	keyFile                   string
+	certReloadInterval        time.Duration
+	kubeconfigFile            string
+	port                      int
+	checkSecretNamespaces     bool
+	checkDuplicateHandles     bool
+	parametersSchemaNamespace string
+	markOnly                  bool
+	auditedRules              []string
+	allowSkipValidation       bool
+	reservedMetadataManagers  []string
+	httpEndpoint              string
+	disableHTTPEndpoint       bool
+	httpTLSCertFile           string
+	httpTLSKeyFile            string
+	httpTLSClientCAFile       string
+	metricsPath               string
+	controllerBacklogURL      string
+	maxControllerBacklog      int
+	backlogPollInterval       time.Duration
+	backlogPriorityClasses    []string
+	storageVersionMigrators   []string
+	recordRequestedBy         bool
 )
 
 // CmdWebhook is used by Cobra.
@@ -64,12 +92,54 @@ func init() {
 		"File containing the x509 private key matching --tls-cert-file. Required.")
 	CmdWebhook.Flags().IntVar(&port, "port", 443,
 		"Secure port that the webhook listens on")
+	CmdWebhook.Flags().DurationVar(&certReloadInterval, "tls-cert-reload-interval", time.Minute,
+		"Interval at which --tls-cert-file and --tls-private-key-file are re-read, in addition to reloading them when they change on disk. 0 disables the periodic reload.")
 	CmdWebhook.MarkFlagRequired("tls-cert-file")
 	CmdWebhook.MarkFlagRequired("tls-private-key-file")
 	// Add optional flag for kubeconfig
 	CmdWebhook.Flags().StringVar(&kubeconfigFile, "kubeconfig", "", "kubeconfig file to use for volumenfsexportclasses")
-	CmdWebhook.Flags().BoolVar(&preventVolumeModeConversion, "prevent-volume-mode-conversion",
-		false, "Prevents an unauthorised user from modifying the volume mode when creating a PVC from an existing VolumeNfsExport.")
+	features.AddPFlag(CmdWebhook.Flags())
+	// Deprecated, replaced by feature gates. See pkg/features.
+	CmdWebhook.Flags().Bool("prevent-volume-mode-conversion",
+		false, "(deprecated) Prevents an unauthorised user from modifying the volume mode when creating a PVC from an existing VolumeNfsExport. Use --feature-gates=PreventVolumeModeConversion=true instead.")
+	CmdWebhook.Flags().BoolVar(&checkSecretNamespaces, "check-secret-namespaces",
+		false, "Warns when a VolumeNfsExportClass references a secret in a namespace that does not exist. Requires permission to list and watch namespaces.")
+	CmdWebhook.Flags().BoolVar(&checkDuplicateHandles, "check-duplicate-nfsexport-handles",
+		false, "Denies the creation of a pre-provisioned VolumeNfsExportContent whose nfsexport handle is already used by another VolumeNfsExportContent of the same driver. Requires permission to list and watch volumenfsexportcontents.")
+	CmdWebhook.Flags().StringVar(&parametersSchemaNamespace, "parameters-schema-namespace",
+		"", "Namespace of the ConfigMaps labeled "+ParametersSchemaDriverLabel+" in which CSI drivers publish a JSON schema of their VolumeNfsExportClass parameters under the "+ParametersSchemaKey+" key. The parameters of VolumeNfsExportClasses are validated against the schema of their driver. Requires permission to list and watch configmaps in that namespace. If empty, the parameters are not validated against schemas.")
+	CmdWebhook.Flags().BoolVar(&markOnly, "mark-only",
+		false, "Admits VolumeNfsExports and VolumeNfsExportContents failing validation with a warning, and labels them as invalid like the nfsexport controller does, instead of denying them. The webhook must be registered with a MutatingWebhookConfiguration for the labels to be applied. Immutable fields, duplicate nfsexport handles and VolumeNfsExportClasses are still enforced.")
+	CmdWebhook.Flags().StringSliceVar(&auditedRules, "audit-rules",
+		nil, "Comma separated list of validation rules whose failures are admitted with a warning and an "+auditedRulesAnnotation+" audit annotation instead of denied, to find the requests a new rule would deny before enforcing it. A rule is named by the path of the field it validates, as in the rule label of the nfsexport_webhook_rule_violations_total metric, e.g. spec.sources or parameters[*]. Requests also failing a rule that is not listed are still denied, as are changes of immutable fields or reserved labels and annotations, unauthorized skip-validation annotations and errors. If empty, all rules are enforced.")
+	CmdWebhook.Flags().BoolVar(&allowSkipValidation, "allow-skip-validation",
+		false, "Admits VolumeNfsExports and VolumeNfsExportContents annotated with nfsexport.storage.kubernetes.io/skip-validation=true without validating them, if the user adding the annotation is allowed the skip-validation verb on their resource. Each skipped validation is recorded as an event. Requires permission to create subjectaccessreviews and events. If false, the annotation is ignored.")
+	CmdWebhook.Flags().StringSliceVar(&reservedMetadataManagers, "reserved-metadata-managers",
+		nil, "Comma separated list of the users and groups allowed to change the labels and annotations with the "+utils.ReservedMetadataPrefix+" prefix of VolumeNfsExports and VolumeNfsExportContents, e.g. system:serviceaccount:kube-system:nfsexport-controller for the nfsexport controller and system:serviceaccounts:default for the csi-nfsexporter sidecars. Changes by other users are denied, except for the user settable "+utils.AnnSkipValidation+", "+utils.AnnVolumeNfsExportRebindTo+" and deletion secret annotations. If empty, the keys are not protected.")
+	CmdWebhook.Flags().StringVar(&httpEndpoint, "http-endpoint", "",
+		"The TCP network address (example: :8080) or the unix socket (example: unix:///run/nfsexport/http.sock) where the HTTP server for metrics will listen. The default is empty string, which means the server is disabled.")
+	CmdWebhook.Flags().BoolVar(&disableHTTPEndpoint, "disable-http-endpoint", false,
+		"Do not start the HTTP server for metrics even if --http-endpoint is set, for environments that forbid additional listeners.")
+	CmdWebhook.Flags().StringVar(&httpTLSCertFile, "http-tls-cert-file", "",
+		"PEM file of the certificate of the HTTP server for metrics at a TCP --http-endpoint. TLS is used when it is set. Requires --http-tls-key-file. It is reloaded when it changes.")
+	CmdWebhook.Flags().StringVar(&httpTLSKeyFile, "http-tls-key-file", "",
+		"PEM file of the key of --http-tls-cert-file.")
+	CmdWebhook.Flags().StringVar(&httpTLSClientCAFile, "http-tls-client-ca-file", "",
+		"PEM file of the certificate authorities used to verify the client certificates required by the HTTP server for metrics, for mutual TLS. Requires --http-tls-cert-file. It is reloaded when it changes.")
+	CmdWebhook.Flags().StringVar(&metricsPath, "metrics-path", "/metrics",
+		"The HTTP path where prometheus metrics will be exposed. Default is `/metrics`.")
+	CmdWebhook.Flags().StringVar(&controllerBacklogURL, "controller-backlog-url", "",
+		"URL of the "+utils.BacklogPath+" path of the --http-endpoint of the nfsexport controller, e.g. http://nfsexport-controller.kube-system:8080"+utils.BacklogPath+". The creation of VolumeNfsExports is denied with a 429 status while the number of objects waiting in the work queues of the controller exceeds --max-controller-backlog, except for the VolumeNfsExportClasses of --backlog-priority-classes. Creations are admitted while the backlog cannot be polled. Polls answered by a standby replica of the controller keep the last backlog of the leader. If empty, creations are never denied for the backlog.")
+	CmdWebhook.Flags().IntVar(&maxControllerBacklog, "max-controller-backlog", 1000,
+		"Backlog of the nfsexport controller above which the creation of VolumeNfsExports is denied. Only used if --controller-backlog-url is set.")
+	CmdWebhook.Flags().DurationVar(&backlogPollInterval, "backlog-poll-interval", 10*time.Second,
+		"Interval at which --controller-backlog-url is polled. Denied clients are asked to retry after this interval.")
+	CmdWebhook.Flags().StringSliceVar(&backlogPriorityClasses, "backlog-priority-classes",
+		nil, "Comma separated list of the VolumeNfsExportClasses whose VolumeNfsExports are created regardless of the backlog of the nfsexport controller.")
+	CmdWebhook.Flags().StringSliceVar(&storageVersionMigrators, "storage-version-migrators",
+		nil, "Comma separated list of the users and groups of the storage version migrator, e.g. system:serviceaccount:kube-system:storage-version-migrator. Their updates of VolumeNfsExports and VolumeNfsExportContents which change neither the spec nor the labels and annotations are admitted without validation, so that objects which do not pass the current validation can be migrated. If empty, their updates are validated.")
+	CmdWebhook.Flags().BoolVar(&recordRequestedBy, "record-requesters",
+		false, "Records the name of the user who creates a VolumeNfsExport or VolumeNfsExportContent, or changes its spec, in its "+utils.AnnRequestedBy+" annotation, which the nfsexport controller copies to dynamically created VolumeNfsExportContents and the csi-nfsexporter sidecar reports in its audit records. The webhook must be registered with a MutatingWebhookConfiguration for the annotation to be applied. Changes by the users and groups of --reserved-metadata-managers are not recorded.")
 }
 
 // admitv1beta1Func handles a v1beta1 admission
@@ -184,28 +254,104 @@ func serve(w http.ResponseWriter, r *http.Request, admit admitHandler) {
 }
 
 type serveWebhook struct {
-	lister storagelisters.VolumeNfsExportClassLister
+	lister          storagelisters.VolumeNfsExportClassLister
+	namespaceLister corelisters.NamespaceLister
+	contentIndexer  cache.Indexer
+	schemaLister    corelisters.ConfigMapNamespaceLister
+	markOnly        bool
+	// auditedRules are the rules audited instead of enforced, see
+	// auditRules.
+	auditedRules []string
+	skipper      *validationSkipper
+	// backpressure is nil if creations are never denied for the backlog of
+	// the nfsexport controller.
+	backpressure *backpressure
+	// reservedMetadata is nil if the reserved labels and annotations are
+	// not protected.
+	reservedMetadata *reservedMetadataGuard
+	// storageMigrators is nil if the updates of the storage version
+	// migrator are validated.
+	storageMigrators *storageMigrators
+	// recordRequesters records the requesters of nfsexports and contents,
+	// see requesterRecorder.
+	recordRequesters bool
+	// metrics is nil if metrics are disabled.
+	metrics *webhookMetrics
 }
 
 func (s serveWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	serve(w, r, newDelegateToV1AdmitHandler(NewNfsExportAdmitter(s.lister)))
+	a := &admitter{
+		lister:           s.lister,
+		namespaceLister:  s.namespaceLister,
+		contentIndexer:   s.contentIndexer,
+		schemaLister:     s.schemaLister,
+		markOnly:         s.markOnly,
+		skipper:          s.skipper,
+		reservedMetadata: s.reservedMetadata,
+		backpressure:     s.backpressure,
+		storageMigrators: s.storageMigrators,
+	}
+	serve(w, r, newDelegateToV1AdmitHandler(s.metrics.instrument(recordRequesters(auditRules(a, s.auditedRules), s.recordRequesters, s.reservedMetadata))))
 }
 
-func startServer(ctx context.Context, tlsConfig *tls.Config, cw *CertWatcher, lister storagelisters.VolumeNfsExportClassLister) error {
+func startServer(ctx context.Context, tlsConfig *tls.Config, cw *CertWatcher, lister storagelisters.VolumeNfsExportClassLister, namespaceLister corelisters.NamespaceLister, contentIndexer cache.Indexer, schemaLister corelisters.ConfigMapNamespaceLister, skipper *validationSkipper, info buildinfo.Info) error {
 	go func() {
 		klog.Info("Starting certificate watcher")
 		if err := cw.Start(ctx); err != nil {
 			klog.Errorf("certificate watcher error: %v", err)
 		}
 	}()
+	if controllerBacklogURL != "" && backlogPollInterval <= 0 {
+		return fmt.Errorf("--backlog-poll-interval must be positive, got %v", backlogPollInterval)
+	}
 	// Pipe through the informer at some point here.
 	s := &serveWebhook{
-		lister: lister,
+		lister:           lister,
+		namespaceLister:  namespaceLister,
+		contentIndexer:   contentIndexer,
+		schemaLister:     schemaLister,
+		markOnly:         markOnly,
+		auditedRules:     auditedRules,
+		skipper:          skipper,
+		reservedMetadata: newReservedMetadataGuard(reservedMetadataManagers),
+		backpressure:     newBackpressure(controllerBacklogURL, maxControllerBacklog, backlogPriorityClasses, backlogPollInterval),
+		storageMigrators: newStorageMigrators(storageVersionMigrators),
+		recordRequesters: recordRequestedBy,
+	}
+	if s.backpressure != nil {
+		klog.Infof("Denying the creation of VolumeNfsExports while the backlog of the nfsexport controller exceeds %d", maxControllerBacklog)
+		go s.backpressure.run(ctx)
+	}
+	if markOnly {
+		klog.Info("Running in mark-only mode, invalid VolumeNfsExports and VolumeNfsExportContents are labeled instead of denied")
+	}
+
+	if httpEndpoint != "" {
+		s.metrics = newWebhookMetrics()
+		buildinfo.RegisterMetrics(s.metrics.registry, info)
+		features.RegisterMetrics(s.metrics.registry)
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle(metricsPath, s.metrics.handler())
+		metricsMux.Handle(buildinfo.VersionPath, buildinfo.Handler(info))
+		l, err := httpendpoint.Listen(httpEndpoint, httpendpoint.Options{
+			CertFile:     httpTLSCertFile,
+			KeyFile:      httpTLSKeyFile,
+			ClientCAFile: httpTLSClientCAFile,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to listen on address %s: %v", httpEndpoint, err)
+		}
+		go func() {
+			if err := http.Serve(l, metricsMux); err != nil {
+				klog.Fatalf("failed to start endpoint at %s%s: %v", httpEndpoint, metricsPath, err)
+			}
+		}()
+		klog.Infof("Metrics http server successfully started on %s, %s", httpEndpoint, metricsPath)
 	}
 
 	fmt.Println("Starting webhook server")
 	mux := http.NewServeMux()
-	mux.Handle("/volumenfsexport", s)
+	mux.Handle("/volumenfsexport", s.metrics.recoverPanics(s))
 	mux.HandleFunc("/readyz", func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("ok")) })
 	srv := &http.Server{
 		Handler:   mux,
@@ -222,10 +368,20 @@ func startServer(ctx context.Context, tlsConfig *tls.Config, cw *CertWatcher, li
 }
 
 func main(cmd *cobra.Command, args []string) {
+	info := buildinfo.Get(cmd.Version)
+	klog.Infof("Version: %s", info)
+	if err := features.SetFromDeprecatedPFlags(cmd.Flags()); err != nil {
+		klog.Fatal(err)
+	}
+	if disableHTTPEndpoint && httpEndpoint != "" {
+		klog.Infof("Not starting the HTTP server at %s, --disable-http-endpoint is set", httpEndpoint)
+		httpEndpoint = ""
+	}
+
 	// Create new cert watcher
 	ctx, cancel := context.WithCancel(cmd.Context())
 	defer cancel() // stops certwatcher
-	cw, err := NewCertWatcher(certFile, keyFile)
+	cw, err := NewCertWatcher(certFile, keyFile, certReloadInterval)
 	if err != nil {
 		klog.Fatalf("failed to initialize new cert watcher: %v", err)
 	}
@@ -249,12 +405,53 @@ func main(cmd *cobra.Command, args []string) {
 	factory := informers.NewSharedInformerFactory(snapClient, 0)
 	lister := factory.NfsExport().V1().VolumeNfsExportClasses().Lister()
 
+	var kubeClient kubernetes.Interface
+	if checkSecretNamespaces || allowSkipValidation || parametersSchemaNamespace != "" {
+		kubeClient, err = kubernetes.NewForConfig(config)
+		if err != nil {
+			klog.Errorf("Error building kubernetes clientset: %s", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	var namespaceLister corelisters.NamespaceLister
+	if checkSecretNamespaces {
+		coreFactory := coreinformers.NewSharedInformerFactory(kubeClient, 0)
+		namespaceLister = coreFactory.Core().V1().Namespaces().Lister()
+		coreFactory.Start(ctx.Done())
+		coreFactory.WaitForCacheSync(ctx.Done())
+	}
+
+	var schemaLister corelisters.ConfigMapNamespaceLister
+	if parametersSchemaNamespace != "" {
+		schemaFactory := coreinformers.NewSharedInformerFactoryWithOptions(kubeClient, 0, coreinformers.WithNamespace(parametersSchemaNamespace))
+		schemaLister = schemaFactory.Core().V1().ConfigMaps().Lister().ConfigMaps(parametersSchemaNamespace)
+		schemaFactory.Start(ctx.Done())
+		schemaFactory.WaitForCacheSync(ctx.Done())
+	}
+
+	var contentIndexer cache.Indexer
+	if checkDuplicateHandles {
+		contentInformer := factory.NfsExport().V1().VolumeNfsExportContents().Informer()
+		if err := contentInformer.AddIndexers(cache.Indexers{utils.ContentHandleIndex: utils.ContentHandleIndexFunc}); err != nil {
+			klog.Errorf("Error adding the %s index to the content informer: %s", utils.ContentHandleIndex, err.Error())
+			os.Exit(1)
+		}
+		contentIndexer = contentInformer.GetIndexer()
+	}
+
 	// Start the informers
 	factory.Start(ctx.Done())
 	// wait for the caches to sync
 	factory.WaitForCacheSync(ctx.Done())
 
-	if err := startServer(ctx, tlsConfig, cw, lister); err != nil {
+	var skipper *validationSkipper
+	if allowSkipValidation {
+		klog.Info("Objects annotated with " + utils.AnnSkipValidation + " skip validation for authorized users")
+		skipper = newValidationSkipper(kubeClient)
+	}
+
+	if err := startServer(ctx, tlsConfig, cw, lister, namespaceLister, contentIndexer, schemaLister, skipper, info); err != nil {
 		klog.Fatalf("server stopped: %v", err)
 	}
 }
@@ -12,8 +12,11 @@ import (
 	"math/big"
 	"net"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/buildinfo"
 )
 
 func TestWebhookCertReload(t *testing.T) {
@@ -37,7 +40,7 @@ func TestWebhookCertReload(t *testing.T) {
 	// Start test server
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	cw, err := NewCertWatcher(certFile, keyFile)
+	cw, err := NewCertWatcher(certFile, keyFile, 0)
 	if err != nil {
 		t.Errorf("failed to initialize new cert watcher: %v", err)
 	}
@@ -45,7 +48,7 @@ func TestWebhookCertReload(t *testing.T) {
 		GetCertificate: cw.GetCertificate,
 	}
 	go func() {
-		if err := startServer(ctx, tlsConfig, cw, &fakeNfsExportLister{}); err != nil {
+		if err := startServer(ctx, tlsConfig, cw, &fakeNfsExportLister{}, nil, nil, nil, nil, buildinfo.Get("test")); err != nil {
 			panic(err)
 		}
 	}()
@@ -96,6 +99,61 @@ func TestWebhookCertReload(t *testing.T) {
 	}
 }
 
+// TestCertWatcherSecretVolumeUpdate replaces the certificate the way the
+// kubelet updates a Secret volume: files are symlinks into a "..data" symlink
+// to a timestamped directory, which is swapped atomically.
+func TestCertWatcherSecretVolumeUpdate(t *testing.T) {
+	dir := t.TempDir()
+	writeVersion := func(version string) {
+		versionDir := filepath.Join(dir, version)
+		if err := os.Mkdir(versionDir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := generateTestCertKeyPair(t, filepath.Join(versionDir, "tls.crt"), filepath.Join(versionDir, "tls.key")); err != nil {
+			t.Fatal(err)
+		}
+		tmpLink := filepath.Join(dir, "..data_tmp")
+		if err := os.Symlink(version, tmpLink); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmpLink, filepath.Join(dir, "..data")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeVersion("v1")
+	for _, name := range []string{"tls.crt", "tls.key"} {
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cw, err := NewCertWatcher(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to initialize new cert watcher: %v", err)
+	}
+	go cw.Start(ctx)
+	originalCert, _ := cw.GetCertificate(nil)
+
+	writeVersion("v2")
+	if err := os.RemoveAll(filepath.Join(dir, "v1")); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		cert, _ := cw.GetCertificate(nil)
+		if string(cert.Certificate[0]) != string(originalCert.Certificate[0]) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("certificate was not reloaded")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
 // generateTestCertKeyPair generates a new random test key/crt and writes it to tmpDir
 // based on https://golang.org/src/crypto/tls/generate_cert.go
 func generateTestCertKeyPair(t *testing.T, certPath, keyPath string) error {
@@ -0,0 +1,52 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	"github.com/kubernetes-csi/external-nfsexporter/v6/pkg/utils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDistributedMode checks that the nfsexports of a node-local volume are
+// handed to the sidecar running on the node of the volume.
+func TestDistributedMode(t *testing.T) {
+	if *nodeDriverName == "" {
+		t.Skip("no node driver configured, set -node-driver")
+	}
+	f := newFramework(t)
+	nodes, err := kubeClient.CoreV1().Nodes().List(f.ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to list nodes: %v", err)
+	}
+	if len(nodes.Items) == 0 {
+		t.Fatalf("no nodes in the cluster")
+	}
+	nodeName := nodes.Items[0].Name
+
+	class := f.createClass(*nodeDriverName, crdv1.VolumeNfsExportContentDelete, nil)
+	claim := f.createSourceClaim(*nodeDriverName, nodeName)
+	f.createNfsExport("export", class.Name, claim.Name)
+	_, content := f.waitForNfsExportReady("export")
+
+	if managedBy := content.Labels[utils.VolumeNfsExportContentManagedByLabel]; managedBy != nodeName {
+		t.Errorf("expected content managed by node %s, got %q", nodeName, managedBy)
+	}
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package e2e holds end-to-end tests that run against a cluster with the
+// nfsexport controller, the validation webhook and the mock NFS CSI driver of
+// test/e2e/mock-driver deployed, usually set up by run-kind.sh. The tests are
+// skipped unless -kubeconfig or E2E_KUBECONFIG points at such a cluster.
+// Every test runs in its own namespace and with its own cluster scoped
+// objects, so tests run in parallel.
+package e2e
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+	clientset "github.com/kubernetes-csi/external-nfsexporter/client/v6/clientset/versioned"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Command line flags, passed after -args.
+var (
+	kubeconfig        = flag.String("kubeconfig", os.Getenv("E2E_KUBECONFIG"), "Kubeconfig of the cluster under test. The tests are skipped if empty. Defaults to the E2E_KUBECONFIG environment variable.")
+	driverName        = flag.String("driver", "nfs.mock.csi.k8s.io", "Name of the CSI driver served by the controller deployment of the sidecar.")
+	nodeDriverName    = flag.String("node-driver", "nfs-node.mock.csi.k8s.io", "Name of the CSI driver served by the node deployment of the sidecar. Distributed mode tests are skipped if empty.")
+	webhookDeployed   = flag.Bool("webhook", true, "Run the tests of the validation webhook.")
+	operationTimeout  = flag.Duration("operation-timeout", 2*time.Minute, "Maximum time to wait for an object to reach a state.")
+	operationInterval = flag.Duration("operation-interval", time.Second, "Interval between checks of the state of an object.")
+)
+
+var (
+	kubeClient kubernetes.Interface
+	client     clientset.Interface
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if *kubeconfig != "" {
+		config, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to load %s: %v\n", *kubeconfig, err)
+			os.Exit(1)
+		}
+		kubeClient = kubernetes.NewForConfigOrDie(config)
+		client = clientset.NewForConfigOrDie(config)
+	}
+	os.Exit(m.Run())
+}
+
+// framework holds the namespace and the helpers of a test.
+type framework struct {
+	t         *testing.T
+	ctx       context.Context
+	namespace string
+}
+
+// newFramework skips the test if no cluster is configured. Otherwise it marks
+// the test as parallel and creates a namespace for it, deleted with the other
+// objects of the test when it completes.
+func newFramework(t *testing.T) *framework {
+	if client == nil {
+		t.Skip("no cluster configured, set -kubeconfig or E2E_KUBECONFIG")
+	}
+	t.Parallel()
+
+	f := &framework{t: t, ctx: context.Background()}
+	ns, err := kubeClient.CoreV1().Namespaces().Create(f.ctx, &v1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "nfsexport-e2e-"},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create namespace: %v", err)
+	}
+	f.namespace = ns.Name
+	t.Cleanup(func() {
+		if err := kubeClient.CoreV1().Namespaces().Delete(f.ctx, f.namespace, metav1.DeleteOptions{}); err != nil {
+			t.Errorf("failed to delete namespace %s: %v", f.namespace, err)
+		}
+	})
+	return f
+}
+
+// createClass creates a VolumeNfsExportClass of the driver, named after the
+// namespace of the test.
+func (f *framework) createClass(driver string, policy crdv1.DeletionPolicy, parameters map[string]string) *crdv1.VolumeNfsExportClass {
+	class, err := client.NfsExportV1().VolumeNfsExportClasses().Create(f.ctx, &crdv1.VolumeNfsExportClass{
+		ObjectMeta:     metav1.ObjectMeta{Name: f.namespace},
+		Driver:         driver,
+		Parameters:     parameters,
+		DeletionPolicy: policy,
+	}, metav1.CreateOptions{})
+	if err != nil {
+		f.t.Fatalf("failed to create VolumeNfsExportClass: %v", err)
+	}
+	f.t.Cleanup(func() {
+		client.NfsExportV1().VolumeNfsExportClasses().Delete(f.ctx, class.Name, metav1.DeleteOptions{})
+	})
+	return class
+}
+
+// createSourceClaim creates a PVC bound to a pre-provisioned PV of the
+// driver, so that no provisioner is needed. If nodeName is set, the PV is
+// only accessible from that node.
+func (f *framework) createSourceClaim(driver, nodeName string) *v1.PersistentVolumeClaim {
+	name := "source"
+	storageClassName := ""
+	pv := &v1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: f.namespace},
+		Spec: v1.PersistentVolumeSpec{
+			Capacity:    v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")},
+			AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteMany},
+			PersistentVolumeSource: v1.PersistentVolumeSource{
+				CSI: &v1.CSIPersistentVolumeSource{Driver: driver, VolumeHandle: f.namespace},
+			},
+			ClaimRef:                      &v1.ObjectReference{Namespace: f.namespace, Name: name},
+			PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+			StorageClassName:              storageClassName,
+		},
+	}
+	if nodeName != "" {
+		pv.Spec.NodeAffinity = &v1.VolumeNodeAffinity{
+			Required: &v1.NodeSelector{
+				NodeSelectorTerms: []v1.NodeSelectorTerm{{
+					MatchFields: []v1.NodeSelectorRequirement{{
+						Key:      "metadata.name",
+						Operator: v1.NodeSelectorOpIn,
+						Values:   []string{nodeName},
+					}},
+				}},
+			},
+		}
+	}
+	if _, err := kubeClient.CoreV1().PersistentVolumes().Create(f.ctx, pv, metav1.CreateOptions{}); err != nil {
+		f.t.Fatalf("failed to create PersistentVolume: %v", err)
+	}
+	f.t.Cleanup(func() {
+		kubeClient.CoreV1().PersistentVolumes().Delete(f.ctx, pv.Name, metav1.DeleteOptions{})
+	})
+
+	_, err := kubeClient.CoreV1().PersistentVolumeClaims(f.namespace).Create(f.ctx, &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: v1.PersistentVolumeClaimSpec{
+			AccessModes:      []v1.PersistentVolumeAccessMode{v1.ReadWriteMany},
+			Resources:        v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceStorage: resource.MustParse("1Gi")}},
+			StorageClassName: &storageClassName,
+			VolumeName:       pv.Name,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		f.t.Fatalf("failed to create PersistentVolumeClaim: %v", err)
+	}
+
+	var claim *v1.PersistentVolumeClaim
+	f.waitFor("PersistentVolumeClaim "+name+" to be bound", func() (bool, error) {
+		claim, err = kubeClient.CoreV1().PersistentVolumeClaims(f.namespace).Get(f.ctx, name, metav1.GetOptions{})
+		return err == nil && claim.Status.Phase == v1.ClaimBound, err
+	})
+	return claim
+}
+
+// createNfsExport creates a VolumeNfsExport of the class from the PVC.
+func (f *framework) createNfsExport(name, className, claimName string) *crdv1.VolumeNfsExport {
+	nfsexport, err := client.NfsExportV1().VolumeNfsExports(f.namespace).Create(f.ctx, &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: crdv1.VolumeNfsExportSpec{
+			Source:                   crdv1.VolumeNfsExportSource{PersistentVolumeClaimName: &claimName},
+			VolumeNfsExportClassName: &className,
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		f.t.Fatalf("failed to create VolumeNfsExport %s: %v", name, err)
+	}
+	return nfsexport
+}
+
+// waitForNfsExportReady waits for a VolumeNfsExport to be bound and ready to
+// use, and returns it with its content.
+func (f *framework) waitForNfsExportReady(name string) (*crdv1.VolumeNfsExport, *crdv1.VolumeNfsExportContent) {
+	var nfsexport *crdv1.VolumeNfsExport
+	f.waitFor("VolumeNfsExport "+name+" to be ready", func() (bool, error) {
+		var err error
+		nfsexport, err = client.NfsExportV1().VolumeNfsExports(f.namespace).Get(f.ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		status := nfsexport.Status
+		return status != nil && status.BoundVolumeNfsExportContentName != nil && status.ReadyToUse != nil && *status.ReadyToUse, nil
+	})
+	content, err := client.NfsExportV1().VolumeNfsExportContents().Get(f.ctx, *nfsexport.Status.BoundVolumeNfsExportContentName, metav1.GetOptions{})
+	if err != nil {
+		f.t.Fatalf("failed to get VolumeNfsExportContent of %s: %v", name, err)
+	}
+	return nfsexport, content
+}
+
+// deleteNfsExport deletes a VolumeNfsExport and waits for it to be gone.
+func (f *framework) deleteNfsExport(name string) {
+	if err := client.NfsExportV1().VolumeNfsExports(f.namespace).Delete(f.ctx, name, metav1.DeleteOptions{}); err != nil {
+		f.t.Fatalf("failed to delete VolumeNfsExport %s: %v", name, err)
+	}
+	f.waitFor("VolumeNfsExport "+name+" to be deleted", func() (bool, error) {
+		_, err := client.NfsExportV1().VolumeNfsExports(f.namespace).Get(f.ctx, name, metav1.GetOptions{})
+		return apierrs.IsNotFound(err), ignoreNotFound(err)
+	})
+}
+
+// cleanupContent deletes a VolumeNfsExportContent when the test completes,
+// for contents that outlive their nfsexport.
+func (f *framework) cleanupContent(name string) {
+	f.t.Cleanup(func() {
+		client.NfsExportV1().VolumeNfsExportContents().Delete(f.ctx, name, metav1.DeleteOptions{})
+	})
+}
+
+// waitFor polls condition until it returns true, and fails the test if it
+// returns an error or does not return true within the operation timeout.
+func (f *framework) waitFor(what string, condition wait.ConditionFunc) {
+	f.t.Helper()
+	if err := wait.PollImmediate(*operationInterval, *operationTimeout, condition); err != nil {
+		f.t.Fatalf("failed waiting for %s: %v", what, err)
+	}
+}
+
+func ignoreNotFound(err error) error {
+	if apierrs.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
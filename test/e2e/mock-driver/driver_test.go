@@ -0,0 +1,72 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+)
+
+func TestDriver(t *testing.T) {
+	endpoint := "unix://" + filepath.Join(t.TempDir(), "csi.sock")
+	listener, err := listen(endpoint)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", endpoint, err)
+	}
+	server := grpc.NewServer()
+	newDriver("nfs.mock.csi.k8s.io", "v1.0.0").register(server)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, endpoint, grpc.WithInsecure(), grpc.WithBlock())
+	if err != nil {
+		t.Fatalf("failed to connect to %s: %v", endpoint, err)
+	}
+	defer conn.Close()
+
+	identity := csi.NewIdentityClient(conn)
+	info, err := identity.GetPluginInfo(ctx, &csi.GetPluginInfoRequest{})
+	if err != nil {
+		t.Fatalf("GetPluginInfo failed: %v", err)
+	}
+	if info.Name != "nfs.mock.csi.k8s.io" || info.VendorVersion != "v1.0.0" {
+		t.Errorf("expected nfs.mock.csi.k8s.io v1.0.0, got %s %s", info.Name, info.VendorVersion)
+	}
+	probe, err := identity.Probe(ctx, &csi.ProbeRequest{})
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if !probe.GetReady().GetValue() {
+		t.Errorf("expected the driver to be ready")
+	}
+	if _, err := csi.NewControllerClient(conn).ControllerGetCapabilities(ctx, &csi.ControllerGetCapabilitiesRequest{}); err != nil {
+		t.Errorf("ControllerGetCapabilities failed: %v", err)
+	}
+}
+
+func TestListenUnsupportedEndpoint(t *testing.T) {
+	if _, err := listen("/csi/csi.sock"); err == nil {
+		t.Errorf("expected an error for an endpoint without scheme")
+	}
+}
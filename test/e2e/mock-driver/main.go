@@ -0,0 +1,91 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command mock-driver is a lightweight mock NFS CSI driver that the E2E
+// tests deploy next to the csi-nfsexporter sidecar.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"google.golang.org/grpc"
+	klog "k8s.io/klog/v2"
+)
+
+// Command line flags
+var (
+	endpoint    = flag.String("endpoint", "unix:///csi/csi.sock", "CSI endpoint to listen on.")
+	driverName  = flag.String("drivername", "nfs.mock.csi.k8s.io", "Name of the driver.")
+	showVersion = flag.Bool("version", false, "Show version.")
+)
+
+var version = "unknown"
+
+func main() {
+	klog.InitFlags(nil)
+	flag.Set("logtostderr", "true")
+	flag.Parse()
+
+	if *showVersion {
+		fmt.Println(os.Args[0], version)
+		os.Exit(0)
+	}
+	klog.Infof("Version: %s", version)
+
+	listener, err := listen(*endpoint)
+	if err != nil {
+		klog.Errorf("failed to listen on %s: %v", *endpoint, err)
+		os.Exit(1)
+	}
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(logGRPC))
+	newDriver(*driverName, version).register(server)
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-c
+		server.GracefulStop()
+	}()
+
+	klog.Infof("Serving %s on %s", *driverName, *endpoint)
+	if err := server.Serve(listener); err != nil {
+		klog.Errorf("failed to serve: %v", err)
+		os.Exit(1)
+	}
+}
+
+// listen listens on a unix:// or tcp:// endpoint. A stale unix socket left by
+// a previous run is removed first.
+func listen(endpoint string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(endpoint, "unix://"):
+		path := strings.TrimPrefix(endpoint, "unix://")
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %v", path, err)
+		}
+		return net.Listen("unix", path)
+	case strings.HasPrefix(endpoint, "tcp://"):
+		return net.Listen("tcp", strings.TrimPrefix(endpoint, "tcp://"))
+	}
+	return nil, fmt.Errorf("unsupported endpoint %q, expected unix:// or tcp://", endpoint)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+
+	v1 "k8s.io/api/core/v1"
+	apierrs "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDynamicCreate(t *testing.T) {
+	f := newFramework(t)
+	class := f.createClass(*driverName, crdv1.VolumeNfsExportContentDelete, nil)
+	claim := f.createSourceClaim(*driverName, "")
+
+	f.createNfsExport("export", class.Name, claim.Name)
+	nfsexport, content := f.waitForNfsExportReady("export")
+
+	if content.Spec.Driver != *driverName {
+		t.Errorf("expected content of driver %s, got %s", *driverName, content.Spec.Driver)
+	}
+	if content.Spec.Source.VolumeHandle == nil || *content.Spec.Source.VolumeHandle != f.namespace {
+		t.Errorf("expected content of volume %s, got %v", f.namespace, content.Spec.Source.VolumeHandle)
+	}
+	if ref := content.Spec.VolumeNfsExportRef; ref.Namespace != f.namespace || ref.Name != nfsexport.Name || ref.UID != nfsexport.UID {
+		t.Errorf("expected content bound to %s/%s, got %+v", f.namespace, nfsexport.Name, ref)
+	}
+}
+
+func TestPreProvisionedImport(t *testing.T) {
+	f := newFramework(t)
+	handle := "imported-" + f.namespace
+	contentName := f.namespace
+	content, err := client.NfsExportV1().VolumeNfsExportContents().Create(f.ctx, &crdv1.VolumeNfsExportContent{
+		ObjectMeta: metav1.ObjectMeta{Name: contentName},
+		Spec: crdv1.VolumeNfsExportContentSpec{
+			VolumeNfsExportRef: v1.ObjectReference{Namespace: f.namespace, Name: "export"},
+			DeletionPolicy:     crdv1.VolumeNfsExportContentRetain,
+			Driver:             *driverName,
+			Source:             crdv1.VolumeNfsExportContentSource{NfsExportHandle: &handle},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create VolumeNfsExportContent: %v", err)
+	}
+	f.cleanupContent(content.Name)
+
+	_, err = client.NfsExportV1().VolumeNfsExports(f.namespace).Create(f.ctx, &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "export"},
+		Spec: crdv1.VolumeNfsExportSpec{
+			Source: crdv1.VolumeNfsExportSource{VolumeNfsExportContentName: &contentName},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		t.Fatalf("failed to create VolumeNfsExport: %v", err)
+	}
+	_, content = f.waitForNfsExportReady("export")
+
+	if content.Name != contentName {
+		t.Errorf("expected VolumeNfsExport bound to %s, got %s", contentName, content.Name)
+	}
+	if content.Status == nil || content.Status.NfsExportHandle == nil || *content.Status.NfsExportHandle != handle {
+		t.Errorf("expected content status with handle %s, got %+v", handle, content.Status)
+	}
+}
+
+func TestDeletionPolicy(t *testing.T) {
+	tests := []struct {
+		policy        crdv1.DeletionPolicy
+		expectDeleted bool
+	}{
+		{policy: crdv1.VolumeNfsExportContentDelete, expectDeleted: true},
+		{policy: crdv1.VolumeNfsExportContentRetain, expectDeleted: false},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(string(test.policy), func(t *testing.T) {
+			f := newFramework(t)
+			class := f.createClass(*driverName, test.policy, nil)
+			claim := f.createSourceClaim(*driverName, "")
+			f.createNfsExport("export", class.Name, claim.Name)
+			_, content := f.waitForNfsExportReady("export")
+			f.cleanupContent(content.Name)
+
+			f.deleteNfsExport("export")
+
+			if test.expectDeleted {
+				f.waitFor("VolumeNfsExportContent "+content.Name+" to be deleted", func() (bool, error) {
+					_, err := client.NfsExportV1().VolumeNfsExportContents().Get(f.ctx, content.Name, metav1.GetOptions{})
+					return apierrs.IsNotFound(err), ignoreNotFound(err)
+				})
+				return
+			}
+			retained, err := client.NfsExportV1().VolumeNfsExportContents().Get(f.ctx, content.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("expected VolumeNfsExportContent %s to be retained: %v", content.Name, err)
+			}
+			if retained.Status == nil || retained.Status.NfsExportHandle == nil {
+				t.Errorf("expected retained content to keep its handle, got %+v", retained.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+#!/bin/bash
+
+# Copyright 2026 The Kubernetes Authors.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Runs the E2E tests against a kind cluster. The cluster is created unless
+# E2E_KIND_CLUSTER names an existing one, the components of this repository
+# and the mock NFS CSI driver are built and deployed into it, then the tests
+# are run with E2E_PARALLEL tests at a time. Set E2E_SKIP_DEPLOY=true to
+# rerun the tests against a cluster that is already set up.
+#
+# Requires docker, kind, kubectl and jq.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+ROOT=$(cd "$(dirname "$0")/../.."; pwd)
+cd "${ROOT}"
+
+cluster="${E2E_KIND_CLUSTER:-nfsexport-e2e}"
+parallel="${E2E_PARALLEL:-4}"
+kubeconfig="${ROOT}/bin/e2e-kubeconfig"
+
+if ! kind get clusters | grep -qx "${cluster}"; then
+    kind create cluster --name "${cluster}"
+fi
+mkdir -p "${ROOT}/bin"
+kind get kubeconfig --name "${cluster}" >"${kubeconfig}"
+export KUBECONFIG="${kubeconfig}"
+
+if [ "${E2E_SKIP_DEPLOY:-false}" != "true" ]; then
+    # Build the images and load them into the nodes.
+    for cmd in nfsexport-controller csi-nfsexporter nfsexport-validation-webhook; do
+        (cd "cmd/${cmd}" && CGO_ENABLED=0 GOOS=linux go build -mod=vendor -o "${ROOT}/bin/${cmd}" .)
+        docker build -t "${cmd}:e2e" -f "cmd/${cmd}/Dockerfile" --build-arg "binary=./bin/${cmd}" .
+        kind load docker-image --name "${cluster}" "${cmd}:e2e"
+    done
+    (cd test/e2e/mock-driver && CGO_ENABLED=0 GOOS=linux go build -mod=vendor -o "${ROOT}/bin/mock-driver" .)
+    docker build -t mock-driver:e2e -f test/e2e/mock-driver/Dockerfile .
+    kind load docker-image --name "${cluster}" mock-driver:e2e
+
+    # CRDs and the nfsexport controller, with distributed mode enabled.
+    kubectl apply -k client/config/crd
+    kubectl apply -k deploy/kubernetes/nfsexport-controller
+    kubectl apply -f test/e2e/manifests/rbac-distributed.yaml
+    kubectl -n kube-system set image deployment/nfsexport-controller nfsexport-controller=nfsexport-controller:e2e
+    kubectl -n kube-system patch deployment/nfsexport-controller --type=json \
+        -p='[{"op": "add", "path": "/spec/template/spec/containers/0/args/-", "value": "--feature-gates=DistributedExporting=true"}]'
+
+    # The validation webhook.
+    ./deploy/kubernetes/webhook-example/create-cert.sh --service nfsexport-validation-service --secret nfsexport-validation-secret --namespace default
+    ./deploy/kubernetes/webhook-example/patch-ca-bundle.sh <deploy/kubernetes/webhook-example/admission-configuration-template | kubectl apply -f -
+    kubectl apply -f deploy/kubernetes/webhook-example/rbac-nfsexport-webhook.yaml -f deploy/kubernetes/webhook-example/webhook.yaml
+    kubectl set image deployment/nfsexport-validation-deployment nfsexport-validation=nfsexport-validation-webhook:e2e
+
+    # The mock driver with its sidecars.
+    kubectl apply -f deploy/kubernetes/csi-nfsexporter/rbac-csi-nfsexporter.yaml
+    kubectl apply -f test/e2e/manifests/mock-driver.yaml
+
+    kubectl -n kube-system rollout status deployment/nfsexport-controller --timeout=5m
+    kubectl rollout status deployment/nfsexport-validation-deployment --timeout=5m
+    kubectl rollout status statefulset/csi-nfsexporter-mock --timeout=5m
+    kubectl rollout status daemonset/csi-nfsexporter-mock-node --timeout=5m
+fi
+
+go test -mod=vendor -v -timeout 30m -parallel "${parallel}" ./test/e2e -args -kubeconfig="${kubeconfig}" "$@"
@@ -0,0 +1,64 @@
+/*
+Copyright 2026 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"strings"
+	"testing"
+
+	crdv1 "github.com/kubernetes-csi/external-nfsexporter/client/v6/apis/volumenfsexport/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWebhookRejectsInvalidNfsExport(t *testing.T) {
+	if !*webhookDeployed {
+		t.Skip("webhook tests disabled with -webhook=false")
+	}
+	f := newFramework(t)
+	claimName := "source"
+	emptyClassName := ""
+	_, err := client.NfsExportV1().VolumeNfsExports(f.namespace).Create(f.ctx, &crdv1.VolumeNfsExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "export"},
+		Spec: crdv1.VolumeNfsExportSpec{
+			Source:                   crdv1.VolumeNfsExportSource{PersistentVolumeClaimName: &claimName},
+			VolumeNfsExportClassName: &emptyClassName,
+		},
+	}, metav1.CreateOptions{})
+	if err == nil || !strings.Contains(err.Error(), "volumeNfsExportClassName") {
+		t.Errorf("expected the webhook to reject an empty class name, got: %v", err)
+	}
+}
+
+func TestWebhookRejectsInvalidClass(t *testing.T) {
+	if !*webhookDeployed {
+		t.Skip("webhook tests disabled with -webhook=false")
+	}
+	f := newFramework(t)
+	_, err := client.NfsExportV1().VolumeNfsExportClasses().Create(f.ctx, &crdv1.VolumeNfsExportClass{
+		ObjectMeta:     metav1.ObjectMeta{Name: f.namespace},
+		Driver:         *driverName,
+		Parameters:     map[string]string{"csi.storage.k8s.io/nfsexporter-secret-nmae": "secret"},
+		DeletionPolicy: crdv1.VolumeNfsExportContentDelete,
+	}, metav1.CreateOptions{})
+	if err == nil {
+		client.NfsExportV1().VolumeNfsExportClasses().Delete(f.ctx, f.namespace, metav1.DeleteOptions{})
+	}
+	if err == nil || !strings.Contains(err.Error(), "found unknown parameter key") {
+		t.Errorf("expected the webhook to reject an unknown prefixed parameter, got: %v", err)
+	}
+}